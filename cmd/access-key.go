@@ -19,12 +19,24 @@ package cmd
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
 )
 
 // credential container for access and secret keys.
 type credential struct {
 	AccessKeyID     string `json:"accessKey"`
 	SecretAccessKey string `json:"secretKey"`
+	// Time after which the keys are rejected, nil if they never expire.
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// isExpired - have the keys expired at time now.
+func (cred credential) isExpired(now time.Time) bool {
+	return cred.Expiration != nil && !now.Before(*cred.Expiration)
 }
 
 const (
@@ -44,6 +56,58 @@ func isValidSecretKey(secretKey string) bool {
 	return len(secretKey) >= secretKeyMinLen && len(secretKey) <= secretKeyMaxLen
 }
 
+// validateCredential - validates the strength of the keys.
+func validateCredential(cred credential) error {
+	if !isValidAccessKey(cred.AccessKeyID) {
+		return fmt.Errorf("Access key must be %d to %d characters in length", accessKeyMinLen, accessKeyMaxLen)
+	}
+	if !isValidSecretKey(cred.SecretAccessKey) {
+		return fmt.Errorf("Secret key must be %d to %d characters in length", secretKeyMinLen, secretKeyMaxLen)
+	}
+	if cred.SecretAccessKey == cred.AccessKeyID {
+		return fmt.Errorf("Secret key must differ from the access key")
+	}
+	return nil
+}
+
+// getEnvOrFile - returns the value of the environment variable name,
+// or the contents of the file named by name_FILE as used by Docker
+// and Kubernetes secrets. Setting both is an error.
+func getEnvOrFile(name string) (string, error) {
+	value, fileName := os.Getenv(name), os.Getenv(name+"_FILE")
+	if fileName == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("Both %s and %s_FILE are set", name, name)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	// Secret files usually end with a newline.
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// loadEnvCredential - loads the credentials from MINIO_ACCESS_KEY and
+// MINIO_SECRET_KEY or their _FILE variants, ok is false if neither
+// is set.
+func loadEnvCredential() (cred credential, ok bool, err error) {
+	if cred.AccessKeyID, err = getEnvOrFile("MINIO_ACCESS_KEY"); err != nil {
+		return cred, false, err
+	}
+	if cred.SecretAccessKey, err = getEnvOrFile("MINIO_SECRET_KEY"); err != nil {
+		return cred, false, err
+	}
+	if cred.AccessKeyID == "" && cred.SecretAccessKey == "" {
+		return cred, false, nil
+	}
+	if cred.AccessKeyID == "" || cred.SecretAccessKey == "" {
+		return cred, false, fmt.Errorf("Both access and secret keys must be set")
+	}
+	return cred, true, nil
+}
+
 // mustGenAccessKeys - must generate access credentials.
 func mustGenAccessKeys() (creds credential) {
 	creds, err := genAccessKeys()
@@ -0,0 +1,106 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests validation of the key strength.
+func TestValidateCredential(t *testing.T) {
+	testCases := []struct {
+		cred       credential
+		shouldPass bool
+	}{
+		{credential{AccessKeyID: "minio", SecretAccessKey: "miniostorage"}, true},
+		{credential{AccessKeyID: "abc", SecretAccessKey: "miniostorage"}, false},
+		{credential{AccessKeyID: "minio", SecretAccessKey: "short"}, false},
+		{credential{AccessKeyID: "minio123", SecretAccessKey: "minio123"}, false},
+	}
+	for i, testCase := range testCases {
+		if err := validateCredential(testCase.cred); (err == nil) != testCase.shouldPass {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+	}
+}
+
+// Tests loading the credentials from the environment and secret files.
+func TestLoadEnvCredential(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(dir)
+	secretFile := filepath.Join(dir, "secret_key")
+	if err = ioutil.WriteFile(secretFile, []byte("miniostorage\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	envNames := []string{"MINIO_ACCESS_KEY", "MINIO_ACCESS_KEY_FILE", "MINIO_SECRET_KEY", "MINIO_SECRET_KEY_FILE"}
+	testCases := []struct {
+		env        map[string]string
+		cred       credential
+		ok         bool
+		shouldPass bool
+	}{
+		// Test case - 1.
+		// Nothing set.
+		{map[string]string{}, credential{}, false, true},
+		// Test case - 2.
+		{map[string]string{"MINIO_ACCESS_KEY": "minio", "MINIO_SECRET_KEY": "miniostorage"},
+			credential{AccessKeyID: "minio", SecretAccessKey: "miniostorage"}, true, true},
+		// Test case - 3.
+		// Secret key read from a file, without the trailing newline.
+		{map[string]string{"MINIO_ACCESS_KEY": "minio", "MINIO_SECRET_KEY_FILE": secretFile},
+			credential{AccessKeyID: "minio", SecretAccessKey: "miniostorage"}, true, true},
+		// Test case - 4.
+		// Only the access key.
+		{map[string]string{"MINIO_ACCESS_KEY": "minio"}, credential{}, false, false},
+		// Test case - 5.
+		// Both the value and the file.
+		{map[string]string{"MINIO_ACCESS_KEY": "minio", "MINIO_SECRET_KEY": "miniostorage", "MINIO_SECRET_KEY_FILE": secretFile},
+			credential{}, false, false},
+		// Test case - 6.
+		// Missing file.
+		{map[string]string{"MINIO_ACCESS_KEY": "minio", "MINIO_SECRET_KEY_FILE": filepath.Join(dir, "missing")},
+			credential{}, false, false},
+	}
+	for i, testCase := range testCases {
+		for _, name := range envNames {
+			os.Unsetenv(name)
+		}
+		for name, value := range testCase.env {
+			os.Setenv(name, value)
+		}
+		cred, ok, err := loadEnvCredential()
+		if (err == nil) != testCase.shouldPass {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass {
+			continue
+		}
+		if ok != testCase.ok || cred != testCase.cred {
+			t.Errorf("Test %d: Expected %v %t, got %v %t", i+1, testCase.cred, testCase.ok, cred, ok)
+		}
+	}
+	for _, name := range envNames {
+		os.Unsetenv(name)
+	}
+}
@@ -0,0 +1,1193 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	router "github.com/gorilla/mux"
+	"github.com/minio/minio/pkg/quick"
+)
+
+// Maximum size of a config document accepted by SetConfigHandler.
+const maxConfigJSONSize = 256 * humanize.KiByte
+
+// Maximum size of an anonymous policy document.
+const maxAnonymousPolicySize = 4 * humanize.KiByte
+
+// Maximum size of a batch job description.
+const maxBatchJobJSONSize = 64 * humanize.KiByte
+
+// Maximum size of an event replay request.
+const maxEventReplayJSONSize = 1 * humanize.MiByte
+
+// Period rotated out credentials stay valid for if not specified.
+const defaultCredentialGracePeriod = 24 * time.Hour
+
+// ServerVersion - server version information.
+type ServerVersion struct {
+	Version  string `json:"version"`
+	CommitID string `json:"commitID"`
+}
+
+// ServiceStatus - represents the response of service status API.
+type ServiceStatus struct {
+	ServerVersion ServerVersion `json:"serverVersion"`
+	Uptime        time.Duration `json:"uptime"`
+}
+
+// ServerInfo - represents the response of server info API.
+type ServerInfo struct {
+	ServerVersion ServerVersion `json:"serverVersion"`
+	Uptime        time.Duration `json:"uptime"`
+	Region        string        `json:"region"`
+	IsDistXL      bool          `json:"isDistXL"`
+	StorageInfo   StorageInfo   `json:"storageInfo"`
+}
+
+// checkAdminRequestAuth - admin API requests are only allowed with
+// AWS Signature Version '4' signed by the server credentials.
+func checkAdminRequestAuth(r *http.Request) APIErrorCode {
+	if getRequestAuthType(r) != authTypeSigned {
+		return ErrAccessDenied
+	}
+	s3Error := throttleAuth(r, func() APIErrorCode {
+		return isReqAuthenticated(r, serverConfig.GetRegion())
+	})
+	if s3Error != ErrNone {
+		errorIf(errSignatureMismatch, "%s", dumpRequest(r))
+		return s3Error
+	}
+	return checkAdminAccessKey(r)
+}
+
+// checkAdminAccessKey - admin API requests must be signed by the
+// current credentials, the credentials replaced by a rotation are for
+// the S3 API only and may not rotate the keys or extend their expiry.
+// The signature of r must have been verified already.
+func checkAdminAccessKey(r *http.Request) APIErrorCode {
+	if getRequestAccessKey(r) != serverConfig.GetCredential().AccessKeyID {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
+
+// checkAdminStreamingAuth - like checkAdminRequestAuth for requests
+// whose body is too large to be read for the verification, the body
+// is wrapped to verify its SHA256 sum while it is read instead.
+func checkAdminStreamingAuth(r *http.Request) APIErrorCode {
+	if getRequestAuthType(r) != authTypeSigned {
+		return ErrAccessDenied
+	}
+	s3Error := throttleAuth(r, func() APIErrorCode {
+		return reqSignatureV4Verify(r)
+	})
+	if s3Error != ErrNone {
+		errorIf(errSignatureMismatch, "%s", dumpRequest(r))
+		return s3Error
+	}
+	if s3Error = checkAdminAccessKey(r); s3Error != ErrNone {
+		return s3Error
+	}
+	if !skipContentSha256Cksum(r) {
+		r.Body = ioutil.NopCloser(newSHA256VerifyReader(r.Body, r.Header.Get("X-Amz-Content-Sha256")))
+	}
+	return ErrNone
+}
+
+// writeSuccessResponseJSON - writes a JSON encoded admin API response.
+func writeSuccessResponseJSON(w http.ResponseWriter, r *http.Request, response interface{}) {
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		errorIf(err, "Unable to marshal admin API response.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, jsonBytes)
+}
+
+// getServerVersion - returns version information of this server.
+func getServerVersion() ServerVersion {
+	return ServerVersion{
+		Version:  Version,
+		CommitID: CommitID,
+	}
+}
+
+// ServiceStatusHandler - GET /minio/admin/v1/service
+// ----------
+// Returns the version and uptime of the server.
+func (adminAPI adminAPIHandlers) ServiceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponseJSON(w, r, ServiceStatus{
+		ServerVersion: getServerVersion(),
+		Uptime:        time.Now().UTC().Sub(globalBootTime),
+	})
+}
+
+// ServiceRestartHandler - POST /minio/admin/v1/service/restart
+// ----------
+// Restarts the server process, the response is sent before the
+// server goes down.
+func (adminAPI adminAPIHandlers) ServiceRestartHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponse(w, nil)
+	globalServiceSignalCh <- serviceRestart
+}
+
+// ServiceStopHandler - POST /minio/admin/v1/service/stop
+// ----------
+// Stops the server process gracefully, the response is sent before
+// the server goes down.
+func (adminAPI adminAPIHandlers) ServiceStopHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponse(w, nil)
+	globalServiceSignalCh <- serviceStop
+}
+
+// ServerInfoHandler - GET /minio/admin/v1/info
+// ----------
+// Returns version, uptime, region and storage information of the server.
+func (adminAPI adminAPIHandlers) ServerInfoHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponseJSON(w, r, ServerInfo{
+		ServerVersion: getServerVersion(),
+		Uptime:        time.Now().UTC().Sub(globalBootTime),
+		Region:        serverConfig.GetRegion(),
+		IsDistXL:      globalIsDistXL,
+		StorageInfo:   objectAPI.StorageInfo(),
+	})
+}
+
+// ServerStatsHandler - GET /minio/admin/v1/stats
+// ----------
+// Returns live request counters per API, error counts, bytes
+// transferred, open connections and in-flight requests.
+func (adminAPI adminAPIHandlers) ServerStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponseJSON(w, r, getServerStats())
+}
+
+// ListLocksHandler - GET /minio/admin/v1/locks?bucket=<bucket>&prefix=<prefix>
+// ----------
+// Lists the namespace locks currently held or waited upon, optionally
+// restricted to a bucket and an object prefix.
+func (adminAPI adminAPIHandlers) ListLocksHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	lockState, err := getSystemLockState()
+	if err != nil {
+		errorIf(err, "Unable to fetch system lock state.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	bucket := r.URL.Query().Get("bucket")
+	prefix := r.URL.Query().Get("prefix")
+	if bucket != "" || prefix != "" {
+		var locksInfo []VolumeLockInfo
+		for _, volLockInfo := range lockState.LocksInfoPerObject {
+			if bucket != "" && volLockInfo.Bucket != bucket {
+				continue
+			}
+			if !strings.HasPrefix(volLockInfo.Object, prefix) {
+				continue
+			}
+			locksInfo = append(locksInfo, volLockInfo)
+		}
+		lockState.LocksInfoPerObject = locksInfo
+	}
+
+	writeSuccessResponseJSON(w, r, lockState)
+}
+
+// HealBucketHandler - POST /minio/admin/v1/heal/<bucket>
+// ----------
+// Heals missing bucket entries on all the disks, valid only for XL.
+func (adminAPI adminAPIHandlers) HealBucketHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := objectAPI.HealBucket(bucket); err != nil {
+		errorIf(err, "Unable to heal bucket %s.", bucket)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// HealObjectHandler - POST /minio/admin/v1/heal/<bucket>/<object>
+// ----------
+// Heals missing or outdated object parts on all the disks, valid only for XL.
+func (adminAPI adminAPIHandlers) HealObjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := router.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := objectAPI.HealObject(bucket, object); err != nil {
+		errorIf(err, "Unable to heal object %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// VerifyObjectHandler - POST /minio/admin/v1/verify/<bucket>/<object>
+// ----------
+// Re-reads an object from the backend and compares it with its stored
+// ETag and bitrot checksums, discrepancies are reported but not healed.
+func (adminAPI adminAPIHandlers) VerifyObjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := router.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	result, err := verifyObject(objectAPI, bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to verify object %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, result)
+}
+
+// VerifyObjectsHandler - POST /minio/admin/v1/verify/<bucket>?prefix=<prefix>&marker=<marker>&max-keys=<n>
+// ----------
+// Verifies up to max-keys objects under prefix, continuing after
+// marker. Only objects with discrepancies are listed in the response.
+func (adminAPI adminAPIHandlers) VerifyObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	prefix, marker, _, maxKeys, _ := getListObjectsV1Args(r.URL.Query())
+	if maxKeys < 0 {
+		writeErrorResponse(w, r, ErrInvalidMaxKeys, r.URL.Path)
+		return
+	}
+
+	result, err := verifyObjects(objectAPI, bucket, prefix, marker, maxKeys)
+	if err != nil {
+		errorIf(err, "Unable to verify objects in %s/%s.", bucket, prefix)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, result)
+}
+
+// FsckHandler - POST /minio/admin/v1/fsck?repair=<bool>
+// ----------
+// Checks all objects against their metadata and checksums. With
+// repair=true stale temporary files are removed, inconsistent objects
+// are healed and objects with damaged data are quarantined.
+func (adminAPI adminAPIHandlers) FsckHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	repair := false
+	if value := r.URL.Query().Get("repair"); value != "" {
+		var err error
+		if repair, err = strconv.ParseBool(value); err != nil {
+			writeErrorResponse(w, r, ErrInvalidQueryParams, r.URL.Path)
+			return
+		}
+	}
+
+	info, err := fsckObjectLayer(objectAPI, repair)
+	if err != nil {
+		errorIf(err, "Unable to check backend consistency.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, info)
+}
+
+// StartBatchJobHandler - POST /minio/admin/v1/batch
+// ----------
+// Starts a job copying or deleting all objects under a prefix which
+// match a filter, described by the JSON request body. The job runs in
+// the background, the response carries its ID.
+func (adminAPI adminAPIHandlers) StartBatchJobHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	jobBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBatchJobJSONSize))
+	if err != nil {
+		errorIf(err, "Unable to read batch job description.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	req := BatchJobRequest{}
+	if err = json.Unmarshal(jobBytes, &req); err != nil {
+		writeErrorResponse(w, r, ErrMalformedJSON, r.URL.Path)
+		return
+	}
+
+	status, err := globalBatchJobs.Start(objectAPI, req)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, status)
+}
+
+// ListBatchJobsHandler - GET /minio/admin/v1/batch
+// ----------
+// Lists the running jobs and the reports of the last finished ones.
+func (adminAPI adminAPIHandlers) ListBatchJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponseJSON(w, r, globalBatchJobs.List())
+}
+
+// BatchJobStatusHandler - GET /minio/admin/v1/batch/<id>
+// ----------
+// Returns the progress of a job, its completion report once finished.
+func (adminAPI adminAPIHandlers) BatchJobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	status, err := globalBatchJobs.Get(router.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, status)
+}
+
+// CancelBatchJobHandler - DELETE /minio/admin/v1/batch/<id>
+// ----------
+// Stops a running job once the object in progress is done.
+func (adminAPI adminAPIHandlers) CancelBatchJobHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := globalBatchJobs.Cancel(router.Vars(r)["id"]); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessNoContent(w)
+}
+
+// parseEventQuery - parses the optional RFC3339 from and to times and
+// the maximum number of events of a listing.
+func parseEventQuery(values url.Values) (from, to time.Time, max int, err error) {
+	if value := values.Get("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return from, to, max, errInvalidEventQuery
+		}
+	}
+	if value := values.Get("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil || to.Before(from) {
+			return from, to, max, errInvalidEventQuery
+		}
+	}
+	max = defaultEventListMax
+	if value := values.Get("max"); value != "" {
+		if max, err = strconv.Atoi(value); err != nil || max <= 0 || max > maxEventListMax {
+			return from, to, max, errInvalidEventQuery
+		}
+	}
+	return from, to, max, nil
+}
+
+// ListEventsHandler - GET /minio/admin/v1/events?from=<time>&to=<time>&bucket=<bucket>&max=<n>
+// ----------
+// Lists the bucket events recorded between the optional RFC3339 from
+// and to times, oldest first, in all buckets unless bucket is given.
+// At most max events are returned, 1000 if not specified.
+func (adminAPI adminAPIHandlers) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if globalEventJournal == nil {
+		writeErrorResponse(w, r, ErrAdminEventJournalDisabled, r.URL.Path)
+		return
+	}
+	from, to, max, err := parseEventQuery(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	list, err := globalEventJournal.list(objectAPI, from, to, r.URL.Query().Get("bucket"), max)
+	if err != nil {
+		errorIf(err, "Unable to list recorded events.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, list)
+}
+
+// ReplayEventsHandler - POST /minio/admin/v1/events/replay
+// ----------
+// Sends recorded bucket events again to a configured notification
+// target, selected by the IDs or the time range of the JSON request
+// body. The response carries the number of events sent.
+func (adminAPI adminAPIHandlers) ReplayEventsHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if globalEventJournal == nil {
+		writeErrorResponse(w, r, ErrAdminEventJournalDisabled, r.URL.Path)
+		return
+	}
+	reqBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxEventReplayJSONSize))
+	if err != nil {
+		errorIf(err, "Unable to read event replay request.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	req := EventReplayRequest{}
+	if err = json.Unmarshal(reqBytes, &req); err != nil {
+		writeErrorResponse(w, r, ErrMalformedJSON, r.URL.Path)
+		return
+	}
+
+	result, err := globalEventJournal.replay(objectAPI, req)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, result)
+}
+
+// ExportBucketHandler - GET /minio/admin/v1/export/<bucket>?prefix=<prefix>
+// ----------
+// Streams the objects under prefix with their metadata as a tar
+// archive, which ImportBucketHandler restores.
+func (adminAPI adminAPIHandlers) ExportBucketHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	setCommonHeaders(w)
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+bucket+".tar\"")
+	w.WriteHeader(http.StatusOK)
+	if err := exportBucket(objectAPI, bucket, r.URL.Query().Get("prefix"), w); err != nil {
+		// The archive is left unterminated, which tar readers report.
+		errorIf(err, "Unable to export bucket %s.", bucket)
+	}
+}
+
+// ImportBucketHandler - PUT /minio/admin/v1/import/<bucket>
+// ----------
+// Creates an object for every file of the tar archive in the request
+// body, with the metadata recorded by ExportBucketHandler. The bucket
+// is created if it does not exist.
+func (adminAPI adminAPIHandlers) ImportBucketHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminStreamingAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		if _, ok := errorCause(err).(BucketNotFound); !ok {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+		if err = objectAPI.MakeBucket(bucket); err != nil {
+			errorIf(err, "Unable to create bucket %s.", bucket)
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+
+	info, err := importBucket(objectAPI, bucket, r.Body)
+	if err != nil {
+		errorIf(err, "Unable to import into bucket %s.", bucket)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, info)
+}
+
+// RewrapKeysHandler - POST /minio/admin/v1/kms/rewrap/<bucket>?marker=<marker>&version-marker=<marker>&force=<bool>
+// ----------
+// Re-wraps the data keys of the encrypted objects of a bucket and of
+// the prior versions of its objects under the current master key, so
+// that the master key they were sealed by can be retired. Data keys
+// already sealed by the current master key are re-wrapped only with
+// force=true. The progress is streamed as one JSON document per line
+// after every page of objects, an interrupted run is resumed with the
+// markers of its last progress.
+func (adminAPI adminAPIHandlers) RewrapKeysHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	rewrapper, ok := objectAPI.(kmsRewrapper)
+	if !ok || globalKMS == nil {
+		writeErrorResponse(w, r, ErrAdminKMSNotConfigured, r.URL.Path)
+		return
+	}
+	force := false
+	if value := r.URL.Query().Get("force"); value != "" {
+		var err error
+		if force, err = strconv.ParseBool(value); err != nil {
+			writeErrorResponse(w, r, ErrInvalidQueryParams, r.URL.Path)
+			return
+		}
+	}
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	progress := KMSRewrapInfo{
+		Bucket:        bucket,
+		Marker:        r.URL.Query().Get("marker"),
+		VersionMarker: r.URL.Query().Get("version-marker"),
+	}
+	setCommonHeaders(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	report := func(p KMSRewrapInfo) error {
+		progress = p
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+		w.(http.Flusher).Flush()
+		return nil
+	}
+	if err := rewrapper.rewrapKeys(progress, force, report); err != nil {
+		errorIf(err, "Unable to re-wrap the data keys of bucket %s.", bucket)
+		// The status was sent, the failure ends the progress with the
+		// markers to resume from.
+		progress.Done = true
+		progress.Error = err.Error()
+		report(progress)
+	}
+}
+
+// ListIncompleteUploadsHandler - GET /minio/admin/v1/uploads?bucket=&prefix=
+// ----------
+// Lists the multipart uploads in progress with the size of their parts,
+// in all buckets unless bucket is given.
+func (adminAPI adminAPIHandlers) ListIncompleteUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	bucket, prefix := r.URL.Query().Get("bucket"), r.URL.Query().Get("prefix")
+	info, err := listIncompleteUploads(objectAPI, bucket, prefix)
+	if err != nil {
+		errorIf(err, "Unable to list incomplete uploads.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, info)
+}
+
+// Secret keys and notification target passwords are never returned
+// by the admin API, they are replaced by this value. A config sent back
+// with it keeps the secret keys and passwords.
+const redactedSecretKey = "REDACTED"
+
+// redactNotifyPasswords - returns a copy of the notification targets
+// with their passwords redacted.
+func redactNotifyPasswords(n notifier) notifier {
+	nats := make(map[string]natsNotify, len(n.NATS))
+	for id, target := range n.NATS {
+		if target.Password != "" {
+			target.Password = redactedSecretKey
+		}
+		nats[id] = target
+	}
+	redis := make(map[string]redisNotify, len(n.Redis))
+	for id, target := range n.Redis {
+		if target.Password != "" {
+			target.Password = redactedSecretKey
+		}
+		redis[id] = target
+	}
+	postgreSQL := make(map[string]postgreSQLNotify, len(n.PostgreSQL))
+	for id, target := range n.PostgreSQL {
+		if target.Password != "" {
+			target.Password = redactedSecretKey
+		}
+		postgreSQL[id] = target
+	}
+	n.NATS, n.Redis, n.PostgreSQL = nats, redis, postgreSQL
+	return n
+}
+
+// restoreNotifyPasswords - replaces the redacted passwords of the
+// notification targets of n by the passwords of the targets with the
+// same ID in current, returns false if there is no such target.
+func restoreNotifyPasswords(n, current notifier) bool {
+	for id, target := range n.NATS {
+		if target.Password == redactedSecretKey {
+			currentTarget, ok := current.NATS[id]
+			if !ok {
+				return false
+			}
+			target.Password = currentTarget.Password
+			n.NATS[id] = target
+		}
+	}
+	for id, target := range n.Redis {
+		if target.Password == redactedSecretKey {
+			currentTarget, ok := current.Redis[id]
+			if !ok {
+				return false
+			}
+			target.Password = currentTarget.Password
+			n.Redis[id] = target
+		}
+	}
+	for id, target := range n.PostgreSQL {
+		if target.Password == redactedSecretKey {
+			currentTarget, ok := current.PostgreSQL[id]
+			if !ok {
+				return false
+			}
+			target.Password = currentTarget.Password
+			n.PostgreSQL[id] = target
+		}
+	}
+	return true
+}
+
+// GetConfigHandler - GET /minio/admin/v1/config
+// ----------
+// Returns the currently loaded server configuration, with the secret
+// keys and the notification target passwords redacted.
+func (adminAPI adminAPIHandlers) GetConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	serverConfigMu.RLock()
+	config := *serverConfig
+	config.Credential.SecretAccessKey = redactedSecretKey
+	if config.PreviousCredential != nil {
+		previous := *config.PreviousCredential
+		previous.SecretAccessKey = redactedSecretKey
+		config.PreviousCredential = &previous
+	}
+	config.Notify = redactNotifyPasswords(config.Notify)
+	configBytes, err := json.Marshal(config)
+	serverConfigMu.RUnlock()
+	if err != nil {
+		errorIf(err, "Unable to marshal server config.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, configBytes)
+}
+
+// SetConfigHandler - PUT /minio/admin/v1/config
+// ----------
+// Validates and persists a new server configuration, the new
+// configuration is applied on the next service restart.
+func (adminAPI adminAPIHandlers) SetConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	configBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxConfigJSONSize))
+	if err != nil {
+		errorIf(err, "Unable to read config document.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	srvCfg := &serverConfigV10{}
+	if err = json.Unmarshal(configBytes, srvCfg); err != nil {
+		writeErrorResponse(w, r, ErrMalformedJSON, r.URL.Path)
+		return
+	}
+	if srvCfg.Version != globalMinioConfigVersion {
+		writeErrorResponse(w, r, ErrAdminConfigBadVersion, r.URL.Path)
+		return
+	}
+	// Redacted secret keys are kept as they are.
+	if srvCfg.Credential.SecretAccessKey == redactedSecretKey {
+		srvCfg.Credential.SecretAccessKey = serverConfig.GetCredential().SecretAccessKey
+	}
+	if previous := srvCfg.PreviousCredential; previous != nil && previous.SecretAccessKey == redactedSecretKey {
+		current := serverConfig.GetPreviousCredential()
+		if current == nil || current.AccessKeyID != previous.AccessKeyID {
+			writeErrorResponse(w, r, ErrAdminConfigBadSecretKey, r.URL.Path)
+			return
+		}
+		previous.SecretAccessKey = current.SecretAccessKey
+	}
+	serverConfigMu.RLock()
+	restored := restoreNotifyPasswords(srvCfg.Notify, serverConfig.Notify)
+	serverConfigMu.RUnlock()
+	if !restored {
+		writeErrorResponse(w, r, ErrAdminConfigBadNotifyPassword, r.URL.Path)
+		return
+	}
+	if !isValidAccessKey(srvCfg.Credential.AccessKeyID) {
+		writeErrorResponse(w, r, ErrInvalidAccessKeyID, r.URL.Path)
+		return
+	}
+	if !isValidSecretKey(srvCfg.Credential.SecretAccessKey) {
+		writeErrorResponse(w, r, ErrAdminConfigBadSecretKey, r.URL.Path)
+		return
+	}
+
+	configFile, err := getConfigFile()
+	if err != nil {
+		errorIf(err, "Unable to get config file path.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	qc, err := quick.New(srvCfg)
+	if err != nil {
+		errorIf(err, "Unable to initialize config.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	if err = qc.Save(configFile); err != nil {
+		errorIf(err, "Unable to save config file.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// ListAnonymousPoliciesHandler - GET /minio/admin/v1/policy/<bucket>
+// ----------
+// Returns the anonymous policy (none, download, upload or public) of
+// every prefix in the bucket policy.
+func (adminAPI adminAPIHandlers) ListAnonymousPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	policies, err := getAnonymousPolicies(objectAPI, bucket)
+	if err != nil {
+		errorIf(err, "Unable to read bucket policy of %s.", bucket)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, policies)
+}
+
+// SetAnonymousPolicyHandler - PUT /minio/admin/v1/policy/<bucket>
+// ----------
+// Sets the anonymous policy of the prefix in the JSON encoded
+// AnonymousPolicy body, translated into statements of the bucket
+// policy. An empty prefix applies to the whole bucket.
+func (adminAPI adminAPIHandlers) SetAnonymousPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	bucket := router.Vars(r)["bucket"]
+
+	objectAPI := adminAPI.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	var anonPolicy AnonymousPolicy
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAnonymousPolicySize)).Decode(&anonPolicy); err != nil {
+		writeErrorResponse(w, r, ErrMalformedJSON, r.URL.Path)
+		return
+	}
+
+	if err := setAnonymousPolicy(objectAPI, bucket, anonPolicy.Prefix, anonPolicy.Policy); err != nil {
+		errorIf(err, "Unable to set anonymous policy of %s/%s.", bucket, anonPolicy.Prefix)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// parseCredentialExpiry - parses an optional RFC3339 expiry which must
+// be in the future, nil if empty.
+func parseCredentialExpiry(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	if !expiry.After(now) {
+		return nil, errInvalidArgument
+	}
+	expiry = expiry.UTC()
+	return &expiry, nil
+}
+
+// updateCredentials - updates the credentials on all the peers and
+// the local server and saves the configuration.
+func updateCredentials(creds credential, previousCreds *credential) error {
+	// Notify all other Minio peers to update credentials.
+	errsMap := updateCredsOnPeers(creds, previousCreds)
+	for svr, err := range errsMap {
+		errorIf(err, "Unable to change credentials on %s.", svr)
+	}
+
+	// Update local credentials.
+	serverConfig.SetCredential(creds)
+	serverConfig.SetPreviousCredential(previousCreds)
+	return serverConfig.Save()
+}
+
+// RotateCredentialHandler - POST /minio/admin/v1/credential/rotate?grace=<duration>&expiry=<time>
+// ----------
+// Replaces the credentials with newly generated ones, returned in
+// the response. The current credentials stay valid for the grace
+// period, 24h if not specified, and the new ones expire at the
+// optional RFC3339 expiry.
+func (adminAPI adminAPIHandlers) RotateCredentialHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	now := time.Now().UTC()
+	grace := defaultCredentialGracePeriod
+	if value := r.URL.Query().Get("grace"); value != "" {
+		var err error
+		if grace, err = time.ParseDuration(value); err != nil || grace < 0 {
+			writeErrorResponse(w, r, ErrAdminInvalidCredentialExpiry, r.URL.Path)
+			return
+		}
+	}
+	expiry, err := parseCredentialExpiry(r.URL.Query().Get("expiry"), now)
+	if err != nil {
+		writeErrorResponse(w, r, ErrAdminInvalidCredentialExpiry, r.URL.Path)
+		return
+	}
+
+	creds, err := genAccessKeys()
+	if err != nil {
+		errorIf(err, "Unable to generate access keys.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	creds.Expiration = expiry
+
+	// The current credentials expire at the end of the grace
+	// period, unless they expire earlier.
+	previousCreds := serverConfig.GetCredential()
+	graceEnd := now.Add(grace)
+	if !previousCreds.isExpired(graceEnd) {
+		previousCreds.Expiration = &graceEnd
+	}
+
+	if err = updateCredentials(creds, &previousCreds); err != nil {
+		errorIf(err, "Unable to save rotated credentials.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	writeSuccessResponseJSON(w, r, creds)
+}
+
+// SetCredentialExpiryHandler - PUT /minio/admin/v1/credential/expiry?expiry=<time>
+// ----------
+// Sets the RFC3339 expiry of the current credentials, an empty expiry
+// removes it.
+func (adminAPI adminAPIHandlers) SetCredentialExpiryHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	expiry, err := parseCredentialExpiry(r.URL.Query().Get("expiry"), time.Now().UTC())
+	if err != nil {
+		writeErrorResponse(w, r, ErrAdminInvalidCredentialExpiry, r.URL.Path)
+		return
+	}
+
+	creds := serverConfig.GetCredential()
+	creds.Expiration = expiry
+	if err = updateCredentials(creds, serverConfig.GetPreviousCredential()); err != nil {
+		errorIf(err, "Unable to save credentials.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// RuntimeInfoHandler - GET /minio/admin/v1/debug/runtime
+// ----------
+// Returns goroutine, memory and garbage collector statistics of the
+// server process.
+func (adminAPI adminAPIHandlers) RuntimeInfoHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	writeSuccessResponseJSON(w, r, getRuntimeInfo())
+}
+
+// DumpProfileHandler - GET /minio/admin/v1/debug/pprof/{profile}?debug=<level>
+// ----------
+// Writes the named runtime profile, e.g goroutine, heap, threadcreate
+// or block, in pprof format or as text when debug is non-zero.
+// debug=2 dumps the stacks of all goroutines.
+func (adminAPI adminAPIHandlers) DumpProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	profile := pprof.Lookup(router.Vars(r)["profile"])
+	if profile == nil {
+		writeErrorResponse(w, r, ErrAdminInvalidProfile, r.URL.Path)
+		return
+	}
+	debug := 0
+	if value := r.URL.Query().Get("debug"); value != "" {
+		var err error
+		if debug, err = strconv.Atoi(value); err != nil || debug < 0 {
+			writeErrorResponse(w, r, ErrAdminInvalidProfile, r.URL.Path)
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := profile.WriteTo(&buf, debug); err != nil {
+		errorIf(err, "Unable to write %s profile.", profile.Name())
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	writeSuccessResponse(w, buf.Bytes())
+}
+
+// CaptureProfileHandler - POST /minio/admin/v1/debug/profile?profile=<cpu|heap|block>&duration=<duration>
+// ----------
+// Samples a CPU or block profile for the duration, 30s if not
+// specified, or takes a heap profile, and returns it in pprof format.
+func (adminAPI adminAPIHandlers) CaptureProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := checkAdminRequestAuth(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	profile := r.URL.Query().Get("profile")
+	duration := defaultProfileDuration
+	if value := r.URL.Query().Get("duration"); value != "" {
+		var err error
+		if duration, err = time.ParseDuration(value); err != nil {
+			writeErrorResponse(w, r, ErrAdminInvalidProfile, r.URL.Path)
+			return
+		}
+	}
+	if !isValidProfile(profile) || duration < time.Second || duration > maxProfileDuration {
+		writeErrorResponse(w, r, ErrAdminInvalidProfile, r.URL.Path)
+		return
+	}
+
+	data, err := captureProfile(profile, duration, closeNotify(w))
+	if err != nil {
+		errorIf(err, "Unable to capture %s profile.", profile)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+profile+".pprof\"")
+	writeSuccessResponse(w, data)
+}
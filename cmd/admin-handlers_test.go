@@ -0,0 +1,899 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/minio/minio/pkg/madmin"
+)
+
+// newTestAdminClient - returns an admin client for the given test server.
+func newTestAdminClient(t *testing.T, testServer TestServer, accessKey, secretKey string) *madmin.AdminClient {
+	u, err := url.Parse(testServer.Server.URL)
+	if err != nil {
+		t.Fatalf("Unable to parse test server URL: %s", err)
+	}
+	adm, err := madmin.New(u.Host, accessKey, secretKey, false)
+	if err != nil {
+		t.Fatalf("Unable to initialize admin client: %s", err)
+	}
+	return adm
+}
+
+// Tests service status, restart and stop admin APIs.
+func TestAdminServiceHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	status, err := adm.ServiceStatus()
+	if err != nil {
+		t.Fatalf("Unexpected error from ServiceStatus: %s", err)
+	}
+	if status.ServerVersion.Version != Version {
+		t.Errorf("Expected version %s, got %s", Version, status.ServerVersion.Version)
+	}
+
+	testCases := []struct {
+		apiFn          func() error
+		expectedSignal serviceSignal
+	}{
+		{adm.ServiceRestart, serviceRestart},
+		{adm.ServiceStop, serviceStop},
+	}
+	for i, testCase := range testCases {
+		globalServiceSignalCh = make(chan serviceSignal, 1)
+		if err = testCase.apiFn(); err != nil {
+			t.Fatalf("Test %d: Unexpected error: %s", i+1, err)
+		}
+		if signal := <-globalServiceSignalCh; signal != testCase.expectedSignal {
+			t.Errorf("Test %d: Expected signal %v, got %v", i+1, testCase.expectedSignal, signal)
+		}
+	}
+}
+
+// Tests that admin APIs reject requests with invalid credentials.
+func TestAdminHandlersAuth(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, "invalidsecretkey")
+	_, err := adm.ServiceStatus()
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "SignatureDoesNotMatch" {
+		t.Errorf("Expected SignatureDoesNotMatch, got %v", err)
+	}
+
+	// Anonymous requests are denied.
+	resp, err := http.Get(testServer.Server.URL + "/minio/admin/v1/service")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, resp.StatusCode)
+	}
+}
+
+// Tests server info, list locks and heal admin APIs.
+func TestAdminInfoHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "XL")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	info, err := adm.ServerInfo()
+	if err != nil {
+		t.Fatalf("Unexpected error from ServerInfo: %s", err)
+	}
+	if info.Region != serverConfig.GetRegion() {
+		t.Errorf("Expected region %s, got %s", serverConfig.GetRegion(), info.Region)
+	}
+	if info.StorageInfo.Backend.Type != madmin.XL {
+		t.Errorf("Expected XL backend, got %v", info.StorageInfo.Backend.Type)
+	}
+
+	if _, err = adm.ListLocks("bucket", "prefix"); err != nil {
+		t.Errorf("Unexpected error from ListLocks: %s", err)
+	}
+
+	bucketName := getRandomBucketName()
+	if err = testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	if _, err = testServer.Obj.PutObject(bucketName, "dir/object", int64(len("hello")),
+		bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("Unable to create object: %s", err)
+	}
+
+	if err = adm.HealBucket(bucketName); err != nil {
+		t.Errorf("Unexpected error from HealBucket: %s", err)
+	}
+	if err = adm.HealObject(bucketName, "dir/object"); err != nil {
+		t.Errorf("Unexpected error from HealObject: %s", err)
+	}
+	err = adm.HealBucket("non-existent-bucket")
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "NoSuchBucket" {
+		t.Errorf("Expected NoSuchBucket, got %v", err)
+	}
+}
+
+// Tests get and set config admin APIs.
+func TestAdminConfigHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	serverConfigMu.Lock()
+	serverConfig.Notify.NATS = map[string]natsNotify{"1": {Address: "localhost:4222", Password: "nats-secret"}}
+	serverConfig.Notify.Redis = map[string]redisNotify{"1": {Addr: "localhost:6379", Password: "redis-secret"}}
+	serverConfig.Notify.PostgreSQL = map[string]postgreSQLNotify{"1": {Host: "localhost", Password: "postgres-secret"}}
+	serverConfigMu.Unlock()
+
+	configBytes, err := adm.GetConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error from GetConfig: %s", err)
+	}
+	config := serverConfigV10{}
+	if err = json.Unmarshal(configBytes, &config); err != nil {
+		t.Fatalf("Unable to unmarshal config: %s", err)
+	}
+	if config.Credential.AccessKeyID != testServer.AccessKey {
+		t.Errorf("Expected access key %s, got %s", testServer.AccessKey, config.Credential.AccessKeyID)
+	}
+	if config.Credential.SecretAccessKey != redactedSecretKey {
+		t.Errorf("Expected the secret key to be redacted, got %s", config.Credential.SecretAccessKey)
+	}
+	for _, password := range []string{config.Notify.NATS["1"].Password, config.Notify.Redis["1"].Password, config.Notify.PostgreSQL["1"].Password} {
+		if password != redactedSecretKey {
+			t.Errorf("Expected the notification target password to be redacted, got %s", password)
+		}
+	}
+	if serverConfig.Notify.Redis["1"].Password != "redis-secret" {
+		t.Errorf("Expected the loaded config to keep its password, got %s", serverConfig.Notify.Redis["1"].Password)
+	}
+
+	// Storing the same config back must succeed and keep the secret key.
+	if err = adm.SetConfig(bytes.NewReader(configBytes)); err != nil {
+		t.Errorf("Unexpected error from SetConfig: %s", err)
+	}
+	configFile, err := getConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedBytes, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := serverConfigV10{}
+	if err = json.Unmarshal(savedBytes, &saved); err != nil {
+		t.Fatalf("Unable to unmarshal saved config: %s", err)
+	}
+	if saved.Credential.SecretAccessKey != testServer.SecretKey {
+		t.Errorf("Expected the secret key to be kept, got %s", saved.Credential.SecretAccessKey)
+	}
+	if saved.Notify.NATS["1"].Password != "nats-secret" || saved.Notify.Redis["1"].Password != "redis-secret" ||
+		saved.Notify.PostgreSQL["1"].Password != "postgres-secret" {
+		t.Errorf("Expected the notification target passwords to be kept, got %+v", saved.Notify)
+	}
+
+	// Redacted passwords of new targets are not known.
+	config.Notify.Redis["2"] = redisNotify{Addr: "localhost:6380", Password: redactedSecretKey}
+	unknownBytes, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		config       []byte
+		expectedCode string
+	}{
+		{[]byte("not json"), "XMinioMalformedJSON"},
+		{[]byte(`{"version": "1"}`), "XMinioAdminConfigBadVersion"},
+		{unknownBytes, "XMinioAdminConfigBadNotifyPassword"},
+	}
+	for i, testCase := range testCases {
+		err = adm.SetConfig(bytes.NewReader(testCase.config))
+		if errResp := madmin.ToErrorResponse(err); errResp.Code != testCase.expectedCode {
+			t.Errorf("Test %d: Expected %s, got %v", i+1, testCase.expectedCode, err)
+		}
+	}
+}
+
+// Tests list and set anonymous policy admin APIs.
+func TestAdminAnonymousPolicyHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err := testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	if _, err := testServer.Obj.PutObject(bucketName, "public/object", int64(len("hello")),
+		bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("Unable to create object: %s", err)
+	}
+
+	policies, err := adm.ListAnonymousPolicies(bucketName)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListAnonymousPolicies: %s", err)
+	}
+	if len(policies) != 0 {
+		t.Errorf("Expected no anonymous policies, got %v", policies)
+	}
+
+	if err = adm.SetAnonymousPolicy(bucketName, "public", madmin.AnonymousPolicyDownload); err != nil {
+		t.Fatalf("Unexpected error from SetAnonymousPolicy: %s", err)
+	}
+	if err = adm.SetAnonymousPolicy(bucketName, "uploads", madmin.AnonymousPolicyUpload); err != nil {
+		t.Fatalf("Unexpected error from SetAnonymousPolicy: %s", err)
+	}
+	policies, err = adm.ListAnonymousPolicies(bucketName)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListAnonymousPolicies: %s", err)
+	}
+	expectedPolicies := []madmin.AnonymousPolicy{
+		{Prefix: "public", Policy: madmin.AnonymousPolicyDownload},
+		{Prefix: "uploads", Policy: madmin.AnonymousPolicyUpload},
+	}
+	if !reflect.DeepEqual(policies, expectedPolicies) {
+		t.Errorf("Expected policies %v, got %v", expectedPolicies, policies)
+	}
+
+	// Anonymous downloads are allowed on the prefix.
+	resp, err := http.Get(testServer.Server.URL + "/" + bucketName + "/public/object")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Removing all the policies removes the bucket policy.
+	for _, prefix := range []string{"public", "uploads"} {
+		if err = adm.SetAnonymousPolicy(bucketName, prefix, madmin.AnonymousPolicyNone); err != nil {
+			t.Fatalf("Unexpected error from SetAnonymousPolicy: %s", err)
+		}
+	}
+	if _, err = readBucketPolicyJSON(bucketName, testServer.Obj); err == nil {
+		t.Errorf("Expected bucket policy to be removed")
+	}
+
+	testCases := []struct {
+		bucket       string
+		policy       madmin.AnonymousPolicyType
+		expectedCode string
+	}{
+		{bucketName, "private", "XMinioAdminInvalidAnonymousPolicy"},
+		{"non-existent-bucket", madmin.AnonymousPolicyPublic, "NoSuchBucket"},
+	}
+	for i, testCase := range testCases {
+		err = adm.SetAnonymousPolicy(testCase.bucket, "", testCase.policy)
+		if errResp := madmin.ToErrorResponse(err); errResp.Code != testCase.expectedCode {
+			t.Errorf("Test %d: Expected %s, got %v", i+1, testCase.expectedCode, err)
+		}
+	}
+}
+
+// Tests credential rotation and expiry admin APIs.
+func TestAdminCredentialHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	creds, err := adm.RotateCredential(time.Hour, time.Time{})
+	if err != nil {
+		t.Fatalf("Unexpected error from RotateCredential: %s", err)
+	}
+	if creds.AccessKey == testServer.AccessKey || creds.Expiration != nil {
+		t.Fatalf("Unexpected credentials %v", creds)
+	}
+
+	// The old credentials are valid for the S3 API during the grace
+	// period, but not for the admin API, they may neither rotate the
+	// keys nor extend their own expiry.
+	newAdm := newTestAdminClient(t, testServer, creds.AccessKey, creds.SecretKey)
+	if _, err = newAdm.ServiceStatus(); err != nil {
+		t.Fatalf("Unexpected error from ServiceStatus: %s", err)
+	}
+	req, err := newTestSignedRequestV4("GET", getListBucketURL(testServer.Server.URL), 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListBuckets: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the old credentials to list buckets, got %s", resp.Status)
+	}
+	_, err = adm.ServiceStatus()
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "AccessDenied" {
+		t.Errorf("Expected AccessDenied, got %v", err)
+	}
+	_, err = adm.RotateCredential(time.Hour, time.Time{})
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "AccessDenied" {
+		t.Errorf("Expected AccessDenied, got %v", err)
+	}
+	err = adm.SetCredentialExpiry(time.Now().UTC().Add(24 * time.Hour))
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "AccessDenied" {
+		t.Errorf("Expected AccessDenied, got %v", err)
+	}
+	if cred := serverConfig.GetCredential(); cred.AccessKeyID != creds.AccessKey {
+		t.Errorf("Expected the credentials to be kept, got %s", cred.AccessKeyID)
+	}
+
+	// Without a grace period the old credentials expire at once.
+	if _, err = newAdm.RotateCredential(0, time.Time{}); err != nil {
+		t.Fatalf("Unexpected error from RotateCredential: %s", err)
+	}
+	_, err = newAdm.ServiceStatus()
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAccessKeyExpired" {
+		t.Errorf("Expected XMinioAccessKeyExpired, got %v", err)
+	}
+	// Credentials rotated before are no longer known.
+	_, err = adm.ServiceStatus()
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "InvalidAccessKeyID" {
+		t.Errorf("Expected InvalidAccessKeyID, got %v", err)
+	}
+
+	cred := serverConfig.GetCredential()
+	adm = newTestAdminClient(t, testServer, cred.AccessKeyID, cred.SecretAccessKey)
+	expiry := time.Now().UTC().Add(time.Hour)
+	if err = adm.SetCredentialExpiry(expiry); err != nil {
+		t.Fatalf("Unexpected error from SetCredentialExpiry: %s", err)
+	}
+	if cred = serverConfig.GetCredential(); cred.Expiration == nil || cred.Expiration.Unix() != expiry.Unix() {
+		t.Errorf("Expected expiration %s, got %v", expiry, cred.Expiration)
+	}
+	if err = adm.SetCredentialExpiry(time.Time{}); err != nil {
+		t.Fatalf("Unexpected error from SetCredentialExpiry: %s", err)
+	}
+	if cred = serverConfig.GetCredential(); cred.Expiration != nil {
+		t.Errorf("Expected no expiration, got %s", cred.Expiration)
+	}
+
+	// Expiry must be in the future.
+	err = adm.SetCredentialExpiry(time.Now().UTC().Add(-time.Hour))
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidCredentialExpiry" {
+		t.Errorf("Expected XMinioAdminInvalidCredentialExpiry, got %v", err)
+	}
+}
+
+// Tests object verification admin APIs.
+func TestAdminVerifyHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err := testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	if _, err := testServer.Obj.PutObject(bucketName, "prefix/object", int64(len("hello")),
+		bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("Unable to create object: %s", err)
+	}
+
+	info, err := adm.VerifyObject(bucketName, "prefix/object")
+	if err != nil {
+		t.Fatalf("Unexpected error from VerifyObject: %s", err)
+	}
+	if info.Size != int64(len("hello")) || len(info.Problems) != 0 {
+		t.Errorf("Unexpected result %+v", info)
+	}
+
+	result, err := adm.VerifyObjects(bucketName, "prefix/", "", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from VerifyObjects: %s", err)
+	}
+	if result.Verified != 1 || len(result.Objects) != 0 || result.IsTruncated {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	_, err = adm.VerifyObject(bucketName, "missing")
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "NoSuchKey" {
+		t.Errorf("Expected NoSuchKey, got %v", err)
+	}
+}
+
+// Tests the runtime diagnostics admin handlers.
+func TestAdminDiagnosticsHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	info, err := adm.RuntimeInfo()
+	if err != nil {
+		t.Fatalf("Unexpected error from RuntimeInfo: %s", err)
+	}
+	if info.NumGoroutine == 0 || info.GoVersion == "" {
+		t.Errorf("Unexpected runtime info %+v", info)
+	}
+
+	stacks, err := adm.DumpGoroutines()
+	if err != nil {
+		t.Fatalf("Unexpected error from DumpGoroutines: %s", err)
+	}
+	if !bytes.Contains(stacks, []byte("goroutine ")) {
+		t.Errorf("Expected goroutine stacks, got %q", stacks)
+	}
+
+	profile, err := adm.CaptureProfile(madmin.ProfilerHeap, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from CaptureProfile: %s", err)
+	}
+	data, err := ioutil.ReadAll(profile)
+	profile.Close()
+	if err != nil || len(data) == 0 {
+		t.Errorf("Expected a heap profile, got %d bytes, %v", len(data), err)
+	}
+
+	_, err = adm.CaptureProfile(madmin.ProfilerCPU, time.Hour)
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidProfile" {
+		t.Errorf("Expected XMinioAdminInvalidProfile, got %v", err)
+	}
+
+	// Only one profile may be captured at a time.
+	globalProfilerBusy = 1
+	_, err = adm.CaptureProfile(madmin.ProfilerBlock, time.Second)
+	globalProfilerBusy = 0
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminProfilerBusy" {
+		t.Errorf("Expected XMinioAdminProfilerBusy, got %v", err)
+	}
+}
+
+// Tests the server statistics admin handler.
+func TestAdminServerStatsHandler(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	if _, err := adm.ServerInfo(); err != nil {
+		t.Fatalf("Unexpected error from ServerInfo: %s", err)
+	}
+	stats, err := adm.ServerStats()
+	if err != nil {
+		t.Fatalf("Unexpected error from ServerStats: %s", err)
+	}
+	if stats.APIs["Admin"].Requests == 0 {
+		t.Errorf("Expected admin requests to be counted, got %+v", stats.APIs)
+	}
+	// The stats request itself is in flight.
+	if stats.InFlightRequests < 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// Tests the consistency check admin handler.
+func TestAdminFsckHandler(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err := testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	if _, err := testServer.Obj.PutObject(bucketName, "object", int64(len("hello")),
+		bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("Unable to create object: %s", err)
+	}
+
+	info, err := adm.Fsck(true)
+	if err != nil {
+		t.Fatalf("Unexpected error from Fsck: %s", err)
+	}
+	if info.Objects != 1 || len(info.Inconsistent) != 0 {
+		t.Errorf("Unexpected result %+v", info)
+	}
+
+	// Only one check may run at a time.
+	globalFsckRunning = 1
+	_, err = adm.Fsck(false)
+	globalFsckRunning = 0
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminFsckInProgress" {
+		t.Errorf("Expected XMinioAdminFsckInProgress, got %v", err)
+	}
+}
+
+// Tests re-wrapping the data keys of a bucket under the current master
+// key through the admin API.
+func TestAdminRewrapKeysHandler(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+	_, err := adm.RewrapKeys("bucket", "", "", false, nil)
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminKMSNotConfigured" {
+		t.Errorf("Expected XMinioAdminKMSNotConfigured, got %v", err)
+	}
+	testServer.Stop()
+
+	previous, err := parseMasterKey("old-key:" + strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globalKMS, err = parseMasterKey(testMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	globalPreviousKMS = previous
+	defer func() { globalKMS, globalPreviousKMS = nil, nil }()
+	testServer = StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm = newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err = testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	backend := testServer.Obj.(encryptedObjects).ObjectLayer
+	oldObj := newEncryptedObjects(backend, previous, nil)
+	for _, object := range []string{"a", "b"} {
+		if _, err = oldObj.PutObject(bucketName, object, int64(len("hello")),
+			bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+			t.Fatalf("Unable to create object: %s", err)
+		}
+	}
+
+	var reports []madmin.KMSRewrapInfo
+	info, err := adm.RewrapKeys(bucketName, "a", "", false, func(progress madmin.KMSRewrapInfo) {
+		reports = append(reports, progress)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from RewrapKeys: %s", err)
+	}
+	if len(reports) < 2 || !info.Done || info.Rewrapped != 1 || info.Marker != "b" {
+		t.Errorf("Unexpected progress %+v", reports)
+	}
+	for object, keyID := range map[string]string{"a": "old-key", "b": "my-key"} {
+		objInfo, err := backend.GetObjectInfo(bucketName, object)
+		if err != nil {
+			t.Fatalf("Unable to stat object: %s", err)
+		}
+		if objInfo.UserDefined[sseKMSKeyIDHeader] != keyID {
+			t.Errorf("Expected %s sealed by %s, got %s", object, keyID, objInfo.UserDefined[sseKMSKeyIDHeader])
+		}
+	}
+
+	_, err = adm.RewrapKeys("missing-bucket", "", "", false, nil)
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "NoSuchBucket" {
+		t.Errorf("Expected NoSuchBucket, got %v", err)
+	}
+}
+
+// waitForBatchJob - polls the status of a batch job until it finishes.
+func waitForBatchJob(t *testing.T, adm *madmin.AdminClient, id string) madmin.BatchJobStatus {
+	for i := 0; i < 100; i++ {
+		status, err := adm.GetBatchJob(id)
+		if err != nil {
+			t.Fatalf("Unexpected error from GetBatchJob: %s", err)
+		}
+		if status.State != "running" {
+			return status
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Batch job %s did not finish", id)
+	return madmin.BatchJobStatus{}
+}
+
+// Tests running batch jobs via admin API.
+func TestAdminBatchJobHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	targetBucket := getRandomBucketName()
+	for _, bucket := range []string{bucketName, targetBucket} {
+		if err := testServer.Obj.MakeBucket(bucket); err != nil {
+			t.Fatalf("Unable to create bucket: %s", err)
+		}
+	}
+	for _, object := range []string{"logs/a.log", "logs/b.log", "logs/c.txt", "other.log"} {
+		if _, err := testServer.Obj.PutObject(bucketName, object, int64(len("hello")),
+			bytes.NewReader([]byte("hello")), map[string]string{"content-type": "text/plain"}, ""); err != nil {
+			t.Fatalf("Unable to create object: %s", err)
+		}
+	}
+
+	// Copy the logs to another bucket.
+	status, err := adm.StartBatchJob(madmin.BatchJobRequest{
+		Operation:    madmin.BatchJobCopy,
+		Bucket:       bucketName,
+		Prefix:       "logs/",
+		Filter:       madmin.BatchJobFilter{Suffix: ".log"},
+		TargetBucket: targetBucket,
+		TargetPrefix: "archive/",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from StartBatchJob: %s", err)
+	}
+	status = waitForBatchJob(t, adm, status.ID)
+	if status.State != "completed" || status.Scanned != 3 || status.Matched != 2 || status.Succeeded != 2 || status.Bytes != 10 {
+		t.Errorf("Unexpected copy job report %+v", status)
+	}
+	for _, object := range []string{"archive/a.log", "archive/b.log"} {
+		objInfo, err := testServer.Obj.GetObjectInfo(targetBucket, object)
+		if err != nil {
+			t.Fatalf("Copy %s is missing: %s", object, err)
+		}
+		if objInfo.ContentType != "text/plain" {
+			t.Errorf("Copy %s lost its content type: %+v", object, objInfo)
+		}
+	}
+
+	// Delete the logs from the source.
+	status, err = adm.StartBatchJob(madmin.BatchJobRequest{
+		Operation: madmin.BatchJobDelete,
+		Bucket:    bucketName,
+		Filter:    madmin.BatchJobFilter{Suffix: ".log"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from StartBatchJob: %s", err)
+	}
+	if status = waitForBatchJob(t, adm, status.ID); status.State != "completed" || status.Succeeded != 3 {
+		t.Errorf("Unexpected delete job report %+v", status)
+	}
+	result, err := testServer.Obj.ListObjects(bucketName, "", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "logs/c.txt" {
+		t.Errorf("Unexpected objects after delete job %+v", result.Objects)
+	}
+
+	statuses, err := adm.ListBatchJobs()
+	if err != nil {
+		t.Fatalf("Unexpected error from ListBatchJobs: %s", err)
+	}
+	if len(statuses) < 2 || statuses[len(statuses)-1].ID != status.ID {
+		t.Errorf("Unexpected batch jobs %+v", statuses)
+	}
+	if err = adm.CancelBatchJob(status.ID); err != nil {
+		t.Errorf("Unexpected error from CancelBatchJob: %s", err)
+	}
+
+	// Copies into the source prefix are rejected.
+	_, err = adm.StartBatchJob(madmin.BatchJobRequest{
+		Operation:    madmin.BatchJobCopy,
+		Bucket:       bucketName,
+		Prefix:       "logs/",
+		TargetBucket: bucketName,
+		TargetPrefix: "logs/copy/",
+	})
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidBatchJob" {
+		t.Errorf("Expected XMinioAdminInvalidBatchJob, got %v", err)
+	}
+	_, err = adm.StartBatchJob(madmin.BatchJobRequest{Operation: madmin.BatchJobDelete, Bucket: "nonexistent-bucket"})
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "NoSuchBucket" {
+		t.Errorf("Expected NoSuchBucket, got %v", err)
+	}
+	_, err = adm.GetBatchJob("nonexistent-job")
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminNoSuchBatchJob" {
+		t.Errorf("Expected XMinioAdminNoSuchBatchJob, got %v", err)
+	}
+}
+
+// Tests exporting a bucket and importing it into another via admin API.
+func TestAdminBucketExportImportHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err := testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	objects := map[string]string{
+		"a.txt":       "hello",
+		"dir/b.txt":   "world",
+		"dir/empty":   "",
+		"other/c.txt": "excluded",
+	}
+	for object, data := range objects {
+		metadata := map[string]string{"content-type": "text/plain", "X-Amz-Meta-Owner": "minio"}
+		if _, err := testServer.Obj.PutObject(bucketName, object, int64(len(data)),
+			bytes.NewReader([]byte(data)), metadata, ""); err != nil {
+			t.Fatalf("Unable to create object: %s", err)
+		}
+	}
+
+	reader, err := adm.ExportBucket(bucketName, "")
+	if err != nil {
+		t.Fatalf("Unexpected error from ExportBucket: %s", err)
+	}
+	archive, err := ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Unable to read exported archive: %s", err)
+	}
+
+	targetBucket := getRandomBucketName()
+	info, err := adm.ImportBucket(targetBucket, bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Unexpected error from ImportBucket: %s", err)
+	}
+	if info.Objects != len(objects) || info.Bytes != 18 {
+		t.Errorf("Unexpected import result %+v", info)
+	}
+	for object, data := range objects {
+		var buffer bytes.Buffer
+		if err = testServer.Obj.GetObject(targetBucket, object, 0, int64(len(data)), &buffer); err != nil {
+			t.Fatalf("Imported object %s is missing: %s", object, err)
+		}
+		if buffer.String() != data {
+			t.Errorf("Imported object %s has %q, expected %q", object, buffer.String(), data)
+		}
+		objInfo, err := testServer.Obj.GetObjectInfo(targetBucket, object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if objInfo.ContentType != "text/plain" || objInfo.UserDefined["X-Amz-Meta-Owner"] != "minio" {
+			t.Errorf("Imported object %s lost its metadata: %+v", object, objInfo)
+		}
+	}
+
+	// Export a prefix only.
+	reader, err = adm.ExportBucket(bucketName, "dir/")
+	if err != nil {
+		t.Fatalf("Unexpected error from ExportBucket: %s", err)
+	}
+	archive, err = ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err = adm.ImportBucket(getRandomBucketName(), bytes.NewReader(archive), int64(len(archive))); err != nil || info.Objects != 2 {
+		t.Errorf("Unexpected import of a prefix %+v, %v", info, err)
+	}
+
+	if _, err = adm.ExportBucket("nonexistent-bucket", ""); madmin.ToErrorResponse(err).Code != "NoSuchBucket" {
+		t.Errorf("Expected NoSuchBucket, got %v", err)
+	}
+	invalid := []byte("not a tar archive")
+	_, err = adm.ImportBucket(targetBucket, bytes.NewReader(invalid), int64(len(invalid)))
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioInvalidArchive" {
+		t.Errorf("Expected XMinioInvalidArchive, got %v", err)
+	}
+}
+
+// Tests listing incomplete multipart uploads via admin API.
+func TestAdminListIncompleteUploadsHandler(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	bucketName := getRandomBucketName()
+	if err := testServer.Obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("Unable to create bucket: %s", err)
+	}
+	uploadID, err := testServer.Obj.NewMultipartUpload(bucketName, "object", nil)
+	if err != nil {
+		t.Fatalf("Unable to start upload: %s", err)
+	}
+	if _, err = testServer.Obj.PutObjectPart(bucketName, "object", uploadID, 1, int64(len("hello")),
+		bytes.NewReader([]byte("hello")), "", ""); err != nil {
+		t.Fatalf("Unable to upload part: %s", err)
+	}
+
+	info, err := adm.ListIncompleteUploads(bucketName, "")
+	if err != nil {
+		t.Fatalf("Unexpected error from ListIncompleteUploads: %s", err)
+	}
+	if len(info.Uploads) != 1 || info.Uploads[0].UploadID != uploadID || info.TotalSize != int64(len("hello")) {
+		t.Errorf("Unexpected result %+v", info)
+	}
+
+	_, err = adm.ListIncompleteUploads("nonexistent-bucket", "")
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "NoSuchBucket" {
+		t.Errorf("Expected NoSuchBucket, got %v", err)
+	}
+}
+
+// Tests listing and replaying recorded events via admin API.
+func TestAdminEventHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	adm := newTestAdminClient(t, testServer, testServer.AccessKey, testServer.SecretKey)
+
+	_, err := adm.ListEvents(time.Time{}, time.Time{}, "", 0)
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminEventJournalDisabled" {
+		t.Errorf("Expected XMinioAdminEventJournalDisabled, got %v", err)
+	}
+
+	globalEventJournal = newEventJournal(time.Hour)
+	defer func() { globalEventJournal = nil }()
+	start := time.Now().UTC().Add(-time.Second)
+	bucketName := getRandomBucketName()
+	for _, object := range []string{"a", "b"} {
+		eventNotify(eventData{Type: ObjectCreatedPut, Bucket: bucketName, ObjInfo: ObjectInfo{Name: object}})
+	}
+	if err = globalEventJournal.flush(testServer.Obj, time.Now().UTC()); err != nil {
+		t.Fatalf("Unable to flush the journal: %s", err)
+	}
+	eventNotify(eventData{Type: ObjectRemovedDelete, Bucket: bucketName, ObjInfo: ObjectInfo{Name: "a"}})
+
+	list, err := adm.ListEvents(start, time.Time{}, bucketName, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListEvents: %s", err)
+	}
+	if len(list.Events) != 3 || list.Truncated || list.Events[2].EventType != "s3:ObjectRemoved:Delete" {
+		t.Fatalf("Unexpected events %+v", list)
+	}
+	if list, err = adm.ListEvents(start, time.Time{}, bucketName, 2); err != nil || len(list.Events) != 2 || !list.Truncated {
+		t.Errorf("Expected 2 events of 3, got %+v, %v", list, err)
+	}
+	_, err = adm.ListEvents(time.Now(), start, "", 0)
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidEventQuery" {
+		t.Errorf("Expected XMinioAdminInvalidEventQuery, got %v", err)
+	}
+
+	// Replay to a target collecting the events.
+	var buffer bytes.Buffer
+	targetLog := logrus.New()
+	targetLog.Out = &buffer
+	targetLog.Formatter = new(logrus.JSONFormatter)
+	arn := "arn:minio:sqs:us-east-1:1:webhook"
+	globalEventNotifier.external.targets[arn] = targetLog
+	defer delete(globalEventNotifier.external.targets, arn)
+
+	_, err = adm.ReplayEvents(madmin.EventReplayRequest{Target: "arn:minio:sqs:us-east-1:1:missing", From: start})
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidEventTarget" {
+		t.Errorf("Expected XMinioAdminInvalidEventTarget, got %v", err)
+	}
+	_, err = adm.ReplayEvents(madmin.EventReplayRequest{Target: arn})
+	if errResp := madmin.ToErrorResponse(err); errResp.Code != "XMinioAdminInvalidEventQuery" {
+		t.Errorf("Expected XMinioAdminInvalidEventQuery, got %v", err)
+	}
+	result, err := adm.ReplayEvents(madmin.EventReplayRequest{
+		Target: arn,
+		IDs:    []string{list.Events[1].ID},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from ReplayEvents: %s", err)
+	}
+	if result.Replayed != 1 {
+		t.Errorf("Expected 1 event replayed, got %+v", result)
+	}
+	var fields logrus.Fields
+	if err = json.Unmarshal(buffer.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["Key"] != bucketName+"/b" || fields["EventType"] != "s3:ObjectCreated:Put" {
+		t.Errorf("Unexpected replayed event %v", fields)
+	}
+
+	buffer.Reset()
+	result, err = adm.ReplayEvents(madmin.EventReplayRequest{Target: arn, From: start, Bucket: bucketName})
+	if err != nil || result.Replayed != 3 {
+		t.Errorf("Expected 3 events replayed, got %+v, %v", result, err)
+	}
+	if lines := bytes.Count(buffer.Bytes(), []byte("\n")); lines != 3 {
+		t.Errorf("Expected 3 events sent, got %d", lines)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// Limits of the duration of profiles captured through the admin API.
+const (
+	defaultProfileDuration = 30 * time.Second
+	maxProfileDuration     = 5 * time.Minute
+)
+
+// Set while a profile is being captured, CPU and block profiling are
+// process wide so only one capture may run at a time.
+var globalProfilerBusy int32
+
+// RuntimeInfo - Go runtime statistics of the server process.
+type RuntimeInfo struct {
+	GoVersion    string        `json:"goVersion"`
+	NumCPU       int           `json:"numCPU"`
+	NumGoroutine int           `json:"numGoroutine"`
+	Alloc        uint64        `json:"alloc"`
+	TotalAlloc   uint64        `json:"totalAlloc"`
+	Sys          uint64        `json:"sys"`
+	HeapAlloc    uint64        `json:"heapAlloc"`
+	HeapInuse    uint64        `json:"heapInuse"`
+	HeapObjects  uint64        `json:"heapObjects"`
+	NumGC        uint32        `json:"numGC"`
+	PauseTotal   time.Duration `json:"pauseTotal"`
+	LastGC       time.Time     `json:"lastGC"`
+}
+
+// getRuntimeInfo - returns the current runtime statistics.
+func getRuntimeInfo() RuntimeInfo {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	info := RuntimeInfo{
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		NumGoroutine: runtime.NumGoroutine(),
+		Alloc:        memStats.Alloc,
+		TotalAlloc:   memStats.TotalAlloc,
+		Sys:          memStats.Sys,
+		HeapAlloc:    memStats.HeapAlloc,
+		HeapInuse:    memStats.HeapInuse,
+		HeapObjects:  memStats.HeapObjects,
+		NumGC:        memStats.NumGC,
+		PauseTotal:   time.Duration(memStats.PauseTotalNs),
+	}
+	if memStats.LastGC != 0 {
+		info.LastGC = time.Unix(0, int64(memStats.LastGC)).UTC()
+	}
+	return info
+}
+
+// isValidProfile - returns true if profile can be captured by
+// captureProfile.
+func isValidProfile(profile string) bool {
+	switch profile {
+	case "cpu", "heap", "block":
+		return true
+	}
+	return false
+}
+
+// captureProfile - captures a profile in pprof format. CPU and block
+// profiles are sampled for duration or until done is closed, heap
+// profiles are a snapshot taken after a garbage collection.
+func captureProfile(profile string, duration time.Duration, done <-chan bool) ([]byte, error) {
+	// Profiles sampled over a duration conflict with _MINIO_PROFILER.
+	if profile != "heap" && globalProfiler != nil {
+		return nil, errProfilerBusy
+	}
+	if !atomic.CompareAndSwapInt32(&globalProfilerBusy, 0, 1) {
+		return nil, errProfilerBusy
+	}
+	defer atomic.StoreInt32(&globalProfilerBusy, 0)
+
+	wait := func() {
+		select {
+		case <-time.After(duration):
+		case <-done:
+		}
+	}
+
+	var buf bytes.Buffer
+	switch profile {
+	case "cpu":
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		wait()
+		pprof.StopCPUProfile()
+	case "heap":
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(&buf); err != nil {
+			return nil, err
+		}
+	case "block":
+		runtime.SetBlockProfileRate(1)
+		wait()
+		runtime.SetBlockProfileRate(0)
+		if err := pprof.Lookup("block").WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("Unknown profile " + profile)
+	}
+	return buf.Bytes(), nil
+}
+
+// closeNotify - returns a channel closed when the client goes away,
+// nil if the response writer does not support it.
+func closeNotify(w http.ResponseWriter) <-chan bool {
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import router "github.com/gorilla/mux"
+
+// Admin API path prefix, all admin API requests are served under
+// the reserved bucket so that they never collide with S3 API paths.
+const (
+	adminAPIPathPrefix = reservedBucket + "/admin"
+	adminAPIVersion    = "v1"
+)
+
+// adminAPIHandlers provides HTTP handlers for Minio admin API.
+type adminAPIHandlers struct {
+	ObjectAPI func() ObjectLayer
+}
+
+// registerAdminRouter - Add handler functions for each service REST API routes.
+func registerAdminRouter(mux *router.Router) {
+	adminAPI := adminAPIHandlers{
+		ObjectAPI: newObjectLayerFn,
+	}
+
+	// Admin router
+	adminRouter := mux.NewRoute().PathPrefix(adminAPIPathPrefix + "/" + adminAPIVersion).Subrouter()
+
+	/// Service operations
+
+	// Service status
+	adminRouter.Methods("GET").Path("/service").HandlerFunc(adminAPI.ServiceStatusHandler)
+	// Service restart
+	adminRouter.Methods("POST").Path("/service/restart").HandlerFunc(adminAPI.ServiceRestartHandler)
+	// Service stop
+	adminRouter.Methods("POST").Path("/service/stop").HandlerFunc(adminAPI.ServiceStopHandler)
+
+	/// Server information
+
+	// Server info
+	adminRouter.Methods("GET").Path("/info").HandlerFunc(adminAPI.ServerInfoHandler)
+	// Server statistics
+	adminRouter.Methods("GET").Path("/stats").HandlerFunc(adminAPI.ServerStatsHandler)
+
+	/// Lock operations
+
+	// List locks
+	adminRouter.Methods("GET").Path("/locks").HandlerFunc(adminAPI.ListLocksHandler)
+
+	/// Heal operations
+
+	// Heal object
+	adminRouter.Methods("POST").Path("/heal/{bucket}/{object:.+}").HandlerFunc(adminAPI.HealObjectHandler)
+	// Heal bucket
+	adminRouter.Methods("POST").Path("/heal/{bucket}").HandlerFunc(adminAPI.HealBucketHandler)
+
+	/// Verify operations
+
+	// Verify object
+	adminRouter.Methods("POST").Path("/verify/{bucket}/{object:.+}").HandlerFunc(adminAPI.VerifyObjectHandler)
+	// Verify objects under a prefix
+	adminRouter.Methods("POST").Path("/verify/{bucket}").HandlerFunc(adminAPI.VerifyObjectsHandler)
+	// Check and repair the consistency of the backend
+	adminRouter.Methods("POST").Path("/fsck").HandlerFunc(adminAPI.FsckHandler)
+
+	/// Batch job operations
+
+	// Start batch job
+	adminRouter.Methods("POST").Path("/batch").HandlerFunc(adminAPI.StartBatchJobHandler)
+	// List batch jobs
+	adminRouter.Methods("GET").Path("/batch").HandlerFunc(adminAPI.ListBatchJobsHandler)
+	// Batch job status
+	adminRouter.Methods("GET").Path("/batch/{id}").HandlerFunc(adminAPI.BatchJobStatusHandler)
+	// Cancel batch job
+	adminRouter.Methods("DELETE").Path("/batch/{id}").HandlerFunc(adminAPI.CancelBatchJobHandler)
+
+	/// Event journal operations
+
+	// List recorded bucket events
+	adminRouter.Methods("GET").Path("/events").HandlerFunc(adminAPI.ListEventsHandler)
+	// Replay recorded bucket events to a target
+	adminRouter.Methods("POST").Path("/events/replay").HandlerFunc(adminAPI.ReplayEventsHandler)
+
+	/// Bucket export operations
+
+	// Export bucket as a tar archive
+	adminRouter.Methods("GET").Path("/export/{bucket}").HandlerFunc(adminAPI.ExportBucketHandler)
+	// Import a tar archive into a bucket
+	adminRouter.Methods("PUT").Path("/import/{bucket}").HandlerFunc(adminAPI.ImportBucketHandler)
+
+	/// Multipart operations
+
+	// List incomplete multipart uploads
+	adminRouter.Methods("GET").Path("/uploads").HandlerFunc(adminAPI.ListIncompleteUploadsHandler)
+
+	/// KMS operations
+
+	// Re-wrap the data keys of a bucket under the current master key
+	adminRouter.Methods("POST").Path("/kms/rewrap/{bucket}").HandlerFunc(adminAPI.RewrapKeysHandler)
+
+	/// Config operations
+
+	// Get config
+	adminRouter.Methods("GET").Path("/config").HandlerFunc(adminAPI.GetConfigHandler)
+	// Set config
+	adminRouter.Methods("PUT").Path("/config").HandlerFunc(adminAPI.SetConfigHandler)
+
+	/// Anonymous policy operations
+
+	// List anonymous policies
+	adminRouter.Methods("GET").Path("/policy/{bucket}").HandlerFunc(adminAPI.ListAnonymousPoliciesHandler)
+	// Set anonymous policy
+	adminRouter.Methods("PUT").Path("/policy/{bucket}").HandlerFunc(adminAPI.SetAnonymousPolicyHandler)
+
+	/// Credential operations
+
+	// Rotate credentials
+	adminRouter.Methods("POST").Path("/credential/rotate").HandlerFunc(adminAPI.RotateCredentialHandler)
+	// Set credential expiry
+	adminRouter.Methods("PUT").Path("/credential/expiry").HandlerFunc(adminAPI.SetCredentialExpiryHandler)
+
+	/// Diagnostics operations
+
+	// Runtime statistics
+	adminRouter.Methods("GET").Path("/debug/runtime").HandlerFunc(adminAPI.RuntimeInfoHandler)
+	// Dump runtime profile
+	adminRouter.Methods("GET").Path("/debug/pprof/{profile}").HandlerFunc(adminAPI.DumpProfileHandler)
+	// Capture profile
+	adminRouter.Methods("POST").Path("/debug/profile").HandlerFunc(adminAPI.CaptureProfileHandler)
+}
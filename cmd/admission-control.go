@@ -0,0 +1,290 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Average latency is computed over windows of this length.
+	admissionLatencyInterval = time.Second
+
+	// Listings are shed from this percentage of the maximum number
+	// of in-flight requests on.
+	admissionListingPercent = 75
+)
+
+// requestPriority - order in which requests are shed when the server
+// is saturated, lowest first.
+type requestPriority int
+
+const (
+	// Listings, shed first as they are the most expensive for the
+	// backend and clients can retry them at any time.
+	priorityListing requestPriority = iota
+	// Object and bucket operations.
+	priorityData
+	// Admin, RPC and browser requests, never shed.
+	priorityCritical
+)
+
+// AdmissionStats - load of the server against its admission limits.
+type AdmissionStats struct {
+	// Configured limits, zero if disabled.
+	MaxRequests int64         `json:"maxRequests"`
+	MaxLatency  time.Duration `json:"maxLatency"`
+	// Requests admitted and not completed yet.
+	InFlight int64 `json:"inFlight"`
+	// Average latency of the last complete window.
+	Latency time.Duration `json:"latency"`
+	// Whether listings are shed at present.
+	Saturated bool `json:"saturated"`
+	// Requests rejected with SlowDown.
+	ShedListings uint64 `json:"shedListings"`
+	ShedRequests uint64 `json:"shedRequests"`
+}
+
+// admissionControl - rejects requests with SlowDown while the server
+// is saturated. Listings are shed when the average latency is above
+// its limit or the in-flight requests come close to their limit, all
+// other S3 requests only once the in-flight limit is reached.
+type admissionControl struct {
+	// Updated atomically, kept first for 64-bit alignment.
+	inFlight     int64
+	latencySum   int64
+	latencyCount int64
+	latency      int64
+	shedListings uint64
+	shedRequests uint64
+
+	// Limits, zero if disabled.
+	maxRequests int64
+	maxLatency  time.Duration
+}
+
+// Admission limits of the server, set with MINIO_ADMISSION_MAX_REQUESTS
+// and MINIO_ADMISSION_MAX_LATENCY.
+var globalAdmissionControl = &admissionControl{}
+
+// loadAdmissionLimits - loads the admission limits from the environment.
+func loadAdmissionLimits() (maxRequests int64, maxLatency time.Duration, err error) {
+	if value := os.Getenv("MINIO_ADMISSION_MAX_REQUESTS"); value != "" && strings.ToLower(value) != "off" {
+		maxRequests, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || maxRequests <= 0 {
+			return 0, 0, fmt.Errorf("Unknown value `%s` for MINIO_ADMISSION_MAX_REQUESTS, expected a positive number or `off`", value)
+		}
+	}
+	if value := os.Getenv("MINIO_ADMISSION_MAX_LATENCY"); value != "" && strings.ToLower(value) != "off" {
+		maxLatency, err = time.ParseDuration(value)
+		if err != nil || maxLatency <= 0 {
+			return 0, 0, fmt.Errorf("Unknown value `%s` for MINIO_ADMISSION_MAX_LATENCY, expected a duration such as `500ms` or `off`", value)
+		}
+	}
+	return maxRequests, maxLatency, nil
+}
+
+// getAPIPriority - returns the priority of requests to api when
+// shedding load.
+func getAPIPriority(api string) requestPriority {
+	switch api {
+	case "Admin", "RPC", "Browser":
+		return priorityCritical
+	case "ListBuckets", "ListObjectsV1", "ListObjectsV2", "ListMultipartUploads", "ListObjectParts":
+		return priorityListing
+	}
+	return priorityData
+}
+
+// isEnabled - returns true if any limit is configured.
+func (a *admissionControl) isEnabled() bool {
+	return a.maxRequests > 0 || a.maxLatency > 0
+}
+
+// isSaturated - returns true if listings are shed at present.
+func (a *admissionControl) isSaturated() bool {
+	if a.maxLatency > 0 && time.Duration(atomic.LoadInt64(&a.latency)) > a.maxLatency {
+		return true
+	}
+	return a.maxRequests > 0 && atomic.LoadInt64(&a.inFlight) >= a.maxRequests*admissionListingPercent/100
+}
+
+// admit - returns true if a request of priority may be served now.
+func (a *admissionControl) admit(priority requestPriority) bool {
+	switch priority {
+	case priorityCritical:
+		return true
+	case priorityListing:
+		if a.isSaturated() {
+			atomic.AddUint64(&a.shedListings, 1)
+			return false
+		}
+		return true
+	}
+	if a.maxRequests > 0 && atomic.LoadInt64(&a.inFlight) >= a.maxRequests {
+		atomic.AddUint64(&a.shedRequests, 1)
+		return false
+	}
+	return true
+}
+
+// observe - records the time a request took to its first response byte.
+func (a *admissionControl) observe(latency time.Duration) {
+	atomic.AddInt64(&a.latencySum, int64(latency))
+	atomic.AddInt64(&a.latencyCount, 1)
+}
+
+// updateLatency - closes the current window and keeps its average
+// latency, zero if no request completed in it.
+func (a *admissionControl) updateLatency() {
+	sum := atomic.SwapInt64(&a.latencySum, 0)
+	count := atomic.SwapInt64(&a.latencyCount, 0)
+	var latency int64
+	if count > 0 {
+		latency = sum / count
+	}
+	atomic.StoreInt64(&a.latency, latency)
+}
+
+// retryAfter - returns the seconds clients should wait before retrying
+// a shed request of priority, at least the current average latency.
+// Listings are asked to wait twice as long.
+func (a *admissionControl) retryAfter(priority requestPriority) int {
+	seconds := int((time.Duration(atomic.LoadInt64(&a.latency)) + time.Second - 1) / time.Second)
+	if seconds < slowDownRetryAfter {
+		seconds = slowDownRetryAfter
+	}
+	if priority == priorityListing {
+		seconds *= 2
+	}
+	return seconds
+}
+
+// stats - returns the load and the shed requests.
+func (a *admissionControl) stats() AdmissionStats {
+	return AdmissionStats{
+		MaxRequests:  a.maxRequests,
+		MaxLatency:   a.maxLatency,
+		InFlight:     atomic.LoadInt64(&a.inFlight),
+		Latency:      time.Duration(atomic.LoadInt64(&a.latency)),
+		Saturated:    a.isSaturated(),
+		ShedListings: atomic.LoadUint64(&a.shedListings),
+		ShedRequests: atomic.LoadUint64(&a.shedRequests),
+	}
+}
+
+// startAdmissionMonitor - averages the latency every
+// admissionLatencyInterval if enabled with MINIO_ADMISSION_MAX_LATENCY.
+func startAdmissionMonitor() {
+	if globalAdmissionControl.maxLatency == 0 {
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(admissionLatencyInterval)
+			globalAdmissionControl.updateLatency()
+		}
+	}()
+}
+
+// admissionResponseWriter - records when the first response byte is
+// written.
+type admissionResponseWriter struct {
+	http.ResponseWriter
+	start   time.Time
+	latency time.Duration
+}
+
+func (w *admissionResponseWriter) WriteHeader(statusCode int) {
+	if w.latency == 0 {
+		w.latency = time.Since(w.start)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *admissionResponseWriter) Write(p []byte) (int, error) {
+	if w.latency == 0 {
+		w.latency = time.Since(w.start)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush - handlers streaming responses expect an http.Flusher.
+func (w *admissionResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify - handlers waiting on clients expect an http.CloseNotifier.
+func (w *admissionResponseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+type admissionHandler struct {
+	handler http.Handler
+}
+
+// setAdmissionHandler - rejects requests with SlowDown while the server
+// is saturated, lowest priority first.
+func setAdmissionHandler(h http.Handler) http.Handler {
+	return admissionHandler{h}
+}
+
+func (h admissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a := globalAdmissionControl
+	if !a.isEnabled() {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	api := getRequestAPIName(r)
+	priority := getAPIPriority(api)
+	if priority == priorityCritical {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if !a.admit(priority) {
+		w.Header().Set("Retry-After", strconv.Itoa(a.retryAfter(priority)))
+		writeErrorResponse(w, r, ErrSlowDown, r.URL.Path)
+		return
+	}
+
+	atomic.AddInt64(&a.inFlight, 1)
+	defer atomic.AddInt64(&a.inFlight, -1)
+
+	aw := &admissionResponseWriter{ResponseWriter: w, start: time.Now()}
+	h.handler.ServeHTTP(aw, r)
+
+	// Uploads take as long as their body, notifications as long as
+	// the client listens, neither tells how loaded the server is.
+	if r.ContentLength != 0 || api == "ListenBucketNotification" {
+		return
+	}
+	if aw.latency == 0 {
+		aw.latency = time.Since(aw.start)
+	}
+	a.observe(aw.latency)
+}
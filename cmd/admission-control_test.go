@@ -0,0 +1,134 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// Tests loading the admission limits from the environment.
+func TestLoadAdmissionLimits(t *testing.T) {
+	defer os.Unsetenv("MINIO_ADMISSION_MAX_REQUESTS")
+	defer os.Unsetenv("MINIO_ADMISSION_MAX_LATENCY")
+
+	testCases := []struct {
+		maxRequests         string
+		maxLatency          string
+		expectedMaxRequests int64
+		expectedMaxLatency  time.Duration
+		success             bool
+	}{
+		{"", "", 0, 0, true},
+		{"off", "off", 0, 0, true},
+		{"100", "500ms", 100, 500 * time.Millisecond, true},
+		{"0", "", 0, 0, false},
+		{"many", "", 0, 0, false},
+		{"", "-1s", 0, 0, false},
+		{"", "slow", 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		os.Setenv("MINIO_ADMISSION_MAX_REQUESTS", testCase.maxRequests)
+		os.Setenv("MINIO_ADMISSION_MAX_LATENCY", testCase.maxLatency)
+		maxRequests, maxLatency, err := loadAdmissionLimits()
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if maxRequests != testCase.expectedMaxRequests || maxLatency != testCase.expectedMaxLatency {
+			t.Errorf("Test %d: Expected %d and %s, got %d and %s", i+1,
+				testCase.expectedMaxRequests, testCase.expectedMaxLatency, maxRequests, maxLatency)
+		}
+	}
+}
+
+// Tests that listings are shed before other requests and admin
+// requests are never shed.
+func TestAdmissionHandler(t *testing.T) {
+	defer func(a *admissionControl) { globalAdmissionControl = a }(globalAdmissionControl)
+	globalAdmissionControl = &admissionControl{maxRequests: 4, maxLatency: time.Second}
+
+	handler := setAdmissionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testCases := []struct {
+		inFlight       int64
+		latency        time.Duration
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{0, 0, "GET", "/bucket", http.StatusOK},
+		{0, 0, "GET", "/bucket/object", http.StatusOK},
+		// Listings are shed close to the in-flight limit.
+		{3, 0, "GET", "/bucket", http.StatusServiceUnavailable},
+		{3, 0, "GET", "/", http.StatusServiceUnavailable},
+		{3, 0, "GET", "/bucket/object", http.StatusOK},
+		// Listings are shed above the latency limit.
+		{0, 2 * time.Second, "GET", "/bucket", http.StatusServiceUnavailable},
+		{0, 2 * time.Second, "PUT", "/bucket/object", http.StatusOK},
+		// Everything but admin requests is shed at the in-flight limit.
+		{4, 0, "PUT", "/bucket/object", http.StatusServiceUnavailable},
+		{4, 0, "GET", adminAPIPathPrefix + "/info", http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		globalAdmissionControl.inFlight = testCase.inFlight
+		globalAdmissionControl.latency = int64(testCase.latency)
+		req, err := http.NewRequest(testCase.method, "http://localhost:9000"+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, rec.Code)
+		}
+		if rec.Code == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("Test %d: Expected a Retry-After header", i+1)
+		}
+	}
+	if stats := globalAdmissionControl.stats(); stats.ShedListings != 3 || stats.ShedRequests != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// Tests averaging the latency of a window and the retry hints.
+func TestAdmissionLatency(t *testing.T) {
+	a := &admissionControl{maxLatency: time.Second}
+	a.observe(1 * time.Second)
+	a.observe(3 * time.Second)
+	a.updateLatency()
+	if latency := a.stats().Latency; latency != 2*time.Second {
+		t.Errorf("Expected latency 2s, got %s", latency)
+	}
+	if seconds := a.retryAfter(priorityData); seconds != 2 {
+		t.Errorf("Expected 2 seconds, got %d", seconds)
+	}
+	if seconds := a.retryAfter(priorityListing); seconds != 4 {
+		t.Errorf("Expected 4 seconds, got %d", seconds)
+	}
+	// No request completed in the next window.
+	a.updateLatency()
+	if a.isSaturated() {
+		t.Errorf("Expected the server not to be saturated without requests")
+	}
+	if seconds := a.retryAfter(priorityData); seconds != slowDownRetryAfter {
+		t.Errorf("Expected %d seconds, got %d", slowDownRetryAfter, seconds)
+	}
+}
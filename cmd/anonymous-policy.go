@@ -0,0 +1,95 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/policy"
+)
+
+// Anonymous access policies of a prefix, a simplified model of the
+// bucket policy for the common cases.
+const (
+	anonymousPolicyNone     = "none"
+	anonymousPolicyDownload = "download"
+	anonymousPolicyUpload   = "upload"
+	anonymousPolicyPublic   = "public"
+)
+
+// Canned bucket policies each anonymous policy translates to.
+var anonymousBucketPolicies = map[string]policy.BucketPolicy{
+	anonymousPolicyNone:     policy.BucketPolicyNone,
+	anonymousPolicyDownload: policy.BucketPolicyReadOnly,
+	anonymousPolicyUpload:   policy.BucketPolicyWriteOnly,
+	anonymousPolicyPublic:   policy.BucketPolicyReadWrite,
+}
+
+// AnonymousPolicy - anonymous access policy of a prefix in a bucket.
+type AnonymousPolicy struct {
+	Prefix string `json:"prefix"`
+	Policy string `json:"policy"`
+}
+
+// byAnonymousPolicyPrefix - collection satisfying sort.Interface.
+type byAnonymousPolicyPrefix []AnonymousPolicy
+
+func (d byAnonymousPolicyPrefix) Len() int           { return len(d) }
+func (d byAnonymousPolicyPrefix) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d byAnonymousPolicyPrefix) Less(i, j int) bool { return d[i].Prefix < d[j].Prefix }
+
+// toAnonymousPolicy - returns the anonymous policy of a canned bucket
+// policy.
+func toAnonymousPolicy(bucketP policy.BucketPolicy) string {
+	for anonPolicy, p := range anonymousBucketPolicies {
+		if p == bucketP {
+			return anonPolicy
+		}
+	}
+	return anonymousPolicyNone
+}
+
+// getAnonymousPolicies - returns the anonymous policies of all the
+// prefixes of bucket, sorted by prefix.
+func getAnonymousPolicies(objAPI ObjectLayer, bucket string) ([]AnonymousPolicy, error) {
+	policyInfo, err := readBucketAccessPolicy(objAPI, bucket)
+	if err != nil {
+		return nil, err
+	}
+	policies := []AnonymousPolicy{}
+	for resource, bucketP := range policy.GetPolicies(policyInfo.Statements, bucket) {
+		// Resources are of the form "bucket/prefix*".
+		prefix := strings.TrimSuffix(strings.TrimPrefix(resource, bucket+"/"), "*")
+		policies = append(policies, AnonymousPolicy{
+			Prefix: prefix,
+			Policy: toAnonymousPolicy(bucketP),
+		})
+	}
+	sort.Sort(byAnonymousPolicyPrefix(policies))
+	return policies, nil
+}
+
+// setAnonymousPolicy - sets the anonymous policy of prefix in bucket
+// by updating the bucket policy.
+func setAnonymousPolicy(objAPI ObjectLayer, bucket, prefix, anonPolicy string) error {
+	bucketP, ok := anonymousBucketPolicies[anonPolicy]
+	if !ok {
+		return errInvalidAnonymousPolicy
+	}
+	return setBucketAccessPolicy(objAPI, bucket, prefix, bucketP)
+}
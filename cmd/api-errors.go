@@ -63,6 +63,8 @@ const (
 	ErrInvalidRequestBody
 	ErrInvalidCopySource
 	ErrInvalidCopyDest
+	ErrInvalidCopyPartRange
+	ErrInvalidCopyPartRangeSource
 	ErrInvalidPolicyDocument
 	ErrInvalidObjectState
 	ErrMalformedXML
@@ -109,6 +111,10 @@ const (
 	ErrInvalidQuerySignatureAlgo
 	ErrInvalidQueryParams
 	ErrBucketAlreadyOwnedByYou
+	ErrInvalidTrailer
+	ErrMalformedTrailer
+	ErrChecksumMismatch
+	ErrSlowDown
 	// Add new error codes here.
 
 	// Bucket notification related errors.
@@ -135,6 +141,51 @@ const (
 	ErrPolicyNesting
 	ErrInvalidObjectName
 	ErrServerNotInitialized
+	ErrMalformedJSON
+	ErrAdminConfigBadVersion
+	ErrAdminConfigBadSecretKey
+	ErrAdminConfigBadNotifyPassword
+	ErrInvalidArchive
+	ErrNoSuchTransformConfiguration
+	ErrInvalidTransformEndpoint
+	ErrTransformFailed
+	ErrNoSuchInventoryConfiguration
+	ErrInvalidInventoryConfiguration
+	ErrAdminInvalidAnonymousPolicy
+	ErrAccessKeyExpired
+	ErrAdminInvalidCredentialExpiry
+	ErrAuthThrottled
+	ErrAdminInvalidProfile
+	ErrAdminProfilerBusy
+	ErrAdminFsckInProgress
+	ErrComposeTooManySources
+	ErrInvalidCompressedObject
+	ErrAdminInvalidBatchJob
+	ErrAdminNoSuchBatchJob
+	ErrObjectNameCollision
+	ErrAdminEventJournalDisabled
+	ErrAdminInvalidEventQuery
+	ErrAdminInvalidEventTarget
+	ErrInvalidTag
+	ErrNoSuchVersion
+	ErrIllegalVersioningConfiguration
+	ErrNoSuchLifecycleConfiguration
+	ErrInvalidLifecycleConfiguration
+	ErrInsecureSSECustomerRequest
+	ErrInvalidEncryptionAlgorithm
+	ErrInvalidSSECustomerKey
+	ErrMissingSSECustomerKey
+	ErrSSECustomerKeyMD5Mismatch
+	ErrSSEEncryptedObject
+	ErrSSEKeyMismatch
+	ErrInvalidEncryptionParameters
+	ErrKMSNotConfigured
+	ErrMalformedPartNumber
+	ErrInvalidPartNumber
+	ErrInvalidRangePartNumber
+	ErrUnknownPartBoundaries
+	ErrServerReadOnly
+	ErrAdminKMSNotConfigured
 	// Add new extended error codes here.
 	// Please open a https://github.com/minio/minio/issues before adding
 	// new error codes here.
@@ -153,6 +204,16 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Copy Source must mention the source bucket and key: sourcebucket/sourcekey.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidCopyPartRange: {
+		Code:           "InvalidArgument",
+		Description:    "The x-amz-copy-source-range value must be of the form bytes=first-last where first and last are the zero-based offsets of the first and last bytes to copy",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidCopyPartRangeSource: {
+		Code:           "InvalidArgument",
+		Description:    "Range specified is not valid for source object",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidRequestBody: {
 		Code:           "InvalidArgument",
 		Description:    "Body shouldn't be set for this request.",
@@ -471,6 +532,26 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Your previous request to create the named bucket succeeded and you already own it.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrInvalidTrailer: {
+		Code:           "InvalidArgument",
+		Description:    "The x-amz-trailer header must name a supported x-amz-checksum-* header of a trailer streaming payload.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMalformedTrailer: {
+		Code:           "MalformedTrailerError",
+		Description:    "The request contained trailing data that was not well-formed or did not conform to our published schema.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrChecksumMismatch: {
+		Code:           "BadDigest",
+		Description:    "The checksum you specified did not match the calculated checksum.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSlowDown: {
+		Code:           "SlowDown",
+		Description:    "Please reduce your request rate.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
 
 	/// Bucket notification related errors.
 	ErrEventNotification: {
@@ -562,6 +643,231 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Server not initialized, please try again.",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrMalformedJSON: {
+		Code:           "XMinioMalformedJSON",
+		Description:    "The JSON you provided was not well-formed or did not validate against our published format.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminConfigBadVersion: {
+		Code:           "XMinioAdminConfigBadVersion",
+		Description:    "The config version you provided does not match the version supported by the server.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminConfigBadSecretKey: {
+		Code:           "XMinioAdminConfigBadSecretKey",
+		Description:    "The secret key in the config you provided must be 8 to 40 characters in length.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminConfigBadNotifyPassword: {
+		Code:           "XMinioAdminConfigBadNotifyPassword",
+		Description:    "A redacted password in the config you provided is not the password of an existing notification target.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidArchive: {
+		Code:           "XMinioInvalidArchive",
+		Description:    "The object is not a valid zip or tar archive.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchTransformConfiguration: {
+		Code:           "XMinioNoSuchTransformConfiguration",
+		Description:    "The bucket does not have a transform configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidTransformEndpoint: {
+		Code:           "XMinioInvalidTransformEndpoint",
+		Description:    "The transform endpoint must be an absolute http or https URL.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrTransformFailed: {
+		Code:           "XMinioTransformFailed",
+		Description:    "The transform endpoint of the bucket failed to transform the object.",
+		HTTPStatusCode: http.StatusBadGateway,
+	},
+	ErrNoSuchInventoryConfiguration: {
+		Code:           "NoSuchConfiguration",
+		Description:    "The specified configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidInventoryConfiguration: {
+		Code:           "InvalidArgument",
+		Description:    "The inventory configuration must specify a destination bucket, a Daily or Weekly schedule and the CSV format.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminInvalidAnonymousPolicy: {
+		Code:           "XMinioAdminInvalidAnonymousPolicy",
+		Description:    "The anonymous policy must be one of none, download, upload or public.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAccessKeyExpired: {
+		Code:           "XMinioAccessKeyExpired",
+		Description:    "The access key ID you provided has expired.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrAdminInvalidCredentialExpiry: {
+		Code:           "XMinioAdminInvalidCredentialExpiry",
+		Description:    "The expiry must be a future RFC3339 time and the grace period a non-negative duration.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAuthThrottled: {
+		Code:           "SlowDown",
+		Description:    "Too many failed authentication attempts, please try again later.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrAdminInvalidProfile: {
+		Code:           "XMinioAdminInvalidProfile",
+		Description:    "The requested profile is unknown or its parameters are not valid, the duration must be between 1s and 5m.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminProfilerBusy: {
+		Code:           "XMinioAdminProfilerBusy",
+		Description:    "A profile is already being captured, please try again later.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrAdminFsckInProgress: {
+		Code:           "XMinioAdminFsckInProgress",
+		Description:    "A consistency check is already in progress, please try again later.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrComposeTooManySources: {
+		Code:           "XMinioComposeTooManySources",
+		Description:    "A compose request can concatenate at most 32 source objects.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidCompressedObject: {
+		Code:           "XMinioInvalidCompressedObject",
+		Description:    "The object is stored with Content-Encoding gzip but is not valid gzip compressed data.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminInvalidBatchJob: {
+		Code:           "XMinioAdminInvalidBatchJob",
+		Description:    "The batch job needs a copy or delete operation, a valid bucket and, for copies, a target outside the source prefix.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminNoSuchBatchJob: {
+		Code:           "XMinioAdminNoSuchBatchJob",
+		Description:    "The specified batch job does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrObjectNameCollision: {
+		Code:           "XMinioObjectNameCollision",
+		Description:    "Object name differs only in case from an existing object, which the backend does not distinguish.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrAdminEventJournalDisabled: {
+		Code:           "XMinioAdminEventJournalDisabled",
+		Description:    "Bucket events are not recorded, set MINIO_EVENT_JOURNAL_RETENTION to enable the event journal.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrAdminInvalidEventQuery: {
+		Code:           "XMinioAdminInvalidEventQuery",
+		Description:    "Recorded events are selected by IDs or an RFC3339 time range starting before it ends, with an optional maximum of at most 10000.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminInvalidEventTarget: {
+		Code:           "XMinioAdminInvalidEventTarget",
+		Description:    "The specified ARN is not a configured notification target.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidTag: {
+		Code:           "InvalidTag",
+		Description:    "An object has at most 10 tags with unique keys of 1 to 128 characters and values of at most 256 characters.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchVersion: {
+		Code:           "NoSuchVersion",
+		Description:    "The specified version does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrIllegalVersioningConfiguration: {
+		Code:           "IllegalVersioningConfigurationException",
+		Description:    "The versioning configuration specified in the request is invalid, the status should be Enabled or Suspended.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchLifecycleConfiguration: {
+		Code:           "NoSuchLifecycleConfiguration",
+		Description:    "The lifecycle configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidLifecycleConfiguration: {
+		Code:           "InvalidArgument",
+		Description:    "Every lifecycle rule needs a unique ID, an Enabled or Disabled status and an expiration after a positive number of days or at a date, or an abort of multipart uploads after a positive number of days.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInsecureSSECustomerRequest: {
+		Code:           "InvalidRequest",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must be made over a secure connection.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidEncryptionAlgorithm: {
+		Code:           "InvalidEncryptionAlgorithmError",
+		Description:    "The encryption request you specified is not valid. The valid value is AES256.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidSSECustomerKey: {
+		Code:           "InvalidArgument",
+		Description:    "The secret key was invalid for the specified algorithm.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMissingSSECustomerKey: {
+		Code:           "InvalidArgument",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must provide an appropriate secret key.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSECustomerKeyMD5Mismatch: {
+		Code:           "InvalidArgument",
+		Description:    "The calculated MD5 hash of the key did not match the hash that was provided.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSEEncryptedObject: {
+		Code:           "InvalidRequest",
+		Description:    "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSEKeyMismatch: {
+		Code:           "AccessDenied",
+		Description:    "The provided encryption key does not match the key the object was encrypted with.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrInvalidEncryptionParameters: {
+		Code:           "InvalidRequest",
+		Description:    "The encryption parameters are not applicable to this object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrKMSNotConfigured: {
+		Code:           "NotImplemented",
+		Description:    "The object is encrypted at rest and no KMS is configured.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrAdminKMSNotConfigured: {
+		Code:           "XMinioAdminKMSNotConfigured",
+		Description:    "Objects are not encrypted at rest, set MINIO_KMS_MASTER_KEY or MINIO_KMS_VAULT_* to configure a KMS.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrMalformedPartNumber: {
+		Code:           "InvalidArgument",
+		Description:    "Part number must be an integer between 1 and 10000, inclusive.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidPartNumber: {
+		Code:           "InvalidPartNumber",
+		Description:    "The requested partnumber is not satisfiable.",
+		HTTPStatusCode: http.StatusRequestedRangeNotSatisfiable,
+	},
+	ErrInvalidRangePartNumber: {
+		Code:           "InvalidRequest",
+		Description:    "Cannot specify both Range header and partNumber query parameter.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrUnknownPartBoundaries: {
+		Code:           "NotImplemented",
+		Description:    "The part boundaries of this object are not known, it can only be read whole or by range.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrServerReadOnly: {
+		Code:           "XMinioServerReadOnly",
+		Description:    "The server is read-only, only reads and listings are served.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 	// Add your error structure here.
 }
 
@@ -580,6 +886,62 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrSignatureDoesNotMatch
 	case errContentSHA256Mismatch:
 		apiErr = ErrContentSHA256Mismatch
+	case errMalformedTrailer:
+		apiErr = ErrMalformedTrailer
+	case errChecksumMismatch:
+		apiErr = ErrChecksumMismatch
+	case errInvalidArchive:
+		apiErr = ErrInvalidArchive
+	case errInvalidCompressedObject:
+		apiErr = ErrInvalidCompressedObject
+	case errNoSuchTransform:
+		apiErr = ErrNoSuchTransformConfiguration
+	case errTransformFailed:
+		apiErr = ErrTransformFailed
+	case errNoSuchInventory:
+		apiErr = ErrNoSuchInventoryConfiguration
+	case errInvalidAnonymousPolicy:
+		apiErr = ErrAdminInvalidAnonymousPolicy
+	case errProfilerBusy:
+		apiErr = ErrAdminProfilerBusy
+	case errFsckInProgress:
+		apiErr = ErrAdminFsckInProgress
+	case errInvalidBatchJob:
+		apiErr = ErrAdminInvalidBatchJob
+	case errNoSuchBatchJob:
+		apiErr = ErrAdminNoSuchBatchJob
+	case errEventJournalDisabled:
+		apiErr = ErrAdminEventJournalDisabled
+	case errInvalidEventQuery:
+		apiErr = ErrAdminInvalidEventQuery
+	case errInvalidEventTarget:
+		apiErr = ErrAdminInvalidEventTarget
+	case errInvalidTag:
+		apiErr = ErrInvalidTag
+	case errNoSuchVersion:
+		apiErr = ErrNoSuchVersion
+	case errNoSuchLifecycle:
+		apiErr = ErrNoSuchLifecycleConfiguration
+	case errInsecureSSECustomerRequest:
+		apiErr = ErrInsecureSSECustomerRequest
+	case errInvalidEncryptionAlgorithm:
+		apiErr = ErrInvalidEncryptionAlgorithm
+	case errInvalidSSECustomerKey:
+		apiErr = ErrInvalidSSECustomerKey
+	case errMissingSSECustomerKey:
+		apiErr = ErrMissingSSECustomerKey
+	case errSSECustomerKeyMD5Mismatch:
+		apiErr = ErrSSECustomerKeyMD5Mismatch
+	case errEncryptedObject:
+		apiErr = ErrSSEEncryptedObject
+	case errSSEKeyMismatch:
+		apiErr = ErrSSEKeyMismatch
+	case errInvalidEncryptionParameters:
+		apiErr = ErrInvalidEncryptionParameters
+	case errKMSNotConfigured:
+		apiErr = ErrKMSNotConfigured
+	case errServerReadOnly:
+		apiErr = ErrServerReadOnly
 	}
 
 	if apiErr != ErrNone {
@@ -596,6 +958,8 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrIncompleteBody
 	case ObjectExistsAsDirectory:
 		apiErr = ErrObjectExistsAsDirectory
+	case ObjectNameCollision:
+		apiErr = ErrObjectNameCollision
 	case PrefixAccessDenied:
 		apiErr = ErrAccessDenied
 	case BucketNameInvalid:
@@ -634,6 +998,8 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrEntityTooLarge
 	case ObjectTooSmall:
 		apiErr = ErrEntityTooSmall
+	case NotImplemented:
+		apiErr = ErrNotImplemented
 	default:
 		apiErr = ErrInternalError
 	}
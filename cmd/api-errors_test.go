@@ -39,6 +39,10 @@ func TestAPIErrCode(t *testing.T) {
 			ObjectExistsAsDirectory{},
 			ErrObjectExistsAsDirectory,
 		},
+		{
+			ObjectNameCollision{},
+			ErrObjectNameCollision,
+		},
 		{
 			BucketNameInvalid{},
 			ErrInvalidBucketName,
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"runtime"
 	"strconv"
+	"strings"
 )
 
 // Static alphanumeric table used for generating unique request ids
@@ -74,6 +75,9 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 
 	// Set all other user defined metadata.
 	for k, v := range objInfo.UserDefined {
+		if strings.HasPrefix(k, internalMetadataPrefix) {
+			continue
+		}
 		w.Header().Set(k, v)
 	}
 
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"encoding/xml"
+	"io"
 	"net/http"
 	"path"
 	"time"
@@ -205,6 +206,23 @@ type CopyObjectResponse struct {
 	ETag         string   // md5sum of the copied object.
 }
 
+// PostResponse container returns the location of an object created by a POST
+// form upload asking for success_action_status 201.
+type PostResponse struct {
+	XMLName  xml.Name `xml:"PostResponse" json:"-"`
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+// CopyObjectPartResponse container returns ETag and LastModified of the successfully copied object part
+type CopyObjectPartResponse struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyPartResult" json:"-"`
+	LastModified string   // time string of format "2006-01-02T15:04:05.000Z"
+	ETag         string   // md5sum of the copied object part.
+}
+
 // Initiator inherit from Owner struct, fields are same
 type Initiator Owner
 
@@ -284,97 +302,124 @@ func generateListBucketsResponse(buckets []BucketInfo) ListBucketsResponse {
 	return data
 }
 
-// generates an ListObjectsV1 response for the said bucket with other enumerated options.
-func generateListObjectsV1Response(bucket, prefix, marker, delimiter string, maxKeys int, resp ListObjectsInfo) ListObjectsResponse {
-	var contents []Object
-	var prefixes []CommonPrefix
-	var owner = Owner{}
-	var data = ListObjectsResponse{}
+// Namespace of the S3 API XML documents.
+const s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
 
-	owner.ID = "minio"
-	owner.DisplayName = "minio"
+// listObjectsEncoder - streams a ListBucketResult document, entries
+// are encoded one at a time instead of building the whole response.
+// The first error is kept and returned by close.
+type listObjectsEncoder struct {
+	enc   *xml.Encoder
+	owner Owner
+	err   error
+}
 
-	for _, object := range resp.Objects {
-		var content = Object{}
-		if object.Name == "" {
-			continue
-		}
-		content.Key = object.Name
-		content.LastModified = object.ModTime.UTC().Format(timeFormatAMZLong)
-		if object.MD5Sum != "" {
-			content.ETag = "\"" + object.MD5Sum + "\""
-		}
-		content.Size = object.Size
-		content.StorageClass = "STANDARD"
-		content.Owner = owner
-		contents = append(contents, content)
+// newListObjectsEncoder - writes the XML header and the opening
+// ListBucketResult element.
+func newListObjectsEncoder(w io.Writer, owner Owner) *listObjectsEncoder {
+	e := &listObjectsEncoder{enc: xml.NewEncoder(w), owner: owner}
+	_, e.err = io.WriteString(w, xml.Header)
+	if e.err == nil {
+		e.err = e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Space: s3XMLNamespace, Local: "ListBucketResult"}})
 	}
-	// TODO - support EncodingType in xml decoding
-	data.Name = bucket
-	data.Contents = contents
+	return e
+}
 
-	data.Prefix = prefix
-	data.Marker = marker
-	data.Delimiter = delimiter
-	data.MaxKeys = maxKeys
+// element - encodes a field of the response, empty strings are
+// skipped if omitEmpty is set.
+func (e *listObjectsEncoder) element(name string, value interface{}, omitEmpty bool) {
+	if e.err != nil || (omitEmpty && value == "") {
+		return
+	}
+	e.err = e.enc.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// entry - encodes an object as Contents, or a common prefix as
+// CommonPrefixes.
+func (e *listObjectsEncoder) entry(objInfo ObjectInfo) {
+	if objInfo.IsDir {
+		e.element("CommonPrefixes", CommonPrefix{Prefix: objInfo.Name}, false)
+		return
+	}
+	content := Object{
+		Key:          objInfo.Name,
+		LastModified: objInfo.ModTime.UTC().Format(timeFormatAMZLong),
+		Size:         objInfo.Size,
+		Owner:        e.owner,
+		StorageClass: "STANDARD",
+	}
+	if objInfo.MD5Sum != "" {
+		content.ETag = "\"" + objInfo.MD5Sum + "\""
+	}
+	e.element("Contents", content, false)
+}
 
-	data.NextMarker = resp.NextMarker
-	data.IsTruncated = resp.IsTruncated
+// entries - encodes the objects of a listing page followed by its
+// common prefixes.
+func (e *listObjectsEncoder) entries(bucket string, resp ListObjectsInfo) {
+	for _, objInfo := range resp.Objects {
+		if objInfo.Name != "" {
+			e.entry(objInfo)
+		}
+	}
 	for _, prefix := range resp.Prefixes {
-		var prefixItem = CommonPrefix{}
-		prefixItem.Prefix = prefix
-		prefixes = append(prefixes, prefixItem)
+		e.entry(ObjectInfo{Bucket: bucket, Name: prefix, IsDir: true})
 	}
-	data.CommonPrefixes = prefixes
-	return data
 }
 
-// generates an ListObjectsV2 response for the said bucket with other enumerated options.
-func generateListObjectsV2Response(bucket, prefix, token, startAfter, delimiter string, fetchOwner bool, maxKeys int, resp ListObjectsInfo) ListObjectsV2Response {
-	var contents []Object
-	var prefixes []CommonPrefix
-	var owner = Owner{}
-	var data = ListObjectsV2Response{}
+// close - ends the ListBucketResult element and flushes the output.
+func (e *listObjectsEncoder) close() error {
+	if e.err == nil {
+		e.err = e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Space: s3XMLNamespace, Local: "ListBucketResult"}})
+	}
+	if e.err == nil {
+		e.err = e.enc.Flush()
+	}
+	return e.err
+}
 
+// writeListObjectsV1Response - streams a ListObjectsV1 response for
+// the said bucket with other enumerated options.
+func writeListObjectsV1Response(w io.Writer, bucket, prefix, marker, delimiter string, maxKeys int, resp ListObjectsInfo) error {
+	e := newListObjectsEncoder(w, Owner{ID: "minio", DisplayName: "minio"})
+	// TODO - support EncodingType in xml decoding
+	e.element("Name", bucket, false)
+	e.element("Prefix", prefix, false)
+	e.element("Marker", marker, false)
+	e.element("NextMarker", resp.NextMarker, true)
+	e.element("MaxKeys", maxKeys, false)
+	e.element("Delimiter", delimiter, false)
+	e.element("IsTruncated", resp.IsTruncated, false)
+	e.entries(bucket, resp)
+	return e.close()
+}
+
+// writeListObjectsV2Response - streams a ListObjectsV2 response for
+// the said bucket with other enumerated options.
+func writeListObjectsV2Response(w io.Writer, bucket, prefix, token, startAfter, delimiter string, fetchOwner bool, maxKeys int, resp ListObjectsInfo) error {
+	var owner Owner
 	if fetchOwner {
-		owner.ID = "minio"
-		owner.DisplayName = "minio"
+		owner = Owner{ID: "minio", DisplayName: "minio"}
 	}
-
-	for _, object := range resp.Objects {
-		var content = Object{}
-		if object.Name == "" {
-			continue
-		}
-		content.Key = object.Name
-		content.LastModified = object.ModTime.UTC().Format(timeFormatAMZLong)
-		if object.MD5Sum != "" {
-			content.ETag = "\"" + object.MD5Sum + "\""
+	keyCount := len(resp.Prefixes)
+	for _, objInfo := range resp.Objects {
+		if objInfo.Name != "" {
+			keyCount++
 		}
-		content.Size = object.Size
-		content.StorageClass = "STANDARD"
-		content.Owner = owner
-		contents = append(contents, content)
 	}
+	e := newListObjectsEncoder(w, owner)
 	// TODO - support EncodingType in xml decoding
-	data.Name = bucket
-	data.Contents = contents
-
-	data.StartAfter = startAfter
-	data.Delimiter = delimiter
-	data.Prefix = prefix
-	data.MaxKeys = maxKeys
-	data.ContinuationToken = token
-	data.NextContinuationToken = resp.NextMarker
-	data.IsTruncated = resp.IsTruncated
-	for _, prefix := range resp.Prefixes {
-		var prefixItem = CommonPrefix{}
-		prefixItem.Prefix = prefix
-		prefixes = append(prefixes, prefixItem)
-	}
-	data.CommonPrefixes = prefixes
-	data.KeyCount = len(data.Contents) + len(data.CommonPrefixes)
-	return data
+	e.element("Name", bucket, false)
+	e.element("Prefix", prefix, false)
+	e.element("StartAfter", startAfter, true)
+	e.element("ContinuationToken", token, true)
+	e.element("NextContinuationToken", resp.NextMarker, true)
+	e.element("KeyCount", keyCount, false)
+	e.element("MaxKeys", maxKeys, false)
+	e.element("Delimiter", delimiter, false)
+	e.element("IsTruncated", resp.IsTruncated, false)
+	e.entries(bucket, resp)
+	return e.close()
 }
 
 // generates CopyObjectResponse from etag and lastModified time.
@@ -385,6 +430,14 @@ func generateCopyObjectResponse(etag string, lastModified time.Time) CopyObjectR
 	}
 }
 
+// generates CopyObjectPartResponse from etag and lastModified time.
+func generateCopyObjectPartResponse(etag string, lastModified time.Time) CopyObjectPartResponse {
+	return CopyObjectPartResponse{
+		ETag:         "\"" + etag + "\"",
+		LastModified: lastModified.UTC().Format(timeFormatAMZLong),
+	}
+}
+
 // generates InitiateMultipartUploadResponse for given bucket, key and uploadID.
 func generateInitiateMultipartUploadResponse(bucket, key, uploadID string) InitiateMultipartUploadResponse {
 	return InitiateMultipartUploadResponse{
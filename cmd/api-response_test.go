@@ -0,0 +1,84 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests streaming list objects responses.
+func TestWriteListObjectsResponse(t *testing.T) {
+	modTime := time.Date(2016, 11, 1, 10, 0, 0, 0, time.UTC)
+	resp := ListObjectsInfo{
+		IsTruncated: true,
+		NextMarker:  "dir/",
+		Objects: []ObjectInfo{
+			{Name: "a&b", ModTime: modTime, Size: 10, MD5Sum: "abcd"},
+			{Name: ""},
+		},
+		Prefixes: []string{"dir/"},
+	}
+	expectedContents := []Object{{
+		Key:          "a&b",
+		LastModified: "2016-11-01T10:00:00.000Z",
+		ETag:         "\"abcd\"",
+		Size:         10,
+		Owner:        Owner{ID: "minio", DisplayName: "minio"},
+		StorageClass: "STANDARD",
+	}}
+	expectedPrefixes := []CommonPrefix{{Prefix: "dir/"}}
+
+	var buf bytes.Buffer
+	if err := writeListObjectsV1Response(&buf, "bucket", "", "a", "/", 2, resp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header+`<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`) {
+		t.Fatalf("Unexpected response %s", buf.String())
+	}
+	v1 := ListObjectsResponse{}
+	if err := xml.Unmarshal(buf.Bytes(), &v1); err != nil {
+		t.Fatal(err)
+	}
+	if v1.Name != "bucket" || v1.Marker != "a" || v1.NextMarker != "dir/" || v1.MaxKeys != 2 || v1.Delimiter != "/" || !v1.IsTruncated {
+		t.Errorf("Unexpected V1 response %+v", v1)
+	}
+	if !reflect.DeepEqual(v1.Contents, expectedContents) || !reflect.DeepEqual(v1.CommonPrefixes, expectedPrefixes) {
+		t.Errorf("Unexpected V1 entries %+v %+v", v1.Contents, v1.CommonPrefixes)
+	}
+
+	buf.Reset()
+	if err := writeListObjectsV2Response(&buf, "bucket", "", "token", "", "/", false, 2, resp); err != nil {
+		t.Fatal(err)
+	}
+	v2 := ListObjectsV2Response{}
+	if err := xml.Unmarshal(buf.Bytes(), &v2); err != nil {
+		t.Fatal(err)
+	}
+	if v2.ContinuationToken != "token" || v2.NextContinuationToken != "dir/" || v2.KeyCount != 2 || !v2.IsTruncated {
+		t.Errorf("Unexpected V2 response %+v", v2)
+	}
+	// The owner is only returned with fetch-owner.
+	expectedContents[0].Owner = Owner{}
+	if !reflect.DeepEqual(v2.Contents, expectedContents) || !reflect.DeepEqual(v2.CommonPrefixes, expectedPrefixes) {
+		t.Errorf("Unexpected V2 entries %+v %+v", v2.Contents, v2.CommonPrefixes)
+	}
+}
@@ -40,18 +40,34 @@ func registerAPIRouter(mux *router.Router) {
 
 	// HeadObject
 	bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+	// CopyObjectPart
+	bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(api.CopyObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
 	// PutObjectPart
 	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
 	// ListObjectPxarts
 	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.ListObjectPartsHandler).Queries("uploadId", "{uploadId:.*}")
+	// GetObjectTorrent
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTorrentHandler).Queries("torrent", "")
+	// GetObjectArchive
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectArchiveHandler).Queries("archive", "{archive:.*}")
 	// CompleteMultipartUpload
 	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.CompleteMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
 	// NewMultipartUpload
 	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
+	// SelectObjectContent
+	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.SelectObjectContentHandler).Queries("select", "", "select-type", "2")
 	// AbortMultipartUpload
 	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+	// GetObjectTagging
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTaggingHandler).Queries("tagging", "")
+	// PutObjectTagging
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectTaggingHandler).Queries("tagging", "")
+	// DeleteObjectTagging
+	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectTaggingHandler).Queries("tagging", "")
 	// GetObject
 	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+	// ComposeObject
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.ComposeObjectHandler).Queries("compose", "")
 	// CopyObject
 	bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(api.CopyObjectHandler)
 	// PutObject
@@ -67,6 +83,14 @@ func registerAPIRouter(mux *router.Router) {
 	bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
 	// GetBucketNotification
 	bucket.Methods("GET").HandlerFunc(api.GetBucketNotificationHandler).Queries("notification", "")
+	// GetBucketTransform
+	bucket.Methods("GET").HandlerFunc(api.GetBucketTransformHandler).Queries("transform", "")
+	// GetBucketInventory
+	bucket.Methods("GET").HandlerFunc(api.GetBucketInventoryHandler).Queries("inventory", "")
+	// GetBucketLifecycle
+	bucket.Methods("GET").HandlerFunc(api.GetBucketLifecycleHandler).Queries("lifecycle", "")
+	// GetBucketVersioning
+	bucket.Methods("GET").HandlerFunc(api.GetBucketVersioningHandler).Queries("versioning", "")
 	// ListenBucketNotification
 	bucket.Methods("GET").HandlerFunc(api.ListenBucketNotificationHandler).Queries("events", "{events:.*}")
 	// ListMultipartUploads
@@ -79,6 +103,14 @@ func registerAPIRouter(mux *router.Router) {
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
 	// PutBucketNotification
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketNotificationHandler).Queries("notification", "")
+	// PutBucketTransform
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketTransformHandler).Queries("transform", "")
+	// PutBucketInventory
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketInventoryHandler).Queries("inventory", "")
+	// PutBucketLifecycle
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketLifecycleHandler).Queries("lifecycle", "")
+	// PutBucketVersioning
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketVersioningHandler).Queries("versioning", "")
 	// PutBucket
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketHandler)
 	// HeadBucket
@@ -89,6 +121,12 @@ func registerAPIRouter(mux *router.Router) {
 	bucket.Methods("POST").HandlerFunc(api.DeleteMultipleObjectsHandler)
 	// DeleteBucketPolicy
 	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketPolicyHandler).Queries("policy", "")
+	// DeleteBucketTransform
+	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketTransformHandler).Queries("transform", "")
+	// DeleteBucketInventory
+	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketInventoryHandler).Queries("inventory", "")
+	// DeleteBucketLifecycle
+	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketLifecycleHandler).Queries("lifecycle", "")
 	// DeleteBucket
 	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketHandler)
 
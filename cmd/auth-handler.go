@@ -61,9 +61,10 @@ func isRequestPostPolicySignatureV4(r *http.Request) bool {
 	return strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") && r.Method == "POST"
 }
 
-// Verify if the request has AWS Streaming Signature Version '4'. This is only valid for 'PUT' operation.
+// Verify if the request has AWS Streaming Signature Version '4', with or
+// without trailing headers. This is only valid for 'PUT' operation.
 func isRequestSignStreamingV4(r *http.Request) bool {
-	return r.Header.Get("x-amz-content-sha256") == streamingContentSHA256 && r.Method == "PUT"
+	return isStreamingPayload(r.Header.Get("x-amz-content-sha256")) && r.Method == "PUT"
 }
 
 // Authorization type.
@@ -110,13 +111,17 @@ func checkRequestAuthType(r *http.Request, bucket, policyAction, region string)
 	switch reqAuthType {
 	case authTypePresignedV2, authTypeSignedV2:
 		// Signature V2 validation.
-		s3Error := isReqAuthenticatedV2(r)
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return isReqAuthenticatedV2(r)
+		})
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 		}
 		return s3Error
 	case authTypeSigned, authTypePresigned:
-		s3Error := isReqAuthenticated(r, region)
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return isReqAuthenticated(r, region)
+		})
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 		}
@@ -125,7 +130,7 @@ func checkRequestAuthType(r *http.Request, bucket, policyAction, region string)
 
 	if reqAuthType == authTypeAnonymous && policyAction != "" {
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		return enforceBucketPolicy(bucket, policyAction, r.URL)
+		return enforceBucketPolicy(bucket, policyAction, r)
 	}
 
 	// By default return ErrAccessDenied
@@ -315,7 +315,7 @@ func TestIsReqAuthenticated(t *testing.T) {
 	}
 	defer removeAll(path)
 
-	serverConfig.SetCredential(credential{"myuser", "mypassword"})
+	serverConfig.SetCredential(credential{AccessKeyID: "myuser", SecretAccessKey: "mypassword"})
 
 	// List of test cases for validating http request authentication.
 	testCases := []struct {
@@ -0,0 +1,297 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Failures older than this are forgotten.
+	authFailureWindow = 15 * time.Minute
+
+	// Failures allowed before responses to further failures are
+	// delayed, the delay doubles with each failure up to the maximum.
+	authFailureFreeAttempts = 5
+	authFailureBaseDelay    = 100 * time.Millisecond
+	authFailureMaxDelay     = 5 * time.Second
+
+	// Failures from a source IP after which all its requests are
+	// rejected for authFailureBlockDuration.
+	authFailureBlockAttempts = 20
+	authFailureBlockDuration = 15 * time.Minute
+
+	// Maximum number of tracked sources, new sources are not tracked
+	// while full.
+	maxAuthFailureSources = 10000
+)
+
+// authFailures - recent authentication failures of a source.
+type authFailures struct {
+	count        int
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// AuthThrottleStats - authentication throttling counters.
+type AuthThrottleStats struct {
+	// Failed authentication attempts.
+	Failures uint64 `json:"failures"`
+	// Requests rejected from blocked source IPs.
+	Rejected uint64 `json:"rejected"`
+	// Source IPs blocked at present.
+	BlockedSources int `json:"blockedSources"`
+}
+
+// authThrottle - tracks failed authentication attempts per source IP
+// and access key. Failures are answered with a growing delay and
+// source IPs with too many failures are blocked for a while. Access
+// keys are never blocked, so that a client guessing secret keys
+// cannot lock out the owner of the key.
+type authThrottle struct {
+	mu       sync.Mutex
+	sources  map[string]*authFailures
+	failures uint64
+	rejected uint64
+}
+
+// Global authentication throttle, nil until the server starts so
+// that tests calling the signature checks are not delayed.
+var globalAuthThrottle *authThrottle
+
+func newAuthThrottle() *authThrottle {
+	return &authThrottle{sources: make(map[string]*authFailures)}
+}
+
+// authThrottleKeys - returns the source IP and access key keys of a
+// request, the access key is empty if the request carries none.
+func authThrottleKeys(r *http.Request) (ipKey, accessKeyKey string) {
+	return authThrottleKeysOf(r.RemoteAddr, getRequestAccessKey(r))
+}
+
+// authThrottleKeysOf - returns the source IP and access key keys of an
+// attempt from remoteAddr with accessKey.
+func authThrottleKeysOf(remoteAddr, accessKey string) (ipKey, accessKeyKey string) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ipKey = "ip:" + host
+	if accessKey != "" {
+		accessKeyKey = "key:" + accessKey
+	}
+	return ipKey, accessKeyKey
+}
+
+// getRequestAccessKey - returns the access key a signed request
+// claims, without validating the signature.
+func getRequestAccessKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if strings.HasPrefix(auth, signV2Algorithm+" ") {
+			// "AWS accessKey:signature"
+			accessKey := strings.TrimPrefix(auth, signV2Algorithm+" ")
+			if i := strings.LastIndex(accessKey, ":"); i >= 0 {
+				return accessKey[:i]
+			}
+			return ""
+		}
+		// "AWS4-HMAC-SHA256 Credential=accessKey/date/region/s3/aws4_request, ..."
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			accessKey := auth[i+len("Credential="):]
+			if j := strings.Index(accessKey, "/"); j >= 0 {
+				return accessKey[:j]
+			}
+		}
+		return ""
+	}
+	query := r.URL.Query()
+	if accessKey := query.Get("AWSAccessKeyId"); accessKey != "" {
+		return accessKey
+	}
+	accessKey := query.Get("X-Amz-Credential")
+	if j := strings.Index(accessKey, "/"); j >= 0 {
+		return accessKey[:j]
+	}
+	return ""
+}
+
+// getPostPolicyAccessKey - returns the access key the form of a POST
+// policy request claims, without validating the signature.
+func getPostPolicyAccessKey(formValues map[string]string) string {
+	if accessKey := formValues["Awsaccesskeyid"]; accessKey != "" {
+		return accessKey
+	}
+	accessKey := formValues["X-Amz-Credential"]
+	if j := strings.Index(accessKey, "/"); j >= 0 {
+		return accessKey[:j]
+	}
+	return ""
+}
+
+// get - returns the unexpired failures of key, caller must hold the
+// lock.
+func (t *authThrottle) get(key string, now time.Time) *authFailures {
+	f, ok := t.sources[key]
+	if !ok {
+		return nil
+	}
+	if now.Sub(f.last) >= authFailureWindow && !now.Before(f.blockedUntil) {
+		delete(t.sources, key)
+		return nil
+	}
+	return f
+}
+
+// admit - returns false if the source IP is blocked.
+func (t *authThrottle) admit(ipKey string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if f := t.get(ipKey, now); f != nil && now.Before(f.blockedUntil) {
+		t.rejected++
+		return false
+	}
+	return true
+}
+
+// fail - records a failed attempt, returns how long the response
+// should be delayed.
+func (t *authThrottle) fail(ipKey, accessKeyKey string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.failures++
+	var delay time.Duration
+	for _, key := range []string{ipKey, accessKeyKey} {
+		if key == "" {
+			continue
+		}
+		f := t.get(key, now)
+		if f == nil {
+			if len(t.sources) >= maxAuthFailureSources {
+				t.purge(now)
+			}
+			if len(t.sources) >= maxAuthFailureSources {
+				continue
+			}
+			f = &authFailures{}
+			t.sources[key] = f
+		}
+		f.count++
+		f.last = now
+		if key == ipKey && f.count >= authFailureBlockAttempts {
+			f.blockedUntil = now.Add(authFailureBlockDuration)
+			f.count = 0
+		}
+		if d := authFailureDelay(f.count); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// reset - forgets the failures of a source IP after a successful
+// attempt, blocks are kept.
+func (t *authThrottle) reset(ipKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if f, ok := t.sources[ipKey]; ok {
+		f.count = 0
+	}
+}
+
+// purge - removes all expired sources, caller must hold the lock.
+func (t *authThrottle) purge(now time.Time) {
+	for key := range t.sources {
+		t.get(key, now)
+	}
+}
+
+// stats - returns the throttling counters.
+func (t *authThrottle) stats() AuthThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	stats := AuthThrottleStats{
+		Failures: t.failures,
+		Rejected: t.rejected,
+	}
+	for _, f := range t.sources {
+		if now.Before(f.blockedUntil) {
+			stats.BlockedSources++
+		}
+	}
+	return stats
+}
+
+// authFailureDelay - returns the delay after count failures.
+func authFailureDelay(count int) time.Duration {
+	if count <= authFailureFreeAttempts {
+		return 0
+	}
+	delay := authFailureBaseDelay
+	for i := authFailureFreeAttempts + 1; i < count && delay < authFailureMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > authFailureMaxDelay {
+		delay = authFailureMaxDelay
+	}
+	return delay
+}
+
+// isAuthFailure - is the error a wrong access or secret key.
+func isAuthFailure(s3Error APIErrorCode) bool {
+	switch s3Error {
+	case ErrSignatureDoesNotMatch, ErrInvalidAccessKeyID, ErrAccessKeyExpired:
+		return true
+	}
+	return false
+}
+
+// throttleAuth - verifies the request with verify unless its source
+// IP is blocked, failed attempts are delayed progressively.
+func throttleAuth(r *http.Request, verify func() APIErrorCode) APIErrorCode {
+	if globalAuthThrottle == nil {
+		return verify()
+	}
+	ipKey, accessKeyKey := authThrottleKeys(r)
+	return throttleAuthKeys(ipKey, accessKeyKey, verify)
+}
+
+// throttleAuthKeys - verifies an attempt with verify unless ipKey is
+// blocked, failed attempts are delayed progressively.
+func throttleAuthKeys(ipKey, accessKeyKey string, verify func() APIErrorCode) APIErrorCode {
+	if globalAuthThrottle == nil {
+		return verify()
+	}
+	if !globalAuthThrottle.admit(ipKey, time.Now().UTC()) {
+		return ErrAuthThrottled
+	}
+	s3Error := verify()
+	if isAuthFailure(s3Error) {
+		time.Sleep(globalAuthThrottle.fail(ipKey, accessKeyKey, time.Now().UTC()))
+	} else if s3Error == ErrNone {
+		globalAuthThrottle.reset(ipKey)
+	}
+	return s3Error
+}
@@ -0,0 +1,166 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Tests the delay after consecutive failures.
+func TestAuthFailureDelay(t *testing.T) {
+	testCases := []struct {
+		count int
+		delay time.Duration
+	}{
+		{1, 0},
+		{authFailureFreeAttempts, 0},
+		{authFailureFreeAttempts + 1, authFailureBaseDelay},
+		{authFailureFreeAttempts + 2, 2 * authFailureBaseDelay},
+		{authFailureFreeAttempts + 4, 8 * authFailureBaseDelay},
+		{authFailureBlockAttempts, authFailureMaxDelay},
+	}
+	for i, testCase := range testCases {
+		if delay := authFailureDelay(testCase.count); delay != testCase.delay {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.delay, delay)
+		}
+	}
+}
+
+// Tests blocking of source IPs and expiry of failures.
+func TestAuthThrottle(t *testing.T) {
+	throttle := newAuthThrottle()
+	now := time.Date(2016, 12, 1, 10, 0, 0, 0, time.UTC)
+	ipKey, accessKeyKey := "ip:192.0.2.1", "key:minio"
+
+	for i := 1; i < authFailureBlockAttempts; i++ {
+		throttle.fail(ipKey, accessKeyKey, now)
+	}
+	if !throttle.admit(ipKey, now) {
+		t.Fatalf("Expected source to be admitted before the last failure")
+	}
+	// Success forgets the failures of the source but not of the key.
+	throttle.reset(ipKey)
+	if delay := throttle.fail(ipKey, accessKeyKey, now); delay != authFailureMaxDelay {
+		t.Errorf("Expected delay %s from the access key failures, got %s", authFailureMaxDelay, delay)
+	}
+
+	for i := 1; i < authFailureBlockAttempts; i++ {
+		throttle.fail(ipKey, "", now)
+	}
+	if throttle.admit(ipKey, now) {
+		t.Fatalf("Expected source to be blocked")
+	}
+	// Access keys are never blocked.
+	if !throttle.admit(accessKeyKey, now) {
+		t.Errorf("Expected access key not to be blocked")
+	}
+	if throttle.admit(ipKey, now.Add(authFailureBlockDuration-time.Second)) {
+		t.Errorf("Expected source to be blocked until the block expires")
+	}
+	if !throttle.admit(ipKey, now.Add(authFailureBlockDuration)) {
+		t.Errorf("Expected source to be admitted after the block expires")
+	}
+
+	// Failures are forgotten after the window.
+	later := now.Add(authFailureBlockDuration + authFailureWindow)
+	if delay := throttle.fail(ipKey, accessKeyKey, later); delay != 0 {
+		t.Errorf("Expected no delay after the window, got %s", delay)
+	}
+
+	stats := throttle.stats()
+	if stats.Failures != 2*authFailureBlockAttempts || stats.Rejected != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// Tests extracting the access key of signed requests.
+func TestGetRequestAccessKey(t *testing.T) {
+	testCases := []struct {
+		auth      string
+		query     string
+		accessKey string
+	}{
+		{"AWS4-HMAC-SHA256 Credential=minio/20161201/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abcd", "", "minio"},
+		{"AWS minio:abcd", "", "minio"},
+		{"", "X-Amz-Credential=minio%2F20161201%2Fus-east-1%2Fs3%2Faws4_request&X-Amz-Signature=abcd", "minio"},
+		{"", "AWSAccessKeyId=minio&Signature=abcd", "minio"},
+		{"", "", ""},
+		{"Bearer token", "", ""},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest("GET", "http://localhost:9000/bucket?"+testCase.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.auth != "" {
+			req.Header.Set("Authorization", testCase.auth)
+		}
+		if accessKey := getRequestAccessKey(req); accessKey != testCase.accessKey {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.accessKey, accessKey)
+		}
+	}
+}
+
+// Tests extracting the access key of POST policy forms.
+func TestGetPostPolicyAccessKey(t *testing.T) {
+	testCases := []struct {
+		formValues map[string]string
+		accessKey  string
+	}{
+		{map[string]string{"X-Amz-Credential": "minio/20161201/us-east-1/s3/aws4_request"}, "minio"},
+		{map[string]string{"Awsaccesskeyid": "minio", "Signature": "abcd"}, "minio"},
+		{map[string]string{"X-Amz-Credential": "minio"}, ""},
+		{map[string]string{}, ""},
+	}
+	for i, testCase := range testCases {
+		if accessKey := getPostPolicyAccessKey(testCase.formValues); accessKey != testCase.accessKey {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.accessKey, accessKey)
+		}
+	}
+}
+
+// Tests that blocked sources are rejected before verification.
+func TestThrottleAuth(t *testing.T) {
+	globalAuthThrottle = newAuthThrottle()
+	defer func() { globalAuthThrottle = nil }()
+
+	req, err := http.NewRequest("GET", "http://localhost:9000/bucket", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	verified := 0
+	verify := func() APIErrorCode {
+		verified++
+		return ErrSignatureDoesNotMatch
+	}
+	if s3Error := throttleAuth(req, verify); s3Error != ErrSignatureDoesNotMatch {
+		t.Errorf("Expected ErrSignatureDoesNotMatch, got %d", s3Error)
+	}
+
+	ipKey, _ := authThrottleKeys(req)
+	globalAuthThrottle.sources[ipKey].blockedUntil = time.Now().UTC().Add(time.Minute)
+	if s3Error := throttleAuth(req, verify); s3Error != ErrAuthThrottled {
+		t.Errorf("Expected ErrAuthThrottled, got %d", s3Error)
+	}
+	if verified != 1 {
+		t.Errorf("Expected blocked request not to be verified")
+	}
+}
@@ -0,0 +1,405 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Number of finished jobs kept for their completion report.
+	maxFinishedBatchJobs = 100
+
+	// Number of failed objects listed in the report of a job.
+	maxBatchJobFailures = 1000
+
+	// Retries of an object operation unless given in the job.
+	defaultBatchJobRetries = 3
+
+	// Upper bound of the retries of an object operation.
+	maxBatchJobRetries = 10
+
+	// Delay before the first retry, doubled for every retry.
+	batchJobRetryDelay = 100 * time.Millisecond
+)
+
+// Operations applied by batch jobs.
+const (
+	batchJobCopy   = "copy"
+	batchJobDelete = "delete"
+)
+
+// States of a batch job.
+const (
+	batchJobRunning   = "running"
+	batchJobCompleted = "completed"
+	batchJobFailed    = "failed"
+	batchJobCancelled = "cancelled"
+)
+
+// BatchJobFilter - selects the objects a batch job applies to, empty
+// fields match every object.
+type BatchJobFilter struct {
+	Suffix         string    `json:"suffix,omitempty"`
+	MinSize        int64     `json:"minSize,omitempty"`
+	MaxSize        int64     `json:"maxSize,omitempty"`
+	ModifiedBefore time.Time `json:"modifiedBefore,omitempty"`
+	ModifiedAfter  time.Time `json:"modifiedAfter,omitempty"`
+}
+
+// BatchJobRequest - description of a batch job, the operation is
+// applied to every object under prefix matched by the filter.
+type BatchJobRequest struct {
+	Operation string         `json:"operation"`
+	Bucket    string         `json:"bucket"`
+	Prefix    string         `json:"prefix,omitempty"`
+	Filter    BatchJobFilter `json:"filter"`
+	// Destination of copies, the prefix replaces the source prefix.
+	TargetBucket string `json:"targetBucket,omitempty"`
+	TargetPrefix string `json:"targetPrefix,omitempty"`
+	// Retries of a failed object operation.
+	Retries *int `json:"retries,omitempty"`
+}
+
+// BatchJobFailure - an object the operation failed for.
+type BatchJobFailure struct {
+	Object string `json:"object"`
+	Error  string `json:"error"`
+}
+
+// BatchJobStatus - progress of a batch job, its completion report
+// once finished.
+type BatchJobStatus struct {
+	ID        string            `json:"id"`
+	Request   BatchJobRequest   `json:"request"`
+	State     string            `json:"state"`
+	Started   time.Time         `json:"started"`
+	Finished  time.Time         `json:"finished,omitempty"`
+	Scanned   int64             `json:"scanned"`
+	Matched   int64             `json:"matched"`
+	Succeeded int64             `json:"succeeded"`
+	Failed    int64             `json:"failed"`
+	Bytes     int64             `json:"bytes"`
+	Failures  []BatchJobFailure `json:"failures,omitempty"`
+	// Reason of a failed job.
+	Error string `json:"error,omitempty"`
+}
+
+// matches - returns true if the object is selected by the filter.
+func (filter BatchJobFilter) matches(objInfo ObjectInfo) bool {
+	if !strings.HasSuffix(objInfo.Name, filter.Suffix) {
+		return false
+	}
+	if objInfo.Size < filter.MinSize {
+		return false
+	}
+	if filter.MaxSize > 0 && objInfo.Size > filter.MaxSize {
+		return false
+	}
+	if !filter.ModifiedBefore.IsZero() && !objInfo.ModTime.Before(filter.ModifiedBefore) {
+		return false
+	}
+	if !filter.ModifiedAfter.IsZero() && !objInfo.ModTime.After(filter.ModifiedAfter) {
+		return false
+	}
+	return true
+}
+
+// validate - checks the job description, fills in the defaults.
+func (req *BatchJobRequest) validate() error {
+	if !IsValidBucketName(req.Bucket) {
+		return errInvalidBatchJob
+	}
+	if req.Prefix != "" && !IsValidObjectPrefix(req.Prefix) {
+		return errInvalidBatchJob
+	}
+	if req.Filter.MinSize < 0 || req.Filter.MaxSize < 0 {
+		return errInvalidBatchJob
+	}
+	if req.Filter.MaxSize > 0 && req.Filter.MinSize > req.Filter.MaxSize {
+		return errInvalidBatchJob
+	}
+	if req.Retries == nil {
+		retries := defaultBatchJobRetries
+		req.Retries = &retries
+	}
+	if *req.Retries < 0 || *req.Retries > maxBatchJobRetries {
+		return errInvalidBatchJob
+	}
+	switch req.Operation {
+	case batchJobCopy:
+		if !IsValidBucketName(req.TargetBucket) {
+			return errInvalidBatchJob
+		}
+		if req.TargetPrefix != "" && !IsValidObjectPrefix(req.TargetPrefix) {
+			return errInvalidBatchJob
+		}
+		// Copies would be listed again as sources.
+		if req.TargetBucket == req.Bucket && strings.HasPrefix(req.TargetPrefix, req.Prefix) {
+			return errInvalidBatchJob
+		}
+	case batchJobDelete:
+		if req.TargetBucket != "" || req.TargetPrefix != "" {
+			return errInvalidBatchJob
+		}
+	default:
+		return errInvalidBatchJob
+	}
+	return nil
+}
+
+// batchJob - a batch job running or finished.
+type batchJob struct {
+	mutex     sync.Mutex
+	status    BatchJobStatus
+	cancelled bool
+}
+
+// getStatus - returns a copy of the job status.
+func (job *batchJob) getStatus() BatchJobStatus {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	status := job.status
+	status.Failures = append([]BatchJobFailure(nil), job.status.Failures...)
+	return status
+}
+
+// isCancelled - returns true if the job was asked to stop.
+func (job *batchJob) isCancelled() bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return job.cancelled
+}
+
+// isFinished - returns true if the job is no longer running.
+func (job *batchJob) isFinished() bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return job.status.State != batchJobRunning
+}
+
+// finish - records the final state of the job.
+func (job *batchJob) finish(state string, err error) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	job.status.State = state
+	job.status.Finished = time.Now().UTC()
+	if err != nil {
+		job.status.Error = err.Error()
+	}
+}
+
+// record - updates the progress of the job after an object.
+func (job *batchJob) record(objInfo ObjectInfo, matched bool, err error) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	job.status.Scanned++
+	if !matched {
+		return
+	}
+	job.status.Matched++
+	if err != nil {
+		job.status.Failed++
+		if len(job.status.Failures) < maxBatchJobFailures {
+			job.status.Failures = append(job.status.Failures, BatchJobFailure{
+				Object: objInfo.Name,
+				Error:  errorCause(err).Error(),
+			})
+		}
+		return
+	}
+	job.status.Succeeded++
+	job.status.Bytes += objInfo.Size
+}
+
+// copyObject - copies an object with its metadata to the target of the job.
+func (job *batchJob) copyObject(objAPI ObjectLayer, objInfo ObjectInfo) error {
+	req := job.status.Request
+	target := req.TargetPrefix + strings.TrimPrefix(objInfo.Name, req.Prefix)
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(objAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, pipeWriter))
+	}()
+	metadata := make(map[string]string, len(objInfo.UserDefined)+1)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	// The ETag of a multipart object is not the MD5 sum of its data.
+	delete(metadata, "md5Sum")
+	if !strings.Contains(objInfo.MD5Sum, "-") {
+		metadata["md5Sum"] = objInfo.MD5Sum
+	}
+	_, err := objAPI.PutObject(req.TargetBucket, target, objInfo.Size, pipeReader, metadata, "")
+	pipeReader.CloseWithError(err)
+	return err
+}
+
+// apply - applies the operation of the job to an object, retrying
+// unless the object is gone.
+func (job *batchJob) apply(objAPI ObjectLayer, objInfo ObjectInfo) (err error) {
+	retries := *job.status.Request.Retries
+	delay := batchJobRetryDelay
+	for attempt := 0; ; attempt++ {
+		switch job.status.Request.Operation {
+		case batchJobCopy:
+			err = job.copyObject(objAPI, objInfo)
+		case batchJobDelete:
+			err = objAPI.DeleteObject(objInfo.Bucket, objInfo.Name)
+		}
+		if err == nil || isErrObjectNotFound(err) || attempt >= retries || job.isCancelled() {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// run - applies the operation to all matching objects, stops at the
+// first listing error.
+func (job *batchJob) run(objAPI ObjectLayer) {
+	req := job.status.Request
+	lister := newObjectLister(objAPI, req.Bucket, req.Prefix, "", "")
+	for {
+		objInfo, err := lister.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errorIf(err, "Unable to list objects of batch job %s.", job.status.ID)
+			job.finish(batchJobFailed, errorCause(err))
+			return
+		}
+		if job.isCancelled() {
+			job.finish(batchJobCancelled, nil)
+			return
+		}
+		if !req.Filter.matches(objInfo) {
+			job.record(objInfo, false, nil)
+			continue
+		}
+		job.record(objInfo, true, job.apply(objAPI, objInfo))
+	}
+	job.finish(batchJobCompleted, nil)
+}
+
+// batchJobs - the batch jobs of this server, finished jobs are
+// forgotten on restart.
+type batchJobs struct {
+	mutex sync.Mutex
+	jobs  map[string]*batchJob
+	// Job IDs in the order the jobs were started.
+	order []string
+}
+
+func newBatchJobs() *batchJobs {
+	return &batchJobs{jobs: make(map[string]*batchJob)}
+}
+
+// Batch jobs submitted through the admin API.
+var globalBatchJobs = newBatchJobs()
+
+// Start - validates a job description and runs the job in the background.
+func (b *batchJobs) Start(objAPI ObjectLayer, req BatchJobRequest) (BatchJobStatus, error) {
+	if err := req.validate(); err != nil {
+		return BatchJobStatus{}, err
+	}
+	if _, err := objAPI.GetBucketInfo(req.Bucket); err != nil {
+		return BatchJobStatus{}, err
+	}
+	if req.Operation == batchJobCopy {
+		if _, err := objAPI.GetBucketInfo(req.TargetBucket); err != nil {
+			return BatchJobStatus{}, err
+		}
+	}
+
+	job := &batchJob{status: BatchJobStatus{
+		ID:      mustGetUUID(),
+		Request: req,
+		State:   batchJobRunning,
+		Started: time.Now().UTC(),
+	}}
+
+	b.mutex.Lock()
+	b.jobs[job.status.ID] = job
+	b.order = append(b.order, job.status.ID)
+	b.evictFinished()
+	b.mutex.Unlock()
+
+	go job.run(objAPI)
+	return job.getStatus(), nil
+}
+
+// evictFinished - forgets the oldest finished jobs beyond
+// maxFinishedBatchJobs, must be called with the lock held.
+func (b *batchJobs) evictFinished() {
+	finished := 0
+	for _, id := range b.order {
+		if b.jobs[id].isFinished() {
+			finished++
+		}
+	}
+	order := b.order[:0]
+	for _, id := range b.order {
+		if finished > maxFinishedBatchJobs && b.jobs[id].isFinished() {
+			delete(b.jobs, id)
+			finished--
+			continue
+		}
+		order = append(order, id)
+	}
+	b.order = order
+}
+
+// Get - returns the status of a job.
+func (b *batchJobs) Get(id string) (BatchJobStatus, error) {
+	b.mutex.Lock()
+	job, ok := b.jobs[id]
+	b.mutex.Unlock()
+	if !ok {
+		return BatchJobStatus{}, errNoSuchBatchJob
+	}
+	return job.getStatus(), nil
+}
+
+// List - returns the status of all jobs, oldest first.
+func (b *batchJobs) List() []BatchJobStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	statuses := make([]BatchJobStatus, 0, len(b.order))
+	for _, id := range b.order {
+		statuses = append(statuses, b.jobs[id].getStatus())
+	}
+	return statuses
+}
+
+// Cancel - stops a running job after the object in progress.
+func (b *batchJobs) Cancel(id string) error {
+	b.mutex.Lock()
+	job, ok := b.jobs[id]
+	b.mutex.Unlock()
+	if !ok {
+		return errNoSuchBatchJob
+	}
+	job.mutex.Lock()
+	job.cancelled = true
+	job.mutex.Unlock()
+	return nil
+}
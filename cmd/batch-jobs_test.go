@@ -0,0 +1,78 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests selecting objects with a batch job filter.
+func TestBatchJobFilterMatches(t *testing.T) {
+	modTime := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	objInfo := ObjectInfo{Name: "logs/a.log", Size: 100, ModTime: modTime}
+	testCases := []struct {
+		filter   BatchJobFilter
+		expected bool
+	}{
+		{BatchJobFilter{}, true},
+		{BatchJobFilter{Suffix: ".log"}, true},
+		{BatchJobFilter{Suffix: ".txt"}, false},
+		{BatchJobFilter{MinSize: 100, MaxSize: 100}, true},
+		{BatchJobFilter{MinSize: 101}, false},
+		{BatchJobFilter{MaxSize: 99}, false},
+		{BatchJobFilter{ModifiedBefore: modTime.Add(time.Hour)}, true},
+		{BatchJobFilter{ModifiedBefore: modTime}, false},
+		{BatchJobFilter{ModifiedAfter: modTime.Add(-time.Hour)}, true},
+		{BatchJobFilter{ModifiedAfter: modTime}, false},
+	}
+	for i, testCase := range testCases {
+		if matches := testCase.filter.matches(objInfo); matches != testCase.expected {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, matches)
+		}
+	}
+}
+
+// Tests validating batch job descriptions.
+func TestBatchJobRequestValidate(t *testing.T) {
+	negative, tooMany := -1, maxBatchJobRetries+1
+	testCases := []struct {
+		req     BatchJobRequest
+		success bool
+	}{
+		{BatchJobRequest{Operation: "delete", Bucket: "bucket"}, true},
+		{BatchJobRequest{Operation: "copy", Bucket: "bucket", TargetBucket: "target"}, true},
+		{BatchJobRequest{Operation: "copy", Bucket: "bucket", Prefix: "a/", TargetBucket: "bucket", TargetPrefix: "b/"}, true},
+		{BatchJobRequest{Operation: "copy", Bucket: "bucket", Prefix: "a/", TargetBucket: "bucket", TargetPrefix: "a/b/"}, false},
+		{BatchJobRequest{Operation: "copy", Bucket: "bucket"}, false},
+		{BatchJobRequest{Operation: "delete", Bucket: "bucket", TargetBucket: "target"}, false},
+		{BatchJobRequest{Operation: "tag", Bucket: "bucket"}, false},
+		{BatchJobRequest{Operation: "delete", Bucket: "b"}, false},
+		{BatchJobRequest{Operation: "delete", Bucket: "bucket", Filter: BatchJobFilter{MinSize: 10, MaxSize: 5}}, false},
+		{BatchJobRequest{Operation: "delete", Bucket: "bucket", Retries: &negative}, false},
+		{BatchJobRequest{Operation: "delete", Bucket: "bucket", Retries: &tooMany}, false},
+	}
+	for i, testCase := range testCases {
+		err := testCase.req.validate()
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if err == nil && *testCase.req.Retries != defaultBatchJobRetries {
+			t.Errorf("Test %d: Expected %d retries by default, got %d", i+1, defaultBatchJobRetries, *testCase.req.Retries)
+		}
+	}
+}
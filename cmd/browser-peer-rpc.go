@@ -49,6 +49,10 @@ type SetAuthPeerArgs struct {
 
 	// New credentials that receiving peer should update to.
 	Creds credential
+
+	// Credentials replaced by a rotation which stay valid until
+	// their expiration, nil if none.
+	PreviousCreds *credential
 }
 
 // SetAuthPeer - Update to new credentials sent from a peer Minio
@@ -66,6 +70,7 @@ func (br *browserPeerAPIHandlers) SetAuthPeer(args SetAuthPeerArgs, reply *Gener
 
 	// Update credentials in memory
 	serverConfig.SetCredential(args.Creds)
+	serverConfig.SetPreviousCredential(args.PreviousCreds)
 
 	// Save credentials to config file
 	if err := serverConfig.Save(); err != nil {
@@ -77,7 +82,7 @@ func (br *browserPeerAPIHandlers) SetAuthPeer(args SetAuthPeerArgs, reply *Gener
 }
 
 // Sends SetAuthPeer RPCs to all peers in the Minio cluster
-func updateCredsOnPeers(creds credential) map[string]error {
+func updateCredsOnPeers(creds credential, previousCreds *credential) map[string]error {
 	// Get list of peer addresses (from globalS3Peers)
 	peers := []string{}
 	for _, p := range globalS3Peers {
@@ -112,7 +117,7 @@ func updateCredsOnPeers(creds credential) map[string]error {
 			})
 
 			// Construct RPC call arguments.
-			args := SetAuthPeerArgs{Creds: creds}
+			args := SetAuthPeerArgs{Creds: creds, PreviousCreds: previousCreds}
 
 			// Make RPC call - we only care about error
 			// response and not the reply.
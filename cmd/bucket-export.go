@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Exported objects carry their metadata and ETag as PAX records.
+const (
+	exportMetadataPrefix = "MINIO.metadata."
+	exportETagRecord     = "MINIO.etag"
+)
+
+// BucketImportInfo - summary of an imported archive.
+type BucketImportInfo struct {
+	Objects int   `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// exportBucket - writes the objects under prefix to w as a tar
+// archive, in lexical order, with their metadata. The archive is
+// not terminated if an object cannot be read.
+func exportBucket(objAPI ObjectLayer, bucket, prefix string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	lister := newObjectLister(objAPI, bucket, prefix, "", "")
+	for {
+		objInfo, err := lister.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		records := map[string]string{exportETagRecord: objInfo.MD5Sum}
+		for k, v := range objInfo.UserDefined {
+			if k != "md5Sum" {
+				records[exportMetadataPrefix+k] = v
+			}
+		}
+		hdr := &tar.Header{
+			Typeflag:   tar.TypeReg,
+			Name:       objInfo.Name,
+			Mode:       0644,
+			Size:       objInfo.Size,
+			ModTime:    objInfo.ModTime,
+			PAXRecords: records,
+			Format:     tar.FormatPAX,
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err = objAPI.GetObject(bucket, objInfo.Name, 0, objInfo.Size, tw); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// importBucket - creates an object for every file of a tar archive,
+// restoring the metadata of archives written by exportBucket. Objects
+// with a single part ETag are verified against it. The import stops
+// at the first error, objects created before remain.
+func importBucket(objAPI ObjectLayer, bucket string, r io.Reader) (BucketImportInfo, error) {
+	info := BucketImportInfo{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			// Read past the end of the archive so the payload is verified.
+			_, err = io.Copy(ioutil.Discard, r)
+			return info, err
+		}
+		if err != nil {
+			if errorCause(err) == errContentSHA256Mismatch {
+				return info, err
+			}
+			return info, errInvalidArchive
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		metadata := make(map[string]string)
+		for k, v := range hdr.PAXRecords {
+			if strings.HasPrefix(k, exportMetadataPrefix) {
+				metadata[strings.TrimPrefix(k, exportMetadataPrefix)] = v
+			}
+		}
+		// The ETag of a multipart object is not the MD5 sum of its data.
+		if etag := hdr.PAXRecords[exportETagRecord]; etag != "" && !strings.Contains(etag, "-") {
+			metadata["md5Sum"] = etag
+		}
+		if _, err = objAPI.PutObject(bucket, strings.TrimPrefix(hdr.Name, "./"), hdr.Size, tr, metadata, ""); err != nil {
+			return info, err
+		}
+		info.Objects++
+		info.Bytes += hdr.Size
+	}
+}
+
+// sha256VerifyReader - returns errContentSHA256Mismatch at the end of
+// the stream if its SHA256 sum is not the expected one.
+type sha256VerifyReader struct {
+	reader   io.Reader
+	sha256   hash.Hash
+	expected string
+}
+
+func newSHA256VerifyReader(reader io.Reader, expected string) io.Reader {
+	return &sha256VerifyReader{reader: reader, sha256: sha256.New(), expected: expected}
+}
+
+func (s *sha256VerifyReader) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	s.sha256.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(s.sha256.Sum(nil)) != s.expected {
+		return n, traceError(errContentSHA256Mismatch)
+	}
+	return n, err
+}
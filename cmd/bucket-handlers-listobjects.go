@@ -101,11 +101,11 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 		return
 	}
 
-	response := generateListObjectsV2Response(bucket, prefix, token, startAfter, delimiter, fetchOwner, maxKeys, listObjectsInfo)
 	// Write headers
 	setCommonHeaders(w)
-	// Write success response.
-	writeSuccessResponse(w, encodeResponse(response))
+	// Stream the success response.
+	err = writeListObjectsV2Response(w, bucket, prefix, token, startAfter, delimiter, fetchOwner, maxKeys, listObjectsInfo)
+	errorIf(err, "Unable to write list objects response.")
 }
 
 // ListObjectsV1Handler - GET Bucket (List Objects) Version 1.
@@ -147,9 +147,9 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
-	response := generateListObjectsV1Response(bucket, prefix, marker, delimiter, maxKeys, listObjectsInfo)
 	// Write headers
 	setCommonHeaders(w)
-	// Write success response.
-	writeSuccessResponse(w, encodeResponse(response))
+	// Stream the success response.
+	err = writeListObjectsV1Response(w, bucket, prefix, marker, delimiter, maxKeys, listObjectsInfo)
+	errorIf(err, "Unable to write list objects response.")
 }
@@ -20,10 +20,12 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	mux "github.com/gorilla/mux"
 	"github.com/minio/minio-go/pkg/set"
@@ -31,7 +33,7 @@ import (
 
 // http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
 // Enforces bucket policies for a bucket for a given tatusaction.
-func enforceBucketPolicy(bucket string, action string, reqURL *url.URL) (s3Error APIErrorCode) {
+func enforceBucketPolicy(bucket string, action string, r *http.Request) (s3Error APIErrorCode) {
 	// Verify if bucket actually exists
 	if err := checkBucketExist(bucket, newObjectLayerFn()); err != nil {
 		err = errorCause(err)
@@ -55,13 +57,10 @@ func enforceBucketPolicy(bucket string, action string, reqURL *url.URL) (s3Error
 	}
 
 	// Construct resource in 'arn:aws:s3:::examplebucket/object' format.
-	resource := AWSResourcePrefix + strings.TrimSuffix(strings.TrimPrefix(reqURL.Path, "/"), "/")
+	resource := AWSResourcePrefix + strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/")
 
 	// Get conditions for policy verification.
-	conditionKeyMap := make(map[string]set.StringSet)
-	for queryParam := range reqURL.Query() {
-		conditionKeyMap[queryParam] = set.CreateStringSet(reqURL.Query().Get(queryParam))
-	}
+	conditionKeyMap := getConditionKeyMap(r)
 
 	// Validate action, resource and conditions with current policy statements.
 	if !bucketPolicyEvalStatements(action, resource, conditionKeyMap, policy.Statements) {
@@ -70,6 +69,28 @@ func enforceBucketPolicy(bucket string, action string, reqURL *url.URL) (s3Error
 	return ErrNone
 }
 
+// getConditionKeyMap - returns the values of the policy condition
+// keys for a request, query parameters along with the aws: keys.
+func getConditionKeyMap(r *http.Request) map[string]set.StringSet {
+	conditionKeyMap := make(map[string]set.StringSet)
+	for queryParam := range r.URL.Query() {
+		// aws: keys are only set from the request itself.
+		if strings.HasPrefix(queryParam, "aws:") {
+			continue
+		}
+		conditionKeyMap[queryParam] = set.CreateStringSet(r.URL.Query().Get(queryParam))
+	}
+	if referer := r.Referer(); referer != "" {
+		conditionKeyMap["aws:Referer"] = set.CreateStringSet(referer)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		conditionKeyMap["aws:SourceIp"] = set.CreateStringSet(host)
+	}
+	conditionKeyMap["aws:SecureTransport"] = set.CreateStringSet(strconv.FormatBool(r.TLS != nil))
+	conditionKeyMap["aws:CurrentTime"] = set.CreateStringSet(time.Now().UTC().Format(time.RFC3339))
+	return conditionKeyMap
+}
+
 // GetBucketLocationHandler - GET Bucket location.
 // -------------------------
 // This operation returns bucket location.
@@ -244,21 +265,10 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		return
 	}
 
-	var wg = &sync.WaitGroup{} // Allocate a new wait group.
-	var dErrs = make([]error, len(deleteObjects.Objects))
-
 	// Delete all requested objects in parallel.
-	for index, object := range deleteObjects.Objects {
-		wg.Add(1)
-		go func(i int, obj ObjectIdentifier) {
-			defer wg.Done()
-			dErr := objectAPI.DeleteObject(bucket, obj.ObjectName)
-			if dErr != nil {
-				dErrs[i] = dErr
-			}
-		}(index, object)
-	}
-	wg.Wait()
+	dErrs := fanOut(len(deleteObjects.Objects), func(i int) error {
+		return deleteObjectVersioned(objectAPI, bucket, deleteObjects.Objects[i].ObjectName)
+	})
 
 	// Collect deleted objects and errors if any.
 	var deletedObjects []ObjectIdentifier
@@ -346,6 +356,50 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 	writeSuccessResponse(w, nil)
 }
 
+// writePostPolicyResponse - replies to a successful POST form upload. Like S3,
+// success_action_redirect, or redirect, sends the browser to a URL with the
+// bucket, key and ETag of the object, otherwise success_action_status selects
+// an empty 200, a 201 with a PostResponse document or the default empty 204.
+// Only the redirects the policy has a condition on are followed, anyone could
+// send the browser elsewhere otherwise.
+func writePostPolicyResponse(w http.ResponseWriter, formValues map[string]string, postPolicyForm PostPolicyForm, bucket, object, md5Sum string) {
+	redirect, redirectCond := formValues["Success_action_redirect"], "$success_action_redirect"
+	if redirect == "" {
+		redirect, redirectCond = formValues["Redirect"], "$redirect"
+	}
+	if _, ok := postPolicyForm.Conditions.Policies[redirectCond]; !ok {
+		redirect = ""
+	}
+	if redirectURL, err := url.Parse(redirect); redirect != "" && err == nil && redirectURL.IsAbs() {
+		query := redirectURL.Query()
+		query.Set("bucket", bucket)
+		query.Set("key", object)
+		query.Set("etag", "\""+md5Sum+"\"")
+		redirectURL.RawQuery = query.Encode()
+		setCommonHeaders(w)
+		w.Header().Set("Location", redirectURL.String())
+		w.WriteHeader(http.StatusSeeOther)
+		return
+	}
+
+	switch formValues["Success_action_status"] {
+	case "200":
+		writeSuccessResponse(w, nil)
+	case "201":
+		encodedSuccessResponse := encodeResponse(PostResponse{
+			Location: getObjectLocation(bucket, object),
+			Bucket:   bucket,
+			Key:      object,
+			ETag:     "\"" + md5Sum + "\"",
+		})
+		setCommonHeaders(w)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(encodedSuccessResponse)
+	default:
+		writeSuccessNoContent(w)
+	}
+}
+
 // PostPolicyBucketHandler - POST policy
 // ----------
 // This implementation of the POST operation handles object creation with a specified
@@ -382,8 +436,11 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 	}
 	object := formValues["Key"]
 
-	// Verify policy signature.
-	apiErr := doesPolicySignatureMatch(formValues)
+	// Verify policy signature, failures are throttled.
+	ipKey, accessKeyKey := authThrottleKeysOf(r.RemoteAddr, getPostPolicyAccessKey(formValues))
+	apiErr := throttleAuthKeys(ipKey, accessKeyKey, func() APIErrorCode {
+		return doesPolicySignatureMatch(formValues)
+	})
 	if apiErr != ErrNone {
 		writeErrorResponse(w, r, apiErr, r.URL.Path)
 		return
@@ -431,20 +488,26 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 
 	sha256sum := ""
 
+	versionID, err := prepareObjectVersion(objectAPI, bucket, object, metadata)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
 	objInfo, err := objectAPI.PutObject(bucket, object, -1, fileBody, metadata, sha256sum)
 	if err != nil {
 		errorIf(err, "Unable to create object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
+	if versionID != "" {
+		w.Header().Set(objectVersionIDHeader, versionID)
+	}
 	w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 	w.Header().Set("Location", getObjectLocation(bucket, object))
 
-	// Set common headers.
-	setCommonHeaders(w)
-
-	// Write successful response.
-	writeSuccessNoContent(w)
+	// Write successful response, a redirect or the status the form asks for.
+	writePostPolicyResponse(w, formValues, postPolicyForm, bucket, object, objInfo.MD5Sum)
 
 	// Notify object created event.
 	eventNotify(eventData{
@@ -519,6 +582,20 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Delete listener config, if present - ignore any errors.
 	_ = removeListenerConfig(bucket, objectAPI)
 
+	// Delete transform config, if present - ignore any errors.
+	if removeTransformConfig(bucket, objectAPI) == nil {
+		S3PeersUpdateBucketTransform(bucket, nil)
+	}
+
+	// Delete inventory config, if present - ignore any errors.
+	_ = removeInventoryConfig(bucket, objectAPI)
+
+	// Delete lifecycle config, if present - ignore any errors.
+	_ = removeLifecycleConfig(bucket, objectAPI)
+
+	// Delete versioning config and prior versions - ignore any errors.
+	_ = removeVersioningConfig(bucket, objectAPI)
+
 	// Write success response.
 	writeSuccessNoContent(w)
 }
@@ -24,6 +24,9 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
+
+	"github.com/minio/minio-go/pkg/set"
 )
 
 // Wrapper for calling GetBucketPolicy HTTP handler tests for both XL multiple disks and single node setup.
@@ -771,3 +774,35 @@ func testAPIDeleteMultipleObjectsHandler(obj ObjectLayer, instanceType, bucketNa
 	// `ExecObjectLayerAPINilTest` manages the operation.
 	ExecObjectLayerAPINilTest(t, nilBucket, nilObject, instanceType, apiRouter, nilReq)
 }
+
+// Tests the condition key values of a request.
+func TestGetConditionKeyMap(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://localhost:9000/bucket?prefix=Asia/&aws:SourceIp=10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("Unable to create request: %s", err)
+	}
+	req.RemoteAddr = "192.168.1.10:52342"
+	req.Header.Set("Referer", "http://example.com/")
+
+	conditionKeyMap := getConditionKeyMap(req)
+	expectedValues := map[string]string{
+		"prefix":              "Asia/",
+		"aws:SourceIp":        "192.168.1.10",
+		"aws:Referer":         "http://example.com/",
+		"aws:SecureTransport": "false",
+	}
+	for key, value := range expectedValues {
+		if !conditionKeyMap[key].Equals(set.CreateStringSet(value)) {
+			t.Errorf("Expected %s to be %s, got %s", key, value, conditionKeyMap[key])
+		}
+	}
+	currentTime := conditionKeyMap["aws:CurrentTime"]
+	if len(currentTime) != 1 {
+		t.Fatalf("Expected a single aws:CurrentTime, got %s", currentTime)
+	}
+	for value := range currentTime {
+		if _, err = time.Parse(time.RFC3339, value); err != nil {
+			t.Errorf("Unexpected aws:CurrentTime %s", value)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Inventory configuration and status files stored per bucket.
+	bucketInventoryConfig = "inventory.xml"
+	bucketInventoryStatus = "inventory.json"
+
+	// Interval at which inventory configurations are checked for due
+	// reports.
+	inventorySchedulerInterval = time.Hour
+)
+
+// Valid inventory report frequencies.
+const (
+	inventoryFrequencyDaily  = "Daily"
+	inventoryFrequencyWeekly = "Weekly"
+)
+
+// Valid inventory report formats.
+const (
+	inventoryFormatCSV     = "CSV"
+	inventoryFormatParquet = "Parquet"
+)
+
+// Fields of every inventory record, in order.
+var inventoryFileSchema = []string{"Bucket", "Key", "Size", "LastModifiedDate", "ETag", "StorageClass", "EncryptionStatus"}
+
+// inventoryConfig - represents the inventory configuration of a
+// bucket, reports of all objects are written periodically to the
+// destination bucket.
+type inventoryConfig struct {
+	XMLName     xml.Name `xml:"InventoryConfiguration"`
+	Destination struct {
+		Bucket string
+		Prefix string
+	}
+	Schedule struct {
+		Frequency string
+	}
+	Format string
+}
+
+// inventoryStatus - time of the last report of a bucket.
+type inventoryStatus struct {
+	LastRun time.Time `json:"lastRun"`
+}
+
+// inventoryManifest - describes a single inventory report, written
+// next to the report as manifest.json.
+type inventoryManifest struct {
+	SourceBucket      string                  `json:"sourceBucket"`
+	DestinationBucket string                  `json:"destinationBucket"`
+	CreationTimestamp string                  `json:"creationTimestamp"`
+	FileFormat        string                  `json:"fileFormat"`
+	FileSchema        string                  `json:"fileSchema"`
+	Files             []inventoryManifestFile `json:"files"`
+}
+
+// inventoryManifestFile - a report file listed in the manifest.
+type inventoryManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5checksum string `json:"MD5checksum"`
+}
+
+// validateInventoryConfig - validates an inventory configuration, the
+// destination bucket must exist.
+func validateInventoryConfig(icfg inventoryConfig, objAPI ObjectLayer) APIErrorCode {
+	switch icfg.Format {
+	case inventoryFormatCSV:
+	case inventoryFormatParquet:
+		// No Parquet encoder is available.
+		return ErrNotImplemented
+	default:
+		return ErrInvalidInventoryConfiguration
+	}
+	switch icfg.Schedule.Frequency {
+	case inventoryFrequencyDaily, inventoryFrequencyWeekly:
+	default:
+		return ErrInvalidInventoryConfiguration
+	}
+	if icfg.Destination.Bucket == "" {
+		return ErrInvalidInventoryConfiguration
+	}
+	if _, err := objAPI.GetBucketInfo(icfg.Destination.Bucket); err != nil {
+		return toAPIErrorCode(err)
+	}
+	return ErrNone
+}
+
+// period - returns the interval between two reports.
+func (icfg inventoryConfig) period() time.Duration {
+	if icfg.Schedule.Frequency == inventoryFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// readBucketConfigFile - reads a configuration file of bucket, returns
+// errConfigNotFound if it doesn't exist.
+func readBucketConfigFile(bucket, name string, objAPI ObjectLayer) ([]byte, error) {
+	configPath := path.Join(bucketConfigPrefix, bucket, name)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, configPath)
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errConfigNotFound
+		}
+		return nil, errorCause(err)
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, configPath, 0, objInfo.Size, &buffer); err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errConfigNotFound
+		}
+		return nil, errorCause(err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeBucketConfigFile - writes a configuration file of bucket.
+func writeBucketConfigFile(bucket, name string, data []byte, objAPI ObjectLayer) error {
+	configPath := path.Join(bucketConfigPrefix, bucket, name)
+	_, err := objAPI.PutObject(minioMetaBucket, configPath, int64(len(data)), bytes.NewReader(data), nil, getSHA256Hash(data))
+	return errorCause(err)
+}
+
+// loadInventoryConfig - loads the inventory config of a bucket,
+// returns errNoSuchInventory if the bucket has none.
+func loadInventoryConfig(bucket string, objAPI ObjectLayer) (*inventoryConfig, error) {
+	data, err := readBucketConfigFile(bucket, bucketInventoryConfig, objAPI)
+	if err == errConfigNotFound {
+		return nil, errNoSuchInventory
+	}
+	if err != nil {
+		errorIf(err, "Unable to load bucket-inventory for bucket %s", bucket)
+		return nil, err
+	}
+	icfg := &inventoryConfig{}
+	if err = xml.Unmarshal(data, icfg); err != nil {
+		return nil, err
+	}
+	return icfg, nil
+}
+
+// persistInventoryConfig - persists validated inventory config to
+// object layer.
+func persistInventoryConfig(bucket string, icfg *inventoryConfig, objAPI ObjectLayer) error {
+	data, err := xml.Marshal(icfg)
+	if err != nil {
+		errorIf(err, "Unable to marshal inventory configuration into XML")
+		return err
+	}
+	if err = writeBucketConfigFile(bucket, bucketInventoryConfig, data, objAPI); err != nil {
+		errorIf(err, "Unable to write bucket inventory configuration.")
+		return err
+	}
+	return nil
+}
+
+// removeInventoryConfig - removes the inventory config and status of a
+// bucket, returns errNoSuchInventory if the bucket has none.
+func removeInventoryConfig(bucket string, objAPI ObjectLayer) error {
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketInventoryConfig)
+	if err := objAPI.DeleteObject(minioMetaBucket, configPath); err != nil {
+		if isErrObjectNotFound(err) {
+			return errNoSuchInventory
+		}
+		errorIf(err, "Unable to remove bucket-inventory on bucket %s.", bucket)
+		return errorCause(err)
+	}
+	// Status is absent until the first report - ignore any errors.
+	_ = objAPI.DeleteObject(minioMetaBucket, path.Join(bucketConfigPrefix, bucket, bucketInventoryStatus))
+	return nil
+}
+
+// writeInventoryRecords - writes CSV records of all objects in bucket.
+func writeInventoryRecords(w io.Writer, bucket string, objAPI ObjectLayer) error {
+	cw := csv.NewWriter(w)
+	marker := ""
+	for {
+		lo, err := objAPI.ListObjects(bucket, "", marker, "", 1000)
+		if err != nil {
+			return errorCause(err)
+		}
+		for _, obj := range lo.Objects {
+			record := []string{
+				bucket,
+				obj.Name,
+				strconv.FormatInt(obj.Size, 10),
+				obj.ModTime.UTC().Format(timeFormatAMZLong),
+				obj.MD5Sum,
+				"STANDARD",
+				"NOT-SSE",
+			}
+			if err = cw.Write(record); err != nil {
+				return err
+			}
+		}
+		if !lo.IsTruncated {
+			break
+		}
+		marker = lo.NextMarker
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// generateInventory - writes an inventory report of bucket along with
+// its manifest to the destination bucket, returns the manifest key.
+func generateInventory(bucket string, icfg *inventoryConfig, now time.Time, objAPI ObjectLayer) (string, error) {
+	reportPrefix := path.Join(icfg.Destination.Prefix, bucket, now.UTC().Format("2006-01-02T15-04Z"))
+	dataKey := path.Join(reportPrefix, "data.csv")
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeInventoryRecords(pw, bucket, objAPI))
+	}()
+	objInfo, err := objAPI.PutObject(icfg.Destination.Bucket, dataKey, -1, pr, map[string]string{"content-type": "text/csv"}, "")
+	pr.Close()
+	if err != nil {
+		return "", errorCause(err)
+	}
+
+	manifest := inventoryManifest{
+		SourceBucket:      bucket,
+		DestinationBucket: icfg.Destination.Bucket,
+		CreationTimestamp: strconv.FormatInt(now.Unix()*1000, 10),
+		FileFormat:        icfg.Format,
+		FileSchema:        strings.Join(inventoryFileSchema, ", "),
+		Files:             []inventoryManifestFile{{dataKey, objInfo.Size, objInfo.MD5Sum}},
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	manifestKey := path.Join(reportPrefix, "manifest.json")
+	if _, err = objAPI.PutObject(icfg.Destination.Bucket, manifestKey, int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": "application/json"}, ""); err != nil {
+		return "", errorCause(err)
+	}
+	return manifestKey, nil
+}
+
+// runDueInventory - generates the report of bucket if one is due. The
+// status is checked and updated under a lock, so that only one server
+// of a distributed setup writes the report. The lock is not taken on
+// the status file itself, which is locked again while written. No
+// report is written while the server is read-only.
+func runDueInventory(bucket string, now time.Time, objAPI ObjectLayer) error {
+	if globalIsReadOnly {
+		return nil
+	}
+	icfg, err := loadInventoryConfig(bucket, objAPI)
+	if err != nil {
+		if err == errNoSuchInventory {
+			return nil
+		}
+		return err
+	}
+
+	statusLock := nsMutex.NewNSLock(minioMetaBucket, path.Join(bucketConfigPrefix, bucket, bucketInventoryStatus+".lock"))
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	var status inventoryStatus
+	data, err := readBucketConfigFile(bucket, bucketInventoryStatus, objAPI)
+	if err != nil && err != errConfigNotFound {
+		return err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &status); err != nil {
+			return err
+		}
+	}
+	if now.Sub(status.LastRun) < icfg.period() {
+		return nil
+	}
+
+	if _, err = generateInventory(bucket, icfg, now, objAPI); err != nil {
+		return err
+	}
+	status.LastRun = now
+	if data, err = json.Marshal(status); err != nil {
+		return err
+	}
+	return writeBucketConfigFile(bucket, bucketInventoryStatus, data, objAPI)
+}
+
+// startInventoryScheduler - checks all buckets for due inventory
+// reports every inventorySchedulerInterval.
+func startInventoryScheduler(objAPI ObjectLayer) {
+	go func() {
+		for {
+			buckets, err := objAPI.ListBuckets()
+			errorIf(err, "Unable to list buckets.")
+			for _, bucket := range buckets {
+				errorIf(runDueInventory(bucket.Name, time.Now().UTC(), objAPI), "Unable to generate inventory of bucket %s.", bucket.Name)
+			}
+			time.Sleep(inventorySchedulerInterval)
+		}
+	}()
+}
@@ -0,0 +1,226 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+)
+
+// readTestObject - returns the contents of an object.
+func readTestObject(obj ObjectLayer, bucket, object string) ([]byte, error) {
+	objInfo, err := obj.GetObjectInfo(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, object, 0, objInfo.Size, &buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Tests scheduled inventory reports, only due reports are written.
+func TestRunDueInventory(t *testing.T) {
+	ExecObjectLayerTest(t, testRunDueInventory)
+}
+
+func testRunDueInventory(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket, destBucket := "inventory-source", "inventory-dest"
+	for _, b := range []string{bucket, destBucket} {
+		if err := obj.MakeBucket(b); err != nil {
+			t.Fatalf("%s: Unable to create bucket %s: %v", instanceType, b, err)
+		}
+	}
+	objects := map[string][]byte{
+		"a.txt":     []byte("hello"),
+		"dir/b.txt": []byte("hello, world"),
+	}
+	for name, data := range objects {
+		if _, err := obj.PutObject(bucket, name, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object %s: %v", instanceType, name, err)
+		}
+	}
+
+	now := time.Date(2016, 12, 1, 10, 30, 0, 0, time.UTC)
+
+	// No configuration, no report.
+	if err := runDueInventory(bucket, now, obj); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+
+	icfg := &inventoryConfig{Format: inventoryFormatCSV}
+	icfg.Destination.Bucket = destBucket
+	icfg.Destination.Prefix = "reports"
+	icfg.Schedule.Frequency = inventoryFrequencyDaily
+	if err := persistInventoryConfig(bucket, icfg, obj); err != nil {
+		t.Fatalf("%s: Unable to persist inventory config: %v", instanceType, err)
+	}
+
+	if err := runDueInventory(bucket, now, obj); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	manifestKey := "reports/inventory-source/2016-12-01T10-30Z/manifest.json"
+	data, err := readTestObject(obj, destBucket, manifestKey)
+	if err != nil {
+		t.Fatalf("%s: Unable to read manifest: %v", instanceType, err)
+	}
+	var manifest inventoryManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("%s: Unable to parse manifest: %v", instanceType, err)
+	}
+	if manifest.SourceBucket != bucket || len(manifest.Files) != 1 {
+		t.Fatalf("%s: Unexpected manifest %s", instanceType, data)
+	}
+
+	data, err = readTestObject(obj, destBucket, manifest.Files[0].Key)
+	if err != nil {
+		t.Fatalf("%s: Unable to read report: %v", instanceType, err)
+	}
+	if int64(len(data)) != manifest.Files[0].Size {
+		t.Errorf("%s: Expected report size %d, got %d", instanceType, manifest.Files[0].Size, len(data))
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: Unable to parse report: %v", instanceType, err)
+	}
+	if len(records) != len(objects) {
+		t.Fatalf("%s: Expected %d records, got %d", instanceType, len(objects), len(records))
+	}
+	for _, record := range records {
+		if len(record) != len(inventoryFileSchema) {
+			t.Fatalf("%s: Unexpected record %v", instanceType, record)
+		}
+		objInfo, err := obj.GetObjectInfo(bucket, record[1])
+		if err != nil {
+			t.Fatalf("%s: Unexpected object %s in report", instanceType, record[1])
+		}
+		if record[0] != bucket || record[2] != "5" && record[2] != "12" || record[4] != objInfo.MD5Sum {
+			t.Errorf("%s: Unexpected record %v", instanceType, record)
+		}
+	}
+
+	// Report is not due again within the day.
+	later := now.Add(time.Hour)
+	if err = runDueInventory(bucket, later, obj); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(destBucket, "reports/inventory-source/2016-12-01T11-30Z/manifest.json"); err == nil {
+		t.Errorf("%s: Expected no report an hour later", instanceType)
+	}
+
+	// Removing the configuration removes its status as well.
+	if err = removeInventoryConfig(bucket, obj); err != nil {
+		t.Fatalf("%s: Unable to remove inventory config: %v", instanceType, err)
+	}
+	if _, err = readBucketConfigFile(bucket, bucketInventoryStatus, obj); err != errConfigNotFound {
+		t.Errorf("%s: Expected status to be removed, got %v", instanceType, err)
+	}
+	if err = removeInventoryConfig(bucket, obj); err != errNoSuchInventory {
+		t.Errorf("%s: Expected errNoSuchInventory, got %v", instanceType, err)
+	}
+}
+
+// Wrapper for calling bucket inventory HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIBucketInventoryHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIBucketInventoryHandlers, []string{"BucketInventory"})
+}
+
+func testAPIBucketInventoryHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	inventoryXML := func(destBucket, frequency, format string) []byte {
+		return []byte(`<InventoryConfiguration><Destination><Bucket>` + destBucket +
+			`</Bucket><Prefix>reports</Prefix></Destination><Schedule><Frequency>` + frequency +
+			`</Frequency></Schedule><Format>` + format + `</Format></InventoryConfiguration>`)
+	}
+
+	queryValues := url.Values{}
+	queryValues.Set("inventory", "")
+	testCases := []struct {
+		method             string
+		body               []byte
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+	}{
+		// Test case - 1.
+		// No inventory configuration yet.
+		{"GET", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+		// Test case - 2.
+		// Set a daily CSV inventory.
+		{"PUT", inventoryXML(bucketName, "Daily", "CSV"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Test case - 3.
+		// Fetch the inventory configuration.
+		{"GET", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Test case - 4.
+		// Parquet is not supported.
+		{"PUT", inventoryXML(bucketName, "Daily", "Parquet"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotImplemented},
+		// Test case - 5.
+		// Invalid frequency.
+		{"PUT", inventoryXML(bucketName, "Hourly", "CSV"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest},
+		// Test case - 6.
+		// Non-existent destination bucket.
+		{"PUT", inventoryXML("missing-bucket", "Weekly", "CSV"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+		// Test case - 7.
+		// Malformed XML.
+		{"PUT", []byte("<InventoryConfiguration>"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest},
+		// Test case - 8.
+		// Invalid credentials.
+		{"GET", nil, "Invalid-AccessID", credentials.SecretAccessKey, http.StatusForbidden},
+		// Test case - 9.
+		// Remove the inventory configuration.
+		{"DELETE", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNoContent},
+		// Test case - 10.
+		// Removing it again fails.
+		{"DELETE", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+	}
+
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(testCase.method, makeTestTargetURL("", bucketName, "", queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if testCase.method == "GET" && rec.Code == http.StatusOK {
+			var icfg inventoryConfig
+			if err = xml.Unmarshal(rec.Body.Bytes(), &icfg); err != nil {
+				t.Fatalf("Test %d: %s: Unable to parse response: %v", i+1, instanceType, err)
+			}
+			if icfg.Destination.Bucket != bucketName || icfg.Destination.Prefix != "reports" || icfg.Schedule.Frequency != "Daily" {
+				t.Errorf("Test %d: %s: Unexpected configuration %s", i+1, instanceType, rec.Body.String())
+			}
+		}
+	}
+
+	// Configuration files live in the meta bucket.
+	if _, err := obj.GetObjectInfo(minioMetaBucket, path.Join(bucketConfigPrefix, bucketName, bucketInventoryConfig)); err == nil {
+		t.Errorf("%s: Expected inventory configuration to be removed", instanceType)
+	}
+}
@@ -0,0 +1,293 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// Lifecycle configuration file stored per bucket.
+	bucketLifecycleConfig = "lifecycle.xml"
+
+	// Interval at which lifecycle rules are applied.
+	lifecycleInterval = time.Hour
+
+	// Maximum number of rules of a lifecycle configuration.
+	maxLifecycleRules = 1000
+)
+
+// Valid lifecycle rule statuses.
+const (
+	lifecycleRuleEnabled  = "Enabled"
+	lifecycleRuleDisabled = "Disabled"
+)
+
+// lifecycleConfig - represents the lifecycle configuration of a
+// bucket, objects and multipart uploads matching its rules are
+// removed once they expire.
+type lifecycleConfig struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+// lifecycleRule - a rule of a lifecycle configuration, applying to
+// the objects under the prefix of its filter, or the legacy top level
+// prefix.
+type lifecycleRule struct {
+	ID     string `xml:",omitempty"`
+	Prefix string `xml:",omitempty"`
+	Filter *struct {
+		Prefix string
+	} `xml:",omitempty"`
+	Status     string
+	Expiration *struct {
+		Days int    `xml:",omitempty"`
+		Date string `xml:",omitempty"`
+	} `xml:",omitempty"`
+	AbortIncompleteMultipartUpload *struct {
+		DaysAfterInitiation int
+	} `xml:",omitempty"`
+}
+
+// prefix - returns the prefix of the objects the rule applies to.
+func (rule lifecycleRule) prefix() string {
+	if rule.Filter != nil {
+		return rule.Filter.Prefix
+	}
+	return rule.Prefix
+}
+
+// isExpired - returns true if an object modified at modTime has
+// expired under the rule at now.
+func (rule lifecycleRule) isExpired(modTime, now time.Time) bool {
+	if rule.Expiration == nil {
+		return false
+	}
+	if rule.Expiration.Days > 0 {
+		return !now.Before(modTime.Add(time.Duration(rule.Expiration.Days) * 24 * time.Hour))
+	}
+	date, err := time.Parse(time.RFC3339, rule.Expiration.Date)
+	return err == nil && !now.Before(date)
+}
+
+// validateLifecycleConfig - validates the rules of a lifecycle
+// configuration, every rule should have an expiration of a positive
+// number of days or a date, or abort multipart uploads after a
+// positive number of days.
+func validateLifecycleConfig(lcfg lifecycleConfig) APIErrorCode {
+	if len(lcfg.Rules) == 0 || len(lcfg.Rules) > maxLifecycleRules {
+		return ErrInvalidLifecycleConfiguration
+	}
+	ids := make(map[string]struct{}, len(lcfg.Rules))
+	for _, rule := range lcfg.Rules {
+		if len(rule.ID) > 255 {
+			return ErrInvalidLifecycleConfiguration
+		}
+		if rule.ID != "" {
+			if _, ok := ids[rule.ID]; ok {
+				return ErrInvalidLifecycleConfiguration
+			}
+			ids[rule.ID] = struct{}{}
+		}
+		if rule.Status != lifecycleRuleEnabled && rule.Status != lifecycleRuleDisabled {
+			return ErrInvalidLifecycleConfiguration
+		}
+		if rule.Filter != nil && rule.Prefix != "" {
+			return ErrInvalidLifecycleConfiguration
+		}
+		if rule.Expiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+			return ErrInvalidLifecycleConfiguration
+		}
+		if expiration := rule.Expiration; expiration != nil {
+			switch {
+			case expiration.Days > 0 && expiration.Date == "":
+			case expiration.Days == 0 && expiration.Date != "":
+				if _, err := time.Parse(time.RFC3339, expiration.Date); err != nil {
+					return ErrInvalidLifecycleConfiguration
+				}
+			default:
+				return ErrInvalidLifecycleConfiguration
+			}
+		}
+		if abort := rule.AbortIncompleteMultipartUpload; abort != nil && abort.DaysAfterInitiation <= 0 {
+			return ErrInvalidLifecycleConfiguration
+		}
+	}
+	return ErrNone
+}
+
+// loadLifecycleConfig - loads the lifecycle config of a bucket,
+// returns errNoSuchLifecycle if the bucket has none.
+func loadLifecycleConfig(bucket string, objAPI ObjectLayer) (*lifecycleConfig, error) {
+	data, err := readBucketConfigFile(bucket, bucketLifecycleConfig, objAPI)
+	if err == errConfigNotFound {
+		return nil, errNoSuchLifecycle
+	}
+	if err != nil {
+		errorIf(err, "Unable to load bucket-lifecycle for bucket %s", bucket)
+		return nil, err
+	}
+	lcfg := &lifecycleConfig{}
+	if err = xml.Unmarshal(data, lcfg); err != nil {
+		return nil, err
+	}
+	return lcfg, nil
+}
+
+// persistLifecycleConfig - persists validated lifecycle config to
+// object layer.
+func persistLifecycleConfig(bucket string, lcfg *lifecycleConfig, objAPI ObjectLayer) error {
+	data, err := xml.Marshal(lcfg)
+	if err != nil {
+		errorIf(err, "Unable to marshal lifecycle configuration into XML")
+		return err
+	}
+	return writeBucketConfigFile(bucket, bucketLifecycleConfig, data, objAPI)
+}
+
+// removeLifecycleConfig - removes the lifecycle config of a bucket,
+// returns errNoSuchLifecycle if the bucket has none.
+func removeLifecycleConfig(bucket string, objAPI ObjectLayer) error {
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketLifecycleConfig)
+	if err := objAPI.DeleteObject(minioMetaBucket, configPath); err != nil {
+		if isErrObjectNotFound(err) {
+			return errNoSuchLifecycle
+		}
+		errorIf(err, "Unable to remove bucket-lifecycle on bucket %s.", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
+
+// expireObjects - deletes the objects of bucket expired under the
+// enabled rules of lcfg at now, returns the number of objects deleted.
+func expireObjects(bucket string, lcfg *lifecycleConfig, now time.Time, objAPI ObjectLayer) (int, error) {
+	var rules []lifecycleRule
+	for _, rule := range lcfg.Rules {
+		if rule.Status == lifecycleRuleEnabled && rule.Expiration != nil {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	expired := 0
+	marker := ""
+	for {
+		lo, err := objAPI.ListObjects(bucket, "", marker, "", 1000)
+		if err != nil {
+			return expired, errorCause(err)
+		}
+		for _, obj := range lo.Objects {
+			for _, rule := range rules {
+				if !strings.HasPrefix(obj.Name, rule.prefix()) || !rule.isExpired(obj.ModTime, now) {
+					continue
+				}
+				if err = deleteObjectVersioned(objAPI, bucket, obj.Name); err != nil && !isErrObjectNotFound(err) {
+					return expired, errorCause(err)
+				}
+				expired++
+				break
+			}
+		}
+		if !lo.IsTruncated {
+			return expired, nil
+		}
+		marker = lo.NextMarker
+	}
+}
+
+// abortExpiredUploads - aborts the multipart uploads of bucket
+// initiated longer ago than the enabled rules of lcfg allow, returns
+// the number of uploads aborted.
+func abortExpiredUploads(bucket string, lcfg *lifecycleConfig, now time.Time, objAPI ObjectLayer) (int, error) {
+	aborted := 0
+	for _, rule := range lcfg.Rules {
+		if rule.Status != lifecycleRuleEnabled || rule.AbortIncompleteMultipartUpload == nil {
+			continue
+		}
+		expiry := time.Duration(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation) * 24 * time.Hour
+		keyMarker, uploadIDMarker := "", ""
+		for {
+			lmi, err := objAPI.ListMultipartUploads(bucket, rule.prefix(), keyMarker, uploadIDMarker, "", maxUploadsList)
+			if err != nil {
+				return aborted, errorCause(err)
+			}
+			for _, upload := range lmi.Uploads {
+				if now.Sub(upload.Initiated) < expiry {
+					continue
+				}
+				err = objAPI.AbortMultipartUpload(bucket, upload.Object, upload.UploadID)
+				if err != nil {
+					if _, ok := errorCause(err).(InvalidUploadID); ok {
+						// Completed or aborted since listed.
+						continue
+					}
+					return aborted, errorCause(err)
+				}
+				aborted++
+			}
+			if !lmi.IsTruncated {
+				break
+			}
+			keyMarker, uploadIDMarker = lmi.NextKeyMarker, lmi.NextUploadIDMarker
+		}
+	}
+	return aborted, nil
+}
+
+// applyLifecycle - applies the lifecycle configuration of bucket, if
+// any, at now. Every server of a distributed setup applies the rules,
+// expiring an object twice is harmless. Nothing expires while the
+// server is read-only.
+func applyLifecycle(bucket string, now time.Time, objAPI ObjectLayer) error {
+	if globalIsReadOnly {
+		return nil
+	}
+	lcfg, err := loadLifecycleConfig(bucket, objAPI)
+	if err != nil {
+		if err == errNoSuchLifecycle {
+			return nil
+		}
+		return err
+	}
+	if _, err = expireObjects(bucket, lcfg, now, objAPI); err != nil {
+		return err
+	}
+	_, err = abortExpiredUploads(bucket, lcfg, now, objAPI)
+	return err
+}
+
+// startLifecycle - applies the lifecycle configurations of all buckets
+// every lifecycleInterval.
+func startLifecycle(objAPI ObjectLayer) {
+	go func() {
+		for {
+			buckets, err := objAPI.ListBuckets()
+			errorIf(err, "Unable to list buckets.")
+			for _, bucket := range buckets {
+				errorIf(applyLifecycle(bucket.Name, time.Now().UTC(), objAPI), "Unable to apply lifecycle of bucket %s.", bucket.Name)
+			}
+			time.Sleep(lifecycleInterval)
+		}
+	}()
+}
@@ -0,0 +1,220 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Tests validation of lifecycle configurations.
+func TestValidateLifecycleConfig(t *testing.T) {
+	parse := func(rules string) lifecycleConfig {
+		var lcfg lifecycleConfig
+		if err := xml.Unmarshal([]byte("<LifecycleConfiguration>"+rules+"</LifecycleConfiguration>"), &lcfg); err != nil {
+			t.Fatalf("Unable to parse lifecycle configuration: %v", err)
+		}
+		return lcfg
+	}
+	testCases := []struct {
+		rules       string
+		expectedErr APIErrorCode
+	}{
+		// Expiration after a number of days, with a filter.
+		{`<Rule><ID>logs</ID><Filter><Prefix>logs/</Prefix></Filter><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>`, ErrNone},
+		// Expiration at a date, with the legacy prefix.
+		{`<Rule><Prefix>tmp/</Prefix><Status>Disabled</Status><Expiration><Date>2017-01-01T00:00:00Z</Date></Expiration></Rule>`, ErrNone},
+		// Abort multipart uploads only.
+		{`<Rule><Status>Enabled</Status><AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule>`, ErrNone},
+		// No rules.
+		{``, ErrInvalidLifecycleConfiguration},
+		// Invalid status.
+		{`<Rule><Status>On</Status><Expiration><Days>1</Days></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+		// No action.
+		{`<Rule><Status>Enabled</Status></Rule>`, ErrInvalidLifecycleConfiguration},
+		// Zero days.
+		{`<Rule><Status>Enabled</Status><Expiration><Days>0</Days></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+		// Both days and date.
+		{`<Rule><Status>Enabled</Status><Expiration><Days>1</Days><Date>2017-01-01T00:00:00Z</Date></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+		// Invalid date.
+		{`<Rule><Status>Enabled</Status><Expiration><Date>tomorrow</Date></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+		// Duplicate IDs.
+		{`<Rule><ID>a</ID><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule><Rule><ID>a</ID><Status>Enabled</Status><Expiration><Days>2</Days></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+		// Both a filter and a legacy prefix.
+		{`<Rule><Prefix>a</Prefix><Filter><Prefix>b</Prefix></Filter><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule>`, ErrInvalidLifecycleConfiguration},
+	}
+	for i, testCase := range testCases {
+		if s3Error := validateLifecycleConfig(parse(testCase.rules)); s3Error != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, s3Error)
+		}
+	}
+}
+
+// Tests that expired objects and multipart uploads are removed.
+func TestApplyLifecycle(t *testing.T) {
+	ExecObjectLayerTest(t, testApplyLifecycle)
+}
+
+func testApplyLifecycle(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "lifecycle-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	for _, name := range []string{"logs/a.log", "logs/b.log", "tmp/c", "keep/d"} {
+		if _, err := obj.PutObject(bucket, name, 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object %s: %v", instanceType, name, err)
+		}
+	}
+	uploadID, err := obj.NewMultipartUpload(bucket, "uploads/e", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start multipart upload: %v", instanceType, err)
+	}
+
+	now := time.Now().UTC()
+
+	// No configuration, nothing is removed.
+	if err = applyLifecycle(bucket, now.Add(365*24*time.Hour), obj); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+
+	var lcfg lifecycleConfig
+	rules := `<LifecycleConfiguration>
+<Rule><Filter><Prefix>logs/</Prefix></Filter><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>
+<Rule><Prefix>tmp/</Prefix><Status>Enabled</Status><Expiration><Date>` + now.Add(24*time.Hour).Format(time.RFC3339) + `</Date></Expiration></Rule>
+<Rule><Prefix>keep/</Prefix><Status>Disabled</Status><Expiration><Days>1</Days></Expiration></Rule>
+<Rule><Status>Enabled</Status><AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule>
+</LifecycleConfiguration>`
+	if err = xml.Unmarshal([]byte(rules), &lcfg); err != nil {
+		t.Fatalf("%s: Unable to parse lifecycle configuration: %v", instanceType, err)
+	}
+	if err = persistLifecycleConfig(bucket, &lcfg, obj); err != nil {
+		t.Fatalf("%s: Unable to persist lifecycle configuration: %v", instanceType, err)
+	}
+
+	testCases := []struct {
+		now           time.Time
+		remaining     []string
+		uploadAborted bool
+	}{
+		// Nothing has expired yet.
+		{now, []string{"keep/d", "logs/a.log", "logs/b.log", "tmp/c"}, false},
+		// The date of tmp/ has passed, uploads are older than 7 days.
+		{now.Add(8 * 24 * time.Hour), []string{"keep/d", "logs/a.log", "logs/b.log"}, true},
+		// Logs are older than 30 days, disabled rules do not apply.
+		{now.Add(31 * 24 * time.Hour), []string{"keep/d"}, true},
+	}
+	for i, testCase := range testCases {
+		if err = applyLifecycle(bucket, testCase.now, obj); err != nil {
+			t.Fatalf("Test %d: %s: Unable to apply lifecycle: %v", i+1, instanceType, err)
+		}
+		lo, err := obj.ListObjects(bucket, "", "", "", 1000)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Unable to list objects: %v", i+1, instanceType, err)
+		}
+		var remaining []string
+		for _, object := range lo.Objects {
+			remaining = append(remaining, object.Name)
+		}
+		if len(remaining) != len(testCase.remaining) {
+			t.Fatalf("Test %d: %s: Expected %v, got %v", i+1, instanceType, testCase.remaining, remaining)
+		}
+		for j := range remaining {
+			if remaining[j] != testCase.remaining[j] {
+				t.Fatalf("Test %d: %s: Expected %v, got %v", i+1, instanceType, testCase.remaining, remaining)
+			}
+		}
+		_, err = obj.ListObjectParts(bucket, "uploads/e", uploadID, 0, maxPartsList)
+		if _, ok := errorCause(err).(InvalidUploadID); ok != testCase.uploadAborted {
+			t.Fatalf("Test %d: %s: Expected upload aborted to be %v, got %v", i+1, instanceType, testCase.uploadAborted, err)
+		}
+	}
+}
+
+// Wrapper for calling bucket lifecycle HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIBucketLifecycleHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIBucketLifecycleHandlers, []string{"BucketLifecycle"})
+}
+
+func testAPIBucketLifecycleHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	lifecycleXML := func(days string) []byte {
+		return []byte(`<LifecycleConfiguration><Rule><ID>logs</ID><Filter><Prefix>logs/</Prefix></Filter>` +
+			`<Status>Enabled</Status><Expiration><Days>` + days + `</Days></Expiration></Rule></LifecycleConfiguration>`)
+	}
+
+	queryValues := url.Values{}
+	queryValues.Set("lifecycle", "")
+	testCases := []struct {
+		method             string
+		body               []byte
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+	}{
+		// Test case - 1.
+		// No lifecycle configuration yet.
+		{"GET", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+		// Test case - 2.
+		// Expire logs after 30 days.
+		{"PUT", lifecycleXML("30"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Test case - 3.
+		// Fetch the lifecycle configuration.
+		{"GET", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Test case - 4.
+		// Invalid number of days.
+		{"PUT", lifecycleXML("0"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest},
+		// Test case - 5.
+		// Malformed XML.
+		{"PUT", []byte("<LifecycleConfiguration>"), credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest},
+		// Test case - 6.
+		// Invalid credentials.
+		{"GET", nil, "Invalid-AccessID", credentials.SecretAccessKey, http.StatusForbidden},
+		// Test case - 7.
+		// Remove the lifecycle configuration.
+		{"DELETE", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNoContent},
+		// Test case - 8.
+		// Removing it again fails.
+		{"DELETE", nil, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+	}
+
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(testCase.method, makeTestTargetURL("", bucketName, "", queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if testCase.method == "GET" && rec.Code == http.StatusOK {
+			var lcfg lifecycleConfig
+			if err = xml.Unmarshal(rec.Body.Bytes(), &lcfg); err != nil {
+				t.Fatalf("Test %d: %s: Unable to parse response: %v", i+1, instanceType, err)
+			}
+			if len(lcfg.Rules) != 1 || lcfg.Rules[0].prefix() != "logs/" || lcfg.Rules[0].Expiration == nil || lcfg.Rules[0].Expiration.Days != 30 {
+				t.Errorf("Test %d: %s: Unexpected configuration %s", i+1, instanceType, rec.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "sync"
+
+// bucketMetadataCache - caches a configuration of every bucket, loaded
+// when a bucket is first looked up instead of reading all buckets at
+// startup. Concurrent lookups of a bucket not loaded yet share a
+// single load.
+type bucketMetadataCache struct {
+	// Loads the configuration of a bucket, nil if it has none. Errors
+	// are not cached, the next lookup loads again. Only cached values
+	// are returned while nil.
+	load func(bucket string) (interface{}, error)
+
+	mu     sync.Mutex
+	values map[string]interface{}
+	calls  map[string]*bucketMetadataCall
+}
+
+// bucketMetadataCall - a load in progress.
+type bucketMetadataCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+func newBucketMetadataCache(load func(bucket string) (interface{}, error)) *bucketMetadataCache {
+	return &bucketMetadataCache{
+		load:   load,
+		values: make(map[string]interface{}),
+		calls:  make(map[string]*bucketMetadataCall),
+	}
+}
+
+// Get - returns the configuration of a bucket, loading it if not cached.
+func (c *bucketMetadataCache) Get(bucket string) (interface{}, error) {
+	c.mu.Lock()
+	if value, ok := c.values[bucket]; ok || c.load == nil {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if call, ok := c.calls[bucket]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+	call := &bucketMetadataCall{}
+	call.wg.Add(1)
+	c.calls[bucket] = call
+	c.mu.Unlock()
+
+	call.value, call.err = c.load(bucket)
+	call.wg.Done()
+
+	c.mu.Lock()
+	// The load is stale if the bucket was set meanwhile.
+	if c.calls[bucket] == call {
+		delete(c.calls, bucket)
+		if call.err == nil {
+			c.values[bucket] = call.value
+		}
+	}
+	c.mu.Unlock()
+	return call.value, call.err
+}
+
+// Set - caches a new configuration of a bucket, nil if it has none.
+func (c *bucketMetadataCache) Set(bucket string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.calls, bucket)
+	c.values[bucket] = value
+}
@@ -0,0 +1,94 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests loading bucket configs on first use.
+func TestBucketMetadataCache(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := newBucketMetadataCache(func(bucket string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		switch bucket {
+		case "missing":
+			return nil, errVolumeNotFound
+		case "none":
+			return nil, nil
+		}
+		return "config-" + bucket, nil
+	})
+
+	// Concurrent lookups share a single load.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := cache.Get("bucket"); err != nil || value != "config-bucket" {
+				t.Errorf("Unexpected result %v, %v", value, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if loads != 1 {
+		t.Fatalf("Expected a single load, got %d", loads)
+	}
+
+	// Cached values, including buckets without config, are not loaded again.
+	for i := 0; i < 2; i++ {
+		if value, err := cache.Get("none"); err != nil || value != nil {
+			t.Errorf("Unexpected result %v, %v", value, err)
+		}
+		if _, err := cache.Get("bucket"); err != nil {
+			t.Error(err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("Expected 2 loads, got %d", loads)
+	}
+
+	// Errors are not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get("missing"); err != errVolumeNotFound {
+			t.Errorf("Expected %v, got %v", errVolumeNotFound, err)
+		}
+	}
+	if loads != 4 {
+		t.Errorf("Expected 4 loads, got %d", loads)
+	}
+
+	// Set values replace loaded ones.
+	cache.Set("bucket", nil)
+	if value, err := cache.Get("bucket"); err != nil || value != nil {
+		t.Errorf("Unexpected result %v, %v", value, err)
+	}
+
+	// Without a loader only set values are returned.
+	cache = newBucketMetadataCache(nil)
+	if value, err := cache.Get("bucket"); err != nil || value != nil {
+		t.Errorf("Unexpected result %v, %v", value, err)
+	}
+}
@@ -33,6 +33,9 @@ type BucketMetaState interface {
 	// Updates bucket policy
 	UpdateBucketPolicy(args *SetBucketPolicyPeerArgs) error
 
+	// Updates bucket transform
+	UpdateBucketTransform(args *SetBucketTransformPeerArgs) error
+
 	// Sends event
 	SendEvent(args *EventArgs) error
 }
@@ -91,6 +94,19 @@ func (lc *localBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArgs
 	return globalBucketPolicies.SetBucketPolicy(args.Bucket, pCh)
 }
 
+// localBucketMetaState.UpdateBucketTransform - updates in-memory global
+// bucket transform info.
+func (lc *localBucketMetaState) UpdateBucketTransform(args *SetBucketTransformPeerArgs) error {
+	// check if object layer is available.
+	objAPI := lc.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	globalBucketTransforms.SetBucketTransform(args.Bucket, args.TCfg)
+	return nil
+}
+
 // localBucketMetaState.SendEvent - sends event to local event notifier via
 // `globalEventNotifier`
 func (lc *localBucketMetaState) SendEvent(args *EventArgs) error {
@@ -156,6 +172,22 @@ func (rc *remoteBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArg
 	return err
 }
 
+// remoteBucketMetaState.UpdateBucketTransform - sends bucket transform
+// change to remote peer via RPC call.
+func (rc *remoteBucketMetaState) UpdateBucketTransform(args *SetBucketTransformPeerArgs) error {
+	reply := GenericReply{}
+	err := rc.Call("S3.SetBucketTransformPeer", args, &reply)
+	// Check for network error and retry once.
+	if err != nil && err == rpc.ErrShutdown {
+		// Close the underlying connection to attempt once more.
+		rc.Close()
+
+		// Attempt again and proceed.
+		err = rc.Call("S3.SetBucketTransformPeer", args, &reply)
+	}
+	return err
+}
+
 // remoteBucketMetaState.SendEvent - sends event for bucket listener to remote
 // peer via RPC call.
 func (rc *remoteBucketMetaState) SendEvent(args *EventArgs) error {
@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	mux "github.com/gorilla/mux"
@@ -33,20 +36,22 @@ import (
 const maxAccessPolicySize = 20 * humanize.KiByte
 
 // Verify if a given action is valid for the url path based on the
-// existing bucket access policy.
+// existing bucket access policy. Any matching Deny statement overrides
+// the matching Allow statements, whatever their order.
 func bucketPolicyEvalStatements(action string, resource string, conditions map[string]set.StringSet, statements []policyStatement) bool {
+	allowed := false
 	for _, statement := range statements {
 		if bucketPolicyMatchStatement(action, resource, conditions, statement) {
+			if statement.Effect == "Deny" {
+				return false
+			}
 			if statement.Effect == "Allow" {
-				return true
+				allowed = true
 			}
-			// Do not uncomment kept here for readability.
-			// else statement.Effect == "Deny"
-			return false
 		}
 	}
 	// None match so deny.
-	return false
+	return allowed
 }
 
 // Verify if action, resource and conditions match input policy statement.
@@ -84,35 +89,95 @@ func bucketPolicyResourceMatch(resource string, statement policyStatement) bool
 // Verify if given condition matches with policy statement.
 func bucketPolicyConditionMatch(conditions map[string]set.StringSet, statement policyStatement) bool {
 	// Supports following conditions.
-	// - StringEquals
-	// - StringNotEquals
+	// - StringEquals, StringNotEquals, StringLike, StringNotLike
+	// - IpAddress, NotIpAddress
+	// - DateEquals, DateNotEquals, DateLessThan, DateLessThanEquals,
+	//   DateGreaterThan, DateGreaterThanEquals
+	// - Bool
 	//
 	// Supported applicable condition keys for each conditions.
-	// - s3:prefix
-	// - s3:max-keys
-	var conditionMatches = true
+	// - s3:prefix, s3:max-keys, aws:Referer
+	// - aws:SourceIp
+	// - aws:CurrentTime
+	// - aws:SecureTransport
 	for condition, conditionKeyVal := range statement.Conditions {
-		if condition == "StringEquals" {
-			if !conditionKeyVal["s3:prefix"].Equals(conditions["prefix"]) {
-				conditionMatches = false
-				break
-			}
-			if !conditionKeyVal["s3:max-keys"].Equals(conditions["max-keys"]) {
-				conditionMatches = false
-				break
-			}
-		} else if condition == "StringNotEquals" {
-			if !conditionKeyVal["s3:prefix"].Equals(conditions["prefix"]) {
-				conditionMatches = false
-				break
-			}
-			if !conditionKeyVal["s3:max-keys"].Equals(conditions["max-keys"]) {
-				conditionMatches = false
-				break
+		for key, values := range conditionKeyVal {
+			if !bucketPolicyConditionKeyMatch(condition, key, values, conditions) {
+				return false
 			}
 		}
 	}
-	return conditionMatches
+	return true
+}
+
+// Verify if the request value of key satisfies condition with values.
+func bucketPolicyConditionKeyMatch(condition, key string, values set.StringSet, conditions map[string]set.StringSet) bool {
+	// Negated conditions are satisfied by requests without the key.
+	// s3 condition keys are the request query parameters of the same
+	// name.
+	negated := strings.Contains(condition, "Not")
+	requestValues, ok := conditions[strings.TrimPrefix(key, "s3:")]
+	if !ok || requestValues.IsEmpty() {
+		return negated
+	}
+	var requestValue string
+	for requestValue = range requestValues {
+		break
+	}
+
+	var matches bool
+	switch condition {
+	case "StringEquals", "StringNotEquals", "Bool":
+		matches = values.Contains(requestValue)
+	case "StringLike", "StringNotLike":
+		matches = !values.FuncMatch(actionMatch, requestValue).IsEmpty()
+	case "IpAddress", "NotIpAddress":
+		matches = !values.FuncMatch(ipAddressMatch, requestValue).IsEmpty()
+	default:
+		matches = !values.FuncMatch(func(value, requestTime string) bool {
+			return dateMatch(condition, value, requestTime)
+		}, requestValue).IsEmpty()
+	}
+	if negated {
+		return !matches
+	}
+	return matches
+}
+
+// Match function matches an IP address against a CIDR block.
+func ipAddressMatch(cidr, ipAddress string) bool {
+	ipNet, err := parsePolicyIPNet(cidr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(ipAddress)
+	return ip != nil && ipNet.Contains(ip)
+}
+
+// Match function compares the request time with the date of a date
+// condition.
+func dateMatch(condition, date, requestTime string) bool {
+	t, err := parsePolicyDate(date)
+	if err != nil {
+		return false
+	}
+	now, err := time.Parse(time.RFC3339, requestTime)
+	if err != nil {
+		return false
+	}
+	switch condition {
+	case "DateEquals", "DateNotEquals":
+		return now.Equal(t)
+	case "DateLessThan":
+		return now.Before(t)
+	case "DateLessThanEquals":
+		return !now.After(t)
+	case "DateGreaterThan":
+		return now.After(t)
+	case "DateGreaterThanEquals":
+		return !now.Before(t)
+	}
+	return false
 }
 
 // PutBucketPolicyHandler - PUT Bucket policy
@@ -907,39 +907,167 @@ func TestBucketPolicyConditionMatch(t *testing.T) {
 			expectedMatch: false,
 		},
 		// Test case - 5.
-		// StringNotEquals condition matches.
+		// StringNotEquals condition doesn't match.
 		{
 
 			statementCondition: getStatementWithCondition("StringNotEquals", "s3:prefix", "Asia/"),
 			condition:          getInnerMap("prefix", "Asia/"),
 
-			expectedMatch: true,
+			expectedMatch: false,
 		},
 		// Test case - 6.
-		// StringNotEquals condition doesn't match.
+		// StringNotEquals condition matches.
 		{
 
 			statementCondition: getStatementWithCondition("StringNotEquals", "s3:prefix", "Asia/"),
 			condition:          getInnerMap("prefix", "Africa/"),
 
-			expectedMatch: false,
+			expectedMatch: true,
 		},
 		// Test case - 7.
-		// StringNotEquals condition matches.
+		// StringNotEquals condition doesn't match.
 		{
 
 			statementCondition: getStatementWithCondition("StringNotEquals", "s3:max-keys", "Asia/"),
 			condition:          getInnerMap("max-keys", "Asia/"),
 
-			expectedMatch: true,
+			expectedMatch: false,
 		},
 		// Test case - 8.
-		// StringNotEquals condition doesn't match.
+		// StringNotEquals condition matches.
 		{
 
 			statementCondition: getStatementWithCondition("StringNotEquals", "s3:max-keys", "Asia/"),
 			condition:          getInnerMap("max-keys", "Africa/"),
 
+			expectedMatch: true,
+		},
+		// Test case - 9.
+		// StringEquals referer condition matches.
+		{
+			statementCondition: getStatementWithCondition("StringEquals", "aws:Referer", "http://example.com/"),
+			condition:          getInnerMap("aws:Referer", "http://example.com/"),
+
+			expectedMatch: true,
+		},
+		// Test case - 10.
+		// StringEquals referer condition doesn't match requests without a referer.
+		{
+			statementCondition: getStatementWithCondition("StringEquals", "aws:Referer", "http://example.com/"),
+			condition:          getInnerMap("prefix", "Asia/"),
+
+			expectedMatch: false,
+		},
+		// Test case - 11.
+		// StringNotEquals referer condition matches requests without a referer.
+		{
+			statementCondition: getStatementWithCondition("StringNotEquals", "aws:Referer", "http://example.com/"),
+			condition:          getInnerMap("prefix", "Asia/"),
+
+			expectedMatch: true,
+		},
+		// Test case - 12.
+		// StringLike referer condition matches.
+		{
+			statementCondition: getStatementWithCondition("StringLike", "aws:Referer", "http://*.example.com/*"),
+			condition:          getInnerMap("aws:Referer", "http://www.example.com/index.html"),
+
+			expectedMatch: true,
+		},
+		// Test case - 13.
+		// StringNotLike referer condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("StringNotLike", "aws:Referer", "http://*.example.com/*"),
+			condition:          getInnerMap("aws:Referer", "http://www.example.com/index.html"),
+
+			expectedMatch: false,
+		},
+		// Test case - 14.
+		// IpAddress condition matches.
+		{
+			statementCondition: getStatementWithCondition("IpAddress", "aws:SourceIp", "192.168.1.0/24"),
+			condition:          getInnerMap("aws:SourceIp", "192.168.1.10"),
+
+			expectedMatch: true,
+		},
+		// Test case - 15.
+		// IpAddress condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("IpAddress", "aws:SourceIp", "192.168.1.0/24"),
+			condition:          getInnerMap("aws:SourceIp", "10.0.0.1"),
+
+			expectedMatch: false,
+		},
+		// Test case - 16.
+		// NotIpAddress condition with a single address matches.
+		{
+			statementCondition: getStatementWithCondition("NotIpAddress", "aws:SourceIp", "192.168.1.10"),
+			condition:          getInnerMap("aws:SourceIp", "192.168.1.11"),
+
+			expectedMatch: true,
+		},
+		// Test case - 17.
+		// DateLessThan condition matches.
+		{
+			statementCondition: getStatementWithCondition("DateLessThan", "aws:CurrentTime", "2017-01-01T00:00:00Z"),
+			condition:          getInnerMap("aws:CurrentTime", "2016-12-01T10:00:00Z"),
+
+			expectedMatch: true,
+		},
+		// Test case - 18.
+		// DateGreaterThan condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("DateGreaterThan", "aws:CurrentTime", "2017-01-01"),
+			condition:          getInnerMap("aws:CurrentTime", "2016-12-01T10:00:00Z"),
+
+			expectedMatch: false,
+		},
+		// Test case - 19.
+		// Bool secure transport condition matches.
+		{
+			statementCondition: getStatementWithCondition("Bool", "aws:SecureTransport", "true"),
+			condition:          getInnerMap("aws:SecureTransport", "true"),
+
+			expectedMatch: true,
+		},
+		// Test case - 20.
+		// Bool secure transport condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("Bool", "aws:SecureTransport", "true"),
+			condition:          getInnerMap("aws:SecureTransport", "false"),
+
+			expectedMatch: false,
+		},
+		// Test case - 21.
+		// StringLike prefix condition matches.
+		{
+			statementCondition: getStatementWithCondition("StringLike", "s3:prefix", "home/*"),
+			condition:          getInnerMap("prefix", "home/user/"),
+
+			expectedMatch: true,
+		},
+		// Test case - 22.
+		// StringLike prefix condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("StringLike", "s3:prefix", "home/*"),
+			condition:          getInnerMap("prefix", "etc/"),
+
+			expectedMatch: false,
+		},
+		// Test case - 23.
+		// StringNotLike prefix condition matches.
+		{
+			statementCondition: getStatementWithCondition("StringNotLike", "s3:prefix", "home/*"),
+			condition:          getInnerMap("prefix", "etc/"),
+
+			expectedMatch: true,
+		},
+		// Test case - 24.
+		// StringNotLike prefix condition doesn't match.
+		{
+			statementCondition: getStatementWithCondition("StringNotLike", "s3:prefix", "home/*"),
+			condition:          getInnerMap("prefix", "home/user/"),
+
 			expectedMatch: false,
 		},
 	}
@@ -954,3 +1082,40 @@ func TestBucketPolicyConditionMatch(t *testing.T) {
 		})
 	}
 }
+
+// TestBucketPolicyEvalStatements - Tests that matching Deny statements
+// override matching Allow statements, whatever their order.
+func TestBucketPolicyEvalStatements(t *testing.T) {
+	resource := "arn:aws:s3:::bucket/object"
+	allow := policyStatement{
+		Actions:   set.CreateStringSet("s3:GetObject"),
+		Effect:    "Allow",
+		Resources: set.CreateStringSet("arn:aws:s3:::bucket/*"),
+	}
+	denyInsecure := policyStatement{
+		Actions:    set.CreateStringSet("s3:*"),
+		Conditions: map[string]map[string]set.StringSet{"Bool": {"aws:SecureTransport": set.CreateStringSet("false")}},
+		Effect:     "Deny",
+		Resources:  set.CreateStringSet("arn:aws:s3:::bucket/*"),
+	}
+	insecure := map[string]set.StringSet{"aws:SecureTransport": set.CreateStringSet("false")}
+	secure := map[string]set.StringSet{"aws:SecureTransport": set.CreateStringSet("true")}
+
+	testCases := []struct {
+		statements []policyStatement
+		conditions map[string]set.StringSet
+		allowed    bool
+	}{
+		{[]policyStatement{allow}, insecure, true},
+		{[]policyStatement{allow, denyInsecure}, insecure, false},
+		{[]policyStatement{denyInsecure, allow}, insecure, false},
+		{[]policyStatement{allow, denyInsecure}, secure, true},
+		{[]policyStatement{denyInsecure}, secure, false},
+		{nil, secure, false},
+	}
+	for i, testCase := range testCases {
+		if allowed := bucketPolicyEvalStatements("s3:GetObject", resource, testCase.conditions, testCase.statements); allowed != testCase.allowed {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.allowed, allowed)
+		}
+	}
+}
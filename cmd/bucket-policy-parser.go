@@ -23,8 +23,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/pkg/set"
 )
@@ -40,11 +42,85 @@ var supportedActionMap = set.CreateStringSet("*", "s3:*", "s3:GetObject",
 	"s3:AbortMultipartUpload", "s3:ListBucketMultipartUploads", "s3:ListMultipartUploadParts")
 
 // supported Conditions type.
-var supportedConditionsType = set.CreateStringSet("StringEquals", "StringNotEquals")
+var supportedConditionsType = set.CreateStringSet("StringEquals", "StringNotEquals",
+	"StringLike", "StringNotLike", "IpAddress", "NotIpAddress", "DateEquals", "DateNotEquals",
+	"DateLessThan", "DateLessThanEquals", "DateGreaterThan", "DateGreaterThanEquals", "Bool")
 
 // Validate s3:prefix, s3:max-keys are present if not
 // supported keys for the conditions.
-var supportedConditionsKey = set.CreateStringSet("s3:prefix", "s3:max-keys")
+var supportedConditionsKey = set.CreateStringSet("s3:prefix", "s3:max-keys",
+	"aws:Referer", "aws:SourceIp", "aws:CurrentTime", "aws:SecureTransport")
+
+// Condition keys applicable to each kind of condition type.
+var (
+	stringConditionsKey = set.CreateStringSet("s3:prefix", "s3:max-keys", "aws:Referer")
+	ipConditionsKey     = set.CreateStringSet("aws:SourceIp")
+	dateConditionsKey   = set.CreateStringSet("aws:CurrentTime")
+	boolConditionsKey   = set.CreateStringSet("aws:SecureTransport")
+)
+
+// conditionTypeKeys - returns the condition keys applicable to
+// conditionType.
+func conditionTypeKeys(conditionType string) set.StringSet {
+	switch {
+	case strings.HasPrefix(conditionType, "String"):
+		return stringConditionsKey
+	case strings.HasSuffix(conditionType, "IpAddress"):
+		return ipConditionsKey
+	case strings.HasPrefix(conditionType, "Date"):
+		return dateConditionsKey
+	case conditionType == "Bool":
+		return boolConditionsKey
+	}
+	return set.NewStringSet()
+}
+
+// Date formats accepted by the date condition types.
+var policyDateFormats = []string{time.RFC3339, "2006-01-02"}
+
+// parsePolicyDate - parses the value of a date condition.
+func parsePolicyDate(value string) (t time.Time, err error) {
+	for _, format := range policyDateFormats {
+		if t, err = time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// parsePolicyIPNet - parses the value of an IP address condition, a
+// single address is treated as a /32 or /128 network.
+func parsePolicyIPNet(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("Invalid IP address '%s'", value)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	return ipNet, err
+}
+
+// isValidConditionValue - is value valid for conditionType.
+func isValidConditionValue(conditionType, value string) bool {
+	switch {
+	case strings.HasSuffix(conditionType, "IpAddress"):
+		_, err := parsePolicyIPNet(value)
+		return err == nil
+	case strings.HasPrefix(conditionType, "Date"):
+		_, err := parsePolicyDate(value)
+		return err == nil
+	case conditionType == "Bool":
+		return value == "true" || value == "false"
+	}
+	return true
+}
 
 // supportedEffectMap - supported effects.
 var supportedEffectMap = set.CreateStringSet("Allow", "Deny")
@@ -199,6 +275,16 @@ func isValidConditions(conditions map[string]map[string]set.StringSet) (err erro
 				err = fmt.Errorf("Unsupported condition key '%s', please validate your policy document", conditionType)
 				return err
 			}
+			if !conditionTypeKeys(conditionType).Contains(key) {
+				err = fmt.Errorf("Condition key '%s' cannot be used with condition type '%s', please validate your policy document", key, conditionType)
+				return err
+			}
+			for v := range value {
+				if !isValidConditionValue(conditionType, v) {
+					err = fmt.Errorf("Invalid value '%s' for condition type '%s', please validate your policy document", v, conditionType)
+					return err
+				}
+			}
 			conditionVal, ok := conditionKeyVal[key]
 			if ok && !value.Intersection(conditionVal).IsEmpty() {
 				err = fmt.Errorf("Ambigious condition values for key '%s', please validate your policy document", key)
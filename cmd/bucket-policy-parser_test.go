@@ -421,6 +421,14 @@ func TestIsValidConditions(t *testing.T) {
 		generateConditions("StringEquals", "s3:max-keys", "100"),
 		generateConditions("StringNotEquals", "s3:prefix", "Asia/"),
 		generateConditions("StringNotEquals", "s3:max-keys", "100"),
+		generateConditions("IpAddress", "aws:SourceIp", "192.168.1.0/24"),
+		generateConditions("DateLessThan", "aws:CurrentTime", "2017-01-01T00:00:00Z"),
+		generateConditions("Bool", "aws:SecureTransport", "true"),
+		generateConditions("StringLike", "aws:Referer", "http://*.example.com/*"),
+		generateConditions("IpAddress", "aws:Referer", "192.168.1.0/24"),
+		generateConditions("IpAddress", "aws:SourceIp", "192.168.1.0/33"),
+		generateConditions("DateGreaterThan", "aws:CurrentTime", "yesterday"),
+		generateConditions("Bool", "aws:SecureTransport", "yes"),
 	}
 
 	testCases := []struct {
@@ -466,6 +474,30 @@ func TestIsValidConditions(t *testing.T) {
 		{testConditions[10], nil, true},
 		// Test case 10.
 		{testConditions[11], nil, true},
+		// Test case - 13.
+		{testConditions[12], nil, true},
+		// Test case - 14.
+		{testConditions[13], nil, true},
+		// Test case - 15.
+		{testConditions[14], nil, true},
+		// Test case - 16.
+		{testConditions[15], nil, true},
+		// Test case - 17.
+		// "aws:Referer" can't be used with "IpAddress".
+		{testConditions[16], fmt.Errorf("Condition key 'aws:Referer' cannot be used with condition type " +
+			"'IpAddress', please validate your policy document"), false},
+		// Test case - 18.
+		// Invalid CIDR block.
+		{testConditions[17], fmt.Errorf("Invalid value '192.168.1.0/33' for condition type 'IpAddress', " +
+			"please validate your policy document"), false},
+		// Test case - 19.
+		// Invalid date.
+		{testConditions[18], fmt.Errorf("Invalid value 'yesterday' for condition type 'DateGreaterThan', " +
+			"please validate your policy document"), false},
+		// Test case - 20.
+		// Invalid boolean.
+		{testConditions[19], fmt.Errorf("Invalid value 'yes' for condition type 'Bool', " +
+			"please validate your policy document"), false},
 	}
 	for i, testCase := range testCases {
 		actualErr := isValidConditions(testCase.inputCondition)
@@ -21,19 +21,16 @@ import (
 	"encoding/json"
 	"io"
 	"path"
-	"sync"
 )
 
 // Variable represents bucket policies in memory.
 var globalBucketPolicies *bucketPolicies
 
 // Global bucket policies list, policies are enforced on each bucket looking
-// through the policies here.
+// through the policies here. Policies are read when a bucket is first used.
 type bucketPolicies struct {
-	rwMutex *sync.RWMutex
-
 	// Collection of 'bucket' policies.
-	bucketPolicyConfigs map[string]*bucketPolicy
+	bucketPolicyConfigs *bucketMetadataCache
 }
 
 // Represent a policy change
@@ -46,85 +43,59 @@ type policyChange struct {
 	BktPolicy *bucketPolicy
 }
 
-// Fetch bucket policy for a given bucket.
+// Fetch bucket policy for a given bucket, nil if none or if it
+// cannot be read.
 func (bp bucketPolicies) GetBucketPolicy(bucket string) *bucketPolicy {
-	bp.rwMutex.RLock()
-	defer bp.rwMutex.RUnlock()
-	return bp.bucketPolicyConfigs[bucket]
+	value, err := bp.bucketPolicyConfigs.Get(bucket)
+	if err != nil {
+		return nil
+	}
+	policy, _ := value.(*bucketPolicy)
+	return policy
 }
 
 // Set a new bucket policy for a bucket, this operation will overwrite
 // any previous bucket policies for the bucket.
 func (bp *bucketPolicies) SetBucketPolicy(bucket string, pCh policyChange) error {
-	bp.rwMutex.Lock()
-	defer bp.rwMutex.Unlock()
-
 	if pCh.IsRemove {
-		delete(bp.bucketPolicyConfigs, bucket)
-	} else {
-		if pCh.BktPolicy == nil {
-			return errInvalidArgument
-		}
-		bp.bucketPolicyConfigs[bucket] = pCh.BktPolicy
+		bp.bucketPolicyConfigs.Set(bucket, nil)
+		return nil
+	}
+	if pCh.BktPolicy == nil {
+		return errInvalidArgument
 	}
+	bp.bucketPolicyConfigs.Set(bucket, pCh.BktPolicy)
 	return nil
 }
 
-// Loads all bucket policies from persistent layer.
-func loadAllBucketPolicies(objAPI ObjectLayer) (policies map[string]*bucketPolicy, err error) {
-	// List buckets to proceed loading all notification configuration.
-	buckets, err := objAPI.ListBuckets()
-	errorIf(err, "Unable to list buckets.")
-	if err != nil {
-		return nil, errorCause(err)
-	}
-
-	policies = make(map[string]*bucketPolicy)
-	var pErrs []error
-	// Loads bucket policy.
-	for _, bucket := range buckets {
-		policy, pErr := readBucketPolicy(bucket.Name, objAPI)
-		if pErr != nil {
-			// net.Dial fails for rpc client or any
-			// other unexpected errors during net.Dial.
-			if !isErrIgnored(pErr, errDiskNotFound) {
-				if !isErrBucketPolicyNotFound(pErr) {
-					pErrs = append(pErrs, pErr)
-				}
-			}
-			// Continue to load other bucket policies if possible.
-			continue
-		}
-		policies[bucket.Name] = policy
+// loadBucketPolicy - reads the policy of a bucket for the policy
+// cache, nil if the bucket has none. Buckets which do not exist are
+// not cached.
+func loadBucketPolicy(bucket string, objAPI ObjectLayer) (interface{}, error) {
+	if _, err := objAPI.GetBucketInfo(bucket); err != nil {
+		return nil, err
 	}
-
-	// Look for any errors occurred while reading bucket policies.
-	for _, pErr := range pErrs {
-		if pErr != nil {
-			return policies, pErr
+	policy, err := readBucketPolicy(bucket, objAPI)
+	if err != nil {
+		if isErrBucketPolicyNotFound(err) {
+			return nil, nil
 		}
+		return nil, err
 	}
-
-	// Success.
-	return policies, nil
+	return policy, nil
 }
 
-// Intialize all bucket policies.
+// Intialize bucket policies, they are loaded on first use.
 func initBucketPolicies(objAPI ObjectLayer) error {
 	if objAPI == nil {
 		return errInvalidArgument
 	}
 
-	// Read all bucket policies.
-	policies, err := loadAllBucketPolicies(objAPI)
-	if err != nil {
-		return err
-	}
-
 	// Populate global bucket collection.
 	globalBucketPolicies = &bucketPolicies{
-		rwMutex:             &sync.RWMutex{},
-		bucketPolicyConfigs: policies,
+		bucketPolicyConfigs: newBucketMetadataCache(func(bucket string) (interface{}, error) {
+			return loadBucketPolicy(bucket, objAPI)
+		}),
 	}
 
 	// Success.
@@ -0,0 +1,221 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+
+	mux "github.com/gorilla/mux"
+)
+
+// Maximum size of a transform configuration document.
+const maxTransformConfigSize = 64 * 1024
+
+// HTTP client used to stream objects through bucket transforms.
+var transformHTTPClient = &http.Client{}
+
+// GetBucketTransformHandler - GET Bucket transform
+// -----------------
+// Returns the transform configuration of a bucket.
+func (api objectAPIHandlers) GetBucketTransformHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to find bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	tcfg, err := loadTransformConfig(bucket, objAPI)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, encodeResponse(tcfg))
+}
+
+// PutBucketTransformHandler - PUT Bucket transform
+// -----------------
+// Sets the transform endpoint of a bucket, GET Object responses of the
+// bucket are streamed through the endpoint before being returned.
+func (api objectAPIHandlers) PutBucketTransformHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to find bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	var tcfg transformConfig
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxTransformConfigSize)).Decode(&tcfg); err != nil {
+		errorIf(err, "Unable to parse transform configuration XML.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if s3Error := validateTransformConfig(tcfg); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := PutBucketTransformConfig(bucket, &tcfg, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// DeleteBucketTransformHandler - DELETE Bucket transform
+// -----------------
+// Removes the transform configuration of a bucket.
+func (api objectAPIHandlers) DeleteBucketTransformHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := PutBucketTransformConfig(bucket, nil, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessNoContent(w)
+}
+
+// PutBucketTransformConfig - Put a new transform config for a bucket,
+// nil removes the config, persistently, updates global in-memory state,
+// and notify other nodes in the cluster (if any)
+func PutBucketTransformConfig(bucket string, tcfg *transformConfig, objAPI ObjectLayer) error {
+	// Acquire a write lock on bucket before modifying its
+	// configuration.
+	bucketLock := nsMutex.NewNSLock(bucket, "")
+	bucketLock.Lock()
+	// Release lock after notifying peers
+	defer bucketLock.Unlock()
+
+	var err error
+	if tcfg == nil {
+		err = removeTransformConfig(bucket, objAPI)
+	} else {
+		err = persistTransformConfig(bucket, tcfg, objAPI)
+	}
+	if err != nil {
+		return err
+	}
+
+	// All servers (including local) are told to update in-memory config
+	S3PeersUpdateBucketTransform(bucket, tcfg)
+
+	return nil
+}
+
+// transformObject - streams the object to the transform endpoint and
+// the response of the endpoint to the client. The object is posted
+// along with the context of the original request, any failure of the
+// endpoint is reported as ErrTransformFailed.
+func transformObject(w http.ResponseWriter, r *http.Request, objectAPI ObjectLayer, objInfo ObjectInfo, tcfg *transformConfig) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(objectAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, pw))
+	}()
+	defer pr.Close()
+
+	req, err := http.NewRequest("POST", tcfg.Endpoint, pr)
+	if err != nil {
+		errorIf(err, "Unable to create transform request.")
+		writeErrorResponse(w, r, ErrTransformFailed, r.URL.Path)
+		return
+	}
+	req.ContentLength = objInfo.Size
+	req.Header.Set("Content-Type", objInfo.ContentType)
+	req.Header.Set("X-Minio-Transform-Bucket", objInfo.Bucket)
+	req.Header.Set("X-Minio-Transform-Object", objInfo.Name)
+	req.Header.Set("X-Minio-Transform-Request-Url", r.URL.RequestURI())
+	req.Header.Set("X-Minio-Transform-Etag", objInfo.MD5Sum)
+	for _, header := range []string{"User-Agent", "Range", "Accept", "Accept-Encoding"} {
+		if value := r.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+
+	resp, err := transformHTTPClient.Do(req)
+	if err != nil {
+		errorIf(err, "Unable to reach transform endpoint %s.", tcfg.Endpoint)
+		writeErrorResponse(w, r, ErrTransformFailed, r.URL.Path)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		errorIf(errTransformFailed, "Transform endpoint %s returned %s.", tcfg.Endpoint, resp.Status)
+		writeErrorResponse(w, r, ErrTransformFailed, r.URL.Path)
+		return
+	}
+
+	setCommonHeaders(w)
+	w.Header().Set("Last-Modified", objInfo.ModTime.UTC().Format(http.TimeFormat))
+	for _, header := range []string{"Content-Type", "Content-Range", "Content-Encoding", "Content-Disposition", "Cache-Control"} {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	if resp.ContentLength >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err = io.Copy(w, resp.Body); err != nil {
+		errorIf(err, "Unable to write transformed object to client.")
+	}
+}
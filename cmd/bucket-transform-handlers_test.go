@@ -0,0 +1,154 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Wrapper for calling bucket transform HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIBucketTransformHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIBucketTransformHandlers, []string{"BucketTransform"})
+}
+
+func testAPIBucketTransformHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	// Transform updates are applied through the local peer.
+	savedPeers := globalS3Peers
+	initGlobalS3Peers(nil)
+	defer func() { globalS3Peers = savedPeers }()
+
+	// Transform endpoint upper cases the object, the failing endpoint
+	// always returns an error.
+	transformServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("X-Minio-Transform-Bucket") != bucketName || r.Header.Get("X-Minio-Transform-Object") != "object.txt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/x-transformed")
+		w.Write(bytes.ToUpper(data))
+	}))
+	defer transformServer.Close()
+
+	objectData := []byte("hello world")
+	if _, err := obj.PutObject(bucketName, "object.txt", int64(len(objectData)), bytes.NewReader(objectData), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	transformQuery := url.Values{}
+	transformQuery.Set("transform", "")
+	configXML := func(endpoint string) []byte {
+		return []byte("<TransformConfiguration><Endpoint>" + endpoint + "</Endpoint></TransformConfiguration>")
+	}
+
+	testCases := []struct {
+		method             string
+		objectName         string
+		body               []byte
+		expectedRespStatus int
+		expectedBody       string
+	}{
+		// Test case - 1.
+		// No transform configured yet.
+		{"GET", "", nil, http.StatusNotFound, ""},
+		// Test case - 2.
+		// Invalid endpoint.
+		{"PUT", "", configXML("ftp://localhost/transform"), http.StatusBadRequest, ""},
+		// Test case - 3.
+		// Malformed configuration.
+		{"PUT", "", []byte("<TransformConfiguration>"), http.StatusBadRequest, ""},
+		// Test case - 4.
+		// Set the transform and read the object through it.
+		{"PUT", "", configXML(transformServer.URL + "/upper"), http.StatusOK, ""},
+		{"GET", "object.txt", nil, http.StatusOK, "HELLO WORLD"},
+		// Test case - 6.
+		// Failing transform is reported as 5xx.
+		{"PUT", "", configXML(transformServer.URL + "/fail"), http.StatusOK, ""},
+		{"GET", "object.txt", nil, http.StatusBadGateway, ""},
+		// Test case - 8.
+		// Remove the transform, the object is returned unmodified.
+		{"DELETE", "", nil, http.StatusNoContent, ""},
+		{"GET", "object.txt", nil, http.StatusOK, "hello world"},
+		{"DELETE", "", nil, http.StatusNotFound, ""},
+	}
+
+	for i, testCase := range testCases {
+		queryValues := transformQuery
+		if testCase.objectName != "" {
+			queryValues = url.Values{}
+		}
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(testCase.method, makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if testCase.expectedBody != "" && rec.Body.String() != testCase.expectedBody {
+			t.Errorf("Test %d: %s: Expected body %q, got %q", i+1, instanceType, testCase.expectedBody, rec.Body.String())
+		}
+	}
+
+	// Verify the configuration is returned as set.
+	if _, err := obj.GetBucketInfo(bucketName); err != nil {
+		t.Fatal(err)
+	}
+	tcfg := &transformConfig{Endpoint: transformServer.URL + "/upper"}
+	if err := PutBucketTransformConfig(bucketName, tcfg, obj); err != nil {
+		t.Fatalf("%s: Unable to set transform: %v", instanceType, err)
+	}
+	rec := httptest.NewRecorder()
+	req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, "", transformQuery),
+		0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	var gotCfg transformConfig
+	if err = xml.Unmarshal(rec.Body.Bytes(), &gotCfg); err != nil {
+		t.Fatalf("%s: Unable to decode transform configuration: %v", instanceType, err)
+	}
+	if gotCfg.Endpoint != tcfg.Endpoint {
+		t.Errorf("%s: Expected endpoint %s, got %s", instanceType, tcfg.Endpoint, gotCfg.Endpoint)
+	}
+
+	// Reload from the backend, as done at server start.
+	globalBucketTransforms.SetBucketTransform(bucketName, nil)
+	if err = initBucketTransforms(obj); err != nil {
+		t.Fatalf("%s: Unable to load bucket transforms: %v", instanceType, err)
+	}
+	if globalBucketTransforms.GetBucketTransform(bucketName) == nil {
+		t.Errorf("%s: Expected transform to be loaded", instanceType)
+	}
+	if err = PutBucketTransformConfig(bucketName, nil, obj); err != nil {
+		t.Fatalf("%s: Unable to remove transform: %v", instanceType, err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"path"
+)
+
+const (
+	// Transform configuration file stored per bucket.
+	bucketTransformConfig = "transform.xml"
+)
+
+// transformConfig - represents the transform configuration of a
+// bucket, GET Object responses are streamed through Endpoint.
+type transformConfig struct {
+	XMLName  xml.Name `xml:"TransformConfiguration"`
+	Endpoint string
+}
+
+// validateTransformConfig - validates the transform endpoint.
+func validateTransformConfig(tcfg transformConfig) APIErrorCode {
+	u, err := url.Parse(tcfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidTransformEndpoint
+	}
+	return ErrNone
+}
+
+// Variable represents bucket transforms in memory.
+var globalBucketTransforms = &bucketTransforms{
+	transformConfigs: newBucketMetadataCache(nil),
+}
+
+// Global bucket transforms list, GET Object on these buckets is served
+// through the configured transform. Transforms are read when a bucket
+// is first used.
+type bucketTransforms struct {
+	// Collection of 'bucket' transforms.
+	transformConfigs *bucketMetadataCache
+}
+
+// Fetch bucket transform for a given bucket, nil if none or if it
+// cannot be read.
+func (bt bucketTransforms) GetBucketTransform(bucket string) *transformConfig {
+	value, err := bt.transformConfigs.Get(bucket)
+	if err != nil {
+		return nil
+	}
+	tcfg, _ := value.(*transformConfig)
+	return tcfg
+}
+
+// Set a new bucket transform for a bucket, nil removes any previous
+// transform of the bucket.
+func (bt *bucketTransforms) SetBucketTransform(bucket string, tcfg *transformConfig) {
+	bt.transformConfigs.Set(bucket, tcfg)
+}
+
+// Intialize bucket transforms, they are loaded on first use. Buckets
+// which do not exist are not cached.
+func initBucketTransforms(objAPI ObjectLayer) error {
+	if objAPI == nil {
+		return errInvalidArgument
+	}
+
+	globalBucketTransforms.transformConfigs = newBucketMetadataCache(func(bucket string) (interface{}, error) {
+		if _, err := objAPI.GetBucketInfo(bucket); err != nil {
+			return nil, err
+		}
+		tcfg, err := loadTransformConfig(bucket, objAPI)
+		if err == errNoSuchTransform {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return tcfg, nil
+	})
+
+	// Success.
+	return nil
+}
+
+// loadTransformConfig - loads the transform config of a bucket, returns
+// errNoSuchTransform if the bucket has none.
+func loadTransformConfig(bucket string, objAPI ObjectLayer) (*transformConfig, error) {
+	transformConfigPath := path.Join(bucketConfigPrefix, bucket, bucketTransformConfig)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, transformConfigPath)
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errNoSuchTransform
+		}
+		errorIf(err, "Unable to load bucket-transform for bucket %s", bucket)
+		return nil, errorCause(err)
+	}
+	var buffer bytes.Buffer
+	err = objAPI.GetObject(minioMetaBucket, transformConfigPath, 0, objInfo.Size, &buffer)
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errNoSuchTransform
+		}
+		errorIf(err, "Unable to load bucket-transform for bucket %s", bucket)
+		return nil, errorCause(err)
+	}
+
+	tcfg := &transformConfig{}
+	if err = xml.Unmarshal(buffer.Bytes(), tcfg); err != nil {
+		return nil, err
+	}
+	return tcfg, nil
+}
+
+// persistTransformConfig - persists validated transform config to
+// object layer.
+func persistTransformConfig(bucket string, tcfg *transformConfig, objAPI ObjectLayer) error {
+	buf, err := xml.Marshal(tcfg)
+	if err != nil {
+		errorIf(err, "Unable to marshal transform configuration into XML")
+		return err
+	}
+
+	transformConfigPath := path.Join(bucketConfigPrefix, bucket, bucketTransformConfig)
+	sha256Sum := getSHA256Hash(buf)
+	if _, err = objAPI.PutObject(minioMetaBucket, transformConfigPath, int64(len(buf)), bytes.NewReader(buf), nil, sha256Sum); err != nil {
+		errorIf(err, "Unable to write bucket transform configuration.")
+		return errorCause(err)
+	}
+	return nil
+}
+
+// removeTransformConfig - removes the transform config of a bucket,
+// returns errNoSuchTransform if the bucket has none.
+func removeTransformConfig(bucket string, objAPI ObjectLayer) error {
+	transformConfigPath := path.Join(bucketConfigPrefix, bucket, bucketTransformConfig)
+	if err := objAPI.DeleteObject(minioMetaBucket, transformConfigPath); err != nil {
+		if isErrObjectNotFound(err) {
+			return errNoSuchTransform
+		}
+		errorIf(err, "Unable to remove bucket-transform on bucket %s.", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// Versioning configuration file stored per bucket.
+	bucketVersioningConfig = "versioning.xml"
+
+	// Directories of the content and the object info of prior
+	// versions of a bucket's objects.
+	objectVersionsPrefix    = "versions"
+	objectVersionInfoPrefix = "version-info"
+
+	// Metadata and response header carrying the version ID of an
+	// object.
+	objectVersionIDHeader = "X-Amz-Version-Id"
+
+	// Version ID of objects written while versioning was not enabled.
+	nullVersionID = "null"
+)
+
+// Valid versioning states of a bucket, buckets which were never
+// versioned have an empty status.
+const (
+	versioningEnabled   = "Enabled"
+	versioningSuspended = "Suspended"
+)
+
+// versioningConfig - represents the versioning configuration of a
+// bucket, objects written while versioning is enabled get a version
+// ID and the versions they replace remain retrievable.
+type versioningConfig struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Status  string   `xml:",omitempty"`
+}
+
+// objectVersionV1 - object info of a prior version of an object, kept
+// apart from its content as the meta bucket does not keep the metadata
+// of objects on every backend.
+type objectVersionV1 struct {
+	Version string     `json:"version"`
+	Info    ObjectInfo `json:"info"`
+}
+
+// loadVersioningConfig - loads the versioning config of a bucket, an
+// empty config if the bucket was never versioned.
+func loadVersioningConfig(bucket string, objAPI ObjectLayer) (*versioningConfig, error) {
+	data, err := readBucketConfigFile(bucket, bucketVersioningConfig, objAPI)
+	if err == errConfigNotFound {
+		return &versioningConfig{}, nil
+	}
+	if err != nil {
+		errorIf(err, "Unable to load bucket-versioning for bucket %s", bucket)
+		return nil, err
+	}
+	vcfg := &versioningConfig{}
+	if err = xml.Unmarshal(data, vcfg); err != nil {
+		return nil, err
+	}
+	return vcfg, nil
+}
+
+// persistVersioningConfig - persists validated versioning config to
+// object layer.
+func persistVersioningConfig(bucket string, vcfg *versioningConfig, objAPI ObjectLayer) error {
+	data, err := xml.Marshal(vcfg)
+	if err != nil {
+		errorIf(err, "Unable to marshal versioning configuration into XML")
+		return err
+	}
+	return writeBucketConfigFile(bucket, bucketVersioningConfig, data, objAPI)
+}
+
+// removeVersioningConfig - removes the versioning config of a bucket
+// along with all prior versions of its objects.
+func removeVersioningConfig(bucket string, objAPI ObjectLayer) error {
+	configPath := path.Join(bucketConfigPrefix, bucket, bucketVersioningConfig)
+	if err := objAPI.DeleteObject(minioMetaBucket, configPath); err != nil && !isErrObjectNotFound(err) {
+		return errorCause(err)
+	}
+	for _, dir := range []string{objectVersionsPrefix, objectVersionInfoPrefix} {
+		prefix := path.Join(bucketConfigPrefix, bucket, dir) + slashSeparator
+		marker := ""
+		for {
+			lo, err := objAPI.ListObjects(minioMetaBucket, prefix, marker, "", 1000)
+			if err != nil {
+				return errorCause(err)
+			}
+			for _, obj := range lo.Objects {
+				if err = objAPI.DeleteObject(minioMetaBucket, obj.Name); err != nil && !isErrObjectNotFound(err) {
+					return errorCause(err)
+				}
+			}
+			if !lo.IsTruncated {
+				break
+			}
+			marker = lo.NextMarker
+		}
+	}
+	return nil
+}
+
+// isValidVersionID - returns true if versionID is "null" or a version
+// ID generated by mustGetUUID.
+func isValidVersionID(versionID string) bool {
+	if versionID == nullVersionID {
+		return true
+	}
+	if len(versionID) != 36 {
+		return false
+	}
+	for i, c := range versionID {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getObjectVersionID - returns the version ID of an object.
+func getObjectVersionID(objInfo ObjectInfo) string {
+	if versionID := objInfo.UserDefined[objectVersionIDHeader]; versionID != "" {
+		return versionID
+	}
+	return nullVersionID
+}
+
+// getObjectVersionPath - returns the path in the meta bucket of the
+// content of a prior version of an object.
+func getObjectVersionPath(bucket, object, versionID string) string {
+	return path.Join(bucketConfigPrefix, bucket, objectVersionsPrefix, versionID, object)
+}
+
+// getObjectVersionInfoFile - returns the name of the bucket
+// configuration file holding the object info of a prior version of an
+// object.
+func getObjectVersionInfoFile(object, versionID string) string {
+	return path.Join(objectVersionInfoPrefix, versionID, object) + ".json"
+}
+
+// archiveObjectVersion - keeps a copy of the current version of an
+// object before it is replaced or deleted. Unversioned objects are
+// only kept while versioning is enabled, a new unversioned object
+// replaces them otherwise.
+func archiveObjectVersion(objAPI ObjectLayer, bucket, object, status string) error {
+	if status == "" {
+		return nil
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		if isErrObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	versionID := getObjectVersionID(objInfo)
+	if versionID == nullVersionID && status != versioningEnabled {
+		return nil
+	}
+	if _, err = objAPI.CopyObject(bucket, object, minioMetaBucket, getObjectVersionPath(bucket, object, versionID), nil); err != nil {
+		return err
+	}
+	if objInfo.UserDefined == nil {
+		objInfo.UserDefined = make(map[string]string)
+	}
+	objInfo.UserDefined[objectVersionIDHeader] = versionID
+	data, err := json.Marshal(objectVersionV1{Version: "1", Info: objInfo})
+	if err != nil {
+		return err
+	}
+	return writeBucketConfigFile(bucket, getObjectVersionInfoFile(object, versionID), data, objAPI)
+}
+
+// setObjectVersionID - sets a new version ID in the metadata of an
+// object about to be written, and returns it. Objects written while
+// versioning is not enabled have no version ID.
+func setObjectVersionID(metadata map[string]string, status string) string {
+	delete(metadata, objectVersionIDHeader)
+	if status != versioningEnabled {
+		return ""
+	}
+	versionID := mustGetUUID()
+	metadata[objectVersionIDHeader] = versionID
+	return versionID
+}
+
+// getBucketVersioning - returns the versioning status of a bucket.
+func getBucketVersioning(objAPI ObjectLayer, bucket string) (string, error) {
+	vcfg, err := loadVersioningConfig(bucket, objAPI)
+	if err != nil {
+		return "", err
+	}
+	return vcfg.Status, nil
+}
+
+// prepareObjectVersion - archives the current version of an object
+// about to be replaced, and sets the version ID of the new object in
+// metadata. Returns the new version ID, empty if there is none.
+func prepareObjectVersion(objAPI ObjectLayer, bucket, object string, metadata map[string]string) (string, error) {
+	status, err := getBucketVersioning(objAPI, bucket)
+	if err != nil {
+		return "", err
+	}
+	if err = archiveObjectVersion(objAPI, bucket, object, status); err != nil {
+		errorIf(err, "Unable to keep the current version of %s/%s.", bucket, object)
+		return "", err
+	}
+	return setObjectVersionID(metadata, status), nil
+}
+
+// deleteObjectVersioned - deletes the current version of an object,
+// keeping a copy if the bucket is versioned.
+func deleteObjectVersioned(objAPI ObjectLayer, bucket, object string) error {
+	status, err := getBucketVersioning(objAPI, bucket)
+	if err != nil {
+		return err
+	}
+	if err = archiveObjectVersion(objAPI, bucket, object, status); err != nil {
+		errorIf(err, "Unable to keep the current version of %s/%s.", bucket, object)
+		return err
+	}
+	return objAPI.DeleteObject(bucket, object)
+}
+
+// getObjectVersionInfo - returns the object info of a version of an
+// object, which is read from the bucket and object of the returned
+// info. Returns errNoSuchVersion if the version does not exist.
+func getObjectVersionInfo(objAPI ObjectLayer, bucket, object, versionID string) (ObjectInfo, error) {
+	if !isValidVersionID(versionID) {
+		return ObjectInfo{}, errNoSuchVersion
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err == nil && getObjectVersionID(objInfo) == versionID {
+		return objInfo, nil
+	}
+	if err != nil && !isErrObjectNotFound(err) {
+		return ObjectInfo{}, err
+	}
+	return readObjectVersionInfo(objAPI, bucket, object, versionID)
+}
+
+// readObjectVersionInfo - returns the object info of a prior version
+// of an object, which is read from the bucket and object of the
+// returned info. Returns errNoSuchVersion if the version does not
+// exist.
+func readObjectVersionInfo(objAPI ObjectLayer, bucket, object, versionID string) (ObjectInfo, error) {
+	data, err := readBucketConfigFile(bucket, getObjectVersionInfoFile(object, versionID), objAPI)
+	if err == errConfigNotFound {
+		return ObjectInfo{}, errNoSuchVersion
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	var version objectVersionV1
+	if err = json.Unmarshal(data, &version); err != nil {
+		return ObjectInfo{}, err
+	}
+	objInfo := version.Info
+	objInfo.Bucket = minioMetaBucket
+	objInfo.Name = getObjectVersionPath(bucket, object, versionID)
+	return objInfo, nil
+}
+
+// parseObjectVersionPath - returns the bucket, object and version ID
+// of the content of a prior version of an object at name in the meta
+// bucket, the inverse of getObjectVersionPath.
+func parseObjectVersionPath(name string) (bucket, object, versionID string, ok bool) {
+	elems := strings.SplitN(name, slashSeparator, 5)
+	if len(elems) != 5 || elems[0] != bucketConfigPrefix || elems[2] != objectVersionsPrefix {
+		return "", "", "", false
+	}
+	if !isValidVersionID(elems[3]) {
+		return "", "", "", false
+	}
+	return elems[1], elems[4], elems[3], true
+}
+
+// getRequestedObjectInfo - returns the object info of the version of
+// an object selected by the versionId query parameter of r, of the
+// current version if there is none.
+func getRequestedObjectInfo(objAPI ObjectLayer, bucket, object string, r *http.Request) (ObjectInfo, error) {
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		return getObjectVersionInfo(objAPI, bucket, object, versionID)
+	}
+	return objAPI.GetObjectInfo(bucket, object)
+}
+
+// deleteObjectVersion - permanently deletes a version of an object.
+func deleteObjectVersion(objAPI ObjectLayer, bucket, object, versionID string) error {
+	objInfo, err := getObjectVersionInfo(objAPI, bucket, object, versionID)
+	if err != nil {
+		return err
+	}
+	if objInfo.Bucket != minioMetaBucket {
+		return objAPI.DeleteObject(bucket, object)
+	}
+	infoPath := path.Join(bucketConfigPrefix, bucket, getObjectVersionInfoFile(object, versionID))
+	if err = objAPI.DeleteObject(minioMetaBucket, infoPath); err != nil {
+		return err
+	}
+	// The version is gone once its info is - ignore any errors.
+	_ = objAPI.DeleteObject(minioMetaBucket, objInfo.Name)
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"testing"
+)
+
+// Tests validation of version IDs used in paths.
+func TestIsValidVersionID(t *testing.T) {
+	testCases := []struct {
+		versionID string
+		valid     bool
+	}{
+		{nullVersionID, true},
+		{mustGetUUID(), true},
+		{"", false},
+		{"../../config.json", false},
+		{"0123456789abcdef0123456789abcdef0123", false},
+		{"0123456-89ab-cdef-0123-456789abcdef0", false},
+	}
+	for i, testCase := range testCases {
+		if valid := isValidVersionID(testCase.versionID); valid != testCase.valid {
+			t.Errorf("Test %d: Expected %v for %q, got %v", i+1, testCase.valid, testCase.versionID, valid)
+		}
+	}
+}
+
+// Tests that replaced and deleted versions remain retrievable.
+func TestObjectVersions(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectVersions)
+}
+
+func testObjectVersions(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket, object := "versioned-bucket", "dir/object"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	putVersion := func(data string) string {
+		metadata := make(map[string]string)
+		versionID, err := prepareObjectVersion(obj, bucket, object, metadata)
+		if err != nil {
+			t.Fatalf("%s: Unable to prepare version: %v", instanceType, err)
+		}
+		if _, err = obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader([]byte(data)), metadata, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object: %v", instanceType, err)
+		}
+		return versionID
+	}
+	readVersion := func(versionID string) (string, error) {
+		objInfo, err := getObjectVersionInfo(obj, bucket, object, versionID)
+		if err != nil {
+			return "", err
+		}
+		data, err := readTestObject(obj, objInfo.Bucket, objInfo.Name)
+		return string(data), err
+	}
+
+	// Unversioned buckets keep no versions.
+	if versionID := putVersion("unversioned"); versionID != "" {
+		t.Fatalf("%s: Expected no version ID, got %s", instanceType, versionID)
+	}
+	if err := persistVersioningConfig(bucket, &versioningConfig{Status: versioningEnabled}, obj); err != nil {
+		t.Fatalf("%s: Unable to enable versioning: %v", instanceType, err)
+	}
+
+	v1 := putVersion("first")
+	v2 := putVersion("second")
+	if v1 == "" || v2 == "" || v1 == v2 {
+		t.Fatalf("%s: Expected distinct version IDs, got %q and %q", instanceType, v1, v2)
+	}
+	if err := deleteObjectVersioned(obj, bucket, object); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	if _, err := obj.GetObjectInfo(bucket, object); !isErrObjectNotFound(err) {
+		t.Fatalf("%s: Expected the object to be deleted, got %v", instanceType, err)
+	}
+
+	expected := map[string]string{nullVersionID: "unversioned", v1: "first", v2: "second"}
+	for versionID, data := range expected {
+		if got, err := readVersion(versionID); err != nil || got != data {
+			t.Errorf("%s: Expected %q for version %s, got %q, %v", instanceType, data, versionID, got, err)
+		}
+	}
+	if _, err := readVersion(mustGetUUID()); err != errNoSuchVersion {
+		t.Errorf("%s: Expected errNoSuchVersion, got %v", instanceType, err)
+	}
+
+	// Deleting a version removes it permanently.
+	if err := deleteObjectVersion(obj, bucket, object, v1); err != nil {
+		t.Fatalf("%s: Unable to delete version: %v", instanceType, err)
+	}
+	if _, err := readVersion(v1); err != errNoSuchVersion {
+		t.Errorf("%s: Expected errNoSuchVersion after delete, got %v", instanceType, err)
+	}
+
+	// Suspended versioning replaces unversioned objects.
+	if err := persistVersioningConfig(bucket, &versioningConfig{Status: versioningSuspended}, obj); err != nil {
+		t.Fatalf("%s: Unable to suspend versioning: %v", instanceType, err)
+	}
+	putVersion("suspended")
+	putVersion("suspended again")
+	if got, err := readVersion(nullVersionID); err != nil || got != "suspended again" {
+		t.Errorf("%s: Expected the current null version, got %q, %v", instanceType, got, err)
+	}
+
+	// Removing the configuration removes all versions.
+	if err := removeVersioningConfig(bucket, obj); err != nil {
+		t.Fatalf("%s: Unable to remove versioning config: %v", instanceType, err)
+	}
+	if _, err := obj.GetObjectInfo(minioMetaBucket, getObjectVersionPath(bucket, object, v2)); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected versions to be removed, got %v", instanceType, err)
+	}
+	if _, err := obj.GetObjectInfo(minioMetaBucket, path.Join(bucketConfigPrefix, bucket, bucketVersioningConfig)); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected versioning configuration to be removed, got %v", instanceType, err)
+	}
+}
+
+// Wrapper for calling bucket versioning HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIBucketVersioningHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIBucketVersioningHandlers, []string{"BucketVersioning"})
+}
+
+func testAPIBucketVersioningHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "versioned-object"
+	versioningXML := func(status string) []byte {
+		return []byte(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>` + status + `</Status></VersioningConfiguration>`)
+	}
+	versioningQuery := url.Values{}
+	versioningQuery.Set("versioning", "")
+
+	send := func(method, object string, queryValues url.Values, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, makeTestTargetURL("", bucketName, object, queryValues),
+			int64(len(body)), bytes.NewReader(body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+	getStatus := func() string {
+		rec := send("GET", "", versioningQuery, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: Expected the response status to be `200`, but instead found `%d`", instanceType, rec.Code)
+		}
+		var vcfg versioningConfig
+		if err := xml.Unmarshal(rec.Body.Bytes(), &vcfg); err != nil {
+			t.Fatalf("%s: Unable to parse response: %v", instanceType, err)
+		}
+		return vcfg.Status
+	}
+
+	if status := getStatus(); status != "" {
+		t.Fatalf("%s: Expected no versioning status, got %s", instanceType, status)
+	}
+	if rec := send("PUT", "", versioningQuery, versioningXML("Disabled")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected an invalid status to be rejected, got `%d`", instanceType, rec.Code)
+	}
+	if rec := send("PUT", "", versioningQuery, []byte("<VersioningConfiguration>")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected malformed XML to be rejected, got `%d`", instanceType, rec.Code)
+	}
+	if rec := send("PUT", "", versioningQuery, versioningXML(versioningEnabled)); rec.Code != http.StatusOK {
+		t.Fatalf("%s: Unable to enable versioning, got `%d`", instanceType, rec.Code)
+	}
+	if status := getStatus(); status != versioningEnabled {
+		t.Fatalf("%s: Expected versioning to be enabled, got %s", instanceType, status)
+	}
+
+	// Each PUT returns a new version ID.
+	var versionIDs []string
+	for _, data := range []string{"first", "second"} {
+		rec := send("PUT", objectName, nil, []byte(data))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: Unable to upload object, got `%d`", instanceType, rec.Code)
+		}
+		versionIDs = append(versionIDs, rec.Header().Get(objectVersionIDHeader))
+	}
+	if versionIDs[0] == "" || versionIDs[0] == versionIDs[1] {
+		t.Fatalf("%s: Expected distinct version IDs, got %v", instanceType, versionIDs)
+	}
+
+	// GET serves the current version, or the one asked for.
+	rec := send("GET", objectName, nil, nil)
+	if rec.Body.String() != "second" || rec.Header().Get(objectVersionIDHeader) != versionIDs[1] {
+		t.Errorf("%s: Unexpected current version %q, %s", instanceType, rec.Body.String(), rec.Header().Get(objectVersionIDHeader))
+	}
+	versionQuery := url.Values{}
+	versionQuery.Set("versionId", versionIDs[0])
+	rec = send("GET", objectName, versionQuery, nil)
+	if rec.Body.String() != "first" || rec.Header().Get(objectVersionIDHeader) != versionIDs[0] {
+		t.Errorf("%s: Unexpected prior version %q, %s", instanceType, rec.Body.String(), rec.Header().Get(objectVersionIDHeader))
+	}
+	if rec = send("HEAD", objectName, versionQuery, nil); rec.Code != http.StatusOK || rec.Header().Get("Content-Length") != "5" {
+		t.Errorf("%s: Unexpected HEAD of prior version `%d`, %s", instanceType, rec.Code, rec.Header().Get("Content-Length"))
+	}
+
+	// Deleting a version removes it, the current version is kept.
+	if rec = send("DELETE", objectName, versionQuery, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Unable to delete version, got `%d`", instanceType, rec.Code)
+	}
+	if rec = send("GET", objectName, versionQuery, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("%s: Expected the deleted version to be missing, got `%d`", instanceType, rec.Code)
+	}
+	if rec = send("GET", objectName, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("%s: Expected the current version to remain, got `%d`", instanceType, rec.Code)
+	}
+}
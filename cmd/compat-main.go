@@ -0,0 +1,109 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/s3compat"
+)
+
+var compatFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "region",
+		Value: "us-east-1",
+		Usage: "Region of the endpoint.",
+	},
+	cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report in JSON format.",
+	},
+}
+
+// Runs the S3 compatibility suite against an endpoint.
+var compatCmd = cli.Command{
+	Name:   "compat",
+	Usage:  "Verify the S3 compatibility of an endpoint.",
+	Action: mainCompat,
+	Flags:  append(compatFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+   minio {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio {{.Name}} [FLAGS] ENDPOINT
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  ACCESS:
+     MINIO_ACCESS_KEY: Access key used to sign requests.
+     MINIO_SECRET_KEY: Secret key used to sign requests.
+
+A bucket is created for the checks and deleted once they complete.
+The command exits with status 1 unless every check passes.
+
+EXAMPLES:
+  1. Verify a server running on this machine.
+      $ export MINIO_ACCESS_KEY=minio
+      $ export MINIO_SECRET_KEY=miniostorage
+      $ minio {{.Name}} http://localhost:9000
+
+  2. Verify a gateway serving TLS in region eu-west-1, with a JSON report.
+      $ minio {{.Name}} --region eu-west-1 --json https://gateway.example.com
+`,
+}
+
+func mainCompat(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "compat", 1)
+	}
+
+	cred, ok, err := loadEnvCredential()
+	if err != nil {
+		console.Fatalf("Unable to load credentials. Err: %s.\n", err)
+	}
+	if !ok {
+		console.Fatalln("MINIO_ACCESS_KEY and MINIO_SECRET_KEY must be set.")
+	}
+
+	report, err := s3compat.Run(s3compat.Config{
+		Endpoint:  ctx.Args().First(),
+		AccessKey: cred.AccessKeyID,
+		SecretKey: cred.SecretAccessKey,
+		Region:    ctx.String("region"),
+	})
+	if err != nil {
+		console.Fatalf("Unable to run the compatibility suite. Err: %s.\n", err)
+	}
+
+	if ctx.Bool("json") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			console.Fatalf("Unable to encode the report. Err: %s.\n", err)
+		}
+		console.Println(string(data))
+	} else {
+		report.WriteTo(os.Stdout)
+	}
+	if !report.Compatible() {
+		os.Exit(1)
+	}
+}
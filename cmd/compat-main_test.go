@@ -0,0 +1,39 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/minio/pkg/s3compat"
+)
+
+// Tests that the server passes its own S3 compatibility suite.
+func TestS3Compatibility(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testServer := StartTestServer(t, instanceType)
+		report := s3compat.RunTest(t, s3compat.Config{
+			Endpoint:  testServer.Server.URL,
+			AccessKey: testServer.AccessKey,
+			SecretKey: testServer.SecretKey,
+		})
+		if passed, _, _ := report.Counts(); passed == 0 {
+			t.Errorf("%s: Expected checks to run, got none", instanceType)
+		}
+		testServer.Stop()
+	}
+}
@@ -120,13 +120,11 @@ func migrateV2ToV3() error {
 		Enable: true,
 		Level:  "fatal",
 	}
-	flogger := fileLogger{}
-	flogger.Level = "error"
+	srvConfig.Logger.File.Level = "error"
 	if cv2.FileLogger.Filename != "" {
-		flogger.Enable = true
-		flogger.Filename = cv2.FileLogger.Filename
+		srvConfig.Logger.File.Enable = true
+		srvConfig.Logger.File.Filename = cv2.FileLogger.Filename
 	}
-	srvConfig.Logger.File = flogger
 
 	slogger := syslogLoggerV3{}
 	slogger.Level = "debug"
@@ -276,7 +274,11 @@ func migrateV5ToV6() error {
 		srvConfig.Region = "us-east-1"
 	}
 	srvConfig.Logger.Console = cv5.Logger.Console
-	srvConfig.Logger.File = cv5.Logger.File
+	srvConfig.Logger.File = fileLogger{
+		Enable:   cv5.Logger.File.Enable,
+		Filename: cv5.Logger.File.Filename,
+		Level:    cv5.Logger.File.Level,
+	}
 	srvConfig.Logger.Syslog = cv5.Logger.Syslog
 
 	srvConfig.Notify.AMQP = map[string]amqpNotify{
@@ -19,6 +19,7 @@ package cmd
 import (
 	"os"
 	"sync"
+	"time"
 
 	"github.com/minio/minio/pkg/quick"
 )
@@ -35,6 +36,10 @@ type serverConfigV10 struct {
 	Credential credential `json:"credential"`
 	Region     string     `json:"region"`
 
+	// Credential replaced by the last rotation, valid until its
+	// expiration.
+	PreviousCredential *credential `json:"previousCredential,omitempty"`
+
 	// Additional error logging configuration.
 	Logger logger `json:"logger"`
 
@@ -68,6 +73,8 @@ func initConfig() (bool, error) {
 		srvCfg.Notify.NATS["1"] = natsNotify{}
 		srvCfg.Notify.PostgreSQL = make(map[string]postgreSQLNotify)
 		srvCfg.Notify.PostgreSQL["1"] = postgreSQLNotify{}
+		srvCfg.Notify.Webhook = make(map[string]webhookNotify)
+		srvCfg.Notify.Webhook["1"] = webhookNotify{}
 
 		// Create config path.
 		err := createConfigPath()
@@ -234,6 +241,32 @@ func (s serverConfigV10) GetPostgreSQLNotifyByID(accountID string) postgreSQLNot
 	return s.Notify.PostgreSQL[accountID]
 }
 
+func (s *serverConfigV10) SetWebhookNotifyByID(accountID string, whNotify webhookNotify) {
+	serverConfigMu.Lock()
+	defer serverConfigMu.Unlock()
+
+	// Configs written before webhooks were supported have none.
+	if s.Notify.Webhook == nil {
+		s.Notify.Webhook = make(map[string]webhookNotify)
+	}
+	s.Notify.Webhook[accountID] = whNotify
+}
+
+func (s serverConfigV10) GetWebhook() map[string]webhookNotify {
+	serverConfigMu.RLock()
+	defer serverConfigMu.RUnlock()
+
+	return s.Notify.Webhook
+}
+
+// GetWebhookNotifyByID get current webhook logger.
+func (s serverConfigV10) GetWebhookNotifyByID(accountID string) webhookNotify {
+	serverConfigMu.RLock()
+	defer serverConfigMu.RUnlock()
+
+	return s.Notify.Webhook[accountID]
+}
+
 // SetFileLogger set new file logger.
 func (s *serverConfigV10) SetFileLogger(flogger fileLogger) {
 	serverConfigMu.Lock()
@@ -298,6 +331,43 @@ func (s serverConfigV10) GetCredential() credential {
 	return s.Credential
 }
 
+// SetPreviousCredential set the credentials replaced by a rotation,
+// nil if none are accepted anymore.
+func (s *serverConfigV10) SetPreviousCredential(creds *credential) {
+	serverConfigMu.Lock()
+	defer serverConfigMu.Unlock()
+
+	s.PreviousCredential = creds
+}
+
+// GetPreviousCredential get the credentials replaced by a rotation.
+func (s serverConfigV10) GetPreviousCredential() *credential {
+	serverConfigMu.RLock()
+	defer serverConfigMu.RUnlock()
+
+	return s.PreviousCredential
+}
+
+// GetCredentialByAccessKey get the current or the previous credentials
+// with accessKey, fails if neither have the access key or the keys
+// have expired.
+func (s serverConfigV10) GetCredentialByAccessKey(accessKey string) (credential, APIErrorCode) {
+	serverConfigMu.RLock()
+	defer serverConfigMu.RUnlock()
+
+	cred := s.Credential
+	if accessKey != cred.AccessKeyID {
+		if s.PreviousCredential == nil || accessKey != s.PreviousCredential.AccessKeyID {
+			return credential{}, ErrInvalidAccessKeyID
+		}
+		cred = *s.PreviousCredential
+	}
+	if cred.isExpired(time.Now().UTC()) {
+		return credential{}, ErrAccessKeyExpired
+	}
+	return cred, ErrNone
+}
+
 // Save config.
 func (s serverConfigV10) Save() error {
 	serverConfigMu.RLock()
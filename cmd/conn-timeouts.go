@@ -0,0 +1,182 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dial timeout of remote connections unless a read header timeout
+// is configured.
+const defaultRemoteDialTimeout = 3 * time.Second
+
+// connTimeouts - timeouts of connections, zero if disabled.
+type connTimeouts struct {
+	// Reading the request headers, or waiting for the response headers
+	// of a remote.
+	readHeader time.Duration
+	// Keep-alive connections without requests are closed after idle.
+	idle time.Duration
+	// Transfers without a byte read or written for this long are aborted.
+	progress time.Duration
+	// Upper bound of a whole request, reading it and writing the response.
+	total time.Duration
+}
+
+// parseTimeout - parses a duration such as "30s", zero if empty or "off".
+func parseTimeout(name, value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("Unknown value `%s` for %s, expected a positive duration such as `30s` or `off`", value, name)
+	}
+	return timeout, nil
+}
+
+// loadConnTimeouts - loads the timeouts from the environment variables
+// <prefix>_READ_HEADER_TIMEOUT, <prefix>_IDLE_TIMEOUT,
+// <prefix>_PROGRESS_TIMEOUT and <prefix>_TRANSFER_TIMEOUT.
+func loadConnTimeouts(prefix string) (timeouts connTimeouts, err error) {
+	for _, timeout := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{prefix + "_READ_HEADER_TIMEOUT", &timeouts.readHeader},
+		{prefix + "_IDLE_TIMEOUT", &timeouts.idle},
+		{prefix + "_PROGRESS_TIMEOUT", &timeouts.progress},
+		{prefix + "_TRANSFER_TIMEOUT", &timeouts.total},
+	} {
+		if *timeout.value, err = parseTimeout(timeout.name, os.Getenv(timeout.name)); err != nil {
+			return connTimeouts{}, err
+		}
+	}
+	return timeouts, nil
+}
+
+// progressConn - aborts reads and writes which do not transfer a
+// byte within the timeout, while the connection is active.
+type progressConn struct {
+	net.Conn
+	timeout time.Duration
+
+	mu     sync.Mutex
+	active bool
+	// Deadlines set by the user of the connection.
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newProgressConn(conn net.Conn, timeout time.Duration) *progressConn {
+	return &progressConn{Conn: conn, timeout: timeout, active: true}
+}
+
+// earliest - returns the earliest of the deadline set by the user and
+// the progress deadline, must be called with the lock held.
+func (c *progressConn) earliest(deadline time.Time) time.Time {
+	if !c.active {
+		return deadline
+	}
+	progress := time.Now().Add(c.timeout)
+	if deadline.IsZero() || progress.Before(deadline) {
+		return progress
+	}
+	return deadline
+}
+
+// setActive - enables the timeout, idle connections wait for the
+// next request without it.
+func (c *progressConn) setActive(active bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active = active
+	c.Conn.SetReadDeadline(c.earliest(c.readDeadline))
+	c.Conn.SetWriteDeadline(c.earliest(c.writeDeadline))
+}
+
+func (c *progressConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	c.Conn.SetReadDeadline(c.earliest(c.readDeadline))
+	c.mu.Unlock()
+	return c.Conn.Read(b)
+}
+
+func (c *progressConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.Conn.SetWriteDeadline(c.earliest(c.writeDeadline))
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+func (c *progressConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *progressConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *progressConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// dialRemote - connects to a remote with the dial and progress timeouts.
+func dialRemote(network, addr string, timeouts connTimeouts) (net.Conn, error) {
+	dialTimeout := timeouts.readHeader
+	if dialTimeout == 0 {
+		dialTimeout = defaultRemoteDialTimeout
+	}
+	conn, err := net.DialTimeout(network, addr, dialTimeout)
+	if err != nil || timeouts.progress == 0 {
+		return conn, err
+	}
+	return newProgressConn(conn, timeouts.progress), nil
+}
+
+// newRemoteHTTPClient - returns a HTTP client for remote backends
+// applying the timeouts.
+func newRemoteHTTPClient(timeouts connTimeouts) *http.Client {
+	return &http.Client{
+		Timeout: timeouts.total,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			Dial: func(network, addr string) (net.Conn, error) {
+				return dialRemote(network, addr, timeouts)
+			},
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: timeouts.readHeader,
+			IdleConnTimeout:       timeouts.idle,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// Tests parsing the connection timeouts from the environment.
+func TestLoadConnTimeouts(t *testing.T) {
+	testCases := []struct {
+		readHeader, idle, progress, transfer string
+		expected                             connTimeouts
+		success                              bool
+	}{
+		{"", "", "", "", connTimeouts{}, true},
+		{"10s", "1m", "30s", "1h", connTimeouts{10 * time.Second, time.Minute, 30 * time.Second, time.Hour}, true},
+		{"off", "", "OFF", "", connTimeouts{}, true},
+		{"10", "", "", "", connTimeouts{}, false},
+		{"", "-1s", "", "", connTimeouts{}, false},
+		{"", "", "0s", "", connTimeouts{}, false},
+		{"", "", "", "forever", connTimeouts{}, false},
+	}
+	defer os.Unsetenv("MINIO_TEST_READ_HEADER_TIMEOUT")
+	defer os.Unsetenv("MINIO_TEST_IDLE_TIMEOUT")
+	defer os.Unsetenv("MINIO_TEST_PROGRESS_TIMEOUT")
+	defer os.Unsetenv("MINIO_TEST_TRANSFER_TIMEOUT")
+	for i, testCase := range testCases {
+		os.Setenv("MINIO_TEST_READ_HEADER_TIMEOUT", testCase.readHeader)
+		os.Setenv("MINIO_TEST_IDLE_TIMEOUT", testCase.idle)
+		os.Setenv("MINIO_TEST_PROGRESS_TIMEOUT", testCase.progress)
+		os.Setenv("MINIO_TEST_TRANSFER_TIMEOUT", testCase.transfer)
+		timeouts, err := loadConnTimeouts("MINIO_TEST")
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if timeouts != testCase.expected {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.expected, timeouts)
+		}
+	}
+}
+
+// Tests that stalled requests are aborted while idle keep-alive
+// connections are kept open.
+func TestListenerMuxProgressTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := newListenerMux(ln, &tls.Config{}, 100*time.Millisecond)
+	defer listener.Close()
+
+	bodyErrs := make(chan error, 1)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := io.Copy(ioutil.Discard, r.Body)
+			if r.URL.Path == "/stalled" {
+				bodyErrs <- err
+			}
+		}),
+		ConnState: listener.trackProgress,
+	}
+	go srv.Serve(listener)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// Connections waiting for the next request are not closed.
+	for i := 0; i < 2; i++ {
+		if _, err = io.WriteString(conn, "PUT /complete HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\n\r\nhello"); err != nil {
+			t.Fatal(err)
+		}
+		resp, rerr := http.ReadResponse(reader, nil)
+		if rerr != nil {
+			t.Fatalf("Request %d: Unexpected error %v", i+1, rerr)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Request %d: Expected status 200, got %d", i+1, resp.StatusCode)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	// Requests not sending their body are aborted.
+	if _, err = io.WriteString(conn, "PUT /stalled HTTP/1.1\r\nHost: localhost\r\nContent-Length: 10\r\n\r\nhello"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-bodyErrs:
+		if err == nil {
+			t.Fatal("Expected the stalled request body to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stalled request was not aborted")
+	}
+}
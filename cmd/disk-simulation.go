@@ -0,0 +1,267 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Operations of the object layer the simulated disk conditions can
+// be set for, bandwidth caps only apply to the ones transferring data.
+var simulatedDiskOps = map[string]bool{
+	"MakeBucket":              false,
+	"GetBucketInfo":           false,
+	"ListBuckets":             false,
+	"DeleteBucket":            false,
+	"ListObjects":             false,
+	"GetObject":               true,
+	"GetObjectInfo":           false,
+	"PutObject":               true,
+	"CopyObject":              false,
+	"DeleteObject":            false,
+	"ListMultipartUploads":    false,
+	"NewMultipartUpload":      false,
+	"PutObjectPart":           true,
+	"ListObjectParts":         false,
+	"AbortMultipartUpload":    false,
+	"CompleteMultipartUpload": false,
+}
+
+// diskSimulation - delays and bandwidth caps applied to the operations
+// of the object layer, to test the timeouts of the API against a slow
+// backend. Set with _MINIO_SIMULATE_DISK_LATENCY and
+// _MINIO_SIMULATE_DISK_BANDWIDTH, meant for testing only. The empty
+// operation holds the values of the operations not set.
+type diskSimulation struct {
+	// Delay before an operation is run.
+	latency map[string]time.Duration
+	// Bytes per second read from or written to the backend.
+	bandwidth map[string]int64
+}
+
+// Backend conditions simulated for testing, disabled by default.
+var globalDiskSimulation diskSimulation
+
+// parseSimulatedDiskValues - splits a comma separated list of values,
+// each for all the operations or for the one it is prefixed with, such
+// as `10ms,GetObject=100ms`, into a value per operation.
+func parseSimulatedDiskValues(name, value string) (map[string]string, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return nil, nil
+	}
+	values := make(map[string]string)
+	for _, elem := range strings.Split(value, ",") {
+		op := ""
+		if i := strings.Index(elem, "="); i >= 0 {
+			op, elem = strings.TrimSpace(elem[:i]), elem[i+1:]
+			if _, ok := simulatedDiskOps[op]; !ok {
+				return nil, fmt.Errorf("Unknown operation `%s` in %s", op, name)
+			}
+		}
+		values[op] = strings.TrimSpace(elem)
+	}
+	return values, nil
+}
+
+// parseDiskSimulation - parses the values of
+// _MINIO_SIMULATE_DISK_LATENCY and _MINIO_SIMULATE_DISK_BANDWIDTH.
+func parseDiskSimulation(latency, bandwidth string) (simulation diskSimulation, err error) {
+	latencies, err := parseSimulatedDiskValues("_MINIO_SIMULATE_DISK_LATENCY", latency)
+	if err != nil {
+		return diskSimulation{}, err
+	}
+	for op, value := range latencies {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return diskSimulation{}, fmt.Errorf("Unknown value `%s` for _MINIO_SIMULATE_DISK_LATENCY, expected durations such as `100ms` or `GetObject=100ms`", value)
+		}
+		if simulation.latency == nil {
+			simulation.latency = make(map[string]time.Duration)
+		}
+		simulation.latency[op] = duration
+	}
+
+	rates, err := parseSimulatedDiskValues("_MINIO_SIMULATE_DISK_BANDWIDTH", bandwidth)
+	if err != nil {
+		return diskSimulation{}, err
+	}
+	for op, value := range rates {
+		if op != "" && !simulatedDiskOps[op] {
+			return diskSimulation{}, fmt.Errorf("Operation `%s` in _MINIO_SIMULATE_DISK_BANDWIDTH does not transfer data", op)
+		}
+		rate, err := humanize.ParseBytes(value)
+		if err != nil || rate == 0 {
+			return diskSimulation{}, fmt.Errorf("Unknown value `%s` for _MINIO_SIMULATE_DISK_BANDWIDTH, expected bytes per second such as `1MiB` or `GetObject=1MiB`", value)
+		}
+		if simulation.bandwidth == nil {
+			simulation.bandwidth = make(map[string]int64)
+		}
+		simulation.bandwidth[op] = int64(rate)
+	}
+	return simulation, nil
+}
+
+// loadDiskSimulation - loads the simulated backend conditions from the
+// environment.
+func loadDiskSimulation() (diskSimulation, error) {
+	return parseDiskSimulation(os.Getenv("_MINIO_SIMULATE_DISK_LATENCY"), os.Getenv("_MINIO_SIMULATE_DISK_BANDWIDTH"))
+}
+
+// isEnabled - returns true if any condition is simulated.
+func (s diskSimulation) isEnabled() bool {
+	return len(s.latency) > 0 || len(s.bandwidth) > 0
+}
+
+// delay - sleeps for the latency of op.
+func (s diskSimulation) delay(op string) {
+	latency, ok := s.latency[op]
+	if !ok {
+		latency = s.latency[""]
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+}
+
+// limiter - returns a limiter to the bandwidth of op, nil if unlimited.
+func (s diskSimulation) limiter(op string) *bandwidthLimiter {
+	rate, ok := s.bandwidth[op]
+	if !ok {
+		rate = s.bandwidth[""]
+	}
+	if rate <= 0 {
+		return nil
+	}
+	return newBandwidthLimiter(rate)
+}
+
+// reader - returns data read at the bandwidth of op.
+func (s diskSimulation) reader(op string, data io.Reader) io.Reader {
+	if limiter := s.limiter(op); limiter != nil {
+		return &simulatedReader{ReadCloser: ioutil.NopCloser(data), limiter: limiter}
+	}
+	return data
+}
+
+// simulatedObjects - object layer delaying its operations and capping
+// their bandwidth, as if its backend were slow.
+type simulatedObjects struct {
+	ObjectLayer
+	simulation diskSimulation
+}
+
+// newSimulatedObjects - returns objAPI slowed down by simulation.
+func newSimulatedObjects(objAPI ObjectLayer, simulation diskSimulation) ObjectLayer {
+	return simulatedObjects{ObjectLayer: objAPI, simulation: simulation}
+}
+
+/// Bucket operations
+
+func (s simulatedObjects) MakeBucket(bucket string) error {
+	s.simulation.delay("MakeBucket")
+	return s.ObjectLayer.MakeBucket(bucket)
+}
+
+func (s simulatedObjects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	s.simulation.delay("GetBucketInfo")
+	return s.ObjectLayer.GetBucketInfo(bucket)
+}
+
+func (s simulatedObjects) ListBuckets() ([]BucketInfo, error) {
+	s.simulation.delay("ListBuckets")
+	return s.ObjectLayer.ListBuckets()
+}
+
+func (s simulatedObjects) DeleteBucket(bucket string) error {
+	s.simulation.delay("DeleteBucket")
+	return s.ObjectLayer.DeleteBucket(bucket)
+}
+
+func (s simulatedObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	s.simulation.delay("ListObjects")
+	return s.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+}
+
+/// Object operations
+
+func (s simulatedObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	s.simulation.delay("GetObject")
+	if limiter := s.simulation.limiter("GetObject"); limiter != nil {
+		writer = &simulatedWriter{Writer: writer, limiter: limiter}
+	}
+	return s.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+}
+
+func (s simulatedObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	s.simulation.delay("GetObjectInfo")
+	return s.ObjectLayer.GetObjectInfo(bucket, object)
+}
+
+func (s simulatedObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	s.simulation.delay("PutObject")
+	return s.ObjectLayer.PutObject(bucket, object, size, s.simulation.reader("PutObject", data), metadata, sha256sum)
+}
+
+func (s simulatedObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	s.simulation.delay("CopyObject")
+	return s.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+func (s simulatedObjects) DeleteObject(bucket, object string) error {
+	s.simulation.delay("DeleteObject")
+	return s.ObjectLayer.DeleteObject(bucket, object)
+}
+
+/// Multipart operations
+
+func (s simulatedObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	s.simulation.delay("ListMultipartUploads")
+	return s.ObjectLayer.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+}
+
+func (s simulatedObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	s.simulation.delay("NewMultipartUpload")
+	return s.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+}
+
+func (s simulatedObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	s.simulation.delay("PutObjectPart")
+	return s.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, s.simulation.reader("PutObjectPart", data), md5Hex, sha256sum)
+}
+
+func (s simulatedObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	s.simulation.delay("ListObjectParts")
+	return s.ObjectLayer.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (s simulatedObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	s.simulation.delay("AbortMultipartUpload")
+	return s.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID)
+}
+
+func (s simulatedObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	s.simulation.delay("CompleteMultipartUpload")
+	return s.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
@@ -0,0 +1,132 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests parsing the simulated disk conditions.
+func TestParseDiskSimulation(t *testing.T) {
+	testCases := []struct {
+		latency    string
+		bandwidth  string
+		simulation diskSimulation
+		success    bool
+	}{
+		{"", "", diskSimulation{}, true},
+		{"off", "off", diskSimulation{}, true},
+		{"10ms", "1MiB", diskSimulation{
+			latency:   map[string]time.Duration{"": 10 * time.Millisecond},
+			bandwidth: map[string]int64{"": 1 << 20},
+		}, true},
+		{"10ms, GetObject=100ms", "PutObject=1KiB", diskSimulation{
+			latency:   map[string]time.Duration{"": 10 * time.Millisecond, "GetObject": 100 * time.Millisecond},
+			bandwidth: map[string]int64{"PutObject": 1 << 10},
+		}, true},
+		{"-1s", "", diskSimulation{}, false},
+		{"Unknown=10ms", "", diskSimulation{}, false},
+		{"", "0", diskSimulation{}, false},
+		{"", "fast", diskSimulation{}, false},
+		// Listings transfer no data.
+		{"", "ListObjects=1MiB", diskSimulation{}, false},
+	}
+	for i, testCase := range testCases {
+		simulation, err := parseDiskSimulation(testCase.latency, testCase.bandwidth)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if !reflect.DeepEqual(simulation, testCase.simulation) {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.simulation, simulation)
+		}
+	}
+}
+
+// Tests that operations are delayed and capped, each by its own values
+// or the default ones.
+func TestSimulatedObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	simulation, err := parseDiskSimulation("MakeBucket=200ms,ListObjects=200ms", "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sobj := newSimulatedObjects(obj, simulation)
+
+	// Times fn, failing if not between minimum and maximum.
+	timed := func(name string, minimum, maximum time.Duration, fn func() error) {
+		start := time.Now()
+		if err := fn(); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if elapsed := time.Since(start); elapsed < minimum || elapsed > maximum {
+			t.Errorf("%s: Expected between %s and %s, took %s", name, minimum, maximum, elapsed)
+		}
+	}
+
+	data := bytes.Repeat([]byte("a"), 300)
+	timed("MakeBucket", 200*time.Millisecond, time.Second, func() error {
+		return sobj.MakeBucket("bucket")
+	})
+	timed("GetBucketInfo", 0, 100*time.Millisecond, func() error {
+		_, err := sobj.GetBucketInfo("bucket")
+		return err
+	})
+	// 300 bytes at 1000 bytes per second.
+	timed("PutObject", 200*time.Millisecond, 2*time.Second, func() error {
+		_, err := sobj.PutObject("bucket", "object", int64(len(data)), bytes.NewReader(data), nil, "")
+		return err
+	})
+	var buffer bytes.Buffer
+	timed("GetObject", 200*time.Millisecond, 2*time.Second, func() error {
+		return sobj.GetObject("bucket", "object", 0, int64(len(data)), &buffer)
+	})
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("Expected the object to be read back, got %d bytes", buffer.Len())
+	}
+	timed("ListObjects", 200*time.Millisecond, time.Second, func() error {
+		_, err := sobj.ListObjects("bucket", "", "", "", 10)
+		return err
+	})
+}
+
+// Tests loading the simulated disk conditions from the environment.
+func TestLoadDiskSimulation(t *testing.T) {
+	defer os.Unsetenv("_MINIO_SIMULATE_DISK_LATENCY")
+	os.Setenv("_MINIO_SIMULATE_DISK_LATENCY", "GetObject=1s")
+	simulation, err := loadDiskSimulation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !simulation.isEnabled() || simulation.latency["GetObject"] != time.Second {
+		t.Errorf("Unexpected simulation %+v", simulation)
+	}
+}
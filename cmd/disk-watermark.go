@@ -0,0 +1,231 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+)
+
+// Disk usage is compared against the watermarks this often.
+const diskWatermarkInterval = 30 * time.Second
+
+// diskWatermark - disk usage thresholds, in percent of the capacity,
+// above which uploads are rejected.
+type diskWatermark struct {
+	// Uploads are rejected once usage reaches high, zero if disabled.
+	high int
+	// Uploads resume once usage drops below low.
+	low int
+	// Reject all writes except deletes while full, not only uploads.
+	readOnly bool
+}
+
+// Set while disk usage is above the watermarks, accessed atomically.
+var globalIsStorageFull int32
+
+// isStorageFull - returns true if uploads are currently rejected.
+func isStorageFull() bool {
+	return atomic.LoadInt32(&globalIsStorageFull) == 1
+}
+
+// parseWatermark - parses a percentage such as "90" or "90%".
+func parseWatermark(value string) (int, error) {
+	percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("Unknown value `%s`, expected a percentage between 1 and 100", value)
+	}
+	return percent, nil
+}
+
+// parseDiskWatermark - parses the values of MINIO_DISK_HIGH_WATERMARK,
+// MINIO_DISK_LOW_WATERMARK and MINIO_DISK_FULL_READONLY.
+func parseDiskWatermark(high, low, readOnly string) (watermark diskWatermark, err error) {
+	switch strings.ToLower(readOnly) {
+	case "", "off":
+	case "on":
+		watermark.readOnly = true
+	default:
+		return diskWatermark{}, fmt.Errorf("Unknown value `%s` for MINIO_DISK_FULL_READONLY, expected `on` or `off`", readOnly)
+	}
+	switch strings.ToLower(high) {
+	case "", "off":
+		if low != "" || watermark.readOnly {
+			return diskWatermark{}, fmt.Errorf("MINIO_DISK_HIGH_WATERMARK is required by MINIO_DISK_LOW_WATERMARK and MINIO_DISK_FULL_READONLY")
+		}
+		return diskWatermark{}, nil
+	}
+	if watermark.high, err = parseWatermark(high); err != nil {
+		return diskWatermark{}, err
+	}
+	watermark.low = watermark.high
+	if low != "" {
+		if watermark.low, err = parseWatermark(low); err != nil {
+			return diskWatermark{}, err
+		}
+		if watermark.low > watermark.high {
+			return diskWatermark{}, fmt.Errorf("Low watermark %d%% is above high watermark %d%%", watermark.low, watermark.high)
+		}
+	}
+	return watermark, nil
+}
+
+// loadDiskWatermark - loads the disk usage watermarks from the environment.
+func loadDiskWatermark() (diskWatermark, error) {
+	return parseDiskWatermark(os.Getenv("MINIO_DISK_HIGH_WATERMARK"), os.Getenv("MINIO_DISK_LOW_WATERMARK"),
+		os.Getenv("MINIO_DISK_FULL_READONLY"))
+}
+
+// isAboveWatermark - returns whether uploads should be rejected given
+// the storage usage and whether they are rejected now. Usage has to
+// drop below the low watermark before uploads resume.
+func (watermark diskWatermark) isAboveWatermark(storageInfo StorageInfo, full bool) bool {
+	if watermark.high == 0 || storageInfo.Total <= 0 {
+		return false
+	}
+	used := float64(storageInfo.Total-storageInfo.Free) * 100 / float64(storageInfo.Total)
+	if full {
+		return used >= float64(watermark.low)
+	}
+	return used >= float64(watermark.high)
+}
+
+// updateStorageFull - compares the storage usage against the
+// watermarks, returns true if uploads are rejected afterwards.
+func updateStorageFull(objAPI ObjectLayer, watermark diskWatermark) bool {
+	full := isStorageFull()
+	storageInfo := objAPI.StorageInfo()
+	if storageInfo.Total <= 0 {
+		// Disks are offline, keep the current state.
+		return full
+	}
+	newFull := watermark.isAboveWatermark(storageInfo, full)
+	if newFull == full {
+		return full
+	}
+	if newFull {
+		atomic.StoreInt32(&globalIsStorageFull, 1)
+	} else {
+		atomic.StoreInt32(&globalIsStorageFull, 0)
+	}
+	if !globalQuiet {
+		used := humanize.IBytes(uint64(storageInfo.Total - storageInfo.Free))
+		total := humanize.IBytes(uint64(storageInfo.Total))
+		if newFull {
+			console.Printf("Disk usage %s of %s reached the high watermark of %d%%, rejecting uploads.\n",
+				used, total, watermark.high)
+		} else {
+			console.Printf("Disk usage %s of %s dropped below the low watermark of %d%%, accepting uploads.\n",
+				used, total, watermark.low)
+		}
+	}
+	return newFull
+}
+
+// startDiskWatermarkMonitor - compares the storage usage against the
+// watermarks every diskWatermarkInterval if enabled with
+// MINIO_DISK_HIGH_WATERMARK.
+func startDiskWatermarkMonitor(objAPI ObjectLayer) {
+	if globalDiskWatermark.high == 0 {
+		return
+	}
+	go func() {
+		for {
+			updateStorageFull(objAPI, globalDiskWatermark)
+			time.Sleep(diskWatermarkInterval)
+		}
+	}()
+}
+
+// isUploadRequest - returns true if the request stores new data:
+// object and part uploads, copies and browser uploads. Completing a
+// multipart upload is allowed since its parts are already stored.
+func isUploadRequest(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/upload/") {
+		return r.Method == "PUT"
+	}
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/") {
+		return false
+	}
+	splits := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket, object := splits[0], ""
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	switch r.Method {
+	case "PUT":
+		// Bucket creation and bucket sub-resources store no data.
+		return object != ""
+	case "POST":
+		if object == "" {
+			// Browser form uploads, not multi-object delete.
+			_, isDelete := r.URL.Query()["delete"]
+			return bucket != "" && !isDelete
+		}
+		_, isNewUpload := r.URL.Query()["uploads"]
+		return isNewUpload
+	}
+	return false
+}
+
+// isWriteRequest - returns true for every request which modifies the
+// backend apart from deletes, which have to stay possible to reclaim
+// space. The admin API stays available.
+func isWriteRequest(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/upload/") {
+		return r.Method == "PUT"
+	}
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/") {
+		return false
+	}
+	switch r.Method {
+	case "PUT":
+		return true
+	case "POST":
+		_, isDelete := r.URL.Query()["delete"]
+		return !isDelete
+	}
+	return false
+}
+
+type storageFullHandler struct {
+	handler http.Handler
+}
+
+// setStorageFullHandler - rejects uploads while disk usage is above
+// the watermarks, and all writes but deletes in read-only mode.
+func setStorageFullHandler(h http.Handler) http.Handler {
+	return storageFullHandler{h}
+}
+
+func (h storageFullHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isStorageFull() {
+		if isUploadRequest(r) || (globalDiskWatermark.readOnly && isWriteRequest(r)) {
+			writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
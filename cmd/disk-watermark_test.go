@@ -0,0 +1,165 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// Tests parsing the MINIO_DISK_* variables.
+func TestParseDiskWatermark(t *testing.T) {
+	testCases := []struct {
+		high, low, readOnly string
+		watermark           diskWatermark
+		success             bool
+	}{
+		{"", "", "", diskWatermark{}, true},
+		{"off", "", "off", diskWatermark{}, true},
+		{"90", "", "", diskWatermark{high: 90, low: 90}, true},
+		{"90%", "80%", "on", diskWatermark{high: 90, low: 80, readOnly: true}, true},
+		{"80", "90", "", diskWatermark{}, false},
+		{"0", "", "", diskWatermark{}, false},
+		{"101", "", "", diskWatermark{}, false},
+		{"full", "", "", diskWatermark{}, false},
+		{"90", "", "yes", diskWatermark{}, false},
+		{"", "80", "", diskWatermark{}, false},
+		{"", "", "on", diskWatermark{}, false},
+	}
+	for i, testCase := range testCases {
+		watermark, err := parseDiskWatermark(testCase.high, testCase.low, testCase.readOnly)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if watermark != testCase.watermark {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.watermark, watermark)
+		}
+	}
+}
+
+// Tests the hysteresis between the high and low watermarks.
+func TestDiskWatermarkIsAbove(t *testing.T) {
+	watermark := diskWatermark{high: 90, low: 80}
+	testCases := []struct {
+		free     int64
+		full     bool
+		expected bool
+	}{
+		{50, false, false},
+		{15, false, false},
+		{10, false, true},
+		{15, true, true},
+		{20, true, true},
+		{21, true, false},
+		{-1, true, false},
+	}
+	for i, testCase := range testCases {
+		storageInfo := StorageInfo{Total: 100, Free: testCase.free}
+		if testCase.free < 0 {
+			storageInfo = StorageInfo{Total: -1, Free: -1}
+		}
+		if above := watermark.isAboveWatermark(storageInfo, testCase.full); above != testCase.expected {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, above)
+		}
+	}
+	if (diskWatermark{}).isAboveWatermark(StorageInfo{Total: 100}, false) {
+		t.Error("Expected disabled watermarks to never reject uploads")
+	}
+}
+
+// Tests which requests are rejected while the storage is full.
+func TestStorageFullHandler(t *testing.T) {
+	defer atomic.StoreInt32(&globalIsStorageFull, 0)
+	defer func(watermark diskWatermark) { globalDiskWatermark = watermark }(globalDiskWatermark)
+
+	handler := setStorageFullHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testCases := []struct {
+		method, url      string
+		upload, readOnly bool
+	}{
+		{"GET", "/bucket/object", false, false},
+		{"HEAD", "/bucket/object", false, false},
+		{"DELETE", "/bucket/object", false, false},
+		{"PUT", "/bucket/object", true, true},
+		{"PUT", "/bucket/object?partNumber=1&uploadId=id", true, true},
+		{"POST", "/bucket/object?uploads", true, true},
+		{"POST", "/bucket/object?uploadId=id", false, true},
+		{"POST", "/bucket", true, true},
+		{"POST", "/bucket?delete", false, false},
+		{"PUT", "/bucket", false, true},
+		{"PUT", "/bucket?policy", false, true},
+		{"PUT", "/minio/upload/bucket/object", true, true},
+		{"POST", "/minio/webrpc", false, false},
+		{"POST", "/minio/admin/v1/fsck", false, false},
+	}
+	for _, readOnly := range []bool{false, true} {
+		globalDiskWatermark = diskWatermark{high: 90, low: 90, readOnly: readOnly}
+		for i, testCase := range testCases {
+			for _, full := range []bool{false, true} {
+				if full {
+					atomic.StoreInt32(&globalIsStorageFull, 1)
+				} else {
+					atomic.StoreInt32(&globalIsStorageFull, 0)
+				}
+				req, err := http.NewRequest(testCase.method, "http://127.0.0.1:9000"+testCase.url, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				rejected := full && (testCase.upload || (readOnly && testCase.readOnly))
+				if rejected != (rec.Code != http.StatusOK) {
+					t.Errorf("Test %d: %s %s, read-only %v, full %v: expected rejected %v, got status %d",
+						i+1, testCase.method, testCase.url, readOnly, full, rejected, rec.Code)
+				}
+			}
+		}
+	}
+}
+
+// Tests switching between full and not full from the object layer.
+func TestUpdateStorageFull(t *testing.T) {
+	defer atomic.StoreInt32(&globalIsStorageFull, 0)
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	// Any data on the disk reaches a 1% watermark.
+	if storageInfo := obj.StorageInfo(); (storageInfo.Total-storageInfo.Free)*100 < storageInfo.Total {
+		t.Skip("Disk usage is below 1%")
+	}
+	if !updateStorageFull(obj, diskWatermark{high: 1, low: 1}) || !isStorageFull() {
+		t.Fatal("Expected storage to be full")
+	}
+	if updateStorageFull(obj, diskWatermark{high: 100, low: 100}) || isStorageFull() {
+		t.Fatal("Expected storage not to be full")
+	}
+}
@@ -18,15 +18,24 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"hash"
 	"io"
+	"os"
+	"strings"
 	"sync"
 
 	"github.com/klauspost/reedsolomon"
 	"github.com/minio/blake2b-simd"
+	"github.com/minio/highwayhash"
 )
 
+// Fixed key of the HighwayHash bitrot checksums, HighwayHash is keyed
+// but bitrot protection does not need a secret.
+var magicHighwayHash256Key = []byte("\x4b\xe7\x34\xfa\x8e\x23\x8a\xcd\x26\x3e\x83\xe6\xbb\x96\x85\x52\x04\x0f\x93\x5d\xa3\x9f\x44\x14\x97\xe0\x9d\x13\x22\xde\x36\xa0")
+
 // newHashWriters - inititialize a slice of hashes for the disk count.
 func newHashWriters(diskCount int, algo string) []hash.Hash {
 	hashWriters := make([]hash.Hash, diskCount)
@@ -41,6 +50,11 @@ func newHash(algo string) hash.Hash {
 	switch algo {
 	case "blake2b":
 		return blake2b.New512()
+	case "highwayhash256":
+		h, _ := highwayhash.New(magicHighwayHash256Key)
+		return h
+	case "sha256":
+		return sha256.New()
 	// Add new hashes here.
 	default:
 		// Default to blake2b.
@@ -48,6 +62,24 @@ func newHash(algo string) hash.Hash {
 	}
 }
 
+// parseBitRotAlgo - parses the value of MINIO_BITROT_ALGO. HighwayHash
+// uses AVX2 and SHA-256 the SHA extensions of the CPU where available,
+// run `go test -bench BitRot` to compare them with blake2b.
+func parseBitRotAlgo(value string) (string, error) {
+	switch algo := strings.ToLower(value); algo {
+	case "":
+		return defaultBitRotAlgo, nil
+	case "blake2b", "highwayhash256", "sha256":
+		return algo, nil
+	}
+	return "", fmt.Errorf("Unknown value `%s` for MINIO_BITROT_ALGO, expected `blake2b`, `highwayhash256` or `sha256`", value)
+}
+
+// loadBitRotAlgo - loads the bit-rot algo from the environment.
+func loadBitRotAlgo() (string, error) {
+	return parseBitRotAlgo(os.Getenv("MINIO_BITROT_ALGO"))
+}
+
 // Hash buffer pool is a pool of reusable
 // buffers used while checksumming a stream.
 var hashBufferPool = sync.Pool{
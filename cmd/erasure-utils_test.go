@@ -18,21 +18,103 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
 	"errors"
+	"hash"
+	"hash/crc32"
 	"io"
 	"testing"
+
+	humanize "github.com/dustin/go-humanize"
 )
 
 // Test validates the number hash writers returned.
 func TestNewHashWriters(t *testing.T) {
 	diskNum := 8
-	hashWriters := newHashWriters(diskNum, bitRotAlgo)
+	hashWriters := newHashWriters(diskNum, globalBitRotAlgo)
 	if len(hashWriters) != diskNum {
 		t.Errorf("Expected %d hashWriters, but instead got %d", diskNum, len(hashWriters))
 	}
 
 }
 
+// Tests parsing MINIO_BITROT_ALGO.
+func TestParseBitRotAlgo(t *testing.T) {
+	testCases := []struct {
+		value        string
+		expectedAlgo string
+		shouldPass   bool
+	}{
+		{"", "blake2b", true},
+		{"blake2b", "blake2b", true},
+		{"HighwayHash256", "highwayhash256", true},
+		{"sha256", "sha256", true},
+		{"md5", "", false},
+		{"off", "", false},
+	}
+	for i, testCase := range testCases {
+		algo, err := parseBitRotAlgo(testCase.value)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error: %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error for %s", i+1, testCase.value)
+		}
+		if algo != testCase.expectedAlgo {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expectedAlgo, algo)
+		}
+	}
+}
+
+// Tests that objects created with any bit-rot algo are verified and
+// read once the algo has changed.
+func TestBitRotAlgos(t *testing.T) {
+	defer func() { globalBitRotAlgo = defaultBitRotAlgo }()
+
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("abcd"), 1024)
+	for _, algo := range []string{"blake2b", "highwayhash256", "sha256"} {
+		globalBitRotAlgo = algo
+		if _, err = obj.PutObject(bucket, algo, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object: %v", algo, err)
+		}
+	}
+
+	globalBitRotAlgo = defaultBitRotAlgo
+	for _, algo := range []string{"blake2b", "highwayhash256", "sha256"} {
+		problems, err := obj.VerifyObject(bucket, algo)
+		if err != nil {
+			t.Fatalf("%s: Unexpected error: %v", algo, err)
+		}
+		if len(problems) != 0 {
+			t.Errorf("%s: Expected an intact object, got %v", algo, problems)
+		}
+		var buf bytes.Buffer
+		if err = obj.GetObject(bucket, algo, 0, int64(len(data)), &buf); err != nil {
+			t.Fatalf("%s: Unexpected error: %v", algo, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: Unexpected object data", algo)
+		}
+	}
+}
+
 // Tests validate the output of getChunkSize.
 func TestGetChunkSize(t *testing.T) {
 	// Refer to comments on getChunkSize() for details.
@@ -158,3 +240,42 @@ func TestCopyBuffer(t *testing.T) {
 		}
 	}
 }
+
+// Benchmark hashing a block of size with h.
+func benchmarkHash(b *testing.B, h hash.Hash, size int) {
+	data := bytes.Repeat([]byte("a"), size)
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(data)
+		h.Sum(nil)
+	}
+}
+
+// Benchmarks of the bit-rot algos on an erasure coded block of a disk.
+func BenchmarkBitRotBlake2b(b *testing.B) {
+	benchmarkHash(b, newHash("blake2b"), humanize.MiByte)
+}
+
+func BenchmarkBitRotHighwayHash256(b *testing.B) {
+	benchmarkHash(b, newHash("highwayhash256"), humanize.MiByte)
+}
+
+func BenchmarkBitRotSHA256(b *testing.B) {
+	benchmarkHash(b, newHash("sha256"), humanize.MiByte)
+}
+
+// Benchmarks of the checksums computed while uploading a part.
+func BenchmarkPayloadMD5(b *testing.B) {
+	benchmarkHash(b, md5.New(), 5*humanize.MiByte)
+}
+
+func BenchmarkPayloadSHA256(b *testing.B) {
+	benchmarkHash(b, sha256.New(), 5*humanize.MiByte)
+}
+
+func BenchmarkPayloadCRC32C(b *testing.B) {
+	benchmarkHash(b, crc32.New(crc32cTable), 5*humanize.MiByte)
+}
@@ -0,0 +1,394 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Prefix of the journal in minioMetaBucket.
+	eventJournalPrefix = "events"
+
+	// Index of the journal segments, updated by every server.
+	eventJournalIndex = eventJournalPrefix + "/index.json"
+
+	// Recorded events are written to a new segment this often.
+	eventJournalFlushInterval = 10 * time.Second
+
+	// Expired segments of an idle journal are purged this often.
+	eventJournalPurgeInterval = time.Hour
+
+	// Events listed unless a maximum is given.
+	defaultEventListMax = 1000
+
+	// Upper bound of the events listed at once.
+	maxEventListMax = 10000
+)
+
+// JournalEvent - a bucket event recorded in the journal.
+type JournalEvent struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Bucket    string            `json:"bucket"`
+	Object    string            `json:"object"`
+	EventType string            `json:"eventType"`
+	Record    NotificationEvent `json:"record"`
+}
+
+// EventList - recorded events in chronological order, truncated if
+// more events match than were asked for.
+type EventList struct {
+	Events    []JournalEvent `json:"events"`
+	Truncated bool           `json:"truncated"`
+}
+
+// EventReplayRequest - selects recorded events to send again to an
+// external target. Events are selected by ID if any are given, within
+// the time range and bucket otherwise.
+type EventReplayRequest struct {
+	Target string    `json:"target"`
+	IDs    []string  `json:"ids,omitempty"`
+	From   time.Time `json:"from,omitempty"`
+	To     time.Time `json:"to,omitempty"`
+	Bucket string    `json:"bucket,omitempty"`
+}
+
+// EventReplayResult - number of events sent to the target.
+type EventReplayResult struct {
+	Replayed int `json:"replayed"`
+}
+
+// byJournalEventTime - collection satisfying sort.Interface.
+type byJournalEventTime []JournalEvent
+
+func (d byJournalEventTime) Len() int           { return len(d) }
+func (d byJournalEventTime) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d byJournalEventTime) Less(i, j int) bool { return d[i].Time.Before(d[j].Time) }
+
+// eventJournalSegment - an object holding the events recorded by one
+// server between two flushes.
+type eventJournalSegment struct {
+	Name  string    `json:"name"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// eventJournalIndexV1 - segments of the journal, oldest first.
+type eventJournalIndexV1 struct {
+	Version  string                `json:"version"`
+	Segments []eventJournalSegment `json:"segments"`
+}
+
+// eventJournal - records bucket events so that they can be listed
+// and replayed to external targets after an outage. Events are kept
+// in memory until the next flush and in minioMetaBucket for the
+// retention period.
+type eventJournal struct {
+	retention time.Duration
+
+	mu        sync.Mutex
+	pending   []JournalEvent
+	lastPurge time.Time
+}
+
+// Journal of bucket events, nil unless enabled with
+// MINIO_EVENT_JOURNAL_RETENTION.
+var globalEventJournal *eventJournal
+
+func newEventJournal(retention time.Duration) *eventJournal {
+	return &eventJournal{retention: retention}
+}
+
+// parseEventJournalRetention - parses the value of
+// MINIO_EVENT_JOURNAL_RETENTION, zero if the journal is disabled.
+func parseEventJournalRetention(value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		return 0, fmt.Errorf("Unknown value `%s` for MINIO_EVENT_JOURNAL_RETENTION, expected a positive duration such as `24h` or `off`", value)
+	}
+	return retention, nil
+}
+
+// loadEventJournalRetention - loads the retention of the event journal
+// from the environment.
+func loadEventJournalRetention() (time.Duration, error) {
+	return parseEventJournalRetention(os.Getenv("MINIO_EVENT_JOURNAL_RETENTION"))
+}
+
+// record - adds an event to be written with the next flush.
+func (j *eventJournal) record(event eventData, nEvent NotificationEvent) {
+	j.mu.Lock()
+	j.pending = append(j.pending, JournalEvent{
+		ID:        mustGetUUID(),
+		Time:      time.Now().UTC(),
+		Bucket:    event.Bucket,
+		Object:    event.ObjInfo.Name,
+		EventType: event.Type.String(),
+		Record:    nEvent,
+	})
+	j.mu.Unlock()
+}
+
+// readEventJournalObject - reads an object of the journal, returns
+// errConfigNotFound if it doesn't exist.
+func readEventJournalObject(name string, objAPI ObjectLayer) ([]byte, error) {
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, name)
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errConfigNotFound
+		}
+		return nil, errorCause(err)
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, name, 0, objInfo.Size, &buffer); err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, errConfigNotFound
+		}
+		return nil, errorCause(err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeEventJournalObject - writes an object of the journal as JSON.
+func writeEventJournalObject(name string, v interface{}, objAPI ObjectLayer) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, name, int64(len(data)), bytes.NewReader(data), nil, getSHA256Hash(data))
+	return errorCause(err)
+}
+
+// readEventJournalIndex - reads the index, empty if there is none yet.
+func readEventJournalIndex(objAPI ObjectLayer) (eventJournalIndexV1, error) {
+	index := eventJournalIndexV1{Version: "1"}
+	data, err := readEventJournalObject(eventJournalIndex, objAPI)
+	if err != nil {
+		if err == errConfigNotFound {
+			return index, nil
+		}
+		return index, err
+	}
+	if err = json.Unmarshal(data, &index); err != nil {
+		return index, err
+	}
+	return index, nil
+}
+
+// flush - writes the pending events to a new segment and drops the
+// segments older than the retention period. The index is updated under
+// a lock, so that servers of a distributed setup don't overwrite each
+// other's segments. The lock is not taken on the index itself, which is
+// locked again while written.
+func (j *eventJournal) flush(objAPI ObjectLayer, now time.Time) error {
+	j.mu.Lock()
+	events := j.pending
+	purgeDue := now.Sub(j.lastPurge) >= eventJournalPurgeInterval
+	j.mu.Unlock()
+	if len(events) == 0 && !purgeDue {
+		return nil
+	}
+
+	var segment eventJournalSegment
+	if len(events) > 0 {
+		segment = eventJournalSegment{
+			Name:  path.Join(eventJournalPrefix, mustGetUUID()+".json"),
+			First: events[0].Time,
+			Last:  events[len(events)-1].Time,
+		}
+		if err := writeEventJournalObject(segment.Name, events, objAPI); err != nil {
+			return err
+		}
+	}
+
+	indexLock := nsMutex.NewNSLock(minioMetaBucket, eventJournalIndex+".lock")
+	indexLock.Lock()
+	defer indexLock.Unlock()
+
+	index, err := readEventJournalIndex(objAPI)
+	if err != nil {
+		return err
+	}
+	var expired []eventJournalSegment
+	segments := index.Segments[:0]
+	for _, s := range index.Segments {
+		if now.Sub(s.Last) > j.retention {
+			expired = append(expired, s)
+			continue
+		}
+		segments = append(segments, s)
+	}
+	index.Segments = segments
+	if len(events) > 0 {
+		index.Segments = append(index.Segments, segment)
+	}
+	if len(events) > 0 || len(expired) > 0 {
+		if err = writeEventJournalObject(eventJournalIndex, index, objAPI); err != nil {
+			return err
+		}
+	}
+
+	// Events recorded while flushing stay pending.
+	j.mu.Lock()
+	j.pending = j.pending[len(events):]
+	j.lastPurge = now
+	j.mu.Unlock()
+
+	for _, s := range expired {
+		err = objAPI.DeleteObject(minioMetaBucket, s.Name)
+		errorIf(err, "Unable to delete expired event journal segment %s.", s.Name)
+	}
+	return nil
+}
+
+// list - returns up to max events recorded between from and to in
+// bucket, in all buckets if bucket is empty. A zero to is the current
+// time, all matching events are returned if max is zero.
+func (j *eventJournal) list(objAPI ObjectLayer, from, to time.Time, bucket string, max int) (EventList, error) {
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	match := func(event JournalEvent) bool {
+		if event.Time.Before(from) || event.Time.After(to) {
+			return false
+		}
+		return bucket == "" || event.Bucket == bucket
+	}
+
+	// A flushed segment may still be pending until the flush
+	// completes, events are deduplicated by ID.
+	seen := make(map[string]bool)
+	events := []JournalEvent{}
+	add := func(event JournalEvent) {
+		if match(event) && !seen[event.ID] {
+			seen[event.ID] = true
+			events = append(events, event)
+		}
+	}
+
+	indexLock := nsMutex.NewNSLock(minioMetaBucket, eventJournalIndex+".lock")
+	indexLock.RLock()
+	index, err := readEventJournalIndex(objAPI)
+	indexLock.RUnlock()
+	if err != nil {
+		return EventList{}, err
+	}
+	for _, s := range index.Segments {
+		if s.Last.Before(from) || s.First.After(to) {
+			continue
+		}
+		data, err := readEventJournalObject(s.Name, objAPI)
+		if err != nil {
+			if err == errConfigNotFound {
+				// Purged since the index was read.
+				continue
+			}
+			return EventList{}, err
+		}
+		var segmentEvents []JournalEvent
+		if err = json.Unmarshal(data, &segmentEvents); err != nil {
+			return EventList{}, err
+		}
+		for _, event := range segmentEvents {
+			add(event)
+		}
+	}
+
+	j.mu.Lock()
+	for _, event := range j.pending {
+		add(event)
+	}
+	j.mu.Unlock()
+
+	sort.Stable(byJournalEventTime(events))
+	list := EventList{Events: events}
+	if max > 0 && len(events) > max {
+		list.Events, list.Truncated = events[:max], true
+	}
+	return list, nil
+}
+
+// replay - sends the events selected by req again to its external
+// target, in the order they were recorded.
+func (j *eventJournal) replay(objAPI ObjectLayer, req EventReplayRequest) (EventReplayResult, error) {
+	if len(req.IDs) == 0 && req.From.IsZero() {
+		return EventReplayResult{}, errInvalidEventQuery
+	}
+	if !req.To.IsZero() && req.To.Before(req.From) {
+		return EventReplayResult{}, errInvalidEventQuery
+	}
+	var targetLog *logrus.Logger
+	if globalEventNotifier != nil {
+		targetLog = globalEventNotifier.GetExternalTarget(req.Target)
+	}
+	if targetLog == nil {
+		return EventReplayResult{}, errInvalidEventTarget
+	}
+
+	list, err := j.list(objAPI, req.From, req.To, req.Bucket, 0)
+	if err != nil {
+		return EventReplayResult{}, err
+	}
+	ids := make(map[string]bool)
+	for _, id := range req.IDs {
+		ids[id] = true
+	}
+
+	var result EventReplayResult
+	for _, event := range list.Events {
+		if len(ids) > 0 && !ids[event.ID] {
+			continue
+		}
+		targetLog.WithFields(logrus.Fields{
+			"Key":       path.Join(event.Bucket, event.Object),
+			"EventType": event.EventType,
+			"Records":   []NotificationEvent{event.Record},
+		}).Info()
+		result.Replayed++
+	}
+	return result, nil
+}
+
+// startEventJournal - flushes the recorded events every
+// eventJournalFlushInterval if enabled with
+// MINIO_EVENT_JOURNAL_RETENTION.
+func startEventJournal(objAPI ObjectLayer) {
+	if globalEventJournal == nil {
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(eventJournalFlushInterval)
+			errorIf(globalEventJournal.flush(objAPI, time.Now().UTC()), "Unable to write the event journal.")
+		}
+	}()
+}
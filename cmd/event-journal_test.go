@@ -0,0 +1,111 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests recording, listing and purging journal events.
+func TestEventJournal(t *testing.T) {
+	ExecObjectLayerTest(t, testEventJournal)
+}
+
+func testEventJournal(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	journal := newEventJournal(time.Hour)
+	record := func(bucket, object string) {
+		journal.record(eventData{Type: ObjectCreatedPut, Bucket: bucket, ObjInfo: ObjectInfo{Name: object}}, NotificationEvent{})
+	}
+	start := time.Now().UTC()
+	record("journal-bucket1", "a")
+	record("journal-bucket2", "b")
+	if err := journal.flush(obj, time.Now().UTC()); err != nil {
+		t.Fatalf("%s: Unable to flush the journal: %v", instanceType, err)
+	}
+	// Not flushed yet, but listed.
+	record("journal-bucket1", "c")
+
+	list, err := journal.list(obj, start, time.Time{}, "", 0)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(list.Events) != 3 || list.Truncated {
+		t.Fatalf("%s: Expected 3 events, got %+v", instanceType, list)
+	}
+	for i, object := range []string{"a", "b", "c"} {
+		if event := list.Events[i]; event.Object != object || event.EventType != "s3:ObjectCreated:Put" || event.ID == "" {
+			t.Errorf("%s: Unexpected event %d %+v", instanceType, i, event)
+		}
+	}
+
+	list, err = journal.list(obj, start, time.Time{}, "journal-bucket1", 1)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(list.Events) != 1 || list.Events[0].Object != "a" || !list.Truncated {
+		t.Errorf("%s: Expected the first event of journal-bucket1, got %+v", instanceType, list)
+	}
+	list, err = journal.list(obj, time.Now().UTC().Add(time.Minute), time.Time{}, "", 0)
+	if err != nil || len(list.Events) != 0 {
+		t.Errorf("%s: Expected no events, got %+v, %v", instanceType, list, err)
+	}
+
+	// The first segment expires, the pending event is kept.
+	if err = journal.flush(obj, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("%s: Unable to flush the journal: %v", instanceType, err)
+	}
+	index, err := readEventJournalIndex(obj)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(index.Segments) != 1 {
+		t.Fatalf("%s: Expected 1 segment, got %+v", instanceType, index)
+	}
+	list, err = journal.list(obj, start, time.Time{}, "", 0)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(list.Events) != 1 || list.Events[0].Object != "c" {
+		t.Errorf("%s: Expected only the last event, got %+v", instanceType, list)
+	}
+}
+
+// Tests parsing MINIO_EVENT_JOURNAL_RETENTION.
+func TestParseEventJournalRetention(t *testing.T) {
+	testCases := []struct {
+		value     string
+		retention time.Duration
+		success   bool
+	}{
+		{"", 0, true},
+		{"off", 0, true},
+		{"24h", 24 * time.Hour, true},
+		{"0s", 0, false},
+		{"-1h", 0, false},
+		{"day", 0, false},
+	}
+	for i, testCase := range testCases {
+		retention, err := parseEventJournalRetention(testCase.value)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if retention != testCase.retention {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.retention, retention)
+		}
+	}
+}
@@ -295,6 +295,11 @@ func eventNotify(event eventData) {
 
 	// Notify internal targets.
 	eventNotifyForBucketListeners(eventType, objectName, event.Bucket, notificationEvent)
+
+	// Record the event for later replays.
+	if globalEventJournal != nil {
+		globalEventJournal.record(event, notificationEvent[0])
+	}
 }
 
 // loads notification config if any for a given bucket, returns
@@ -460,17 +465,22 @@ func loadAllBucketNotifications(objAPI ObjectLayer) (map[string]*notificationCon
 		return nil, nil, err
 	}
 
+	// Loads all bucket notifications, persistent notification and
+	// listener configurations of the buckets are read concurrently.
+	nConfigList := make([]*notificationConfig, len(buckets))
+	lConfigList := make([][]listenerConfig, len(buckets))
+	errs := fanOut(len(buckets), func(i int) (lErr error) {
+		nConfigList[i], lConfigList[i], lErr = loadNotificationAndListenerConfig(buckets[i].Name, objAPI)
+		return lErr
+	})
+
 	nConfigs := make(map[string]*notificationConfig)
 	lConfigs := make(map[string][]listenerConfig)
-
-	// Loads all bucket notifications.
-	for _, bucket := range buckets {
-		// Load persistent notification and listener configurations
-		// a given bucket name.
-		nConfigs[bucket.Name], lConfigs[bucket.Name], err = loadNotificationAndListenerConfig(bucket.Name, objAPI)
-		if err != nil {
-			return nil, nil, err
+	for i, bucket := range buckets {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
 		}
+		nConfigs[bucket.Name], lConfigs[bucket.Name] = nConfigList[i], lConfigList[i]
 	}
 
 	// Success.
@@ -615,6 +625,24 @@ func loadAllQueueTargets() (map[string]*logrus.Logger, error) {
 		}
 		queueTargets[queueARN] = pgLog
 	}
+	// Load webhook targets, initialize their respective loggers.
+	for accountID, whN := range serverConfig.GetWebhook() {
+		if !whN.Enable {
+			continue
+		}
+		// Construct the queue ARN for the webhook.
+		queueARN := minioSqs + serverConfig.GetRegion() + ":" + accountID + ":" + queueTypeWebhook
+		_, ok := queueTargets[queueARN]
+		if ok {
+			continue
+		}
+		// Using accountID initialize a new webhook logrus instance.
+		webhookLog, err := newWebhookNotify(accountID)
+		if err != nil {
+			return nil, err
+		}
+		queueTargets[queueARN] = webhookLog
+	}
 
 	// Successfully initialized queue targets.
 	return queueTargets, nil
@@ -170,6 +170,43 @@ func TestInitEventNotifierWithRedis(t *testing.T) {
 	}
 }
 
+// Tests that initEventNotifier fails for webhooks with an invalid
+// endpoint.
+func TestInitEventNotifierWithWebhook(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	disks, err := getRandomDisks(1)
+	defer removeAll(disks[0])
+	if err != nil {
+		t.Fatal("Unable to create directories for FS backend. ", err)
+	}
+	endpoints, err := parseStorageEndpoints(disks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, _, err := initObjectLayer(endpoints)
+	if err != nil {
+		t.Fatal("Unable to initialize FS backend.", err)
+	}
+
+	serverConfig.SetWebhookNotifyByID("1", webhookNotify{Enable: true, Endpoint: "localhost:8080"})
+	if err := initEventNotifier(fs); err == nil {
+		t.Fatal("Webhook config didn't fail.")
+	}
+
+	serverConfig.SetWebhookNotifyByID("1", webhookNotify{Enable: true, Endpoint: "http://localhost:8080/events"})
+	if err := initEventNotifier(fs); err != nil {
+		t.Fatalf("Unexpected error for a valid webhook config: %v", err)
+	}
+	if globalEventNotifier.GetExternalTarget("arn:minio:sqs:us-east-1:1:webhook") == nil {
+		t.Fatal("Webhook target was not initialized.")
+	}
+}
+
 type TestPeerRPCServerData struct {
 	serverType string
 	testServer TestServer
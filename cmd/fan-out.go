@@ -0,0 +1,50 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "sync"
+
+// Upper bound of the object layer calls a fan-out runs at once.
+const maxFanOut = 16
+
+// fanOut - calls fn for every index from 0 to n-1, running at most
+// maxFanOut calls concurrently, and returns their errors by index.
+func fanOut(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	indexCh := make(chan int)
+
+	workers := maxFanOut
+	if n < workers {
+		workers = n
+	}
+	var wg = &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexCh {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexCh <- i
+	}
+	close(indexCh)
+	wg.Wait()
+	return errs
+}
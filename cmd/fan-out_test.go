@@ -0,0 +1,61 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests that fanOut calls every index once with bounded concurrency.
+func TestFanOut(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 100} {
+		var running, maxRunning int32
+		calls := make([]int32, n)
+		errs := fanOut(n, func(i int) error {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				prev := atomic.LoadInt32(&maxRunning)
+				if cur <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&calls[i], 1)
+			atomic.AddInt32(&running, -1)
+			if i%2 == 1 {
+				return errUnexpected
+			}
+			return nil
+		})
+		if len(errs) != n {
+			t.Fatalf("%d: Expected %d errors, got %d", n, n, len(errs))
+		}
+		for i := range calls {
+			if calls[i] != 1 {
+				t.Errorf("%d: Index %d called %d times", n, i, calls[i])
+			}
+			if (i%2 == 1) != (errs[i] == errUnexpected) {
+				t.Errorf("%d: Unexpected error %v at index %d", n, errs[i], i)
+			}
+		}
+		if maxRunning > maxFanOut {
+			t.Errorf("%d: Expected at most %d concurrent calls, got %d", n, maxFanOut, maxRunning)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// errFaultInjected - the default error of faultyObjects.
+var errFaultInjected = errors.New("fault injected")
+
+// faultyObjects wraps an object layer and injects programmed faults,
+// to exercise the error handling of the API layer beyond the happy
+// path. Only the bucket, object and multipart operations are faulty.
+type faultyObjects struct {
+	ObjectLayer
+	// Every Nth call fails with err, none if zero.
+	failEvery int
+	// The error of the failing calls, errFaultInjected if nil.
+	err error
+	// Object data read or written is cut to half its length.
+	shortWrites bool
+	// Object data read or written has all its bits flipped.
+	corrupt bool
+	// The current call number
+	callNR int
+	// Data protection
+	mu sync.Mutex
+}
+
+func (f *faultyObjects) calcError() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callNR++
+	if f.failEvery == 0 || f.callNR%f.failEvery != 0 {
+		return nil
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return errFaultInjected
+}
+
+// faultyReader - flips the bits of the data read.
+type faultyReader struct {
+	io.Reader
+}
+
+func (r faultyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0xff
+	}
+	return n, err
+}
+
+// faultyWriter - flips the bits of the data written.
+type faultyWriter struct {
+	io.Writer
+}
+
+func (w faultyWriter) Write(p []byte) (int, error) {
+	q := make([]byte, len(p))
+	for i := range p {
+		q[i] = p[i] ^ 0xff
+	}
+	return w.Writer.Write(q)
+}
+
+// reader - returns the data written to the object layer with faults.
+func (f *faultyObjects) reader(size int64, data io.Reader) io.Reader {
+	if f.shortWrites {
+		data = io.LimitReader(data, size/2)
+	}
+	if f.corrupt {
+		data = faultyReader{data}
+	}
+	return data
+}
+
+func (f *faultyObjects) MakeBucket(bucket string) error {
+	if err := f.calcError(); err != nil {
+		return err
+	}
+	return f.ObjectLayer.MakeBucket(bucket)
+}
+
+func (f *faultyObjects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	if err := f.calcError(); err != nil {
+		return BucketInfo{}, err
+	}
+	return f.ObjectLayer.GetBucketInfo(bucket)
+}
+
+func (f *faultyObjects) ListBuckets() ([]BucketInfo, error) {
+	if err := f.calcError(); err != nil {
+		return nil, err
+	}
+	return f.ObjectLayer.ListBuckets()
+}
+
+func (f *faultyObjects) DeleteBucket(bucket string) error {
+	if err := f.calcError(); err != nil {
+		return err
+	}
+	return f.ObjectLayer.DeleteBucket(bucket)
+}
+
+func (f *faultyObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ListObjectsInfo{}, err
+	}
+	return f.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+}
+
+func (f *faultyObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	if err := f.calcError(); err != nil {
+		return err
+	}
+	if f.shortWrites {
+		length /= 2
+	}
+	if f.corrupt {
+		writer = faultyWriter{writer}
+	}
+	return f.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+}
+
+func (f *faultyObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.ObjectLayer.GetObjectInfo(bucket, object)
+}
+
+func (f *faultyObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.ObjectLayer.PutObject(bucket, object, size, f.reader(size, data), metadata, sha256sum)
+}
+
+func (f *faultyObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+func (f *faultyObjects) DeleteObject(bucket, object string) error {
+	if err := f.calcError(); err != nil {
+		return err
+	}
+	return f.ObjectLayer.DeleteObject(bucket, object)
+}
+
+func (f *faultyObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ListMultipartsInfo{}, err
+	}
+	return f.ObjectLayer.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+}
+
+func (f *faultyObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if err := f.calcError(); err != nil {
+		return "", err
+	}
+	return f.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+}
+
+func (f *faultyObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	if err := f.calcError(); err != nil {
+		return "", err
+	}
+	return f.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, f.reader(size, data), md5Hex, sha256sum)
+}
+
+func (f *faultyObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	if err := f.calcError(); err != nil {
+		return ListPartsInfo{}, err
+	}
+	return f.ObjectLayer.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (f *faultyObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if err := f.calcError(); err != nil {
+		return err
+	}
+	return f.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID)
+}
+
+func (f *faultyObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	if err := f.calcError(); err != nil {
+		return "", err
+	}
+	return f.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
+
+// Tests that programmed faults are injected on the right calls.
+func TestFaultyObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	faulty := &faultyObjects{ObjectLayer: obj, failEvery: 3, err: errFaultyDisk}
+	for i := 1; i <= 6; i++ {
+		err = faulty.MakeBucket(getRandomBucketName())
+		if (i%3 == 0) != (err == errFaultyDisk) {
+			t.Errorf("Call %d: Unexpected error %v", i, err)
+		}
+	}
+
+	data := []byte("hello, world")
+	faulty = &faultyObjects{ObjectLayer: obj}
+	if err = faulty.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = faulty.PutObject("bucket", "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	faulty.shortWrites = true
+	var buffer bytes.Buffer
+	if err = faulty.GetObject("bucket", "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data[:len(data)/2]) {
+		t.Errorf("Expected a short read, got %q", buffer.Bytes())
+	}
+	if _, err = faulty.PutObject("bucket", "object", int64(len(data)), bytes.NewReader(data), nil, ""); !isSameType(errorCause(err), IncompleteBody{}) {
+		t.Errorf("Expected IncompleteBody, got %v", err)
+	}
+
+	faulty = &faultyObjects{ObjectLayer: obj, corrupt: true}
+	buffer.Reset()
+	if err = faulty.GetObject("bucket", "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.Len() != len(data) || bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("Expected corrupted data, got %q", buffer.Bytes())
+	}
+	if _, err = faulty.PutObject("bucket", "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": getMD5Hash(data)}, ""); !isSameType(errorCause(err), BadDigest{}) {
+		t.Errorf("Expected BadDigest, got %v", err)
+	}
+}
+
+// Wrapper for calling testAPIFaultyObjects for both XL and FS.
+func TestAPIFaultyObjects(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIFaultyObjects, []string{"GetObject", "PutObject"})
+}
+
+// Tests the responses of the API to failing, short and corrupted reads
+// and writes of the object layer.
+func testAPIFaultyObjects(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	data := generateBytesData(10 * 1024)
+	if _, err := obj.PutObject(bucketName, "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+	md5Sum := md5.Sum(data)
+
+	testCases := []struct {
+		method string
+		faulty *faultyObjects
+		// expected output.
+		expectedRespStatus int
+		expectedErrCode    string
+		expectedBody       []byte
+	}{
+		// Failing calls surface as internal errors.
+		{"GET", &faultyObjects{failEvery: 1}, http.StatusInternalServerError, "InternalError", nil},
+		{"PUT", &faultyObjects{failEvery: 1}, http.StatusInternalServerError, "InternalError", nil},
+		// Short and corrupted writes are rejected.
+		{"PUT", &faultyObjects{shortWrites: true}, http.StatusBadRequest, "IncompleteBody", nil},
+		{"PUT", &faultyObjects{corrupt: true}, http.StatusBadRequest, "BadDigest", nil},
+		// Short and corrupted reads are served as read, past the headers.
+		{"GET", &faultyObjects{shortWrites: true}, http.StatusOK, "", data[:len(data)/2]},
+		{"GET", &faultyObjects{corrupt: true}, http.StatusOK, "", faultyWriterBytes(data)},
+		// No fault.
+		{"GET", &faultyObjects{}, http.StatusOK, "", data},
+	}
+	defer func() {
+		globalObjLayerMutex.Lock()
+		globalObjectAPI = obj
+		globalObjLayerMutex.Unlock()
+	}()
+	for i, testCase := range testCases {
+		testCase.faulty.ObjectLayer = obj
+		globalObjLayerMutex.Lock()
+		globalObjectAPI = testCase.faulty
+		globalObjLayerMutex.Unlock()
+
+		var body []byte
+		if testCase.method == "PUT" {
+			body = data
+		}
+		req, err := newTestSignedRequestV4(testCase.method, getPutObjectURL("", bucketName, "object"),
+			int64(len(body)), bytes.NewReader(body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		if testCase.method == "PUT" {
+			req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Errorf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if testCase.expectedErrCode != "" {
+			errorResponse := APIErrorResponse{}
+			if err = xml.Unmarshal(rec.Body.Bytes(), &errorResponse); err != nil {
+				t.Fatalf("Test %d: %s: Unable to unmarshal response body %s", i+1, instanceType, rec.Body.String())
+			}
+			if errorResponse.Code != testCase.expectedErrCode {
+				t.Errorf("Test %d: %s: Expected the error code to be `%s`, but instead found `%s`", i+1, instanceType, testCase.expectedErrCode, errorResponse.Code)
+			}
+		}
+		if testCase.expectedBody != nil && !bytes.Equal(rec.Body.Bytes(), testCase.expectedBody) {
+			t.Errorf("Test %d: %s: Unexpected response body of %d bytes", i+1, instanceType, rec.Body.Len())
+		}
+	}
+
+	// The object is left as uploaded.
+	var buffer bytes.Buffer
+	if err := obj.GetObject(bucketName, "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected the object to be left as uploaded", instanceType)
+	}
+}
+
+// faultyWriterBytes - returns data as written by a corrupting
+// faultyObjects.
+func faultyWriterBytes(data []byte) []byte {
+	var buffer bytes.Buffer
+	faultyWriter{&buffer}.Write(data)
+	return buffer.Bytes()
+}
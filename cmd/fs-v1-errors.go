@@ -20,3 +20,7 @@ import "errors"
 
 // errFSDiskFormat - returned when given disk format is other than FS format.
 var errFSDiskFormat = errors.New("Disk is not in FS format")
+
+// errFSCloneUnsupported - returned when an object cannot be copied
+// without reading its data.
+var errFSCloneUnsupported = errors.New("Object cannot be cloned")
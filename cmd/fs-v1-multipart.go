@@ -596,8 +596,20 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}
 
-	// No need to save part info, since we have concatenated all parts.
-	fsMeta.Parts = nil
+	// Drop a previous packed copy.
+	if fs.packs != nil {
+		if _, err = fs.packs.delete(bucket, object); err != nil {
+			return "", toObjectErr(err, bucket, object)
+		}
+	}
+
+	// Keep the info of the parts the object was completed from, in
+	// order, so that readers can find the boundaries between them.
+	completedParts := make([]objectPartInfo, len(parts))
+	for i, part := range parts {
+		completedParts[i] = fsMeta.Parts[fsMeta.ObjectPartIndex(part.PartNumber)]
+	}
+	fsMeta.Parts = completedParts
 
 	// Save additional metadata.
 	if len(fsMeta.Meta) == 0 {
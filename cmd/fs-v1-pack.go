@@ -0,0 +1,520 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+const (
+	// Packs of every bucket are kept under '.minio.sys/pack/bucket/'.
+	fsPackMetaPrefix = "pack"
+
+	// Journal of the objects in a pack, one JSON record per line.
+	fsPackIndexFile = "index.json"
+
+	// Prefix of the container file holding the data of a pack.
+	fsPackDataPrefix = "data-"
+
+	// Largest threshold allowed, packed objects are read in one piece.
+	fsPackMaxThreshold = 1 * humanize.MiByte
+
+	// A pack is compacted once its deleted and overwritten data is
+	// above this size and above the size of its live data.
+	fsPackCompactMinBytes = 4 * humanize.MiByte
+)
+
+// Objects up to this size are packed, zero if disabled. Set with
+// MINIO_FS_PACK_THRESHOLD.
+var globalFSPackThreshold int64
+
+// parseFSPackThreshold - parses the value of MINIO_FS_PACK_THRESHOLD.
+func parseFSPackThreshold(value string) (int64, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	threshold, err := humanize.ParseBytes(value)
+	if err != nil || threshold == 0 || threshold > fsPackMaxThreshold {
+		return 0, fmt.Errorf("Unknown value `%s` for MINIO_FS_PACK_THRESHOLD, expected a size up to `1MiB` or `off`", value)
+	}
+	return int64(threshold), nil
+}
+
+// loadFSPackThreshold - loads the packing threshold from the environment.
+func loadFSPackThreshold() (int64, error) {
+	return parseFSPackThreshold(os.Getenv("MINIO_FS_PACK_THRESHOLD"))
+}
+
+// fsPackRecord - a line of the pack journal. The first record names
+// the container file, the others add or remove an object.
+type fsPackRecord struct {
+	// "data", "put" or "delete".
+	Op      string            `json:"op"`
+	Name    string            `json:"name"`
+	Offset  int64             `json:"offset,omitempty"`
+	Size    int64             `json:"size,omitempty"`
+	ModTime time.Time         `json:"modTime,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+}
+
+// fsPackEntry - location and metadata of a packed object.
+type fsPackEntry struct {
+	offset  int64
+	size    int64
+	modTime time.Time
+	meta    map[string]string
+}
+
+// fsPack - objects of a bucket packed into a single append-only
+// container file.
+type fsPack struct {
+	mu sync.RWMutex
+	// Container file, relative to the pack directory.
+	dataFile string
+	// Size of the container file, where the next object is appended.
+	dataSize int64
+	// Size of the objects still referenced.
+	liveSize int64
+	// Whether the journal exists yet.
+	created bool
+	entries map[string]fsPackEntry
+	// Names of all entries, sorted for listing.
+	names []string
+}
+
+// fsPacks - packs of all buckets of a FS backend, loaded on first use.
+type fsPacks struct {
+	storage   StorageAPI
+	threshold int64
+
+	mu    sync.Mutex
+	packs map[string]*fsPack
+}
+
+func newFSPacks(storage StorageAPI, threshold int64) *fsPacks {
+	return &fsPacks{
+		storage:   storage,
+		threshold: threshold,
+		packs:     make(map[string]*fsPack),
+	}
+}
+
+// isPackable - returns true if an object of size is small enough to
+// be packed, objects of unknown size are never packed.
+func (p *fsPacks) isPackable(bucket, object string, size int64) bool {
+	return bucket != minioMetaBucket && size >= 0 && size <= p.threshold &&
+		!strings.HasSuffix(object, slashSeparator)
+}
+
+// get - returns the pack of bucket, loading it on first use.
+func (p *fsPacks) get(bucket string) (*fsPack, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pack, ok := p.packs[bucket]; ok {
+		return pack, nil
+	}
+	pack, err := p.load(bucket)
+	if err != nil {
+		return nil, err
+	}
+	p.packs[bucket] = pack
+	return pack, nil
+}
+
+// load - replays the journal of the pack of bucket and removes
+// container files left behind by an interrupted compaction. The last
+// record may be torn by a crash while appending, it is dropped from
+// the journal before anything is appended after it.
+func (p *fsPacks) load(bucket string) (*fsPack, error) {
+	packDir := pathJoin(fsPackMetaPrefix, bucket)
+	pack := &fsPack{
+		dataFile: fsPackDataPrefix + mustGetUUID(),
+		entries:  make(map[string]fsPackEntry),
+	}
+	buf, err := p.storage.ReadAll(minioMetaBucket, pathJoin(packDir, fsPackIndexFile))
+	if err == errFileNotFound {
+		return pack, nil
+	}
+	if err != nil {
+		return nil, traceError(err)
+	}
+
+	var good int
+	lines := bytes.SplitAfter(buf, []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var record fsPackRecord
+		if err = json.Unmarshal(line, &record); err != nil || !bytes.HasSuffix(line, []byte("\n")) {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, traceError(err)
+		}
+		pack.apply(record)
+		good += len(line)
+	}
+	if good < len(buf) {
+		if err = p.writeIndex(bucket, buf[:good]); err != nil {
+			return nil, err
+		}
+	}
+	// Without the record naming the container file the journal is
+	// started over.
+	pack.created = good > 0
+
+	fi, err := p.storage.StatFile(minioMetaBucket, pathJoin(packDir, pack.dataFile))
+	if err != nil && err != errFileNotFound {
+		return nil, traceError(err)
+	}
+	pack.dataSize = fi.Size
+
+	entries, err := p.storage.ListDir(minioMetaBucket, packDir)
+	if err != nil {
+		return nil, traceError(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, fsPackDataPrefix) && entry != pack.dataFile {
+			errorIf(p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, entry)),
+				"Unable to remove stale pack file %s", pathJoin(packDir, entry))
+		}
+	}
+	return pack, nil
+}
+
+// apply - updates the entries with a journal record.
+func (pack *fsPack) apply(record fsPackRecord) {
+	switch record.Op {
+	case "data":
+		pack.dataFile = record.Name
+	case "put":
+		pack.remove(record.Name)
+		pack.entries[record.Name] = fsPackEntry{
+			offset:  record.Offset,
+			size:    record.Size,
+			modTime: record.ModTime,
+			meta:    record.Meta,
+		}
+		pack.liveSize += record.Size
+		i := sort.SearchStrings(pack.names, record.Name)
+		pack.names = append(pack.names, "")
+		copy(pack.names[i+1:], pack.names[i:])
+		pack.names[i] = record.Name
+	case "delete":
+		pack.remove(record.Name)
+	}
+}
+
+// remove - drops the entry of object if any.
+func (pack *fsPack) remove(object string) {
+	entry, ok := pack.entries[object]
+	if !ok {
+		return
+	}
+	delete(pack.entries, object)
+	pack.liveSize -= entry.size
+	i := sort.SearchStrings(pack.names, object)
+	pack.names = append(pack.names[:i], pack.names[i+1:]...)
+}
+
+// encodeFSPackRecords - returns records as journal lines.
+func encodeFSPackRecords(records []fsPackRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, traceError(err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// appendRecords - appends records to the journal of the pack of bucket.
+func (p *fsPacks) appendRecords(bucket string, records ...fsPackRecord) error {
+	buf, err := encodeFSPackRecords(records)
+	if err != nil {
+		return err
+	}
+	return traceError(p.storage.AppendFile(minioMetaBucket, pathJoin(fsPackMetaPrefix, bucket, fsPackIndexFile), buf))
+}
+
+// writeIndex - replaces the journal of the pack of bucket with buf in
+// a single rename.
+func (p *fsPacks) writeIndex(bucket string, buf []byte) error {
+	tmpPath := mustGetUUID()
+	if err := p.storage.AppendFile(minioMetaTmpBucket, tmpPath, buf); err != nil {
+		return traceError(err)
+	}
+	if err := p.storage.RenameFile(minioMetaTmpBucket, tmpPath, minioMetaBucket, pathJoin(fsPackMetaPrefix, bucket, fsPackIndexFile)); err != nil {
+		p.storage.DeleteFile(minioMetaTmpBucket, tmpPath)
+		return traceError(err)
+	}
+	return nil
+}
+
+// put - appends an object to the pack of bucket, replacing any packed
+// object of the same name.
+func (p *fsPacks) put(bucket, object string, data []byte, meta map[string]string, modTime time.Time) error {
+	pack, err := p.get(bucket)
+	if err != nil {
+		return err
+	}
+	pack.mu.Lock()
+	defer pack.mu.Unlock()
+
+	var records []fsPackRecord
+	if !pack.created {
+		records = append(records, fsPackRecord{Op: "data", Name: pack.dataFile})
+	}
+	record := fsPackRecord{
+		Op:      "put",
+		Name:    object,
+		Offset:  pack.dataSize,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+		Meta:    meta,
+	}
+	if len(data) > 0 {
+		dataPath := pathJoin(fsPackMetaPrefix, bucket, pack.dataFile)
+		if err = p.storage.AppendFile(minioMetaBucket, dataPath, data); err != nil {
+			// Part of the data may have been written, continue after it.
+			if fi, serr := p.storage.StatFile(minioMetaBucket, dataPath); serr == nil {
+				pack.dataSize = fi.Size
+			}
+			return traceError(err)
+		}
+		pack.dataSize += int64(len(data))
+	}
+	if err = p.appendRecords(bucket, append(records, record)...); err != nil {
+		return err
+	}
+	pack.created = true
+	pack.apply(record)
+	return p.compact(bucket, pack)
+}
+
+// delete - removes an object from the pack of bucket, returns false if
+// it is not packed.
+func (p *fsPacks) delete(bucket, object string) (bool, error) {
+	pack, err := p.get(bucket)
+	if err != nil {
+		return false, err
+	}
+	pack.mu.Lock()
+	defer pack.mu.Unlock()
+
+	if _, ok := pack.entries[object]; !ok {
+		return false, nil
+	}
+	if err = p.appendRecords(bucket, fsPackRecord{Op: "delete", Name: object}); err != nil {
+		return false, err
+	}
+	pack.remove(object)
+	return true, p.compact(bucket, pack)
+}
+
+// lookup - returns the entry of a packed object.
+func (p *fsPacks) lookup(bucket, object string) (fsPackEntry, bool, error) {
+	pack, err := p.get(bucket)
+	if err != nil {
+		return fsPackEntry{}, false, err
+	}
+	pack.mu.RLock()
+	defer pack.mu.RUnlock()
+
+	entry, ok := pack.entries[object]
+	return entry, ok, nil
+}
+
+// read - writes length bytes from offset of a packed object to writer,
+// returns false if it is not packed.
+func (p *fsPacks) read(bucket, object string, offset, length int64, writer io.Writer) (bool, error) {
+	pack, err := p.get(bucket)
+	if err != nil {
+		return false, err
+	}
+	pack.mu.RLock()
+	defer pack.mu.RUnlock()
+
+	entry, ok := pack.entries[object]
+	if !ok {
+		return false, nil
+	}
+	if offset+length > entry.size {
+		return true, traceError(InvalidRange{offset, length, entry.size})
+	}
+	buf := make([]byte, length)
+	if length > 0 {
+		dataPath := pathJoin(fsPackMetaPrefix, bucket, pack.dataFile)
+		if _, err = p.storage.ReadFile(minioMetaBucket, dataPath, entry.offset+offset, buf); err != nil {
+			return true, traceError(err)
+		}
+	}
+	_, err = writer.Write(buf)
+	return true, traceError(err)
+}
+
+// compact - copies the live objects of a pack into a new container
+// file once most of it is deleted or overwritten data. The new journal
+// replaces the old one in a single rename, caller must hold the lock.
+func (p *fsPacks) compact(bucket string, pack *fsPack) error {
+	dead := pack.dataSize - pack.liveSize
+	if dead < fsPackCompactMinBytes || dead <= pack.liveSize {
+		return nil
+	}
+	packDir := pathJoin(fsPackMetaPrefix, bucket)
+	dataFile := fsPackDataPrefix + mustGetUUID()
+	records := []fsPackRecord{{Op: "data", Name: dataFile}}
+
+	var offset int64
+	for _, name := range pack.names {
+		entry := pack.entries[name]
+		if entry.size > 0 {
+			buf := make([]byte, entry.size)
+			if _, err := p.storage.ReadFile(minioMetaBucket, pathJoin(packDir, pack.dataFile), entry.offset, buf); err != nil {
+				p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, dataFile))
+				return traceError(err)
+			}
+			if err := p.storage.AppendFile(minioMetaBucket, pathJoin(packDir, dataFile), buf); err != nil {
+				p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, dataFile))
+				return traceError(err)
+			}
+		}
+		records = append(records, fsPackRecord{
+			Op:      "put",
+			Name:    name,
+			Offset:  offset,
+			Size:    entry.size,
+			ModTime: entry.modTime,
+			Meta:    entry.meta,
+		})
+		offset += entry.size
+	}
+
+	buf, err := encodeFSPackRecords(records)
+	if err != nil {
+		p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, dataFile))
+		return err
+	}
+	if err = p.writeIndex(bucket, buf); err != nil {
+		p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, dataFile))
+		return err
+	}
+
+	oldDataFile := pack.dataFile
+	pack.dataFile, pack.dataSize = dataFile, offset
+	for _, record := range records[1:] {
+		entry := pack.entries[record.Name]
+		entry.offset = record.Offset
+		pack.entries[record.Name] = entry
+	}
+	// Left behind files are removed when the pack is loaded next.
+	errorIf(p.storage.DeleteFile(minioMetaBucket, pathJoin(packDir, oldDataFile)),
+		"Unable to remove compacted pack file %s", pathJoin(packDir, oldDataFile))
+	return nil
+}
+
+// isEmpty - returns true if bucket has no packed objects.
+func (p *fsPacks) isEmpty(bucket string) (bool, error) {
+	pack, err := p.get(bucket)
+	if err != nil {
+		return false, err
+	}
+	pack.mu.RLock()
+	defer pack.mu.RUnlock()
+	return len(pack.entries) == 0, nil
+}
+
+// deleteBucket - removes the pack of a deleted bucket.
+func (p *fsPacks) deleteBucket(bucket string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.packs, bucket)
+	err := cleanupDir(p.storage, minioMetaBucket, pathJoin(fsPackMetaPrefix, bucket))
+	if err != nil && errorCause(err) != errVolumeNotFound && errorCause(err) != errFileNotFound {
+		return err
+	}
+	return nil
+}
+
+// listDir - returns listDir merging the entries of packed objects
+// into the entries of the directories of bucket.
+func (p *fsPacks) listDir(listDir listDirFunc) listDirFunc {
+	return func(bucket, prefixDir, prefixEntry string) ([]string, bool, error) {
+		entries, delayIsLeaf, err := listDir(bucket, prefixDir, prefixEntry)
+		if err != nil && !isErrIgnored(errorCause(err), fsTreeWalkIgnoredErrs...) {
+			return nil, false, err
+		}
+		pack, perr := p.get(bucket)
+		if perr != nil {
+			return nil, false, perr
+		}
+
+		pack.mu.RLock()
+		var packed []string
+		prefix := prefixDir + prefixEntry
+		for i := sort.SearchStrings(pack.names, prefix); i < len(pack.names); {
+			name := pack.names[i]
+			if !strings.HasPrefix(name, prefix) {
+				break
+			}
+			entry := strings.TrimPrefix(name, prefixDir)
+			j := strings.Index(entry, slashSeparator)
+			if j < 0 {
+				packed = append(packed, entry)
+				i++
+				continue
+			}
+			// Skip the other objects of the sub-directory, '0'
+			// follows '/' in byte order.
+			packed = append(packed, entry[:j+1])
+			i = sort.SearchStrings(pack.names, prefixDir+entry[:j]+"0")
+		}
+		pack.mu.RUnlock()
+
+		if len(packed) == 0 {
+			return entries, delayIsLeaf, err
+		}
+		seen := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			seen[entry] = true
+		}
+		for _, entry := range packed {
+			if !seen[entry] {
+				entries = append(entries, entry)
+			}
+		}
+		sort.Strings(entries)
+		return entries, delayIsLeafCheck(entries), nil
+	}
+}
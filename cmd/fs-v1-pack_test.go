@@ -0,0 +1,294 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Tests parsing MINIO_FS_PACK_THRESHOLD.
+func TestParseFSPackThreshold(t *testing.T) {
+	testCases := []struct {
+		value     string
+		threshold int64
+		success   bool
+	}{
+		{"", 0, true},
+		{"off", 0, true},
+		{"16KiB", 16 * humanize.KiByte, true},
+		{"1MiB", humanize.MiByte, true},
+		{"2MiB", 0, false},
+		{"0", 0, false},
+		{"tiny", 0, false},
+	}
+	for i, testCase := range testCases {
+		threshold, err := parseFSPackThreshold(testCase.value)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if threshold != testCase.threshold {
+			t.Errorf("Test %d: Expected %d, got %d", i+1, testCase.threshold, threshold)
+		}
+	}
+}
+
+// prepareFSPacked - returns a FS object layer packing objects up to
+// threshold.
+func prepareFSPacked(t *testing.T, threshold int64) (fsObjects, string) {
+	defer func(threshold int64) { globalFSPackThreshold = threshold }(globalFSPackThreshold)
+	globalFSPackThreshold = threshold
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj.(fsObjects), fsDir
+}
+
+// Tests that packed objects behave like unpacked ones.
+func TestFSPackedObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	fs, fsDir := prepareFSPacked(t, 16)
+	defer removeAll(fsDir)
+
+	bucket := "bucket"
+	if err = fs.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	objects := map[string]string{
+		"a":         "small",
+		"dir/b":     "small b",
+		"dir/c":     strings.Repeat("c", 32),
+		"dir/sub/d": "d",
+		"e":         "",
+	}
+	for object, data := range objects {
+		_, err = fs.PutObject(bucket, object, int64(len(data)), strings.NewReader(data), map[string]string{"x-amz-meta-k": object}, "")
+		if err != nil {
+			t.Fatalf("%s: %s", object, err)
+		}
+	}
+	// Only the large object has a file.
+	for object := range objects {
+		_, err = fs.storage.StatFile(bucket, object)
+		if packed := len(objects[object]) <= 16; packed != (err == errFileNotFound) {
+			t.Errorf("%s: Expected packed %v, got %v", object, packed, err)
+		}
+	}
+
+	for object, data := range objects {
+		objInfo, err := fs.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("%s: %s", object, err)
+		}
+		if objInfo.Size != int64(len(data)) || objInfo.UserDefined["x-amz-meta-k"] != object {
+			t.Errorf("%s: Unexpected object info %+v", object, objInfo)
+		}
+		var buf bytes.Buffer
+		if err = fs.GetObject(bucket, object, 0, objInfo.Size, &buf); err != nil {
+			t.Fatalf("%s: %s", object, err)
+		}
+		if buf.String() != data {
+			t.Errorf("%s: Expected %q, got %q", object, data, buf.String())
+		}
+	}
+	var buf bytes.Buffer
+	if err = fs.GetObject(bucket, "dir/b", 2, 3, &buf); err != nil || buf.String() != "all" {
+		t.Errorf("Expected a range of the packed object, got %q, %v", buf.String(), err)
+	}
+	if err = fs.GetObject(bucket, "a", 2, 10, &buf); err == nil {
+		t.Error("Expected an invalid range")
+	}
+
+	listTestCases := []struct {
+		prefix    string
+		delimiter string
+		objects   []string
+		prefixes  []string
+	}{
+		{"", "", []string{"a", "dir/b", "dir/c", "dir/sub/d", "e"}, nil},
+		{"", "/", []string{"a", "e"}, []string{"dir/"}},
+		{"dir/", "/", []string{"dir/b", "dir/c"}, []string{"dir/sub/"}},
+		{"dir/s", "/", nil, []string{"dir/sub/"}},
+	}
+	for i, testCase := range listTestCases {
+		result, err := fs.ListObjects(bucket, testCase.prefix, "", testCase.delimiter, 1000)
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		var names []string
+		for _, objInfo := range result.Objects {
+			names = append(names, objInfo.Name)
+		}
+		if !reflect.DeepEqual(names, testCase.objects) || !reflect.DeepEqual(result.Prefixes, testCase.prefixes) {
+			t.Errorf("Test %d: Expected %v and %v, got %v and %v", i+1,
+				testCase.objects, testCase.prefixes, names, result.Prefixes)
+		}
+	}
+
+	// Overwriting swaps between packed and unpacked copies.
+	large := strings.Repeat("l", 32)
+	if _, err = fs.PutObject(bucket, "a", int64(len(large)), strings.NewReader(large), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fs.PutObject(bucket, "dir/c", 1, strings.NewReader("c"), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	for object, size := range map[string]int64{"a": 32, "dir/c": 1} {
+		if objInfo, err := fs.GetObjectInfo(bucket, object); err != nil || objInfo.Size != size {
+			t.Errorf("%s: Expected size %d, got %d, %v", object, size, objInfo.Size, err)
+		}
+	}
+	if _, err = fs.storage.StatFile(bucket, "dir/c"); err != errFileNotFound {
+		t.Errorf("Expected the unpacked copy to be removed, got %v", err)
+	}
+
+	// Buckets with packed objects are not empty.
+	if err = fs.DeleteObject(bucket, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.DeleteBucket(bucket); err == nil {
+		t.Fatal("Expected a bucket with packed objects not to be deleted")
+	}
+	for _, object := range []string{"dir/b", "dir/c", "dir/sub/d", "e"} {
+		if err = fs.DeleteObject(bucket, object); err != nil {
+			t.Fatalf("%s: %s", object, err)
+		}
+	}
+	if _, err = fs.GetObjectInfo(bucket, "dir/b"); err == nil {
+		t.Error("Expected a deleted packed object not to be found")
+	}
+	if err = fs.DeleteBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that packs are compacted and reloaded from their journal.
+func TestFSPackCompaction(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	fs, fsDir := prepareFSPacked(t, fsPackMaxThreshold)
+	defer removeAll(fsDir)
+
+	bucket := "bucket"
+	if err = fs.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fs.PutObject(bucket, "kept", 3, strings.NewReader("abc"), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("x"), fsPackMaxThreshold)
+	for i := 0; i < 6; i++ {
+		if _, err = fs.PutObject(bucket, "overwritten", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pack, err := fs.packs.get(bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.dataSize >= 3*fsPackMaxThreshold {
+		t.Errorf("Expected the pack to be compacted, data size is %d", pack.dataSize)
+	}
+
+	// Append a torn record as left by a crash.
+	indexPath := pathJoin(fsPackMetaPrefix, bucket, fsPackIndexFile)
+	if err = fs.storage.AppendFile(minioMetaBucket, indexPath, []byte(`{"op":"put","na`)); err != nil {
+		t.Fatal(err)
+	}
+	packs := newFSPacks(fs.storage, fsPackMaxThreshold)
+	reloaded, err := packs.get(bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.names, []string{"kept", "overwritten"}) || reloaded.dataSize != pack.dataSize {
+		t.Errorf("Unexpected reloaded pack %v, %d", reloaded.names, reloaded.dataSize)
+	}
+	entries, err := fs.storage.ListDir(minioMetaBucket, pathJoin(fsPackMetaPrefix, bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected the journal and a single container file, got %v", entries)
+	}
+	var buf bytes.Buffer
+	if _, err = packs.read(bucket, "kept", 0, 3, &buf); err != nil || buf.String() != "abc" {
+		t.Errorf("Expected abc, got %q, %v", buf.String(), err)
+	}
+}
+
+// Tests that a torn journal record is dropped from the journal before
+// records are appended after it.
+func TestFSPackTornJournal(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	fs, fsDir := prepareFSPacked(t, fsPackMaxThreshold)
+	defer removeAll(fsDir)
+
+	bucket := "bucket"
+	if err = fs.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fs.PutObject(bucket, "a", 3, strings.NewReader("abc"), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	indexPath := pathJoin(fsPackMetaPrefix, bucket, fsPackIndexFile)
+	if err = fs.storage.AppendFile(minioMetaBucket, indexPath, []byte(`{"op":"put","na`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload and append twice after the torn record.
+	packs := newFSPacks(fs.storage, fsPackMaxThreshold)
+	for _, name := range []string{"b", "c"} {
+		if err = packs.put(bucket, name, []byte(name), nil, time.Now().UTC()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reloaded, err := newFSPacks(fs.storage, fsPackMaxThreshold).get(bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.names, []string{"a", "b", "c"}) {
+		t.Errorf("Expected all objects to be reloaded, got %v", reloaded.names)
+	}
+	for name, data := range map[string]string{"a": "abc", "b": "b", "c": "c"} {
+		var buf bytes.Buffer
+		if _, err = packs.read(bucket, name, 0, int64(len(data)), &buf); err != nil || buf.String() != data {
+			t.Errorf("Expected %q for %s, got %q, %v", data, name, buf.String(), err)
+		}
+	}
+}
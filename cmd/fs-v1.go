@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"bytes"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
@@ -27,6 +28,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/minio/minio/pkg/mimedb"
 )
@@ -40,6 +42,9 @@ type fsObjects struct {
 
 	// To manage the appendRoutine go0routines
 	bgAppend *backgroundAppend
+
+	// Small objects packed into container files, nil if disabled.
+	packs *fsPacks
 }
 
 // list of all errors that can be ignored in tree walk operation in FS
@@ -73,6 +78,9 @@ func newFSObjects(storage StorageAPI) (ObjectLayer, error) {
 			infoMap: make(map[string]bgAppendPartsInfo),
 		},
 	}
+	if globalFSPackThreshold > 0 {
+		fs.packs = newFSPacks(storage, globalFSPackThreshold)
+	}
 
 	// Return successfully initialized object layer.
 	return fs, nil
@@ -102,6 +110,11 @@ func (fs fsObjects) Shutdown() error {
 		// A non nil err means that an unexpected error occurred
 		return toObjectErr(traceError(err))
 	}
+	// Packed objects are kept in '.minio.sys' as well.
+	_, err = fs.storage.ListDir(minioMetaBucket, fsPackMetaPrefix)
+	if err != errFileNotFound {
+		return toObjectErr(traceError(err))
+	}
 	// Cleanup and delete tmp bucket.
 	if err = cleanupDir(fs.storage, minioMetaTmpBucket, prefix); err != nil {
 		return err
@@ -204,10 +217,25 @@ func (fs fsObjects) DeleteBucket(bucket string) error {
 	if !IsValidBucketName(bucket) {
 		return traceError(BucketNameInvalid{Bucket: bucket})
 	}
+	// Packed objects are not in the bucket directory.
+	if fs.packs != nil {
+		empty, err := fs.packs.isEmpty(bucket)
+		if err != nil {
+			return toObjectErr(err, bucket)
+		}
+		if !empty {
+			return traceError(BucketNotEmpty{Bucket: bucket})
+		}
+	}
 	// Attempt to delete regular bucket.
 	if err := fs.storage.DeleteVol(bucket); err != nil {
 		return toObjectErr(traceError(err), bucket)
 	}
+	if fs.packs != nil {
+		if err := fs.packs.deleteBucket(bucket); err != nil {
+			return toObjectErr(err, bucket)
+		}
+	}
 	// Cleanup all the previously incomplete multiparts.
 	if err := cleanupDir(fs.storage, minioMetaMultipartBucket, bucket); err != nil && errorCause(err) != errVolumeNotFound {
 		return toObjectErr(err, bucket)
@@ -231,6 +259,13 @@ func (fs fsObjects) GetObject(bucket, object string, offset int64, length int64,
 		return toObjectErr(traceError(errUnexpected), bucket, object)
 	}
 
+	if fs.packs != nil {
+		packed, perr := fs.packs.read(bucket, object, offset, length, writer)
+		if packed || perr != nil {
+			return toObjectErr(perr, bucket, object)
+		}
+	}
+
 	// Stat the file to get file size.
 	fi, err := fs.storage.StatFile(bucket, object)
 	if err != nil {
@@ -302,6 +337,21 @@ func (fs fsObjects) GetObject(bucket, object string, offset int64, length int64,
 
 // getObjectInfo - get object info.
 func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if fs.packs != nil {
+		entry, packed, err := fs.packs.lookup(bucket, object)
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+		if packed {
+			fsMeta := newFSMetaV1()
+			fsMeta.Meta = make(map[string]string, len(entry.meta))
+			for k, v := range entry.meta {
+				fsMeta.Meta[k] = v
+			}
+			return fsToObjectInfo(bucket, object, FileInfo{Size: entry.size, ModTime: entry.modTime}, fsMeta), nil
+		}
+	}
+
 	fi, err := fs.storage.StatFile(bucket, object)
 	if err != nil {
 		return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
@@ -311,7 +361,12 @@ func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
 	if err != nil && errorCause(err) != errFileNotFound {
 		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
+	return fsToObjectInfo(bucket, object, fi, fsMeta), nil
+}
 
+// fsToObjectInfo - converts the file info and `fs.json` of an object to
+// its object info.
+func fsToObjectInfo(bucket, object string, fi FileInfo, fsMeta fsMetaV1) ObjectInfo {
 	if len(fsMeta.Meta) == 0 {
 		fsMeta.Meta = make(map[string]string)
 	}
@@ -334,15 +389,23 @@ func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
 		MD5Sum:          fsMeta.Meta["md5Sum"],
 		ContentType:     fsMeta.Meta["content-type"],
 		ContentEncoding: fsMeta.Meta["content-encoding"],
+		Parts:           fsMeta.Parts,
+	}
+
+	// Hard linked copies share the modification time of their source
+	// file, theirs is saved in `fs.json` instead.
+	if modTime, err := time.Parse(time.RFC3339Nano, fsMeta.Meta["modTime"]); err == nil {
+		objInfo.ModTime = modTime
 	}
 
 	// md5Sum has already been extracted into objInfo.MD5Sum.  We
 	// need to remove it from fsMeta.Meta to avoid it from appearing as
 	// part of response headers. e.g, X-Minio-* or X-Amz-*.
 	delete(fsMeta.Meta, "md5Sum")
+	delete(fsMeta.Meta, "modTime")
 	objInfo.UserDefined = fsMeta.Meta
 
-	return objInfo, nil
+	return objInfo
 }
 
 // GetObjectInfo - get object info.
@@ -363,6 +426,10 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		metadata = make(map[string]string)
 	}
 
+	if fs.packs != nil && fs.packs.isPackable(bucket, object, size) {
+		return fs.putPackedObject(bucket, object, size, data, metadata, sha256sum)
+	}
+
 	uniqueID := mustGetUUID()
 
 	// Uploaded object will first be written to the temporary location which will eventually
@@ -474,9 +541,189 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		}
 	}
 
+	// Drop a previous packed copy.
+	if fs.packs != nil && bucket != minioMetaBucket {
+		if _, err = fs.packs.delete(bucket, object); err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+	}
+
+	return fs.getObjectInfo(bucket, object)
+}
+
+// putPackedObject - appends a small object to the pack of its bucket
+// instead of creating a file for it.
+func (fs fsObjects) putPackedObject(bucket string, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(data, buf); err != nil {
+		if err == io.ErrUnexpectedEOF || (err == io.EOF && size > 0) {
+			return ObjectInfo{}, traceError(IncompleteBody{})
+		}
+		return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+	}
+
+	md5Sum := md5.Sum(buf)
+	newMD5Hex := hex.EncodeToString(md5Sum[:])
+	// Update the md5sum if not set with the newly calculated one.
+	if len(metadata["md5Sum"]) == 0 {
+		metadata["md5Sum"] = newMD5Hex
+	}
+	if md5Hex := metadata["md5Sum"]; newMD5Hex != md5Hex {
+		// Returns md5 mismatch.
+		return ObjectInfo{}, traceError(BadDigest{md5Hex, newMD5Hex})
+	}
+	if sha256sum != "" {
+		sha256Sum := sha256.Sum256(buf)
+		if hex.EncodeToString(sha256Sum[:]) != sha256sum {
+			return ObjectInfo{}, traceError(SHA256Mismatch{})
+		}
+	}
+
+	objectLock := nsMutex.NewNSLock(bucket, object)
+	objectLock.Lock()
+	defer objectLock.Unlock()
+
+	if err := fs.packs.put(bucket, object, buf, metadata, time.Now().UTC()); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// Drop a previous unpacked copy.
+	if fi, err := fs.storage.StatFile(bucket, object); err == nil && !fi.Mode.IsDir() {
+		err = fs.storage.DeleteFile(minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+		if err != nil && err != errFileNotFound {
+			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		}
+		if err = fs.storage.DeleteFile(bucket, object); err != nil && err != errFileNotFound {
+			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		}
+	}
+
 	return fs.getObjectInfo(bucket, object)
 }
 
+// fsLocalDisk - returns the local disk of storage, nil if its files
+// cannot be reached directly.
+func fsLocalDisk(storage StorageAPI) *posix {
+	if rs, ok := storage.(*retryStorage); ok {
+		storage = rs.remoteStorage
+	}
+	disk, _ := storage.(*posix)
+	return disk
+}
+
+// CopyObject - copies an object. Where the disk supports it the copy
+// shares the data of the source object through a reflink or a hard
+// link, so that copies take the same time regardless of their size.
+// Other objects are read and written back.
+func (fs fsObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	if err := checkGetObjArgs(srcBucket, srcObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := checkPutObjectArgs(dstBucket, dstObject, fs); err != nil {
+		return ObjectInfo{}, err
+	}
+	// No metadata is set, allocate a new one.
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+
+	disk := fsLocalDisk(fs.storage)
+	if disk == nil || srcBucket == minioMetaBucket || dstBucket == minioMetaBucket {
+		return copyObject(fs, srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+
+	tempObj := mustGetUUID()
+	md5Hex, linked, err := fs.cloneObject(disk, srcBucket, srcObject, dstBucket, dstObject, tempObj)
+	if err != nil {
+		// Errors other than errFSCloneUnsupported are returned by the copy.
+		return copyObject(fs, srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+
+	// Delete the temporary object in the case of a
+	// failure. If CopyObject succeeds, then there would be
+	// nothing to delete.
+	defer fs.storage.DeleteFile(minioMetaTmpBucket, tempObj)
+
+	// The source was replaced since md5Sum was read by the caller.
+	if metadata["md5Sum"] != "" && metadata["md5Sum"] != md5Hex {
+		return ObjectInfo{}, traceError(BadDigest{metadata["md5Sum"], md5Hex})
+	}
+	metadata["md5Sum"] = md5Hex
+	if linked {
+		metadata["modTime"] = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	// Lock the object before committing the object.
+	objectLock := nsMutex.NewNSLock(dstBucket, dstObject)
+	objectLock.RLock()
+	defer objectLock.RUnlock()
+
+	if err = fs.storage.RenameFile(minioMetaTmpBucket, tempObj, dstBucket, dstObject); err != nil {
+		return ObjectInfo{}, toObjectErr(traceError(err), dstBucket, dstObject)
+	}
+
+	fsMeta := newFSMetaV1()
+	fsMeta.Meta = metadata
+	fsMetaPath := path.Join(bucketMetaPrefix, dstBucket, dstObject, fsMetaJSONFile)
+	if err = writeFSMetadata(fs.storage, minioMetaBucket, fsMetaPath, fsMeta); err != nil {
+		return ObjectInfo{}, toObjectErr(traceError(err), dstBucket, dstObject)
+	}
+
+	// Drop a previous packed copy.
+	if fs.packs != nil {
+		if _, err = fs.packs.delete(dstBucket, dstObject); err != nil {
+			return ObjectInfo{}, toObjectErr(err, dstBucket, dstObject)
+		}
+	}
+
+	return fs.getObjectInfo(dstBucket, dstObject)
+}
+
+// cloneObject - clones the data of an object into tempObj and returns
+// its md5Sum, linked is true if tempObj is a hard link. Returns
+// errFSCloneUnsupported for objects that have to be copied.
+func (fs fsObjects) cloneObject(disk *posix, srcBucket, srcObject, dstBucket, dstObject, tempObj string) (md5Hex string, linked bool, err error) {
+	// Lock the source so that its data and `fs.json` match.
+	objectLock := nsMutex.NewNSLock(srcBucket, srcObject)
+	objectLock.RLock()
+	defer objectLock.RUnlock()
+
+	if fs.packs != nil {
+		_, packed, err := fs.packs.lookup(srcBucket, srcObject)
+		if err != nil {
+			return "", false, err
+		}
+		if packed {
+			return "", false, traceError(errFSCloneUnsupported)
+		}
+	}
+
+	fi, err := fs.storage.StatFile(srcBucket, srcObject)
+	if err != nil {
+		return "", false, traceError(err)
+	}
+	// Small objects are packed rather than cloned.
+	if fs.packs != nil && fs.packs.isPackable(dstBucket, dstObject, fi.Size) {
+		return "", false, traceError(errFSCloneUnsupported)
+	}
+
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, srcBucket, srcObject, fsMetaJSONFile))
+	if err != nil && errorCause(err) != errFileNotFound {
+		return "", false, err
+	}
+	// Without an md5Sum the object has to be read to compute one.
+	if fsMeta.Meta["md5Sum"] == "" {
+		return "", false, traceError(errFSCloneUnsupported)
+	}
+
+	linked, err = disk.cloneFile(srcBucket, srcObject, minioMetaTmpBucket, tempObj)
+	if err != nil {
+		// The filesystem supports neither reflinks nor hard links.
+		return "", false, traceError(errFSCloneUnsupported)
+	}
+	return fsMeta.Meta["md5Sum"], linked, nil
+}
+
 // DeleteObject - deletes an object from a bucket, this operation is destructive
 // and there are no rollbacks supported.
 func (fs fsObjects) DeleteObject(bucket, object string) error {
@@ -490,6 +737,16 @@ func (fs fsObjects) DeleteObject(bucket, object string) error {
 	objectLock.RLock()
 	defer objectLock.RUnlock()
 
+	if fs.packs != nil && bucket != minioMetaBucket {
+		packed, err := fs.packs.delete(bucket, object)
+		if err != nil {
+			return toObjectErr(err, bucket, object)
+		}
+		if packed {
+			return nil
+		}
+	}
+
 	if bucket != minioMetaBucket {
 		// We don't store fs.json for minio-S3-layer created files like policy.json,
 		// hence we don't try to delete fs.json for such files.
@@ -561,6 +818,9 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 			return !strings.HasSuffix(object, slashSeparator)
 		}
 		listDir := listDirFactory(isLeaf, fsTreeWalkIgnoredErrs, fs.storage)
+		if fs.packs != nil {
+			listDir = fs.packs.listDir(listDir)
+		}
 		walkResultCh = startTreeWalk(bucket, prefix, marker, recursive, listDir, isLeaf, endWalkCh)
 	}
 	var objInfos []ObjectInfo
@@ -620,6 +880,73 @@ func (fs fsObjects) HealBucket(bucket string) error {
 	return traceError(NotImplemented{})
 }
 
+// VerifyObject - fs keeps no checksums besides the ETag, which is
+// verified by the caller.
+func (fs fsObjects) VerifyObject(bucket, object string) ([]string, error) {
+	if _, err := fs.GetObjectInfo(bucket, object); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+// QuarantineObject - moves an object and its fs.json out of the
+// namespace into the quarantine prefix of the meta bucket.
+func (fs fsObjects) QuarantineObject(bucket, object string) error {
+	if err := checkDelObjArgs(bucket, object); err != nil {
+		return err
+	}
+
+	objectLock := nsMutex.NewNSLock(bucket, object)
+	objectLock.Lock()
+	defer objectLock.Unlock()
+
+	quarantinePath := pathJoin(quarantineMetaPrefix, bucket, object, mustGetUUID())
+	if fs.packs != nil {
+		packed, err := fs.quarantinePackedObject(bucket, object, quarantinePath)
+		if packed || err != nil {
+			return toObjectErr(err, bucket, object)
+		}
+	}
+	if err := fs.storage.RenameFile(bucket, object, minioMetaBucket, pathJoin(quarantinePath, path.Base(object))); err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	fsMetaPath := path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)
+	err := fs.storage.RenameFile(minioMetaBucket, fsMetaPath, minioMetaBucket, pathJoin(quarantinePath, fsMetaJSONFile))
+	if err != nil && err != errFileNotFound {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	return nil
+}
+
+// quarantinePackedObject - copies a packed object and its metadata
+// to quarantinePath and removes it from its pack, returns false if it
+// is not packed.
+func (fs fsObjects) quarantinePackedObject(bucket, object, quarantinePath string) (bool, error) {
+	entry, packed, err := fs.packs.lookup(bucket, object)
+	if err != nil || !packed {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if _, err = fs.packs.read(bucket, object, 0, entry.size, &buf); err != nil {
+		return true, err
+	}
+	if err = fs.storage.AppendFile(minioMetaBucket, pathJoin(quarantinePath, path.Base(object)), buf.Bytes()); err != nil {
+		return true, traceError(err)
+	}
+	fsMeta := newFSMetaV1()
+	fsMeta.Meta = entry.meta
+	if err = writeFSMetadata(fs.storage, minioMetaBucket, pathJoin(quarantinePath, fsMetaJSONFile), fsMeta); err != nil {
+		return true, err
+	}
+	_, err = fs.packs.delete(bucket, object)
+	return true, err
+}
+
+// PurgeTempFiles - removes temporary files older than olderThan.
+func (fs fsObjects) PurgeTempFiles(olderThan time.Duration) (int, error) {
+	return purgeTempFiles([]StorageAPI{fs.storage}, olderThan)
+}
+
 // ListObjectsHeal - list all objects to be healed. Valid only for XL
 func (fs fsObjects) ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
 	return ListObjectsInfo{}, traceError(NotImplemented{})
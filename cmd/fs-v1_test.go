@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestNewFS - tests initialization of all input disks
@@ -246,6 +247,91 @@ func TestFSDeleteObject(t *testing.T) {
 
 }
 
+// TestFSCopyObject - tests copies sharing the data of their source.
+func TestFSCopyObject(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	// Prepare for tests
+	disk := filepath.Join(os.TempDir(), "minio-"+nextSuffix())
+	defer removeAll(disk)
+
+	obj := initFSObjects(disk, t)
+	fs := obj.(fsObjects)
+	bucketName := "bucket"
+	data := bytes.Repeat([]byte("abcd"), 1024)
+
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	srcInfo, err := obj.PutObject(bucketName, "src", int64(len(data)), bytes.NewReader(data), nil, "")
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	// Date the source back, a copy is a new object.
+	past := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(filepath.Join(disk, bucketName, "src"), past, past); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+
+	if fsLocalDisk(fs.storage) == nil {
+		t.Fatal("Expected the FS disk to be local")
+	}
+	start := time.Now().Add(-time.Second)
+	metadata := map[string]string{"content-type": "application/octet-stream"}
+	dstInfo, err := obj.CopyObject(bucketName, "src", bucketName, "dst", metadata)
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if dstInfo.MD5Sum != srcInfo.MD5Sum || dstInfo.Size != srcInfo.Size {
+		t.Fatalf("Expected the copy to have md5Sum %s and size %d, got %s and %d", srcInfo.MD5Sum, srcInfo.Size, dstInfo.MD5Sum, dstInfo.Size)
+	}
+	if dstInfo.ContentType != "application/octet-stream" {
+		t.Fatalf("Expected the copy to have the given content type, got %s", dstInfo.ContentType)
+	}
+	if dstInfo.ModTime.Before(start) {
+		t.Fatalf("Expected the copy to be modified after %s, got %s", start, dstInfo.ModTime)
+	}
+	if _, ok := dstInfo.UserDefined["modTime"]; ok {
+		t.Fatal("Expected modTime not to be returned as user metadata")
+	}
+	if srcInfo, err = obj.GetObjectInfo(bucketName, "src"); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if srcInfo.ModTime.After(start) {
+		t.Fatalf("Expected the source to keep its modification time, got %s", srcInfo.ModTime)
+	}
+
+	// Replacing the source must not change the copy.
+	if _, err = obj.PutObject(bucketName, "src", 4, bytes.NewReader([]byte("efgh")), nil, ""); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject(bucketName, "dst", 0, dstInfo.Size, &buf); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("Expected the copy to keep the data of the source")
+	}
+
+	// The source no longer matches the expected md5Sum.
+	metadata = map[string]string{"md5Sum": dstInfo.MD5Sum}
+	if _, err = obj.CopyObject(bucketName, "src", bucketName, "dst2", metadata); !isSameType(errorCause(err), BadDigest{}) {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if _, err = obj.GetObjectInfo(bucketName, "dst2"); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Fatal("Unexpected error: ", err)
+	}
+
+	// Test with inexist object.
+	if _, err = obj.CopyObject(bucketName, "missing", bucketName, "dst3", nil); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Fatal("Unexpected error: ", err)
+	}
+}
+
 // TestFSDeleteBucket - tests for fs DeleteBucket
 func TestFSDeleteBucket(t *testing.T) {
 	// Prepare for testing
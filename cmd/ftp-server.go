@@ -0,0 +1,617 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time allowed for a client to connect to a passive data port.
+const ftpDataConnTimeout = 30 * time.Second
+
+// Number of entries fetched per ListObjects call while listing a directory.
+const ftpListBatchSize = 1000
+
+// errFTPNoDataConn - data transfer requested without a preceding PASV or EPSV.
+var errFTPNoDataConn = errors.New("Use PASV or EPSV first")
+
+// ftpServer - serves the object layer over FTP. Buckets are exposed
+// as top level directories and object prefixes as sub-directories.
+// When TLS is configured clients must upgrade with AUTH TLS (explicit FTPS)
+// before logging in.
+type ftpServer struct {
+	addr      string
+	tlsConfig *tls.Config
+	ObjectAPI func() ObjectLayer
+}
+
+// newFTPServer - initialize a new FTP server listening on addr, TLS is
+// loaded from the server certificates when tlsEnabled is set.
+func newFTPServer(addr string, tlsEnabled bool) (*ftpServer, error) {
+	srv := &ftpServer{
+		addr:      addr,
+		ObjectAPI: newObjectLayerFn,
+	}
+	if tlsEnabled {
+		cert, err := tls.LoadX509KeyPair(mustGetCertFile(), mustGetKeyFile())
+		if err != nil {
+			return nil, err
+		}
+		srv.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		globalTLSPolicy.apply(srv.tlsConfig)
+	}
+	return srv, nil
+}
+
+// ListenAndServe - listens on the configured address and serves FTP clients.
+func (s *ftpServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve - accepts FTP control connections on listener until it is closed.
+func (s *ftpServer) Serve(listener net.Listener) error {
+	defer listener.Close()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		session := &ftpConn{
+			server:  s,
+			conn:    conn,
+			control: textproto.NewConn(conn),
+			cwd:     "/",
+		}
+		go session.serve()
+	}
+}
+
+// ftpConn - state of a single FTP control connection.
+type ftpConn struct {
+	server  *ftpServer
+	conn    net.Conn
+	control *textproto.Conn
+
+	user     string
+	loggedIn bool
+	cwd      string
+
+	pasvListener  net.Listener
+	protectData   bool
+	restartOffset int64
+}
+
+// reply - writes a single line response to the client.
+func (c *ftpConn) reply(code int, format string, args ...interface{}) {
+	c.control.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+// serve - reads and dispatches commands until the client quits.
+func (c *ftpConn) serve() {
+	defer c.close()
+
+	c.reply(220, "Minio FTP server ready.")
+	for {
+		line, err := c.control.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			command, arg = line[:i], line[i+1:]
+		}
+		if !c.handleCommand(strings.ToUpper(command), arg) {
+			return
+		}
+	}
+}
+
+// close - releases the control and any pending data connection.
+func (c *ftpConn) close() {
+	if c.pasvListener != nil {
+		c.pasvListener.Close()
+	}
+	c.control.Close()
+}
+
+// handleCommand - executes a single command, returns false once the
+// session should be terminated.
+func (c *ftpConn) handleCommand(command, arg string) bool {
+	// Commands allowed before login.
+	switch command {
+	case "QUIT":
+		c.reply(221, "Goodbye.")
+		return false
+	case "NOOP":
+		c.reply(200, "OK.")
+		return true
+	case "SYST":
+		c.reply(215, "UNIX Type: L8")
+		return true
+	case "FEAT":
+		c.control.PrintfLine("211-Features:")
+		for _, feat := range []string{"EPSV", "PASV", "SIZE", "MDTM", "REST STREAM", "UTF8"} {
+			c.control.PrintfLine(" %s", feat)
+		}
+		if c.server.tlsConfig != nil {
+			for _, feat := range []string{"AUTH TLS", "PBSZ", "PROT"} {
+				c.control.PrintfLine(" %s", feat)
+			}
+		}
+		c.reply(211, "End")
+		return true
+	case "OPTS":
+		if strings.ToUpper(arg) == "UTF8 ON" {
+			c.reply(200, "UTF8 mode enabled.")
+		} else {
+			c.reply(501, "Option not understood.")
+		}
+		return true
+	case "AUTH":
+		c.handleAUTH(arg)
+		return true
+	case "PBSZ":
+		c.reply(200, "PBSZ=0")
+		return true
+	case "PROT":
+		c.handlePROT(arg)
+		return true
+	case "USER":
+		if c.requiresTLS() {
+			c.reply(530, "Use AUTH TLS before logging in.")
+			return true
+		}
+		c.user = arg
+		c.loggedIn = false
+		c.reply(331, "User name okay, need password.")
+		return true
+	case "PASS":
+		c.handlePASS(arg)
+		return true
+	}
+
+	if !c.loggedIn {
+		c.reply(530, "Please login with USER and PASS.")
+		return true
+	}
+
+	objectAPI := c.server.ObjectAPI()
+	if objectAPI == nil {
+		c.reply(421, "Server not initialized, please try again.")
+		return false
+	}
+
+	if globalIsReadOnly {
+		switch command {
+		case "STOR", "DELE", "MKD", "XMKD", "RMD", "XRMD":
+			c.reply(550, "%s.", errServerReadOnly)
+			return true
+		}
+	}
+
+	switch command {
+	case "TYPE", "MODE", "STRU":
+		c.reply(200, "%s set to %s.", command, arg)
+	case "PWD", "XPWD":
+		c.reply(257, "%q is the current directory.", c.cwd)
+	case "CWD", "XCWD":
+		c.handleCWD(objectAPI, c.resolvePath(arg))
+	case "CDUP", "XCUP":
+		c.handleCWD(objectAPI, path.Dir(c.cwd))
+	case "PASV":
+		c.handlePASV(false)
+	case "EPSV":
+		c.handlePASV(true)
+	case "REST":
+		offset, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || offset < 0 {
+			c.reply(501, "Invalid restart offset.")
+			break
+		}
+		c.restartOffset = offset
+		c.reply(350, "Restarting at %d.", offset)
+	case "LIST", "NLST":
+		c.handleLIST(objectAPI, arg, command == "NLST")
+	case "RETR":
+		c.handleRETR(objectAPI, c.resolvePath(arg))
+	case "STOR":
+		c.handleSTOR(objectAPI, c.resolvePath(arg))
+	case "DELE":
+		bucket, object := ftpPathToObject(c.resolvePath(arg))
+		if object == "" {
+			c.reply(550, "Not a file.")
+		} else if err := objectAPI.DeleteObject(bucket, object); err != nil {
+			c.replyError(err)
+		} else {
+			c.reply(250, "File deleted.")
+		}
+	case "MKD", "XMKD":
+		bucket, object := ftpPathToObject(c.resolvePath(arg))
+		if bucket == "" || object != "" {
+			c.reply(550, "Directories can only be created at the top level.")
+		} else if err := objectAPI.MakeBucket(bucket); err != nil {
+			c.replyError(err)
+		} else {
+			c.reply(257, "%q created.", "/"+bucket)
+		}
+	case "RMD", "XRMD":
+		bucket, object := ftpPathToObject(c.resolvePath(arg))
+		if bucket == "" || object != "" {
+			c.reply(550, "Only top level directories can be removed.")
+		} else if err := objectAPI.DeleteBucket(bucket); err != nil {
+			c.replyError(err)
+		} else {
+			c.reply(250, "Directory removed.")
+		}
+	case "SIZE", "MDTM":
+		bucket, object := ftpPathToObject(c.resolvePath(arg))
+		if object == "" {
+			c.reply(550, "Not a file.")
+			break
+		}
+		objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+		if err != nil {
+			c.replyError(err)
+		} else if command == "SIZE" {
+			c.reply(213, "%d", objInfo.Size)
+		} else {
+			c.reply(213, "%s", objInfo.ModTime.UTC().Format("20060102150405"))
+		}
+	default:
+		c.reply(502, "Command %s not implemented.", command)
+	}
+	return true
+}
+
+// replyError - converts an object layer error into an FTP reply.
+func (c *ftpConn) replyError(err error) {
+	switch err = errorCause(err); err.(type) {
+	case BucketNotFound, ObjectNotFound, BucketNameInvalid, ObjectNameInvalid,
+		BucketExists, BucketNotEmpty:
+		c.reply(550, "%s", err)
+	default:
+		errorIf(err, "FTP operation failed.")
+		c.reply(451, "%s", err)
+	}
+}
+
+// requiresTLS - returns true if TLS is configured but the control
+// connection is not upgraded yet, credentials are never sent in clear
+// text then.
+func (c *ftpConn) requiresTLS() bool {
+	if c.server.tlsConfig == nil {
+		return false
+	}
+	_, ok := c.conn.(*tls.Conn)
+	return !ok
+}
+
+// handlePASS - authenticates the session with the server credentials,
+// those replaced by a rotation are accepted until they expire. Failed
+// logins are throttled like failed S3 signatures.
+func (c *ftpConn) handlePASS(password string) {
+	c.loggedIn = false
+	if c.requiresTLS() {
+		c.reply(530, "Use AUTH TLS before logging in.")
+		return
+	}
+	ipKey, accessKeyKey := authThrottleKeysOf(c.conn.RemoteAddr().String(), c.user)
+	s3Error := throttleAuthKeys(ipKey, accessKeyKey, func() APIErrorCode {
+		cred, s3Error := serverConfig.GetCredentialByAccessKey(c.user)
+		if s3Error != ErrNone {
+			return s3Error
+		}
+		if subtle.ConstantTimeCompare([]byte(password), []byte(cred.SecretAccessKey)) != 1 {
+			return ErrSignatureDoesNotMatch
+		}
+		return ErrNone
+	})
+	switch s3Error {
+	case ErrNone:
+		c.loggedIn = true
+		c.reply(230, "User logged in.")
+	case ErrAccessKeyExpired:
+		c.reply(530, "%s.", errAccessKeyExpired)
+	case ErrAuthThrottled:
+		c.reply(530, "Too many failed logins, try again later.")
+	default:
+		c.reply(530, "Login incorrect.")
+	}
+}
+
+// handleAUTH - upgrades the control connection to TLS.
+func (c *ftpConn) handleAUTH(arg string) {
+	if c.server.tlsConfig == nil {
+		c.reply(502, "TLS is not configured.")
+		return
+	}
+	if mech := strings.ToUpper(arg); mech != "TLS" && mech != "SSL" {
+		c.reply(504, "Unsupported security mechanism %s.", arg)
+		return
+	}
+	c.reply(234, "AUTH TLS successful.")
+	tlsConn := tls.Server(c.conn, c.server.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		c.conn.Close()
+		return
+	}
+	c.conn = tlsConn
+	c.control = textproto.NewConn(tlsConn)
+}
+
+// handlePROT - sets the data channel protection level.
+func (c *ftpConn) handlePROT(arg string) {
+	switch strings.ToUpper(arg) {
+	case "C":
+		c.protectData = false
+		c.reply(200, "Protection level set to Clear.")
+	case "P":
+		if _, ok := c.conn.(*tls.Conn); !ok {
+			c.reply(503, "Use AUTH TLS first.")
+			return
+		}
+		c.protectData = true
+		c.reply(200, "Protection level set to Private.")
+	default:
+		c.reply(504, "Unsupported protection level %s.", arg)
+	}
+}
+
+// handleCWD - changes the current directory if p exists.
+func (c *ftpConn) handleCWD(objectAPI ObjectLayer, p string) {
+	bucket, object := ftpPathToObject(p)
+	switch {
+	case bucket == "":
+	case object == "":
+		if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+			c.replyError(err)
+			return
+		}
+	default:
+		result, err := objectAPI.ListObjects(bucket, object+slashSeparator, "", slashSeparator, 1)
+		if err != nil {
+			c.replyError(err)
+			return
+		}
+		if len(result.Objects) == 0 && len(result.Prefixes) == 0 {
+			c.reply(550, "No such directory.")
+			return
+		}
+	}
+	c.cwd = p
+	c.reply(250, "Directory changed to %s.", p)
+}
+
+// handlePASV - opens a listener for the next data connection on the
+// same interface as the control connection.
+func (c *ftpConn) handlePASV(extended bool) {
+	if c.pasvListener != nil {
+		c.pasvListener.Close()
+		c.pasvListener = nil
+	}
+	localIP := c.conn.LocalAddr().(*net.TCPAddr).IP
+	if !extended && localIP.To4() == nil {
+		c.reply(522, "Use EPSV for IPv6 connections.")
+		return
+	}
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: localIP})
+	if err != nil {
+		errorIf(err, "Unable to open FTP passive port.")
+		c.reply(425, "Unable to open passive port.")
+		return
+	}
+	c.pasvListener = listener
+	port := listener.Addr().(*net.TCPAddr).Port
+	if extended {
+		c.reply(229, "Entering Extended Passive Mode (|||%d|).", port)
+		return
+	}
+	ip := localIP.To4()
+	c.reply(227, "Entering Passive Mode (%d,%d,%d,%d,%d,%d).", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff)
+}
+
+// openDataConn - accepts the data connection announced by PASV/EPSV.
+// Connections from any other host than the client are dropped, so that
+// nobody else can hijack the transfer.
+func (c *ftpConn) openDataConn() (net.Conn, error) {
+	if c.pasvListener == nil {
+		return nil, errFTPNoDataConn
+	}
+	listener := c.pasvListener.(*net.TCPListener)
+	c.pasvListener = nil
+	defer listener.Close()
+
+	listener.SetDeadline(time.Now().Add(ftpDataConnTimeout))
+	clientIP := c.conn.RemoteAddr().(*net.TCPAddr).IP
+	conn, err := listener.Accept()
+	for err == nil && !conn.RemoteAddr().(*net.TCPAddr).IP.Equal(clientIP) {
+		conn.Close()
+		conn, err = listener.Accept()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.protectData {
+		tlsConn := tls.Server(conn, c.server.tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	return conn, nil
+}
+
+// transfer - runs fn over a fresh data connection and sends the
+// preliminary and completion replies.
+func (c *ftpConn) transfer(fn func(io.ReadWriter) error) {
+	dataConn, err := c.openDataConn()
+	if err != nil {
+		c.reply(425, "Can't open data connection: %s", err)
+		return
+	}
+	c.reply(150, "Opening data connection.")
+	err = fn(dataConn)
+	dataConn.Close()
+	if err != nil {
+		c.replyError(err)
+		return
+	}
+	c.reply(226, "Transfer complete.")
+}
+
+// handleLIST - lists the directory named by arg, or the current one.
+func (c *ftpConn) handleLIST(objectAPI ObjectLayer, arg string, namesOnly bool) {
+	// Ignore ls style flags sent by many clients, e.g "LIST -al".
+	var dir string
+	for _, field := range strings.Fields(arg) {
+		if !strings.HasPrefix(field, "-") {
+			dir = field
+		}
+	}
+	p := c.resolvePath(dir)
+	c.transfer(func(w io.ReadWriter) error {
+		return ftpListDir(objectAPI, p, w, namesOnly)
+	})
+}
+
+// handleRETR - streams an object to the client.
+func (c *ftpConn) handleRETR(objectAPI ObjectLayer, p string) {
+	offset := c.restartOffset
+	c.restartOffset = 0
+
+	bucket, object := ftpPathToObject(p)
+	if object == "" {
+		c.reply(550, "Not a file.")
+		return
+	}
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		c.replyError(err)
+		return
+	}
+	if isEncryptedObject(objInfo) {
+		c.reply(550, "%s", errEncryptedObject)
+		return
+	}
+	if offset > objInfo.Size {
+		c.reply(554, "Restart offset beyond end of file.")
+		return
+	}
+	c.transfer(func(w io.ReadWriter) error {
+		return objectAPI.GetObject(bucket, object, offset, objInfo.Size-offset, w)
+	})
+}
+
+// handleSTOR - uploads an object of unknown length from the client.
+func (c *ftpConn) handleSTOR(objectAPI ObjectLayer, p string) {
+	if c.restartOffset != 0 {
+		c.restartOffset = 0
+		c.reply(554, "Resuming uploads is not supported.")
+		return
+	}
+	bucket, object := ftpPathToObject(p)
+	if object == "" {
+		c.reply(550, "Not a file.")
+		return
+	}
+	c.transfer(func(r io.ReadWriter) error {
+		_, err := objectAPI.PutObject(bucket, object, -1, r, nil, "")
+		return err
+	})
+}
+
+// resolvePath - returns the absolute, cleaned path of p relative to cwd.
+func (c *ftpConn) resolvePath(p string) string {
+	if !strings.HasPrefix(p, slashSeparator) {
+		p = path.Join(c.cwd, p)
+	}
+	return path.Clean(slashSeparator + p)
+}
+
+// ftpPathToObject - splits an absolute path into bucket and object names.
+func ftpPathToObject(p string) (bucket, object string) {
+	p = strings.TrimPrefix(p, slashSeparator)
+	if i := strings.Index(p, slashSeparator); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return p, ""
+}
+
+// ftpListDir - writes the listing of directory p to w, buckets are
+// listed at the root and common prefixes are listed as directories.
+func ftpListDir(objectAPI ObjectLayer, p string, w io.Writer, namesOnly bool) error {
+	writeEntry := func(name string, isDir bool, size int64, modTime time.Time) {
+		if namesOnly {
+			fmt.Fprintf(w, "%s\r\n", name)
+			return
+		}
+		mode := "-rw-r--r--"
+		if isDir {
+			mode = "drwxr-xr-x"
+		}
+		fmt.Fprintf(w, "%s 1 minio minio %12d %s %s\r\n", mode, size, modTime.UTC().Format("Jan _2 15:04"), name)
+	}
+
+	bucket, object := ftpPathToObject(p)
+	if bucket == "" {
+		buckets, err := objectAPI.ListBuckets()
+		if err != nil {
+			return err
+		}
+		for _, b := range buckets {
+			writeEntry(b.Name, true, 0, b.Created)
+		}
+		return nil
+	}
+
+	prefix := object
+	if prefix != "" {
+		prefix += slashSeparator
+	}
+	marker := ""
+	for {
+		result, err := objectAPI.ListObjects(bucket, prefix, marker, slashSeparator, ftpListBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, dir := range result.Prefixes {
+			writeEntry(path.Base(dir), true, 0, time.Now())
+		}
+		for _, objInfo := range result.Objects {
+			writeEntry(path.Base(objInfo.Name), false, objInfo.Size, objInfo.ModTime)
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
@@ -0,0 +1,383 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests ftpPathToObject splitting of absolute paths.
+func TestFTPPathToObject(t *testing.T) {
+	testCases := []struct {
+		path, bucket, object string
+	}{
+		{"/", "", ""},
+		{"/bucket", "bucket", ""},
+		{"/bucket/object", "bucket", "object"},
+		{"/bucket/dir/object", "bucket", "dir/object"},
+	}
+	for i, testCase := range testCases {
+		bucket, object := ftpPathToObject(testCase.path)
+		if bucket != testCase.bucket || object != testCase.object {
+			t.Errorf("Test %d: Expected %s/%s, got %s/%s", i+1, testCase.bucket, testCase.object, bucket, object)
+		}
+	}
+}
+
+// ftpTestClient - minimal FTP client used to drive the server in tests.
+type ftpTestClient struct {
+	t    *testing.T
+	conn *textproto.Conn
+}
+
+// cmd - sends a command and fails the test unless the reply code matches.
+func (c ftpTestClient) cmd(expectCode int, format string, args ...interface{}) string {
+	id, err := c.conn.Cmd(format, args...)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+	_, msg, err := c.conn.ReadResponse(expectCode)
+	if err != nil {
+		c.t.Fatalf("%s: %s", format, err)
+	}
+	return msg
+}
+
+// dataConn - opens an EPSV data connection.
+func (c ftpTestClient) dataConn() net.Conn {
+	msg := c.cmd(229, "EPSV")
+	port := regexp.MustCompile(`\(\|\|\|(\d+)\|\)`).FindStringSubmatch(msg)
+	if port == nil {
+		c.t.Fatalf("Unexpected EPSV reply %s", msg)
+	}
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port[1])
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return conn
+}
+
+// startTestFTPServer - serves srv on a local port and connects to it,
+// the returned function stops both.
+func startTestFTPServer(t *testing.T, srv *ftpServer) (ftpTestClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	conn, err := textproto.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		t.Fatal(err)
+	}
+	if _, _, err = conn.ReadResponse(220); err != nil {
+		conn.Close()
+		listener.Close()
+		t.Fatal(err)
+	}
+	return ftpTestClient{t, conn}, func() {
+		conn.Close()
+		listener.Close()
+	}
+}
+
+// Tests login, upload, listing, download and delete over FTP.
+func TestFTPServer(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	objLayer, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	c, stop := startTestFTPServer(t, &ftpServer{ObjectAPI: func() ObjectLayer { return objLayer }})
+	defer stop()
+	conn := c.conn
+
+	cred := serverConfig.GetCredential()
+	c.cmd(530, "PWD")
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(530, "PASS invalid-secret")
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(230, "PASS %s", cred.SecretAccessKey)
+
+	c.cmd(257, "MKD bucket")
+	c.cmd(550, "MKD bucket/dir")
+	c.cmd(250, "CWD /bucket")
+	c.cmd(550, "CWD missing")
+
+	// Upload an object of unknown length.
+	content := []byte("hello, world")
+	dataConn := c.dataConn()
+	id, err := conn.Cmd("STOR dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.StartResponse(id)
+	if _, _, err = conn.ReadResponse(150); err != nil {
+		t.Fatal(err)
+	}
+	dataConn.Write(content)
+	dataConn.Close()
+	if _, _, err = conn.ReadResponse(226); err != nil {
+		t.Fatal(err)
+	}
+	conn.EndResponse(id)
+
+	if size := c.cmd(213, "SIZE dir/object"); size != "12" {
+		t.Errorf("Expected size 12, got %s", size)
+	}
+
+	// Listings show prefixes as directories.
+	readData := func(command string) []byte {
+		dataConn := c.dataConn()
+		id, err := conn.Cmd("%s", command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.StartResponse(id)
+		defer conn.EndResponse(id)
+		if _, _, err = conn.ReadResponse(150); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(dataConn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataConn.Close()
+		if _, _, err = conn.ReadResponse(226); err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	if list := string(readData("LIST -al")); !strings.HasPrefix(list, "drwxr-xr-x") || !strings.HasSuffix(list, " dir\r\n") {
+		t.Errorf("Unexpected listing %q", list)
+	}
+	c.cmd(250, "CWD dir")
+	if names := string(readData("NLST")); names != "object\r\n" {
+		t.Errorf("Unexpected names %q", names)
+	}
+
+	// Download, including a restarted transfer.
+	if data := readData("RETR object"); !bytes.Equal(data, content) {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+	c.cmd(350, "REST 7")
+	if data := readData("RETR object"); !bytes.Equal(data, content[7:]) {
+		t.Errorf("Expected %q, got %q", content[7:], data)
+	}
+
+	c.cmd(250, "CDUP")
+	if pwd := c.cmd(257, "PWD"); !strings.HasPrefix(pwd, `"/bucket"`) {
+		t.Errorf("Unexpected working directory %s", pwd)
+	}
+	// Writes are rejected while read-only.
+	globalIsReadOnly = true
+	c.cmd(550, "DELE /bucket/dir/object")
+	c.cmd(550, "MKD /other")
+	c.cmd(550, "RMD /bucket")
+	globalIsReadOnly = false
+
+	c.cmd(250, "DELE /bucket/dir/object")
+	c.cmd(550, "RETR /bucket/dir/object")
+	c.cmd(250, "RMD /bucket")
+	c.cmd(502, "AUTH TLS")
+	c.cmd(221, "QUIT")
+}
+
+// Tests that expired credentials are rejected, and that credentials
+// replaced by a rotation are accepted until they expire.
+func TestFTPLoginExpiry(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	c, stop := startTestFTPServer(t, &ftpServer{ObjectAPI: newObjectLayerFn})
+	defer stop()
+
+	past, future := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	cred := serverConfig.GetCredential()
+	previous := credential{AccessKeyID: "previous-access", SecretAccessKey: "previous-secret", Expiration: &future}
+	serverConfig.SetPreviousCredential(&previous)
+	c.cmd(331, "USER %s", previous.AccessKeyID)
+	c.cmd(230, "PASS %s", previous.SecretAccessKey)
+
+	previous.Expiration = &past
+	serverConfig.SetPreviousCredential(&previous)
+	c.cmd(331, "USER %s", previous.AccessKeyID)
+	c.cmd(530, "PASS %s", previous.SecretAccessKey)
+
+	cred.Expiration = &past
+	serverConfig.SetCredential(cred)
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(530, "PASS %s", cred.SecretAccessKey)
+}
+
+// Tests that logins require TLS when configured.
+func TestFTPLoginRequiresTLS(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	certPEM, keyPEM, err := generateTLSCertKey("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &ftpServer{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		ObjectAPI: newObjectLayerFn,
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	defer listener.Close()
+
+	rawConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rawConn.Close()
+	c := ftpTestClient{t, textproto.NewConn(rawConn)}
+	if _, _, err = c.conn.ReadResponse(220); err != nil {
+		t.Fatal(err)
+	}
+
+	cred := serverConfig.GetCredential()
+	c.cmd(530, "USER %s", cred.AccessKeyID)
+	c.cmd(530, "PASS %s", cred.SecretAccessKey)
+	c.cmd(234, "AUTH TLS")
+	c = ftpTestClient{t, textproto.NewConn(tls.Client(rawConn, &tls.Config{InsecureSkipVerify: true}))}
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(230, "PASS %s", cred.SecretAccessKey)
+}
+
+// Tests that failed logins are throttled.
+func TestFTPLoginThrottle(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	globalAuthThrottle = newAuthThrottle()
+	defer func() { globalAuthThrottle = nil }()
+
+	c, stop := startTestFTPServer(t, &ftpServer{ObjectAPI: newObjectLayerFn})
+	defer stop()
+
+	cred := serverConfig.GetCredential()
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(530, "PASS invalid-secret")
+	if failures := globalAuthThrottle.stats().Failures; failures != 1 {
+		t.Errorf("Expected 1 failed login, got %d", failures)
+	}
+
+	// Blocked sources can not login at all.
+	globalAuthThrottle.mu.Lock()
+	globalAuthThrottle.sources["ip:127.0.0.1"].blockedUntil = time.Now().UTC().Add(time.Minute)
+	globalAuthThrottle.mu.Unlock()
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	if msg := c.cmd(530, "PASS %s", cred.SecretAccessKey); !strings.Contains(msg, "Too many failed logins") {
+		t.Errorf("Expected the login to be throttled, got %s", msg)
+	}
+}
+
+// Tests that data connections from other hosts than the client are
+// dropped.
+func TestFTPDataConnPeer(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	objLayer, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	c, stop := startTestFTPServer(t, &ftpServer{ObjectAPI: func() ObjectLayer { return objLayer }})
+	defer stop()
+
+	cred := serverConfig.GetCredential()
+	c.cmd(331, "USER %s", cred.AccessKeyID)
+	c.cmd(230, "PASS %s", cred.SecretAccessKey)
+
+	msg := c.cmd(229, "EPSV")
+	port := regexp.MustCompile(`\(\|\|\|(\d+)\|\)`).FindStringSubmatch(msg)
+	if port == nil {
+		t.Fatalf("Unexpected EPSV reply %s", msg)
+	}
+	dialer := net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.2")}}
+	intruder, err := dialer.Dial("tcp", "127.0.0.1:"+port[1])
+	if err != nil {
+		t.Skipf("Unable to connect from 127.0.0.2: %s", err)
+	}
+	defer intruder.Close()
+
+	id, err := c.conn.Cmd("NLST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+	intruder.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if n, err := intruder.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("Expected the connection from another host to be closed, got %d, %v", n, err)
+	}
+	dataConn, err := net.Dial("tcp", "127.0.0.1:"+port[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dataConn.Close()
+	if _, _, err = c.conn.ReadResponse(150); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioutil.ReadAll(dataConn); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = c.conn.ReadResponse(226); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,897 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata which is not a standard header nor user metadata, such as
+// the version ID or the internal encryption metadata, is kept upstream
+// as user metadata with this prefix. User metadata with this prefix is
+// dropped, clients could forge the internal metadata otherwise.
+const s3GatewayMetadataPrefix = "X-Amz-Meta-X-"
+
+// Upstream error codes and the object layer errors they map to, the
+// params are the bucket, object and upload ID of the request.
+var s3GatewayErrors = map[string]func(params ...string) error{
+	"NoSuchBucket": func(params ...string) error {
+		return BucketNotFound{Bucket: params[0]}
+	},
+	"NoSuchKey": func(params ...string) error {
+		return ObjectNotFound{Bucket: params[0], Object: params[1]}
+	},
+	"BucketAlreadyExists": func(params ...string) error {
+		return BucketExists{Bucket: params[0]}
+	},
+	"BucketAlreadyOwnedByYou": func(params ...string) error {
+		return BucketExists{Bucket: params[0]}
+	},
+	"BucketNotEmpty": func(params ...string) error {
+		return BucketNotEmpty{Bucket: params[0]}
+	},
+	"InvalidBucketName": func(params ...string) error {
+		return BucketNameInvalid{Bucket: params[0]}
+	},
+	"KeyTooLong": func(params ...string) error {
+		return ObjectNameInvalid{Bucket: params[0], Object: params[1]}
+	},
+	"NoSuchUpload": func(params ...string) error {
+		return InvalidUploadID{UploadID: params[2]}
+	},
+	"InvalidPart": func(params ...string) error {
+		return InvalidPart{}
+	},
+	"InvalidPartOrder": func(params ...string) error {
+		return InvalidPart{}
+	},
+	"EntityTooSmall": func(params ...string) error {
+		return PartTooSmall{}
+	},
+	"EntityTooLarge": func(params ...string) error {
+		return ObjectTooLarge{Bucket: params[0], Object: params[1]}
+	},
+	"BadDigest": func(params ...string) error {
+		return BadDigest{}
+	},
+	"XAmzContentSHA256Mismatch": func(params ...string) error {
+		return SHA256Mismatch{}
+	},
+	"IncompleteBody": func(params ...string) error {
+		return IncompleteBody{Bucket: params[0], Object: params[1]}
+	},
+	"InvalidRange": func(params ...string) error {
+		return InvalidRange{}
+	},
+	"AccessDenied": func(params ...string) error {
+		return PrefixAccessDenied{Bucket: params[0], Object: params[1]}
+	},
+	"NotImplemented": func(params ...string) error {
+		return NotImplemented{}
+	},
+}
+
+// s3ErrorResponse - error document of a failed upstream request.
+type s3ErrorResponse struct {
+	XMLName xml.Name
+	Code    string
+	Message string
+}
+
+// s3ToObjectErr - converts the response of a failed upstream request
+// to an object layer error. Responses to HEAD requests carry no error
+// document, their status is converted instead.
+func s3ToObjectErr(resp *http.Response, params ...string) error {
+	params = append(params, "", "", "")
+	var errResp s3ErrorResponse
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err == nil && len(body) > 0 {
+		err = xml.Unmarshal(body, &errResp)
+	}
+	if err != nil || errResp.Code == "" {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			if params[1] != "" {
+				errResp.Code = "NoSuchKey"
+			} else {
+				errResp.Code = "NoSuchBucket"
+			}
+		case http.StatusForbidden:
+			errResp.Code = "AccessDenied"
+		case http.StatusRequestedRangeNotSatisfiable:
+			errResp.Code = "InvalidRange"
+		default:
+			return traceError(fmt.Errorf("Upstream returned %s", resp.Status))
+		}
+	}
+	if toErr, ok := s3GatewayErrors[errResp.Code]; ok {
+		return traceError(toErr(params...))
+	}
+	return traceError(fmt.Errorf("Upstream returned %s: %s", errResp.Code, errResp.Message))
+}
+
+// s3Request - values of a request sent upstream.
+type s3Request struct {
+	method    string
+	bucket    string
+	object    string
+	query     url.Values
+	header    http.Header
+	body      io.Reader
+	size      int64
+	sha256sum string
+}
+
+// s3Objects - implements the object layer with a remote S3 compatible
+// endpoint, requests are signed with signature v4 by the credentials
+// of the gateway. The reserved metadata bucket is not kept upstream,
+// its objects are never found and cannot be written, so bucket
+// configurations such as policies and notifications are not available.
+type s3Objects struct {
+	endpointURL url.URL
+	accessKey   string
+	secretKey   string
+	region      string
+	httpClient  *http.Client
+}
+
+// newS3Objects - returns the object layer of the S3 compatible endpoint,
+// which is given as http(s)://host[:port].
+func newS3Objects(endpoint, accessKey, secretKey, region string) (ObjectLayer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" || u.RawQuery != "" {
+		return nil, fmt.Errorf("Invalid endpoint `%s`, expected http(s)://host[:port]", endpoint)
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("Access key and secret key of the endpoint are required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	u.Path = ""
+	httpClient := newRemoteHTTPClient(globalRemoteTimeouts)
+	httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: globalRootCAs}
+	return &s3Objects{
+		endpointURL: *u,
+		accessKey:   accessKey,
+		secretKey:   secretKey,
+		region:      region,
+		httpClient:  httpClient,
+	}, nil
+}
+
+// newRequest - returns req as a signed HTTP request.
+func (l *s3Objects) newRequest(req s3Request) (*http.Request, error) {
+	urlPath := "/"
+	if req.bucket != "" {
+		urlPath += req.bucket
+		if req.object != "" {
+			urlPath += "/" + req.object
+		}
+	}
+	targetURL := l.endpointURL
+	targetURL.Path = urlPath
+	targetURL.RawPath = getURLEncodedName(urlPath)
+	targetURL.RawQuery = strings.Replace(req.query.Encode(), "+", "%20", -1)
+
+	// Empty bodies are not sent, they would be sent chunked.
+	body := req.body
+	if req.size == 0 {
+		body = nil
+	}
+	httpReq, err := http.NewRequest(req.method, targetURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	httpReq.ContentLength = req.size
+
+	payload := req.sha256sum
+	if payload == "" {
+		payload = unsignedPayload
+		if body == nil {
+			sum := sha256.Sum256(nil)
+			payload = hex.EncodeToString(sum[:])
+		}
+	}
+	t := time.Now().UTC()
+	httpReq.Header.Set("X-Amz-Date", t.Format(iso8601Format))
+	httpReq.Header.Set("X-Amz-Content-Sha256", payload)
+
+	signedHeaders := make(http.Header)
+	for k, v := range httpReq.Header {
+		if k != "Authorization" && k != "Content-Length" && k != "User-Agent" {
+			signedHeaders[k] = v
+		}
+	}
+	canonicalRequest := getCanonicalRequest(signedHeaders, payload, targetURL.RawQuery, urlPath, req.method, httpReq.URL.Host)
+	stringToSign := getStringToSign(canonicalRequest, t, l.region)
+	signature := getSignature(getSigningKey(l.secretKey, t, l.region), stringToSign)
+	httpReq.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signV4Algorithm, l.accessKey, getScope(t, l.region), getSignedHeaders(signedHeaders), signature))
+	return httpReq, nil
+}
+
+// do - sends req upstream, the response body must be closed unless
+// an error is returned.
+func (l *s3Objects) do(req s3Request) (*http.Response, error) {
+	httpReq, err := l.newRequest(req)
+	if err != nil {
+		return nil, traceError(err)
+	}
+	resp, err := l.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, traceError(err)
+	}
+	return resp, nil
+}
+
+// doDecode - sends req upstream and decodes the XML document of the
+// response into v. Some requests fail with an error document after a
+// successful status, it is returned as error as well.
+func (l *s3Objects) doDecode(req s3Request, v interface{}, params ...string) error {
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3ToObjectErr(resp, params...)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return traceError(err)
+	}
+	var errResp s3ErrorResponse
+	if xml.Unmarshal(body, &errResp) == nil && errResp.XMLName.Local == "Error" {
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return s3ToObjectErr(resp, params...)
+	}
+	if err = xml.Unmarshal(body, v); err != nil {
+		return traceError(err)
+	}
+	return nil
+}
+
+// doExpect - sends req upstream and expects a response with status.
+func (l *s3Objects) doExpect(req s3Request, status int, params ...string) (http.Header, error) {
+	resp, err := l.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		return nil, s3ToObjectErr(resp, params...)
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Header, nil
+}
+
+// toS3Header - returns the headers storing metadata upstream.
+func toS3Header(metadata map[string]string) http.Header {
+	header := make(http.Header)
+	for k, v := range metadata {
+		switch {
+		case strings.HasPrefix(http.CanonicalHeaderKey(k), s3GatewayMetadataPrefix):
+			continue
+		case contains(supportedHeaders, k), strings.HasPrefix(k, "X-Amz-Meta-"):
+			header.Set(k, v)
+		case strings.HasPrefix(k, "X-"):
+			header.Set(s3GatewayMetadataPrefix+strings.TrimPrefix(k, "X-"), v)
+		}
+	}
+	return header
+}
+
+// fromS3Header - returns the object info of the headers of an upstream
+// object.
+func fromS3Header(bucket, object string, header http.Header) (ObjectInfo, error) {
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return ObjectInfo{}, traceError(err)
+	}
+	modTime, _ := http.ParseTime(header.Get("Last-Modified"))
+	objInfo := ObjectInfo{
+		Bucket:          bucket,
+		Name:            object,
+		ModTime:         modTime.UTC(),
+		Size:            size,
+		MD5Sum:          strings.Trim(header.Get("ETag"), "\""),
+		ContentType:     header.Get("Content-Type"),
+		ContentEncoding: header.Get("Content-Encoding"),
+		UserDefined:     make(map[string]string),
+	}
+	for _, k := range supportedHeaders {
+		if v := header.Get(k); v != "" {
+			objInfo.UserDefined[k] = v
+		}
+	}
+	for k := range header {
+		switch {
+		case strings.HasPrefix(k, s3GatewayMetadataPrefix):
+			objInfo.UserDefined["X-"+strings.TrimPrefix(k, s3GatewayMetadataPrefix)] = header.Get(k)
+		case strings.HasPrefix(k, "X-Amz-Meta-"):
+			objInfo.UserDefined[k] = header.Get(k)
+		}
+	}
+	return objInfo, nil
+}
+
+// parseS3Time - parses a time of an upstream XML document.
+func parseS3Time(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t.UTC()
+}
+
+// Shutdown - nothing to do for the gateway.
+func (l *s3Objects) Shutdown() error {
+	return nil
+}
+
+// StorageInfo - the capacity of the endpoint is not known.
+func (l *s3Objects) StorageInfo() StorageInfo {
+	var storageInfo StorageInfo
+	storageInfo.Backend.Type = Gateway
+	return storageInfo
+}
+
+/// Bucket operations
+
+// MakeBucket - creates a bucket in the region of the gateway.
+func (l *s3Objects) MakeBucket(bucket string) error {
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	req := s3Request{method: "PUT", bucket: bucket}
+	if l.region != "us-east-1" {
+		data, err := xml.Marshal(createBucketLocationConfiguration{Location: l.region})
+		if err != nil {
+			return traceError(err)
+		}
+		req.body, req.size = bytes.NewReader(data), int64(len(data))
+		sum := sha256.Sum256(data)
+		req.sha256sum = hex.EncodeToString(sum[:])
+	}
+	_, err := l.doExpect(req, http.StatusOK, bucket)
+	return err
+}
+
+// GetBucketInfo - returns the bucket as listed upstream.
+func (l *s3Objects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	if !IsValidBucketName(bucket) {
+		return BucketInfo{}, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	buckets, err := l.ListBuckets()
+	if err != nil {
+		return BucketInfo{}, err
+	}
+	for _, bucketInfo := range buckets {
+		if bucketInfo.Name == bucket {
+			return bucketInfo, nil
+		}
+	}
+	return BucketInfo{}, traceError(BucketNotFound{Bucket: bucket})
+}
+
+// ListBuckets - lists the buckets of the gateway credentials.
+func (l *s3Objects) ListBuckets() ([]BucketInfo, error) {
+	var result struct {
+		Buckets []struct {
+			Name         string
+			CreationDate string
+		} `xml:"Buckets>Bucket"`
+	}
+	if err := l.doDecode(s3Request{method: "GET"}, &result); err != nil {
+		return nil, err
+	}
+	var bucketInfos []BucketInfo
+	for _, bucket := range result.Buckets {
+		bucketInfos = append(bucketInfos, BucketInfo{
+			Name:    bucket.Name,
+			Created: parseS3Time(bucket.CreationDate),
+		})
+	}
+	return bucketInfos, nil
+}
+
+// DeleteBucket - deletes an empty bucket.
+func (l *s3Objects) DeleteBucket(bucket string) error {
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	_, err := l.doExpect(s3Request{method: "DELETE", bucket: bucket}, http.StatusNoContent, bucket)
+	return err
+}
+
+// ListObjects - lists the objects of a bucket, the metadata bucket is
+// always empty.
+func (l *s3Objects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	if bucket == minioMetaBucket {
+		return ListObjectsInfo{}, nil
+	}
+	if !IsValidBucketName(bucket) {
+		return ListObjectsInfo{}, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	query.Set("marker", marker)
+	query.Set("delimiter", delimiter)
+	query.Set("max-keys", strconv.Itoa(maxKeys))
+	var result struct {
+		IsTruncated bool
+		NextMarker  string
+		Contents    []struct {
+			Key          string
+			LastModified string
+			ETag         string
+			Size         int64
+		}
+		CommonPrefixes []struct {
+			Prefix string
+		}
+	}
+	if err := l.doDecode(s3Request{method: "GET", bucket: bucket, query: query}, &result, bucket); err != nil {
+		return ListObjectsInfo{}, err
+	}
+
+	listInfo := ListObjectsInfo{IsTruncated: result.IsTruncated, NextMarker: result.NextMarker}
+	for _, object := range result.Contents {
+		listInfo.Objects = append(listInfo.Objects, ObjectInfo{
+			Bucket:  bucket,
+			Name:    object.Key,
+			ModTime: parseS3Time(object.LastModified),
+			Size:    object.Size,
+			MD5Sum:  strings.Trim(object.ETag, "\""),
+		})
+	}
+	for _, prefix := range result.CommonPrefixes {
+		listInfo.Prefixes = append(listInfo.Prefixes, prefix.Prefix)
+	}
+	// NextMarker is only sent with a delimiter, the listing continues
+	// after the last key otherwise.
+	if listInfo.IsTruncated && listInfo.NextMarker == "" && len(listInfo.Objects) > 0 {
+		listInfo.NextMarker = listInfo.Objects[len(listInfo.Objects)-1].Name
+	}
+	return listInfo, nil
+}
+
+/// Object Operations
+
+// GetObject - writes length bytes of the object from offset to writer.
+func (l *s3Objects) GetObject(bucket, object string, offset int64, length int64, writer io.Writer) error {
+	if bucket == minioMetaBucket {
+		return traceError(ObjectNotFound{Bucket: bucket, Object: object})
+	}
+	if err := checkGetObjArgs(bucket, object); err != nil {
+		return err
+	}
+	// Offset and length cannot be negative.
+	if offset < 0 || length < 0 {
+		return toObjectErr(traceError(errUnexpected), bucket, object)
+	}
+	// Writer cannot be nil.
+	if writer == nil {
+		return toObjectErr(traceError(errUnexpected), bucket, object)
+	}
+	// An empty range can't be requested, only check that the object
+	// exists.
+	if length == 0 {
+		_, err := l.GetObjectInfo(bucket, object)
+		return err
+	}
+
+	header := make(http.Header)
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	resp, err := l.do(s3Request{method: "GET", bucket: bucket, object: object, header: header})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent && (resp.StatusCode != http.StatusOK || offset != 0) {
+		if resp.StatusCode == http.StatusOK {
+			return traceError(fmt.Errorf("Upstream ignored the range of %s", object))
+		}
+		return s3ToObjectErr(resp, bucket, object)
+	}
+	if _, err = io.CopyN(writer, resp.Body, length); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return traceError(IncompleteBody{Bucket: bucket, Object: object})
+		}
+		return traceError(err)
+	}
+	return nil
+}
+
+// GetObjectInfo - returns the object info of the upstream object.
+func (l *s3Objects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return ObjectInfo{}, traceError(ObjectNotFound{Bucket: bucket, Object: object})
+	}
+	if err := checkGetObjArgs(bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+	header, err := l.doExpect(s3Request{method: "HEAD", bucket: bucket, object: object}, http.StatusOK, bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return fromS3Header(bucket, object, header)
+}
+
+// PutObject - uploads the object, data of unknown size is staged in a
+// temporary file as the upstream needs the length.
+func (l *s3Objects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return ObjectInfo{}, traceError(NotImplemented{})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+	if size < 0 {
+		tmpFile, err := ioutil.TempFile("", "minio-gateway-")
+		if err != nil {
+			return ObjectInfo{}, traceError(err)
+		}
+		defer os.Remove(tmpFile.Name())
+		defer tmpFile.Close()
+		if size, err = io.Copy(tmpFile, data); err != nil {
+			return ObjectInfo{}, traceError(err)
+		}
+		if _, err = tmpFile.Seek(0, 0); err != nil {
+			return ObjectInfo{}, traceError(err)
+		}
+		data = tmpFile
+	}
+
+	header := toS3Header(metadata)
+	if md5Hex := metadata["md5Sum"]; md5Hex != "" {
+		md5Bytes, err := hex.DecodeString(md5Hex)
+		if err != nil {
+			return ObjectInfo{}, traceError(BadDigest{ExpectedMD5: md5Hex})
+		}
+		header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5Bytes))
+	}
+	req := s3Request{
+		method:    "PUT",
+		bucket:    bucket,
+		object:    object,
+		header:    header,
+		body:      io.LimitReader(data, size),
+		size:      size,
+		sha256sum: sha256sum,
+	}
+	if _, err := l.doExpect(req, http.StatusOK, bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+	return l.GetObjectInfo(bucket, object)
+}
+
+// CopyObject - copies the object upstream, replacing its metadata.
+func (l *s3Objects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	if srcBucket == minioMetaBucket || dstBucket == minioMetaBucket {
+		return ObjectInfo{}, traceError(NotImplemented{})
+	}
+	if err := checkGetObjArgs(srcBucket, srcObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := checkBucketAndObjectNames(dstBucket, dstObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	header := toS3Header(metadata)
+	header.Set("X-Amz-Copy-Source", getURLEncodedName("/"+srcBucket+"/"+srcObject))
+	header.Set("X-Amz-Metadata-Directive", "REPLACE")
+	var result struct {
+		ETag string
+	}
+	req := s3Request{method: "PUT", bucket: dstBucket, object: dstObject, header: header}
+	if err := l.doDecode(req, &result, srcBucket, srcObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	return l.GetObjectInfo(dstBucket, dstObject)
+}
+
+// DeleteObject - deletes the object, which has to exist.
+func (l *s3Objects) DeleteObject(bucket, object string) error {
+	if bucket == minioMetaBucket {
+		return traceError(NotImplemented{})
+	}
+	if err := checkDelObjArgs(bucket, object); err != nil {
+		return err
+	}
+	// S3 doesn't report deleting missing objects.
+	if _, err := l.GetObjectInfo(bucket, object); err != nil {
+		return err
+	}
+	_, err := l.doExpect(s3Request{method: "DELETE", bucket: bucket, object: object}, http.StatusNoContent, bucket, object)
+	return err
+}
+
+/// Multipart operations
+
+// ListMultipartUploads - lists the incomplete uploads of the bucket.
+func (l *s3Objects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	if bucket == minioMetaBucket {
+		return ListMultipartsInfo{}, nil
+	}
+	if !IsValidBucketName(bucket) {
+		return ListMultipartsInfo{}, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	query := url.Values{}
+	query.Set("uploads", "")
+	query.Set("prefix", prefix)
+	query.Set("key-marker", keyMarker)
+	query.Set("upload-id-marker", uploadIDMarker)
+	query.Set("delimiter", delimiter)
+	query.Set("max-uploads", strconv.Itoa(maxUploads))
+	var result struct {
+		IsTruncated        bool
+		NextKeyMarker      string
+		NextUploadIDMarker string `xml:"NextUploadIdMarker"`
+		Uploads            []struct {
+			Key          string
+			UploadID     string `xml:"UploadId"`
+			Initiated    string
+			StorageClass string
+		} `xml:"Upload"`
+		CommonPrefixes []struct {
+			Prefix string
+		}
+	}
+	if err := l.doDecode(s3Request{method: "GET", bucket: bucket, query: query}, &result, bucket); err != nil {
+		return ListMultipartsInfo{}, err
+	}
+
+	listInfo := ListMultipartsInfo{
+		KeyMarker:          keyMarker,
+		UploadIDMarker:     uploadIDMarker,
+		NextKeyMarker:      result.NextKeyMarker,
+		NextUploadIDMarker: result.NextUploadIDMarker,
+		MaxUploads:         maxUploads,
+		IsTruncated:        result.IsTruncated,
+		Prefix:             prefix,
+		Delimiter:          delimiter,
+	}
+	for _, upload := range result.Uploads {
+		listInfo.Uploads = append(listInfo.Uploads, uploadMetadata{
+			Object:       upload.Key,
+			UploadID:     upload.UploadID,
+			Initiated:    parseS3Time(upload.Initiated),
+			StorageClass: upload.StorageClass,
+		})
+	}
+	for _, prefix := range result.CommonPrefixes {
+		listInfo.CommonPrefixes = append(listInfo.CommonPrefixes, prefix.Prefix)
+	}
+	return listInfo, nil
+}
+
+// NewMultipartUpload - initiates an upload of the object with metadata.
+func (l *s3Objects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if bucket == minioMetaBucket {
+		return "", traceError(NotImplemented{})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("uploads", "")
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	req := s3Request{method: "POST", bucket: bucket, object: object, query: query, header: toS3Header(metadata)}
+	if err := l.doDecode(req, &result, bucket, object); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+// PutObjectPart - uploads a part, returns its ETag.
+func (l *s3Objects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	if bucket == minioMetaBucket {
+		return "", traceError(NotImplemented{})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return "", err
+	}
+	if size < 0 {
+		return "", toObjectErr(traceError(errUnexpected), bucket, object)
+	}
+	header := make(http.Header)
+	if md5Hex != "" {
+		md5Bytes, err := hex.DecodeString(md5Hex)
+		if err != nil {
+			return "", traceError(BadDigest{ExpectedMD5: md5Hex})
+		}
+		header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5Bytes))
+	}
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partID))
+	query.Set("uploadId", uploadID)
+	req := s3Request{
+		method:    "PUT",
+		bucket:    bucket,
+		object:    object,
+		query:     query,
+		header:    header,
+		body:      io.LimitReader(data, size),
+		size:      size,
+		sha256sum: sha256sum,
+	}
+	respHeader, err := l.doExpect(req, http.StatusOK, bucket, object, uploadID)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(respHeader.Get("ETag"), "\""), nil
+}
+
+// ListObjectParts - lists the uploaded parts of an upload.
+func (l *s3Objects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	if bucket == minioMetaBucket {
+		return ListPartsInfo{}, traceError(InvalidUploadID{UploadID: uploadID})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return ListPartsInfo{}, err
+	}
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	query.Set("part-number-marker", strconv.Itoa(partNumberMarker))
+	query.Set("max-parts", strconv.Itoa(maxParts))
+	var result struct {
+		StorageClass         string
+		NextPartNumberMarker int
+		IsTruncated          bool
+		Parts                []struct {
+			PartNumber   int
+			LastModified string
+			ETag         string
+			Size         int64
+		} `xml:"Part"`
+	}
+	if err := l.doDecode(s3Request{method: "GET", bucket: bucket, object: object, query: query}, &result, bucket, object, uploadID); err != nil {
+		return ListPartsInfo{}, err
+	}
+
+	listInfo := ListPartsInfo{
+		Bucket:               bucket,
+		Object:               object,
+		UploadID:             uploadID,
+		StorageClass:         result.StorageClass,
+		PartNumberMarker:     partNumberMarker,
+		NextPartNumberMarker: result.NextPartNumberMarker,
+		MaxParts:             maxParts,
+		IsTruncated:          result.IsTruncated,
+	}
+	for _, part := range result.Parts {
+		listInfo.Parts = append(listInfo.Parts, partInfo{
+			PartNumber:   part.PartNumber,
+			LastModified: parseS3Time(part.LastModified),
+			ETag:         strings.Trim(part.ETag, "\""),
+			Size:         part.Size,
+		})
+	}
+	return listInfo, nil
+}
+
+// AbortMultipartUpload - aborts an upload and removes its parts.
+func (l *s3Objects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if bucket == minioMetaBucket {
+		return traceError(InvalidUploadID{UploadID: uploadID})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	_, err := l.doExpect(s3Request{method: "DELETE", bucket: bucket, object: object, query: query}, http.StatusNoContent, bucket, object, uploadID)
+	return err
+}
+
+// CompleteMultipartUpload - creates the object of the uploaded parts,
+// returns its ETag.
+func (l *s3Objects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	if bucket == minioMetaBucket {
+		return "", traceError(InvalidUploadID{UploadID: uploadID})
+	}
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return "", err
+	}
+	complete := struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}{}
+	for _, part := range uploadedParts {
+		complete.Parts = append(complete.Parts, completePart{
+			PartNumber: part.PartNumber,
+			ETag:       "\"" + canonicalizeETag(part.ETag) + "\"",
+		})
+	}
+	data, err := xml.Marshal(complete)
+	if err != nil {
+		return "", traceError(err)
+	}
+	sum := sha256.Sum256(data)
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	req := s3Request{
+		method:    "POST",
+		bucket:    bucket,
+		object:    object,
+		query:     query,
+		body:      bytes.NewReader(data),
+		size:      int64(len(data)),
+		sha256sum: hex.EncodeToString(sum[:]),
+	}
+	var result struct {
+		ETag string
+	}
+	if err = l.doDecode(req, &result, bucket, object, uploadID); err != nil {
+		return "", err
+	}
+	return strings.Trim(result.ETag, "\""), nil
+}
+
+/// Healing and verification operations, the upstream takes care of
+/// the durability of its objects.
+
+// HealBucket - not implemented.
+func (l *s3Objects) HealBucket(bucket string) error {
+	return traceError(NotImplemented{})
+}
+
+// HealObject - not implemented.
+func (l *s3Objects) HealObject(bucket, object string) error {
+	return traceError(NotImplemented{})
+}
+
+// ListObjectsHeal - not implemented.
+func (l *s3Objects) ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	return ListObjectsInfo{}, traceError(NotImplemented{})
+}
+
+// VerifyObject - not implemented.
+func (l *s3Objects) VerifyObject(bucket, object string) ([]string, error) {
+	return nil, traceError(NotImplemented{})
+}
+
+// QuarantineObject - not implemented.
+func (l *s3Objects) QuarantineObject(bucket, object string) error {
+	return traceError(NotImplemented{})
+}
+
+// PurgeTempFiles - the gateway keeps no temporary files.
+func (l *s3Objects) PurgeTempFiles(olderThan time.Duration) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,250 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+// Tests parsing the endpoint of the gateway.
+func TestNewS3Objects(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		success  bool
+	}{
+		{"https://s3.amazonaws.com", true},
+		{"http://localhost:9000/", true},
+		{"localhost:9000", false},
+		{"ftp://localhost", false},
+		{"http://localhost:9000/bucket", false},
+		{"http://localhost:9000?versioning", false},
+	}
+	for i, testCase := range testCases {
+		_, err := newS3Objects(testCase.endpoint, "access", "secret", "")
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+	}
+	if _, err := newS3Objects("https://s3.amazonaws.com", "", "", ""); err == nil {
+		t.Error("Expected missing credentials to fail")
+	}
+}
+
+// Tests the object layer of the gateway against a server.
+func TestS3Objects(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	obj, err := newS3Objects(testServer.Server.URL, testServer.AccessKey, testServer.SecretKey, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := getRandomBucketName()
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.MakeBucket(bucket); !isSameType(errorCause(err), BucketExists{}) {
+		t.Errorf("Expected BucketExists, got %v", err)
+	}
+	if _, err = obj.GetBucketInfo(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.GetBucketInfo("missing-bucket"); !isSameType(errorCause(err), BucketNotFound{}) {
+		t.Errorf("Expected BucketNotFound, got %v", err)
+	}
+
+	// Objects keep their data and metadata, the internal metadata
+	// included.
+	data := make([]byte, 1024)
+	rand.Read(data)
+	metadata := map[string]string{
+		"content-type":                 "application/json",
+		"X-Amz-Meta-Color":             "red",
+		sseNonceMetadata:               "nonce",
+		"md5Sum":                       getMD5Hash(data),
+		"X-Minio-Meta-Not-Sent-As-Is":  "value",
+		"unknown-key-which-is-dropped": "value",
+	}
+	objInfo, err := obj.PutObject(bucket, "dir/object 1", int64(len(data)), bytes.NewReader(data), metadata, getSHA256Hash(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data)) || objInfo.MD5Sum != getMD5Hash(data) || objInfo.ContentType != "application/json" {
+		t.Errorf("Unexpected object info %v", objInfo)
+	}
+	for _, k := range []string{"content-type", "X-Amz-Meta-Color", sseNonceMetadata, "X-Minio-Meta-Not-Sent-As-Is"} {
+		if objInfo.UserDefined[k] != metadata[k] {
+			t.Errorf("Expected %s to be %q, got %q", k, metadata[k], objInfo.UserDefined[k])
+		}
+	}
+	if _, ok := objInfo.UserDefined["unknown-key-which-is-dropped"]; ok {
+		t.Error("Expected unknown metadata to be dropped")
+	}
+
+	// User metadata can not pass for internal metadata.
+	objInfo, err = obj.PutObject(bucket, "forged", int64(len(data)), bytes.NewReader(data),
+		map[string]string{s3GatewayMetadataPrefix + "Minio-Internal-Kms-Multipart": "true"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo, err = obj.GetObjectInfo(bucket, "forged"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objInfo.UserDefined[kmsMultipartMetadata]; ok {
+		t.Errorf("Expected the forged internal metadata to be dropped, got %v", objInfo.UserDefined)
+	}
+	if err = obj.DeleteObject(bucket, "forged"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": getMD5Hash([]byte("other"))}, ""); !isSameType(errorCause(err), BadDigest{}) {
+		t.Errorf("Expected BadDigest, got %v", err)
+	}
+
+	// Objects of unknown size are uploaded as well.
+	if _, err = obj.PutObject(bucket, "dir/object 2", -1, bytes.NewReader(data[:10]), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "empty", 0, bytes.NewReader(nil), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range [][2]int64{{0, 1024}, {0, 1}, {100, 200}, {1023, 1}} {
+		var buffer bytes.Buffer
+		if err = obj.GetObject(bucket, "dir/object 1", r[0], r[1], &buffer); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[r[0]:r[0]+r[1]]) {
+			t.Errorf("Range %d-%d does not match", r[0], r[0]+r[1])
+		}
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "empty", 0, 0, &buffer); err != nil || buffer.Len() != 0 {
+		t.Errorf("Expected an empty object, got %d bytes, %v", buffer.Len(), err)
+	}
+	if err = obj.GetObject(bucket, "missing", 0, 1, &buffer); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Errorf("Expected ObjectNotFound, got %v", err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "missing"); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Errorf("Expected ObjectNotFound, got %v", err)
+	}
+
+	// Listings page with markers.
+	result, err := obj.ListObjects(bucket, "", "", "/", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "empty" || len(result.Prefixes) != 1 || result.Prefixes[0] != "dir/" {
+		t.Errorf("Unexpected listing %v", result)
+	}
+	result, err = obj.ListObjects(bucket, "dir/", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsTruncated || result.NextMarker != "dir/object 1" {
+		t.Errorf("Expected a truncated listing, got %v", result)
+	}
+	result, err = obj.ListObjects(bucket, "dir/", result.NextMarker, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "dir/object 2" || result.Objects[0].Size != 10 {
+		t.Errorf("Unexpected listing %v", result)
+	}
+
+	objInfo, err = obj.CopyObject(bucket, "dir/object 1", bucket, "copy", objInfo.UserDefined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data)) || objInfo.MD5Sum != getMD5Hash(data) {
+		t.Errorf("Unexpected copy %v", objInfo)
+	}
+
+	// Multipart uploads.
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart", map[string]string{"X-Amz-Meta-Color": "blue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads, err := obj.ListMultipartUploads(bucket, "", "", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads.Uploads) != 1 || uploads.Uploads[0].UploadID != uploadID {
+		t.Errorf("Unexpected uploads %v", uploads)
+	}
+	parts := [][]byte{make([]byte, 5*1024*1024), data}
+	var completeParts []completePart
+	for i, part := range parts {
+		md5Sum, err := obj.PutObjectPart(bucket, "multipart", uploadID, i+1, int64(len(part)), bytes.NewReader(part), getMD5Hash(part), getSHA256Hash(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: md5Sum})
+	}
+	partsInfo, err := obj.ListObjectParts(bucket, "multipart", uploadID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partsInfo.Parts) != 1 || !partsInfo.IsTruncated || partsInfo.Parts[0].Size != int64(len(parts[0])) {
+		t.Errorf("Unexpected parts %v", partsInfo)
+	}
+	if _, err = obj.CompleteMultipartUpload(bucket, "multipart", uploadID, completeParts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.ListObjectParts(bucket, "multipart", uploadID, 0, 1); !isSameType(errorCause(err), InvalidUploadID{}) {
+		t.Errorf("Expected InvalidUploadID, got %v", err)
+	}
+	objInfo, err = obj.GetObjectInfo(bucket, "multipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(parts[0])+len(parts[1])) || objInfo.UserDefined["X-Amz-Meta-Color"] != "blue" {
+		t.Errorf("Unexpected object info %v", objInfo)
+	}
+	uploadID, err = obj.NewMultipartUpload(bucket, "aborted", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.AbortMultipartUpload(bucket, "aborted", uploadID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The metadata bucket is not kept upstream.
+	if _, err = obj.GetObjectInfo(minioMetaBucket, "buckets/"+bucket+"/policy.json"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected ObjectNotFound, got %v", err)
+	}
+	if _, err = obj.PutObject(minioMetaBucket, "config", 0, bytes.NewReader(nil), nil, ""); !isSameType(errorCause(err), NotImplemented{}) {
+		t.Errorf("Expected NotImplemented, got %v", err)
+	}
+
+	if err = obj.DeleteBucket(bucket); !isSameType(errorCause(err), BucketNotEmpty{}) {
+		t.Errorf("Expected BucketNotEmpty, got %v", err)
+	}
+	for _, object := range []string{"dir/object 1", "dir/object 2", "empty", "copy", "multipart"} {
+		if err = obj.DeleteObject(bucket, object); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = obj.DeleteObject(bucket, "empty"); !isSameType(errorCause(err), ObjectNotFound{}) {
+		t.Errorf("Expected ObjectNotFound, got %v", err)
+	}
+	if err = obj.DeleteBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+}
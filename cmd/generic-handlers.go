@@ -82,8 +82,9 @@ func setBrowserRedirectHandler(h http.Handler) http.Handler {
 
 func (h redirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	aType := getRequestAuthType(r)
-	// Re-direct only for JWT and anonymous requests coming from web-browser.
-	if aType == authTypeJWT || aType == authTypeAnonymous {
+	// Re-direct only for JWT and anonymous requests coming from web-browser,
+	// there is nothing to re-direct to if the browser is disabled.
+	if globalIsBrowserEnabled && (aType == authTypeJWT || aType == authTypeAnonymous) {
 		// Re-direction handled specifically for browsers.
 		if strings.Contains(r.Header.Get("User-Agent"), "Mozilla") {
 			switch r.URL.Path {
@@ -312,19 +313,16 @@ func ignoreNotImplementedObjectResources(req *http.Request) bool {
 var notimplementedBucketResourceNames = map[string]bool{
 	"acl":            true,
 	"cors":           true,
-	"lifecycle":      true,
 	"logging":        true,
 	"replication":    true,
 	"tagging":        true,
 	"versions":       true,
 	"requestPayment": true,
-	"versioning":     true,
 	"website":        true,
 }
 
 // List of not implemented object queries
 var notimplementedObjectResourceNames = map[string]bool{
-	"torrent": true,
-	"acl":     true,
-	"policy":  true,
+	"acl":    true,
+	"policy": true,
 }
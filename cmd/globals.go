@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"crypto/x509"
+	"os"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -61,6 +62,10 @@ var (
 
 	globalIsDistXL = false // "Is Distributed?" flag.
 
+	globalIsJBOD = false // Spread whole objects across the disks instead of erasure coding.
+
+	globalIsReadOnly = false // Reject all the writes of the S3 API, the admin API, the browser and FTP.
+
 	// Maximum cache size. Defaults to disabled.
 	// Caching is enabled only for RAM size > 8GiB.
 	globalMaxCacheSize = uint64(0)
@@ -79,6 +84,38 @@ var (
 	// CA root certificates, a nil value means system certs pool will be used
 	globalRootCAs *x509.CertPool
 
+	// Time when the server process was started, used to report uptime.
+	globalBootTime = time.Now().UTC()
+
+	// Is the web browser enabled, set to false with MINIO_BROWSER=off.
+	globalIsBrowserEnabled = true
+
+	// Restrictions on the TLS listeners, set with MINIO_TLS_* variables.
+	globalTLSPolicy tlsPolicy
+
+	// Are the credentials set from the environment.
+	globalIsEnvCreds = false
+
+	// Consistency check run at startup, "check" or "repair" with
+	// MINIO_FSCK, empty if disabled.
+	globalFsckMode = ""
+
+	// Multipart uploads without activity for longer are aborted, set
+	// with MINIO_MULTIPART_EXPIRY, zero if disabled.
+	globalMultipartExpiry time.Duration
+
+	// Disk usage above which uploads are rejected, set with
+	// MINIO_DISK_*_WATERMARK, disabled by default.
+	globalDiskWatermark diskWatermark
+
+	// Timeouts of client connections, set with MINIO_HTTP_*_TIMEOUT,
+	// disabled by default.
+	globalHTTPTimeouts connTimeouts
+
+	// Timeouts of connections to remote peers and transform endpoints,
+	// set with MINIO_REMOTE_*_TIMEOUT.
+	globalRemoteTimeouts connTimeouts
+
 	// Add new variable global values here.
 )
 
@@ -104,6 +141,9 @@ func setGlobalsFromContext(c *cli.Context) {
 		globalConfigDir = c.String("config-dir")
 	case c.GlobalIsSet("config-dir"):
 		globalConfigDir = c.GlobalString("config-dir")
+	case os.Getenv("MINIO_CONFIG_DIR") != "":
+		// Flags take precedence over the environment.
+		globalConfigDir = os.Getenv("MINIO_CONFIG_DIR")
 	}
 	if globalConfigDir == "" {
 		console.Fatalf("Unable to get config file. Config directory is empty.")
@@ -62,6 +62,7 @@ var supportedHeaders = []string{
 	"cache-control",
 	"content-encoding",
 	"content-disposition",
+	"expires",
 	// Add more supported headers here.
 }
 
@@ -98,6 +98,21 @@ func TestExtractMetadataHeaders(t *testing.T) {
 				"content-type": "image/png",
 			},
 		},
+		// Validate if the standard headers replayed on GET are kept.
+		{
+			header: http.Header{
+				"Cache-Control":       []string{"max-age=3600"},
+				"Content-Disposition": []string{"attachment; filename=\"a.png\""},
+				"Content-Encoding":    []string{"gzip"},
+				"Expires":             []string{"Thu, 01 Dec 1994 16:00:00 GMT"},
+			},
+			metadata: map[string]string{
+				"cache-control":       "max-age=3600",
+				"content-disposition": "attachment; filename=\"a.png\"",
+				"content-encoding":    "gzip",
+				"expires":             "Thu, 01 Dec 1994 16:00:00 GMT",
+			},
+		},
 		// Validate if there are no keys to extract.
 		{
 			header: http.Header{
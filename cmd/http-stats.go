@@ -0,0 +1,513 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Number of recent failed requests kept for the web console.
+	maxRecentHTTPErrors = 100
+
+	// Throughput is averaged over the samples taken in this window.
+	throughputSampleInterval = 10 * time.Second
+	throughputSamples        = 6
+
+	// Bucket usage is sampled every hour and kept for a week.
+	bucketUsageSampleInterval = time.Hour
+	maxBucketUsageSamples     = 7 * 24
+)
+
+// HTTPError - a request which failed with a 4xx or 5xx status.
+type HTTPError struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"statusCode"`
+}
+
+// Throughput - request and byte rates per second.
+type Throughput struct {
+	RequestsPerSec float64 `json:"requestsPerSec"`
+	RxBytesPerSec  float64 `json:"rxBytesPerSec"`
+	TxBytesPerSec  float64 `json:"txBytesPerSec"`
+}
+
+// APIStats - request counters of a single API.
+type APIStats struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+}
+
+// httpCounters - cumulative request counters at a point in time.
+type httpCounters struct {
+	time          time.Time
+	requests      uint64
+	errors        uint64
+	serverErrors  uint64
+	bytesReceived uint64
+	bytesSent     uint64
+}
+
+// httpStats - request statistics of the server.
+type httpStats struct {
+	// Updated atomically, kept first for 64-bit alignment.
+	inFlight  int64
+	openConns int64
+
+	mu           sync.Mutex
+	current      httpCounters
+	apis         map[string]*APIStats
+	samples      []httpCounters // Oldest first.
+	recentErrors []HTTPError    // Oldest first.
+}
+
+// Global request statistics, updated by setHTTPStatsHandler.
+var globalHTTPStats = &httpStats{}
+
+// update - records a single completed request.
+func (s *httpStats) update(r *http.Request, statusCode int, bytesReceived, bytesSent uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	api := getRequestAPIName(r)
+	if s.apis == nil {
+		s.apis = make(map[string]*APIStats)
+	}
+	if s.apis[api] == nil {
+		s.apis[api] = &APIStats{}
+	}
+	s.apis[api].Requests++
+
+	s.current.requests++
+	s.current.bytesReceived += bytesReceived
+	s.current.bytesSent += bytesSent
+	if statusCode >= http.StatusInternalServerError {
+		s.current.serverErrors++
+	}
+	if statusCode >= http.StatusBadRequest {
+		s.current.errors++
+		s.apis[api].Errors++
+		if len(s.recentErrors) == maxRecentHTTPErrors {
+			s.recentErrors = s.recentErrors[1:]
+		}
+		s.recentErrors = append(s.recentErrors, HTTPError{
+			Time:       time.Now().UTC(),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			StatusCode: statusCode,
+		})
+	}
+	s.sample(time.Now())
+}
+
+// sample - saves the counters if the last sample is older than
+// throughputSampleInterval, caller must hold the lock.
+func (s *httpStats) sample(now time.Time) {
+	if n := len(s.samples); n > 0 && now.Sub(s.samples[n-1].time) < throughputSampleInterval {
+		return
+	}
+	s.current.time = now
+	if len(s.samples) == throughputSamples {
+		s.samples = s.samples[1:]
+	}
+	s.samples = append(s.samples, s.current)
+}
+
+// snapshot - returns the cumulative counters, the throughput since the
+// oldest sample and the recent errors.
+func (s *httpStats) snapshot() (httpCounters, Throughput, []HTTPError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sample(now)
+	current := s.current
+	current.time = now
+
+	var throughput Throughput
+	oldest := s.samples[0]
+	if elapsed := now.Sub(oldest.time).Seconds(); elapsed > 0 {
+		throughput.RequestsPerSec = float64(current.requests-oldest.requests) / elapsed
+		throughput.RxBytesPerSec = float64(current.bytesReceived-oldest.bytesReceived) / elapsed
+		throughput.TxBytesPerSec = float64(current.bytesSent-oldest.bytesSent) / elapsed
+	}
+
+	recentErrors := make([]HTTPError, len(s.recentErrors))
+	copy(recentErrors, s.recentErrors)
+	return current, throughput, recentErrors
+}
+
+// apiStats - returns the request counters of every API seen so far.
+func (s *httpStats) apiStats() map[string]APIStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apis := make(map[string]APIStats, len(s.apis))
+	for api, stats := range s.apis {
+		apis[api] = *stats
+	}
+	return apis
+}
+
+// getRequestAPIName - returns the name of the S3 API called by r, or
+// Admin, RPC or Browser for requests under the reserved bucket.
+func getRequestAPIName(r *http.Request) string {
+	if r.URL.Path == reservedBucket || strings.HasPrefix(r.URL.Path, reservedBucket+"/") {
+		switch {
+		case strings.HasPrefix(r.URL.Path, adminAPIPathPrefix+"/"):
+			return "Admin"
+		case strings.HasPrefix(r.URL.Path, storageRPCPath),
+			strings.HasPrefix(r.URL.Path, lockRPCPath),
+			strings.HasPrefix(r.URL.Path, reservedBucket+s3Path):
+			return "RPC"
+		}
+		return "Browser"
+	}
+
+	var bucket, object string
+	splits := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	query := r.URL.Query()
+	has := func(key string) bool {
+		_, ok := query[key]
+		return ok
+	}
+	if bucket == "" {
+		return "ListBuckets"
+	}
+	if object != "" {
+		switch r.Method {
+		case "HEAD":
+			return "HeadObject"
+		case "GET":
+			switch {
+			case has("uploadId"):
+				return "ListObjectParts"
+			case has("torrent"):
+				return "GetObjectTorrent"
+			case has("archive"):
+				return "GetObjectArchive"
+			}
+			return "GetObject"
+		case "PUT":
+			switch {
+			case has("uploadId"):
+				return "PutObjectPart"
+			case r.Header.Get("X-Amz-Copy-Source") != "":
+				return "CopyObject"
+			}
+			return "PutObject"
+		case "POST":
+			switch {
+			case has("uploadId"):
+				return "CompleteMultipartUpload"
+			case has("uploads"):
+				return "NewMultipartUpload"
+			case has("select"):
+				return "SelectObjectContent"
+			}
+		case "DELETE":
+			if has("uploadId") {
+				return "AbortMultipartUpload"
+			}
+			return "DeleteObject"
+		}
+		return "Unknown"
+	}
+
+	// Bucket sub-resources handled for every method.
+	subResource := ""
+	for _, resource := range []string{"policy", "notification", "transform", "inventory"} {
+		if has(resource) {
+			subResource = strings.Title(resource)
+			break
+		}
+	}
+	switch r.Method {
+	case "GET":
+		switch {
+		case subResource != "":
+			return "GetBucket" + subResource
+		case has("location"):
+			return "GetBucketLocation"
+		case has("events"):
+			return "ListenBucketNotification"
+		case has("uploads"):
+			return "ListMultipartUploads"
+		case query.Get("list-type") == "2":
+			return "ListObjectsV2"
+		}
+		return "ListObjectsV1"
+	case "PUT":
+		if subResource != "" {
+			return "PutBucket" + subResource
+		}
+		return "PutBucket"
+	case "HEAD":
+		return "HeadBucket"
+	case "POST":
+		if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+			return "PostPolicyBucket"
+		}
+		return "DeleteMultipleObjects"
+	case "DELETE":
+		if subResource != "" {
+			return "DeleteBucket" + subResource
+		}
+		return "DeleteBucket"
+	}
+	return "Unknown"
+}
+
+// ServerStats - live statistics of the server.
+type ServerStats struct {
+	Uptime           time.Duration             `json:"uptime"`
+	Requests         uint64                    `json:"requests"`
+	Errors           uint64                    `json:"errors"`
+	ServerErrors     uint64                    `json:"serverErrors"`
+	BytesReceived    uint64                    `json:"bytesReceived"`
+	BytesSent        uint64                    `json:"bytesSent"`
+	Throughput       Throughput                `json:"throughput"`
+	OpenConnections  int64                     `json:"openConnections"`
+	InFlightRequests int64                     `json:"inFlightRequests"`
+	APIs             map[string]APIStats       `json:"apis"`
+	AuthThrottle     AuthThrottleStats         `json:"authThrottle"`
+	MemoryPressure   MemoryPressureStats       `json:"memoryPressure"`
+	Admission        AdmissionStats            `json:"admission"`
+	QoS              QoSStats                  `json:"qos"`
+	ListCache        ListCacheStats            `json:"listCache"`
+	Dedup            DedupStats                `json:"dedup"`
+	Backend          map[string]BackendOpStats `json:"backend"`
+}
+
+// getServerStats - returns the current statistics of the server.
+func getServerStats() ServerStats {
+	counters, throughput, _ := globalHTTPStats.snapshot()
+	stats := ServerStats{
+		Uptime:           time.Since(globalBootTime),
+		Requests:         counters.requests,
+		Errors:           counters.errors,
+		ServerErrors:     counters.serverErrors,
+		BytesReceived:    counters.bytesReceived,
+		BytesSent:        counters.bytesSent,
+		Throughput:       throughput,
+		OpenConnections:  atomic.LoadInt64(&globalHTTPStats.openConns),
+		InFlightRequests: atomic.LoadInt64(&globalHTTPStats.inFlight),
+		APIs:             globalHTTPStats.apiStats(),
+		MemoryPressure:   globalMemoryPressure.stats(),
+		Admission:        globalAdmissionControl.stats(),
+	}
+	if globalQoSScheduler != nil {
+		stats.QoS = globalQoSScheduler.stats()
+	}
+	if globalListCache != nil {
+		stats.ListCache = globalListCache.stats()
+	}
+	if globalDedup != nil {
+		stats.Dedup = globalDedup.stats()
+	}
+	if globalBackendMetrics != nil {
+		stats.Backend = globalBackendMetrics.stats()
+	}
+	if globalAuthThrottle != nil {
+		stats.AuthThrottle = globalAuthThrottle.stats()
+	}
+	return stats
+}
+
+// statsReader - counts the bytes read from the request body.
+type statsReader struct {
+	io.ReadCloser
+	n uint64
+}
+
+func (r *statsReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += uint64(n)
+	return n, err
+}
+
+// statsResponseWriter - records the status and counts the bytes
+// written to the response.
+type statsResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	n          uint64
+}
+
+func (w *statsResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statsResponseWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.n += uint64(n)
+	return n, err
+}
+
+// Flush - handlers streaming responses expect an http.Flusher.
+func (w *statsResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify - handlers waiting on clients expect an http.CloseNotifier.
+func (w *statsResponseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// Records request statistics for the web console.
+type httpStatsHandler struct {
+	handler http.Handler
+}
+
+func setHTTPStatsHandler(h http.Handler) http.Handler {
+	return httpStatsHandler{handler: h}
+}
+
+func (h httpStatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&globalHTTPStats.inFlight, 1)
+	defer atomic.AddInt64(&globalHTTPStats.inFlight, -1)
+
+	var body *statsReader
+	if r.Body != nil {
+		body = &statsReader{ReadCloser: r.Body}
+		r.Body = body
+	}
+	sw := &statsResponseWriter{ResponseWriter: w}
+	h.handler.ServeHTTP(sw, r)
+
+	var bytesReceived uint64
+	if body != nil {
+		bytesReceived = body.n
+	}
+	statusCode := sw.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	globalHTTPStats.update(r, statusCode, bytesReceived, sw.n)
+}
+
+// BucketUsageSample - size and number of objects of a bucket at a
+// point in time.
+type BucketUsageSample struct {
+	Time    time.Time `json:"time"`
+	Objects int64     `json:"objects"`
+	Size    int64     `json:"size"`
+}
+
+// bucketUsage - usage samples of all buckets, oldest first.
+type bucketUsage struct {
+	mu      sync.RWMutex
+	samples map[string][]BucketUsageSample
+}
+
+// Global bucket usage history, updated by the usage sampler.
+var globalBucketUsage = &bucketUsage{samples: make(map[string][]BucketUsageSample)}
+
+// get - returns the usage samples of bucket, all buckets if empty.
+func (u *bucketUsage) get(bucket string) map[string][]BucketUsageSample {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	usage := make(map[string][]BucketUsageSample)
+	for name, samples := range u.samples {
+		if bucket != "" && name != bucket {
+			continue
+		}
+		usage[name] = append([]BucketUsageSample(nil), samples...)
+	}
+	return usage
+}
+
+// sampleBucketUsage - walks all the objects of all buckets and records
+// their usage, samples of removed buckets are dropped.
+func sampleBucketUsage(objAPI ObjectLayer) error {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return errorCause(err)
+	}
+
+	now := time.Now().UTC()
+	samples := make(map[string]BucketUsageSample)
+	for _, bucket := range buckets {
+		sample := BucketUsageSample{Time: now}
+		marker := ""
+		for {
+			lo, err := objAPI.ListObjects(bucket.Name, "", marker, "", 1000)
+			if err != nil {
+				return errorCause(err)
+			}
+			for _, obj := range lo.Objects {
+				sample.Objects++
+				sample.Size += obj.Size
+			}
+			if !lo.IsTruncated {
+				break
+			}
+			marker = lo.NextMarker
+		}
+		samples[bucket.Name] = sample
+	}
+
+	globalBucketUsage.mu.Lock()
+	defer globalBucketUsage.mu.Unlock()
+	for name := range globalBucketUsage.samples {
+		if _, ok := samples[name]; !ok {
+			delete(globalBucketUsage.samples, name)
+		}
+	}
+	for name, sample := range samples {
+		history := globalBucketUsage.samples[name]
+		if len(history) == maxBucketUsageSamples {
+			history = history[1:]
+		}
+		globalBucketUsage.samples[name] = append(history, sample)
+	}
+	return nil
+}
+
+// startBucketUsageSampler - samples bucket usage now and every
+// bucketUsageSampleInterval for the web console.
+func startBucketUsageSampler(objAPI ObjectLayer) {
+	go func() {
+		for {
+			errorIf(sampleBucketUsage(objAPI), "Unable to sample bucket usage.")
+			time.Sleep(bucketUsageSampleInterval)
+		}
+	}()
+}
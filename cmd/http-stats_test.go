@@ -0,0 +1,133 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that the stats handler counts requests, bytes and errors.
+func TestHTTPStatsHandler(t *testing.T) {
+	savedStats := globalHTTPStats
+	globalHTTPStats = &httpStats{}
+	defer func() { globalHTTPStats = savedStats }()
+
+	handler := setHTTPStatsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("hello"))
+		// Streaming handlers flush the response.
+		w.(http.Flusher).Flush()
+	}))
+
+	for _, path := range []string{"/object", "/missing"} {
+		req, err := http.NewRequest("PUT", path, strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	counters, _, recentErrors := globalHTTPStats.snapshot()
+	if counters.requests != 2 || counters.errors != 1 {
+		t.Errorf("Expected 2 requests and 1 error, got %d and %d", counters.requests, counters.errors)
+	}
+	if counters.bytesReceived != 8 || counters.bytesSent != 5 {
+		t.Errorf("Expected 8 bytes received and 5 sent, got %d and %d", counters.bytesReceived, counters.bytesSent)
+	}
+	if len(recentErrors) != 1 || recentErrors[0].Path != "/missing" || recentErrors[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected recent errors %+v", recentErrors)
+	}
+	// Both paths are buckets, PUT without sub-resources creates one.
+	if apis := globalHTTPStats.apiStats(); apis["PutBucket"] != (APIStats{Requests: 2, Errors: 1}) {
+		t.Errorf("Unexpected API stats %+v", apis)
+	}
+	if inFlight := globalHTTPStats.inFlight; inFlight != 0 {
+		t.Errorf("Expected no requests in flight, got %d", inFlight)
+	}
+}
+
+// Tests classification of requests by API.
+func TestGetRequestAPIName(t *testing.T) {
+	testCases := []struct {
+		method string
+		path   string
+		header string
+		api    string
+	}{
+		{"GET", "/", "", "ListBuckets"},
+		{"GET", "/bucket", "", "ListObjectsV1"},
+		{"GET", "/bucket?list-type=2", "", "ListObjectsV2"},
+		{"GET", "/bucket?location", "", "GetBucketLocation"},
+		{"PUT", "/bucket?policy", "", "PutBucketPolicy"},
+		{"DELETE", "/bucket?inventory", "", "DeleteBucketInventory"},
+		{"POST", "/bucket?delete", "", "DeleteMultipleObjects"},
+		{"GET", "/bucket/dir/object", "", "GetObject"},
+		{"HEAD", "/bucket/object", "", "HeadObject"},
+		{"PUT", "/bucket/object", "", "PutObject"},
+		{"PUT", "/bucket/object", "/src/object", "CopyObject"},
+		{"PUT", "/bucket/object?partNumber=1&uploadId=id", "", "PutObjectPart"},
+		{"POST", "/bucket/object?uploads", "", "NewMultipartUpload"},
+		{"DELETE", "/bucket/object?uploadId=id", "", "AbortMultipartUpload"},
+		{"GET", adminAPIPathPrefix + "/v1/info", "", "Admin"},
+		{"POST", storageRPCPath + "/disk", "", "RPC"},
+		{"POST", reservedBucket + "/webrpc", "", "Browser"},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest(testCase.method, "http://localhost:9000"+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.header != "" {
+			req.Header.Set("X-Amz-Copy-Source", testCase.header)
+		}
+		if api := getRequestAPIName(req); api != testCase.api {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.api, api)
+		}
+	}
+}
+
+// Tests throughput computation and the bound on recent errors.
+func TestHTTPStatsSnapshot(t *testing.T) {
+	stats := &httpStats{}
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/"}}
+	for i := 0; i < maxRecentHTTPErrors+10; i++ {
+		stats.update(req, http.StatusInternalServerError, 0, 0)
+	}
+	// Pretend the first sample was taken 10 seconds ago with no traffic.
+	stats.samples = []httpCounters{{time: time.Now().Add(-10 * time.Second)}}
+
+	counters, throughput, recentErrors := stats.snapshot()
+	if counters.errors != maxRecentHTTPErrors+10 {
+		t.Errorf("Expected %d errors, got %d", maxRecentHTTPErrors+10, counters.errors)
+	}
+	if len(recentErrors) != maxRecentHTTPErrors {
+		t.Errorf("Expected %d recent errors, got %d", maxRecentHTTPErrors, len(recentErrors))
+	}
+	if throughput.RequestsPerSec < 10 || throughput.RequestsPerSec > 11.1 {
+		t.Errorf("Unexpected requests per second %f", throughput.RequestsPerSec)
+	}
+}
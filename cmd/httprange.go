@@ -118,6 +118,11 @@ func parseRequestRange(rangeString string, resourceSize int64) (hrange *httpRang
 			return nil, errInvalidRange
 		}
 
+		if resourceSize == 0 {
+			// An empty resource has no last bytes to serve.
+			return nil, errInvalidRange
+		}
+
 		if offsetEnd >= resourceSize {
 			offsetBegin = 0
 		} else {
@@ -132,3 +137,28 @@ func parseRequestRange(rangeString string, resourceSize int64) (hrange *httpRang
 
 	return &httpRange{offsetBegin, offsetEnd, resourceSize}, nil
 }
+
+// Valid x-amz-copy-source-range regexp, both byte positions are required.
+var validCopyPartRange = regexp.MustCompile(`^bytes=([0-9]+)-([0-9]+)$`)
+
+// parseCopyPartRange - parses the x-amz-copy-source-range header of an
+// upload part copy, eg. "bytes=0-1023". Unlike a Range header the last
+// byte position has to be within the source.
+func parseCopyPartRange(rangeString string, resourceSize int64) (*httpRange, APIErrorCode) {
+	matches := validCopyPartRange.FindStringSubmatch(rangeString)
+	if matches == nil {
+		return nil, ErrInvalidCopyPartRange
+	}
+	offsetBegin, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCopyPartRange
+	}
+	offsetEnd, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil || offsetBegin > offsetEnd {
+		return nil, ErrInvalidCopyPartRange
+	}
+	if offsetEnd >= resourceSize {
+		return nil, ErrInvalidCopyPartRangeSource
+	}
+	return &httpRange{offsetBegin, offsetEnd, resourceSize}, ErrNone
+}
@@ -87,4 +87,43 @@ func TestParseRequestRange(t *testing.T) {
 			t.Fatalf("expected: %s, got: %s", errInvalidRange, err)
 		}
 	}
+
+	// No range of an empty resource is satisfiable.
+	for _, rangeString := range []string{"bytes=0-", "bytes=0-5", "bytes=-5"} {
+		if _, err := parseRequestRange(rangeString, 0); err != errInvalidRange {
+			t.Fatalf("expected: %s, got: %s", errInvalidRange, err)
+		}
+	}
+}
+
+// Test parseCopyPartRange()
+func TestParseCopyPartRange(t *testing.T) {
+	testCases := []struct {
+		rangeString string
+		offsetBegin int64
+		offsetEnd   int64
+		s3Error     APIErrorCode
+	}{
+		{"bytes=2-5", 2, 5, ErrNone},
+		{"bytes=0-9", 0, 9, ErrNone},
+		{"bytes=2-2", 2, 2, ErrNone},
+		{"bytes=2-10", 0, 0, ErrInvalidCopyPartRangeSource},
+		{"bytes=10-11", 0, 0, ErrInvalidCopyPartRangeSource},
+		{"bytes=5-2", 0, 0, ErrInvalidCopyPartRange},
+		{"bytes=2-", 0, 0, ErrInvalidCopyPartRange},
+		{"bytes=-4", 0, 0, ErrInvalidCopyPartRange},
+		{"bytes=2-5,7-8", 0, 0, ErrInvalidCopyPartRange},
+		{"2-5", 0, 0, ErrInvalidCopyPartRange},
+	}
+	for i, testCase := range testCases {
+		hrange, s3Error := parseCopyPartRange(testCase.rangeString, 10)
+		if s3Error != testCase.s3Error {
+			t.Errorf("Test %d: Expected error %d, got %d", i+1, testCase.s3Error, s3Error)
+			continue
+		}
+		if s3Error == ErrNone && (hrange.offsetBegin != testCase.offsetBegin || hrange.offsetEnd != testCase.offsetEnd) {
+			t.Errorf("Test %d: Expected %d-%d, got %d-%d", i+1, testCase.offsetBegin, testCase.offsetEnd,
+				hrange.offsetBegin, hrange.offsetEnd)
+		}
+	}
 }
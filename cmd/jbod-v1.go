@@ -0,0 +1,405 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// jbodObjects - implements the object layer with independent disks,
+// each an FS backend. Every bucket exists on all the disks while each
+// object is kept whole on one of them, picked by rendezvous hashing of
+// the bucket and object name with the paths of the disks. The disks
+// can be given in any order but not added or removed later, objects
+// would no longer be found.
+type jbodObjects struct {
+	disks []ObjectLayer
+	paths []string
+}
+
+// newJBODObjects - returns the object layer of the formatted disks.
+func newJBODObjects(storageDisks []StorageAPI) (ObjectLayer, error) {
+	if len(storageDisks) < 2 {
+		return nil, errInvalidArgument
+	}
+	jbod := &jbodObjects{}
+	for _, storage := range storageDisks {
+		objAPI, err := newFSObjects(storage)
+		if err != nil {
+			return nil, err
+		}
+		jbod.disks = append(jbod.disks, objAPI)
+		jbod.paths = append(jbod.paths, storage.String())
+	}
+	return jbod, nil
+}
+
+// waitForFormatJBODDisks - formats each disk as a separate FS backend.
+func waitForFormatJBODDisks(endpoints []*url.URL, storageDisks []StorageAPI) ([]StorageAPI, error) {
+	var formattedDisks []StorageAPI
+	for i := range storageDisks {
+		disks, err := waitForFormatDisks(true, endpoints[i:i+1], storageDisks[i:i+1])
+		if err != nil {
+			return nil, err
+		}
+		formattedDisks = append(formattedDisks, disks...)
+	}
+	return formattedDisks, nil
+}
+
+// hashDisk - returns the disk keeping the object.
+func (j *jbodObjects) hashDisk(bucket, object string) ObjectLayer {
+	var disk ObjectLayer
+	var maxWeight uint64
+	for i, diskPath := range j.paths {
+		sum := sha256.Sum256([]byte(diskPath + "\x00" + bucket + "/" + object))
+		if weight := binary.BigEndian.Uint64(sum[:8]); disk == nil || weight > maxWeight {
+			disk, maxWeight = j.disks[i], weight
+		}
+	}
+	return disk
+}
+
+// forEachDisk - calls fn with every disk concurrently, returns the
+// first error.
+func (j *jbodObjects) forEachDisk(fn func(i int, disk ObjectLayer) error) error {
+	errs := fanOut(len(j.disks), func(i int) error {
+		return fn(i, j.disks[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown - shuts down all the disks.
+func (j *jbodObjects) Shutdown() error {
+	return j.forEachDisk(func(i int, disk ObjectLayer) error {
+		return disk.Shutdown()
+	})
+}
+
+// StorageInfo - returns the capacity of all the disks.
+func (j *jbodObjects) StorageInfo() StorageInfo {
+	var storageInfo StorageInfo
+	for _, disk := range j.disks {
+		diskInfo := disk.StorageInfo()
+		storageInfo.Total += diskInfo.Total
+		storageInfo.Free += diskInfo.Free
+	}
+	storageInfo.Backend.Type = JBOD
+	storageInfo.Backend.OnlineDisks = len(j.disks)
+	return storageInfo
+}
+
+/// Bucket operations
+
+// MakeBucket - makes the bucket on all the disks, it is removed again
+// from the disks it was made on if that fails on one of them.
+func (j *jbodObjects) MakeBucket(bucket string) error {
+	made := make([]bool, len(j.disks))
+	err := j.forEachDisk(func(i int, disk ObjectLayer) error {
+		if err := disk.MakeBucket(bucket); err != nil {
+			return err
+		}
+		made[i] = true
+		return nil
+	})
+	if err != nil {
+		for i, disk := range j.disks {
+			if made[i] {
+				errorIf(disk.DeleteBucket(bucket), "Unable to remove bucket %s from %s.", bucket, j.paths[i])
+			}
+		}
+	}
+	return err
+}
+
+// GetBucketInfo - returns the bucket as found on the first disk.
+func (j *jbodObjects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	return j.disks[0].GetBucketInfo(bucket)
+}
+
+// ListBuckets - lists the buckets of the first disk.
+func (j *jbodObjects) ListBuckets() ([]BucketInfo, error) {
+	return j.disks[0].ListBuckets()
+}
+
+// DeleteBucket - deletes the bucket from all the disks once it is
+// empty on all of them.
+func (j *jbodObjects) DeleteBucket(bucket string) error {
+	err := j.forEachDisk(func(i int, disk ObjectLayer) error {
+		result, err := disk.ListObjects(bucket, "", "", "", 1)
+		if err != nil {
+			return err
+		}
+		if len(result.Objects) > 0 {
+			return traceError(BucketNotEmpty{Bucket: bucket})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return j.forEachDisk(func(i int, disk ObjectLayer) error {
+		return disk.DeleteBucket(bucket)
+	})
+}
+
+// jbodEntry - an entry of the listing of a disk, either an object, an
+// upload or a common prefix.
+type jbodEntry struct {
+	name   string
+	object *ObjectInfo
+	upload *uploadMetadata
+}
+
+// isPrefix - returns if the entry is a common prefix.
+func (e jbodEntry) isPrefix() bool {
+	return e.object == nil && e.upload == nil
+}
+
+// byJBODEntryName - sorts entries by name, entries of the same name
+// keep their order.
+type byJBODEntryName []jbodEntry
+
+func (e byJBODEntryName) Len() int           { return len(e) }
+func (e byJBODEntryName) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byJBODEntryName) Less(i, j int) bool { return e[i].name < e[j].name }
+
+// mergeJBODEntries - merges the sorted entries of the disks and keeps
+// the first maxKeys. Every disk lists up to maxKeys entries after the
+// marker, so all of the first maxKeys merged entries precede those not
+// listed by a truncated disk. Common prefixes are merged into one.
+func mergeJBODEntries(entries []jbodEntry, maxKeys int, isTruncated bool) ([]jbodEntry, bool) {
+	sort.Stable(byJBODEntryName(entries))
+	var merged []jbodEntry
+	for _, entry := range entries {
+		if n := len(merged); n > 0 && entry.isPrefix() && merged[n-1].isPrefix() && merged[n-1].name == entry.name {
+			continue
+		}
+		merged = append(merged, entry)
+	}
+	if len(merged) > maxKeys {
+		return merged[:maxKeys], true
+	}
+	return merged, isTruncated
+}
+
+// ListObjects - merges the listings of all the disks.
+func (j *jbodObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	results := make([]ListObjectsInfo, len(j.disks))
+	err := j.forEachDisk(func(i int, disk ObjectLayer) (err error) {
+		results[i], err = disk.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+		return err
+	})
+	if err != nil {
+		return ListObjectsInfo{}, err
+	}
+
+	var entries []jbodEntry
+	var isTruncated bool
+	for i := range results {
+		isTruncated = isTruncated || results[i].IsTruncated
+		for k := range results[i].Objects {
+			entries = append(entries, jbodEntry{name: results[i].Objects[k].Name, object: &results[i].Objects[k]})
+		}
+		for _, prefix := range results[i].Prefixes {
+			entries = append(entries, jbodEntry{name: prefix})
+		}
+	}
+	entries, isTruncated = mergeJBODEntries(entries, maxKeys, isTruncated)
+
+	result := ListObjectsInfo{IsTruncated: isTruncated}
+	for _, entry := range entries {
+		if entry.object != nil {
+			result.Objects = append(result.Objects, *entry.object)
+		} else {
+			result.Prefixes = append(result.Prefixes, entry.name)
+		}
+	}
+	if isTruncated && len(entries) > 0 {
+		result.NextMarker = entries[len(entries)-1].name
+	}
+	return result, nil
+}
+
+/// Object Operations
+
+// GetObject - reads the object from its disk.
+func (j *jbodObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	return j.hashDisk(bucket, object).GetObject(bucket, object, startOffset, length, writer)
+}
+
+// GetObjectInfo - returns the object info from its disk.
+func (j *jbodObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	return j.hashDisk(bucket, object).GetObjectInfo(bucket, object)
+}
+
+// PutObject - writes the object to its disk.
+func (j *jbodObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	return j.hashDisk(bucket, object).PutObject(bucket, object, size, data, metadata, sha256sum)
+}
+
+// CopyObject - copies the object on its disk, or reads it and writes
+// it to the disk of the destination.
+func (j *jbodObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	srcDisk := j.hashDisk(srcBucket, srcObject)
+	if srcDisk == j.hashDisk(dstBucket, dstObject) {
+		return srcDisk.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+	return copyObject(j, srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+// DeleteObject - deletes the object from its disk.
+func (j *jbodObjects) DeleteObject(bucket, object string) error {
+	return j.hashDisk(bucket, object).DeleteObject(bucket, object)
+}
+
+/// Multipart operations
+
+// ListMultipartUploads - merges the uploads of all the disks, the
+// uploads of an object are all on its disk which is the only one the
+// upload id marker is passed to.
+func (j *jbodObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	markerDisk := j.hashDisk(bucket, keyMarker)
+	results := make([]ListMultipartsInfo, len(j.disks))
+	err := j.forEachDisk(func(i int, disk ObjectLayer) (err error) {
+		diskUploadIDMarker := ""
+		if disk == markerDisk {
+			diskUploadIDMarker = uploadIDMarker
+		}
+		results[i], err = disk.ListMultipartUploads(bucket, prefix, keyMarker, diskUploadIDMarker, delimiter, maxUploads)
+		return err
+	})
+	if err != nil {
+		return ListMultipartsInfo{}, err
+	}
+
+	var entries []jbodEntry
+	var isTruncated bool
+	for i := range results {
+		isTruncated = isTruncated || results[i].IsTruncated
+		for k := range results[i].Uploads {
+			entries = append(entries, jbodEntry{name: results[i].Uploads[k].Object, upload: &results[i].Uploads[k]})
+		}
+		for _, prefix := range results[i].CommonPrefixes {
+			entries = append(entries, jbodEntry{name: prefix})
+		}
+	}
+	entries, isTruncated = mergeJBODEntries(entries, maxUploads, isTruncated)
+
+	result := ListMultipartsInfo{
+		KeyMarker:      keyMarker,
+		UploadIDMarker: uploadIDMarker,
+		MaxUploads:     maxUploads,
+		IsTruncated:    isTruncated,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+	}
+	for _, entry := range entries {
+		if entry.upload != nil {
+			result.Uploads = append(result.Uploads, *entry.upload)
+		} else {
+			result.CommonPrefixes = append(result.CommonPrefixes, entry.name)
+		}
+	}
+	if isTruncated && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		result.NextKeyMarker = last.name
+		if last.upload != nil {
+			result.NextUploadIDMarker = last.upload.UploadID
+		}
+	}
+	return result, nil
+}
+
+// NewMultipartUpload - initiates the upload on the disk of the object.
+func (j *jbodObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	return j.hashDisk(bucket, object).NewMultipartUpload(bucket, object, metadata)
+}
+
+// PutObjectPart - writes the part on the disk of the object.
+func (j *jbodObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	return j.hashDisk(bucket, object).PutObjectPart(bucket, object, uploadID, partID, size, data, md5Hex, sha256sum)
+}
+
+// ListObjectParts - lists the parts on the disk of the object.
+func (j *jbodObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	return j.hashDisk(bucket, object).ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+// AbortMultipartUpload - aborts the upload on the disk of the object.
+func (j *jbodObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	return j.hashDisk(bucket, object).AbortMultipartUpload(bucket, object, uploadID)
+}
+
+// CompleteMultipartUpload - completes the upload on the disk of the
+// object.
+func (j *jbodObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	return j.hashDisk(bucket, object).CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
+
+/// Healing and verification operations
+
+// HealBucket - heals the bucket on all the disks.
+func (j *jbodObjects) HealBucket(bucket string) error {
+	return j.forEachDisk(func(i int, disk ObjectLayer) error {
+		return disk.HealBucket(bucket)
+	})
+}
+
+// HealObject - heals the object on its disk.
+func (j *jbodObjects) HealObject(bucket, object string) error {
+	return j.hashDisk(bucket, object).HealObject(bucket, object)
+}
+
+// ListObjectsHeal - not implemented, the disks keep no redundancy.
+func (j *jbodObjects) ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	return ListObjectsInfo{}, traceError(NotImplemented{})
+}
+
+// VerifyObject - verifies the object on its disk.
+func (j *jbodObjects) VerifyObject(bucket, object string) ([]string, error) {
+	return j.hashDisk(bucket, object).VerifyObject(bucket, object)
+}
+
+// QuarantineObject - quarantines the object on its disk.
+func (j *jbodObjects) QuarantineObject(bucket, object string) error {
+	return j.hashDisk(bucket, object).QuarantineObject(bucket, object)
+}
+
+// PurgeTempFiles - purges the temporary files of all the disks.
+func (j *jbodObjects) PurgeTempFiles(olderThan time.Duration) (int, error) {
+	purged := make([]int, len(j.disks))
+	err := j.forEachDisk(func(i int, disk ObjectLayer) (err error) {
+		purged[i], err = disk.PurgeTempFiles(olderThan)
+		return err
+	})
+	var total int
+	for _, n := range purged {
+		total += n
+	}
+	return total, err
+}
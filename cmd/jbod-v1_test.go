@@ -0,0 +1,171 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// prepareJBOD - returns a JBOD object layer of n disks.
+func prepareJBOD(t *testing.T, n int) (*jbodObjects, []string) {
+	disks, err := getRandomDisks(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := parseStorageEndpoints(disks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageDisks, err := initStorageDisks(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formattedDisks, err := waitForFormatJBODDisks(endpoints, storageDisks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := newJBODObjects(formattedDisks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj.(*jbodObjects), disks
+}
+
+// Tests that objects are spread across the disks and listed merged.
+func TestJBODObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	jbod, disks := prepareJBOD(t, 3)
+	defer removeRoots(disks)
+
+	bucket := "bucket"
+	if err = jbod.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if err = jbod.MakeBucket(bucket); !isSameType(errorCause(err), BucketExists{}) {
+		t.Errorf("Expected BucketExists, got %v", err)
+	}
+
+	// Every object is kept whole on the disk it hashes to.
+	var names []string
+	counts := make(map[ObjectLayer]int)
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("dir%d/object%02d", i%2, i)
+		names = append(names, name)
+		data := []byte(name)
+		if _, err = jbod.PutObject(bucket, name, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+		disk := jbod.hashDisk(bucket, name)
+		if _, err = disk.GetObjectInfo(bucket, name); err != nil {
+			t.Errorf("Expected %s on its disk, got %v", name, err)
+		}
+		counts[disk]++
+	}
+	if len(counts) != len(jbod.disks) {
+		t.Errorf("Expected objects on all %d disks, got %d", len(jbod.disks), len(counts))
+	}
+
+	// Listings page through all the disks in order.
+	var listed []string
+	marker := ""
+	for {
+		result, err := jbod.ListObjects(bucket, "", marker, "", 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, objInfo := range result.Objects {
+			listed = append(listed, objInfo.Name)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	if len(listed) != len(names) {
+		t.Fatalf("Expected %d objects, got %d", len(names), len(listed))
+	}
+	for i := 1; i < len(listed); i++ {
+		if listed[i-1] >= listed[i] {
+			t.Errorf("Expected a sorted listing, got %s before %s", listed[i-1], listed[i])
+		}
+	}
+	result, err := jbod.ListObjects(bucket, "", "", "/", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 0 || len(result.Prefixes) != 2 || result.Prefixes[0] != "dir0/" || result.Prefixes[1] != "dir1/" {
+		t.Errorf("Expected the prefixes once, got %v", result)
+	}
+
+	// Copies between disks are read back.
+	var src, dst string
+	for _, name := range names[1:] {
+		if jbod.hashDisk(bucket, name) != jbod.hashDisk(bucket, names[0]) {
+			src, dst = names[0], name+"-copy"
+			break
+		}
+	}
+	if _, err = jbod.CopyObject(bucket, src, bucket, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err = jbod.GetObject(bucket, dst, 0, int64(len(src)), &buffer); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.String() != src {
+		t.Errorf("Expected the copy to be %q, got %q", src, buffer.String())
+	}
+
+	// Uploads are listed across the disks.
+	for _, name := range names[:5] {
+		if _, err = jbod.NewMultipartUpload(bucket, name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uploads, err := jbod.ListMultipartUploads(bucket, "", "", "", "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uploads.IsTruncated || len(uploads.Uploads) != 3 {
+		t.Fatalf("Expected 3 of 5 uploads, got %v", uploads)
+	}
+	uploads, err = jbod.ListMultipartUploads(bucket, "", uploads.NextKeyMarker, uploads.NextUploadIDMarker, "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploads.IsTruncated || len(uploads.Uploads) != 2 {
+		t.Errorf("Expected the other 2 uploads, got %v", uploads)
+	}
+
+	if err = jbod.DeleteBucket(bucket); !isSameType(errorCause(err), BucketNotEmpty{}) {
+		t.Errorf("Expected BucketNotEmpty, got %v", err)
+	}
+}
+
+// Tests that fewer than 2 disks are rejected.
+func TestNewJBODObjects(t *testing.T) {
+	if _, err := newJBODObjects(nil); err != errInvalidArgument {
+		t.Errorf("Expected %s, got %v", errInvalidArgument, err)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// Number of objects re-wrapped between two progress reports.
+const kmsRewrapPageSize = 1000
+
+// KMSRewrapInfo - progress of re-wrapping the data keys of the objects
+// of a bucket under the current master key. The objects are re-wrapped
+// in order, then the prior versions of objects, a run is resumed after
+// Marker and VersionMarker.
+type KMSRewrapInfo struct {
+	Bucket string `json:"bucket"`
+	// Last object and prior version re-wrapped or skipped.
+	Marker        string `json:"marker,omitempty"`
+	VersionMarker string `json:"versionMarker,omitempty"`
+	// Number of objects and prior versions whose data keys were
+	// re-wrapped, and of those not encrypted or already sealed by the
+	// current master key.
+	Rewrapped int64 `json:"rewrapped"`
+	Skipped   int64 `json:"skipped"`
+	// Set on the last progress reported, along with Error if the run
+	// failed.
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// kmsRewrapper - object layers re-wrapping the data keys of encrypted
+// objects.
+type kmsRewrapper interface {
+	// rewrapKeys - re-wraps the data keys of the objects of a bucket
+	// from progress on, calling report after every page of objects.
+	// Data keys already sealed by the current master key are only
+	// re-wrapped if force is true.
+	rewrapKeys(progress KMSRewrapInfo, force bool, report func(KMSRewrapInfo) error) error
+}
+
+// rewrapSealedKey - returns metadata with the data key sealed in it
+// re-wrapped under the current master key, nil if it needs no
+// re-wrapping.
+func (e encryptedObjects) rewrapSealedKey(bucket, object string, objInfo ObjectInfo, force bool) (map[string]string, error) {
+	if !isKMSEncryptedObject(objInfo) || (!force && objInfo.UserDefined[sseKMSKeyIDHeader] == e.kms.KeyID()) {
+		return nil, nil
+	}
+	key, err := e.unsealKey(bucket, object, objInfo)
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := e.kms.SealKey(key, kmsContext(bucket, object))
+	if err != nil {
+		return nil, traceError(err)
+	}
+	metadata := make(map[string]string, len(objInfo.UserDefined))
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	metadata[sseKMSKeyIDHeader] = e.kms.KeyID()
+	metadata[kmsSealedKeyMetadata] = base64.StdEncoding.EncodeToString(sealedKey)
+	return metadata, nil
+}
+
+// rewrapObject - re-wraps the data key of an object, returns false if
+// it needs no re-wrapping. The encrypted data is copied onto itself
+// with the new metadata. The parts of multipart objects would be lost
+// by the copy, their data is encrypted again under a new data key.
+func (e encryptedObjects) rewrapObject(bucket, object string, force bool) (bool, error) {
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return false, err
+	}
+	metadata, err := e.rewrapSealedKey(bucket, object, objInfo, force)
+	if err != nil || metadata == nil {
+		return false, err
+	}
+	if objInfo.UserDefined[kmsMultipartMetadata] == "true" {
+		_, err = copyObject(e, bucket, object, bucket, object, metadata)
+		return err == nil, err
+	}
+	// The object must not be replaced since its data key was read, the
+	// copy fails on another MD5.
+	if !strings.Contains(objInfo.MD5Sum, "-") {
+		metadata["md5Sum"] = objInfo.MD5Sum
+	}
+	_, err = e.ObjectLayer.CopyObject(bucket, object, bucket, object, metadata)
+	if _, ok := errorCause(err).(BadDigest); ok {
+		// Replaced since read, re-wrap the new object.
+		return e.rewrapObject(bucket, object, force)
+	}
+	return err == nil, err
+}
+
+// rewrapObjectVersion - re-wraps the data key of a prior version of an
+// object, kept with its object info, returns false if it needs no
+// re-wrapping.
+func (e encryptedObjects) rewrapObjectVersion(bucket, object, versionID string, force bool) (bool, error) {
+	configFile := getObjectVersionInfoFile(object, versionID)
+	data, err := readBucketConfigFile(bucket, configFile, e.ObjectLayer)
+	if err != nil {
+		return false, err
+	}
+	var version objectVersionV1
+	if err = json.Unmarshal(data, &version); err != nil {
+		return false, err
+	}
+	metadata, err := e.rewrapSealedKey(bucket, object, version.Info, force)
+	if err != nil || metadata == nil {
+		return false, err
+	}
+	version.Info.UserDefined = metadata
+	if data, err = json.Marshal(version); err != nil {
+		return false, err
+	}
+	return true, writeBucketConfigFile(bucket, configFile, data, e.ObjectLayer)
+}
+
+// rewrapKeys - re-wraps the data keys of the objects of a bucket, then
+// of the prior versions of its objects.
+func (e encryptedObjects) rewrapKeys(progress KMSRewrapInfo, force bool, report func(KMSRewrapInfo) error) error {
+	if e.kms == nil {
+		return traceError(errKMSNotConfigured)
+	}
+	bucket := progress.Bucket
+	for {
+		result, err := e.ObjectLayer.ListObjects(bucket, "", progress.Marker, "", kmsRewrapPageSize)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			rewrapped, err := e.rewrapObject(bucket, objInfo.Name, force)
+			if err != nil && !isErrObjectNotFound(err) {
+				return err
+			}
+			if rewrapped {
+				progress.Rewrapped++
+			} else {
+				progress.Skipped++
+			}
+			progress.Marker = objInfo.Name
+		}
+		if err = report(progress); err != nil {
+			return err
+		}
+		if !result.IsTruncated {
+			break
+		}
+	}
+
+	// Prior versions are listed by their object info files, named
+	// "<version-id>/<object>.json".
+	prefix := path.Join(bucketConfigPrefix, bucket, objectVersionInfoPrefix) + slashSeparator
+	for {
+		marker := ""
+		if progress.VersionMarker != "" {
+			marker = prefix + progress.VersionMarker
+		}
+		result, err := e.ObjectLayer.ListObjects(minioMetaBucket, prefix, marker, "", kmsRewrapPageSize)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			name := strings.TrimPrefix(objInfo.Name, prefix)
+			elems := strings.SplitN(strings.TrimSuffix(name, ".json"), slashSeparator, 2)
+			if len(elems) == 2 && isValidVersionID(elems[0]) {
+				rewrapped, err := e.rewrapObjectVersion(bucket, elems[1], elems[0], force)
+				if err != nil && err != errConfigNotFound {
+					return err
+				}
+				if rewrapped {
+					progress.Rewrapped++
+				} else {
+					progress.Skipped++
+				}
+			}
+			progress.VersionMarker = name
+		}
+		if err = report(progress); err != nil {
+			return err
+		}
+		if !result.IsTruncated {
+			break
+		}
+	}
+	progress.Done = true
+	return report(progress)
+}
+
+// rewrapKeys - re-wraps the data keys of the objects of a bucket and
+// drops all its listings, whose objects may have changed.
+func (l listCacheObjects) rewrapKeys(progress KMSRewrapInfo, force bool, report func(KMSRewrapInfo) error) error {
+	rewrapper, ok := l.ObjectLayer.(kmsRewrapper)
+	if !ok {
+		return traceError(errKMSNotConfigured)
+	}
+	defer l.cache.invalidate(progress.Bucket, "")
+	return rewrapper.rewrapKeys(progress, force, report)
+}
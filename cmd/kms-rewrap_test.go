@@ -0,0 +1,155 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Wrapper for calling testRewrapKeys for both XL and FS.
+func TestRewrapKeys(t *testing.T) {
+	ExecObjectLayerTest(t, testRewrapKeys)
+}
+
+// Tests that the data keys of objects, multipart objects and prior
+// versions sealed by a previous master key are re-wrapped under the
+// current one, and that a run is resumed from its markers.
+func testRewrapKeys(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	previous, err := parseMasterKey("old-key:" + strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldObj := newEncryptedObjects(obj, previous, nil)
+
+	bucket := getRandomBucketName()
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := make([]byte, 3*kmsSegmentSize+5)
+	rand.Read(data)
+	if _, err = oldObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = archiveObjectVersion(oldObj, bucket, "object", versioningEnabled); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	parts := [][]byte{make([]byte, 5*1024*1024), make([]byte, kmsSegmentSize+7)}
+	uploadID, err := oldObj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var multipartData []byte
+	var completeParts []completePart
+	for i, part := range parts {
+		rand.Read(part)
+		multipartData = append(multipartData, part...)
+		md5Sum, err := oldObj.PutObjectPart(bucket, "multipart", uploadID, i+1, int64(len(part)), bytes.NewReader(part), "", "")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: md5Sum})
+	}
+	if _, err = oldObj.CompleteMultipartUpload(bucket, "multipart", uploadID, completeParts); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.PutObject(bucket, "plain", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	stored, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Uploads started under the previous master key continue under
+	// the current one.
+	eobj := newEncryptedObjects(obj, kms, previous).(encryptedObjects)
+	uploadID, err = oldObj.NewMultipartUpload(bucket, "upload", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = eobj.PutObjectPart(bucket, "upload", uploadID, 1, int64(len(data)), bytes.NewReader(data), "", ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = eobj.AbortMultipartUpload(bucket, "upload", uploadID); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	read := func(eobj ObjectLayer, bucket, object string, data []byte) {
+		var buffer bytes.Buffer
+		if err = eobj.GetObject(bucket, object, 0, int64(len(data)), &buffer); err != nil {
+			t.Fatalf("%s: %s: %s", instanceType, object, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Errorf("%s: The data of %s does not match", instanceType, object)
+		}
+	}
+	versionPath := getObjectVersionPath(bucket, "object", nullVersionID)
+	read(eobj, bucket, "object", data)
+	read(eobj, minioMetaBucket, versionPath, data)
+
+	var reports []KMSRewrapInfo
+	report := func(progress KMSRewrapInfo) error {
+		reports = append(reports, progress)
+		return nil
+	}
+	if err = eobj.rewrapKeys(KMSRewrapInfo{Bucket: bucket}, false, report); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	last := reports[len(reports)-1]
+	if len(reports) < 2 || !last.Done || last.Rewrapped != 3 || last.Skipped != 1 || last.Marker != "plain" {
+		t.Fatalf("%s: Unexpected progress %+v", instanceType, reports)
+	}
+
+	// The previous master key is not needed anymore, the data of
+	// single part objects is kept as it is stored.
+	eobj = newEncryptedObjects(obj, kms, nil).(encryptedObjects)
+	read(eobj, bucket, "object", data)
+	read(eobj, bucket, "multipart", multipartData)
+	read(eobj, minioMetaBucket, versionPath, data)
+	rewrapped, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if rewrapped.UserDefined[sseKMSKeyIDHeader] != "my-key" || rewrapped.MD5Sum != stored.MD5Sum {
+		t.Errorf("%s: Expected the same data under my-key, got %s, %s", instanceType, rewrapped.MD5Sum, rewrapped.UserDefined)
+	}
+
+	// Keys sealed by the current master key are left alone unless
+	// forced, a run resumed after "object" only visits the rest.
+	reports = nil
+	if err = eobj.rewrapKeys(KMSRewrapInfo{Bucket: bucket}, false, report); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if last = reports[len(reports)-1]; last.Rewrapped != 0 || last.Skipped != 4 {
+		t.Errorf("%s: Expected nothing re-wrapped, got %+v", instanceType, last)
+	}
+	reports = nil
+	if err = eobj.rewrapKeys(KMSRewrapInfo{Bucket: bucket, Marker: "object"}, true, report); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if last = reports[len(reports)-1]; last.Rewrapped != 1 || last.Skipped != 1 {
+		t.Errorf("%s: Expected the prior version re-wrapped, got %+v", instanceType, last)
+	}
+	read(eobj, minioMetaBucket, versionPath, data)
+}
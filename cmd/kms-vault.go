@@ -0,0 +1,165 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Timeout of requests to Vault.
+	vaultTimeout = 10 * time.Second
+
+	// Maximum size of Vault responses read.
+	maxVaultResponseSize = 64 * 1024
+)
+
+// vaultKMS - seals data keys with a key of the transit secrets engine
+// of a Vault server. The key has to be created with derived set, so
+// that sealed keys are bound to their context.
+type vaultKMS struct {
+	endpoint string
+	token    string
+	keyName  string
+	client   *http.Client
+}
+
+// vaultResponse - body of Vault responses, carrying either data or
+// errors.
+type vaultResponse struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// newVaultKMS - returns a KMS sealing data keys with the transit key
+// keyName of the Vault server at endpoint, authenticated with token.
+func newVaultKMS(endpoint, token, keyName string) (KMS, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("Vault endpoint should be a URL such as https://vault.example.com:8200")
+	}
+	if token == "" || keyName == "" {
+		return nil, errors.New("Vault token and key name must be set")
+	}
+	return vaultKMS{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		keyName:  keyName,
+		client:   &http.Client{Timeout: vaultTimeout},
+	}, nil
+}
+
+// KeyID - returns the name of the transit key.
+func (v vaultKMS) KeyID() string {
+	return v.keyName
+}
+
+// post - sends a request with a JSON body to the transit API path of
+// the key and decodes the response.
+func (v vaultKMS) post(apiPath string, body map[string]string) (vaultResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return vaultResponse{}, err
+	}
+	req, err := http.NewRequest("POST", v.endpoint+"/v1/transit/"+apiPath+"/"+url.QueryEscape(v.keyName), bytes.NewReader(data))
+	if err != nil {
+		return vaultResponse{}, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return vaultResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var vresp vaultResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxVaultResponseSize)).Decode(&vresp); err != nil && resp.StatusCode == http.StatusOK {
+		return vaultResponse{}, err
+	}
+	// Drain the rest of the body so the connection can be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxVaultResponseSize))
+	if resp.StatusCode != http.StatusOK {
+		if len(vresp.Errors) > 0 {
+			return vaultResponse{}, fmt.Errorf("Vault replied %s: %s", resp.Status, strings.Join(vresp.Errors, ", "))
+		}
+		return vaultResponse{}, fmt.Errorf("Vault replied %s", resp.Status)
+	}
+	return vresp, nil
+}
+
+// GenerateKey - returns a new data key generated by Vault, and the key
+// sealed by Vault.
+func (v vaultKMS) GenerateKey(context string) (key, sealedKey []byte, err error) {
+	vresp, err := v.post("datakey/plaintext", map[string]string{
+		"context": base64.StdEncoding.EncodeToString([]byte(context)),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err = base64.StdEncoding.DecodeString(vresp.Data.Plaintext)
+	if err != nil || len(key) != kmsKeyLength || vresp.Data.Ciphertext == "" {
+		return nil, nil, errors.New("Vault returned an invalid data key")
+	}
+	return key, []byte(vresp.Data.Ciphertext), nil
+}
+
+// UnsealKey - returns the data key sealed in sealedKey, decrypted by
+// Vault.
+func (v vaultKMS) UnsealKey(sealedKey []byte, context string) ([]byte, error) {
+	vresp, err := v.post("decrypt", map[string]string{
+		"ciphertext": string(sealedKey),
+		"context":    base64.StdEncoding.EncodeToString([]byte(context)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(vresp.Data.Plaintext)
+	if err != nil || len(key) != kmsKeyLength {
+		return nil, errKMSInvalidSealedKey
+	}
+	return key, nil
+}
+
+// SealKey - returns key encrypted by Vault under the latest version of
+// the master key.
+func (v vaultKMS) SealKey(key []byte, context string) ([]byte, error) {
+	vresp, err := v.post("encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(key),
+		"context":   base64.StdEncoding.EncodeToString([]byte(context)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if vresp.Data.Ciphertext == "" {
+		return nil, errors.New("Vault returned an invalid sealed key")
+	}
+	return []byte(vresp.Data.Ciphertext), nil
+}
@@ -0,0 +1,183 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Length of data keys and master keys, 256 bits.
+const kmsKeyLength = 32
+
+// KMS - seals the data keys objects are encrypted with under a master
+// key, which never leaves the KMS.
+type KMS interface {
+	// KeyID - returns the ID of the master key.
+	KeyID() string
+
+	// GenerateKey - returns a new data key, and the data key sealed
+	// by the master key. The sealed key can only be unsealed with the
+	// same context.
+	GenerateKey(context string) (key, sealedKey []byte, err error)
+
+	// UnsealKey - returns the data key sealed in sealedKey.
+	UnsealKey(sealedKey []byte, context string) (key []byte, err error)
+
+	// SealKey - returns an existing data key sealed by the master
+	// key, so that data keys are re-wrapped when the master key is
+	// rotated.
+	SealKey(key []byte, context string) (sealedKey []byte, err error)
+}
+
+// Encrypts new objects with data keys sealed by the configured KMS, set
+// with MINIO_KMS_MASTER_KEY or MINIO_KMS_VAULT_*, nil if disabled.
+var globalKMS KMS
+
+// Unseals the data keys of the objects still sealed by the master key
+// the configured KMS replaced, set with MINIO_KMS_PREVIOUS_MASTER_KEY,
+// nil if none.
+var globalPreviousKMS KMS
+
+// staticKMS - seals data keys with a master key held by the server.
+type staticKMS struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// newStaticKMS - returns a KMS sealing data keys with masterKey, a
+// 256 bit key.
+func newStaticKMS(keyID string, masterKey []byte) (KMS, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return staticKMS{keyID: keyID, aead: aead}, nil
+}
+
+// KeyID - returns the ID of the master key.
+func (k staticKMS) KeyID() string {
+	return k.keyID
+}
+
+// GenerateKey - returns a new data key, and the key sealed with
+// AES-256-GCM as its random nonce followed by its ciphertext.
+func (k staticKMS) GenerateKey(context string) (key, sealedKey []byte, err error) {
+	key = make([]byte, kmsKeyLength)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return nil, nil, err
+	}
+	if sealedKey, err = k.SealKey(key, context); err != nil {
+		return nil, nil, err
+	}
+	return key, sealedKey, nil
+}
+
+// SealKey - returns key sealed with AES-256-GCM as its random nonce
+// followed by its ciphertext.
+func (k staticKMS) SealKey(key []byte, context string) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, key, []byte(context)), nil
+}
+
+// UnsealKey - returns the data key sealed in sealedKey.
+func (k staticKMS) UnsealKey(sealedKey []byte, context string) ([]byte, error) {
+	if len(sealedKey) < k.aead.NonceSize() {
+		return nil, errKMSInvalidSealedKey
+	}
+	nonce, ciphertext := sealedKey[:k.aead.NonceSize()], sealedKey[k.aead.NonceSize():]
+	key, err := k.aead.Open(nil, nonce, ciphertext, []byte(context))
+	if err != nil {
+		return nil, errKMSInvalidSealedKey
+	}
+	return key, nil
+}
+
+// parseMasterKey - returns the static KMS of a master key set as
+// "<key-id>:<hex encoded 256 bit key>".
+func parseMasterKey(value string) (KMS, error) {
+	i := strings.LastIndex(value, ":")
+	if i <= 0 {
+		return nil, errors.New("Master key should be set as <key-id>:<hex encoded 256 bit key>")
+	}
+	masterKey, err := hex.DecodeString(value[i+1:])
+	if err != nil || len(masterKey) != kmsKeyLength {
+		return nil, errors.New("Master key should be a hex encoded 256 bit key")
+	}
+	return newStaticKMS(value[:i], masterKey)
+}
+
+// parseKMS - returns the KMS configured by a master key or by the
+// endpoint, token and key name of a Vault server, nil if neither is
+// set.
+func parseKMS(masterKey, vaultEndpoint, vaultToken, vaultKeyName string) (KMS, error) {
+	vault := vaultEndpoint != "" || vaultToken != "" || vaultKeyName != ""
+	switch {
+	case masterKey != "" && vault:
+		return nil, errors.New("Either a master key or a Vault server can be set, not both")
+	case masterKey != "":
+		return parseMasterKey(masterKey)
+	case vault:
+		return newVaultKMS(vaultEndpoint, vaultToken, vaultKeyName)
+	}
+	return nil, nil
+}
+
+// loadKMS - loads the KMS from the environment.
+func loadKMS() (KMS, error) {
+	return parseKMS(os.Getenv("MINIO_KMS_MASTER_KEY"), os.Getenv("MINIO_KMS_VAULT_ENDPOINT"),
+		os.Getenv("MINIO_KMS_VAULT_TOKEN"), os.Getenv("MINIO_KMS_VAULT_KEY_NAME"))
+}
+
+// parsePreviousKMS - returns the static KMS of the master key kms
+// replaced, nil if none is set. Its key ID must differ from the one
+// of kms, as objects are unsealed by the KMS of their key ID.
+func parsePreviousKMS(previousMasterKey string, kms KMS) (KMS, error) {
+	if previousMasterKey == "" {
+		return nil, nil
+	}
+	if kms == nil {
+		return nil, errors.New("A previous master key can only be set along with a KMS")
+	}
+	previous, err := parseMasterKey(previousMasterKey)
+	if err != nil {
+		return nil, err
+	}
+	if previous.KeyID() == kms.KeyID() {
+		return nil, errors.New("The previous master key should have another key ID than the KMS")
+	}
+	return previous, nil
+}
+
+// loadPreviousKMS - loads the KMS of the previous master key from the
+// environment.
+func loadPreviousKMS(kms KMS) (KMS, error) {
+	return parsePreviousKMS(os.Getenv("MINIO_KMS_PREVIOUS_MASTER_KEY"), kms)
+}
@@ -0,0 +1,206 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testMasterKey = "my-key:6368616e676520746869732070617373776f726420746f206120736563726574"
+
+// Tests parsing the KMS configuration.
+func TestParseKMS(t *testing.T) {
+	testCases := []struct {
+		masterKey, endpoint, token, keyName string
+		keyID                               string
+		success                             bool
+	}{
+		{"", "", "", "", "", true},
+		{testMasterKey, "", "", "", "my-key", true},
+		{"a:b:" + strings.Repeat("00", 32), "", "", "", "a:b", true},
+		{strings.Repeat("00", 32), "", "", "", "", false},
+		{"my-key:" + strings.Repeat("00", 16), "", "", "", "", false},
+		{"my-key:" + strings.Repeat("zz", 32), "", "", "", "", false},
+		{"", "https://vault:8200", "token", "minio", "minio", true},
+		{"", "vault:8200", "token", "minio", "", false},
+		{"", "https://vault:8200", "", "minio", "", false},
+		{"", "https://vault:8200", "token", "", "", false},
+		{testMasterKey, "https://vault:8200", "token", "minio", "", false},
+	}
+	for i, testCase := range testCases {
+		kms, err := parseKMS(testCase.masterKey, testCase.endpoint, testCase.token, testCase.keyName)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if err == nil && testCase.keyID == "" && kms != nil {
+			t.Errorf("Test %d: Expected no KMS, got %s", i+1, kms.KeyID())
+		}
+		if testCase.keyID != "" && kms.KeyID() != testCase.keyID {
+			t.Errorf("Test %d: Expected key ID %s, got %s", i+1, testCase.keyID, kms.KeyID())
+		}
+	}
+}
+
+// Tests parsing the previous master key, which needs a KMS with
+// another key ID.
+func TestParsePreviousKMS(t *testing.T) {
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousKey := "old-key:" + strings.Repeat("01", 32)
+	testCases := []struct {
+		previousMasterKey string
+		kms               KMS
+		keyID             string
+		success           bool
+	}{
+		{"", nil, "", true},
+		{"", kms, "", true},
+		{previousKey, kms, "old-key", true},
+		{previousKey, nil, "", false},
+		{"old-key:" + strings.Repeat("01", 16), kms, "", false},
+		{"my-key:" + strings.Repeat("01", 32), kms, "", false},
+	}
+	for i, testCase := range testCases {
+		previous, err := parsePreviousKMS(testCase.previousMasterKey, testCase.kms)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if testCase.keyID == "" && previous != nil {
+			t.Errorf("Test %d: Expected no previous KMS, got %s", i+1, previous.KeyID())
+		}
+		if testCase.keyID != "" && (previous == nil || previous.KeyID() != testCase.keyID) {
+			t.Errorf("Test %d: Expected key ID %s, got %v", i+1, testCase.keyID, previous)
+		}
+	}
+}
+
+// Tests that data keys are unsealed only with the context they were
+// sealed with.
+func testKMS(t *testing.T, kms KMS) {
+	key, sealedKey, err := kms.GenerateKey("bucket/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != kmsKeyLength {
+		t.Fatalf("Expected a key of %d bytes, got %d", kmsKeyLength, len(key))
+	}
+	if bytes.Contains(sealedKey, key) {
+		t.Fatal("Expected the sealed key not to contain the key")
+	}
+	unsealedKey, err := kms.UnsealKey(sealedKey, "bucket/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unsealedKey, key) {
+		t.Fatal("Expected the unsealed key to be the key")
+	}
+	if _, err = kms.UnsealKey(sealedKey, "bucket/other"); err == nil {
+		t.Fatal("Expected unsealing with another context to fail")
+	}
+	if _, err = kms.UnsealKey(sealedKey[:4], "bucket/object"); err == nil {
+		t.Fatal("Expected unsealing a truncated key to fail")
+	}
+
+	// Existing keys are sealed again with a new sealed key.
+	resealedKey, err := kms.SealKey(key, "bucket/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(resealedKey, sealedKey) || bytes.Contains(resealedKey, key) {
+		t.Fatal("Expected a new sealed key not containing the key")
+	}
+	if unsealedKey, err = kms.UnsealKey(resealedKey, "bucket/object"); err != nil || !bytes.Equal(unsealedKey, key) {
+		t.Fatalf("Expected the resealed key to unseal to the key, got %v", err)
+	}
+}
+
+// Tests sealing data keys with a static master key.
+func TestStaticKMS(t *testing.T) {
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testKMS(t, kms)
+}
+
+// Tests sealing data keys with Vault, served by a static KMS.
+func TestVaultKMS(t *testing.T) {
+	transit, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		context, _ := base64.StdEncoding.DecodeString(req["context"])
+		var resp vaultResponse
+		switch r.URL.Path {
+		case "/v1/transit/datakey/plaintext/minio":
+			key, sealedKey, _ := transit.GenerateKey(string(context))
+			resp.Data.Plaintext = base64.StdEncoding.EncodeToString(key)
+			resp.Data.Ciphertext = "vault:v1:" + base64.StdEncoding.EncodeToString(sealedKey)
+		case "/v1/transit/encrypt/minio":
+			key, _ := base64.StdEncoding.DecodeString(req["plaintext"])
+			sealedKey, _ := transit.SealKey(key, string(context))
+			resp.Data.Ciphertext = "vault:v1:" + base64.StdEncoding.EncodeToString(sealedKey)
+		case "/v1/transit/decrypt/minio":
+			sealedKey, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(req["ciphertext"], "vault:v1:"))
+			key, err := transit.UnsealKey(sealedKey, string(context))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors":["cipher: message authentication failed"]}`))
+				return
+			}
+			resp.Data.Plaintext = base64.StdEncoding.EncodeToString(key)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	kms, err := newVaultKMS(server.URL, "token", "minio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testKMS(t, kms)
+
+	kms, err = newVaultKMS(server.URL, "other", "minio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = kms.GenerateKey("bucket/object"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("Expected permission denied, got %v", err)
+	}
+}
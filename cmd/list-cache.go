@@ -0,0 +1,267 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum number of cached listings, the oldest is evicted when full.
+const maxListCacheEntries = 256
+
+// ListCacheStats - listing cache counters.
+type ListCacheStats struct {
+	// Configured time to live, zero if disabled.
+	TTL time.Duration `json:"ttl"`
+	// Listings cached at present.
+	Entries int `json:"entries"`
+	// Listings served from and missing in the cache.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Listings dropped by writes.
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// listCacheKey - arguments of a cached listing.
+type listCacheKey struct {
+	bucket    string
+	prefix    string
+	marker    string
+	delimiter string
+	maxKeys   int
+}
+
+// listCacheEntry - result of a cached listing.
+type listCacheEntry struct {
+	result  ListObjectsInfo
+	created time.Time
+}
+
+// listCache - caches recent listings for ttl. Writes to an object drop
+// every listing of its bucket whose prefix matches the object, writes
+// done by other servers of a distributed setup are only seen once the
+// listings expire.
+type listCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[listCacheKey]*listCacheEntry
+	// Incremented by every write to a bucket, listings started before
+	// a write are not cached.
+	generations   map[string]uint64
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+// Global listing cache, nil unless enabled with MINIO_LIST_CACHE_TTL.
+var globalListCache *listCache
+
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{
+		ttl:         ttl,
+		entries:     make(map[listCacheKey]*listCacheEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// parseListCacheTTL - parses the value of MINIO_LIST_CACHE_TTL.
+func parseListCacheTTL(value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("Unknown value `%s` for MINIO_LIST_CACHE_TTL, expected a duration such as `10s` or `off`", value)
+	}
+	return ttl, nil
+}
+
+// loadListCacheTTL - loads the listing cache ttl from the environment.
+func loadListCacheTTL() (time.Duration, error) {
+	return parseListCacheTTL(os.Getenv("MINIO_LIST_CACHE_TTL"))
+}
+
+// copyListObjectsInfo - returns a copy of result not sharing its slices.
+func copyListObjectsInfo(result ListObjectsInfo) ListObjectsInfo {
+	result.Objects = append([]ObjectInfo(nil), result.Objects...)
+	result.Prefixes = append([]string(nil), result.Prefixes...)
+	return result
+}
+
+// get - returns the cached listing of key and the generation of its
+// bucket to pass to set on a miss.
+func (c *listCache) get(key listCacheKey) (result ListObjectsInfo, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && time.Since(entry.created) >= c.ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return ListObjectsInfo{}, c.generations[key.bucket], false
+	}
+	c.hits++
+	return copyListObjectsInfo(entry.result), 0, true
+}
+
+// set - caches the listing of key unless its bucket was written to
+// since generation.
+func (c *listCache) set(key listCacheKey, generation uint64, result ListObjectsInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[key.bucket] != generation {
+		return
+	}
+	if len(c.entries) >= maxListCacheEntries {
+		c.evict()
+	}
+	c.entries[key] = &listCacheEntry{
+		result:  copyListObjectsInfo(result),
+		created: time.Now(),
+	}
+}
+
+// evict - drops the expired listings, or the oldest one if none
+// expired, caller must hold the lock.
+func (c *listCache) evict() {
+	var oldestKey listCacheKey
+	var oldest *listCacheEntry
+	for key, entry := range c.entries {
+		if time.Since(entry.created) >= c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldest == nil || entry.created.Before(oldest.created) {
+			oldestKey, oldest = key, entry
+		}
+	}
+	if len(c.entries) >= maxListCacheEntries && oldest != nil {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// invalidate - drops the listings of bucket which may include object,
+// all listings of bucket if object is empty.
+func (c *listCache) invalidate(bucket, object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[bucket]++
+	for key := range c.entries {
+		if key.bucket == bucket && strings.HasPrefix(object, key.prefix) {
+			delete(c.entries, key)
+			c.invalidations++
+		}
+	}
+}
+
+// stats - returns the cache counters.
+func (c *listCache) stats() ListCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return ListCacheStats{
+		TTL:           c.ttl,
+		Entries:       len(c.entries),
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Invalidations: c.invalidations,
+	}
+}
+
+// listCacheObjects - object layer serving listings from a listCache,
+// all other calls go to the wrapped layer.
+type listCacheObjects struct {
+	ObjectLayer
+	cache *listCache
+}
+
+// newListCacheObjects - returns objAPI with its listings cached in cache.
+func newListCacheObjects(objAPI ObjectLayer, cache *listCache) ObjectLayer {
+	return listCacheObjects{ObjectLayer: objAPI, cache: cache}
+}
+
+// ListObjects - returns a cached listing, or lists and caches it.
+func (l listCacheObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	key := listCacheKey{bucket, prefix, marker, delimiter, maxKeys}
+	result, generation, ok := l.cache.get(key)
+	if ok {
+		return result, nil
+	}
+	result, err := l.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return result, err
+	}
+	l.cache.set(key, generation, result)
+	return result, nil
+}
+
+// DeleteBucket - deletes a bucket and drops its listings.
+func (l listCacheObjects) DeleteBucket(bucket string) error {
+	defer l.cache.invalidate(bucket, "")
+	return l.ObjectLayer.DeleteBucket(bucket)
+}
+
+// PutObject - writes an object and drops the listings including it.
+func (l listCacheObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	defer l.cache.invalidate(bucket, object)
+	return l.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+}
+
+// CopyObject - copies an object and drops the listings including the
+// copy.
+func (l listCacheObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	defer l.cache.invalidate(dstBucket, dstObject)
+	return l.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+// DeleteObject - deletes an object and drops the listings including it.
+func (l listCacheObjects) DeleteObject(bucket, object string) error {
+	defer l.cache.invalidate(bucket, object)
+	return l.ObjectLayer.DeleteObject(bucket, object)
+}
+
+// CompleteMultipartUpload - writes an object and drops the listings
+// including it.
+func (l listCacheObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	defer l.cache.invalidate(bucket, object)
+	return l.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
+
+// HealObject - heals an object and drops the listings including it.
+func (l listCacheObjects) HealObject(bucket, object string) error {
+	defer l.cache.invalidate(bucket, object)
+	return l.ObjectLayer.HealObject(bucket, object)
+}
+
+// QuarantineObject - quarantines an object and drops the listings
+// including it.
+func (l listCacheObjects) QuarantineObject(bucket, object string) error {
+	defer l.cache.invalidate(bucket, object)
+	return l.ObjectLayer.QuarantineObject(bucket, object)
+}
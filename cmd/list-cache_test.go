@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Tests parsing MINIO_LIST_CACHE_TTL.
+func TestParseListCacheTTL(t *testing.T) {
+	testCases := []struct {
+		value   string
+		ttl     time.Duration
+		success bool
+	}{
+		{"", 0, true},
+		{"off", 0, true},
+		{"10s", 10 * time.Second, true},
+		{"0s", 0, false},
+		{"often", 0, false},
+	}
+	for i, testCase := range testCases {
+		ttl, err := parseListCacheTTL(testCase.value)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if ttl != testCase.ttl {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.ttl, ttl)
+		}
+	}
+}
+
+// Wrapper for calling testListCacheObjects for both XL and FS.
+func TestListCacheObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testListCacheObjects)
+}
+
+// Tests that listings are cached until a write changes them.
+func testListCacheObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	cache := newListCache(time.Hour)
+	obj = newListCacheObjects(obj, cache)
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	putObject := func(object string) {
+		if _, err := obj.PutObject(bucket, object, 1, bytes.NewReader([]byte("a")), nil, ""); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	list := func(prefix string) int {
+		result, err := obj.ListObjects(bucket, prefix, "", "", 1000)
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		return len(result.Objects)
+	}
+	putObject("dir/a")
+	putObject("other/a")
+
+	testCases := []struct {
+		// Written before listing, if set.
+		object  string
+		deleted bool
+		prefix  string
+		count   int
+		hits    uint64
+	}{
+		{"", false, "dir/", 1, 0},
+		{"", false, "dir/", 1, 1},
+		// Writes elsewhere keep the listing.
+		{"other/b", false, "dir/", 1, 2},
+		{"dir/b", false, "dir/", 2, 2},
+		{"", false, "dir/", 2, 3},
+		{"", false, "", 4, 3},
+		{"dir/a", true, "dir/", 1, 3},
+		{"", false, "", 3, 3},
+	}
+	for i, testCase := range testCases {
+		switch {
+		case testCase.deleted:
+			if err := obj.DeleteObject(bucket, testCase.object); err != nil {
+				t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+			}
+		case testCase.object != "":
+			putObject(testCase.object)
+		}
+		if count := list(testCase.prefix); count != testCase.count {
+			t.Errorf("%s: Test %d: Expected %d objects, got %d", instanceType, i+1, testCase.count, count)
+		}
+		if hits := cache.stats().Hits; hits != testCase.hits {
+			t.Errorf("%s: Test %d: Expected %d hits, got %d", instanceType, i+1, testCase.hits, hits)
+		}
+	}
+}
+
+// Tests that listings started before a write are not cached and
+// expired listings are not served.
+func TestListCacheGenerations(t *testing.T) {
+	cache := newListCache(time.Hour)
+	key := listCacheKey{bucket: "bucket", maxKeys: 1000}
+	result := ListObjectsInfo{Objects: []ObjectInfo{{Name: "a"}}}
+
+	_, generation, _ := cache.get(key)
+	cache.invalidate("bucket", "b")
+	cache.set(key, generation, result)
+	if _, _, ok := cache.get(key); ok {
+		t.Fatal("Expected a listing started before a write not to be cached")
+	}
+
+	_, generation, _ = cache.get(key)
+	cache.set(key, generation, result)
+	cached, _, ok := cache.get(key)
+	if !ok || len(cached.Objects) != 1 {
+		t.Fatalf("Expected the listing to be cached, got %+v", cached)
+	}
+
+	cache.ttl = 0
+	if _, _, ok := cache.get(key); ok {
+		t.Fatal("Expected an expired listing not to be served")
+	}
+	if stats := cache.stats(); stats.Entries != 0 {
+		t.Errorf("Expected no entries, got %d", stats.Entries)
+	}
+}
@@ -20,18 +20,144 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
+	humanize "github.com/dustin/go-humanize"
 )
 
 type fileLogger struct {
 	Enable   bool   `json:"enable"`
 	Filename string `json:"fileName"`
 	Level    string `json:"level"`
+	// Optional rotation limits, e.g. "100MiB" and "24h".
+	MaxSize string `json:"maxSize,omitempty"`
+	MaxAge  string `json:"maxAge,omitempty"`
 }
 
+// Layout of the suffix appended to rotated log files.
+const rotatedLogTimeFormat = "2006-01-02T15-04-05.000"
+
+// localFile - log file which is rotated once it exceeds maxSize bytes
+// or is older than maxAge, zero disables the respective limit. All
+// writes and file switches happen under the mutex so no entries are
+// lost while rotating or reopening.
 type localFile struct {
-	*os.File
+	mu       sync.Mutex
+	filename string
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	maxSize  int64
+	maxAge   time.Duration
+}
+
+// newLocalFile - opens filename for appending with the given rotation
+// limits.
+func newLocalFile(filename string, maxSize int64, maxAge time.Duration) (*localFile, error) {
+	l := &localFile{
+		filename: filename,
+		maxSize:  maxSize,
+		maxAge:   maxAge,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open - opens the log file, must be called with the mutex held.
+func (l *localFile) open() error {
+	// Creates the named file with mode 0666, honors system umask.
+	file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	l.size = fi.Size()
+	l.openedAt = time.Now().UTC()
+	return nil
+}
+
+// reopen - reopens the log file by name, used after the file was
+// moved away by an external tool such as logrotate.
+func (l *localFile) reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.open()
+}
+
+// rotate - renames the current log file with a timestamp suffix and
+// opens a new one, must be called with the mutex held.
+func (l *localFile) rotate(now time.Time) error {
+	rotated := l.filename + "." + now.Format(rotatedLogTimeFormat)
+	if err := os.Rename(l.filename, rotated); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// needsRotation - returns true if writing n more bytes exceeds the
+// configured limits, must be called with the mutex held.
+func (l *localFile) needsRotation(n int, now time.Time) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.maxSize > 0 && l.size+int64(n) > l.maxSize {
+		return true
+	}
+	return l.maxAge > 0 && now.Sub(l.openedAt) >= l.maxAge
+}
+
+// Write - appends p to the log file, rotating it first if needed.
+func (l *localFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now().UTC()
+	if l.needsRotation(len(p), now) {
+		if err := l.rotate(now); err != nil {
+			// Keep logging to the current file.
+			fmt.Fprintf(os.Stderr, "Unable to rotate log file %s, %v\n", l.filename, err)
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, l.file.Sync()
+}
+
+// parseFileLoggerLimits - parses the optional rotation limits of the
+// file logger.
+func parseFileLoggerLimits(flogger fileLogger) (maxSize int64, maxAge time.Duration, err error) {
+	if flogger.MaxSize != "" {
+		var size uint64
+		if size, err = humanize.ParseBytes(flogger.MaxSize); err != nil {
+			return 0, 0, fmt.Errorf("Invalid maxSize %q, %v", flogger.MaxSize, err)
+		}
+		maxSize = int64(size)
+	}
+	if flogger.MaxAge != "" {
+		if maxAge, err = time.ParseDuration(flogger.MaxAge); err != nil {
+			return 0, 0, fmt.Errorf("Invalid maxAge %q, %v", flogger.MaxAge, err)
+		}
+		if maxAge < 0 {
+			return 0, 0, fmt.Errorf("Invalid maxAge %q, must not be negative", flogger.MaxAge)
+		}
+	}
+	return maxSize, maxAge, nil
 }
 
 func enableFileLogger() {
@@ -40,14 +166,19 @@ func enableFileLogger() {
 		return
 	}
 
-	// Creates the named file with mode 0666, honors system umask.
-	file, err := os.OpenFile(flogger.Filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	maxSize, maxAge, err := parseFileLoggerLimits(flogger)
+	fatalIf(err, "Invalid log rotation settings found in the config file.")
+
+	file, err := newLocalFile(flogger.Filename, maxSize, maxAge)
 	fatalIf(err, "Unable to open log file.")
 
+	// Reopen the file when signalled, for logrotate.
+	go reopenLogFileOnSignal(file)
+
 	fileLogger := logrus.New()
 
 	// Add a local file hook.
-	fileLogger.Hooks.Add(&localFile{file})
+	fileLogger.Hooks.Add(file)
 
 	lvl, err := logrus.ParseLevel(flogger.Level)
 	fatalIf(err, "Unknown log level found in the config file.")
@@ -68,8 +199,7 @@ func (l *localFile) Fire(entry *logrus.Entry) error {
 	if err != nil {
 		return fmt.Errorf("Unable to read entry, %v", err)
 	}
-	l.File.Write([]byte(line))
-	l.File.Sync()
+	l.Write([]byte(line))
 	return nil
 }
 
@@ -0,0 +1,125 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests parsing of the file logger rotation limits.
+func TestParseFileLoggerLimits(t *testing.T) {
+	testCases := []struct {
+		maxSize    string
+		maxAge     string
+		size       int64
+		age        time.Duration
+		shouldPass bool
+	}{
+		{"", "", 0, 0, true},
+		{"100MiB", "24h", 100 * 1024 * 1024, 24 * time.Hour, true},
+		{"1KB", "", 1000, 0, true},
+		{"lots", "", 0, 0, false},
+		{"", "daily", 0, 0, false},
+		{"", "-1h", 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		size, age, err := parseFileLoggerLimits(fileLogger{MaxSize: testCase.maxSize, MaxAge: testCase.maxAge})
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if size != testCase.size || age != testCase.age {
+			t.Errorf("Test %d: Expected %d, %s, got %d, %s", i+1, testCase.size, testCase.age, size, age)
+		}
+	}
+}
+
+// Tests size based rotation and reopening of log files.
+func TestLocalFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minio-log-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(dir)
+
+	filename := filepath.Join(dir, "minio.log")
+	l, err := newLocalFile(filename, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.file.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err = l.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rotated, err := filepath.Glob(filename + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 {
+		t.Fatalf("Expected 1 rotated file, found %v", rotated)
+	}
+	if data, _ := ioutil.ReadFile(rotated[0]); string(data) != "first\n" {
+		t.Errorf("Unexpected rotated content %q", data)
+	}
+	if data, _ := ioutil.ReadFile(filename); string(data) != "second\n" {
+		t.Errorf("Unexpected current content %q", data)
+	}
+
+	// Simulate logrotate moving the file away.
+	moved := filename + ".moved"
+	if err = os.Rename(filename, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(moved); string(data) != "second\n" {
+		t.Errorf("Unexpected moved content %q", data)
+	}
+	if data, _ := ioutil.ReadFile(filename); string(data) != "third\n" {
+		t.Errorf("Unexpected reopened content %q", data)
+	}
+}
+
+// Tests age based rotation.
+func TestLocalFileNeedsRotation(t *testing.T) {
+	now := time.Now().UTC()
+	l := &localFile{size: 5, maxAge: time.Hour, openedAt: now}
+	if l.needsRotation(1, now.Add(time.Minute)) {
+		t.Errorf("Expected no rotation before maxAge")
+	}
+	if !l.needsRotation(1, now.Add(time.Hour)) {
+		t.Errorf("Expected rotation after maxAge")
+	}
+	// Empty files are never rotated.
+	l.size = 0
+	if l.needsRotation(1, now.Add(time.Hour)) {
+		t.Errorf("Expected no rotation of an empty file")
+	}
+}
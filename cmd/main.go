@@ -34,13 +34,15 @@ var (
 			Usage: "Show help.",
 		},
 		cli.StringFlag{
-			Name:  "config-dir, C",
-			Value: mustGetConfigPath(),
-			Usage: "Path to configuration directory.",
+			Name:   "config-dir, C",
+			Value:  mustGetConfigPath(),
+			Usage:  "Path to configuration directory.",
+			EnvVar: "MINIO_CONFIG_DIR",
 		},
 		cli.BoolFlag{
-			Name:  "quiet",
-			Usage: "Disable startup information.",
+			Name:   "quiet",
+			Usage:  "Disable startup information.",
+			EnvVar: "MINIO_QUIET",
 		},
 	}
 )
@@ -112,6 +114,8 @@ func registerApp() *cli.App {
 	registerCommand(serverCmd)
 	registerCommand(versionCmd)
 	registerCommand(updateCmd)
+	registerCommand(compatCmd)
+	registerCommand(gatewayCmd)
 
 	// Set up app.
 	app := cli.NewApp()
@@ -183,21 +187,20 @@ func minioInit() {
 	// Enable all loggers by now so we can use errorIf() and fatalIf()
 	enableLoggers()
 
-	// Fetch access keys from environment variables and update the config.
-	accessKey := os.Getenv("MINIO_ACCESS_KEY")
-	secretKey := os.Getenv("MINIO_SECRET_KEY")
-	if accessKey != "" && secretKey != "" {
+	// Fetch access keys from environment variables or secret files
+	// and update the config.
+	cred, ok, err := loadEnvCredential()
+	fatalIf(err, "Unable to load credentials from the environment.")
+	if ok {
 		// Set new credentials.
-		serverConfig.SetCredential(credential{
-			AccessKeyID:     accessKey,
-			SecretAccessKey: secretKey,
-		})
+		serverConfig.SetCredential(cred)
+		globalIsEnvCreds = true
 	}
-	if !isValidAccessKey(serverConfig.GetCredential().AccessKeyID) {
-		fatalIf(errInvalidArgument, "Invalid access key. Accept only a string starting with a alphabetic and containing from 5 to 20 characters.")
-	}
-	if !isValidSecretKey(serverConfig.GetCredential().SecretAccessKey) {
-		fatalIf(errInvalidArgument, "Invalid secret key. Accept only a string containing from 8 to 40 characters.")
+	fatalIf(validateCredential(serverConfig.GetCredential()), "Invalid credentials.")
+
+	// Region from the environment overrides the config.
+	if region := os.Getenv("MINIO_REGION"); region != "" {
+		serverConfig.SetRegion(region)
 	}
 
 	// Init the error tracing module.
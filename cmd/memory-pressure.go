@@ -0,0 +1,175 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/minio/pkg/sys"
+)
+
+const (
+	// Memory usage is compared against the limit this often.
+	memoryPressureInterval = time.Second
+
+	// Writes are accepted again once memory usage drops below this
+	// percentage of the limit.
+	memoryPressureResumePercent = 90
+
+	// Seconds clients are asked to wait before retrying shed requests.
+	slowDownRetryAfter = 1
+)
+
+// MemoryPressureStats - memory usage of the server against its limit.
+type MemoryPressureStats struct {
+	// Configured limit in bytes, zero if disabled.
+	Limit uint64 `json:"limit"`
+	// Memory obtained from the system at the last sample.
+	Used uint64 `json:"used"`
+	// Whether writes are rejected at present.
+	Active bool `json:"active"`
+	// Writes rejected with SlowDown.
+	Shed uint64 `json:"shed"`
+}
+
+// memoryPressure - rejects writes while the memory of the process is
+// above its limit, before it is killed by the kernel.
+type memoryPressure struct {
+	// Updated atomically, kept first for 64-bit alignment.
+	used   uint64
+	shed   uint64
+	active int32
+
+	// Limit in bytes, zero if disabled.
+	limit uint64
+}
+
+// Memory limit of the server, set with MINIO_MEMORY_LIMIT.
+var globalMemoryPressure = &memoryPressure{}
+
+// parseMemoryLimit - parses the value of MINIO_MEMORY_LIMIT, a size
+// such as "4GiB" or a percentage of totalRAM such as "80%".
+func parseMemoryLimit(value string, totalRAM uint64) (uint64, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent <= 0 || percent > 100 {
+			return 0, fmt.Errorf("Unknown value `%s`, expected a percentage between 1 and 100", value)
+		}
+		if totalRAM == 0 {
+			return 0, fmt.Errorf("Unable to find the system memory, expected a size such as `4GiB`")
+		}
+		return totalRAM * uint64(percent) / 100, nil
+	}
+	limit, err := humanize.ParseBytes(value)
+	if err != nil || limit == 0 {
+		return 0, fmt.Errorf("Unknown value `%s`, expected a size such as `4GiB`, a percentage such as `80%%` or `off`", value)
+	}
+	return limit, nil
+}
+
+// loadMemoryLimit - loads the memory limit from the environment.
+func loadMemoryLimit() (uint64, error) {
+	var totalRAM uint64
+	if stats, err := sys.GetStats(); err == nil {
+		totalRAM = stats.TotalRAM
+	}
+	return parseMemoryLimit(os.Getenv("MINIO_MEMORY_LIMIT"), totalRAM)
+}
+
+// isActive - returns true if writes are rejected at present.
+func (m *memoryPressure) isActive() bool {
+	return atomic.LoadInt32(&m.active) == 1
+}
+
+// update - records a memory usage sample, writes are rejected from
+// the limit on until usage drops below memoryPressureResumePercent of it.
+func (m *memoryPressure) update(used uint64) {
+	atomic.StoreUint64(&m.used, used)
+	if m.limit == 0 {
+		return
+	}
+	switch {
+	case used >= m.limit:
+		atomic.StoreInt32(&m.active, 1)
+	case used < m.limit/100*memoryPressureResumePercent:
+		atomic.StoreInt32(&m.active, 0)
+	}
+}
+
+// stats - returns the memory usage and the shed writes.
+func (m *memoryPressure) stats() MemoryPressureStats {
+	return MemoryPressureStats{
+		Limit:  m.limit,
+		Used:   atomic.LoadUint64(&m.used),
+		Active: m.isActive(),
+		Shed:   atomic.LoadUint64(&m.shed),
+	}
+}
+
+// processMemoryUsage - returns the memory obtained from the system
+// by the process and not returned to it yet.
+func processMemoryUsage() uint64 {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.Sys - memStats.HeapReleased
+}
+
+// startMemoryPressureMonitor - samples the memory usage every
+// memoryPressureInterval if enabled with MINIO_MEMORY_LIMIT.
+func startMemoryPressureMonitor() {
+	if globalMemoryPressure.limit == 0 {
+		return
+	}
+	go func() {
+		for {
+			globalMemoryPressure.update(processMemoryUsage())
+			time.Sleep(memoryPressureInterval)
+		}
+	}()
+}
+
+type memoryPressureHandler struct {
+	handler http.Handler
+}
+
+// setMemoryPressureHandler - rejects all writes but deletes with
+// SlowDown while memory usage is above the limit.
+func setMemoryPressureHandler(h http.Handler) http.Handler {
+	return memoryPressureHandler{h}
+}
+
+func (h memoryPressureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if globalMemoryPressure.isActive() && isWriteRequest(r) {
+		atomic.AddUint64(&globalMemoryPressure.shed, 1)
+		w.Header().Set("Retry-After", strconv.Itoa(slowDownRetryAfter))
+		writeErrorResponse(w, r, ErrSlowDown, r.URL.Path)
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
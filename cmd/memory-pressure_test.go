@@ -0,0 +1,97 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests parsing MINIO_MEMORY_LIMIT.
+func TestParseMemoryLimit(t *testing.T) {
+	testCases := []struct {
+		value    string
+		totalRAM uint64
+		limit    uint64
+		success  bool
+	}{
+		{"", 8 << 30, 0, true},
+		{"off", 8 << 30, 0, true},
+		{"4GiB", 8 << 30, 4 << 30, true},
+		{"512MiB", 0, 512 << 20, true},
+		{"50%", 8 << 30, 4 << 30, true},
+		{"50%", 0, 0, false},
+		{"0%", 8 << 30, 0, false},
+		{"101%", 8 << 30, 0, false},
+		{"0", 8 << 30, 0, false},
+		{"lots", 8 << 30, 0, false},
+	}
+	for i, testCase := range testCases {
+		limit, err := parseMemoryLimit(testCase.value, testCase.totalRAM)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if limit != testCase.limit {
+			t.Errorf("Test %d: Expected %d, got %d", i+1, testCase.limit, limit)
+		}
+	}
+}
+
+// Tests that writes are shed above the memory limit until usage drops
+// below the resume threshold.
+func TestMemoryPressureHandler(t *testing.T) {
+	defer func(m *memoryPressure) { globalMemoryPressure = m }(globalMemoryPressure)
+	globalMemoryPressure = &memoryPressure{limit: 1000}
+
+	handler := setMemoryPressureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testCases := []struct {
+		used           uint64
+		method         string
+		expectedStatus int
+	}{
+		{500, "PUT", http.StatusOK},
+		{1000, "PUT", http.StatusServiceUnavailable},
+		{1000, "GET", http.StatusOK},
+		{1000, "DELETE", http.StatusOK},
+		// Still above the resume threshold.
+		{950, "PUT", http.StatusServiceUnavailable},
+		{899, "PUT", http.StatusOK},
+		// Below the limit after resuming.
+		{950, "PUT", http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		globalMemoryPressure.update(testCase.used)
+		req, err := http.NewRequest(testCase.method, "http://localhost:9000/bucket/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, rec.Code)
+		}
+		if rec.Code == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("Test %d: Expected a Retry-After header", i+1)
+		}
+	}
+	if stats := globalMemoryPressure.stats(); stats.Shed != 2 || stats.Used != 950 || stats.Active {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stale multipart uploads are looked for this often.
+const multipartReaperInterval = time.Hour
+
+// IncompleteUpload - a multipart upload in progress.
+type IncompleteUpload struct {
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	UploadID  string    `json:"uploadId"`
+	Initiated time.Time `json:"initiated"`
+	// Time of the latest part upload, Initiated if there are none.
+	LastModified time.Time `json:"lastModified"`
+	Parts        int       `json:"parts"`
+	Size         int64     `json:"size"`
+}
+
+// IncompleteUploadsInfo - multipart uploads in progress and the
+// storage used by their parts.
+type IncompleteUploadsInfo struct {
+	Uploads   []IncompleteUpload `json:"uploads"`
+	TotalSize int64              `json:"totalSize"`
+}
+
+// listIncompleteUploads - lists the multipart uploads in progress under
+// prefix, in all buckets if bucket is empty.
+func listIncompleteUploads(objAPI ObjectLayer, bucket, prefix string) (IncompleteUploadsInfo, error) {
+	buckets := []string{bucket}
+	if bucket == "" {
+		bucketsInfo, err := objAPI.ListBuckets()
+		if err != nil {
+			return IncompleteUploadsInfo{}, err
+		}
+		buckets = nil
+		for _, bucketInfo := range bucketsInfo {
+			buckets = append(buckets, bucketInfo.Name)
+		}
+	}
+
+	info := IncompleteUploadsInfo{Uploads: []IncompleteUpload{}}
+	for _, bucket := range buckets {
+		keyMarker, uploadIDMarker := "", ""
+		for {
+			lmi, err := objAPI.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, "", maxUploadsList)
+			if err != nil {
+				return IncompleteUploadsInfo{}, err
+			}
+			for _, upload := range lmi.Uploads {
+				incomplete, err := getIncompleteUpload(objAPI, bucket, upload)
+				if err != nil {
+					if _, ok := errorCause(err).(InvalidUploadID); ok {
+						// Completed or aborted since listed.
+						continue
+					}
+					return IncompleteUploadsInfo{}, err
+				}
+				info.Uploads = append(info.Uploads, incomplete)
+				info.TotalSize += incomplete.Size
+			}
+			if !lmi.IsTruncated {
+				break
+			}
+			keyMarker, uploadIDMarker = lmi.NextKeyMarker, lmi.NextUploadIDMarker
+		}
+	}
+	return info, nil
+}
+
+// getIncompleteUpload - sums up the parts of a multipart upload.
+func getIncompleteUpload(objAPI ObjectLayer, bucket string, upload uploadMetadata) (IncompleteUpload, error) {
+	incomplete := IncompleteUpload{
+		Bucket:       bucket,
+		Object:       upload.Object,
+		UploadID:     upload.UploadID,
+		Initiated:    upload.Initiated,
+		LastModified: upload.Initiated,
+	}
+	partNumberMarker := 0
+	for {
+		lpi, err := objAPI.ListObjectParts(bucket, upload.Object, upload.UploadID, partNumberMarker, maxPartsList)
+		if err != nil {
+			return IncompleteUpload{}, err
+		}
+		for _, part := range lpi.Parts {
+			incomplete.Parts++
+			incomplete.Size += part.Size
+			if part.LastModified.After(incomplete.LastModified) {
+				incomplete.LastModified = part.LastModified
+			}
+		}
+		if !lpi.IsTruncated {
+			break
+		}
+		partNumberMarker = lpi.NextPartNumberMarker
+	}
+	return incomplete, nil
+}
+
+// reapStaleUploads - aborts the multipart uploads without activity for
+// longer than expiry, returns the number of uploads aborted. Uploads are
+// kept while the server is read-only.
+func reapStaleUploads(objAPI ObjectLayer, expiry time.Duration, now time.Time) (int, error) {
+	if globalIsReadOnly {
+		return 0, nil
+	}
+	info, err := listIncompleteUploads(objAPI, "", "")
+	if err != nil {
+		return 0, err
+	}
+	aborted := 0
+	for _, upload := range info.Uploads {
+		if now.Sub(upload.LastModified) < expiry {
+			continue
+		}
+		err = objAPI.AbortMultipartUpload(upload.Bucket, upload.Object, upload.UploadID)
+		if err != nil {
+			if _, ok := errorCause(err).(InvalidUploadID); ok {
+				continue
+			}
+			return aborted, err
+		}
+		aborted++
+	}
+	return aborted, nil
+}
+
+// parseMultipartExpiryEnv - parses the value of MINIO_MULTIPART_EXPIRY,
+// a duration such as "168h", zero if the reaper is disabled.
+func parseMultipartExpiryEnv(value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	expiry, err := time.ParseDuration(value)
+	if err != nil || expiry <= 0 {
+		return 0, fmt.Errorf("Unknown value `%s`, expected a positive duration such as `168h` or `off`", value)
+	}
+	return expiry, nil
+}
+
+// startMultipartReaper - aborts stale multipart uploads every
+// multipartReaperInterval if enabled with MINIO_MULTIPART_EXPIRY.
+func startMultipartReaper(objAPI ObjectLayer) {
+	if globalMultipartExpiry == 0 {
+		return
+	}
+	go func() {
+		for {
+			_, err := reapStaleUploads(objAPI, globalMultipartExpiry, time.Now().UTC())
+			errorIf(err, "Unable to abort stale multipart uploads.")
+			time.Sleep(multipartReaperInterval)
+		}
+	}()
+}
@@ -0,0 +1,121 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Tests listing and reaping incomplete multipart uploads.
+func TestMultipartReaper(t *testing.T) {
+	ExecObjectLayerTest(t, testMultipartReaper)
+}
+
+func testMultipartReaper(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	buckets := []string{"reaper-bucket1", "reaper-bucket2"}
+	for _, bucket := range buckets {
+		if err := obj.MakeBucket(bucket); err != nil {
+			t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+		}
+	}
+	staleID, err := obj.NewMultipartUpload(buckets[0], "stale", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start upload: %v", instanceType, err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	for partID := 1; partID <= 2; partID++ {
+		if _, err = obj.PutObjectPart(buckets[0], "stale", staleID, partID, int64(len(data)), bytes.NewReader(data), "", ""); err != nil {
+			t.Fatalf("%s: Unable to upload part: %v", instanceType, err)
+		}
+	}
+	if _, err = obj.NewMultipartUpload(buckets[1], "dir/empty", nil); err != nil {
+		t.Fatalf("%s: Unable to start upload: %v", instanceType, err)
+	}
+
+	info, err := listIncompleteUploads(obj, "", "")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(info.Uploads) != 2 || info.TotalSize != int64(2*len(data)) {
+		t.Fatalf("%s: Unexpected uploads %+v", instanceType, info)
+	}
+	stale := info.Uploads[0]
+	if stale.Bucket != buckets[0] || stale.UploadID != staleID || stale.Parts != 2 || stale.Size != int64(2*len(data)) {
+		t.Errorf("%s: Unexpected upload %+v", instanceType, stale)
+	}
+	if stale.LastModified.Before(stale.Initiated) {
+		t.Errorf("%s: Expected last activity after initiation, got %+v", instanceType, stale)
+	}
+
+	info, err = listIncompleteUploads(obj, buckets[1], "dir/")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(info.Uploads) != 1 || info.Uploads[0].Object != "dir/empty" || info.Uploads[0].Parts != 0 {
+		t.Errorf("%s: Unexpected uploads %+v", instanceType, info)
+	}
+	if _, err = listIncompleteUploads(obj, "reaper-missing", ""); err == nil {
+		t.Errorf("%s: Expected BucketNotFound, got %v", instanceType, err)
+	} else if _, ok := errorCause(err).(BucketNotFound); !ok {
+		t.Errorf("%s: Expected BucketNotFound, got %v", instanceType, err)
+	}
+
+	// Nothing is idle long enough yet.
+	aborted, err := reapStaleUploads(obj, time.Hour, time.Now().UTC())
+	if err != nil || aborted != 0 {
+		t.Fatalf("%s: Expected no uploads aborted, got %d, %v", instanceType, aborted, err)
+	}
+	aborted, err = reapStaleUploads(obj, time.Hour, time.Now().UTC().Add(2*time.Hour))
+	if err != nil || aborted != 2 {
+		t.Fatalf("%s: Expected 2 uploads aborted, got %d, %v", instanceType, aborted, err)
+	}
+	info, err = listIncompleteUploads(obj, "", "")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(info.Uploads) != 0 || info.TotalSize != 0 {
+		t.Errorf("%s: Expected no uploads left, got %+v", instanceType, info)
+	}
+}
+
+// Tests parsing MINIO_MULTIPART_EXPIRY.
+func TestParseMultipartExpiryEnv(t *testing.T) {
+	testCases := []struct {
+		value   string
+		expiry  time.Duration
+		success bool
+	}{
+		{"", 0, true},
+		{"off", 0, true},
+		{"168h", 168 * time.Hour, true},
+		{"30m", 30 * time.Minute, true},
+		{"-1h", 0, false},
+		{"0s", 0, false},
+		{"week", 0, false},
+	}
+	for i, testCase := range testCases {
+		expiry, err := parseMultipartExpiryEnv(testCase.value)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if expiry != testCase.expiry {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expiry, expiry)
+		}
+	}
+}
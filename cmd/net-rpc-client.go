@@ -78,6 +78,13 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 	var err error
 	var conn net.Conn
 
+	// Connecting and the response to CONNECT are bounded by the
+	// remote read header timeout, the connection is kept open.
+	dialer := &net.Dialer{Timeout: defaultRemoteDialTimeout}
+	if globalRemoteTimeouts.readHeader > 0 {
+		dialer.Timeout = globalRemoteTimeouts.readHeader
+	}
+
 	if rpcClient.secureConn {
 		hostname, _, splitErr := net.SplitHostPort(rpcClient.node)
 		if splitErr != nil {
@@ -90,10 +97,9 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 			}
 		}
 		// ServerName in tls.Config needs to be specified to support SNI certificates
-		conn, err = tls.Dial("tcp", rpcClient.node, &tls.Config{ServerName: hostname, RootCAs: globalRootCAs})
+		conn, err = tls.DialWithDialer(dialer, "tcp", rpcClient.node, &tls.Config{ServerName: hostname, RootCAs: globalRootCAs})
 	} else {
-		// Have a dial timeout with 3 secs.
-		conn, err = net.DialTimeout("tcp", rpcClient.node, 3*time.Second)
+		conn, err = dialer.Dial("tcp", rpcClient.node)
 	}
 	if err != nil {
 		// Print RPC connection errors that are worthy to display in log
@@ -108,10 +114,14 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 			Err:  err,
 		}
 	}
+	if globalRemoteTimeouts.readHeader > 0 {
+		conn.SetDeadline(time.Now().Add(globalRemoteTimeouts.readHeader))
+	}
 	io.WriteString(conn, "CONNECT "+rpcClient.rpcPath+" HTTP/1.0\n\n")
 
 	// Require successful HTTP response before switching to RPC protocol.
 	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	conn.SetDeadline(time.Time{})
 	if err == nil && resp.Status == "200 Connected to Go RPC" {
 		rpc := rpc.NewClient(conn)
 		if rpc == nil {
@@ -0,0 +1,230 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Bandwidth limited transfers are split into chunks of at most this
+// fraction of a second.
+const simulatedChunksPerSec = 10
+
+// networkSimulation - delays and bandwidth cap applied to every client
+// request, to test client retries and timeouts against a slow server.
+// Set with _MINIO_SIMULATE_LATENCY, _MINIO_SIMULATE_JITTER and
+// _MINIO_SIMULATE_BANDWIDTH, meant for testing only.
+type networkSimulation struct {
+	// Delay before a request is served.
+	latency time.Duration
+	// Random delay up to this duration added to latency.
+	jitter time.Duration
+	// Bytes per second read from a request and written to its
+	// response, zero if unlimited.
+	bandwidth int64
+}
+
+// Network conditions simulated for testing, disabled by default.
+var globalNetworkSimulation networkSimulation
+
+// parseSimulatedDuration - parses a simulated delay, empty or "off" for none.
+func parseSimulatedDuration(name, value string) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("Unknown value `%s` for %s, expected a duration such as `100ms` or `off`", value, name)
+	}
+	return duration, nil
+}
+
+// parseNetworkSimulation - parses the values of _MINIO_SIMULATE_LATENCY,
+// _MINIO_SIMULATE_JITTER and _MINIO_SIMULATE_BANDWIDTH.
+func parseNetworkSimulation(latency, jitter, bandwidth string) (simulation networkSimulation, err error) {
+	if simulation.latency, err = parseSimulatedDuration("_MINIO_SIMULATE_LATENCY", latency); err != nil {
+		return networkSimulation{}, err
+	}
+	if simulation.jitter, err = parseSimulatedDuration("_MINIO_SIMULATE_JITTER", jitter); err != nil {
+		return networkSimulation{}, err
+	}
+	switch strings.ToLower(bandwidth) {
+	case "", "off":
+	default:
+		rate, err := humanize.ParseBytes(bandwidth)
+		if err != nil || rate == 0 {
+			return networkSimulation{}, fmt.Errorf("Unknown value `%s` for _MINIO_SIMULATE_BANDWIDTH, expected bytes per second such as `1MiB` or `off`", bandwidth)
+		}
+		simulation.bandwidth = int64(rate)
+	}
+	return simulation, nil
+}
+
+// loadNetworkSimulation - loads the simulated network conditions from
+// the environment.
+func loadNetworkSimulation() (networkSimulation, error) {
+	return parseNetworkSimulation(os.Getenv("_MINIO_SIMULATE_LATENCY"), os.Getenv("_MINIO_SIMULATE_JITTER"),
+		os.Getenv("_MINIO_SIMULATE_BANDWIDTH"))
+}
+
+// isEnabled - returns true if any condition is simulated.
+func (s networkSimulation) isEnabled() bool {
+	return s.latency > 0 || s.jitter > 0 || s.bandwidth > 0
+}
+
+// delay - returns the latency with a random jitter added.
+func (s networkSimulation) delay() time.Duration {
+	if s.jitter <= 0 {
+		return s.latency
+	}
+	return s.latency + time.Duration(rand.Int63n(int64(s.jitter)+1))
+}
+
+// bandwidthLimiter - paces a transfer to a number of bytes per second.
+type bandwidthLimiter struct {
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+func newBandwidthLimiter(rate int64) *bandwidthLimiter {
+	return &bandwidthLimiter{rate: rate}
+}
+
+// chunkSize - returns the largest transfer to pace at once.
+func (l *bandwidthLimiter) chunkSize() int {
+	if size := l.rate / simulatedChunksPerSec; size > 0 {
+		return int(size)
+	}
+	return 1
+}
+
+// wait - sleeps until n more bytes are allowed since the first
+// transfer.
+func (l *bandwidthLimiter) wait(n int) {
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	l.n += int64(n)
+	due := time.Duration(l.n * int64(time.Second) / l.rate)
+	if elapsed := time.Since(l.start); due > elapsed {
+		time.Sleep(due - elapsed)
+	}
+}
+
+// simulatedReader - reads the request body at the simulated bandwidth.
+type simulatedReader struct {
+	io.ReadCloser
+	limiter *bandwidthLimiter
+}
+
+func (r *simulatedReader) Read(p []byte) (int, error) {
+	if size := r.limiter.chunkSize(); len(p) > size {
+		p = p[:size]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.limiter.wait(n)
+	return n, err
+}
+
+// simulatedWriter - writes at the simulated bandwidth.
+type simulatedWriter struct {
+	io.Writer
+	limiter *bandwidthLimiter
+}
+
+func (w *simulatedWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if size := w.limiter.chunkSize(); len(chunk) > size {
+			chunk = chunk[:size]
+		}
+		w.limiter.wait(len(chunk))
+		n, err := w.Writer.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// simulatedResponseWriter - writes the response at the simulated
+// bandwidth.
+type simulatedResponseWriter struct {
+	http.ResponseWriter
+	limiter *bandwidthLimiter
+}
+
+func (w *simulatedResponseWriter) Write(p []byte) (int, error) {
+	return (&simulatedWriter{Writer: w.ResponseWriter, limiter: w.limiter}).Write(p)
+}
+
+// Flush - handlers streaming responses expect an http.Flusher.
+func (w *simulatedResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify - handlers waiting on clients expect an http.CloseNotifier.
+func (w *simulatedResponseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+type networkSimulationHandler struct {
+	handler http.Handler
+}
+
+// setNetworkSimulationHandler - delays client requests and caps their
+// bandwidth if enabled for testing. Requests between servers are not
+// slowed down.
+func setNetworkSimulationHandler(h http.Handler) http.Handler {
+	return networkSimulationHandler{h}
+}
+
+func (h networkSimulationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := globalNetworkSimulation
+	if !s.isEnabled() || getRequestAPIName(r) == "RPC" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if delay := s.delay(); delay > 0 {
+		time.Sleep(delay)
+	}
+	if s.bandwidth > 0 {
+		if r.Body != nil {
+			r.Body = &simulatedReader{ReadCloser: r.Body, limiter: newBandwidthLimiter(s.bandwidth)}
+		}
+		w = &simulatedResponseWriter{ResponseWriter: w, limiter: newBandwidthLimiter(s.bandwidth)}
+	}
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,99 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests parsing the simulated network conditions.
+func TestParseNetworkSimulation(t *testing.T) {
+	testCases := []struct {
+		latency    string
+		jitter     string
+		bandwidth  string
+		simulation networkSimulation
+		success    bool
+	}{
+		{"", "", "", networkSimulation{}, true},
+		{"off", "off", "off", networkSimulation{}, true},
+		{"100ms", "50ms", "1MiB", networkSimulation{100 * time.Millisecond, 50 * time.Millisecond, 1 << 20}, true},
+		{"-1s", "", "", networkSimulation{}, false},
+		{"", "slow", "", networkSimulation{}, false},
+		{"", "", "0", networkSimulation{}, false},
+		{"", "", "fast", networkSimulation{}, false},
+	}
+	for i, testCase := range testCases {
+		simulation, err := parseNetworkSimulation(testCase.latency, testCase.jitter, testCase.bandwidth)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if simulation != testCase.simulation {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.simulation, simulation)
+		}
+	}
+}
+
+// Tests that requests are delayed and paced, and requests between
+// servers are not.
+func TestNetworkSimulationHandler(t *testing.T) {
+	defer func(s networkSimulation) { globalNetworkSimulation = s }(globalNetworkSimulation)
+
+	handler := setNetworkSimulationHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}))
+	testCases := []struct {
+		simulation networkSimulation
+		path       string
+		size       int
+		minimum    time.Duration
+		maximum    time.Duration
+	}{
+		{networkSimulation{}, "/bucket/object", 1000, 0, 100 * time.Millisecond},
+		{networkSimulation{latency: 200 * time.Millisecond}, "/bucket/object", 1000, 200 * time.Millisecond, time.Second},
+		{networkSimulation{latency: 200 * time.Millisecond}, reservedBucket + s3Path, 1000, 0, 100 * time.Millisecond},
+		// 200 bytes each way at 1000 bytes per second.
+		{networkSimulation{bandwidth: 1000}, "/bucket/object", 200, 300 * time.Millisecond, 2 * time.Second},
+	}
+	for i, testCase := range testCases {
+		globalNetworkSimulation = testCase.simulation
+		data := bytes.Repeat([]byte("a"), testCase.size)
+		req, err := http.NewRequest("PUT", "http://localhost:9000"+testCase.path, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		handler.ServeHTTP(rec, req)
+		elapsed := time.Since(start)
+		if elapsed < testCase.minimum || elapsed > testCase.maximum {
+			t.Errorf("Test %d: Expected between %s and %s, took %s", i+1, testCase.minimum, testCase.maximum, elapsed)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), data) {
+			t.Errorf("Test %d: Unexpected response of %d bytes", i+1, rec.Body.Len())
+		}
+	}
+}
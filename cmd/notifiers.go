@@ -38,6 +38,8 @@ const (
 	queueTypeRedis = "redis"
 	// Static string indicating queue type 'postgresql'.
 	queueTypePostgreSQL = "postgresql"
+	// Static string indicating queue type 'webhook'.
+	queueTypeWebhook = "webhook"
 )
 
 // Topic type.
@@ -58,6 +60,7 @@ type notifier struct {
 	ElasticSearch map[string]elasticSearchNotify `json:"elasticsearch"`
 	Redis         map[string]redisNotify         `json:"redis"`
 	PostgreSQL    map[string]postgreSQLNotify    `json:"postgresql"`
+	Webhook       map[string]webhookNotify       `json:"webhook"`
 	// Add new notification queues.
 }
 
@@ -177,3 +180,19 @@ func filterRuleMatch(object string, frs []filterRule) bool {
 	}
 	return prefixMatch && suffixMatch
 }
+
+// Returns true if queueArn is for a webhook.
+func isWebhookQueue(sqsArn arnSQS) bool {
+	if sqsArn.Type != queueTypeWebhook {
+		return false
+	}
+	whNotify := serverConfig.GetWebhookNotifyByID(sqsArn.AccountID)
+	if !whNotify.Enable {
+		return false
+	}
+	if _, err := dialWebhook(whNotify); err != nil {
+		errorIf(err, "Unable to use webhook. %#v", whNotify)
+		return false
+	}
+	return true
+}
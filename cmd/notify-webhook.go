@@ -0,0 +1,137 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Number of attempts at delivering an event to a webhook.
+	webhookAttempts = 3
+	// Delay before retrying a failed delivery, doubled after every
+	// attempt.
+	webhookRetryDelay = 250 * time.Millisecond
+	// Maximum time a webhook has to respond.
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookNotify - POSTs events as JSON to an HTTP endpoint.
+type webhookNotify struct {
+	Enable   bool   `json:"enable"`
+	Endpoint string `json:"endpoint"`
+}
+
+type httpConn struct {
+	*http.Client
+	Endpoint string
+}
+
+// dialWebhook - validates the endpoint of a webhook and returns a
+// connection to send events to it. Returns error if the webhook is not
+// enabled.
+func dialWebhook(whNotify webhookNotify) (httpConn, error) {
+	if !whNotify.Enable {
+		return httpConn{}, errNotifyNotEnabled
+	}
+	u, err := url.Parse(whNotify.Endpoint)
+	if err != nil {
+		return httpConn{}, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return httpConn{}, fmt.Errorf("Invalid webhook endpoint %s", whNotify.Endpoint)
+	}
+	return httpConn{
+		Client:   &http.Client{Timeout: webhookTimeout},
+		Endpoint: whNotify.Endpoint,
+	}, nil
+}
+
+func newWebhookNotify(accountID string) (*logrus.Logger, error) {
+	whNotify := serverConfig.GetWebhookNotifyByID(accountID)
+
+	conn, err := dialWebhook(whNotify)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookLog := logrus.New()
+
+	// Disable writing to console.
+	webhookLog.Out = ioutil.Discard
+
+	// Set default JSON formatter.
+	webhookLog.Formatter = new(logrus.JSONFormatter)
+
+	webhookLog.Hooks.Add(conn)
+
+	// Success, webhook enabled.
+	return webhookLog, nil
+}
+
+// post - sends an event once, returns an error unless the endpoint
+// accepts it with a 2xx status. retry is true if the delivery may
+// succeed when attempted again.
+func (n httpConn) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", n.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Rejected events are not sent again.
+		return resp.StatusCode >= 500, fmt.Errorf("Unable to send event to %s: %s", n.Endpoint, resp.Status)
+	}
+	return false, nil
+}
+
+// Fire is called when an event should be sent to the webhook, failed
+// deliveries are retried webhookAttempts times in all.
+func (n httpConn) Fire(entry *logrus.Entry) error {
+	body, err := entry.Reader()
+	if err != nil {
+		return err
+	}
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(body.Bytes())
+		if err == nil || !retry || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Levels are Info for notification events.
+func (n httpConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Tests that events are POSTed to the webhook, and that only failures
+// of the endpoint are retried.
+func TestWebhookFire(t *testing.T) {
+	testCases := []struct {
+		statuses         []int
+		expectedAttempts int
+		shouldPass       bool
+	}{
+		// Accepted at once.
+		{[]int{http.StatusOK}, 1, true},
+		// Accepted after the endpoint recovers.
+		{[]int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusNoContent}, 3, true},
+		// Rejected events are not sent again.
+		{[]int{http.StatusBadRequest}, 1, false},
+		// Given up after webhookAttempts attempts.
+		{[]int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}, webhookAttempts, false},
+	}
+
+	for i, testCase := range testCases {
+		var mu sync.Mutex
+		var bodies []map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body map[string]interface{}
+			if err = json.Unmarshal(data, &body); err != nil {
+				t.Errorf("Test %d: Invalid JSON event %q: %v", i+1, data, err)
+			}
+			if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Test %d: Expected Content-Type application/json, got %s", i+1, contentType)
+			}
+			w.WriteHeader(testCase.statuses[len(bodies)])
+			bodies = append(bodies, body)
+		}))
+
+		conn, err := dialWebhook(webhookNotify{Enable: true, Endpoint: server.URL})
+		if err != nil {
+			t.Fatalf("Test %d: Unable to dial webhook: %v", i+1, err)
+		}
+		log := logrus.New()
+		log.Formatter = new(logrus.JSONFormatter)
+		entry := log.WithFields(logrus.Fields{
+			"EventType": "s3:ObjectCreated:Put",
+			"Key":       "bucket/object",
+		})
+		entry.Message = ""
+		err = conn.Fire(entry)
+		server.Close()
+
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error: %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected the delivery to fail", i+1)
+		}
+		if len(bodies) != testCase.expectedAttempts {
+			t.Errorf("Test %d: Expected %d attempts, got %d", i+1, testCase.expectedAttempts, len(bodies))
+		}
+		if len(bodies) > 0 && bodies[0]["Key"] != "bucket/object" {
+			t.Errorf("Test %d: Unexpected event %v", i+1, bodies[0])
+		}
+	}
+}
+
+// Tests validation of webhook endpoints.
+func TestDialWebhook(t *testing.T) {
+	testCases := []struct {
+		whNotify   webhookNotify
+		shouldPass bool
+	}{
+		{webhookNotify{Enable: true, Endpoint: "http://localhost:8080/events"}, true},
+		{webhookNotify{Enable: true, Endpoint: "https://example.com"}, true},
+		{webhookNotify{Enable: false, Endpoint: "http://localhost:8080"}, false},
+		{webhookNotify{Enable: true, Endpoint: "localhost:8080"}, false},
+		{webhookNotify{Enable: true, Endpoint: "ftp://localhost"}, false},
+		{webhookNotify{Enable: true}, false},
+	}
+	for i, testCase := range testCases {
+		_, err := dialWebhook(testCase.whNotify)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error: %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
@@ -17,11 +17,13 @@
 package cmd
 
 import (
+	"io"
 	"net"
 	"net/url"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 )
@@ -35,6 +37,9 @@ const (
 
 	// Buckets meta prefix.
 	bucketMetaPrefix = "buckets"
+
+	// Quarantined objects meta prefix.
+	quarantineMetaPrefix = "quarantine"
 )
 
 // Global object layer mutex, used for safely updating object layer.
@@ -94,6 +99,82 @@ func houseKeeping(storageDisks []StorageAPI) error {
 	return nil
 }
 
+// purgeTempFiles - removes the temporary entries on the local disks
+// whose files were all last modified more than olderThan ago, unlike
+// houseKeeping this is safe while the server is serving requests.
+// Returns the number of entries removed.
+func purgeTempFiles(storageDisks []StorageAPI, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().UTC().Add(-olderThan)
+	purged := 0
+	for _, disk := range storageDisks {
+		if disk == nil {
+			continue
+		}
+		if _, ok := disk.(*networkStorage); ok {
+			// Skip remote disks.
+			continue
+		}
+		entries, err := disk.ListDir(minioMetaTmpBucket, "")
+		if err != nil {
+			if isErrIgnored(err, errDiskNotFound, errVolumeNotFound, errFileNotFound) {
+				continue
+			}
+			return purged, traceError(err)
+		}
+		for _, entry := range entries {
+			modTime, err := tempEntryModTime(disk, entry)
+			if err != nil {
+				return purged, err
+			}
+			// Empty directories may belong to a write which
+			// just started.
+			if modTime.IsZero() || modTime.After(cutoff) {
+				continue
+			}
+			if strings.HasSuffix(entry, slashSeparator) {
+				err = cleanupDir(disk, minioMetaTmpBucket, entry)
+			} else {
+				err = traceError(disk.DeleteFile(minioMetaTmpBucket, entry))
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// tempEntryModTime - returns the latest modification time of the
+// files under a temporary entry, zero if there are none.
+func tempEntryModTime(disk StorageAPI, entry string) (time.Time, error) {
+	if !strings.HasSuffix(entry, slashSeparator) {
+		fi, err := disk.StatFile(minioMetaTmpBucket, entry)
+		if err == errFileNotFound {
+			// Renamed or removed meanwhile.
+			return time.Time{}, nil
+		}
+		return fi.ModTime, traceError(err)
+	}
+	entries, err := disk.ListDir(minioMetaTmpBucket, entry)
+	if err == errFileNotFound {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, traceError(err)
+	}
+	var latest time.Time
+	for _, child := range entries {
+		modTime, err := tempEntryModTime(disk, pathJoin(entry, child))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if modTime.After(latest) {
+			latest = modTime
+		}
+	}
+	return latest, nil
+}
+
 // Check if a network path is local to this node.
 func isLocalStorage(ep *url.URL) bool {
 	if ep.Host == "" {
@@ -276,3 +357,38 @@ func cleanupDir(storage StorageAPI, volume, dirPath string) error {
 	err := delFunc(retainSlash(pathJoin(dirPath)))
 	return err
 }
+
+// copyObject - copies an object by reading it and writing it back
+// with metadata, for object layers without a faster way to copy.
+func copyObject(obj ObjectLayer, srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	srcInfo, err := obj.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		startOffset := int64(0) // Read the whole file.
+		// Get the object.
+		gErr := obj.GetObject(srcBucket, srcObject, startOffset, srcInfo.Size, pipeWriter)
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+
+	sha256sum := ""
+	// Create the object.
+	objInfo, err := obj.PutObject(dstBucket, dstObject, srcInfo.Size, pipeReader, metadata, sha256sum)
+	if err != nil {
+		// Close the this end of the pipe upon error in PutObject.
+		pipeReader.CloseWithError(err)
+		return ObjectInfo{}, err
+	}
+	// Explicitly close the reader, before returning object info.
+	pipeReader.Close()
+
+	return objInfo, nil
+}
@@ -28,6 +28,10 @@ const (
 	FS
 	// Multi disk single node XL backend.
 	XL
+	// Gateway to a remote S3 compatible endpoint.
+	Gateway
+	// Multi disk single node backend keeping whole objects on one disk.
+	JBOD
 	// Add your own backend.
 )
 
@@ -89,6 +93,9 @@ type ObjectInfo struct {
 
 	// User-Defined metadata
 	UserDefined map[string]string
+
+	// Parts of a multipart object, in order, with their stored sizes.
+	Parts []objectPartInfo `json:",omitempty"`
 }
 
 // ListPartsInfo - represents list of all parts.
@@ -67,13 +67,20 @@ func toObjectErr(err error, params ...string) error {
 				Object: params[1],
 			}
 		}
-	case errFileNameTooLong:
+	case errFileNameTooLong, errFileNameInvalid:
 		if len(params) >= 2 {
 			err = ObjectNameInvalid{
 				Bucket: params[0],
 				Object: params[1],
 			}
 		}
+	case errFileNameCollision:
+		if len(params) >= 2 {
+			err = ObjectNameCollision{
+				Bucket: params[0],
+				Object: params[1],
+			}
+		}
 	case errDataTooLarge:
 		if len(params) >= 2 {
 			err = ObjectTooLarge{
@@ -166,6 +173,14 @@ func (e ObjectExistsAsDirectory) Error() string {
 	return "Object exists on : " + e.Bucket + " as directory " + e.Object
 }
 
+// ObjectNameCollision object name differs only in case from an
+// existing object on a case insensitive backend.
+type ObjectNameCollision GenericError
+
+func (e ObjectNameCollision) Error() string {
+	return "Object name collides with an existing object of a different case: " + e.Bucket + "#" + e.Object
+}
+
 //PrefixAccessDenied object access is denied.
 type PrefixAccessDenied GenericError
 
@@ -16,7 +16,10 @@
 
 package cmd
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 // ObjectLayer implements primitives for object API layer.
 type ObjectLayer interface {
@@ -35,6 +38,7 @@ type ObjectLayer interface {
 	GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) (err error)
 	GetObjectInfo(bucket, object string) (objInfo ObjectInfo, err error)
 	PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (objInto ObjectInfo, err error)
+	CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (objInfo ObjectInfo, err error)
 	DeleteObject(bucket, object string) error
 
 	// Multipart operations.
@@ -49,4 +53,9 @@ type ObjectLayer interface {
 	HealBucket(bucket string) error
 	HealObject(bucket, object string) error
 	ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error)
+
+	// Verification operations.
+	VerifyObject(bucket, object string) (problems []string, err error)
+	QuarantineObject(bucket, object string) error
+	PurgeTempFiles(olderThan time.Duration) (purged int, err error)
 }
@@ -0,0 +1,99 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"sort"
+)
+
+// objectLister - iterates over all the objects and common prefixes
+// of a listing, holding a single page of it in memory.
+type objectLister struct {
+	objAPI    ObjectLayer
+	bucket    string
+	prefix    string
+	marker    string
+	delimiter string
+
+	// Entries of the current page not returned yet.
+	entries   []ObjectInfo
+	truncated bool
+}
+
+// newObjectLister - returns a lister of the entries after marker,
+// pages are listed as the entries are consumed.
+func newObjectLister(objAPI ObjectLayer, bucket, prefix, marker, delimiter string) *objectLister {
+	return &objectLister{
+		objAPI:    objAPI,
+		bucket:    bucket,
+		prefix:    prefix,
+		marker:    marker,
+		delimiter: delimiter,
+		truncated: true,
+	}
+}
+
+// listEntries - returns the objects and common prefixes of a listing
+// page in lexical order, common prefixes have IsDir set.
+func listEntries(bucket string, result ListObjectsInfo) []ObjectInfo {
+	entries := make([]ObjectInfo, 0, len(result.Objects)+len(result.Prefixes))
+	for _, objInfo := range result.Objects {
+		if objInfo.Name != "" {
+			entries = append(entries, objInfo)
+		}
+	}
+	for _, prefix := range result.Prefixes {
+		entries = append(entries, ObjectInfo{Bucket: bucket, Name: prefix, IsDir: true})
+	}
+	sort.Sort(byObjectInfoName(entries))
+	return entries
+}
+
+// byObjectInfoName - sorts object infos by name.
+type byObjectInfoName []ObjectInfo
+
+func (s byObjectInfoName) Len() int           { return len(s) }
+func (s byObjectInfoName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byObjectInfoName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+// Next - returns the next entry of the listing, io.EOF once all
+// entries were returned.
+func (l *objectLister) Next() (ObjectInfo, error) {
+	for len(l.entries) == 0 {
+		if !l.truncated {
+			return ObjectInfo{}, io.EOF
+		}
+		result, err := l.objAPI.ListObjects(l.bucket, l.prefix, l.marker, l.delimiter, maxObjectList)
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+		l.entries = listEntries(l.bucket, result)
+		if len(l.entries) == 0 {
+			l.truncated = false
+			continue
+		}
+		l.truncated = result.IsTruncated
+		l.marker = result.NextMarker
+		if l.marker == "" {
+			l.marker = l.entries[len(l.entries)-1].Name
+		}
+	}
+	entry := l.entries[0]
+	l.entries = l.entries[1:]
+	return entry, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// Tests iterating over listings with objectLister.
+func TestObjectLister(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectLister)
+}
+
+func testObjectLister(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "lister-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	for _, object := range []string{"dir/y", "a", "c/d", "dir/x", "b"} {
+		if _, err := obj.PutObject(bucket, object, 1, bytes.NewReader([]byte("a")), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object: %v", instanceType, err)
+		}
+	}
+
+	testCases := []struct {
+		prefix, marker, delimiter string
+		expected                  []string
+	}{
+		{"", "", "", []string{"a", "b", "c/d", "dir/x", "dir/y"}},
+		{"", "", "/", []string{"a", "b", "c/", "dir/"}},
+		{"", "b", "", []string{"c/d", "dir/x", "dir/y"}},
+		{"dir/", "", "/", []string{"dir/x", "dir/y"}},
+		{"missing/", "", "", nil},
+	}
+	for i, testCase := range testCases {
+		lister := newObjectLister(obj, bucket, testCase.prefix, testCase.marker, testCase.delimiter)
+		var names []string
+		for {
+			objInfo, err := lister.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Test %d: %s: Unexpected error: %v", i+1, instanceType, err)
+			}
+			if objInfo.IsDir != (objInfo.Name[len(objInfo.Name)-1] == '/') {
+				t.Errorf("Test %d: %s: Unexpected IsDir of %s", i+1, instanceType, objInfo.Name)
+			}
+			names = append(names, objInfo.Name)
+		}
+		if !reflect.DeepEqual(names, testCase.expected) {
+			t.Errorf("Test %d: %s: Expected %v, got %v", i+1, instanceType, testCase.expected, names)
+		}
+	}
+
+	if _, err := newObjectLister(obj, "lister-missing", "", "", "").Next(); err == nil {
+		t.Errorf("%s: Expected BucketNotFound, got %v", instanceType, err)
+	} else if _, ok := errorCause(err).(BucketNotFound); !ok {
+		t.Errorf("%s: Expected BucketNotFound, got %v", instanceType, err)
+	}
+}
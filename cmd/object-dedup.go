@@ -0,0 +1,719 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Internal metadata of deduplicated objects, whose stored data is
+	// a manifest of their chunks - the size and MD5 of their data.
+	dedupManifestMetadata = internalMetadataPrefix + "Dedup-Manifest"
+	dedupSizeMetadata     = internalMetadataPrefix + "Dedup-Size"
+	dedupMD5Metadata      = internalMetadataPrefix + "Dedup-Md5"
+
+	// Size of the chunks the data of objects is split in, the last
+	// chunk of an object may be shorter.
+	dedupChunkSize = 1024 * 1024
+
+	// Prefix in the meta bucket of the chunks, of their reference
+	// counts and of the names locked while they are updated.
+	dedupPrefix = "dedup"
+)
+
+// dedupMetadata - metadata recording the deduplication of an object.
+var dedupMetadata = []string{
+	dedupManifestMetadata,
+	dedupSizeMetadata,
+	dedupMD5Metadata,
+}
+
+// removeDedupMetadata - removes any deduplication metadata, so that it
+// is never copied from one object to another.
+func removeDedupMetadata(metadata map[string]string) {
+	for _, key := range dedupMetadata {
+		delete(metadata, key)
+	}
+}
+
+// isDedupObject - returns true if the object is stored as a manifest.
+func isDedupObject(objInfo ObjectInfo) bool {
+	return objInfo.UserDefined[dedupManifestMetadata] == "1"
+}
+
+// getDedupChunkPath - returns the name of a chunk in the meta bucket.
+func getDedupChunkPath(hash string) string {
+	return path.Join(dedupPrefix, "chunks", hash)
+}
+
+// getDedupRefPath - returns the name of the reference count of a chunk
+// in the meta bucket.
+func getDedupRefPath(hash string) string {
+	return path.Join(dedupPrefix, "refs", hash) + ".json"
+}
+
+// getDedupMarkerPath - returns the name of the marker of a manifest
+// stored in the meta bucket, which keeps no metadata of its objects on
+// every backend.
+func getDedupMarkerPath(object string) string {
+	return path.Join(dedupPrefix, "manifests", object) + ".json"
+}
+
+// dedupChunk - a chunk of the data of an object.
+type dedupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// dedupManifestV1 - stored data of a deduplicated object, its chunks
+// in order and the parts it was uploaded in if multipart.
+type dedupManifestV1 struct {
+	Version string           `json:"version"`
+	Parts   []objectPartInfo `json:"parts,omitempty"`
+	Chunks  []dedupChunk     `json:"chunks"`
+}
+
+// dedupRefV1 - number of manifests referencing a chunk.
+type dedupRefV1 struct {
+	Version string `json:"version"`
+	Count   int64  `json:"count"`
+	Size    int64  `json:"size"`
+}
+
+// DedupStats - deduplicated storage counters.
+type DedupStats struct {
+	// Distinct chunks stored and their total size.
+	Chunks      int64 `json:"chunks"`
+	StoredBytes int64 `json:"storedBytes"`
+	// Size of the data of all the objects, prior versions included.
+	LogicalBytes int64 `json:"logicalBytes"`
+	// LogicalBytes over StoredBytes, 1 without any duplicate data.
+	Ratio float64 `json:"ratio"`
+}
+
+// Global deduplicated storage, nil unless enabled with MINIO_DEDUP.
+var globalDedup *dedupStore
+
+// dedupStore - counts the chunks stored once for all the objects
+// referencing them.
+type dedupStore struct {
+	mu           sync.Mutex
+	chunks       int64
+	storedBytes  int64
+	logicalBytes int64
+}
+
+// parseDedupEnv - returns whether the data of new objects is
+// deduplicated.
+func parseDedupEnv(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return false, nil
+	case "on":
+		return true, nil
+	}
+	return false, fmt.Errorf("Unknown value `%s` for MINIO_DEDUP, expected `on` or `off`", value)
+}
+
+// loadDedupStore - loads the deduplicated storage from the environment,
+// nil if disabled.
+func loadDedupStore() (*dedupStore, error) {
+	enabled, err := parseDedupEnv(os.Getenv("MINIO_DEDUP"))
+	if err != nil || !enabled {
+		return nil, err
+	}
+	return &dedupStore{}, nil
+}
+
+// update - adds the changes of a reference to the counters.
+func (s *dedupStore) update(chunks, storedBytes, logicalBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks += chunks
+	s.storedBytes += storedBytes
+	s.logicalBytes += logicalBytes
+}
+
+// stats - returns the counters.
+func (s *dedupStore) stats() DedupStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := DedupStats{
+		Chunks:       s.chunks,
+		StoredBytes:  s.storedBytes,
+		LogicalBytes: s.logicalBytes,
+	}
+	if s.storedBytes > 0 {
+		stats.Ratio = float64(s.logicalBytes) / float64(s.storedBytes)
+	}
+	return stats
+}
+
+// load - sets the counters from the reference counts stored in objAPI.
+func (s *dedupStore) load(objAPI ObjectLayer) error {
+	var chunks, storedBytes, logicalBytes int64
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, path.Join(dedupPrefix, "refs")+slashSeparator, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			ref, err := readDedupRef(objAPI, objInfo.Name)
+			if err != nil {
+				return err
+			}
+			chunks++
+			storedBytes += ref.Size
+			logicalBytes += ref.Count * ref.Size
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks, s.storedBytes, s.logicalBytes = chunks, storedBytes, logicalBytes
+	return nil
+}
+
+// readDedupRef - returns the reference count stored as name in the
+// meta bucket, errConfigNotFound if there is none.
+func readDedupRef(objAPI ObjectLayer, name string) (dedupRefV1, error) {
+	var ref dedupRefV1
+	var buffer bytes.Buffer
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, name)
+	if err == nil {
+		err = objAPI.GetObject(minioMetaBucket, name, 0, objInfo.Size, &buffer)
+	}
+	if isErrObjectNotFound(err) {
+		return ref, errConfigNotFound
+	}
+	if err != nil {
+		return ref, err
+	}
+	err = json.Unmarshal(buffer.Bytes(), &ref)
+	return ref, err
+}
+
+// dedupObjects - object layer splitting the data of new objects in
+// chunks stored once by their SHA-256 in the meta bucket, the objects
+// being stored as manifests of their chunks. Chunks are reference
+// counted and removed with the last manifest referencing them. Objects
+// in the meta bucket are not deduplicated, but copies of deduplicated
+// objects are, such as prior versions. Parts of multipart uploads are
+// stored as they are until the upload is completed.
+type dedupObjects struct {
+	ObjectLayer
+	store *dedupStore
+}
+
+// newDedupObjects - returns objAPI deduplicating the data of new
+// objects and counting it in store.
+func newDedupObjects(objAPI ObjectLayer, store *dedupStore) ObjectLayer {
+	return dedupObjects{ObjectLayer: objAPI, store: store}
+}
+
+// lockChunk - returns the lock of the reference count of a chunk.
+func (d dedupObjects) lockChunk(hash string) *lockInstance {
+	return nsMutex.NewNSLock(minioMetaBucket, path.Join(dedupPrefix, "locks", "chunks", hash))
+}
+
+// lockObject - returns the lock of the manifest of an object, held
+// while its chunks are read or replaced.
+func (d dedupObjects) lockObject(bucket, object string) *lockInstance {
+	return nsMutex.NewNSLock(minioMetaBucket, path.Join(dedupPrefix, "locks", "objects", bucket, object))
+}
+
+// statObject - returns the stored info of an object, with the metadata
+// of its marker if a manifest in the meta bucket.
+func (d dedupObjects) statObject(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := d.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil || bucket != minioMetaBucket {
+		return objInfo, err
+	}
+	markerPath := getDedupMarkerPath(object)
+	markerInfo, err := d.ObjectLayer.GetObjectInfo(minioMetaBucket, markerPath)
+	if isErrObjectNotFound(err) {
+		return objInfo, nil
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	var buffer bytes.Buffer
+	if err = d.ObjectLayer.GetObject(minioMetaBucket, markerPath, 0, markerInfo.Size, &buffer); err != nil {
+		return ObjectInfo{}, err
+	}
+	var metadata map[string]string
+	if err = json.Unmarshal(buffer.Bytes(), &metadata); err != nil {
+		return ObjectInfo{}, traceError(errUnexpected)
+	}
+	userDefined := make(map[string]string, len(objInfo.UserDefined)+len(metadata))
+	for k, v := range objInfo.UserDefined {
+		userDefined[k] = v
+	}
+	for _, k := range dedupMetadata {
+		userDefined[k] = metadata[k]
+	}
+	objInfo.UserDefined = userDefined
+	return objInfo, nil
+}
+
+// writeDedupRef - stores the reference count of a chunk.
+func (d dedupObjects) writeDedupRef(hash string, ref dedupRefV1) error {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	_, err = d.ObjectLayer.PutObject(minioMetaBucket, getDedupRefPath(hash), int64(len(data)), bytes.NewReader(data), nil, getSHA256Hash(data))
+	return err
+}
+
+// addRef - references a chunk, storing data if it is not stored yet.
+// data may be nil if the chunk is known to be stored.
+func (d dedupObjects) addRef(hash string, data []byte) error {
+	lock := d.lockChunk(hash)
+	lock.Lock()
+	defer lock.Unlock()
+
+	ref, err := readDedupRef(d.ObjectLayer, getDedupRefPath(hash))
+	if err == errConfigNotFound {
+		if data == nil {
+			return traceError(errUnexpected)
+		}
+		ref = dedupRefV1{Version: "1", Size: int64(len(data))}
+		if _, err = d.ObjectLayer.PutObject(minioMetaBucket, getDedupChunkPath(hash), ref.Size, bytes.NewReader(data), nil, hash); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	ref.Count++
+	if err = d.writeDedupRef(hash, ref); err != nil {
+		return err
+	}
+	if ref.Count == 1 {
+		d.store.update(1, ref.Size, ref.Size)
+	} else {
+		d.store.update(0, 0, ref.Size)
+	}
+	return nil
+}
+
+// releaseRef - drops a reference to a chunk, removing it with the
+// last one.
+func (d dedupObjects) releaseRef(hash string) error {
+	lock := d.lockChunk(hash)
+	lock.Lock()
+	defer lock.Unlock()
+
+	ref, err := readDedupRef(d.ObjectLayer, getDedupRefPath(hash))
+	if err == errConfigNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ref.Count--
+	if ref.Count > 0 {
+		if err = d.writeDedupRef(hash, ref); err != nil {
+			return err
+		}
+		d.store.update(0, 0, -ref.Size)
+		return nil
+	}
+	if err = d.ObjectLayer.DeleteObject(minioMetaBucket, getDedupChunkPath(hash)); err != nil && !isErrObjectNotFound(err) {
+		return err
+	}
+	if err = d.ObjectLayer.DeleteObject(minioMetaBucket, getDedupRefPath(hash)); err != nil && !isErrObjectNotFound(err) {
+		return err
+	}
+	d.store.update(-1, -ref.Size, -ref.Size)
+	return nil
+}
+
+// releaseRefs - drops the references to chunks, logging failures which
+// leave chunks behind.
+func (d dedupObjects) releaseRefs(chunks []dedupChunk) {
+	for _, chunk := range chunks {
+		errorIf(d.releaseRef(chunk.Hash), "Unable to release chunk %s.", chunk.Hash)
+	}
+}
+
+// putChunks - splits data in chunks and references them, returns the
+// chunks with the size, MD5 and SHA-256 of data.
+func (d dedupObjects) putChunks(data io.Reader) (chunks []dedupChunk, size int64, md5Hex, sha256Hex string, err error) {
+	md5Writer := md5.New()
+	sha256Writer := sha256.New()
+	reader := io.TeeReader(data, io.MultiWriter(md5Writer, sha256Writer))
+	buf := make([]byte, dedupChunkSize)
+	for {
+		n, rErr := io.ReadFull(reader, buf)
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+			hash := hex.EncodeToString(sum[:])
+			if err = d.addRef(hash, buf[:n]); err != nil {
+				d.releaseRefs(chunks)
+				return nil, 0, "", "", err
+			}
+			chunks = append(chunks, dedupChunk{Hash: hash, Size: int64(n)})
+			size += int64(n)
+		}
+		if rErr == io.EOF || rErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rErr != nil {
+			d.releaseRefs(chunks)
+			return nil, 0, "", "", traceError(rErr)
+		}
+	}
+	return chunks, size, hex.EncodeToString(md5Writer.Sum(nil)), hex.EncodeToString(sha256Writer.Sum(nil)), nil
+}
+
+// readManifest - returns the manifest of a deduplicated object.
+func (d dedupObjects) readManifest(bucket, object string, objInfo ObjectInfo) (dedupManifestV1, error) {
+	var manifest dedupManifestV1
+	var buffer bytes.Buffer
+	if err := d.ObjectLayer.GetObject(bucket, object, 0, objInfo.Size, &buffer); err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &manifest); err != nil {
+		return manifest, traceError(errUnexpected)
+	}
+	return manifest, nil
+}
+
+// writeManifest - stores the manifest of an object whose chunks are
+// referenced, and releases the chunks of the object it replaces. If
+// expectMD5 is set the object is only replaced if it still has that
+// MD5, the chunks are released otherwise.
+func (d dedupObjects) writeManifest(bucket, object string, manifest dedupManifestV1, size int64, md5Hex, expectMD5 string, metadata map[string]string) (ObjectInfo, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		d.releaseRefs(manifest.Chunks)
+		return ObjectInfo{}, err
+	}
+	delete(metadata, "md5Sum")
+	metadata[dedupManifestMetadata] = "1"
+	metadata[dedupSizeMetadata] = strconv.FormatInt(size, 10)
+	metadata[dedupMD5Metadata] = md5Hex
+
+	lock := d.lockObject(bucket, object)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var oldChunks []dedupChunk
+	oldInfo, err := d.statObject(bucket, object)
+	if err == nil && expectMD5 != "" && (isDedupObject(oldInfo) || oldInfo.MD5Sum != expectMD5) {
+		d.releaseRefs(manifest.Chunks)
+		return dedupObjectInfo(oldInfo)
+	}
+	if err == nil && isDedupObject(oldInfo) {
+		oldManifest, mErr := d.readManifest(bucket, object, oldInfo)
+		if mErr != nil {
+			d.releaseRefs(manifest.Chunks)
+			return ObjectInfo{}, mErr
+		}
+		oldChunks = oldManifest.Chunks
+	}
+
+	objInfo, err := d.ObjectLayer.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), metadata, getSHA256Hash(data))
+	if err == nil && bucket == minioMetaBucket {
+		objInfo.UserDefined = metadata
+		err = d.writeMarker(object, metadata)
+	}
+	if err != nil {
+		d.releaseRefs(manifest.Chunks)
+		return ObjectInfo{}, err
+	}
+	d.releaseRefs(oldChunks)
+	return dedupObjectInfo(objInfo)
+}
+
+// writeMarker - stores the deduplication metadata of a manifest in the
+// meta bucket.
+func (d dedupObjects) writeMarker(object string, metadata map[string]string) error {
+	marker := make(map[string]string, len(dedupMetadata))
+	for _, k := range dedupMetadata {
+		marker[k] = metadata[k]
+	}
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	_, err = d.ObjectLayer.PutObject(minioMetaBucket, getDedupMarkerPath(object), int64(len(data)), bytes.NewReader(data), nil, getSHA256Hash(data))
+	return err
+}
+
+// dedupObjectInfo - sets the size and MD5 of a deduplicated object to
+// the ones of its data.
+func dedupObjectInfo(objInfo ObjectInfo) (ObjectInfo, error) {
+	if !isDedupObject(objInfo) {
+		return objInfo, nil
+	}
+	size, err := strconv.ParseInt(objInfo.UserDefined[dedupSizeMetadata], 10, 64)
+	if err != nil {
+		return ObjectInfo{}, traceError(errUnexpected)
+	}
+	objInfo.Size = size
+	objInfo.MD5Sum = objInfo.UserDefined[dedupMD5Metadata]
+	objInfo.Parts = nil
+	return objInfo, nil
+}
+
+// GetObject - writes the data of an object, from its chunks if
+// deduplicated.
+func (d dedupObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	lock := d.lockObject(bucket, object)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	objInfo, err := d.statObject(bucket, object)
+	if err != nil || !isDedupObject(objInfo) {
+		return d.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	manifest, err := d.readManifest(bucket, object, objInfo)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	if objInfo, err = dedupObjectInfo(objInfo); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	if startOffset < 0 || length < 0 || startOffset+length > objInfo.Size {
+		return traceError(InvalidRange{startOffset, length, objInfo.Size})
+	}
+	for _, chunk := range manifest.Chunks {
+		if length == 0 {
+			break
+		}
+		if startOffset >= chunk.Size {
+			startOffset -= chunk.Size
+			continue
+		}
+		n := chunk.Size - startOffset
+		if n > length {
+			n = length
+		}
+		if err = d.ObjectLayer.GetObject(minioMetaBucket, getDedupChunkPath(chunk.Hash), startOffset, n, writer); err != nil {
+			return err
+		}
+		startOffset = 0
+		length -= n
+	}
+	return nil
+}
+
+// GetObjectInfo - returns the info of an object, with the size and MD5
+// of its data if deduplicated.
+func (d dedupObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := d.statObject(bucket, object)
+	if err != nil || !isDedupObject(objInfo) {
+		return objInfo, err
+	}
+	// The parts of multipart objects are kept in their manifest.
+	var manifest dedupManifestV1
+	if strings.Contains(objInfo.UserDefined[dedupMD5Metadata], "-") {
+		if manifest, err = d.readManifest(bucket, object, objInfo); err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+	}
+	if objInfo, err = dedupObjectInfo(objInfo); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	objInfo.Parts = manifest.Parts
+	return objInfo, nil
+}
+
+// ListObjects - lists objects with the size and MD5 of their data.
+func (d dedupObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	result, err := d.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return result, err
+	}
+	for i, objInfo := range result.Objects {
+		// Keep the stored size of objects with a broken manifest.
+		if objInfo, err = dedupObjectInfo(objInfo); err == nil {
+			result.Objects[i] = objInfo
+		}
+	}
+	return result, nil
+}
+
+// PutObject - creates an object from chunks of its data, the MD5 and
+// SHA-256 sent are verified against the data.
+func (d dedupObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return d.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+	if err := checkPutObjectArgs(bucket, object, d.ObjectLayer); err != nil {
+		return ObjectInfo{}, err
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	removeDedupMetadata(metadata)
+	if size >= 0 {
+		data = io.LimitReader(data, size)
+	}
+	chunks, n, md5Hex, sha256Hex, err := d.putChunks(data)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if n < size {
+		d.releaseRefs(chunks)
+		return ObjectInfo{}, traceError(IncompleteBody{})
+	}
+	if metadata["md5Sum"] != "" && metadata["md5Sum"] != md5Hex {
+		d.releaseRefs(chunks)
+		return ObjectInfo{}, traceError(BadDigest{metadata["md5Sum"], md5Hex})
+	}
+	if sha256sum != "" && sha256sum != sha256Hex {
+		d.releaseRefs(chunks)
+		return ObjectInfo{}, traceError(SHA256Mismatch{})
+	}
+	manifest := dedupManifestV1{Version: "1", Chunks: chunks}
+	return d.writeManifest(bucket, object, manifest, n, md5Hex, "", metadata)
+}
+
+// CopyObject - copies an object, by referencing the chunks of its
+// source again if deduplicated. Other objects are copied through their
+// data unless copied to the meta bucket.
+func (d dedupObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	srcInfo, err := d.statObject(srcBucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	removeDedupMetadata(metadata)
+	if !isDedupObject(srcInfo) {
+		if dstBucket == minioMetaBucket {
+			return d.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+		}
+		return copyObject(d, srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+
+	lock := d.lockObject(srcBucket, srcObject)
+	lock.RLock()
+	manifest, err := d.readManifest(srcBucket, srcObject, srcInfo)
+	if err != nil {
+		lock.RUnlock()
+		return ObjectInfo{}, toObjectErr(err, srcBucket, srcObject)
+	}
+	var chunks []dedupChunk
+	for _, chunk := range manifest.Chunks {
+		if err = d.addRef(chunk.Hash, nil); err != nil {
+			lock.RUnlock()
+			d.releaseRefs(chunks)
+			return ObjectInfo{}, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	lock.RUnlock()
+
+	if srcInfo, err = dedupObjectInfo(srcInfo); err != nil {
+		d.releaseRefs(chunks)
+		return ObjectInfo{}, toObjectErr(err, srcBucket, srcObject)
+	}
+	if metadata["md5Sum"] != "" && metadata["md5Sum"] != srcInfo.MD5Sum {
+		d.releaseRefs(chunks)
+		return ObjectInfo{}, traceError(BadDigest{metadata["md5Sum"], srcInfo.MD5Sum})
+	}
+	return d.writeManifest(dstBucket, dstObject, manifest, srcInfo.Size, srcInfo.MD5Sum, "", metadata)
+}
+
+// DeleteObject - deletes an object and releases its chunks if
+// deduplicated.
+func (d dedupObjects) DeleteObject(bucket, object string) error {
+	lock := d.lockObject(bucket, object)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var chunks []dedupChunk
+	objInfo, err := d.statObject(bucket, object)
+	isManifest := err == nil && isDedupObject(objInfo)
+	if isManifest {
+		manifest, err := d.readManifest(bucket, object, objInfo)
+		if err != nil {
+			return toObjectErr(err, bucket, object)
+		}
+		chunks = manifest.Chunks
+	}
+	if err = d.ObjectLayer.DeleteObject(bucket, object); err != nil {
+		return err
+	}
+	if isManifest && bucket == minioMetaBucket {
+		errorIf(d.ObjectLayer.DeleteObject(minioMetaBucket, getDedupMarkerPath(object)), "Unable to remove the marker of %s.", object)
+	}
+	d.releaseRefs(chunks)
+	return nil
+}
+
+// CompleteMultipartUpload - completes an upload and stores the object
+// it creates in chunks, keeping its parts and MD5. The object is left
+// as it is stored if it fails.
+func (d dedupObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	md5Sum, err := d.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil || bucket == minioMetaBucket {
+		return md5Sum, err
+	}
+	errorIf(d.dedupObject(bucket, object), "Unable to deduplicate %s/%s.", bucket, object)
+	return md5Sum, nil
+}
+
+// dedupObject - replaces an object stored as it is by a manifest of
+// its chunks, unless it is replaced meanwhile.
+func (d dedupObjects) dedupObject(bucket, object string) error {
+	objInfo, err := d.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil || isDedupObject(objInfo) {
+		return err
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(d.ObjectLayer.GetObject(bucket, object, 0, objInfo.Size, pipeWriter))
+	}()
+	chunks, n, _, _, err := d.putChunks(pipeReader)
+	pipeReader.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+	if n != objInfo.Size {
+		d.releaseRefs(chunks)
+		return traceError(IncompleteBody{})
+	}
+	metadata := make(map[string]string, len(objInfo.UserDefined)+3)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	manifest := dedupManifestV1{Version: "1", Parts: objInfo.Parts, Chunks: chunks}
+	_, err = d.writeManifest(bucket, object, manifest, n, objInfo.MD5Sum, objInfo.MD5Sum, metadata)
+	return err
+}
@@ -0,0 +1,238 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+// Tests parsing MINIO_DEDUP.
+func TestParseDedupEnv(t *testing.T) {
+	testCases := []struct {
+		value   string
+		enabled bool
+		success bool
+	}{
+		{"", false, true},
+		{"off", false, true},
+		{"On", true, true},
+		{"yes", false, false},
+	}
+	for i, testCase := range testCases {
+		enabled, err := parseDedupEnv(testCase.value)
+		if testCase.success != (err == nil) || enabled != testCase.enabled {
+			t.Errorf("Test %d: Expected %v, %v, got %v, %v", i+1, testCase.enabled, testCase.success, enabled, err)
+		}
+	}
+}
+
+// Wrapper for calling testDedupObjects for both XL and FS.
+func TestDedupObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testDedupObjects)
+}
+
+// Tests that identical data is stored once, read back whole or in
+// ranges, and removed with the last object referencing it.
+func testDedupObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	store := &dedupStore{}
+	dobj := newDedupObjects(obj, store)
+
+	bucket := getRandomBucketName()
+	if err := dobj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Two and a half chunks, the first two being identical.
+	chunk := make([]byte, dedupChunkSize)
+	rand.Read(chunk)
+	tail := make([]byte, dedupChunkSize/2)
+	rand.Read(tail)
+	data := append(append(append([]byte{}, chunk...), chunk...), tail...)
+	size := int64(len(data))
+
+	for _, object := range []string{"object1", "object2"} {
+		objInfo, err := dobj.PutObject(bucket, object, size, bytes.NewReader(data), map[string]string{"md5Sum": getMD5Hash(data)}, getSHA256Hash(data))
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if objInfo.Size != size || objInfo.MD5Sum != getMD5Hash(data) {
+			t.Errorf("%s: Unexpected object info %v", instanceType, objInfo)
+		}
+	}
+	stats := store.stats()
+	if stats.Chunks != 2 || stats.StoredBytes != int64(len(chunk)+len(tail)) || stats.LogicalBytes != 2*size {
+		t.Errorf("%s: Unexpected stats %v", instanceType, stats)
+	}
+
+	// The objects are stored as manifests.
+	stored, err := obj.GetObjectInfo(bucket, "object1")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !isDedupObject(stored) || stored.Size >= size {
+		t.Errorf("%s: Expected a manifest, got %v", instanceType, stored)
+	}
+
+	objInfo, err := dobj.GetObjectInfo(bucket, "object1")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.Size != size || objInfo.MD5Sum != getMD5Hash(data) {
+		t.Errorf("%s: Unexpected object info %v", instanceType, objInfo)
+	}
+	result, err := dobj.ListObjects(bucket, "", "", "", 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Objects) != 2 || result.Objects[0].Size != size {
+		t.Errorf("%s: Unexpected listing %v", instanceType, result.Objects)
+	}
+	for _, r := range [][2]int64{{0, size}, {10, 20}, {dedupChunkSize - 5, 10}, {2 * dedupChunkSize, size - 2*dedupChunkSize}, {size, 0}} {
+		var buffer bytes.Buffer
+		if err = dobj.GetObject(bucket, "object2", r[0], r[1], &buffer); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[r[0]:r[0]+r[1]]) {
+			t.Errorf("%s: Range %d-%d does not match", instanceType, r[0], r[0]+r[1])
+		}
+	}
+	if err = dobj.GetObject(bucket, "object2", 0, size+1, &bytes.Buffer{}); !isSameType(errorCause(err), InvalidRange{}) {
+		t.Errorf("%s: Expected InvalidRange, got %v", instanceType, err)
+	}
+
+	// Wrong digests are rejected without keeping the chunks.
+	if _, err = dobj.PutObject(bucket, "bad", size, bytes.NewReader(tail), nil, ""); !isSameType(errorCause(err), IncompleteBody{}) {
+		t.Errorf("%s: Expected IncompleteBody, got %v", instanceType, err)
+	}
+	if _, err = dobj.PutObject(bucket, "bad", int64(len(tail)), bytes.NewReader(tail), map[string]string{"md5Sum": getMD5Hash(chunk)}, ""); !isSameType(errorCause(err), BadDigest{}) {
+		t.Errorf("%s: Expected BadDigest, got %v", instanceType, err)
+	}
+	if _, err = dobj.PutObject(bucket, "bad", int64(len(tail)), bytes.NewReader(tail), nil, getSHA256Hash(chunk)); !isSameType(errorCause(err), SHA256Mismatch{}) {
+		t.Errorf("%s: Expected SHA256Mismatch, got %v", instanceType, err)
+	}
+	if stats = store.stats(); stats.LogicalBytes != 2*size {
+		t.Errorf("%s: Unexpected stats %v", instanceType, stats)
+	}
+
+	// Copies reference the same chunks.
+	if _, err = dobj.CopyObject(bucket, "object1", bucket, "copy", map[string]string{"content-type": "text/plain"}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo, err = dobj.GetObjectInfo(bucket, "copy"); err != nil || objInfo.Size != size || objInfo.ContentType != "text/plain" {
+		t.Errorf("%s: Unexpected copy %v, %v", instanceType, objInfo, err)
+	}
+	if stats = store.stats(); stats.StoredBytes != int64(len(chunk)+len(tail)) || stats.LogicalBytes != 3*size || stats.Ratio != float64(3*size)/float64(len(chunk)+len(tail)) {
+		t.Errorf("%s: Unexpected stats %v", instanceType, stats)
+	}
+
+	// Prior versions keep referencing the chunks of the object.
+	if err = archiveObjectVersion(dobj, bucket, "copy", versioningEnabled); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = dobj.DeleteObject(bucket, "copy"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	versionInfo, err := getObjectVersionInfo(dobj, bucket, "copy", nullVersionID)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var buffer bytes.Buffer
+	if err = dobj.GetObject(versionInfo.Bucket, versionInfo.Name, 0, size, &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected the prior version to be read back", instanceType)
+	}
+	if stats = store.stats(); stats.LogicalBytes != 3*size {
+		t.Errorf("%s: Unexpected stats %v", instanceType, stats)
+	}
+
+	// Multipart objects are deduplicated once completed, keeping their
+	// parts.
+	uploadID, err := dobj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	parts := [][]byte{bytes.Repeat(chunk, 5), tail[:10]}
+	var completeParts []completePart
+	for i, part := range parts {
+		md5Sum, err := dobj.PutObjectPart(bucket, "multipart", uploadID, i+1, int64(len(part)), bytes.NewReader(part), getMD5Hash(part), "")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: md5Sum})
+	}
+	md5Sum, err := dobj.CompleteMultipartUpload(bucket, "multipart", uploadID, completeParts)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if stored, err = obj.GetObjectInfo(bucket, "multipart"); err != nil || !isDedupObject(stored) {
+		t.Errorf("%s: Expected a manifest, got %v, %v", instanceType, stored, err)
+	}
+	objInfo, err = dobj.GetObjectInfo(bucket, "multipart")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.MD5Sum != md5Sum || objInfo.Size != int64(len(parts[0])+len(parts[1])) || len(objInfo.Parts) != 2 || objInfo.Parts[1].Size != int64(len(parts[1])) {
+		t.Errorf("%s: Unexpected object info %v", instanceType, objInfo)
+	}
+	buffer.Reset()
+	if err = dobj.GetObject(bucket, "multipart", int64(len(parts[0])), int64(len(parts[1])), &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), parts[1]) {
+		t.Errorf("%s: Expected the second part to be read back", instanceType)
+	}
+
+	// The counters are reloaded from the stored references.
+	reloaded := &dedupStore{}
+	if err = reloaded.load(obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if reloaded.stats() != store.stats() {
+		t.Errorf("%s: Expected %v, got %v", instanceType, store.stats(), reloaded.stats())
+	}
+
+	// Chunks are removed with the last object referencing them, also
+	// when overwritten.
+	if _, err = dobj.PutObject(bucket, "object1", int64(len(tail)), bytes.NewReader(tail), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	for _, object := range []string{"object1", "object2", "multipart"} {
+		if err = dobj.DeleteObject(bucket, object); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	if err = deleteObjectVersion(dobj, bucket, "copy", nullVersionID); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if stats = store.stats(); stats != (DedupStats{}) {
+		t.Errorf("%s: Expected no chunks left, got %v", instanceType, stats)
+	}
+	result, err = obj.ListObjects(minioMetaBucket, dedupPrefix+slashSeparator, "", "", 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Objects) != 0 {
+		t.Errorf("%s: Expected no chunks left, got %v", instanceType, result.Objects)
+	}
+	if err = dobj.DeleteObject(bucket, "object1"); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected ObjectNotFound, got %v", instanceType, err)
+	}
+}
@@ -0,0 +1,748 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+)
+
+const (
+	// Server side encryption header and metadata set on objects
+	// encrypted at rest, and the ID of the master key sealing their
+	// data key.
+	sseKMSHeader      = "X-Amz-Server-Side-Encryption"
+	sseKMSKeyIDHeader = "X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"
+	sseAlgorithmKMS   = "aws:kms"
+
+	// Internal metadata of objects encrypted at rest - the sealed
+	// data key, the algorithm of the data and whether its parts are
+	// encrypted separately.
+	kmsSealedKeyMetadata = internalMetadataPrefix + "Kms-Sealed-Key"
+	kmsAlgorithmMetadata = internalMetadataPrefix + "Kms-Algorithm"
+	kmsMultipartMetadata = internalMetadataPrefix + "Kms-Multipart"
+	kmsAlgorithm         = "AES-256-GCM"
+
+	// Encrypted data is a random salt followed by segments of at most
+	// kmsSegmentSize bytes, each sealed with a tag of kmsTagSize bytes.
+	kmsSaltLength  = 32
+	kmsSegmentSize = 64 * 1024
+	kmsTagSize     = 16
+
+	// Bucket configuration files holding the sealed data keys of
+	// ongoing multipart uploads.
+	multipartKeysPrefix = "multipart-keys"
+)
+
+// kmsMetadata - metadata recording the encryption of an object at rest.
+var kmsMetadata = []string{
+	sseKMSHeader,
+	sseKMSKeyIDHeader,
+	kmsSealedKeyMetadata,
+	kmsAlgorithmMetadata,
+	kmsMultipartMetadata,
+}
+
+// removeKMSMetadata - removes any encryption metadata, so that it is
+// never copied from one object to another.
+func removeKMSMetadata(metadata map[string]string) {
+	for _, key := range kmsMetadata {
+		delete(metadata, key)
+	}
+}
+
+// isKMSEncryptedObject - returns true if the object is encrypted at
+// rest.
+func isKMSEncryptedObject(objInfo ObjectInfo) bool {
+	return objInfo.UserDefined[kmsSealedKeyMetadata] != ""
+}
+
+// kmsContext - returns the context the data key of an object is
+// sealed with.
+func kmsContext(bucket, object string) string {
+	return path.Join(bucket, object)
+}
+
+// encryptedSize - returns the size of size bytes once encrypted.
+func encryptedSize(size int64) int64 {
+	segments := (size + kmsSegmentSize - 1) / kmsSegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return kmsSaltLength + size + segments*kmsTagSize
+}
+
+// decryptedSize - returns the size of encrypted data of size bytes
+// once decrypted, false if no data encrypts to that size.
+func decryptedSize(size int64) (int64, bool) {
+	size -= kmsSaltLength
+	if size < kmsTagSize {
+		return 0, false
+	}
+	segments := (size + kmsSegmentSize + kmsTagSize - 1) / (kmsSegmentSize + kmsTagSize)
+	if size-segments*kmsTagSize < 0 || encryptedSize(size-segments*kmsTagSize) != size+kmsSaltLength {
+		return 0, false
+	}
+	return size - segments*kmsTagSize, true
+}
+
+// newSegmentCipher - returns the cipher of the segments of a stream,
+// keyed by the data key and the salt of the stream.
+func newSegmentCipher(dataKey, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, dataKey)
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce - returns the nonce of a segment, its sequence number
+// and whether it is the last one, so that segments cannot be
+// reordered or dropped.
+func segmentNonce(seq uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, seq)
+	if final {
+		nonce[8] = 1
+	}
+	return nonce
+}
+
+// kmsEncryptReader - encrypts a stream, salt first.
+type kmsEncryptReader struct {
+	reader io.Reader
+	aead   cipher.AEAD
+	seq    uint64
+	final  bool
+	carry  int
+	plain  []byte
+	sealed []byte
+	out    []byte
+}
+
+// newKMSEncryptReader - returns a reader encrypting size bytes of
+// reader with dataKey. The plain data is verified against md5Hex and
+// sha256Hex if set.
+func newKMSEncryptReader(reader io.Reader, size int64, dataKey []byte, md5Hex, sha256Hex string) (io.Reader, error) {
+	salt := make([]byte, kmsSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	aead, err := newSegmentCipher(dataKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		reader = io.LimitReader(reader, size)
+	}
+	return &kmsEncryptReader{
+		reader: &sseVerifyReader{
+			reader:    reader,
+			md5Hash:   md5.New(),
+			md5Hex:    md5Hex,
+			sha256:    sha256.New(),
+			sha256Hex: sha256Hex,
+		},
+		aead:   aead,
+		plain:  make([]byte, kmsSegmentSize+1),
+		sealed: make([]byte, 0, kmsSegmentSize+kmsTagSize),
+		out:    salt,
+	}, nil
+}
+
+func (r *kmsEncryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.final {
+			return 0, io.EOF
+		}
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// seal - encrypts the next segment. One byte past the segment is read
+// to find out whether it is the last one.
+func (r *kmsEncryptReader) seal() error {
+	n, err := io.ReadFull(r.reader, r.plain[r.carry:])
+	n += r.carry
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		r.final = true
+	default:
+		return err
+	}
+	size := n
+	if !r.final {
+		size = kmsSegmentSize
+	}
+	r.sealed = r.aead.Seal(r.sealed[:0], segmentNonce(r.seq, r.final), r.plain[:size], nil)
+	r.out = r.sealed
+	r.seq++
+	if !r.final {
+		r.plain[0] = r.plain[kmsSegmentSize]
+		r.carry = 1
+	}
+	return nil
+}
+
+// kmsDecryptWriter - decrypts segments of a stream, salt first, and
+// writes the plain data between skip and skip+length.
+type kmsDecryptWriter struct {
+	writer  io.Writer
+	dataKey []byte
+	salt    []byte
+	aead    cipher.AEAD
+	seq     uint64
+	lastSeq uint64
+	buf     []byte
+	skip    int64
+	length  int64
+}
+
+// newKMSDecryptWriter - returns a writer decrypting the segments of a
+// stream of size plain bytes from firstSeq on, and writing length
+// bytes after the first skip bytes to writer. The salt of the stream
+// must be written first.
+func newKMSDecryptWriter(writer io.Writer, dataKey []byte, size int64, firstSeq uint64, skip, length int64) *kmsDecryptWriter {
+	lastSeq := uint64(0)
+	if size > 0 {
+		lastSeq = uint64((size - 1) / kmsSegmentSize)
+	}
+	return &kmsDecryptWriter{
+		writer:  writer,
+		dataKey: dataKey,
+		salt:    make([]byte, 0, kmsSaltLength),
+		seq:     firstSeq,
+		lastSeq: lastSeq,
+		buf:     make([]byte, 0, kmsSegmentSize+kmsTagSize),
+		skip:    skip,
+		length:  length,
+	}
+}
+
+func (d *kmsDecryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if d.aead == nil {
+		c := copy(d.salt[len(d.salt):cap(d.salt)], p)
+		d.salt = d.salt[:len(d.salt)+c]
+		p = p[c:]
+		if len(d.salt) < kmsSaltLength {
+			return n, nil
+		}
+		aead, err := newSegmentCipher(d.dataKey, d.salt)
+		if err != nil {
+			return 0, err
+		}
+		d.aead = aead
+	}
+	for len(p) > 0 {
+		c := copy(d.buf[len(d.buf):cap(d.buf)], p)
+		d.buf = d.buf[:len(d.buf)+c]
+		p = p[c:]
+		if len(d.buf) == cap(d.buf) {
+			if err := d.open(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close - decrypts the last segment written, if partial.
+func (d *kmsDecryptWriter) Close() error {
+	if len(d.buf) == 0 {
+		return nil
+	}
+	return d.open()
+}
+
+// open - decrypts the buffered segment and writes its requested data.
+func (d *kmsDecryptWriter) open() error {
+	plain, err := d.aead.Open(d.buf[:0], segmentNonce(d.seq, d.seq == d.lastSeq), d.buf, nil)
+	if err != nil {
+		return traceError(errObjectTampered)
+	}
+	d.seq++
+	d.buf = d.buf[:0]
+	if d.skip >= int64(len(plain)) {
+		d.skip -= int64(len(plain))
+		return nil
+	}
+	plain = plain[d.skip:]
+	d.skip = 0
+	if int64(len(plain)) > d.length {
+		plain = plain[:d.length]
+	}
+	d.length -= int64(len(plain))
+	_, err = d.writer.Write(plain)
+	return err
+}
+
+// multipartKeyV1 - the sealed data key of an ongoing multipart upload,
+// and the ID of the master key sealing it, empty for the uploads which
+// predate it.
+type multipartKeyV1 struct {
+	Version   string `json:"version"`
+	SealedKey []byte `json:"sealedKey"`
+	KeyID     string `json:"keyId,omitempty"`
+}
+
+// getMultipartKeyFile - returns the name of the bucket configuration
+// file holding the sealed data key of a multipart upload.
+func getMultipartKeyFile(uploadID string) string {
+	return path.Join(multipartKeysPrefix, uploadID) + ".json"
+}
+
+// encryptedObjects - object layer encrypting the data of new objects
+// at rest with data keys sealed by a KMS, and decrypting the data of
+// encrypted objects. Objects in the meta bucket are not encrypted,
+// prior versions of objects are kept as they are stored.
+type encryptedObjects struct {
+	ObjectLayer
+	kms KMS
+	// Unseals the data keys sealed by the master key kms replaced.
+	previous KMS
+}
+
+// newEncryptedObjects - returns objAPI encrypting new objects with
+// data keys sealed by kms, and reading the objects whose data keys are
+// sealed by previous as well if not nil. Objects are not encrypted if
+// kms is nil, but encrypted ones can then not be read.
+func newEncryptedObjects(objAPI ObjectLayer, kms, previous KMS) ObjectLayer {
+	return encryptedObjects{ObjectLayer: objAPI, kms: kms, previous: previous}
+}
+
+// kmsOf - returns the KMS unsealing the data keys sealed by the master
+// key of keyID, nil if none is configured.
+func (e encryptedObjects) kmsOf(keyID string) KMS {
+	if e.previous != nil && keyID == e.previous.KeyID() {
+		return e.previous
+	}
+	return e.kms
+}
+
+// generateKey - returns a new data key of an object and records it
+// sealed in metadata.
+func (e encryptedObjects) generateKey(bucket, object string, metadata map[string]string) ([]byte, error) {
+	key, sealedKey, err := e.kms.GenerateKey(kmsContext(bucket, object))
+	if err != nil {
+		return nil, err
+	}
+	metadata[sseKMSHeader] = sseAlgorithmKMS
+	metadata[sseKMSKeyIDHeader] = e.kms.KeyID()
+	metadata[kmsSealedKeyMetadata] = base64.StdEncoding.EncodeToString(sealedKey)
+	metadata[kmsAlgorithmMetadata] = kmsAlgorithm
+	return key, nil
+}
+
+// unsealKey - returns the data key of an encrypted object stored as
+// bucket and object.
+func (e encryptedObjects) unsealKey(bucket, object string, objInfo ObjectInfo) ([]byte, error) {
+	kms := e.kmsOf(objInfo.UserDefined[sseKMSKeyIDHeader])
+	if kms == nil {
+		return nil, traceError(errKMSNotConfigured)
+	}
+	if objInfo.UserDefined[kmsAlgorithmMetadata] != kmsAlgorithm {
+		return nil, traceError(errUnexpected)
+	}
+	sealedKey, err := base64.StdEncoding.DecodeString(objInfo.UserDefined[kmsSealedKeyMetadata])
+	if err != nil {
+		return nil, traceError(errKMSInvalidSealedKey)
+	}
+	key, err := kms.UnsealKey(sealedKey, kmsContext(bucket, object))
+	return key, traceError(err)
+}
+
+// streamSizes - returns the stored sizes of the separately encrypted
+// streams of an object, one per part of multipart objects. objInfo
+// carries the sizes of its plain data.
+func streamSizes(objInfo ObjectInfo) []int64 {
+	if objInfo.UserDefined[kmsMultipartMetadata] != "true" {
+		return []int64{encryptedSize(objInfo.Size)}
+	}
+	sizes := make([]int64, len(objInfo.Parts))
+	for i, part := range objInfo.Parts {
+		sizes[i] = encryptedSize(part.Size)
+	}
+	return sizes
+}
+
+// decryptObjectInfo - sets the size of an encrypted object and of its
+// parts to the sizes of their plain data.
+func decryptObjectInfo(objInfo ObjectInfo) (ObjectInfo, error) {
+	if !isKMSEncryptedObject(objInfo) {
+		return objInfo, nil
+	}
+	if objInfo.UserDefined[kmsMultipartMetadata] != "true" {
+		size, ok := decryptedSize(objInfo.Size)
+		if !ok {
+			return ObjectInfo{}, traceError(errObjectTampered)
+		}
+		objInfo.Size = size
+		return objInfo, nil
+	}
+	parts := make([]objectPartInfo, len(objInfo.Parts))
+	var size int64
+	for i, part := range objInfo.Parts {
+		plainSize, ok := decryptedSize(part.Size)
+		if !ok {
+			return ObjectInfo{}, traceError(errObjectTampered)
+		}
+		part.Size = plainSize
+		parts[i] = part
+		size += plainSize
+	}
+	objInfo.Size = size
+	objInfo.Parts = parts
+	return objInfo, nil
+}
+
+// getEncryptedObject - writes length bytes of the plain data of an
+// object encrypted with key and stored as bucket and object, from
+// startOffset on. objInfo carries the size of its plain data.
+func (e encryptedObjects) getEncryptedObject(bucket, object string, objInfo ObjectInfo, key []byte, startOffset, length int64, writer io.Writer) (err error) {
+	if startOffset < 0 || length < 0 || startOffset+length > objInfo.Size {
+		return traceError(InvalidRange{startOffset, length, objInfo.Size})
+	}
+	var streamOffset int64
+	for _, streamSize := range streamSizes(objInfo) {
+		if length == 0 {
+			break
+		}
+		plainSize, _ := decryptedSize(streamSize)
+		if startOffset >= plainSize {
+			startOffset -= plainSize
+			streamOffset += streamSize
+			continue
+		}
+		n := plainSize - startOffset
+		if n > length {
+			n = length
+		}
+		firstSeq := startOffset / kmsSegmentSize
+		lastSeq := (startOffset + n - 1) / kmsSegmentSize
+		dataOffset := kmsSaltLength + firstSeq*(kmsSegmentSize+kmsTagSize)
+		dataLength := encryptedSize(plainSize) - dataOffset
+		if lastSeq < (plainSize-1)/kmsSegmentSize {
+			dataLength = (lastSeq - firstSeq + 1) * (kmsSegmentSize + kmsTagSize)
+		}
+		dw := newKMSDecryptWriter(writer, key, plainSize, uint64(firstSeq), startOffset-firstSeq*kmsSegmentSize, n)
+		if firstSeq == 0 {
+			// Read the salt along with the segments.
+			dataOffset, dataLength = 0, dataLength+kmsSaltLength
+		} else if err = e.ObjectLayer.GetObject(bucket, object, streamOffset, kmsSaltLength, dw); err != nil {
+			return err
+		}
+		if err = e.ObjectLayer.GetObject(bucket, object, streamOffset+dataOffset, dataLength, dw); err != nil {
+			return err
+		}
+		if err = dw.Close(); err != nil {
+			return err
+		}
+		startOffset = 0
+		length -= n
+		streamOffset += streamSize
+	}
+	return nil
+}
+
+// GetObject - writes the plain data of an object, decrypting it if
+// encrypted.
+func (e encryptedObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	if bucket == minioMetaBucket {
+		// Prior versions of encrypted objects are kept encrypted, their
+		// info is kept aside.
+		vBucket, vObject, versionID, ok := parseObjectVersionPath(object)
+		if !ok {
+			return e.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+		}
+		objInfo, err := readObjectVersionInfo(e.ObjectLayer, vBucket, vObject, versionID)
+		if err != nil || !isKMSEncryptedObject(objInfo) {
+			return e.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+		}
+		key, err := e.unsealKey(vBucket, vObject, objInfo)
+		if err != nil {
+			return err
+		}
+		return e.getEncryptedObject(bucket, object, objInfo, key, startOffset, length, writer)
+	}
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return err
+	}
+	if !isKMSEncryptedObject(objInfo) {
+		return e.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	if objInfo, err = decryptObjectInfo(objInfo); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	key, err := e.unsealKey(bucket, object, objInfo)
+	if err != nil {
+		return err
+	}
+	return e.getEncryptedObject(bucket, object, objInfo, key, startOffset, length, writer)
+}
+
+// GetObjectInfo - returns the info of an object, with the size of its
+// plain data if encrypted.
+func (e encryptedObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil || bucket == minioMetaBucket {
+		return objInfo, err
+	}
+	objInfo, err = decryptObjectInfo(objInfo)
+	return objInfo, toObjectErr(err, bucket, object)
+}
+
+// ListObjects - lists objects with the size of their plain data.
+func (e encryptedObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	result, err := e.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil || bucket == minioMetaBucket {
+		return result, err
+	}
+	for i, objInfo := range result.Objects {
+		// Keep the stored size of objects which fail to decrypt.
+		if objInfo, err = decryptObjectInfo(objInfo); err == nil {
+			result.Objects[i] = objInfo
+		}
+	}
+	return result, nil
+}
+
+// PutObject - creates an object, encrypting its data if a KMS is
+// configured. The MD5 and SHA-256 sent are verified against the plain
+// data, the MD5 of an encrypted object is the one of its encrypted
+// data.
+func (e encryptedObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if bucket == minioMetaBucket {
+		return e.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	removeKMSMetadata(metadata)
+	if e.kms == nil {
+		return e.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+	key, err := e.generateKey(bucket, object, metadata)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	reader, err := newKMSEncryptReader(data, size, key, metadata["md5Sum"], sha256sum)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	delete(metadata, "md5Sum")
+	if size >= 0 {
+		size = encryptedSize(size)
+	}
+	objInfo, err := e.ObjectLayer.PutObject(bucket, object, size, reader, metadata, "")
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	objInfo, err = decryptObjectInfo(objInfo)
+	return objInfo, toObjectErr(err, bucket, object)
+}
+
+// CopyObject - copies an object, through its plain data unless copied
+// to or from the meta bucket, or neither encrypted nor to encrypt.
+func (e encryptedObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	if srcBucket == minioMetaBucket || dstBucket == minioMetaBucket {
+		return e.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+	srcInfo, err := e.ObjectLayer.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	removeKMSMetadata(metadata)
+	if e.kms == nil && !isKMSEncryptedObject(srcInfo) {
+		return e.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+	}
+	// The MD5 sent is the one of the source as stored, not of its
+	// plain data.
+	if metadata["md5Sum"] != "" && metadata["md5Sum"] != srcInfo.MD5Sum {
+		return ObjectInfo{}, traceError(BadDigest{metadata["md5Sum"], srcInfo.MD5Sum})
+	}
+	delete(metadata, "md5Sum")
+	return copyObject(e, srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+// NewMultipartUpload - initiates a multipart upload, whose parts are
+// encrypted separately with the same data key if a KMS is configured.
+func (e encryptedObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if bucket == minioMetaBucket {
+		return e.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	removeKMSMetadata(metadata)
+	if e.kms == nil {
+		return e.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+	}
+	if _, err := e.generateKey(bucket, object, metadata); err != nil {
+		return "", err
+	}
+	metadata[kmsMultipartMetadata] = "true"
+	sealedKey, err := base64.StdEncoding.DecodeString(metadata[kmsSealedKeyMetadata])
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(multipartKeyV1{Version: "1", SealedKey: sealedKey, KeyID: e.kms.KeyID()})
+	if err != nil {
+		return "", err
+	}
+	uploadID, err := e.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+	if err != nil {
+		return "", err
+	}
+	if err = writeBucketConfigFile(bucket, getMultipartKeyFile(uploadID), data, e.ObjectLayer); err != nil {
+		errorIf(e.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID), "Unable to abort upload %s of %s/%s.", uploadID, bucket, object)
+		return "", err
+	}
+	return uploadID, nil
+}
+
+// readMultipartKey - returns the sealed data key of a multipart
+// upload, errConfigNotFound if its parts are not encrypted.
+func (e encryptedObjects) readMultipartKey(bucket, uploadID string) (multipartKeyV1, error) {
+	data, err := readBucketConfigFile(bucket, getMultipartKeyFile(uploadID), e.ObjectLayer)
+	if err != nil {
+		return multipartKeyV1{}, err
+	}
+	var mkey multipartKeyV1
+	if err = json.Unmarshal(data, &mkey); err != nil {
+		return multipartKeyV1{}, err
+	}
+	return mkey, nil
+}
+
+// removeMultipartKey - removes the sealed data key of a multipart
+// upload, if any.
+func (e encryptedObjects) removeMultipartKey(bucket, uploadID string) error {
+	err := e.ObjectLayer.DeleteObject(minioMetaBucket, path.Join(bucketConfigPrefix, bucket, getMultipartKeyFile(uploadID)))
+	if err != nil && !isErrObjectNotFound(err) {
+		return errorCause(err)
+	}
+	return nil
+}
+
+// PutObjectPart - uploads a part, encrypting its data if the upload is
+// encrypted. The MD5 and SHA-256 sent are verified against the plain
+// data, the MD5 returned is the one of the encrypted data.
+func (e encryptedObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	if bucket == minioMetaBucket {
+		return e.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, data, md5Hex, sha256sum)
+	}
+	mkey, err := e.readMultipartKey(bucket, uploadID)
+	if err == errConfigNotFound {
+		return e.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, data, md5Hex, sha256sum)
+	}
+	if err != nil {
+		return "", err
+	}
+	kms := e.kmsOf(mkey.KeyID)
+	if kms == nil {
+		return "", traceError(errKMSNotConfigured)
+	}
+	key, err := kms.UnsealKey(mkey.SealedKey, kmsContext(bucket, object))
+	if err != nil {
+		return "", traceError(err)
+	}
+	reader, err := newKMSEncryptReader(data, size, key, md5Hex, sha256sum)
+	if err != nil {
+		return "", err
+	}
+	if size >= 0 {
+		size = encryptedSize(size)
+	}
+	return e.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, reader, "", "")
+}
+
+// ListObjectParts - lists the parts of an upload with the size of
+// their plain data.
+func (e encryptedObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	result, err := e.ObjectLayer.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+	if err != nil || bucket == minioMetaBucket {
+		return result, err
+	}
+	if _, err = e.readMultipartKey(bucket, uploadID); err == errConfigNotFound {
+		return result, nil
+	} else if err != nil {
+		return ListPartsInfo{}, err
+	}
+	for i, part := range result.Parts {
+		if size, ok := decryptedSize(part.Size); ok {
+			result.Parts[i].Size = size
+		}
+	}
+	return result, nil
+}
+
+// AbortMultipartUpload - aborts an upload, removing its sealed data
+// key.
+func (e encryptedObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if err := e.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID); err != nil {
+		return err
+	}
+	if bucket == minioMetaBucket {
+		return nil
+	}
+	return e.removeMultipartKey(bucket, uploadID)
+}
+
+// CompleteMultipartUpload - completes an upload, removing its sealed
+// data key which the object now carries.
+func (e encryptedObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	md5Sum, err := e.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil || bucket == minioMetaBucket {
+		return md5Sum, err
+	}
+	errorIf(e.removeMultipartKey(bucket, uploadID), "Unable to remove the key of upload %s of %s/%s.", uploadID, bucket, object)
+	return md5Sum, nil
+}
+
+// setKMSHeaders - returns the encryption of an object encrypted at
+// rest in the response, telling clients its ETag is not the MD5 of its
+// data.
+func setKMSHeaders(w http.ResponseWriter, objInfo ObjectInfo) {
+	if !isKMSEncryptedObject(objInfo) {
+		return
+	}
+	w.Header().Set(sseKMSHeader, objInfo.UserDefined[sseKMSHeader])
+	w.Header().Set(sseKMSKeyIDHeader, objInfo.UserDefined[sseKMSKeyIDHeader])
+}
@@ -0,0 +1,320 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+// Tests that decryptedSize is the inverse of encryptedSize.
+func TestEncryptedSize(t *testing.T) {
+	for _, size := range []int64{0, 1, kmsSegmentSize - 1, kmsSegmentSize, kmsSegmentSize + 1, 3*kmsSegmentSize + 5, 5 * 1024 * 1024} {
+		plainSize, ok := decryptedSize(encryptedSize(size))
+		if !ok || plainSize != size {
+			t.Errorf("Expected %d, got %d, %v", size, plainSize, ok)
+		}
+	}
+	for _, size := range []int64{0, kmsSaltLength, kmsSaltLength + kmsTagSize - 1, kmsSaltLength + kmsSegmentSize + kmsTagSize + 1} {
+		if _, ok := decryptedSize(size); ok {
+			t.Errorf("Expected %d not to be an encrypted size", size)
+		}
+	}
+}
+
+// Wrapper for calling testEncryptedObjects for both XL and FS.
+func TestEncryptedObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testEncryptedObjects)
+}
+
+// Tests that objects are stored encrypted and read back decrypted,
+// whole or in ranges.
+func testEncryptedObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eobj := newEncryptedObjects(obj, kms, nil)
+
+	bucket := getRandomBucketName()
+	if err = eobj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	for i, size := range []int64{0, 1, kmsSegmentSize, 3*kmsSegmentSize + 5} {
+		data := make([]byte, size)
+		rand.Read(data)
+		metadata := map[string]string{"md5Sum": getMD5Hash(data)}
+		objInfo, err := eobj.PutObject(bucket, "object", size, bytes.NewReader(data), metadata, getSHA256Hash(data))
+		if err != nil {
+			t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+		}
+		if objInfo.Size != size {
+			t.Errorf("%s: Test %d: Expected size %d, got %d", instanceType, i+1, size, objInfo.Size)
+		}
+
+		// The data is stored encrypted.
+		stored, err := obj.GetObjectInfo(bucket, "object")
+		if err != nil {
+			t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+		}
+		if stored.Size != encryptedSize(size) || stored.UserDefined[sseKMSKeyIDHeader] != "my-key" {
+			t.Errorf("%s: Test %d: Expected an encrypted object, got %d bytes, %v", instanceType, i+1, stored.Size, stored.UserDefined)
+		}
+		var buffer bytes.Buffer
+		if err = obj.GetObject(bucket, "object", 0, stored.Size, &buffer); err != nil {
+			t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+		}
+		if size > kmsTagSize && bytes.Contains(buffer.Bytes(), data) {
+			t.Errorf("%s: Test %d: Expected the stored data to be encrypted", instanceType, i+1)
+		}
+
+		for _, r := range [][2]int64{{0, size}, {0, size / 2}, {size / 2, size - size/2}, {size / 3, size / 3}} {
+			buffer.Reset()
+			if err = eobj.GetObject(bucket, "object", r[0], r[1], &buffer); err != nil {
+				t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+			}
+			if !bytes.Equal(buffer.Bytes(), data[r[0]:r[0]+r[1]]) {
+				t.Errorf("%s: Test %d: Range %d-%d does not match", instanceType, i+1, r[0], r[0]+r[1])
+			}
+		}
+		if err = eobj.GetObject(bucket, "object", 0, size+1, &buffer); err == nil {
+			t.Errorf("%s: Test %d: Expected a range past the object to fail", instanceType, i+1)
+		}
+	}
+
+	// The MD5 and SHA-256 sent are verified against the plain data.
+	data := []byte("hello, world")
+	if _, err = eobj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": "00000000000000000000000000000000"}, ""); err == nil {
+		t.Errorf("%s: Expected a wrong MD5 to fail", instanceType)
+	}
+	if _, err = eobj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, getSHA256Hash([]byte("other"))); err == nil {
+		t.Errorf("%s: Expected a wrong SHA-256 to fail", instanceType)
+	}
+	if _, err = eobj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": getMD5Hash(data)}, getSHA256Hash(data)); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Listings and copies see the plain data.
+	result, err := eobj.ListObjects(bucket, "", "", "", 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Size != int64(len(data)) {
+		t.Errorf("%s: Expected one object of %d bytes, got %v", instanceType, len(data), result.Objects)
+	}
+	if _, err = eobj.CopyObject(bucket, "object", bucket, "copy", nil); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var buffer bytes.Buffer
+	if err = eobj.GetObject(bucket, "copy", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected the copy to be %q, got %q", instanceType, data, buffer.Bytes())
+	}
+
+	// Encrypted objects are not read without the KMS.
+	if err = newEncryptedObjects(obj, nil, nil).GetObject(bucket, "object", 0, int64(len(data)), &buffer); errorCause(err) != errKMSNotConfigured {
+		t.Errorf("%s: Expected %s, got %v", instanceType, errKMSNotConfigured, err)
+	}
+
+	// Modified data fails to decrypt.
+	stored, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	buffer.Reset()
+	if err = obj.GetObject(bucket, "object", 0, stored.Size, &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	tampered := buffer.Bytes()
+	tampered[len(tampered)-1] ^= 1
+	if _, err = obj.PutObject(bucket, "object", stored.Size, bytes.NewReader(tampered), stored.UserDefined, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = eobj.GetObject(bucket, "object", 0, int64(len(data)), &buffer); errorCause(err) != errObjectTampered {
+		t.Errorf("%s: Expected %s, got %v", instanceType, errObjectTampered, err)
+	}
+}
+
+// Wrapper for calling testVerifyEncryptedObjects for both XL and FS.
+func TestVerifyEncryptedObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testVerifyEncryptedObjects)
+}
+
+// Tests that objects encrypted at rest, whose ETag is the MD5 of their
+// encrypted data, pass verification and are left alone by a repair.
+func testVerifyEncryptedObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eobj := newEncryptedObjects(obj, kms, nil)
+
+	bucket := getRandomBucketName()
+	if err = eobj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := bytes.Repeat([]byte("a"), 2*kmsSegmentSize+1)
+	metadata := map[string]string{"md5Sum": getMD5Hash(data)}
+	if _, err = eobj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	result, err := verifyObject(eobj, bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("%s: Expected an intact object, got %v", instanceType, result.Problems)
+	}
+	info, err := fsckObjectLayer(eobj, true)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if info.Objects != 1 || len(info.Inconsistent) != 0 || info.Quarantined != 0 {
+		t.Errorf("%s: Expected a consistent backend, got %+v", instanceType, info)
+	}
+	var buffer bytes.Buffer
+	if err = eobj.GetObject(bucket, "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected the object to be kept after a repair", instanceType)
+	}
+
+	// Modified data fails to decrypt.
+	stored, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	buffer.Reset()
+	if err = obj.GetObject(bucket, "object", 0, stored.Size, &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	tampered := buffer.Bytes()
+	tampered[len(tampered)-1] ^= 1
+	if _, err = obj.PutObject(bucket, "object", stored.Size, bytes.NewReader(tampered), stored.UserDefined, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if result, err = verifyObject(eobj, bucket, "object"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Problems) != 1 {
+		t.Errorf("%s: Expected the modified object to be reported, got %v", instanceType, result.Problems)
+	}
+}
+
+// Wrapper for calling testEncryptedMultipartObjects for both XL and FS.
+func TestEncryptedMultipartObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testEncryptedMultipartObjects)
+}
+
+// Tests that the parts of multipart uploads are encrypted separately
+// and read back across their boundaries, also once archived as a prior
+// version.
+func testEncryptedMultipartObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	kms, err := parseMasterKey(testMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eobj := newEncryptedObjects(obj, kms, nil)
+
+	bucket := getRandomBucketName()
+	if err = eobj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	uploadID, err := eobj.NewMultipartUpload(bucket, "object", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	parts := [][]byte{make([]byte, 5*1024*1024), make([]byte, kmsSegmentSize+7)}
+	var data []byte
+	var completeParts []completePart
+	for i, part := range parts {
+		rand.Read(part)
+		data = append(data, part...)
+		md5Sum, err := eobj.PutObjectPart(bucket, "object", uploadID, i+1, int64(len(part)), bytes.NewReader(part), getMD5Hash(part), "")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: md5Sum})
+	}
+	result, err := eobj.ListObjectParts(bucket, "object", uploadID, 0, 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	for i, part := range result.Parts {
+		if part.Size != int64(len(parts[i])) {
+			t.Errorf("%s: Expected part %d of %d bytes, got %d", instanceType, i+1, len(parts[i]), part.Size)
+		}
+	}
+	if _, err = eobj.CompleteMultipartUpload(bucket, "object", uploadID, completeParts); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = readBucketConfigFile(bucket, getMultipartKeyFile(uploadID), obj); err != errConfigNotFound {
+		t.Errorf("%s: Expected the key of the upload to be removed, got %v", instanceType, err)
+	}
+
+	read := func(bucket, object string) {
+		size := int64(len(data))
+		for _, r := range [][2]int64{{0, size}, {int64(len(parts[0])) - 10, 20}, {int64(len(parts[0])), int64(len(parts[1]))}} {
+			var buffer bytes.Buffer
+			if err = eobj.GetObject(bucket, object, r[0], r[1], &buffer); err != nil {
+				t.Fatalf("%s: %s", instanceType, err)
+			}
+			if !bytes.Equal(buffer.Bytes(), data[r[0]:r[0]+r[1]]) {
+				t.Errorf("%s: Range %d-%d of %s does not match", instanceType, r[0], r[0]+r[1], object)
+			}
+		}
+	}
+	objInfo, err := eobj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.Size != int64(len(data)) {
+		t.Errorf("%s: Expected %d bytes, got %d", instanceType, len(data), objInfo.Size)
+	}
+	for i, part := range objInfo.Parts {
+		if part.Size != int64(len(parts[i])) {
+			t.Errorf("%s: Expected part %d of %d bytes, got %d", instanceType, i+1, len(parts[i]), part.Size)
+		}
+	}
+	read(bucket, "object")
+
+	// Prior versions are kept encrypted as they are stored.
+	if err = archiveObjectVersion(eobj, bucket, "object", versioningEnabled); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = eobj.DeleteObject(bucket, "object"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	versionInfo, err := getObjectVersionInfo(eobj, bucket, "object", nullVersionID)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	stored, err := obj.GetObjectInfo(versionInfo.Bucket, versionInfo.Name)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if stored.Size == int64(len(data)) {
+		t.Errorf("%s: Expected the prior version to be stored encrypted", instanceType)
+	}
+	read(versionInfo.Bucket, versionInfo.Name)
+}
@@ -0,0 +1,171 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Global backend reads fall back to, nil unless enabled with
+// MINIO_FALLBACK_ENDPOINT.
+var globalObjectFallback *objectFallback
+
+// objectFallback - backend objects missing locally are read from,
+// copied to the local backend on the way if populate is set.
+type objectFallback struct {
+	source   ObjectLayer
+	populate bool
+}
+
+// parseFallbackPopulate - returns whether objects read from the
+// fallback are copied to the local backend.
+func parseFallbackPopulate(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return false, nil
+	case "on":
+		return true, nil
+	}
+	return false, fmt.Errorf("Unknown value `%s` for MINIO_FALLBACK_POPULATE, expected `on` or `off`", value)
+}
+
+// loadObjectFallback - loads the fallback from the environment, nil if
+// MINIO_FALLBACK_ENDPOINT is not set.
+func loadObjectFallback() (*objectFallback, error) {
+	endpoint := os.Getenv("MINIO_FALLBACK_ENDPOINT")
+	if endpoint == "" {
+		return nil, nil
+	}
+	populate, err := parseFallbackPopulate(os.Getenv("MINIO_FALLBACK_POPULATE"))
+	if err != nil {
+		return nil, err
+	}
+	source, err := newS3Objects(endpoint, os.Getenv("MINIO_FALLBACK_ACCESS_KEY"),
+		os.Getenv("MINIO_FALLBACK_SECRET_KEY"), os.Getenv("MINIO_FALLBACK_REGION"))
+	if err != nil {
+		return nil, err
+	}
+	return &objectFallback{source: source, populate: populate}, nil
+}
+
+// isErrFallback - returns whether err of a local read is retried on the
+// fallback, the object or its whole bucket being missing.
+func isErrFallback(err error) bool {
+	switch errorCause(err).(type) {
+	case ObjectNotFound, BucketNotFound:
+		return true
+	}
+	return false
+}
+
+// fallbackObjects - object layer reading the objects it misses from a
+// second backend, which keeps the data of a migration until all of it
+// is read or copied. Objects deleted locally but still kept by the
+// fallback are read again. Listings and writes are only local.
+type fallbackObjects struct {
+	ObjectLayer
+	fallback *objectFallback
+}
+
+// newFallbackObjects - returns objAPI falling back to fallback.
+func newFallbackObjects(objAPI ObjectLayer, fallback *objectFallback) ObjectLayer {
+	return fallbackObjects{ObjectLayer: objAPI, fallback: fallback}
+}
+
+// populateObject - copies the object from the fallback, creating its
+// bucket if missing.
+func (f fallbackObjects) populateObject(bucket, object string, objInfo ObjectInfo) error {
+	metadata := make(map[string]string, len(objInfo.UserDefined)+1)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	// The ETag of multipart objects is not the MD5 of the data.
+	if !strings.Contains(objInfo.MD5Sum, "-") {
+		metadata["md5Sum"] = objInfo.MD5Sum
+	}
+
+	put := func() error {
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			pipeWriter.CloseWithError(f.fallback.source.GetObject(bucket, object, 0, objInfo.Size, pipeWriter))
+		}()
+		_, err := f.ObjectLayer.PutObject(bucket, object, objInfo.Size, pipeReader, metadata, "")
+		pipeReader.CloseWithError(err)
+		return err
+	}
+	err := put()
+	if _, ok := errorCause(err).(BucketNotFound); ok {
+		if err = f.ObjectLayer.MakeBucket(bucket); err != nil {
+			if _, ok = errorCause(err).(BucketExists); !ok {
+				return err
+			}
+		}
+		err = put()
+	}
+	return err
+}
+
+// GetObject - reads the object, from the fallback if missing locally.
+func (f fallbackObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	err := f.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	if !isErrFallback(err) || bucket == minioMetaBucket {
+		return err
+	}
+	objInfo, fErr := f.fallback.source.GetObjectInfo(bucket, object)
+	if fErr != nil {
+		return err
+	}
+	if f.fallback.populate {
+		pErr := f.populateObject(bucket, object, objInfo)
+		if pErr == nil {
+			return f.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+		}
+		errorIf(pErr, "Unable to copy %s/%s from the fallback.", bucket, object)
+	}
+	return f.fallback.source.GetObject(bucket, object, startOffset, length, writer)
+}
+
+// GetObjectInfo - returns the info of the object, from the fallback if
+// missing locally.
+func (f fallbackObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := f.ObjectLayer.GetObjectInfo(bucket, object)
+	if !isErrFallback(err) || bucket == minioMetaBucket {
+		return objInfo, err
+	}
+	fObjInfo, fErr := f.fallback.source.GetObjectInfo(bucket, object)
+	if fErr != nil {
+		return objInfo, err
+	}
+	return fObjInfo, nil
+}
+
+// GetBucketInfo - returns the info of the bucket, from the fallback if
+// missing locally.
+func (f fallbackObjects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	bucketInfo, err := f.ObjectLayer.GetBucketInfo(bucket)
+	if !isErrFallback(err) || bucket == minioMetaBucket {
+		return bucketInfo, err
+	}
+	fBucketInfo, fErr := f.fallback.source.GetBucketInfo(bucket)
+	if fErr != nil {
+		return bucketInfo, err
+	}
+	return fBucketInfo, nil
+}
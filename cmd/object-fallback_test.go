@@ -0,0 +1,125 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests parsing MINIO_FALLBACK_POPULATE.
+func TestParseFallbackPopulate(t *testing.T) {
+	testCases := []struct {
+		value    string
+		populate bool
+		success  bool
+	}{
+		{"", false, true},
+		{"off", false, true},
+		{"ON", true, true},
+		{"yes", false, false},
+	}
+	for i, testCase := range testCases {
+		populate, err := parseFallbackPopulate(testCase.value)
+		if testCase.success != (err == nil) || populate != testCase.populate {
+			t.Errorf("Test %d: Expected %v, %v, got %v, %v", i+1, testCase.populate, testCase.success, populate, err)
+		}
+	}
+}
+
+// Tests that objects missing locally are read from the fallback, and
+// copied locally if populating.
+func TestFallbackObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	for _, populate := range []bool{false, true} {
+		primary, primaryDir, err := prepareFS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(primaryDir)
+		source, sourceDir, err := prepareFS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(sourceDir)
+
+		obj := newFallbackObjects(primary, &objectFallback{source: source, populate: populate})
+
+		data := []byte("hello, world")
+		for _, bucket := range []string{"bucket", "migrated"} {
+			if err = source.MakeBucket(bucket); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = source.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": "text/plain"}, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = primary.MakeBucket("bucket"); err != nil {
+			t.Fatal(err)
+		}
+		local := []byte("local")
+		if _, err = primary.PutObject("bucket", "local", int64(len(local)), bytes.NewReader(local), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		// Objects and buckets missing locally are read from the fallback.
+		for _, bucket := range []string{"bucket", "migrated"} {
+			if _, err = obj.GetBucketInfo(bucket); err != nil {
+				t.Errorf("Populate %v: %s: %s", populate, bucket, err)
+			}
+			objInfo, err := obj.GetObjectInfo(bucket, "object")
+			if err != nil {
+				t.Fatalf("Populate %v: %s: %s", populate, bucket, err)
+			}
+			if objInfo.Size != int64(len(data)) || objInfo.ContentType != "text/plain" {
+				t.Errorf("Populate %v: %s: Unexpected object info %v", populate, bucket, objInfo)
+			}
+			var buffer bytes.Buffer
+			if err = obj.GetObject(bucket, "object", 7, 5, &buffer); err != nil {
+				t.Fatalf("Populate %v: %s: %s", populate, bucket, err)
+			}
+			if buffer.String() != "world" {
+				t.Errorf("Populate %v: %s: Expected %q, got %q", populate, bucket, "world", buffer.String())
+			}
+			objInfo, err = primary.GetObjectInfo(bucket, "object")
+			if populate != (err == nil) {
+				t.Errorf("Populate %v: %s: Unexpected local object %v, %v", populate, bucket, objInfo, err)
+			}
+			if populate && (objInfo.MD5Sum != getMD5Hash(data) || objInfo.ContentType != "text/plain") {
+				t.Errorf("Populate %v: %s: Unexpected local object %v", populate, bucket, objInfo)
+			}
+		}
+
+		// Local objects are read locally, objects missing on both are
+		// reported missing.
+		var buffer bytes.Buffer
+		if err = obj.GetObject("bucket", "local", 0, int64(len(local)), &buffer); err != nil || buffer.String() != "local" {
+			t.Errorf("Populate %v: Expected %q, got %q, %v", populate, "local", buffer.String(), err)
+		}
+		if err = obj.GetObject("bucket", "missing", 0, 1, &buffer); !isErrObjectNotFound(err) {
+			t.Errorf("Populate %v: Expected ObjectNotFound, got %v", populate, err)
+		}
+		if _, err = obj.GetBucketInfo("missing"); !isSameType(errorCause(err), BucketNotFound{}) {
+			t.Errorf("Populate %v: Expected BucketNotFound, got %v", populate, err)
+		}
+	}
+}
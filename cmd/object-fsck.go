@@ -0,0 +1,268 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// Temporary files untouched for this long belong to writes which
+// never completed.
+const fsckTempFileAge = 24 * time.Hour
+
+// Set while a consistency check runs.
+var globalFsckRunning int32
+
+// FsckObject - an inconsistent object and the repair applied.
+type FsckObject struct {
+	Bucket   string   `json:"bucket"`
+	Object   string   `json:"object"`
+	Problems []string `json:"problems"`
+	// One of healed, quarantined or unrepaired, empty unless a
+	// repair was requested.
+	Action string `json:"action,omitempty"`
+}
+
+// FsckInfo - summary of a consistency check of the backend.
+type FsckInfo struct {
+	Objects         int          `json:"objects"`
+	TempFilesPurged int          `json:"tempFilesPurged"`
+	Healed          int          `json:"healed"`
+	Quarantined     int          `json:"quarantined"`
+	Inconsistent    []FsckObject `json:"inconsistent"`
+}
+
+// fsckObjectLayer - checks every object of every bucket against its
+// metadata and checksums. With repair, stale temporary files are
+// removed, inconsistent objects are healed where the backend supports
+// it and objects whose data the backend reports as damaged are moved
+// to quarantine.
+func fsckObjectLayer(objAPI ObjectLayer, repair bool) (FsckInfo, error) {
+	if !atomic.CompareAndSwapInt32(&globalFsckRunning, 0, 1) {
+		return FsckInfo{}, errFsckInProgress
+	}
+	defer atomic.StoreInt32(&globalFsckRunning, 0)
+
+	info := FsckInfo{Inconsistent: []FsckObject{}}
+	if repair {
+		purged, err := objAPI.PurgeTempFiles(fsckTempFileAge)
+		if err != nil {
+			return FsckInfo{}, err
+		}
+		info.TempFilesPurged = purged
+	}
+
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return FsckInfo{}, err
+	}
+	check := func(bucket, object string) error {
+		result, err := fsckObject(objAPI, bucket, object, repair)
+		if err != nil {
+			if isErrObjectNotFound(err) {
+				// Removed since listed.
+				return nil
+			}
+			return err
+		}
+		info.Objects++
+		if result == nil {
+			return nil
+		}
+		switch result.Action {
+		case "healed":
+			info.Healed++
+		case "quarantined":
+			info.Quarantined++
+		}
+		info.Inconsistent = append(info.Inconsistent, *result)
+		return nil
+	}
+	for _, bucket := range buckets {
+		// Objects with metadata missing on some disks, the regular
+		// listing of XL may not see them.
+		healCandidates := make(map[string]bool)
+		marker := ""
+		for {
+			lo, err := objAPI.ListObjectsHeal(bucket.Name, "", marker, "", 1000)
+			if err != nil {
+				if _, ok := errorCause(err).(NotImplemented); ok {
+					break
+				}
+				return FsckInfo{}, err
+			}
+			for _, obj := range lo.Objects {
+				healCandidates[obj.Name] = true
+				if err = check(bucket.Name, obj.Name); err != nil {
+					return FsckInfo{}, err
+				}
+			}
+			if !lo.IsTruncated {
+				break
+			}
+			marker = lo.NextMarker
+		}
+
+		marker = ""
+		for {
+			lo, err := objAPI.ListObjects(bucket.Name, "", marker, "", 1000)
+			if err != nil {
+				return FsckInfo{}, err
+			}
+			for _, obj := range lo.Objects {
+				if healCandidates[obj.Name] {
+					continue
+				}
+				if err = check(bucket.Name, obj.Name); err != nil {
+					return FsckInfo{}, err
+				}
+			}
+			if !lo.IsTruncated {
+				break
+			}
+			marker = lo.NextMarker
+		}
+	}
+	return info, nil
+}
+
+// fsckObject - checks an object and repairs it if requested, returns
+// nil if the object is consistent.
+func fsckObject(objAPI ObjectLayer, bucket, object string, repair bool) (*FsckObject, error) {
+	problems, damaged, err := checkObjectConsistency(objAPI, bucket, object)
+	if err != nil || len(problems) == 0 {
+		return nil, err
+	}
+	result := &FsckObject{
+		Bucket:   bucket,
+		Object:   object,
+		Problems: problems,
+	}
+	if !repair {
+		return result, nil
+	}
+
+	// Heal is not implemented by every backend, the check below
+	// tells whether it helped.
+	if err = objAPI.HealObject(bucket, object); err == nil {
+		var remaining []string
+		if remaining, damaged, err = checkObjectConsistency(objAPI, bucket, object); err != nil {
+			return nil, err
+		}
+		if len(remaining) == 0 {
+			result.Action = "healed"
+			return result, nil
+		}
+	}
+	if !damaged {
+		// E.g. offline disks, nothing is lost, or an ETag mismatch
+		// the backend has no evidence of.
+		result.Action = "unrepaired"
+		return result, nil
+	}
+	if err = objAPI.QuarantineObject(bucket, object); err != nil {
+		return nil, err
+	}
+	result.Action = "quarantined"
+	return result, nil
+}
+
+// isBackendDamage - returns true if a problem reported by the backend
+// shows that data was lost.
+func isBackendDamage(problem string) bool {
+	return strings.HasSuffix(problem, bitrotProblem) || strings.HasSuffix(problem, incompleteWriteProblem)
+}
+
+// checkObjectConsistency - returns the problems found with an object
+// and whether its data is damaged beyond what healing can fix. Only
+// the backend tells that, bitrot or an incomplete write. An ETag which
+// does not match the data read back is reported, but may as well come
+// from a layer above the backend.
+func checkObjectConsistency(objAPI ObjectLayer, bucket, object string) (problems []string, damaged bool, err error) {
+	if problems, err = objAPI.VerifyObject(bucket, object); err != nil {
+		if _, ok := errorCause(err).(InsufficientReadQuorum); ok {
+			return []string{"too many disks offline to verify"}, false, nil
+		}
+		return nil, false, err
+	}
+	for _, problem := range problems {
+		if isBackendDamage(problem) {
+			damaged = true
+		}
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		if isErrObjectNotFound(err) && len(problems) > 0 {
+			// Metadata of incomplete writes may not be found.
+			return append(problems, "unable to read object metadata"), true, nil
+		}
+		return nil, false, err
+	}
+	problem, err := checkObjectData(objAPI, objInfo)
+	if err != nil {
+		if _, ok := errorCause(err).(InsufficientReadQuorum); ok {
+			return append(problems, "too many disks offline to read"), damaged, nil
+		}
+		return nil, false, err
+	}
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	return problems, damaged, nil
+}
+
+// parseFsckEnv - parses the value of MINIO_FSCK, one of "off", "check"
+// or "repair".
+func parseFsckEnv(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return "", nil
+	case "check", "repair":
+		return strings.ToLower(value), nil
+	}
+	return "", fmt.Errorf("Unknown value `%s`, expected `off`, `check` or `repair`", value)
+}
+
+// startFsck - runs a consistency check in the background if enabled
+// with MINIO_FSCK, and prints a summary when done.
+func startFsck(objAPI ObjectLayer) {
+	if globalFsckMode == "" {
+		return
+	}
+	go func() {
+		// Inconsistencies are only reported while read-only.
+		info, err := fsckObjectLayer(objAPI, globalFsckMode == "repair" && !globalIsReadOnly)
+		if err != nil {
+			errorIf(err, "Unable to check backend consistency.")
+			return
+		}
+		if globalQuiet {
+			return
+		}
+		for _, obj := range info.Inconsistent {
+			console.Printf("Inconsistent object %s/%s: %s %s\n", obj.Bucket, obj.Object,
+				strings.Join(obj.Problems, ", "), obj.Action)
+		}
+		console.Printf("Consistency check: %d objects checked, %d inconsistent, %d healed, %d quarantined, %d temporary files purged.\n",
+			info.Objects, len(info.Inconsistent), info.Healed, info.Quarantined, info.TempFilesPurged)
+	}()
+}
@@ -0,0 +1,161 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Tests checking and repairing the consistency of the backend.
+func TestFsckObjectLayer(t *testing.T) {
+	ExecObjectLayerTest(t, testFsckObjectLayer)
+}
+
+func testFsckObjectLayer(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "fsck-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	for _, object := range []string{"intact", "damaged", "outdated"} {
+		if _, err := obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object %s: %v", instanceType, object, err)
+		}
+	}
+
+	// FS objects are damaged by overwriting their data, which only
+	// shows as an ETag mismatch and is reported. XL objects are damaged
+	// by removing xl.json from all but one disk as if the write never
+	// completed, which XL reports and quarantines. XL objects missing
+	// on one disk are healed.
+	var disks []StorageAPI
+	var expected map[string]string
+	switch o := obj.(type) {
+	case fsObjects:
+		disks = []StorageAPI{o.storage}
+		expected = map[string]string{"damaged": "unrepaired"}
+		if err := o.storage.DeleteFile(bucket, "damaged"); err != nil {
+			t.Fatalf("%s: Unable to delete object data: %v", instanceType, err)
+		}
+		if err := o.storage.AppendFile(bucket, "damaged", bytes.Repeat([]byte("b"), len(data))); err != nil {
+			t.Fatalf("%s: Unable to damage object: %v", instanceType, err)
+		}
+	case *xlObjects:
+		disks = o.storageDisks
+		expected = map[string]string{"damaged": "quarantined"}
+		for _, disk := range o.storageDisks[1:] {
+			if err := disk.DeleteFile(bucket, pathJoin("damaged", xlMetaJSONFile)); err != nil {
+				t.Fatalf("%s: Unable to damage object: %v", instanceType, err)
+			}
+		}
+		if err := cleanupDir(o.storageDisks[0], bucket, "outdated"); err != nil {
+			t.Fatalf("%s: Unable to remove object from disk: %v", instanceType, err)
+		}
+		expected["outdated"] = "healed"
+	default:
+		t.Fatalf("%s: Unexpected object layer %T", instanceType, obj)
+	}
+
+	info, err := fsckObjectLayer(obj, false)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if info.Objects != 3 || len(info.Inconsistent) != len(expected) {
+		t.Fatalf("%s: Unexpected check result %+v", instanceType, info)
+	}
+	for _, result := range info.Inconsistent {
+		if result.Action != "" {
+			t.Errorf("%s: Expected no repair of %s, got %s", instanceType, result.Object, result.Action)
+		}
+	}
+
+	// A temporary file left behind by an interrupted write.
+	if err = disks[0].AppendFile(minioMetaTmpBucket, "stale", data); err != nil {
+		t.Fatalf("%s: Unable to create temporary file: %v", instanceType, err)
+	}
+	if purged, err := obj.PurgeTempFiles(time.Hour); err != nil || purged != 0 {
+		t.Errorf("%s: Expected recent temporary file to be kept, purged %d, %v", instanceType, purged, err)
+	}
+	if purged, err := obj.PurgeTempFiles(0); err != nil || purged != 1 {
+		t.Errorf("%s: Expected temporary file to be purged, purged %d, %v", instanceType, purged, err)
+	}
+
+	info, err = fsckObjectLayer(obj, true)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(info.Inconsistent) != len(expected) {
+		t.Fatalf("%s: Unexpected repair result %+v", instanceType, info)
+	}
+	for _, result := range info.Inconsistent {
+		if result.Action != expected[result.Object] {
+			t.Errorf("%s: Expected %s to be %s, got %s", instanceType, result.Object, expected[result.Object], result.Action)
+		}
+	}
+	if expected["damaged"] == "unrepaired" {
+		if _, err = obj.GetObjectInfo(bucket, "damaged"); err != nil {
+			t.Errorf("%s: Expected reported object to be kept, got %v", instanceType, err)
+		}
+		if info.Quarantined != 0 {
+			t.Errorf("%s: Expected no object in quarantine, got %d", instanceType, info.Quarantined)
+		}
+		return
+	}
+	if _, err = obj.GetObjectInfo(bucket, "damaged"); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected quarantined object to be gone, got %v", instanceType, err)
+	}
+	if entries, err := disks[0].ListDir(minioMetaBucket, pathJoin(quarantineMetaPrefix, bucket, "damaged")+"/"); err != nil || len(entries) != 1 {
+		t.Errorf("%s: Expected object in quarantine, got %v, %v", instanceType, entries, err)
+	}
+
+	info, err = fsckObjectLayer(obj, false)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if info.Objects != 2 || len(info.Inconsistent) != 0 {
+		t.Errorf("%s: Expected consistent backend after repair, got %+v", instanceType, info)
+	}
+}
+
+// Tests parsing of MINIO_FSCK.
+func TestParseFsckEnv(t *testing.T) {
+	testCases := []struct {
+		value      string
+		mode       string
+		shouldPass bool
+	}{
+		{"", "", true},
+		{"off", "", true},
+		{"check", "check", true},
+		{"REPAIR", "repair", true},
+		{"on", "", false},
+	}
+	for i, testCase := range testCases {
+		mode, err := parseFsckEnv(testCase.value)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if mode != testCase.mode {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.mode, mode)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	mux "github.com/gorilla/mux"
+	"github.com/minio/minio/pkg/mimedb"
+)
+
+// ArchiveMember - a single file stored in an archive object.
+type ArchiveMember struct {
+	Name         string
+	LastModified string
+	Size         int64
+}
+
+// ListArchiveResponse - format for list archive response.
+type ListArchiveResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListArchiveResult" json:"-"`
+
+	Bucket string
+	Key    string
+
+	// List of files in the archive.
+	Members []ArchiveMember `xml:"Member"`
+}
+
+// objectReaderAt - implements io.ReaderAt over an object, every read
+// is served by a ranged GetObject.
+type objectReaderAt struct {
+	objectAPI ObjectLayer
+	bucket    string
+	object    string
+	size      int64
+}
+
+func (o *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= o.size {
+		return 0, io.EOF
+	}
+	length := int64(len(p))
+	if off+length > o.size {
+		length = o.size - off
+	}
+	buf := bytes.NewBuffer(p[:0])
+	if err := o.objectAPI.GetObject(o.bucket, o.object, off, length, buf); err != nil {
+		return 0, err
+	}
+	n := copy(p, buf.Bytes())
+	if int64(n) < int64(len(p)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// isTarArchive - tar archives are recognized by their extension, all
+// other objects are read as zip archives.
+func isTarArchive(object string) bool {
+	object = strings.ToLower(object)
+	return strings.HasSuffix(object, ".tar") || isGzipTarArchive(object)
+}
+
+// isGzipTarArchive - returns true for gzip compressed tar archives.
+func isGzipTarArchive(object string) bool {
+	object = strings.ToLower(object)
+	return strings.HasSuffix(object, ".tar.gz") || strings.HasSuffix(object, ".tgz")
+}
+
+// walkTarArchive - calls fn for every regular file in a tar archive
+// object until fn returns false.
+func walkTarArchive(objectAPI ObjectLayer, bucket, object string, size int64, fn func(hdr *tar.Header, r io.Reader) bool) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(objectAPI.GetObject(bucket, object, 0, size, pw))
+	}()
+	defer pr.Close()
+
+	var reader io.Reader = pr
+	if isGzipTarArchive(object) {
+		gr, err := gzip.NewReader(pr)
+		if err != nil {
+			return errInvalidArchive
+		}
+		reader = gr
+	}
+	tr := tar.NewReader(reader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if _, ok := errorCause(err).(ObjectNotFound); ok {
+				return err
+			}
+			return errInvalidArchive
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if !fn(hdr, tr) {
+			return nil
+		}
+	}
+}
+
+// GetObjectArchiveHandler - GET Object?archive[=member]
+// ----------
+// This operation lists the files stored in a zip or tar object when
+// archive is empty, otherwise it returns the file named by archive
+// without the client downloading the whole archive.
+func (api objectAPIHandlers) GetObjectArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	member := strings.TrimPrefix(r.URL.Query().Get("archive"), "/")
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:GetObject", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Encrypted archives can not be read without their key.
+	if isEncryptedObject(objInfo) {
+		writeErrorResponse(w, r, ErrSSEEncryptedObject, r.URL.Path)
+		return
+	}
+
+	if isTarArchive(object) {
+		api.serveTarArchive(w, r, objInfo, member)
+		return
+	}
+
+	zr, err := zip.NewReader(&objectReaderAt{objectAPI, bucket, object, objInfo.Size}, objInfo.Size)
+	if err != nil {
+		if err == zip.ErrFormat || err == io.ErrUnexpectedEOF {
+			err = errInvalidArchive
+		}
+		errorIf(err, "Unable to read archive.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if member == "" {
+		listResponse := ListArchiveResponse{Bucket: bucket, Key: object}
+		for _, file := range zr.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			listResponse.Members = append(listResponse.Members, ArchiveMember{
+				Name:         file.Name,
+				LastModified: file.ModTime().UTC().Format(timeFormatAMZLong),
+				Size:         int64(file.UncompressedSize64),
+			})
+		}
+		writeSuccessResponse(w, encodeResponse(listResponse))
+		return
+	}
+
+	for _, file := range zr.File {
+		if file.Name != member || file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			errorIf(err, "Unable to open archive member.")
+			writeErrorResponse(w, r, ErrInvalidArchive, r.URL.Path)
+			return
+		}
+		defer rc.Close()
+		writeArchiveMember(w, member, int64(file.UncompressedSize64), file.ModTime(), rc)
+		return
+	}
+	writeErrorResponse(w, r, ErrNoSuchKey, r.URL.Path)
+}
+
+// serveTarArchive - lists or serves a member of a tar archive, tar
+// archives have no index so the archive is read up to the member.
+func (api objectAPIHandlers) serveTarArchive(w http.ResponseWriter, r *http.Request, objInfo ObjectInfo, member string) {
+	objectAPI := api.ObjectAPI()
+	listResponse := ListArchiveResponse{Bucket: objInfo.Bucket, Key: objInfo.Name}
+	found := false
+	err := walkTarArchive(objectAPI, objInfo.Bucket, objInfo.Name, objInfo.Size, func(hdr *tar.Header, tr io.Reader) bool {
+		if member == "" {
+			listResponse.Members = append(listResponse.Members, ArchiveMember{
+				Name:         hdr.Name,
+				LastModified: hdr.ModTime.UTC().Format(timeFormatAMZLong),
+				Size:         hdr.Size,
+			})
+			return true
+		}
+		if strings.TrimPrefix(hdr.Name, "./") != member {
+			return true
+		}
+		found = true
+		writeArchiveMember(w, member, hdr.Size, hdr.ModTime, tr)
+		return false
+	})
+	if found {
+		return
+	}
+	if err != nil {
+		errorIf(err, "Unable to read archive.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if member != "" {
+		writeErrorResponse(w, r, ErrNoSuchKey, r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, encodeResponse(listResponse))
+}
+
+// writeArchiveMember - writes the contents of a single archive member.
+func writeArchiveMember(w http.ResponseWriter, name string, size int64, modTime time.Time, reader io.Reader) {
+	setCommonHeaders(w)
+	contentType := "application/octet-stream"
+	if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))]; ok {
+		contentType = content.ContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, reader); err != nil {
+		errorIf(err, "Unable to write archive member to client.")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Wrapper for calling GetObjectArchive HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIGetObjectArchiveHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIGetObjectArchiveHandler, []string{"GetObjectArchive"})
+}
+
+func testAPIGetObjectArchiveHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	files := []struct {
+		name, body string
+	}{
+		{"readme.txt", "hello world"},
+		{"dir/data.json", `{"a":1}`},
+	}
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(file.body))
+	}
+	zw.Close()
+
+	var tarData bytes.Buffer
+	gw := gzip.NewWriter(&tarData)
+	tw := tar.NewWriter(gw)
+	for _, file := range files {
+		tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.body))})
+		tw.Write([]byte(file.body))
+	}
+	tw.Close()
+	gw.Close()
+
+	objects := map[string][]byte{
+		"files.zip":    zipData.Bytes(),
+		"files.tar.gz": tarData.Bytes(),
+		"plain.txt":    []byte("not an archive"),
+	}
+	for objectName, data := range objects {
+		if _, err := obj.PutObject(bucketName, objectName, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+		}
+	}
+
+	testCases := []struct {
+		objectName         string
+		member             string
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+		expectedBody       string
+	}{
+		// Test case - 1.
+		// Read a member of a zip archive.
+		{"files.zip", "dir/data.json", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK, `{"a":1}`},
+		// Test case - 2.
+		// Read a member of a gzip compressed tar archive.
+		{"files.tar.gz", "readme.txt", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK, "hello world"},
+		// Test case - 3.
+		// Non-existent member.
+		{"files.zip", "missing.txt", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound, ""},
+		{"files.tar.gz", "missing.txt", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound, ""},
+		// Test case - 5.
+		// Object is not an archive.
+		{"plain.txt", "readme.txt", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest, ""},
+		// Test case - 6.
+		// Non-existent object.
+		{"missing.zip", "readme.txt", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound, ""},
+		// Test case - 7.
+		// Invalid credentials.
+		{"files.zip", "readme.txt", "Invalid-AccessID", credentials.SecretAccessKey, http.StatusForbidden, ""},
+	}
+
+	for i, testCase := range testCases {
+		queryValues := url.Values{}
+		queryValues.Set("archive", testCase.member)
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			0, nil, testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code == http.StatusOK && rec.Body.String() != testCase.expectedBody {
+			t.Errorf("Test %d: %s: Expected body %q, got %q", i+1, instanceType, testCase.expectedBody, rec.Body.String())
+		}
+	}
+
+	// List the members of both archives.
+	for _, objectName := range []string{"files.zip", "files.tar.gz"} {
+		queryValues := url.Values{}
+		queryValues.Set("archive", "")
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, objectName, queryValues),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %s: Expected the response status to be `%d`, but instead found `%d`", instanceType, objectName, http.StatusOK, rec.Code)
+		}
+		listResponse := ListArchiveResponse{}
+		if err = xml.Unmarshal(rec.Body.Bytes(), &listResponse); err != nil {
+			t.Fatalf("%s: %s: Unable to decode list response: %v", instanceType, objectName, err)
+		}
+		if len(listResponse.Members) != len(files) {
+			t.Fatalf("%s: %s: Expected %d members, got %d", instanceType, objectName, len(files), len(listResponse.Members))
+		}
+		for i, member := range listResponse.Members {
+			if member.Name != files[i].name || member.Size != int64(len(files[i].body)) {
+				t.Errorf("%s: %s: Unexpected member %+v", instanceType, objectName, member)
+			}
+		}
+	}
+}
@@ -29,7 +29,7 @@ import (
 //  x-amz-copy-source-if-match
 //  x-amz-copy-source-if-none-match
 func checkCopyObjectPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectInfo) bool {
-	// Return false for methods other than GET and HEAD.
+	// Return false for methods other than PUT.
 	if r.Method != "PUT" {
 		return false
 	}
@@ -52,10 +52,14 @@ func checkCopyObjectPreconditions(w http.ResponseWriter, r *http.Request, objInf
 			w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 		}
 	}
+	ifMatchETagHeader := r.Header.Get("x-amz-copy-source-if-match")
+	ifNoneMatchETagHeader := r.Header.Get("x-amz-copy-source-if-none-match")
+
 	// x-amz-copy-source-if-modified-since: Return the object only if it has been modified
-	// since the specified time otherwise return 412 (precondition failed).
+	// since the specified time otherwise return 412 (precondition failed). Like S3 it is
+	// ignored when x-amz-copy-source-if-none-match is present, which decides alone.
 	ifModifiedSinceHeader := r.Header.Get("x-amz-copy-source-if-modified-since")
-	if ifModifiedSinceHeader != "" {
+	if ifModifiedSinceHeader != "" && ifNoneMatchETagHeader == "" {
 		if !ifModifiedSince(objInfo.ModTime, ifModifiedSinceHeader) {
 			// If the object is not modified since the specified time.
 			writeHeaders()
@@ -66,8 +70,9 @@ func checkCopyObjectPreconditions(w http.ResponseWriter, r *http.Request, objInf
 
 	// x-amz-copy-source-if-unmodified-since : Return the object only if it has not been
 	// modified since the specified time, otherwise return a 412 (precondition failed).
+	// Like S3 it is ignored when x-amz-copy-source-if-match is present, which decides alone.
 	ifUnmodifiedSinceHeader := r.Header.Get("x-amz-copy-source-if-unmodified-since")
-	if ifUnmodifiedSinceHeader != "" {
+	if ifUnmodifiedSinceHeader != "" && ifMatchETagHeader == "" {
 		if ifModifiedSince(objInfo.ModTime, ifUnmodifiedSinceHeader) {
 			// If the object is modified since the specified time.
 			writeHeaders()
@@ -78,7 +83,6 @@ func checkCopyObjectPreconditions(w http.ResponseWriter, r *http.Request, objInf
 
 	// x-amz-copy-source-if-match : Return the object only if its entity tag (ETag) is the
 	// same as the one specified; otherwise return a 412 (precondition failed).
-	ifMatchETagHeader := r.Header.Get("x-amz-copy-source-if-match")
 	if ifMatchETagHeader != "" {
 		if objInfo.MD5Sum != "" && !isETagEqual(objInfo.MD5Sum, ifMatchETagHeader) {
 			// If the object ETag does not match with the specified ETag.
@@ -88,9 +92,8 @@ func checkCopyObjectPreconditions(w http.ResponseWriter, r *http.Request, objInf
 		}
 	}
 
-	// If-None-Match : Return the object only if its entity tag (ETag) is different from the
-	// one specified otherwise, return a 304 (not modified).
-	ifNoneMatchETagHeader := r.Header.Get("x-amz-copy-source-if-none-match")
+	// x-amz-copy-source-if-none-match : Return the object only if its entity tag (ETag) is
+	// different from the one specified otherwise, return a 412 (precondition failed).
 	if ifNoneMatchETagHeader != "" {
 		if objInfo.MD5Sum != "" && isETagEqual(objInfo.MD5Sum, ifNoneMatchETagHeader) {
 			// If the object ETag matches with the specified ETag.
@@ -133,10 +136,14 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 			w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 		}
 	}
+	ifMatchETagHeader := r.Header.Get("If-Match")
+	ifNoneMatchETagHeader := r.Header.Get("If-None-Match")
+
 	// If-Modified-Since : Return the object only if it has been modified since the specified time,
-	// otherwise return a 304 (not modified).
+	// otherwise return a 304 (not modified). Like S3 it is ignored when If-None-Match is present,
+	// which decides alone.
 	ifModifiedSinceHeader := r.Header.Get("If-Modified-Since")
-	if ifModifiedSinceHeader != "" {
+	if ifModifiedSinceHeader != "" && ifNoneMatchETagHeader == "" {
 		if !ifModifiedSince(objInfo.ModTime, ifModifiedSinceHeader) {
 			// If the object is not modified since the specified time.
 			writeHeaders()
@@ -146,9 +153,10 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 	}
 
 	// If-Unmodified-Since : Return the object only if it has not been modified since the specified
-	// time, otherwise return a 412 (precondition failed).
+	// time, otherwise return a 412 (precondition failed). Like S3 it is ignored when If-Match is
+	// present, which decides alone.
 	ifUnmodifiedSinceHeader := r.Header.Get("If-Unmodified-Since")
-	if ifUnmodifiedSinceHeader != "" {
+	if ifUnmodifiedSinceHeader != "" && ifMatchETagHeader == "" {
 		if ifModifiedSince(objInfo.ModTime, ifUnmodifiedSinceHeader) {
 			// If the object is modified since the specified time.
 			writeHeaders()
@@ -157,11 +165,10 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 		}
 	}
 
-	// If-Match : Return the object only if its entity tag (ETag) is the same as the one specified;
+	// If-Match : Return the object only if its entity tag (ETag) is one of those specified;
 	// otherwise return a 412 (precondition failed).
-	ifMatchETagHeader := r.Header.Get("If-Match")
 	if ifMatchETagHeader != "" {
-		if !isETagEqual(objInfo.MD5Sum, ifMatchETagHeader) {
+		if !isETagInList(objInfo.MD5Sum, ifMatchETagHeader) {
 			// If the object ETag does not match with the specified ETag.
 			writeHeaders()
 			writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
@@ -169,11 +176,10 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 		}
 	}
 
-	// If-None-Match : Return the object only if its entity tag (ETag) is different from the
-	// one specified otherwise, return a 304 (not modified).
-	ifNoneMatchETagHeader := r.Header.Get("If-None-Match")
+	// If-None-Match : Return the object only if its entity tag (ETag) is different from those
+	// specified otherwise, return a 304 (not modified).
 	if ifNoneMatchETagHeader != "" {
-		if isETagEqual(objInfo.MD5Sum, ifNoneMatchETagHeader) {
+		if isETagInList(objInfo.MD5Sum, ifNoneMatchETagHeader) {
 			// If the object ETag matches with the specified ETag.
 			writeHeaders()
 			w.WriteHeader(http.StatusNotModified)
@@ -184,6 +190,21 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 	return false
 }
 
+// isCopyConditional - returns true if any x-amz-copy-source-if-* header is set.
+func isCopyConditional(r *http.Request) bool {
+	for _, header := range []string{
+		"x-amz-copy-source-if-modified-since",
+		"x-amz-copy-source-if-unmodified-since",
+		"x-amz-copy-source-if-match",
+		"x-amz-copy-source-if-none-match",
+	} {
+		if r.Header.Get(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // returns true if object was modified after givenTime.
 func ifModifiedSince(objTime time.Time, givenTimeStr string) bool {
 	givenTime, err := time.Parse(http.TimeFormat, givenTimeStr)
@@ -210,3 +231,19 @@ func canonicalizeETag(etag string) string {
 func isETagEqual(left, right string) bool {
 	return canonicalizeETag(left) == canonicalizeETag(right)
 }
+
+// isETagInList returns true if etag is one of the comma separated ETags of an
+// If-Match or If-None-Match header, or if the header is "*". Weak ETags are
+// compared as strong ones since objects have a single representation.
+func isETagInList(etag, list string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if isETagEqual(etag, tag) {
+			return true
+		}
+	}
+	return false
+}
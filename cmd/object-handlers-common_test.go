@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2015, 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests evaluating the x-amz-copy-source-if-* headers.
+func TestCheckCopyObjectPreconditions(t *testing.T) {
+	modTime := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	objInfo := ObjectInfo{ModTime: modTime, MD5Sum: "e2fc714c4727ee9395f324cd2e7f331f"}
+	before := modTime.Add(-time.Hour).Format(http.TimeFormat)
+	after := modTime.Add(time.Hour).Format(http.TimeFormat)
+
+	testCases := []struct {
+		headers map[string]string
+		failed  bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"x-amz-copy-source-if-match": `"e2fc714c4727ee9395f324cd2e7f331f"`}, false},
+		{map[string]string{"x-amz-copy-source-if-match": "other"}, true},
+		{map[string]string{"x-amz-copy-source-if-none-match": "e2fc714c4727ee9395f324cd2e7f331f"}, true},
+		{map[string]string{"x-amz-copy-source-if-none-match": "other"}, false},
+		{map[string]string{"x-amz-copy-source-if-modified-since": before}, false},
+		{map[string]string{"x-amz-copy-source-if-modified-since": after}, true},
+		{map[string]string{"x-amz-copy-source-if-unmodified-since": after}, false},
+		{map[string]string{"x-amz-copy-source-if-unmodified-since": before}, true},
+		// A matching ETag wins over a failed unmodified-since.
+		{map[string]string{
+			"x-amz-copy-source-if-match":            "e2fc714c4727ee9395f324cd2e7f331f",
+			"x-amz-copy-source-if-unmodified-since": before,
+		}, false},
+		// A matching ETag fails even if modified since.
+		{map[string]string{
+			"x-amz-copy-source-if-none-match":     "e2fc714c4727ee9395f324cd2e7f331f",
+			"x-amz-copy-source-if-modified-since": before,
+		}, true},
+		// A different ETag wins over a failed modified-since.
+		{map[string]string{
+			"x-amz-copy-source-if-none-match":     "other",
+			"x-amz-copy-source-if-modified-since": after,
+		}, false},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest("PUT", "http://127.0.0.1:9000/bucket/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for header, value := range testCase.headers {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		failed := checkCopyObjectPreconditions(rec, req, objInfo)
+		if failed != testCase.failed {
+			t.Errorf("Test %d: Expected failed %v, got %v", i+1, testCase.failed, failed)
+		}
+		if failed && rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, http.StatusPreconditionFailed, rec.Code)
+		}
+		if isCopyConditional(req) != (len(testCase.headers) > 0) {
+			t.Errorf("Test %d: Unexpected conditional copy %v", i+1, isCopyConditional(req))
+		}
+	}
+}
+
+// Tests evaluating the If-* headers of GET and HEAD.
+func TestCheckPreconditions(t *testing.T) {
+	modTime := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	objInfo := ObjectInfo{ModTime: modTime, MD5Sum: "e2fc714c4727ee9395f324cd2e7f331f"}
+	before := modTime.Add(-time.Hour).Format(http.TimeFormat)
+	after := modTime.Add(time.Hour).Format(http.TimeFormat)
+
+	testCases := []struct {
+		headers map[string]string
+		status  int
+	}{
+		{map[string]string{}, http.StatusOK},
+		{map[string]string{"If-Match": `"e2fc714c4727ee9395f324cd2e7f331f"`}, http.StatusOK},
+		{map[string]string{"If-Match": `"other", "e2fc714c4727ee9395f324cd2e7f331f"`}, http.StatusOK},
+		{map[string]string{"If-Match": "*"}, http.StatusOK},
+		{map[string]string{"If-Match": "other"}, http.StatusPreconditionFailed},
+		{map[string]string{"If-None-Match": "e2fc714c4727ee9395f324cd2e7f331f"}, http.StatusNotModified},
+		{map[string]string{"If-None-Match": `"other", W/"e2fc714c4727ee9395f324cd2e7f331f"`}, http.StatusNotModified},
+		{map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{map[string]string{"If-None-Match": "other"}, http.StatusOK},
+		{map[string]string{"If-Modified-Since": before}, http.StatusOK},
+		{map[string]string{"If-Modified-Since": after}, http.StatusNotModified},
+		{map[string]string{"If-Unmodified-Since": after}, http.StatusOK},
+		{map[string]string{"If-Unmodified-Since": before}, http.StatusPreconditionFailed},
+		// A matching ETag wins over a failed unmodified-since.
+		{map[string]string{
+			"If-Match":            "e2fc714c4727ee9395f324cd2e7f331f",
+			"If-Unmodified-Since": before,
+		}, http.StatusOK},
+		// A matching ETag is not modified even if modified since.
+		{map[string]string{
+			"If-None-Match":     "e2fc714c4727ee9395f324cd2e7f331f",
+			"If-Modified-Since": before,
+		}, http.StatusNotModified},
+		// A different ETag wins over a failed modified-since.
+		{map[string]string{
+			"If-None-Match":     "other",
+			"If-Modified-Since": after,
+		}, http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		for _, method := range []string{"GET", "HEAD"} {
+			req, err := http.NewRequest(method, "http://127.0.0.1:9000/bucket/object", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for header, value := range testCase.headers {
+				req.Header.Set(header, value)
+			}
+			rec := httptest.NewRecorder()
+			if !checkPreconditions(rec, req, objInfo) {
+				rec.WriteHeader(http.StatusOK)
+			}
+			if rec.Code != testCase.status {
+				t.Errorf("Test %d: Expected %s status %d, got %d", i+1, method, testCase.status, rec.Code)
+			}
+		}
+	}
+}
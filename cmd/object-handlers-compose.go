@@ -0,0 +1,277 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	mux "github.com/gorilla/mux"
+)
+
+// Maximum number of source objects in a single compose request.
+const maxComposeSources = 32
+
+// errComposeSourceChanged - a source with an ETag precondition was
+// replaced while it was read.
+var errComposeSourceChanged = errors.New("Source object was replaced during compose")
+
+// ComposeSource - an object concatenated by ComposeObject.
+type ComposeSource struct {
+	// Defaults to the bucket of the composed object.
+	Bucket string
+	Object string
+	// The request fails with PreconditionFailed unless the source
+	// has this ETag, optional.
+	ETag string
+}
+
+// composeWriter - writes the composed data, holding back its last
+// byte until flushed. The object being created is then never complete
+// before all sources have been verified.
+type composeWriter struct {
+	writer io.Writer
+	last   [1]byte
+	held   bool
+}
+
+func (c *composeWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := c.flush(); err != nil {
+		return 0, err
+	}
+	if _, err := c.writer.Write(p[:len(p)-1]); err != nil {
+		return 0, err
+	}
+	c.last[0] = p[len(p)-1]
+	c.held = true
+	return len(p), nil
+}
+
+// flush - writes the byte held back.
+func (c *composeWriter) flush() error {
+	if !c.held {
+		return nil
+	}
+	c.held = false
+	_, err := c.writer.Write(c.last[:])
+	return err
+}
+
+// readComposeSource - writes the data of source to writer, verifying
+// that it still has etag if not empty.
+func readComposeSource(objectAPI ObjectLayer, source ObjectInfo, etag string, writer io.Writer) error {
+	if etag == "" {
+		return objectAPI.GetObject(source.Bucket, source.Name, 0, source.Size, writer)
+	}
+
+	// ETags of multipart objects are not the md5sum of their data,
+	// whether they were replaced is checked once read.
+	if strings.Contains(etag, "-") {
+		if err := objectAPI.GetObject(source.Bucket, source.Name, 0, source.Size, writer); err != nil {
+			return err
+		}
+		objInfo, err := objectAPI.GetObjectInfo(source.Bucket, source.Name)
+		if err != nil {
+			return err
+		}
+		if objInfo.MD5Sum != etag {
+			return traceError(errComposeSourceChanged)
+		}
+		return nil
+	}
+
+	md5Writer := md5.New()
+	if err := objectAPI.GetObject(source.Bucket, source.Name, 0, source.Size, io.MultiWriter(writer, md5Writer)); err != nil {
+		return err
+	}
+	if hex.EncodeToString(md5Writer.Sum(nil)) != etag {
+		return traceError(errComposeSourceChanged)
+	}
+	return nil
+}
+
+// ComposeObjectRequest - format of the compose object request body.
+type ComposeObjectRequest struct {
+	XMLName xml.Name        `xml:"ComposeObject"`
+	Sources []ComposeSource `xml:"Source"`
+}
+
+// ComposeObjectHandler - PUT Object?compose
+// ----------
+// This Minio extension creates an object from the concatenation of
+// up to 32 existing objects, read in the order given in the request
+// body. The data never leaves the server. Appending to an object is
+// composing it with the object itself as the first source.
+//
+// Sources given with an ETag must have it until they have been read,
+// otherwise the request fails with PreconditionFailed and the object
+// is neither created nor replaced.
+func (api objectAPIHandlers) ComposeObjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// Sources may be in other buckets, anonymous requests are not
+	// allowed since bucket policies are checked for the destination only.
+	if s3Error := checkRequestAuthType(r, bucket, "", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	composeBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxFormFieldSize))
+	if err != nil {
+		errorIf(err, "Unable to read compose object request.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	composeRequest := ComposeObjectRequest{}
+	if err = xml.Unmarshal(composeBytes, &composeRequest); err != nil {
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if len(composeRequest.Sources) == 0 {
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if len(composeRequest.Sources) > maxComposeSources {
+		writeErrorResponse(w, r, ErrComposeTooManySources, r.URL.Path)
+		return
+	}
+
+	var size int64
+	sources := make([]ObjectInfo, len(composeRequest.Sources))
+	etags := make([]string, len(composeRequest.Sources))
+	for i, source := range composeRequest.Sources {
+		if source.Bucket == "" {
+			source.Bucket = bucket
+		}
+		if source.Object == "" {
+			writeErrorResponse(w, r, ErrInvalidCopySource, r.URL.Path)
+			return
+		}
+		sources[i], err = objectAPI.GetObjectInfo(source.Bucket, source.Object)
+		if err != nil {
+			errorIf(err, "Unable to fetch object info.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), source.Bucket+"/"+source.Object)
+			return
+		}
+		// Encrypted objects can not be read without their key.
+		if isEncryptedObject(sources[i]) {
+			writeErrorResponse(w, r, ErrSSEEncryptedObject, source.Bucket+"/"+source.Object)
+			return
+		}
+		if source.ETag != "" {
+			etags[i] = canonicalizeETag(source.ETag)
+			if etags[i] != sources[i].MD5Sum {
+				writeErrorResponse(w, r, ErrPreconditionFailed, source.Bucket+"/"+source.Object)
+				return
+			}
+		}
+		size += sources[i].Size
+	}
+
+	/// maximum Upload size for object in a single compose operation.
+	if isMaxObjectSize(size) {
+		writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
+		return
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		writer := &composeWriter{writer: pipeWriter}
+		for i, source := range sources {
+			gErr := readComposeSource(objectAPI, source, etags[i], writer)
+			if gErr != nil {
+				if errorCause(gErr) != errComposeSourceChanged {
+					errorIf(gErr, "Unable to read an object.")
+				}
+				pipeWriter.CloseWithError(gErr)
+				return
+			}
+		}
+		if fErr := writer.flush(); fErr != nil {
+			pipeWriter.CloseWithError(fErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+
+	// Content type defaults to the one of the first source.
+	metadata := extractMetadataFromHeader(r.Header)
+	if _, ok := metadata["content-type"]; !ok && sources[0].ContentType != "" {
+		metadata["content-type"] = sources[0].ContentType
+	}
+
+	versionID, err := prepareObjectVersion(objectAPI, bucket, object, metadata)
+	if err != nil {
+		pipeReader.CloseWithError(err)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Create the object, it replaces an existing one only once all
+	// sources have been read.
+	objInfo, err := objectAPI.PutObject(bucket, object, size, pipeReader, metadata, "")
+	if err != nil {
+		// Close the this end of the pipe upon error in PutObject.
+		pipeReader.CloseWithError(err)
+		if errorCause(err) == errComposeSourceChanged {
+			writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
+			return
+		}
+		errorIf(err, "Unable to create an object.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	// Explicitly close the reader, before fetching object info.
+	pipeReader.Close()
+
+	response := generateCopyObjectResponse(objInfo.MD5Sum, objInfo.ModTime)
+	encodedSuccessResponse := encodeResponse(response)
+	// write headers
+	setCommonHeaders(w)
+	if versionID != "" {
+		w.Header().Set(objectVersionIDHeader, versionID)
+	}
+	// write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedCopy,
+		Bucket:  bucket,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
+}
@@ -0,0 +1,205 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Wrapper for calling ComposeObject HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIComposeObjectHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIComposeObjectHandler, []string{"ComposeObject"})
+}
+
+func testAPIComposeObjectHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	// register event notifier.
+	if err := initEventNotifier(obj); err != nil {
+		t.Fatalf("Initializing event notifiers failed")
+	}
+	otherBucket := getRandomBucketName()
+	if err := obj.MakeBucket(otherBucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	objects := []struct {
+		bucket, object, data string
+	}{
+		{bucketName, "segment-1", "first segment\n"},
+		{bucketName, "segment-2", "second segment\n"},
+		{otherBucket, "segment-3", "third segment\n"},
+		{bucketName, "log", "existing log\n"},
+	}
+	for _, object := range objects {
+		metadata := map[string]string{"content-type": "text/plain"}
+		if _, err := obj.PutObject(object.bucket, object.object, int64(len(object.data)), bytes.NewReader([]byte(object.data)), metadata, ""); err != nil {
+			t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+		}
+	}
+
+	segment1ETag := getMD5Hash([]byte("first segment\n"))
+	tooManySources := `<ComposeObject>` + strings.Repeat(`<Source><Object>segment-1</Object></Source>`, maxComposeSources+1) + `</ComposeObject>`
+
+	testCases := []struct {
+		objectName string
+		body       string
+		accessKey  string
+		// expected output.
+		expectedRespStatus int
+		expectedData       string
+	}{
+		// Test case - 1.
+		// Sources from the same and another bucket.
+		{"composed", `<ComposeObject><Source><Object>segment-1</Object></Source>` +
+			`<Source><Bucket>` + otherBucket + `</Bucket><Object>segment-3</Object></Source>` +
+			`<Source><Object>segment-2</Object></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusOK, "first segment\nthird segment\nsecond segment\n"},
+		// Test case - 2.
+		// Appending to an object.
+		{"log", `<ComposeObject><Source><Object>log</Object></Source>` +
+			`<Source><Object>segment-1</Object></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusOK, "existing log\nfirst segment\n"},
+		// Test case - 3.
+		// No sources.
+		{"composed", `<ComposeObject></ComposeObject>`, credentials.AccessKeyID, http.StatusBadRequest, ""},
+		// Test case - 4.
+		// Malformed body.
+		{"composed", `<ComposeObject>`, credentials.AccessKeyID, http.StatusBadRequest, ""},
+		// Test case - 5.
+		// Missing source object name.
+		{"composed", `<ComposeObject><Source><Bucket>` + bucketName + `</Bucket></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusBadRequest, ""},
+		// Test case - 6.
+		// Non-existent source object.
+		{"composed", `<ComposeObject><Source><Object>missing</Object></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusNotFound, ""},
+		// Test case - 7.
+		// Invalid access key.
+		{"composed", `<ComposeObject><Source><Object>segment-1</Object></Source></ComposeObject>`,
+			"Invalid-AccessID", http.StatusForbidden, ""},
+		// Test case - 8.
+		// Sources with matching ETags, quoted or not.
+		{"checked", `<ComposeObject><Source><Object>segment-1</Object><ETag>"` + segment1ETag + `"</ETag></Source>` +
+			`<Source><Object>segment-1</Object><ETag>` + segment1ETag + `</ETag></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusOK, "first segment\nfirst segment\n"},
+		// Test case - 9.
+		// Source with another ETag.
+		{"checked", `<ComposeObject><Source><Object>segment-1</Object></Source>` +
+			`<Source><Object>segment-2</Object><ETag>` + segment1ETag + `</ETag></Source></ComposeObject>`,
+			credentials.AccessKeyID, http.StatusPreconditionFailed, ""},
+		// Test case - 10.
+		// Too many sources.
+		{"composed", tooManySources, credentials.AccessKeyID, http.StatusBadRequest, ""},
+	}
+
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		queryValues := url.Values{}
+		queryValues.Set("compose", "")
+		req, err := newTestSignedRequestV4("PUT", makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			int64(len(testCase.body)), bytes.NewReader([]byte(testCase.body)), testCase.accessKey, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request for compose object: <ERROR> %v", i+1, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		var buffer bytes.Buffer
+		if err = obj.GetObject(bucketName, testCase.objectName, 0, int64(len(testCase.expectedData)), &buffer); err != nil {
+			t.Fatalf("Test %d: %s: Failed to fetch the composed object: <ERROR> %v", i+1, instanceType, err)
+		}
+		if buffer.String() != testCase.expectedData {
+			t.Errorf("Test %d: %s: Expected %q, got %q", i+1, instanceType, testCase.expectedData, buffer.String())
+		}
+		objInfo, err := obj.GetObjectInfo(bucketName, testCase.objectName)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to stat the composed object: <ERROR> %v", i+1, instanceType, err)
+		}
+		if objInfo.Size != int64(len(testCase.expectedData)) || objInfo.ContentType != "text/plain" {
+			t.Errorf("Test %d: %s: Unexpected object info %+v", i+1, instanceType, objInfo)
+		}
+	}
+
+	// Anonymous requests are rejected even with a public bucket policy.
+	policy := bucketPolicy{
+		Version:    "1.0",
+		Statements: []policyStatement{getReadWriteObjectStatement(bucketName, "")},
+	}
+	globalBucketPolicies.SetBucketPolicy(bucketName, policyChange{false, &policy})
+	defer globalBucketPolicies.SetBucketPolicy(bucketName, policyChange{true, nil})
+
+	queryValues := url.Values{}
+	queryValues.Set("compose", "")
+	body := `<ComposeObject><Source><Object>segment-1</Object></Source></ComposeObject>`
+	anonReq, err := newTestRequest("PUT", makeTestTargetURL("", bucketName, "anon", queryValues),
+		int64(len(body)), bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatalf("%s: Failed to create an anonymous request: <ERROR> %v", instanceType, err)
+	}
+	rec := httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, anonReq)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("%s: Expected anonymous compose to be denied, got status %d", instanceType, rec.Code)
+	}
+}
+
+// Tests that a source replaced while composing is detected before the
+// composed object is complete.
+func TestComposeSourceReplaced(t *testing.T) {
+	ExecObjectLayerTest(t, testComposeSourceReplaced)
+}
+
+func testComposeSourceReplaced(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "compose-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	source, err := obj.PutObject(bucket, "source", 4, bytes.NewReader([]byte("abcd")), nil, "")
+	if err != nil {
+		t.Fatalf("%s: Unable to upload object: %v", instanceType, err)
+	}
+	if _, err = obj.PutObject(bucket, "source", 4, bytes.NewReader([]byte("efgh")), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to upload object: %v", instanceType, err)
+	}
+
+	var buf bytes.Buffer
+	writer := &composeWriter{writer: &buf}
+	if err = readComposeSource(obj, source, "", writer); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if err = readComposeSource(obj, source, source.MD5Sum, writer); errorCause(err) != errComposeSourceChanged {
+		t.Fatalf("%s: Expected the replaced source to be detected, got %v", instanceType, err)
+	}
+	// The last byte is written once flushed.
+	if buf.String() != "efghefg" {
+		t.Fatalf("%s: Expected %q before flushing, got %q", instanceType, "efghefg", buf.String())
+	}
+	if err = writer.flush(); err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if buf.String() != "efghefgh" {
+		t.Fatalf("%s: Expected %q, got %q", instanceType, "efghefgh", buf.String())
+	}
+}
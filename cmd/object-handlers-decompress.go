@@ -0,0 +1,128 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// isDecompressRequest - returns true if the object is stored gzip
+// compressed, with Content-Encoding gzip, and the client asked for
+// its decompressed content with the decompress query parameter.
+func isDecompressRequest(r *http.Request, objInfo ObjectInfo) bool {
+	if r.URL.Query().Get("decompress") != "true" {
+		return false
+	}
+	switch strings.ToLower(objInfo.ContentEncoding) {
+	case "gzip", "x-gzip":
+		return true
+	}
+	return false
+}
+
+// toDecompressError - converts errors of malformed compressed data to
+// errInvalidCompressedObject, returns object layer errors as is.
+func toDecompressError(err error) error {
+	if _, ok := err.(flate.CorruptInputError); ok {
+		return errInvalidCompressedObject
+	}
+	switch err {
+	case gzip.ErrHeader, gzip.ErrChecksum, io.EOF, io.ErrUnexpectedEOF:
+		return errInvalidCompressedObject
+	}
+	return err
+}
+
+// decompressReader - reads the decompressed content of an object,
+// closing it stops reading the object.
+type decompressReader struct {
+	*gzip.Reader
+	pipeReader *io.PipeReader
+}
+
+func (d decompressReader) Close() error {
+	return d.pipeReader.Close()
+}
+
+// newDecompressReader - returns a reader of the decompressed content
+// of a gzip compressed object.
+func newDecompressReader(objectAPI ObjectLayer, objInfo ObjectInfo) (io.ReadCloser, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(objectAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, pipeWriter))
+	}()
+	gzipReader, err := gzip.NewReader(pipeReader)
+	if err != nil {
+		pipeReader.Close()
+		return nil, toDecompressError(err)
+	}
+	return decompressReader{gzipReader, pipeReader}, nil
+}
+
+// getDecompressedObjectInfo - returns the object info describing the
+// decompressed content of a gzip compressed object. The decompressed
+// size is not stored, the object is decompressed once to count it.
+func getDecompressedObjectInfo(objectAPI ObjectLayer, objInfo ObjectInfo) (ObjectInfo, error) {
+	reader, err := newDecompressReader(objectAPI, objInfo)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer reader.Close()
+
+	size, err := io.Copy(ioutil.Discard, reader)
+	if err != nil {
+		return ObjectInfo{}, toDecompressError(err)
+	}
+
+	// Content encoding is removed from the headers, they are set
+	// from user defined metadata.
+	userDefined := make(map[string]string, len(objInfo.UserDefined))
+	for k, v := range objInfo.UserDefined {
+		if strings.ToLower(k) != "content-encoding" {
+			userDefined[k] = v
+		}
+	}
+	objInfo.Size = size
+	objInfo.ContentEncoding = ""
+	objInfo.UserDefined = userDefined
+	// The parts of the object do not apply to its decompressed content.
+	objInfo.Parts = nil
+	return objInfo, nil
+}
+
+// getDecompressedObject - writes length bytes of the decompressed
+// content of a gzip compressed object at offset to writer.
+func getDecompressedObject(objectAPI ObjectLayer, objInfo ObjectInfo, offset, length int64, writer io.Writer) error {
+	reader, err := newDecompressReader(objectAPI, objInfo)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if _, err = io.CopyN(ioutil.Discard, reader, offset); err != nil {
+		return toDecompressError(err)
+	}
+	if _, err = io.CopyN(writer, reader, length); err != nil {
+		return toDecompressError(err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// Wrapper for calling decompressing GetObject and HeadObject HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIGetObjectDecompressHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIGetObjectDecompressHandler, []string{"GetObject", "HeadObject"})
+}
+
+func testAPIGetObjectDecompressHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	data := bytes.Repeat([]byte("decompressed content\n"), 1000)
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"compressed.txt", compressed.Bytes(), "gzip"},
+		{"plain.txt", data, ""},
+		{"corrupted.txt", data, "gzip"},
+	}
+	for _, object := range objects {
+		metadata := map[string]string{"content-type": "text/plain"}
+		if object.encoding != "" {
+			metadata["content-encoding"] = object.encoding
+		}
+		if _, err := obj.PutObject(bucketName, object.name, int64(len(object.data)), bytes.NewReader(object.data), metadata, ""); err != nil {
+			t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+		}
+	}
+
+	testCases := []struct {
+		method     string
+		objectName string
+		decompress bool
+		byteRange  string
+		// expected output.
+		expectedRespStatus int
+		expectedData       []byte
+		expectedLength     int
+		expectedEncoding   string
+	}{
+		// Test case - 1.
+		// Decompressed content of a gzip object.
+		{"GET", "compressed.txt", true, "", http.StatusOK, data, len(data), ""},
+		// Test case - 2.
+		// Stored content without the decompress parameter.
+		{"GET", "compressed.txt", false, "", http.StatusOK, compressed.Bytes(), compressed.Len(), "gzip"},
+		// Test case - 3.
+		// Ranges apply to the decompressed content.
+		{"GET", "compressed.txt", true, "bytes=21-41", http.StatusPartialContent, data[21:42], 21, ""},
+		// Test case - 4.
+		// Range beyond the decompressed content.
+		{"GET", "compressed.txt", true, "bytes=" + strconv.Itoa(len(data)) + "-", http.StatusRequestedRangeNotSatisfiable, nil, -1, ""},
+		// Test case - 5.
+		// Objects without gzip encoding are served as is.
+		{"GET", "plain.txt", true, "", http.StatusOK, data, len(data), ""},
+		// Test case - 6.
+		// Invalid gzip content.
+		{"GET", "corrupted.txt", true, "", http.StatusBadRequest, nil, -1, ""},
+		// Test case - 7.
+		// Decompressed length of a gzip object.
+		{"HEAD", "compressed.txt", true, "", http.StatusOK, nil, len(data), ""},
+		// Test case - 8.
+		// Stored length without the decompress parameter.
+		{"HEAD", "compressed.txt", false, "", http.StatusOK, nil, compressed.Len(), "gzip"},
+	}
+
+	for i, testCase := range testCases {
+		queryValues := url.Values{}
+		if testCase.decompress {
+			queryValues.Set("decompress", "true")
+		}
+		req, err := newTestSignedRequestV4(testCase.method, makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request: <ERROR> %v", i+1, err)
+		}
+		if testCase.byteRange != "" {
+			req.Header.Set("Range", testCase.byteRange)
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if testCase.expectedLength < 0 {
+			continue
+		}
+		if contentLength := rec.Header().Get("Content-Length"); contentLength != strconv.Itoa(testCase.expectedLength) {
+			t.Errorf("Test %d: %s: Expected Content-Length %d, got %s", i+1, instanceType, testCase.expectedLength, contentLength)
+		}
+		if encoding := rec.Header().Get("Content-Encoding"); encoding != testCase.expectedEncoding {
+			t.Errorf("Test %d: %s: Expected Content-Encoding %q, got %q", i+1, instanceType, testCase.expectedEncoding, encoding)
+		}
+		if testCase.method == "GET" && !bytes.Equal(rec.Body.Bytes(), testCase.expectedData) {
+			t.Errorf("Test %d: %s: Unexpected response body of %d bytes", i+1, instanceType, rec.Body.Len())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+
+	humanize "github.com/dustin/go-humanize"
+	mux "github.com/gorilla/mux"
+	"github.com/minio/minio/pkg/s3select"
+)
+
+// Maximum size of a SelectObjectContentRequest document.
+const maxSelectRequestSize = 256 * humanize.KiByte
+
+// writeSelectErrorResponse - writes a select request validation error.
+func writeSelectErrorResponse(w http.ResponseWriter, r *http.Request, err *s3select.Error) {
+	apiError := APIError{
+		Code:           err.Code(),
+		Description:    err.Error(),
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+	if err.Code() == "NotImplemented" {
+		apiError.HTTPStatusCode = http.StatusNotImplemented
+	}
+	setCommonHeaders(w)
+	w.WriteHeader(apiError.HTTPStatusCode)
+	w.Write(encodeResponse(getAPIErrorResponse(apiError, r.URL.Path)))
+}
+
+// SelectObjectContentHandler - POST Object?select&select-type=2
+// ----------
+// This operation evaluates a SQL expression over the contents of an
+// object and streams back only the matching records.
+func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:GetObject", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	selectReq, err := s3select.ParseRequest(io.LimitReader(r.Body, maxSelectRequestSize))
+	if err != nil {
+		writeSelectErrorResponse(w, r, err.(*s3select.Error))
+		return
+	}
+	s3Select, err := s3select.NewSelect(selectReq)
+	if err != nil {
+		writeSelectErrorResponse(w, r, err.(*s3select.Error))
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Encrypted objects can not be read without their key.
+	if isEncryptedObject(objInfo) {
+		writeErrorResponse(w, r, ErrSSEEncryptedObject, r.URL.Path)
+		return
+	}
+
+	// Stream the object into the select engine, closing the reader
+	// stops GetObject early once a LIMIT is satisfied.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(objectAPI.GetObject(bucket, object, 0, objInfo.Size, pw))
+	}()
+	defer pr.Close()
+
+	setCommonHeaders(w)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	if err = s3Select.Evaluate(pr, w); err != nil {
+		errorIf(err, "Unable to write select response to client.")
+	}
+}
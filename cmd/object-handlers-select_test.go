@@ -0,0 +1,109 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Wrapper for calling SelectObjectContent HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPISelectObjectContentHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPISelectObjectContentHandler, []string{"SelectObjectContent"})
+}
+
+func testAPISelectObjectContentHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "data.csv"
+	csvData := []byte("name,age\nAlice,34\nBob,27\nCarol,45\n")
+	if _, err := obj.PutObject(bucketName, objectName, int64(len(csvData)), bytes.NewReader(csvData), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	selectRequest := func(expression string) []byte {
+		return []byte(`<SelectObjectContentRequest>` +
+			`<Expression>` + expression + `</Expression><ExpressionType>SQL</ExpressionType>` +
+			`<InputSerialization><CSV><FileHeaderInfo>USE</FileHeaderInfo></CSV></InputSerialization>` +
+			`<OutputSerialization><CSV/></OutputSerialization>` +
+			`</SelectObjectContentRequest>`)
+	}
+
+	testCases := []struct {
+		objectName         string
+		body               []byte
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+		expectedRecords    string
+	}{
+		// Test case - 1.
+		// Select matching rows and columns.
+		{objectName, selectRequest("SELECT name FROM S3Object WHERE CAST(age AS INT) &gt; 30"),
+			credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK, "Alice\nCarol\n"},
+		// Test case - 2.
+		// Invalid SQL expression.
+		{objectName, selectRequest("SELECT name FROM"),
+			credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusBadRequest, ""},
+		// Test case - 3.
+		// Non-existent object.
+		{"missing.csv", selectRequest("SELECT * FROM S3Object"),
+			credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound, ""},
+		// Test case - 4.
+		// Invalid credentials.
+		{objectName, selectRequest("SELECT * FROM S3Object"),
+			"Invalid-AccessID", credentials.SecretAccessKey, http.StatusForbidden, ""},
+	}
+
+	queryValues := url.Values{}
+	queryValues.Set("select", "")
+	queryValues.Set("select-type", "2")
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("POST", makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		// Collect the payload of all Records messages.
+		var records bytes.Buffer
+		data := rec.Body.Bytes()
+		for len(data) >= 12 {
+			totalLength := binary.BigEndian.Uint32(data[0:4])
+			headersLength := binary.BigEndian.Uint32(data[4:8])
+			headers := data[12 : 12+headersLength]
+			if bytes.Contains(headers, []byte("Records")) {
+				records.Write(data[12+headersLength : totalLength-4])
+			}
+			data = data[totalLength:]
+		}
+		if records.String() != testCase.expectedRecords {
+			t.Errorf("Test %d: %s: Expected records %q, got %q", i+1, instanceType, testCase.expectedRecords, records.String())
+		}
+	}
+}
@@ -0,0 +1,318 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Headers of server side encryption with customer provided keys.
+const (
+	sseCustomerAlgorithmHeader     = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	sseCustomerKeyHeader           = "X-Amz-Server-Side-Encryption-Customer-Key"
+	sseCustomerKeyMD5Header        = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+	sseCopyCustomerAlgorithmHeader = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm"
+	sseCopyCustomerKeyHeader       = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key"
+	sseCopyCustomerKeyMD5Header    = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"
+)
+
+const (
+	// The only supported encryption algorithm.
+	sseAlgorithmAES256 = "AES256"
+
+	// Length of customer provided keys, 256 bits.
+	sseCustomerKeyLength = 32
+
+	// Length of the random nonce an object key is derived from.
+	sseNonceLength = 32
+
+	// Prefix of metadata kept for the server and not returned to
+	// clients.
+	internalMetadataPrefix = "X-Minio-Internal-"
+
+	// Metadata holding the base64 encoded nonce of encrypted objects.
+	sseNonceMetadata = internalMetadataPrefix + "Server-Side-Encryption-Nonce"
+)
+
+// Metadata of encrypted objects, the algorithm and the MD5 of the key
+// are returned to clients as is.
+var sseMetadata = []string{
+	sseCustomerAlgorithmHeader,
+	sseCustomerKeyMD5Header,
+	sseNonceMetadata,
+}
+
+// parseSSECustomerKey - returns the customer provided key sent with
+// the algorithm, key and key MD5 headers, nil if none of them are set.
+func parseSSECustomerKey(header http.Header, algorithmHeader, keyHeader, keyMD5Header string) ([]byte, error) {
+	algorithm := header.Get(algorithmHeader)
+	encodedKey := header.Get(keyHeader)
+	encodedKeyMD5 := header.Get(keyMD5Header)
+	if algorithm == "" && encodedKey == "" && encodedKeyMD5 == "" {
+		return nil, nil
+	}
+	if algorithm != sseAlgorithmAES256 {
+		return nil, errInvalidEncryptionAlgorithm
+	}
+	if encodedKey == "" || encodedKeyMD5 == "" {
+		return nil, errMissingSSECustomerKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != sseCustomerKeyLength {
+		return nil, errInvalidSSECustomerKey
+	}
+	keyMD5, err := base64.StdEncoding.DecodeString(encodedKeyMD5)
+	if err != nil || !hmac.Equal(keyMD5, getMD5Sum(key)) {
+		return nil, errSSECustomerKeyMD5Mismatch
+	}
+	return key, nil
+}
+
+// parseSSECustomerRequest - returns the customer provided key of the
+// object a request reads or writes, nil if none was sent. Keys are
+// only accepted over TLS.
+func parseSSECustomerRequest(r *http.Request) ([]byte, error) {
+	key, err := parseSSECustomerKey(r.Header, sseCustomerAlgorithmHeader, sseCustomerKeyHeader, sseCustomerKeyMD5Header)
+	if key != nil && r.TLS == nil {
+		return nil, errInsecureSSECustomerRequest
+	}
+	return key, err
+}
+
+// parseSSECopyCustomerRequest - returns the customer provided key of
+// the source object of a copy, nil if none was sent.
+func parseSSECopyCustomerRequest(r *http.Request) ([]byte, error) {
+	key, err := parseSSECustomerKey(r.Header, sseCopyCustomerAlgorithmHeader, sseCopyCustomerKeyHeader, sseCopyCustomerKeyMD5Header)
+	if key != nil && r.TLS == nil {
+		return nil, errInsecureSSECustomerRequest
+	}
+	return key, err
+}
+
+// hasSSECustomerHeaders - returns true if any header of customer
+// provided keys is set.
+func hasSSECustomerHeaders(header http.Header) bool {
+	for key := range header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(key), "X-Amz-Server-Side-Encryption-Customer-") {
+			return true
+		}
+	}
+	return false
+}
+
+// isEncryptedObject - returns true if the object was stored encrypted.
+func isEncryptedObject(objInfo ObjectInfo) bool {
+	return objInfo.UserDefined[sseCustomerAlgorithmHeader] != ""
+}
+
+// removeSSEMetadata - removes the encryption metadata of an object,
+// as its data is encrypted again or not at all.
+func removeSSEMetadata(metadata map[string]string) {
+	for _, key := range sseMetadata {
+		delete(metadata, key)
+	}
+}
+
+// newObjectKey - returns the key an object is encrypted with, derived
+// from the customer provided key and the nonce of the object so that
+// no two objects share a key stream.
+func newObjectKey(customerKey, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, customerKey)
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
+
+// newSSEStream - returns the AES-256-CTR key stream of objectKey,
+// positioned at offset of the object.
+func newSSEStream(objectKey []byte, offset int64) (cipher.Stream, error) {
+	block, err := aes.NewCipher(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[aes.BlockSize-8:], uint64(offset/aes.BlockSize))
+	stream := cipher.NewCTR(block, iv)
+	skip := make([]byte, offset%aes.BlockSize)
+	stream.XORKeyStream(skip, skip)
+	return stream, nil
+}
+
+// getSSEObjectKey - returns the key the object was encrypted with,
+// verifying that the customer provided key is the one used. Returns
+// nil if the object is not encrypted and no key was provided.
+func getSSEObjectKey(objInfo ObjectInfo, customerKey []byte) ([]byte, error) {
+	if !isEncryptedObject(objInfo) {
+		if customerKey != nil {
+			return nil, errInvalidEncryptionParameters
+		}
+		return nil, nil
+	}
+	if customerKey == nil {
+		return nil, errEncryptedObject
+	}
+	keyMD5 := base64.StdEncoding.EncodeToString(getMD5Sum(customerKey))
+	if subtle.ConstantTimeCompare([]byte(keyMD5), []byte(objInfo.UserDefined[sseCustomerKeyMD5Header])) != 1 {
+		return nil, errSSEKeyMismatch
+	}
+	nonce, err := base64.StdEncoding.DecodeString(objInfo.UserDefined[sseNonceMetadata])
+	if err != nil || len(nonce) != sseNonceLength {
+		return nil, errCorruptedFormat
+	}
+	return newObjectKey(customerKey, nonce), nil
+}
+
+// getRequestObjectKey - returns the key to decrypt an object with from
+// the customer provided key of r, nil if the object is not encrypted.
+func getRequestObjectKey(r *http.Request, objInfo ObjectInfo) ([]byte, error) {
+	customerKey, err := parseSSECustomerRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return getSSEObjectKey(objInfo, customerKey)
+}
+
+// getCopySourceObjectKey - returns the key to decrypt the source of a
+// copy with from the copy source key of r, nil if the source is not
+// encrypted.
+func getCopySourceObjectKey(r *http.Request, srcInfo ObjectInfo) ([]byte, error) {
+	customerKey, err := parseSSECopyCustomerRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return getSSEObjectKey(srcInfo, customerKey)
+}
+
+// copyEncryptedObject - copies srcInfo to bucket/object, decrypting it
+// with sourceKey and encrypting the copy with customerKey when not nil.
+func copyEncryptedObject(objectAPI ObjectLayer, srcInfo ObjectInfo, sourceKey []byte, bucket, object string, customerKey []byte, metadata map[string]string) (ObjectInfo, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		var writer io.Writer = pipeWriter
+		if sourceKey != nil {
+			decryptWriter, err := newDecryptWriter(pipeWriter, sourceKey, 0)
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+			writer = decryptWriter
+		}
+		pipeWriter.CloseWithError(objectAPI.GetObject(srcInfo.Bucket, srcInfo.Name, 0, srcInfo.Size, writer))
+	}()
+	defer pipeReader.Close()
+
+	var reader io.Reader = pipeReader
+	if customerKey != nil {
+		var err error
+		if reader, err = newEncryptReader(pipeReader, srcInfo.Size, customerKey, metadata, ""); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+	return objectAPI.PutObject(bucket, object, srcInfo.Size, reader, metadata, "")
+}
+
+// newDecryptWriter - returns a writer decrypting the data of an object
+// read from offset with objectKey into writer.
+func newDecryptWriter(writer io.Writer, objectKey []byte, offset int64) (io.Writer, error) {
+	stream, err := newSSEStream(objectKey, offset)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.StreamWriter{S: stream, W: writer}, nil
+}
+
+// sseVerifyReader - verifies the MD5 and SHA-256 of the plain data of
+// an object once read, the object layer only sees its encrypted data.
+type sseVerifyReader struct {
+	reader    io.Reader
+	md5Hash   hash.Hash
+	md5Hex    string
+	sha256    hash.Hash
+	sha256Hex string
+}
+
+func (v *sseVerifyReader) Read(p []byte) (int, error) {
+	n, err := v.reader.Read(p)
+	v.md5Hash.Write(p[:n])
+	v.sha256.Write(p[:n])
+	if err != io.EOF {
+		return n, err
+	}
+	if v.md5Hex != "" {
+		if md5Hex := hex.EncodeToString(v.md5Hash.Sum(nil)); md5Hex != v.md5Hex {
+			return n, traceError(BadDigest{v.md5Hex, md5Hex})
+		}
+	}
+	if v.sha256Hex != "" && hex.EncodeToString(v.sha256.Sum(nil)) != v.sha256Hex {
+		return n, traceError(SHA256Mismatch{})
+	}
+	return n, err
+}
+
+// newEncryptReader - returns a reader encrypting size bytes of reader
+// with a key derived from customerKey, and records the encryption in
+// metadata. The md5Sum of metadata and sha256sum are verified against
+// the plain data, the ETag of the object is the MD5 of its encrypted
+// data.
+func newEncryptReader(reader io.Reader, size int64, customerKey []byte, metadata map[string]string, sha256sum string) (io.Reader, error) {
+	nonce := make([]byte, sseNonceLength)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	stream, err := newSSEStream(newObjectKey(customerKey, nonce), 0)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		reader = io.LimitReader(reader, size)
+	}
+	reader = &sseVerifyReader{
+		reader:    reader,
+		md5Hash:   md5.New(),
+		md5Hex:    metadata["md5Sum"],
+		sha256:    sha256.New(),
+		sha256Hex: sha256sum,
+	}
+	delete(metadata, "md5Sum")
+	metadata[sseCustomerAlgorithmHeader] = sseAlgorithmAES256
+	metadata[sseCustomerKeyMD5Header] = base64.StdEncoding.EncodeToString(getMD5Sum(customerKey))
+	metadata[sseNonceMetadata] = base64.StdEncoding.EncodeToString(nonce)
+	return cipher.StreamReader{S: stream, R: reader}, nil
+}
+
+// setSSEHeaders - returns the encryption algorithm and key MD5 of an
+// encrypted object in the response.
+func setSSEHeaders(w http.ResponseWriter, objInfo ObjectInfo) {
+	if !isEncryptedObject(objInfo) {
+		return
+	}
+	w.Header().Set(sseCustomerAlgorithmHeader, objInfo.UserDefined[sseCustomerAlgorithmHeader])
+	w.Header().Set(sseCustomerKeyMD5Header, objInfo.UserDefined[sseCustomerKeyMD5Header])
+}
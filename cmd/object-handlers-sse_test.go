@@ -0,0 +1,273 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Returns the headers sending key as a customer provided key.
+func newSSECustomerHeader(key []byte, algorithmHeader, keyHeader, keyMD5Header string) http.Header {
+	header := http.Header{}
+	header.Set(algorithmHeader, sseAlgorithmAES256)
+	header.Set(keyHeader, base64.StdEncoding.EncodeToString(key))
+	header.Set(keyMD5Header, getMD5HashBase64(key))
+	return header
+}
+
+// Tests parsing of customer provided keys.
+func TestParseSSECustomerKey(t *testing.T) {
+	key := bytes.Repeat([]byte{'k'}, sseCustomerKeyLength)
+	validHeader := newSSECustomerHeader(key, sseCustomerAlgorithmHeader, sseCustomerKeyHeader, sseCustomerKeyMD5Header)
+
+	withHeader := func(name, value string) http.Header {
+		header := http.Header{}
+		for k, v := range validHeader {
+			header[k] = v
+		}
+		if value == "" {
+			header.Del(name)
+		} else {
+			header.Set(name, value)
+		}
+		return header
+	}
+
+	testCases := []struct {
+		header      http.Header
+		expectedKey []byte
+		expectedErr error
+	}{
+		// No key.
+		{http.Header{}, nil, nil},
+		// Valid key.
+		{validHeader, key, nil},
+		// Unsupported algorithm.
+		{withHeader(sseCustomerAlgorithmHeader, "AES128"), nil, errInvalidEncryptionAlgorithm},
+		// Missing algorithm.
+		{withHeader(sseCustomerAlgorithmHeader, ""), nil, errInvalidEncryptionAlgorithm},
+		// Missing key.
+		{withHeader(sseCustomerKeyHeader, ""), nil, errMissingSSECustomerKey},
+		// Missing key MD5.
+		{withHeader(sseCustomerKeyMD5Header, ""), nil, errMissingSSECustomerKey},
+		// Key of the wrong length.
+		{withHeader(sseCustomerKeyHeader, base64.StdEncoding.EncodeToString(key[:16])), nil, errInvalidSSECustomerKey},
+		// Key not base64 encoded.
+		{withHeader(sseCustomerKeyHeader, "not base64"), nil, errInvalidSSECustomerKey},
+		// Key MD5 of another key.
+		{withHeader(sseCustomerKeyMD5Header, getMD5HashBase64(key[1:])), nil, errSSECustomerKeyMD5Mismatch},
+	}
+	for i, testCase := range testCases {
+		key, err := parseSSECustomerKey(testCase.header, sseCustomerAlgorithmHeader, sseCustomerKeyHeader, sseCustomerKeyMD5Header)
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if !bytes.Equal(key, testCase.expectedKey) {
+			t.Errorf("Test %d: Expected key %q, got %q", i+1, testCase.expectedKey, key)
+		}
+	}
+}
+
+// Tests that data encrypted at once is decrypted from any offset.
+func TestSSEDecryptOffset(t *testing.T) {
+	customerKey := bytes.Repeat([]byte{'k'}, sseCustomerKeyLength)
+	data := bytes.Repeat([]byte("0123456789abcdef!"), 100)
+	metadata := map[string]string{"md5Sum": getMD5Hash(data)}
+
+	reader, err := newEncryptReader(bytes.NewReader(data), int64(len(data)), customerKey, metadata, getSHA256Hash(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := new(bytes.Buffer)
+	if _, err = encrypted.ReadFrom(reader); err != nil {
+		t.Fatalf("Unable to encrypt: %v", err)
+	}
+	if bytes.Equal(encrypted.Bytes(), data) {
+		t.Fatal("Expected the data to be encrypted")
+	}
+	if _, ok := metadata["md5Sum"]; ok {
+		t.Fatal("Expected the md5Sum of the plain data to be removed")
+	}
+
+	objInfo := ObjectInfo{UserDefined: metadata}
+	objectKey, err := getSSEObjectKey(objInfo, customerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, offset := range []int64{0, 1, 15, 16, 17, 1000, int64(len(data)) - 1} {
+		decrypted := new(bytes.Buffer)
+		writer, err := newDecryptWriter(decrypted, objectKey, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = writer.Write(encrypted.Bytes()[offset:]); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted.Bytes(), data[offset:]) {
+			t.Errorf("Offset %d: Decrypted data does not match", offset)
+		}
+	}
+
+	if _, err = getSSEObjectKey(objInfo, bytes.Repeat([]byte{'x'}, sseCustomerKeyLength)); err != errSSEKeyMismatch {
+		t.Errorf("Expected errSSEKeyMismatch for another key, got %v", err)
+	}
+	if _, err = getSSEObjectKey(objInfo, nil); err != errEncryptedObject {
+		t.Errorf("Expected errEncryptedObject without a key, got %v", err)
+	}
+	if _, err = getSSEObjectKey(ObjectInfo{}, customerKey); err != errInvalidEncryptionParameters {
+		t.Errorf("Expected errInvalidEncryptionParameters for a plain object, got %v", err)
+	}
+}
+
+// Tests that the plain data is verified against the MD5 sent.
+func TestSSEEncryptBadDigest(t *testing.T) {
+	customerKey := bytes.Repeat([]byte{'k'}, sseCustomerKeyLength)
+	metadata := map[string]string{"md5Sum": getMD5Hash([]byte("other data"))}
+	reader, err := newEncryptReader(bytes.NewReader([]byte("data")), 4, customerKey, metadata, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = new(bytes.Buffer).ReadFrom(reader); err == nil {
+		t.Fatal("Expected a BadDigest error")
+	}
+	if _, ok := errorCause(err).(BadDigest); !ok {
+		t.Fatalf("Expected a BadDigest error, got %v", err)
+	}
+}
+
+func TestAPISSECustomerHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPISSECustomerHandlers, []string{"CopyObject", "NewMultipart", "PutObject", "GetObject", "HeadObject"})
+}
+
+func testAPISSECustomerHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "encrypted-object"
+	data := []byte("The quick brown fox jumps over the lazy dog.")
+	key := bytes.Repeat([]byte{'k'}, sseCustomerKeyLength)
+	otherKey := bytes.Repeat([]byte{'o'}, sseCustomerKeyLength)
+
+	send := func(method, object string, header http.Header, body []byte, secure bool) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, makeTestTargetURL("", bucketName, object, nil),
+			int64(len(body)), bytes.NewReader(body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if secure {
+			req.TLS = &tls.ConnectionState{}
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+	keyHeader := func(key []byte) http.Header {
+		return newSSECustomerHeader(key, sseCustomerAlgorithmHeader, sseCustomerKeyHeader, sseCustomerKeyMD5Header)
+	}
+
+	// Keys are only accepted over TLS.
+	if rec := send("PUT", objectName, keyHeader(key), data, false); rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected a key over plain HTTP to be rejected, got `%d`", instanceType, rec.Code)
+	}
+	rec := send("PUT", objectName, keyHeader(key), data, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Unable to upload an encrypted object, got `%d`: %s", instanceType, rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(sseCustomerKeyMD5Header) != getMD5HashBase64(key) {
+		t.Errorf("%s: Expected the key MD5 in the response, got %q", instanceType, rec.Header().Get(sseCustomerKeyMD5Header))
+	}
+
+	// The object is stored encrypted.
+	var stored bytes.Buffer
+	if err := obj.GetObject(bucketName, objectName, 0, int64(len(data)), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(stored.Bytes(), data) {
+		t.Fatalf("%s: Expected the object to be stored encrypted", instanceType)
+	}
+
+	// The object is only served with the key it was encrypted with.
+	if rec = send("GET", objectName, nil, nil, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("%s: Expected GET without a key to fail, got `%d`", instanceType, rec.Code)
+	}
+	if rec = send("GET", objectName, keyHeader(otherKey), nil, true); rec.Code != http.StatusForbidden {
+		t.Errorf("%s: Expected GET with another key to fail, got `%d`", instanceType, rec.Code)
+	}
+	if rec = send("HEAD", objectName, nil, nil, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("%s: Expected HEAD without a key to fail, got `%d`", instanceType, rec.Code)
+	}
+	rec = send("GET", objectName, keyHeader(key), nil, true)
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Errorf("%s: Unexpected GET response `%d` %q", instanceType, rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(sseNonceMetadata) != "" {
+		t.Errorf("%s: Expected the nonce not to be returned", instanceType)
+	}
+	rangeHeader := keyHeader(key)
+	rangeHeader.Set("Range", "bytes=4-18")
+	if rec = send("GET", objectName, rangeHeader, nil, true); rec.Body.String() != string(data[4:19]) {
+		t.Errorf("%s: Unexpected range %q", instanceType, rec.Body.String())
+	}
+
+	// Copies decrypt with the copy source key, and encrypt with the
+	// destination key if any.
+	copyHeader := newSSECustomerHeader(key, sseCopyCustomerAlgorithmHeader, sseCopyCustomerKeyHeader, sseCopyCustomerKeyMD5Header)
+	copyHeader.Set("X-Amz-Copy-Source", bucketName+"/"+objectName)
+	if rec = send("PUT", "plain-copy", http.Header{"X-Amz-Copy-Source": copyHeader["X-Amz-Copy-Source"]}, nil, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("%s: Expected a copy without the source key to fail, got `%d`", instanceType, rec.Code)
+	}
+	if rec = send("PUT", "plain-copy", copyHeader, nil, true); rec.Code != http.StatusOK {
+		t.Fatalf("%s: Unable to copy to a plain object, got `%d`: %s", instanceType, rec.Code, rec.Body.String())
+	}
+	if rec = send("GET", "plain-copy", nil, nil, false); !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Errorf("%s: Unexpected plain copy %q", instanceType, rec.Body.String())
+	}
+	for k, v := range keyHeader(otherKey) {
+		copyHeader[k] = v
+	}
+	if rec = send("PUT", "encrypted-copy", copyHeader, nil, true); rec.Code != http.StatusOK {
+		t.Fatalf("%s: Unable to copy to an encrypted object, got `%d`: %s", instanceType, rec.Code, rec.Body.String())
+	}
+	if rec = send("GET", "encrypted-copy", keyHeader(otherKey), nil, true); !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Errorf("%s: Unexpected encrypted copy %q", instanceType, rec.Body.String())
+	}
+
+	// Keys do not apply to plain objects, nor to multipart uploads.
+	if rec = send("GET", "plain-copy", keyHeader(key), nil, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("%s: Expected GET of a plain object with a key to fail, got `%d`", instanceType, rec.Code)
+	}
+	req, err := newTestSignedRequestV4("POST", getNewMultipartURL("", bucketName, "multipart"),
+		0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range keyHeader(key) {
+		req.Header[k] = v
+	}
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("%s: Expected encrypted multipart uploads to be rejected, got `%d`", instanceType, rec.Code)
+	}
+}
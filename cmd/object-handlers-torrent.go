@@ -0,0 +1,193 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"hash"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	mux "github.com/gorilla/mux"
+)
+
+const (
+	// Torrent piece length bounds, the piece length is the smallest
+	// power of two within these bounds giving at most
+	// maxTorrentPieces pieces.
+	minTorrentPieceLength = 256 * 1024
+	maxTorrentPieceLength = 16 * 1024 * 1024
+	maxTorrentPieces      = 2048
+)
+
+// torrentPieceLength - returns the piece length for an object of size.
+func torrentPieceLength(size int64) int64 {
+	pieceLength := int64(minTorrentPieceLength)
+	for pieceLength < maxTorrentPieceLength && size/pieceLength >= maxTorrentPieces {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// pieceHasher - computes the SHA1 sums of consecutive fixed size
+// pieces of the data written to it.
+type pieceHasher struct {
+	pieceLength int64
+	written     int64
+	hash        hash.Hash
+	pieces      []byte
+}
+
+func (p *pieceHasher) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		remaining := p.pieceLength - p.written
+		if int64(len(b)) < remaining {
+			remaining = int64(len(b))
+		}
+		p.hash.Write(b[:remaining])
+		p.written += remaining
+		b = b[remaining:]
+		if p.written == p.pieceLength {
+			p.pieces = p.hash.Sum(p.pieces)
+			p.hash.Reset()
+			p.written = 0
+		}
+	}
+	return n, nil
+}
+
+// Sum - returns the concatenated SHA1 sums of all the pieces.
+func (p *pieceHasher) Sum() []byte {
+	if p.written > 0 {
+		p.pieces = p.hash.Sum(p.pieces)
+		p.hash.Reset()
+		p.written = 0
+	}
+	return p.pieces
+}
+
+// bencode - appends the bencoding of v to buf, v is one of string,
+// []byte, int64, []interface{} or map[string]interface{}.
+func bencode(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		bencode(buf, []byte(v))
+	case []byte:
+		buf.WriteString(strconv.Itoa(len(v)))
+		buf.WriteByte(':')
+		buf.Write(v)
+	case int64:
+		buf.WriteByte('i')
+		buf.WriteString(strconv.FormatInt(v, 10))
+		buf.WriteByte('e')
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, elem := range v {
+			bencode(buf, elem)
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		// Keys must appear in sorted order.
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('d')
+		for _, key := range keys {
+			bencode(buf, key)
+			bencode(buf, v[key])
+		}
+		buf.WriteByte('e')
+	}
+}
+
+// GetObjectTorrentHandler - GET Object?torrent
+// ----------
+// This operation returns a BitTorrent metainfo file for the object
+// with this server as web seed, allowing large objects to be
+// distributed peer-to-peer.
+func (api objectAPIHandlers) GetObjectTorrentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:GetObject", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Encrypted objects can not be read without their key.
+	if isEncryptedObject(objInfo) {
+		writeErrorResponse(w, r, ErrSSEEncryptedObject, r.URL.Path)
+		return
+	}
+
+	// Hash all the pieces of the object.
+	hasher := &pieceHasher{pieceLength: torrentPieceLength(objInfo.Size), hash: sha1.New()}
+	if err = objectAPI.GetObject(bucket, object, 0, objInfo.Size, hasher); err != nil {
+		errorIf(err, "Unable to read object.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	scheme := "http"
+	if isSSL() {
+		scheme = "https"
+	}
+	webSeed := scheme + "://" + r.Host + "/" + getURLEncodedName(bucket+"/"+object)
+
+	var buf bytes.Buffer
+	bencode(&buf, map[string]interface{}{
+		"created by":    "Minio/" + ReleaseTag,
+		"creation date": objInfo.ModTime.Unix(),
+		"info": map[string]interface{}{
+			"name":         path.Base(object),
+			"length":       objInfo.Size,
+			"piece length": hasher.pieceLength,
+			"pieces":       hasher.Sum(),
+		},
+		"url-list": []interface{}{webSeed},
+	})
+
+	setCommonHeaders(w)
+	w.Header().Set("Content-Type", "application/x-bittorrent")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("Last-Modified", objInfo.ModTime.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, &buf); err != nil {
+		errorIf(err, "Unable to write torrent to client.")
+	}
+}
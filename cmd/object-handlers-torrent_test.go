@@ -0,0 +1,124 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// Tests the choice of torrent piece length.
+func TestTorrentPieceLength(t *testing.T) {
+	testCases := []struct {
+		size                int64
+		expectedPieceLength int64
+	}{
+		{0, minTorrentPieceLength},
+		{100 * 1024 * 1024, minTorrentPieceLength},
+		{1024 * 1024 * 1024, 1024 * 1024},
+		{1024 * 1024 * 1024 * 1024, maxTorrentPieceLength},
+	}
+	for i, testCase := range testCases {
+		if pieceLength := torrentPieceLength(testCase.size); pieceLength != testCase.expectedPieceLength {
+			t.Errorf("Test %d: Expected piece length %d, got %d", i+1, testCase.expectedPieceLength, pieceLength)
+		}
+	}
+}
+
+// Wrapper for calling GetObjectTorrent HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIGetObjectTorrentHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIGetObjectTorrentHandler, []string{"GetObjectTorrent"})
+}
+
+func testAPIGetObjectTorrentHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "dataset/data.bin"
+	data := bytes.Repeat([]byte("a"), 2*minTorrentPieceLength+1024)
+	if _, err := obj.PutObject(bucketName, objectName, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	// Expected pieces are the SHA1 sums of the three pieces.
+	var pieces []byte
+	for offset := 0; offset < len(data); offset += minTorrentPieceLength {
+		end := offset + minTorrentPieceLength
+		if end > len(data) {
+			end = len(data)
+		}
+		sum := sha1.Sum(data[offset:end])
+		pieces = append(pieces, sum[:]...)
+	}
+
+	testCases := []struct {
+		objectName         string
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+	}{
+		// Test case - 1.
+		// Fetch the torrent of an object.
+		{objectName, credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Test case - 2.
+		// Non-existent object.
+		{"missing.bin", credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusNotFound},
+		// Test case - 3.
+		// Invalid credentials.
+		{objectName, "Invalid-AccessID", credentials.SecretAccessKey, http.StatusForbidden},
+	}
+
+	queryValues := url.Values{}
+	queryValues.Set("torrent", "")
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, testCase.objectName, queryValues),
+			0, nil, testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		var expectedInfo bytes.Buffer
+		bencode(&expectedInfo, map[string]interface{}{
+			"name":         "data.bin",
+			"length":       int64(len(data)),
+			"piece length": int64(minTorrentPieceLength),
+			"pieces":       pieces,
+		})
+		torrent := rec.Body.Bytes()
+		if !bytes.Contains(torrent, append([]byte("4:info"), expectedInfo.Bytes()...)) {
+			t.Errorf("Test %d: %s: Unexpected info dictionary in %q", i+1, instanceType, torrent)
+		}
+		webSeed := "http://" + req.Host + "/" + bucketName + "/" + objectName
+		if !bytes.Contains(torrent, []byte("8:url-listl"+strconv.Itoa(len(webSeed))+":"+webSeed+"e")) {
+			t.Errorf("Test %d: %s: Expected web seed %s in %q", i+1, instanceType, webSeed, torrent)
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != "application/x-bittorrent" {
+			t.Errorf("Test %d: %s: Unexpected Content-Type %s", i+1, instanceType, contentType)
+		}
+	}
+}
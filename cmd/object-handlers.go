@@ -27,6 +27,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	mux "github.com/gorilla/mux"
 )
@@ -59,8 +60,10 @@ func errAllowableObjectNotFound(bucket string, r *http.Request) APIErrorCode {
 		//we care about the bucket as a whole, not a particular resource
 		url := *r.URL
 		url.Path = "/" + bucket
+		req := *r
+		req.URL = &url
 
-		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", &url); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", &req); s3Error != ErrNone {
 			return ErrAccessDenied
 		}
 	}
@@ -74,6 +77,76 @@ func (f funcToWriter) Write(p []byte) (int, error) {
 	return f(p)
 }
 
+// getRequestRange - returns the range requested with the Range header
+// or the partNumber query parameter, nil for the whole object. An
+// unsatisfiable range is replied to with 416 and the object size in
+// Content-Range, and false is returned.
+func getRequestRange(w http.ResponseWriter, r *http.Request, objInfo ObjectInfo) (*httpRange, bool) {
+	rangeHeader := r.Header.Get("Range")
+	if partNumber := r.URL.Query().Get("partNumber"); partNumber != "" {
+		if rangeHeader != "" {
+			writeErrorResponse(w, r, ErrInvalidRangePartNumber, r.URL.Path)
+			return nil, false
+		}
+		hrange, apiErr := getPartRange(w, objInfo, partNumber)
+		if apiErr != ErrNone {
+			writeErrorResponse(w, r, apiErr, r.URL.Path)
+			return nil, false
+		}
+		return hrange, true
+	}
+	if rangeHeader == "" {
+		return nil, true
+	}
+	hrange, err := parseRequestRange(rangeHeader, objInfo.Size)
+	if err != nil {
+		// Handle only errInvalidRange
+		// Ignore other parse error and treat it as regular Get request like Amazon S3.
+		if err == errInvalidRange {
+			w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(objInfo.Size, 10))
+			writeErrorResponse(w, r, ErrInvalidRange, r.URL.Path)
+			return nil, false
+		}
+
+		// log the error.
+		errorIf(err, "Invalid request range")
+		return nil, true
+	}
+	return hrange, true
+}
+
+// getPartRange - returns the range of a part of an object selected by
+// partNumber, and reports the number of parts of multipart objects.
+// Objects not uploaded in parts have a single part.
+func getPartRange(w http.ResponseWriter, objInfo ObjectInfo, partNumber string) (*httpRange, APIErrorCode) {
+	partID, err := strconv.Atoi(partNumber)
+	if err != nil || partID < 1 || isMaxPartID(partID) {
+		return nil, ErrMalformedPartNumber
+	}
+	parts := []objectPartInfo{{Number: 1, Size: objInfo.Size}}
+	if strings.Contains(objInfo.MD5Sum, "-") {
+		// Objects completed by older releases on FS did not keep
+		// their parts.
+		if len(objInfo.Parts) == 0 {
+			return nil, ErrUnknownPartBoundaries
+		}
+		parts = objInfo.Parts
+		w.Header().Set("X-Amz-Mp-Parts-Count", strconv.Itoa(len(parts)))
+	}
+	if partID > len(parts) {
+		return nil, ErrInvalidPartNumber
+	}
+	var offset int64
+	for _, part := range parts[:partID-1] {
+		offset += part.Size
+	}
+	if parts[partID-1].Size == 0 {
+		// An empty object is served whole.
+		return nil, ErrNone
+	}
+	return &httpRange{offset, offset + parts[partID-1].Size - 1, objInfo.Size}, ErrNone
+}
+
 // GetObjectHandler - GET Object
 // ----------
 // This implementation of the GET operation retrieves object. To use GET,
@@ -96,7 +169,7 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	objInfo, err := getRequestedObjectInfo(objectAPI, bucket, object, r)
 	if err != nil {
 		errorIf(err, "Unable to fetch object info.")
 		apiErr := toAPIErrorCode(err)
@@ -107,22 +180,30 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Get request range.
-	var hrange *httpRange
-	rangeHeader := r.Header.Get("Range")
-	if rangeHeader != "" {
-		if hrange, err = parseRequestRange(rangeHeader, objInfo.Size); err != nil {
-			// Handle only errInvalidRange
-			// Ignore other parse error and treat it as regular Get request like Amazon S3.
-			if err == errInvalidRange {
-				writeErrorResponse(w, r, ErrInvalidRange, r.URL.Path)
-				return
-			}
+	// Encrypted objects are only served with the key they were
+	// encrypted with.
+	objectKey, err := getRequestObjectKey(r, objInfo)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
 
-			// log the error.
-			errorIf(err, "Invalid request range")
+	// Serve the decompressed content of gzip encoded objects if
+	// asked, ranges and lengths apply to the decompressed content.
+	storedInfo := objInfo
+	decompress := objectKey == nil && isDecompressRequest(r, objInfo)
+	if decompress {
+		if objInfo, err = getDecompressedObjectInfo(objectAPI, storedInfo); err != nil {
+			errorIf(err, "Unable to decompress object.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
 		}
+	}
 
+	// Get request range.
+	hrange, ok := getRequestRange(w, r, objInfo)
+	if !ok {
+		return
 	}
 
 	// Validate pre-conditions if any.
@@ -130,6 +211,16 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Report the number of tags of the stored object.
+	setObjectTaggingCount(w, objectAPI, storedInfo)
+
+	// Stream the object through the bucket transform, if configured.
+	// Transforms do not apply to encrypted objects.
+	if tcfg := globalBucketTransforms.GetBucketTransform(bucket); tcfg != nil && objectKey == nil {
+		transformObject(w, r, objectAPI, storedInfo, tcfg)
+		return
+	}
+
 	// Get the object.
 	startOffset := int64(0)
 	length := objInfo.Size
@@ -155,7 +246,17 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 	})
 
 	// Reads the object at startOffset and writes to mw.
-	if err := objectAPI.GetObject(bucket, object, startOffset, length, writer); err != nil {
+	if decompress {
+		err = getDecompressedObject(objectAPI, storedInfo, startOffset, length, writer)
+	} else if objectKey != nil {
+		var decryptWriter io.Writer
+		if decryptWriter, err = newDecryptWriter(writer, objectKey, startOffset); err == nil {
+			err = objectAPI.GetObject(storedInfo.Bucket, storedInfo.Name, startOffset, length, decryptWriter)
+		}
+	} else {
+		err = objectAPI.GetObject(storedInfo.Bucket, storedInfo.Name, startOffset, length, writer)
+	}
+	if err != nil {
 		errorIf(err, "Unable to write to client.")
 		if !dataWritten {
 			// Error response only if no data has been written to client yet. i.e if
@@ -195,7 +296,7 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	objInfo, err := getRequestedObjectInfo(objectAPI, bucket, object, r)
 	if err != nil {
 		errorIf(err, "Unable to fetch object info.")
 		apiErr := toAPIErrorCode(err)
@@ -206,18 +307,69 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Metadata of encrypted objects is only returned with the key
+	// they were encrypted with.
+	objectKey, err := getRequestObjectKey(r, objInfo)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Report the number of tags of the stored object.
+	setObjectTaggingCount(w, objectAPI, objInfo)
+
+	// Report the length a decompressing GET would serve.
+	if objectKey == nil && isDecompressRequest(r, objInfo) {
+		if objInfo, err = getDecompressedObjectInfo(objectAPI, objInfo); err != nil {
+			errorIf(err, "Unable to decompress object.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+
+	// Report the range a GET would serve, so clients can plan
+	// ranged downloads without fetching data.
+	hrange, ok := getRequestRange(w, r, objInfo)
+	if !ok {
+		return
+	}
+
 	// Validate pre-conditions if any.
 	if checkPreconditions(w, r, objInfo) {
 		return
 	}
 
-	// Set standard object headers.
-	setObjectHeaders(w, objInfo, nil)
+	// Set standard object headers, with 206 for a range.
+	setObjectHeaders(w, objInfo, hrange)
+	if hrange != nil {
+		return
+	}
 
 	// Successful response.
 	w.WriteHeader(http.StatusOK)
 }
 
+// parseCopySource - returns the source bucket and object of a copy
+// from the X-Amz-Copy-Source header, and the header value unescaped.
+func parseCopySource(r *http.Request) (sourceBucket, sourceObject, objectSource string) {
+	objectSource, err := url.QueryUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		// Save unescaped string as is.
+		objectSource = r.Header.Get("X-Amz-Copy-Source")
+	}
+
+	// Skip the first element if it is '/', split the rest.
+	objectSource = strings.TrimPrefix(objectSource, "/")
+	splits := strings.SplitN(objectSource, "/", 2)
+
+	// Save sourceBucket and sourceObject extracted from url Path.
+	if len(splits) == 2 {
+		sourceBucket = splits[0]
+		sourceObject = splits[1]
+	}
+	return sourceBucket, sourceObject, objectSource
+}
+
 // CopyObjectHandler - Copy Object
 // ----------
 // This implementation of the PUT operation adds an object to a bucket
@@ -240,23 +392,7 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 
 	// TODO: Reject requests where body/payload is present, for now we don't even read it.
 
-	// objectSource
-	objectSource, err := url.QueryUnescape(r.Header.Get("X-Amz-Copy-Source"))
-	if err != nil {
-		// Save unescaped string as is.
-		objectSource = r.Header.Get("X-Amz-Copy-Source")
-	}
-
-	// Skip the first element if it is '/', split the rest.
-	objectSource = strings.TrimPrefix(objectSource, "/")
-	splits := strings.SplitN(objectSource, "/", 2)
-
-	// Save sourceBucket and sourceObject extracted from url Path.
-	var sourceBucket, sourceObject string
-	if len(splits) == 2 {
-		sourceBucket = splits[0]
-		sourceObject = splits[1]
-	}
+	sourceBucket, sourceObject, objectSource := parseCopySource(r)
 	// If source object is empty, reply back error.
 	if sourceObject == "" {
 		writeErrorResponse(w, r, ErrInvalidCopySource, r.URL.Path)
@@ -287,21 +423,18 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Size of object.
-	size := objInfo.Size
-
-	pipeReader, pipeWriter := io.Pipe()
-	go func() {
-		startOffset := int64(0) // Read the whole file.
-		// Get the object.
-		gErr := objectAPI.GetObject(sourceBucket, sourceObject, startOffset, size, pipeWriter)
-		if gErr != nil {
-			errorIf(gErr, "Unable to read an object.")
-			pipeWriter.CloseWithError(gErr)
-			return
-		}
-		pipeWriter.Close() // Close.
-	}()
+	// Encrypted sources are decrypted with the copy source key, and
+	// the copy is encrypted with the destination key, if any.
+	sourceKey, err := getCopySourceObjectKey(r, objInfo)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), objectSource)
+		return
+	}
+	customerKey, err := parseSSECustomerRequest(r)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
 
 	// Save other metadata if available.
 	metadata := objInfo.UserDefined
@@ -309,25 +442,55 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	// Remove the etag from source metadata because if it was uploaded as a multipart object
 	// then its ETag will not be MD5sum of the object.
 	delete(metadata, "md5Sum")
+	removeSSEMetadata(metadata)
+
+	// The source may be replaced after its preconditions were checked, verify that
+	// a conditional copy has read the content they were checked against. The ETag
+	// of an encrypted source is not the MD5 of its plain data.
+	verifySource := isCopyConditional(r) && sourceKey == nil && objInfo.MD5Sum != "" && !strings.Contains(objInfo.MD5Sum, "-")
+	if verifySource {
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata["md5Sum"] = objInfo.MD5Sum
+	}
 
-	sha256sum := ""
-	// Create the object.
-	objInfo, err = objectAPI.PutObject(bucket, object, size, pipeReader, metadata, sha256sum)
+	// The source version ID does not carry over to the copy.
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	versionID, err := prepareObjectVersion(objectAPI, bucket, object, metadata)
 	if err != nil {
-		// Close the this end of the pipe upon error in PutObject.
-		pipeReader.CloseWithError(err)
-		errorIf(err, "Unable to create an object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
-	// Explicitly close the reader, before fetching object info.
-	pipeReader.Close()
+
+	// Copy the object.
+	if sourceKey != nil || customerKey != nil {
+		objInfo, err = copyEncryptedObject(objectAPI, objInfo, sourceKey, bucket, object, customerKey, metadata)
+	} else {
+		objInfo, err = objectAPI.CopyObject(sourceBucket, sourceObject, bucket, object, metadata)
+	}
+	if err != nil {
+		if _, ok := errorCause(err).(BadDigest); ok && verifySource {
+			writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
+			return
+		}
+		errorIf(err, "Unable to copy an object.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
 
 	md5Sum := objInfo.MD5Sum
 	response := generateCopyObjectResponse(md5Sum, objInfo.ModTime)
 	encodedSuccessResponse := encodeResponse(response)
 	// write headers
 	setCommonHeaders(w)
+	if versionID != "" {
+		w.Header().Set(objectVersionIDHeader, versionID)
+	}
+	setSSEHeaders(w, objInfo)
+	setKMSHeaders(w, objInfo)
 	// write success response.
 	writeSuccessResponse(w, encodedSuccessResponse)
 
@@ -393,6 +556,20 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Validate the tags to set on the object, if any.
+	tags, err := parseTaggingHeader(r.Header.Get("X-Amz-Tagging"))
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Encrypt the object with the customer provided key, if any.
+	customerKey, err := parseSSECustomerRequest(r)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
 	// Extract metadata to be saved from incoming HTTP header.
 	metadata := extractMetadataFromHeader(r.Header)
 	// Make sure we hex encode md5sum here.
@@ -400,6 +577,22 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 
 	sha256sum := ""
 
+	// Keep the version replaced by the object once the request is
+	// authenticated.
+	var versionID string
+	putObject := func(reader io.Reader) (ObjectInfo, error) {
+		if versionID, err = prepareObjectVersion(objectAPI, bucket, object, metadata); err != nil {
+			return ObjectInfo{}, err
+		}
+		if customerKey != nil {
+			if reader, err = newEncryptReader(reader, size, customerKey, metadata, sha256sum); err != nil {
+				return ObjectInfo{}, err
+			}
+			return objectAPI.PutObject(bucket, object, size, reader, metadata, "")
+		}
+		return objectAPI.PutObject(bucket, object, size, reader, metadata, sha256sum)
+	}
+
 	var objInfo ObjectInfo
 	switch rAuthType {
 	default:
@@ -408,12 +601,12 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
 		// Create anonymous object.
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
+		objInfo, err = putObject(r.Body)
 	case authTypeStreamingSigned:
 		// Initialize stream signature verifier.
 		reader, s3Error := newSignV4ChunkedReader(r)
@@ -422,17 +615,22 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		objInfo, err = objectAPI.PutObject(bucket, object, size, reader, metadata, sha256sum)
+		objInfo, err = putObject(reader)
 	case authTypeSignedV2, authTypePresignedV2:
-		s3Error := isReqAuthenticatedV2(r)
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return isReqAuthenticatedV2(r)
+		})
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
+		objInfo, err = putObject(r.Body)
 	case authTypePresigned, authTypeSigned:
-		if s3Error := reqSignatureV4Verify(r); s3Error != ErrNone {
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return reqSignatureV4Verify(r)
+		})
+		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -441,13 +639,25 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			sha256sum = r.Header.Get("X-Amz-Content-Sha256")
 		}
 		// Create object.
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
+		objInfo, err = putObject(r.Body)
 	}
 	if err != nil {
 		errorIf(err, "Unable to create an object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
+	if len(tags) > 0 {
+		if err = setObjectTags(objectAPI, objInfo, tags); err != nil {
+			errorIf(err, "Unable to save tags of object %s/%s.", bucket, object)
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	if versionID != "" {
+		w.Header().Set(objectVersionIDHeader, versionID)
+	}
+	setSSEHeaders(w, objInfo)
+	setKMSHeaders(w, objInfo)
 	w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 	writeSuccessResponse(w, nil)
 
@@ -482,9 +692,23 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	// Parts are not encrypted with customer provided keys.
+	if hasSSECustomerHeaders(r.Header) {
+		writeErrorResponse(w, r, ErrNotImplemented, r.URL.Path)
+		return
+	}
+
 	// Extract metadata that needs to be saved.
 	metadata := extractMetadataFromHeader(r.Header)
 
+	// The version ID is assigned when the upload starts.
+	status, err := getBucketVersioning(objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	setObjectVersionID(metadata, status)
+
 	uploadID, err := objectAPI.NewMultipartUpload(bucket, object, metadata)
 	if err != nil {
 		errorIf(err, "Unable to initiate new multipart upload id.")
@@ -519,7 +743,8 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	/// if Content-Length is unknown/missing, throw away
+	/// if Content-Length is unknown/missing, throw away unless the
+	/// client streams the part with chunked transfer encoding.
 	size := r.ContentLength
 
 	rAuthType := getRequestAuthType(r)
@@ -533,7 +758,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 			return
 		}
 	}
-	if size == -1 {
+	if size == -1 && !contains(r.TransferEncoding, "chunked") {
 		writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
 		return
 	}
@@ -543,6 +768,11 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
 		return
 	}
+	// Parts of unknown length fail once past the maximum part size.
+	var body io.Reader = r.Body
+	if size == -1 {
+		body = &rangeReader{Reader: r.Body, Max: maxPartSize}
+	}
 
 	uploadID := r.URL.Query().Get("uploadId")
 	partIDString := r.URL.Query().Get("partNumber")
@@ -569,12 +799,12 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
 		// No need to verify signature, anonymous request access is already allowed.
-		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, r.Body, incomingMD5, sha256sum)
+		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, body, incomingMD5, sha256sum)
 	case authTypeStreamingSigned:
 		// Initialize stream signature verifier.
 		reader, s3Error := newSignV4ChunkedReader(r)
@@ -585,15 +815,20 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		}
 		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, reader, incomingMD5, sha256sum)
 	case authTypeSignedV2, authTypePresignedV2:
-		s3Error := isReqAuthenticatedV2(r)
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return isReqAuthenticatedV2(r)
+		})
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, r.Body, incomingMD5, sha256sum)
+		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, body, incomingMD5, sha256sum)
 	case authTypePresigned, authTypeSigned:
-		if s3Error := reqSignatureV4Verify(r); s3Error != ErrNone {
+		s3Error := throttleAuth(r, func() APIErrorCode {
+			return reqSignatureV4Verify(r)
+		})
+		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -602,7 +837,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		if !skipContentSha256Cksum(r) {
 			sha256sum = r.Header.Get("X-Amz-Content-Sha256")
 		}
-		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, r.Body, incomingMD5, sha256sum)
+		partMD5, err = objectAPI.PutObjectPart(bucket, object, uploadID, partID, size, body, incomingMD5, sha256sum)
 	}
 	if err != nil {
 		errorIf(err, "Unable to create object part.")
@@ -616,6 +851,119 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 	writeSuccessResponse(w, nil)
 }
 
+// CopyObjectPartHandler - Upload part copy
+// ----------
+// This implementation of the PUT operation uploads a part of a
+// multipart upload by copying data from an existing object, all of it
+// or the bytes given with x-amz-copy-source-range.
+func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:PutObject", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	sourceBucket, sourceObject, objectSource := parseCopySource(r)
+	// If source object is empty, reply back error.
+	if sourceObject == "" {
+		writeErrorResponse(w, r, ErrInvalidCopySource, r.URL.Path)
+		return
+	}
+
+	uploadID := r.URL.Query().Get("uploadId")
+	partID, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil {
+		writeErrorResponse(w, r, ErrInvalidPart, r.URL.Path)
+		return
+	}
+
+	// check partID with maximum part ID for multipart objects
+	if isMaxPartID(partID) {
+		writeErrorResponse(w, r, ErrInvalidMaxParts, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(sourceBucket, sourceObject)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), objectSource)
+		return
+	}
+
+	// Copy the whole object unless a range is given.
+	startOffset, length := int64(0), objInfo.Size
+	if rangeString := r.Header.Get("x-amz-copy-source-range"); rangeString != "" {
+		hrange, s3Error := parseCopyPartRange(rangeString, objInfo.Size)
+		if s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		startOffset, length = hrange.offsetBegin, hrange.getLength()
+	}
+
+	// Verify before x-amz-copy-source preconditions before continuing with CopyObjectPart.
+	if checkCopyObjectPreconditions(w, r, objInfo) {
+		return
+	}
+
+	/// maximum Upload size for multipart objects in a single operation
+	if isMaxObjectSize(length) {
+		writeErrorResponse(w, r, ErrEntityTooLarge, objectSource)
+		return
+	}
+
+	// Encrypted sources are decrypted with the copy source key.
+	sourceKey, err := getCopySourceObjectKey(r, objInfo)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), objectSource)
+		return
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	var sourceWriter io.Writer = pipeWriter
+	if sourceKey != nil {
+		if sourceWriter, err = newDecryptWriter(pipeWriter, sourceKey, startOffset); err != nil {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	go func() {
+		gErr := objectAPI.GetObject(sourceBucket, sourceObject, startOffset, length, sourceWriter)
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+
+	partMD5, err := objectAPI.PutObjectPart(bucket, object, uploadID, partID, length, pipeReader, "", "")
+	if err != nil {
+		// Close the this end of the pipe upon error in PutObjectPart.
+		pipeReader.CloseWithError(err)
+		errorIf(err, "Unable to create object part.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	// Explicitly close the reader.
+	pipeReader.Close()
+
+	response := generateCopyObjectPartResponse(partMD5, time.Now().UTC())
+	encodedSuccessResponse := encodeResponse(response)
+	// write headers
+	setCommonHeaders(w)
+	// write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
 // AbortMultipartUploadHandler - Abort multipart upload
 func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -731,6 +1079,18 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		completeParts = append(completeParts, part)
 	}
 
+	// Keep the version replaced by the completed object.
+	status, err := getBucketVersioning(objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if err = archiveObjectVersion(objectAPI, bucket, object, status); err != nil {
+		errorIf(err, "Unable to keep the current version of %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
 	md5Sum, err = objectAPI.CompleteMultipartUpload(bucket, object, uploadID, completeParts)
 	if err != nil {
 		err = errorCause(err)
@@ -757,6 +1117,13 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		return
 	}
 
+	// Set the version ID of objects in versioned buckets.
+	if status != "" {
+		if objInfo, vErr := objectAPI.GetObjectInfo(bucket, object); vErr == nil && objInfo.UserDefined[objectVersionIDHeader] != "" {
+			w.Header().Set(objectVersionIDHeader, objInfo.UserDefined[objectVersionIDHeader])
+		}
+	}
+
 	// Set etag.
 	w.Header().Set("ETag", "\""+md5Sum+"\"")
 
@@ -801,13 +1168,30 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Permanently delete a version of the object, if requested.
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		if err := deleteObjectVersion(objectAPI, bucket, object, versionID); err != nil {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+		w.Header().Set(objectVersionIDHeader, versionID)
+		writeSuccessNoContent(w)
+		return
+	}
+
 	/// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	/// Ignore delete object errors, since we are suppposed to reply
-	/// only 204.
-	if err := objectAPI.DeleteObject(bucket, object); err != nil {
+	/// only 204. Like S3, a missing bucket is still reported.
+	if err := deleteObjectVersioned(objectAPI, bucket, object); err != nil {
+		if _, err = objectAPI.GetBucketInfo(bucket); err != nil {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
 		writeSuccessNoContent(w)
 		return
 	}
+	// Tags are bound to the deleted object - ignore any errors.
+	_ = removeObjectTags(objectAPI, bucket, object)
 	writeSuccessNoContent(w)
 
 	// Notify object deleted event.
@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -43,6 +45,7 @@ const (
 	BadSignature
 	BadMD5
 	MissingUploadID
+	ChunkedEncoding
 )
 
 // Wrapper for calling HeadObject API handler tests for both XL multiple disks and FS single drive setup.
@@ -191,6 +194,171 @@ func testAPIHeadObjectHandler(obj ObjectLayer, instanceType, bucketName string,
 	ExecObjectLayerAPINilTest(t, nilBucket, nilObject, instanceType, apiRouter, nilReq)
 }
 
+// Wrapper for calling HeadObject API handler tests with a Range header for both XL multiple disks and FS single drive setup.
+func TestAPIHeadObjectHandlerWithRange(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIHeadObjectHandlerWithRange, []string{"HeadObject", "GetObject"})
+}
+
+func testAPIHeadObjectHandlerWithRange(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "test-object"
+	data := generateBytesData(10 * humanize.KiByte)
+	if _, err := obj.PutObject(bucketName, objectName, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	testCases := []struct {
+		byteRange string
+		// expected output.
+		expectedRespStatus    int
+		expectedContentLength string
+		expectedContentRange  string
+	}{
+		// Test case - 1.
+		// No range.
+		{"", http.StatusOK, "10240", ""},
+		// Test case - 2.
+		// First and last byte positions.
+		{"bytes=0-1023", http.StatusPartialContent, "1024", "bytes 0-1023/10240"},
+		// Test case - 3.
+		// Suffix range.
+		{"bytes=-1024", http.StatusPartialContent, "1024", "bytes 9216-10239/10240"},
+		// Test case - 4.
+		// Last byte position past the end.
+		{"bytes=10000-20000", http.StatusPartialContent, "240", "bytes 10000-10239/10240"},
+		// Test case - 5.
+		// Unsatisfiable range.
+		{"bytes=10240-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10240"},
+		// Test case - 6.
+		// Malformed ranges are ignored like Amazon S3.
+		{"bytes=abc", http.StatusOK, "10240", ""},
+	}
+
+	for i, testCase := range testCases {
+		for _, method := range []string{"HEAD", "GET"} {
+			rec := httptest.NewRecorder()
+			req, err := newTestSignedRequestV4(method, getGetObjectURL("", bucketName, objectName),
+				0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+			if err != nil {
+				t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, method, err)
+			}
+			if testCase.byteRange != "" {
+				req.Header.Set("Range", testCase.byteRange)
+			}
+			apiRouter.ServeHTTP(rec, req)
+			if rec.Code != testCase.expectedRespStatus {
+				t.Fatalf("Test %d: %s: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, method, testCase.expectedRespStatus, rec.Code)
+			}
+			if rec.Header().Get("Accept-Ranges") != "bytes" {
+				t.Errorf("Test %d: %s: %s: Expected Accept-Ranges bytes, got %q", i+1, instanceType, method, rec.Header().Get("Accept-Ranges"))
+			}
+			if contentRange := rec.Header().Get("Content-Range"); contentRange != testCase.expectedContentRange {
+				t.Errorf("Test %d: %s: %s: Expected Content-Range %q, got %q", i+1, instanceType, method, testCase.expectedContentRange, contentRange)
+			}
+			if testCase.expectedContentLength == "" {
+				continue
+			}
+			if contentLength := rec.Header().Get("Content-Length"); contentLength != testCase.expectedContentLength {
+				t.Errorf("Test %d: %s: %s: Expected Content-Length %s, got %s", i+1, instanceType, method, testCase.expectedContentLength, contentLength)
+			}
+			if method == "GET" && strconv.Itoa(rec.Body.Len()) != testCase.expectedContentLength {
+				t.Errorf("Test %d: %s: Expected %s bytes, got %d", i+1, instanceType, testCase.expectedContentLength, rec.Body.Len())
+			}
+		}
+	}
+}
+
+// Wrapper for calling GetObject and HeadObject API handler tests with partNumber for both XL multiple disks and FS single drive setup.
+func TestAPIGetObjectHandlerWithPartNumber(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIGetObjectHandlerWithPartNumber, []string{"HeadObject", "GetObject"})
+}
+
+func testAPIGetObjectHandlerWithPartNumber(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	// A multipart object of two parts, and an object uploaded at once.
+	parts := [][]byte{generateBytesData(5 * humanize.MiByte), generateBytesData(1 * humanize.KiByte)}
+	uploadID, err := obj.NewMultipartUpload(bucketName, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: Error initiating upload: <ERROR> %v", instanceType, err)
+	}
+	var completeParts []completePart
+	for i, part := range parts {
+		md5Sum, err := obj.PutObjectPart(bucketName, "multipart", uploadID, i+1, int64(len(part)), bytes.NewReader(part), "", "")
+		if err != nil {
+			t.Fatalf("%s: Error uploading part: <ERROR> %v", instanceType, err)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: md5Sum})
+	}
+	if _, err = obj.CompleteMultipartUpload(bucketName, "multipart", uploadID, completeParts); err != nil {
+		t.Fatalf("%s: Error completing upload: <ERROR> %v", instanceType, err)
+	}
+	data := generateBytesData(10 * humanize.KiByte)
+	if _, err = obj.PutObject(bucketName, "single", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	testCases := []struct {
+		objectName string
+		partNumber string
+		byteRange  string
+		// expected output.
+		expectedRespStatus   int
+		expectedContentRange string
+		expectedPartsCount   string
+		expectedData         []byte
+	}{
+		// Test case - 1.
+		// First part of a multipart object.
+		{"multipart", "1", "", http.StatusPartialContent, "bytes 0-5242879/5243904", "2", parts[0]},
+		// Test case - 2.
+		// Last part of a multipart object.
+		{"multipart", "2", "", http.StatusPartialContent, "bytes 5242880-5243903/5243904", "2", parts[1]},
+		// Test case - 3.
+		// Part past the last one, the number of parts is still reported.
+		{"multipart", "3", "", http.StatusRequestedRangeNotSatisfiable, "", "2", nil},
+		// Test case - 4.
+		// Malformed part number.
+		{"multipart", "0", "", http.StatusBadRequest, "", "", nil},
+		// Test case - 5.
+		// Part number along with a range.
+		{"multipart", "1", "bytes=0-9", http.StatusBadRequest, "", "", nil},
+		// Test case - 6.
+		// Objects uploaded at once have a single part.
+		{"single", "1", "", http.StatusPartialContent, "bytes 0-10239/10240", "", data},
+		// Test case - 7.
+		{"single", "2", "", http.StatusRequestedRangeNotSatisfiable, "", "", nil},
+	}
+
+	for i, testCase := range testCases {
+		for _, method := range []string{"HEAD", "GET"} {
+			rec := httptest.NewRecorder()
+			req, err := newTestSignedRequestV4(method, getGetObjectURL("", bucketName, testCase.objectName)+"?partNumber="+testCase.partNumber,
+				0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+			if err != nil {
+				t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, method, err)
+			}
+			if testCase.byteRange != "" {
+				req.Header.Set("Range", testCase.byteRange)
+			}
+			apiRouter.ServeHTTP(rec, req)
+			if rec.Code != testCase.expectedRespStatus {
+				t.Fatalf("Test %d: %s: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, method, testCase.expectedRespStatus, rec.Code)
+			}
+			if contentRange := rec.Header().Get("Content-Range"); contentRange != testCase.expectedContentRange {
+				t.Errorf("Test %d: %s: %s: Expected Content-Range %q, got %q", i+1, instanceType, method, testCase.expectedContentRange, contentRange)
+			}
+			if partsCount := rec.Header().Get("X-Amz-Mp-Parts-Count"); partsCount != testCase.expectedPartsCount {
+				t.Errorf("Test %d: %s: %s: Expected parts count %q, got %q", i+1, instanceType, method, testCase.expectedPartsCount, partsCount)
+			}
+			if method == "GET" && testCase.expectedData != nil && !bytes.Equal(rec.Body.Bytes(), testCase.expectedData) {
+				t.Errorf("Test %d: %s: Expected the data of the part, got %d bytes", i+1, instanceType, rec.Body.Len())
+			}
+		}
+	}
+}
+
 // Wrapper for calling GetObject API handler tests for both XL multiple disks and FS single drive setup.
 func TestAPIGetObjectHandler(t *testing.T) {
 	defer DetectTestLeak(t)()
@@ -1160,6 +1328,107 @@ func testAPICopyObjectHandler(obj ObjectLayer, instanceType, bucketName string,
 
 }
 
+// Wrapper for calling Copy Object Part API handler tests for both XL multiple disks and single node setup.
+func TestAPICopyObjectPartHandler(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPICopyObjectPartHandler, []string{"CopyObjectPart"})
+}
+
+func testAPICopyObjectPartHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+
+	objectName := "test-object"
+	data := generateBytesData(6 * humanize.KiByte)
+	objInfo, err := obj.PutObject(bucketName, objectName, int64(len(data)), bytes.NewReader(data), nil, "")
+	if err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+	uploadID, err := obj.NewMultipartUpload(bucketName, "new-object", nil)
+	if err != nil {
+		t.Fatalf("%s: Error starting multipart upload: <ERROR> %v", instanceType, err)
+	}
+
+	testCases := []struct {
+		uploadID         string
+		partNumber       string
+		copySourceHeader string
+		copySourceRange  string
+		copySourceIfNone string
+		accessKey        string
+		// expected output.
+		expectedRespStatus int
+		expectedData       []byte
+	}{
+		// Test case - 1.
+		// Copy the whole object.
+		{uploadID, "1", url.QueryEscape("/" + bucketName + "/" + objectName), "", "", credentials.AccessKeyID,
+			http.StatusOK, data},
+		// Test case - 2.
+		// Copy a range of the object.
+		{uploadID, "2", url.QueryEscape("/" + bucketName + "/" + objectName), "bytes=1024-2047", "", credentials.AccessKeyID,
+			http.StatusOK, data[1024:2048]},
+		// Test case - 3.
+		// Range with a missing last byte position.
+		{uploadID, "3", url.QueryEscape("/" + bucketName + "/" + objectName), "bytes=1024-", "", credentials.AccessKeyID,
+			http.StatusBadRequest, nil},
+		// Test case - 4.
+		// Range beyond the end of the object.
+		{uploadID, "3", url.QueryEscape("/" + bucketName + "/" + objectName), "bytes=0-6144", "", credentials.AccessKeyID,
+			http.StatusBadRequest, nil},
+		// Test case - 5.
+		// Failed copy source precondition.
+		{uploadID, "3", url.QueryEscape("/" + bucketName + "/" + objectName), "", objInfo.MD5Sum, credentials.AccessKeyID,
+			http.StatusPreconditionFailed, nil},
+		// Test case - 6.
+		// Non-existent source object.
+		{uploadID, "3", url.QueryEscape("/" + bucketName + "/non-existent-object"), "", "", credentials.AccessKeyID,
+			http.StatusNotFound, nil},
+		// Test case - 7.
+		// Invalid copy source.
+		{uploadID, "3", url.QueryEscape("/"), "", "", credentials.AccessKeyID,
+			http.StatusBadRequest, nil},
+		// Test case - 8.
+		// Non-existent upload.
+		{"invalid-upload-id", "3", url.QueryEscape("/" + bucketName + "/" + objectName), "", "", credentials.AccessKeyID,
+			http.StatusNotFound, nil},
+		// Test case - 9.
+		// Invalid access key.
+		{uploadID, "3", url.QueryEscape("/" + bucketName + "/" + objectName), "", "", "Invalid-AccessID",
+			http.StatusForbidden, nil},
+	}
+
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("PUT", getPutObjectPartURL("", bucketName, "new-object", testCase.uploadID, testCase.partNumber),
+			0, nil, testCase.accessKey, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request for copy object part: <ERROR> %v", i+1, err)
+		}
+		req.Header.Set("X-Amz-Copy-Source", testCase.copySourceHeader)
+		if testCase.copySourceRange != "" {
+			req.Header.Set("X-Amz-Copy-Source-Range", testCase.copySourceRange)
+		}
+		if testCase.copySourceIfNone != "" {
+			req.Header.Set("X-Amz-Copy-Source-If-None-Match", testCase.copySourceIfNone)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		response := CopyObjectPartResponse{}
+		if err = xml.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Test %d: %s: Failed to parse the response: <ERROR> %v", i+1, instanceType, err)
+		}
+		md5Sum := md5.Sum(testCase.expectedData)
+		if response.ETag != "\""+hex.EncodeToString(md5Sum[:])+"\"" {
+			t.Errorf("Test %d: %s: Expected the ETag of the copied data, got %s", i+1, instanceType, response.ETag)
+		}
+	}
+}
+
 // Wrapper for calling NewMultipartUpload tests for both XL multiple disks and single node setup.
 // First register the HTTP handler for NewMutlipartUpload, then a HTTP request for NewMultipart upload is made.
 // The UploadID from the response body is parsed and its existence is asserted with an attempt to ListParts using it.
@@ -1984,6 +2253,17 @@ func testAPIDeleteObjectHandler(obj ObjectLayer, instanceType, bucketName string
 
 			expectedRespStatus: http.StatusForbidden,
 		},
+		// Test case - 4.
+		// Attempt to delete an object of a non-existent bucket.
+		// Should return HTTP response status 404, unlike a missing object.
+		{
+			bucketName: "non-existent-bucket",
+			objectName: objectName,
+			accessKey:  credentials.AccessKeyID,
+			secretKey:  credentials.SecretAccessKey,
+
+			expectedRespStatus: http.StatusNotFound,
+		},
 	}
 
 	// Iterating over the cases, call DeleteObjectHandler and validate the HTTP response.
@@ -2315,6 +2595,19 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 			expectedAPIError: missingContent,
 		},
 		// Test case - 5.
+		// Case where the part is streamed with chunked transfer encoding,
+		// content length is unknown but the request should still succeed.
+		{
+			objectName: testObject,
+			reader:     bytes.NewReader([]byte("hello")),
+			partNumber: "1",
+			fault:      ChunkedEncoding,
+			accessKey:  credentials.AccessKeyID,
+			secretKey:  credentials.SecretAccessKey,
+
+			expectedAPIError: noAPIErr,
+		},
+		// Test case - 6.
 		// case where the object size is set to a value greater than the max allowed size.
 		{
 			objectName: testObject,
@@ -2326,7 +2619,7 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 
 			expectedAPIError: entityTooLarge,
 		},
-		// Test case - 6.
+		// Test case - 7.
 		// case where a signature mismatch is introduced and the response is validated.
 		{
 			objectName: testObject,
@@ -2338,7 +2631,7 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 
 			expectedAPIError: badSigning,
 		},
-		// Test case - 7.
+		// Test case - 8.
 		// Case where incorrect checksum is set and the error response
 		// is asserted with the expected error response.
 		{
@@ -2351,7 +2644,7 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 
 			expectedAPIError: badChecksum,
 		},
-		// Test case - 8.
+		// Test case - 9.
 		// case where the a non-existent uploadID is set.
 		{
 			objectName: testObject,
@@ -2363,7 +2656,7 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 
 			expectedAPIError: noSuchUploadID,
 		},
-		// Test case - 9.
+		// Test case - 10.
 		// case with invalid AccessID.
 		// Forcing the signature check inside the handler to fail.
 		{
@@ -2450,16 +2743,21 @@ func testAPIPutObjectPartHandler(obj ObjectLayer, instanceType, bucketName strin
 				switch test.fault {
 				case MissingContentLength:
 					req.ContentLength = -1
+					// Streaming the part without a known content length.
+					// Used in test case  5.
+				case ChunkedEncoding:
+					req.ContentLength = -1
+					req.TransferEncoding = []string{"chunked"}
 					// Setting the content length to a value greater than the max allowed size of a part.
-					// Used in test case  4.
+					// Used in test case  6.
 				case TooBigObject:
 					req.ContentLength = maxObjectSize + 1
 					// Malformed signature.
-					// Used in test case  6.
+					// Used in test case  7.
 				case BadSignature:
 					req.Header.Set("authorization", req.Header.Get("authorization")+"a")
 					// Setting an invalid Content-MD5 to force a Md5 Mismatch error.
-					// Used in tesr case 7.
+					// Used in tesr case 8.
 				case BadMD5:
 					req.Header.Set("Content-MD5", "badmd5")
 				}
@@ -0,0 +1,261 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upper bounds of the latency histogram of the backend operations,
+// slower calls are counted past the last one.
+var backendLatencyBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// LatencyBucket - calls slower than the bound of the previous bucket
+// and at most as slow as UpperBound, zero for the slowest bucket.
+type LatencyBucket struct {
+	UpperBound time.Duration `json:"upperBound"`
+	Count      uint64        `json:"count"`
+}
+
+// BackendOpStats - calls to a single operation of the backend.
+type BackendOpStats struct {
+	Calls  uint64 `json:"calls"`
+	Errors uint64 `json:"errors"`
+	// Object data read from and written to the backend.
+	BytesRead    uint64 `json:"bytesRead"`
+	BytesWritten uint64 `json:"bytesWritten"`
+	// Total and distribution of the time spent in the calls.
+	TotalLatency time.Duration   `json:"totalLatency"`
+	Latency      []LatencyBucket `json:"latency"`
+}
+
+// Global backend metrics, nil unless enabled with MINIO_BACKEND_METRICS.
+var globalBackendMetrics *backendMetrics
+
+// backendMetrics - counters of the calls to the backend per operation.
+type backendMetrics struct {
+	mu  sync.Mutex
+	ops map[string]*BackendOpStats
+}
+
+// parseBackendMetricsEnv - returns whether the calls to the backend are
+// measured.
+func parseBackendMetricsEnv(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "off":
+		return false, nil
+	case "on":
+		return true, nil
+	}
+	return false, fmt.Errorf("Unknown value `%s` for MINIO_BACKEND_METRICS, expected `on` or `off`", value)
+}
+
+// loadBackendMetrics - loads the backend metrics from the environment,
+// nil if disabled.
+func loadBackendMetrics() (*backendMetrics, error) {
+	enabled, err := parseBackendMetricsEnv(os.Getenv("MINIO_BACKEND_METRICS"))
+	if err != nil || !enabled {
+		return nil, err
+	}
+	return &backendMetrics{}, nil
+}
+
+// record - adds a call to op which started at start.
+func (m *backendMetrics) record(op string, start time.Time, err error, bytesRead, bytesWritten int64) {
+	latency := time.Since(start)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ops == nil {
+		m.ops = make(map[string]*BackendOpStats)
+	}
+	stats := m.ops[op]
+	if stats == nil {
+		stats = &BackendOpStats{Latency: make([]LatencyBucket, len(backendLatencyBounds)+1)}
+		for i, bound := range backendLatencyBounds {
+			stats.Latency[i].UpperBound = bound
+		}
+		m.ops[op] = stats
+	}
+	stats.Calls++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.BytesRead += uint64(bytesRead)
+	stats.BytesWritten += uint64(bytesWritten)
+	stats.TotalLatency += latency
+	i := 0
+	for i < len(backendLatencyBounds) && latency > backendLatencyBounds[i] {
+		i++
+	}
+	stats.Latency[i].Count++
+}
+
+// stats - returns the counters of every operation called so far.
+func (m *backendMetrics) stats() map[string]BackendOpStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ops := make(map[string]BackendOpStats, len(m.ops))
+	for op, stats := range m.ops {
+		opStats := *stats
+		opStats.Latency = make([]LatencyBucket, len(stats.Latency))
+		copy(opStats.Latency, stats.Latency)
+		ops[op] = opStats
+	}
+	return ops
+}
+
+// countingReader - counts the bytes read.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingWriter - counts the bytes written.
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// metricsObjects - object layer recording the calls, errors, bytes and
+// latency of every bucket, object and multipart operation of the layer
+// it wraps.
+type metricsObjects struct {
+	ObjectLayer
+	metrics *backendMetrics
+}
+
+// newMetricsObjects - returns objAPI with its calls recorded in metrics.
+func newMetricsObjects(objAPI ObjectLayer, metrics *backendMetrics) ObjectLayer {
+	return metricsObjects{ObjectLayer: objAPI, metrics: metrics}
+}
+
+/// Bucket operations
+
+func (m metricsObjects) MakeBucket(bucket string) (err error) {
+	defer func(start time.Time) { m.metrics.record("MakeBucket", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.MakeBucket(bucket)
+}
+
+func (m metricsObjects) GetBucketInfo(bucket string) (bucketInfo BucketInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("GetBucketInfo", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.GetBucketInfo(bucket)
+}
+
+func (m metricsObjects) ListBuckets() (buckets []BucketInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("ListBuckets", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.ListBuckets()
+}
+
+func (m metricsObjects) DeleteBucket(bucket string) (err error) {
+	defer func(start time.Time) { m.metrics.record("DeleteBucket", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.DeleteBucket(bucket)
+}
+
+func (m metricsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (result ListObjectsInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("ListObjects", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+}
+
+/// Object operations
+
+func (m metricsObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) (err error) {
+	counter := &countingWriter{Writer: writer}
+	defer func(start time.Time) { m.metrics.record("GetObject", start, err, counter.n, 0) }(time.Now())
+	return m.ObjectLayer.GetObject(bucket, object, startOffset, length, counter)
+}
+
+func (m metricsObjects) GetObjectInfo(bucket, object string) (objInfo ObjectInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("GetObjectInfo", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.GetObjectInfo(bucket, object)
+}
+
+func (m metricsObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (objInfo ObjectInfo, err error) {
+	counter := &countingReader{Reader: data}
+	defer func(start time.Time) { m.metrics.record("PutObject", start, err, 0, counter.n) }(time.Now())
+	return m.ObjectLayer.PutObject(bucket, object, size, counter, metadata, sha256sum)
+}
+
+func (m metricsObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (objInfo ObjectInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("CopyObject", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
+func (m metricsObjects) DeleteObject(bucket, object string) (err error) {
+	defer func(start time.Time) { m.metrics.record("DeleteObject", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.DeleteObject(bucket, object)
+}
+
+/// Multipart operations
+
+func (m metricsObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("ListMultipartUploads", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+}
+
+func (m metricsObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (uploadID string, err error) {
+	defer func(start time.Time) { m.metrics.record("NewMultipartUpload", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+}
+
+func (m metricsObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (md5 string, err error) {
+	counter := &countingReader{Reader: data}
+	defer func(start time.Time) { m.metrics.record("PutObjectPart", start, err, 0, counter.n) }(time.Now())
+	return m.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, counter, md5Hex, sha256sum)
+}
+
+func (m metricsObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (result ListPartsInfo, err error) {
+	defer func(start time.Time) { m.metrics.record("ListObjectParts", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+func (m metricsObjects) AbortMultipartUpload(bucket, object, uploadID string) (err error) {
+	defer func(start time.Time) { m.metrics.record("AbortMultipartUpload", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID)
+}
+
+func (m metricsObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (md5 string, err error) {
+	defer func(start time.Time) { m.metrics.record("CompleteMultipartUpload", start, err, 0, 0) }(time.Now())
+	return m.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
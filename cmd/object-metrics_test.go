@@ -0,0 +1,151 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Tests parsing MINIO_BACKEND_METRICS.
+func TestParseBackendMetricsEnv(t *testing.T) {
+	testCases := []struct {
+		value   string
+		enabled bool
+		success bool
+	}{
+		{"", false, true},
+		{"off", false, true},
+		{"On", true, true},
+		{"yes", false, false},
+	}
+	for i, testCase := range testCases {
+		enabled, err := parseBackendMetricsEnv(testCase.value)
+		if testCase.success != (err == nil) || enabled != testCase.enabled {
+			t.Errorf("Test %d: Expected %v, %v, got %v, %v", i+1, testCase.enabled, testCase.success, enabled, err)
+		}
+	}
+}
+
+// Tests that calls are counted in the latency bucket of their duration.
+func TestBackendMetricsRecord(t *testing.T) {
+	metrics := &backendMetrics{}
+	now := time.Now()
+	for _, latency := range []time.Duration{0, time.Millisecond, 2 * time.Millisecond, time.Minute} {
+		metrics.record("GetObject", now.Add(-latency), nil, 10, 0)
+	}
+	metrics.record("GetObject", now, errFaultInjected, 0, 0)
+
+	stats := metrics.stats()["GetObject"]
+	if stats.Calls != 5 || stats.Errors != 1 || stats.BytesRead != 40 || stats.BytesWritten != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats.TotalLatency < time.Minute {
+		t.Errorf("Expected a total latency of at least a minute, got %s", stats.TotalLatency)
+	}
+	if len(stats.Latency) != len(backendLatencyBounds)+1 {
+		t.Fatalf("Expected %d latency buckets, got %d", len(backendLatencyBounds)+1, len(stats.Latency))
+	}
+	// The fastest calls may take longer than a millisecond to record on
+	// a slow machine, the slowest is past all the bounds.
+	if stats.Latency[0].Count+stats.Latency[1].Count < 3 || stats.Latency[len(backendLatencyBounds)].Count != 1 {
+		t.Errorf("Unexpected latency buckets %+v", stats.Latency)
+	}
+	if stats.Latency[0].UpperBound != time.Millisecond || stats.Latency[len(backendLatencyBounds)].UpperBound != 0 {
+		t.Errorf("Unexpected latency bounds %+v", stats.Latency)
+	}
+
+	// The returned stats are a copy.
+	stats.Latency[0].Count = 100
+	if metrics.stats()["GetObject"].Latency[0].Count == 100 {
+		t.Errorf("Expected the stats to be copied")
+	}
+}
+
+// Wrapper for calling testMetricsObjects for both XL and FS.
+func TestMetricsObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testMetricsObjects)
+}
+
+// Tests that the calls, errors and bytes of each operation are counted.
+func testMetricsObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	metrics := &backendMetrics{}
+	mobj := newMetricsObjects(obj, metrics)
+
+	bucket := getRandomBucketName()
+	if err := mobj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := []byte("hello, world")
+	for i := 0; i < 2; i++ {
+		if _, err := mobj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	var buffer bytes.Buffer
+	if err := mobj.GetObject(bucket, "object", 0, 5, &buffer); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err := mobj.GetObjectInfo(bucket, "missing"); !isErrObjectNotFound(err) {
+		t.Fatalf("%s: Expected ObjectNotFound, got %v", instanceType, err)
+	}
+	uploadID, err := mobj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = mobj.PutObjectPart(bucket, "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "", ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = mobj.AbortMultipartUpload(bucket, "multipart", uploadID); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	testCases := []struct {
+		op           string
+		calls        uint64
+		errors       uint64
+		bytesRead    uint64
+		bytesWritten uint64
+	}{
+		{"MakeBucket", 1, 0, 0, 0},
+		{"PutObject", 2, 0, 0, uint64(2 * len(data))},
+		{"GetObject", 1, 0, 5, 0},
+		{"GetObjectInfo", 1, 1, 0, 0},
+		{"NewMultipartUpload", 1, 0, 0, 0},
+		{"PutObjectPart", 1, 0, 0, uint64(len(data))},
+		{"AbortMultipartUpload", 1, 0, 0, 0},
+	}
+	stats := metrics.stats()
+	if len(stats) != len(testCases) {
+		t.Errorf("%s: Expected %d operations, got %+v", instanceType, len(testCases), stats)
+	}
+	for i, testCase := range testCases {
+		opStats := stats[testCase.op]
+		if opStats.Calls != testCase.calls || opStats.Errors != testCase.errors ||
+			opStats.BytesRead != testCase.bytesRead || opStats.BytesWritten != testCase.bytesWritten {
+			t.Errorf("Test %d: %s: Unexpected stats of %s %+v", i+1, instanceType, testCase.op, opStats)
+		}
+		var count uint64
+		for _, bucket := range opStats.Latency {
+			count += bucket.Count
+		}
+		if count != testCase.calls {
+			t.Errorf("Test %d: %s: Expected %d calls in the latency buckets of %s, got %d", i+1, instanceType, testCase.calls, testCase.op, count)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Maximum number of writes waiting to be mirrored asynchronously,
+// further writes block until the mirror catches up.
+const maxMirrorQueue = 1024
+
+// Global mirror of the writes, nil unless enabled with MINIO_MIRROR_ENDPOINT.
+var globalObjectMirror *objectMirror
+
+// objectMirror - backend the writes to the object layer are mirrored
+// to, right away or in the background.
+type objectMirror struct {
+	target ObjectLayer
+	async  bool
+
+	queue   chan func() error
+	pending sync.WaitGroup
+}
+
+// newObjectMirror - returns a mirror to target, which starts mirroring
+// in the background if async is set.
+func newObjectMirror(target ObjectLayer, async bool) *objectMirror {
+	m := &objectMirror{target: target, async: async}
+	if async {
+		m.queue = make(chan func() error, maxMirrorQueue)
+		go m.run()
+	}
+	return m
+}
+
+// run - mirrors the queued writes in order.
+func (m *objectMirror) run() {
+	for fn := range m.queue {
+		errorIf(fn(), "Unable to mirror a write.")
+		m.pending.Done()
+	}
+}
+
+// apply - mirrors a write, or queues it if asynchronous in which case
+// nil is returned.
+func (m *objectMirror) apply(fn func() error) error {
+	if !m.async {
+		return fn()
+	}
+	m.pending.Add(1)
+	m.queue <- fn
+	return nil
+}
+
+// wait - returns once the queued writes are mirrored.
+func (m *objectMirror) wait() {
+	m.pending.Wait()
+}
+
+// parseMirrorMode - returns whether writes are mirrored asynchronously.
+func parseMirrorMode(value string) (async bool, err error) {
+	switch strings.ToLower(value) {
+	case "", "sync":
+		return false, nil
+	case "async":
+		return true, nil
+	}
+	return false, fmt.Errorf("Unknown value `%s` for MINIO_MIRROR_MODE, expected `sync` or `async`", value)
+}
+
+// loadObjectMirror - loads the mirror from the environment, nil if
+// MINIO_MIRROR_ENDPOINT is not set.
+func loadObjectMirror() (*objectMirror, error) {
+	endpoint := os.Getenv("MINIO_MIRROR_ENDPOINT")
+	if endpoint == "" {
+		return nil, nil
+	}
+	async, err := parseMirrorMode(os.Getenv("MINIO_MIRROR_MODE"))
+	if err != nil {
+		return nil, err
+	}
+	target, err := newS3Objects(endpoint, os.Getenv("MINIO_MIRROR_ACCESS_KEY"),
+		os.Getenv("MINIO_MIRROR_SECRET_KEY"), os.Getenv("MINIO_MIRROR_REGION"))
+	if err != nil {
+		return nil, err
+	}
+	return newObjectMirror(target, async), nil
+}
+
+// mirrorObjects - object layer mirroring the writes of buckets and
+// objects to a second backend, all reads are served by the wrapped
+// layer. Objects are mirrored once written whole, by reading them back,
+// so uploads in progress and the metadata bucket are not mirrored.
+type mirrorObjects struct {
+	ObjectLayer
+	mirror *objectMirror
+}
+
+// newMirrorObjects - returns objAPI with its writes mirrored by mirror.
+func newMirrorObjects(objAPI ObjectLayer, mirror *objectMirror) ObjectLayer {
+	return mirrorObjects{ObjectLayer: objAPI, mirror: mirror}
+}
+
+// mirrorObject - copies the object as it is now to the mirror, creating
+// its bucket if missing. Objects deleted since are skipped.
+func (m mirrorObjects) mirrorObject(bucket, object string) error {
+	objInfo, err := m.ObjectLayer.GetObjectInfo(bucket, object)
+	if isErrObjectNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	metadata := make(map[string]string, len(objInfo.UserDefined)+1)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	// The ETag of multipart objects is not the MD5 of the data.
+	if !strings.Contains(objInfo.MD5Sum, "-") {
+		metadata["md5Sum"] = objInfo.MD5Sum
+	}
+
+	put := func() error {
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			pipeWriter.CloseWithError(m.ObjectLayer.GetObject(bucket, object, 0, objInfo.Size, pipeWriter))
+		}()
+		_, err := m.mirror.target.PutObject(bucket, object, objInfo.Size, pipeReader, metadata, "")
+		pipeReader.CloseWithError(err)
+		return err
+	}
+	err = put()
+	if _, ok := errorCause(err).(BucketNotFound); ok {
+		if err = m.mirror.target.MakeBucket(bucket); err != nil {
+			return err
+		}
+		err = put()
+	}
+	return err
+}
+
+// MakeBucket - creates the bucket on both backends.
+func (m mirrorObjects) MakeBucket(bucket string) error {
+	if err := m.ObjectLayer.MakeBucket(bucket); err != nil {
+		return err
+	}
+	if bucket == minioMetaBucket {
+		return nil
+	}
+	return m.mirror.apply(func() error {
+		err := m.mirror.target.MakeBucket(bucket)
+		if _, ok := errorCause(err).(BucketExists); ok {
+			return nil
+		}
+		return err
+	})
+}
+
+// DeleteBucket - deletes the bucket on both backends.
+func (m mirrorObjects) DeleteBucket(bucket string) error {
+	if err := m.ObjectLayer.DeleteBucket(bucket); err != nil {
+		return err
+	}
+	if bucket == minioMetaBucket {
+		return nil
+	}
+	return m.mirror.apply(func() error {
+		err := m.mirror.target.DeleteBucket(bucket)
+		if _, ok := errorCause(err).(BucketNotFound); ok {
+			return nil
+		}
+		return err
+	})
+}
+
+// PutObject - writes the object and mirrors it.
+func (m mirrorObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	objInfo, err := m.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	if err != nil || bucket == minioMetaBucket {
+		return objInfo, err
+	}
+	return objInfo, m.mirror.apply(func() error {
+		return m.mirrorObject(bucket, object)
+	})
+}
+
+// CopyObject - copies the object and mirrors the copy.
+func (m mirrorObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	objInfo, err := m.ObjectLayer.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata)
+	if err != nil || dstBucket == minioMetaBucket {
+		return objInfo, err
+	}
+	return objInfo, m.mirror.apply(func() error {
+		return m.mirrorObject(dstBucket, dstObject)
+	})
+}
+
+// DeleteObject - deletes the object on both backends.
+func (m mirrorObjects) DeleteObject(bucket, object string) error {
+	if err := m.ObjectLayer.DeleteObject(bucket, object); err != nil {
+		return err
+	}
+	if bucket == minioMetaBucket {
+		return nil
+	}
+	return m.mirror.apply(func() error {
+		err := m.mirror.target.DeleteObject(bucket, object)
+		if isErrObjectNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// CompleteMultipartUpload - completes the upload and mirrors the object.
+func (m mirrorObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	md5Sum, err := m.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil || bucket == minioMetaBucket {
+		return md5Sum, err
+	}
+	return md5Sum, m.mirror.apply(func() error {
+		return m.mirrorObject(bucket, object)
+	})
+}
+
+// Shutdown - mirrors the queued writes and shuts down both backends.
+func (m mirrorObjects) Shutdown() error {
+	m.mirror.wait()
+	errorIf(m.mirror.target.Shutdown(), "Unable to shutdown the mirror.")
+	return m.ObjectLayer.Shutdown()
+}
@@ -0,0 +1,138 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests parsing MINIO_MIRROR_MODE.
+func TestParseMirrorMode(t *testing.T) {
+	testCases := []struct {
+		value   string
+		async   bool
+		success bool
+	}{
+		{"", false, true},
+		{"sync", false, true},
+		{"ASYNC", true, true},
+		{"later", false, false},
+	}
+	for i, testCase := range testCases {
+		async, err := parseMirrorMode(testCase.value)
+		if testCase.success != (err == nil) || async != testCase.async {
+			t.Errorf("Test %d: Expected %v, %v, got %v, %v", i+1, testCase.async, testCase.success, async, err)
+		}
+	}
+}
+
+// Tests that the writes are mirrored, right away or in the background.
+func TestMirrorObjects(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	for _, async := range []bool{false, true} {
+		primary, primaryDir, err := prepareFS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(primaryDir)
+		target, targetDir, err := prepareFS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(targetDir)
+
+		mirror := newObjectMirror(target, async)
+		obj := newMirrorObjects(primary, mirror)
+
+		// Buckets missing on the mirror are created with their objects.
+		if err = primary.MakeBucket("existing"); err != nil {
+			t.Fatal(err)
+		}
+		if err = obj.MakeBucket("bucket"); err != nil {
+			t.Fatal(err)
+		}
+		data := []byte("hello, world")
+		for _, bucket := range []string{"bucket", "existing"} {
+			if _, err = obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": "text/plain"}, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err = obj.CopyObject("bucket", "object", "bucket", "copy", nil); err != nil {
+			t.Fatal(err)
+		}
+		uploadID, err := obj.NewMultipartUpload("bucket", "multipart", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md5Sum, err := obj.PutObjectPart("bucket", "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = obj.CompleteMultipartUpload("bucket", "multipart", uploadID, []completePart{{PartNumber: 1, ETag: md5Sum}}); err != nil {
+			t.Fatal(err)
+		}
+		mirror.wait()
+
+		for _, object := range [][2]string{{"bucket", "object"}, {"existing", "object"}, {"bucket", "copy"}, {"bucket", "multipart"}} {
+			var buffer bytes.Buffer
+			if err = target.GetObject(object[0], object[1], 0, int64(len(data)), &buffer); err != nil {
+				t.Fatalf("Async %v: %s/%s: %s", async, object[0], object[1], err)
+			}
+			if !bytes.Equal(buffer.Bytes(), data) {
+				t.Errorf("Async %v: Expected %s/%s to be %q, got %q", async, object[0], object[1], data, buffer.Bytes())
+			}
+		}
+		objInfo, err := target.GetObjectInfo("bucket", "object")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if objInfo.MD5Sum != getMD5Hash(data) || objInfo.ContentType != "text/plain" {
+			t.Errorf("Async %v: Unexpected mirrored object %v", async, objInfo)
+		}
+
+		// Deletes are mirrored, also of objects missing on the mirror.
+		if _, err = primary.PutObject("bucket", "not-mirrored", 0, bytes.NewReader(nil), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+		for _, object := range []string{"object", "copy", "multipart", "not-mirrored"} {
+			if err = obj.DeleteObject("bucket", object); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = obj.DeleteBucket("bucket"); err != nil {
+			t.Fatal(err)
+		}
+		mirror.wait()
+		if _, err = target.GetBucketInfo("bucket"); !isSameType(errorCause(err), BucketNotFound{}) {
+			t.Errorf("Async %v: Expected the bucket to be deleted on the mirror, got %v", async, err)
+		}
+
+		// Reads are served by the primary only.
+		if _, err = target.PutObject("existing", "mirror-only", 0, bytes.NewReader(nil), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = obj.GetObjectInfo("existing", "mirror-only"); !isErrObjectNotFound(err) {
+			t.Errorf("Async %v: Expected ObjectNotFound, got %v", async, err)
+		}
+	}
+}
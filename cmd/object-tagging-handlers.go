@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+
+	mux "github.com/gorilla/mux"
+)
+
+// Maximum size of a tagging document.
+const maxObjectTaggingSize = 64 * 1024
+
+// GetObjectTaggingHandler - GET Object tagging
+// -----------------
+// Returns the tag set of an object, empty if it has no tags.
+func (api objectAPIHandlers) GetObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:GetObjectTagging", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	tags, err := getObjectTags(objAPI, objInfo)
+	if err != nil {
+		errorIf(err, "Unable to load tags of object %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, encodeResponse(Tagging{TagSet: tags}))
+}
+
+// PutObjectTaggingHandler - PUT Object tagging
+// -----------------
+// Replaces the tag set of an object. Tags belong to the object as
+// written, overwriting the object discards them.
+func (api objectAPIHandlers) PutObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:PutObjectTagging", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	var tagging Tagging
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxObjectTaggingSize)).Decode(&tagging); err != nil {
+		errorIf(err, "Unable to parse tagging XML.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if err := validateObjectTags(tagging.TagSet); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err = setObjectTags(objAPI, objInfo, tagging.TagSet); err != nil {
+		errorIf(err, "Unable to save tags of object %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// DeleteObjectTaggingHandler - DELETE Object tagging
+// -----------------
+// Removes all tags of an object.
+func (api objectAPIHandlers) DeleteObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:DeleteObjectTagging", serverConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if _, err := objAPI.GetObjectInfo(bucket, object); err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err := removeObjectTags(objAPI, bucket, object); err != nil {
+		errorIf(err, "Unable to remove tags of object %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessNoContent(w)
+}
+
+// setObjectTaggingCount - sets the X-Amz-Tagging-Count header to the
+// number of tags of the object described by objInfo, if it has any.
+func setObjectTaggingCount(w http.ResponseWriter, objAPI ObjectLayer, objInfo ObjectInfo) {
+	tags, err := getObjectTags(objAPI, objInfo)
+	if err != nil {
+		errorIf(err, "Unable to load tags of object %s/%s.", objInfo.Bucket, objInfo.Name)
+		return
+	}
+	if len(tags) > 0 {
+		w.Header().Set("X-Amz-Tagging-Count", strconv.Itoa(len(tags)))
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"path"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Directory of the tag sets of a bucket's objects.
+	objectTaggingPrefix = "tagging"
+
+	// Maximum number of tags per object.
+	maxObjectTags = 10
+
+	// Maximum length of a tag key and value in characters.
+	maxObjectTagKeyLength   = 128
+	maxObjectTagValueLength = 256
+)
+
+// Tag - a single key/value tag of an object.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Tagging - tag set of an object, the document sent to and returned
+// by the tagging sub-resource.
+type Tagging struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ Tagging" json:"-"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+// objectTagsV1 - tag set of an object as persisted, only valid for
+// the object version with the same modification time and ETag.
+type objectTagsV1 struct {
+	Version string    `json:"version"`
+	ModTime time.Time `json:"modTime"`
+	MD5Sum  string    `json:"md5Sum"`
+	Tags    []Tag     `json:"tags"`
+}
+
+// validateObjectTags - validates the number, keys and values of tags.
+func validateObjectTags(tags []Tag) error {
+	if len(tags) > maxObjectTags {
+		return errInvalidTag
+	}
+	keys := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		keyLen := utf8.RuneCountInString(tag.Key)
+		if keyLen == 0 || keyLen > maxObjectTagKeyLength {
+			return errInvalidTag
+		}
+		if utf8.RuneCountInString(tag.Value) > maxObjectTagValueLength {
+			return errInvalidTag
+		}
+		if _, ok := keys[tag.Key]; ok {
+			return errInvalidTag
+		}
+		keys[tag.Key] = struct{}{}
+	}
+	return nil
+}
+
+// parseTaggingHeader - parses the URL query encoded tags of the
+// X-Amz-Tagging header.
+func parseTaggingHeader(value string) ([]Tag, error) {
+	if value == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(value)
+	if err != nil {
+		return nil, errInvalidTag
+	}
+	var tags []Tag
+	for key, vals := range values {
+		if len(vals) != 1 {
+			return nil, errInvalidTag
+		}
+		tags = append(tags, Tag{Key: key, Value: vals[0]})
+	}
+	if err = validateObjectTags(tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// getObjectTagsFile - returns the name of the bucket configuration
+// file holding the tag set of an object.
+func getObjectTagsFile(object string) string {
+	return path.Join(objectTaggingPrefix, object) + ".json"
+}
+
+// getObjectTags - returns the tags of the object described by objInfo,
+// none if they were set on a version since overwritten.
+func getObjectTags(objAPI ObjectLayer, objInfo ObjectInfo) ([]Tag, error) {
+	data, err := readBucketConfigFile(objInfo.Bucket, getObjectTagsFile(objInfo.Name), objAPI)
+	if err == errConfigNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var otags objectTagsV1
+	if err = json.Unmarshal(data, &otags); err != nil {
+		return nil, err
+	}
+	if !otags.ModTime.Equal(objInfo.ModTime) || otags.MD5Sum != objInfo.MD5Sum {
+		return nil, nil
+	}
+	return otags.Tags, nil
+}
+
+// setObjectTags - replaces the tags of the object described by
+// objInfo, an empty tag set removes them.
+func setObjectTags(objAPI ObjectLayer, objInfo ObjectInfo, tags []Tag) error {
+	if len(tags) == 0 {
+		return removeObjectTags(objAPI, objInfo.Bucket, objInfo.Name)
+	}
+	data, err := json.Marshal(objectTagsV1{
+		Version: "1",
+		ModTime: objInfo.ModTime,
+		MD5Sum:  objInfo.MD5Sum,
+		Tags:    tags,
+	})
+	if err != nil {
+		return err
+	}
+	return writeBucketConfigFile(objInfo.Bucket, getObjectTagsFile(objInfo.Name), data, objAPI)
+}
+
+// removeObjectTags - removes the tags of an object, if any.
+func removeObjectTags(objAPI ObjectLayer, bucket, object string) error {
+	err := objAPI.DeleteObject(minioMetaBucket, path.Join(bucketConfigPrefix, bucket, getObjectTagsFile(object)))
+	if err != nil && !isErrObjectNotFound(err) {
+		return errorCause(err)
+	}
+	return nil
+}
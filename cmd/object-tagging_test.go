@@ -0,0 +1,228 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Tests validation of tag sets.
+func TestValidateObjectTags(t *testing.T) {
+	tooMany := make([]Tag, maxObjectTags+1)
+	for i := range tooMany {
+		tooMany[i] = Tag{Key: string(rune('a' + i)), Value: "v"}
+	}
+	testCases := []struct {
+		tags        []Tag
+		expectedErr error
+	}{
+		{nil, nil},
+		{[]Tag{{"project", "minio"}, {"team", ""}}, nil},
+		{tooMany[:maxObjectTags], nil},
+		{tooMany, errInvalidTag},
+		{[]Tag{{"", "minio"}}, errInvalidTag},
+		{[]Tag{{strings.Repeat("k", maxObjectTagKeyLength), "v"}}, nil},
+		{[]Tag{{strings.Repeat("k", maxObjectTagKeyLength+1), "v"}}, errInvalidTag},
+		{[]Tag{{"k", strings.Repeat("v", maxObjectTagValueLength+1)}}, errInvalidTag},
+		{[]Tag{{"k", "a"}, {"k", "b"}}, errInvalidTag},
+	}
+	for i, testCase := range testCases {
+		if err := validateObjectTags(testCase.tags); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+}
+
+// Tests parsing of the X-Amz-Tagging header.
+func TestParseTaggingHeader(t *testing.T) {
+	testCases := []struct {
+		value        string
+		expectedTags int
+		expectedErr  error
+	}{
+		{"", 0, nil},
+		{"project=minio", 1, nil},
+		{"project=minio&team=storage%20team", 2, nil},
+		{"project=a&project=b", 0, errInvalidTag},
+		{"=minio", 0, errInvalidTag},
+		{"project=%zz", 0, errInvalidTag},
+	}
+	for i, testCase := range testCases {
+		tags, err := parseTaggingHeader(testCase.value)
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if len(tags) != testCase.expectedTags {
+			t.Errorf("Test %d: Expected %d tags, got %d", i+1, testCase.expectedTags, len(tags))
+		}
+	}
+}
+
+// Tests that tags are only reported for the object they were set on.
+func TestObjectTags(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectTags)
+}
+
+func testObjectTags(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket, object := "tagging-bucket", "dir/object"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	objInfo, err := obj.PutObject(bucket, object, 5, bytes.NewReader([]byte("hello")), nil, "")
+	if err != nil {
+		t.Fatalf("%s: Unable to upload object: %v", instanceType, err)
+	}
+
+	tags, err := getObjectTags(obj, objInfo)
+	if err != nil || len(tags) != 0 {
+		t.Fatalf("%s: Expected no tags, got %v, %v", instanceType, tags, err)
+	}
+
+	expected := []Tag{{"project", "minio"}}
+	if err = setObjectTags(obj, objInfo, expected); err != nil {
+		t.Fatalf("%s: Unable to set tags: %v", instanceType, err)
+	}
+	if tags, err = getObjectTags(obj, objInfo); err != nil || len(tags) != 1 || tags[0] != expected[0] {
+		t.Fatalf("%s: Expected %v, got %v, %v", instanceType, expected, tags, err)
+	}
+
+	// Overwriting the object discards its tags.
+	newInfo, err := obj.PutObject(bucket, object, 6, bytes.NewReader([]byte("hello!")), nil, "")
+	if err != nil {
+		t.Fatalf("%s: Unable to overwrite object: %v", instanceType, err)
+	}
+	if tags, err = getObjectTags(obj, newInfo); err != nil || len(tags) != 0 {
+		t.Fatalf("%s: Expected no tags after overwrite, got %v, %v", instanceType, tags, err)
+	}
+
+	// An empty tag set removes the tags.
+	if err = setObjectTags(obj, newInfo, expected); err != nil {
+		t.Fatalf("%s: Unable to set tags: %v", instanceType, err)
+	}
+	if err = setObjectTags(obj, newInfo, nil); err != nil {
+		t.Fatalf("%s: Unable to clear tags: %v", instanceType, err)
+	}
+	if tags, err = getObjectTags(obj, newInfo); err != nil || len(tags) != 0 {
+		t.Fatalf("%s: Expected no tags after removal, got %v, %v", instanceType, tags, err)
+	}
+	if err = removeObjectTags(obj, bucket, object); err != nil {
+		t.Fatalf("%s: Removing absent tags should succeed, got %v", instanceType, err)
+	}
+}
+
+// Wrapper for calling object tagging HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIObjectTaggingHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIObjectTaggingHandlers, []string{"ObjectTagging"})
+}
+
+func testAPIObjectTaggingHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "tagged-object"
+	taggingXML := func(tags ...Tag) []byte {
+		data, err := xml.Marshal(Tagging{TagSet: tags})
+		if err != nil {
+			t.Fatalf("%s: Unable to marshal tags: %v", instanceType, err)
+		}
+		return data
+	}
+
+	taggingQuery := url.Values{}
+	taggingQuery.Set("tagging", "")
+	testCases := []struct {
+		method             string
+		object             string
+		queryValues        url.Values
+		header             map[string]string
+		body               []byte
+		expectedRespStatus int
+		expectedTagCount   string
+	}{
+		// Test case - 1.
+		// Upload an object with a tag.
+		{"PUT", objectName, nil, map[string]string{"X-Amz-Tagging": "project=minio"}, []byte("hello"), http.StatusOK, ""},
+		// Test case - 2.
+		// GET reports the tag count.
+		{"GET", objectName, nil, nil, nil, http.StatusOK, "1"},
+		// Test case - 3.
+		// Replace the tag set.
+		{"PUT", objectName, taggingQuery, nil, taggingXML(Tag{"a", "1"}, Tag{"b", "2"}), http.StatusOK, ""},
+		// Test case - 4.
+		// HEAD reports the new tag count.
+		{"HEAD", objectName, nil, nil, nil, http.StatusOK, "2"},
+		// Test case - 5.
+		// Fetch the tag set.
+		{"GET", objectName, taggingQuery, nil, nil, http.StatusOK, ""},
+		// Test case - 6.
+		// Duplicate keys are rejected.
+		{"PUT", objectName, taggingQuery, nil, taggingXML(Tag{"a", "1"}, Tag{"a", "2"}), http.StatusBadRequest, ""},
+		// Test case - 7.
+		// Malformed XML.
+		{"PUT", objectName, taggingQuery, nil, []byte("<Tagging>"), http.StatusBadRequest, ""},
+		// Test case - 8.
+		// Invalid tags in the header fail the upload.
+		{"PUT", "other-object", nil, map[string]string{"X-Amz-Tagging": "a=1&a=2"}, []byte("hello"), http.StatusBadRequest, ""},
+		// Test case - 9.
+		// Tagging a non-existent object.
+		{"PUT", "other-object", taggingQuery, nil, taggingXML(Tag{"a", "1"}), http.StatusNotFound, ""},
+		// Test case - 10.
+		// Remove the tag set.
+		{"DELETE", objectName, taggingQuery, nil, nil, http.StatusNoContent, ""},
+		// Test case - 11.
+		// GET no longer reports tags.
+		{"GET", objectName, nil, nil, nil, http.StatusOK, ""},
+	}
+
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(testCase.method, makeTestTargetURL("", bucketName, testCase.object, testCase.queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request: <ERROR> %v", i+1, instanceType, err)
+		}
+		for k, v := range testCase.header {
+			req.Header.Set(k, v)
+		}
+		if testCase.header != nil {
+			// Re-sign with the added headers.
+			if err = signRequestV4(req, credentials.AccessKeyID, credentials.SecretAccessKey); err != nil {
+				t.Fatalf("Test %d: %s: Failed to sign HTTP request: <ERROR> %v", i+1, instanceType, err)
+			}
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if tagCount := rec.Header().Get("X-Amz-Tagging-Count"); tagCount != testCase.expectedTagCount {
+			t.Errorf("Test %d: %s: Expected tag count `%s`, but instead found `%s`", i+1, instanceType, testCase.expectedTagCount, tagCount)
+		}
+		if testCase.method == "GET" && testCase.queryValues != nil {
+			var tagging Tagging
+			if err = xml.Unmarshal(rec.Body.Bytes(), &tagging); err != nil {
+				t.Fatalf("Test %d: %s: Unable to parse response: %v", i+1, instanceType, err)
+			}
+			if len(tagging.TagSet) != 2 || tagging.TagSet[0] != (Tag{"a", "1"}) || tagging.TagSet[1] != (Tag{"b", "2"}) {
+				t.Errorf("Test %d: %s: Unexpected tag set %s", i+1, instanceType, rec.Body.String())
+			}
+		}
+	}
+}
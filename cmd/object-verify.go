@@ -0,0 +1,124 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ObjectVerifyInfo - result of verifying an object against its
+// stored checksums.
+type ObjectVerifyInfo struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+	// Discrepancies found, empty if the object is intact.
+	Problems []string `json:"problems"`
+}
+
+// VerifyObjectsInfo - result of verifying objects under a prefix,
+// only objects with discrepancies are listed.
+type VerifyObjectsInfo struct {
+	Verified    int                `json:"verified"`
+	Objects     []ObjectVerifyInfo `json:"objects"`
+	IsTruncated bool               `json:"isTruncated"`
+	NextMarker  string             `json:"nextMarker,omitempty"`
+}
+
+// verifyObject - verifies the backend checksums of an object and
+// recomputes its ETag. The ETag of multipart objects is not an MD5 of
+// the data, such objects are only read back.
+func verifyObject(objAPI ObjectLayer, bucket, object string) (ObjectVerifyInfo, error) {
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return ObjectVerifyInfo{}, err
+	}
+	result := ObjectVerifyInfo{
+		Bucket: bucket,
+		Object: object,
+		Size:   objInfo.Size,
+		ETag:   objInfo.MD5Sum,
+	}
+	if result.Problems, err = objAPI.VerifyObject(bucket, object); err != nil {
+		return ObjectVerifyInfo{}, err
+	}
+	problem, err := checkObjectData(objAPI, objInfo)
+	if err != nil {
+		return ObjectVerifyInfo{}, err
+	}
+	if problem != "" {
+		result.Problems = append(result.Problems, problem)
+	}
+	return result, nil
+}
+
+// checkObjectData - reads an object back and compares it with its
+// ETag, returns a description of the problem if the data is damaged.
+// The ETag of an object encrypted at rest is the MD5 of its encrypted
+// data, reading it back authenticates every segment instead. Errors
+// which say nothing about the data, like missing read quorum, are
+// returned as such.
+func checkObjectData(objAPI ObjectLayer, objInfo ObjectInfo) (string, error) {
+	md5Writer := md5.New()
+	err := objAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, md5Writer)
+	if err != nil {
+		if _, ok := errorCause(err).(InsufficientReadQuorum); ok {
+			return "", err
+		}
+		return fmt.Sprintf("unable to read object: %s", errorCause(err)), nil
+	}
+	if strings.Contains(objInfo.MD5Sum, "-") || isKMSEncryptedObject(objInfo) {
+		return "", nil
+	}
+	if md5Sum := hex.EncodeToString(md5Writer.Sum(nil)); md5Sum != objInfo.MD5Sum {
+		return fmt.Sprintf("ETag mismatch, computed %s", md5Sum), nil
+	}
+	return "", nil
+}
+
+// verifyObjects - verifies up to maxKeys objects under prefix after
+// marker.
+func verifyObjects(objAPI ObjectLayer, bucket, prefix, marker string, maxKeys int) (VerifyObjectsInfo, error) {
+	lo, err := objAPI.ListObjects(bucket, prefix, marker, "", maxKeys)
+	if err != nil {
+		return VerifyObjectsInfo{}, err
+	}
+	result := VerifyObjectsInfo{
+		Objects:     []ObjectVerifyInfo{},
+		IsTruncated: lo.IsTruncated,
+		NextMarker:  lo.NextMarker,
+	}
+	for _, obj := range lo.Objects {
+		objResult, err := verifyObject(objAPI, bucket, obj.Name)
+		if err != nil {
+			if isErrObjectNotFound(err) {
+				// Removed since listed.
+				continue
+			}
+			return VerifyObjectsInfo{}, err
+		}
+		result.Verified++
+		if len(objResult.Problems) > 0 {
+			result.Objects = append(result.Objects, objResult)
+		}
+	}
+	return result, nil
+}
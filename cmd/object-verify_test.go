@@ -0,0 +1,90 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests verifying objects, corrupted data is reported.
+func TestVerifyObject(t *testing.T) {
+	ExecObjectLayerTest(t, testVerifyObject)
+}
+
+func testVerifyObject(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "verify-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to create bucket: %v", instanceType, err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	for _, object := range []string{"dir/intact", "dir/corrupt"} {
+		if _, err := obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("%s: Unable to upload object %s: %v", instanceType, object, err)
+		}
+	}
+
+	result, err := verifyObject(obj, bucket, "dir/intact")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(result.Problems) != 0 || result.Size != int64(len(data)) {
+		t.Errorf("%s: Expected intact object, got %+v", instanceType, result)
+	}
+
+	// Overwrite the object data on the backend with the same size.
+	var disk StorageAPI
+	var dataPath string
+	switch o := obj.(type) {
+	case fsObjects:
+		disk, dataPath = o.storage, "dir/corrupt"
+	case *xlObjects:
+		disk, dataPath = o.storageDisks[0], "dir/corrupt/part.1"
+	default:
+		t.Fatalf("%s: Unexpected object layer %T", instanceType, obj)
+	}
+	fi, err := disk.StatFile(bucket, dataPath)
+	if err != nil {
+		t.Fatalf("%s: Unable to stat object data: %v", instanceType, err)
+	}
+	if err = disk.DeleteFile(bucket, dataPath); err != nil {
+		t.Fatalf("%s: Unable to delete object data: %v", instanceType, err)
+	}
+	if err = disk.AppendFile(bucket, dataPath, bytes.Repeat([]byte("b"), int(fi.Size))); err != nil {
+		t.Fatalf("%s: Unable to corrupt object data: %v", instanceType, err)
+	}
+
+	result, err = verifyObject(obj, bucket, "dir/corrupt")
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if len(result.Problems) == 0 {
+		t.Errorf("%s: Expected corruption to be reported", instanceType)
+	}
+
+	results, err := verifyObjects(obj, bucket, "dir/", "", 1000)
+	if err != nil {
+		t.Fatalf("%s: Unexpected error: %v", instanceType, err)
+	}
+	if results.Verified != 2 || len(results.Objects) != 1 || results.Objects[0].Object != "dir/corrupt" {
+		t.Errorf("%s: Unexpected results %+v", instanceType, results)
+	}
+
+	if _, err = verifyObject(obj, bucket, "missing"); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected ObjectNotFound, got %v", instanceType, err)
+	}
+}
@@ -107,3 +107,25 @@ func isSysErrHandleInvalid(err error) bool {
 	}
 	return false
 }
+
+// Check if the given error corresponds to ERROR_ACCESS_DENIED,
+// ERROR_SHARING_VIOLATION or ERROR_LOCK_VIOLATION for windows, returned
+// when renaming or removing a file open in another handle.
+func isSysErrSharingViolation(err error) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	}
+	if errno, ok := err.(syscall.Errno); ok {
+		switch errno {
+		case 0x5, 0x20, 0x21:
+			return true
+		}
+	}
+	return false
+}
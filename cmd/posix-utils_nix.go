@@ -18,7 +18,11 @@
 
 package cmd
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // isValidVolname verifies a volname name in accordance with object
 // layer requirements.
@@ -42,3 +46,42 @@ func mkdirAll(path string, perm os.FileMode) error {
 func removeAll(path string) error {
 	return os.RemoveAll(path)
 }
+
+// renameFile renames src to dst, replacing dst if it exists.
+func renameFile(src, dst string) error {
+	return os.Rename(src, dst)
+}
+
+// removeFile removes the file or empty directory path.
+func removeFile(path string) error {
+	return os.Remove(path)
+}
+
+// fileNameCase returns the name of path as stored by the file system,
+// which differs in case from path if the file system is case
+// insensitive. The parent directory is listed to find it.
+func fileNameCase(path string) (string, error) {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	var folded string
+	for _, entry := range names {
+		if entry == name {
+			return entry, nil
+		}
+		if folded == "" && strings.EqualFold(entry, name) {
+			folded = entry
+		}
+	}
+	if folded == "" {
+		return "", os.ErrNotExist
+	}
+	return folded, nil
+}
@@ -24,6 +24,15 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
+)
+
+// Attempts and delay between them to rename or remove a file open in
+// another handle, Windows refuses both until every handle is closed.
+// Readers only keep files open for a single read.
+const (
+	sharingViolationRetries = 20
+	sharingViolationDelay   = 50 * time.Millisecond
 )
 
 // isValidVolname verifies a volname name in accordance with object
@@ -33,7 +42,7 @@ func isValidVolname(volname string) bool {
 		return false
 	}
 	// Volname shouldn't have reserved characters on windows in it.
-	return !strings.ContainsAny(volname, `\:*?\"<>|`)
+	return !strings.ContainsAny(volname, `\:*?\"<>|`) && isValidWindowsPathName(volname)
 }
 
 // mkdirAll creates a directory named path,
@@ -163,3 +172,43 @@ func removeAll(path string) error {
 	}
 	return err
 }
+
+// renameFile renames src to dst, replacing dst if it exists. It is
+// retried while src or dst is open in another handle.
+func renameFile(src, dst string) (err error) {
+	for i := 0; i < sharingViolationRetries; i++ {
+		if err = os.Rename(src, dst); !isSysErrSharingViolation(err) {
+			return err
+		}
+		time.Sleep(sharingViolationDelay)
+	}
+	return err
+}
+
+// removeFile removes the file or empty directory path. It is retried
+// while path is open in another handle.
+func removeFile(path string) (err error) {
+	for i := 0; i < sharingViolationRetries; i++ {
+		if err = os.Remove(path); !isSysErrSharingViolation(err) {
+			return err
+		}
+		time.Sleep(sharingViolationDelay)
+	}
+	return err
+}
+
+// fileNameCase returns the name of path as stored by the file system,
+// which differs in case from path as NTFS is case insensitive.
+func fileNameCase(path string) (string, error) {
+	pathp, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return "", err
+	}
+	var data syscall.Win32finddata
+	h, err := syscall.FindFirstFile(pathp, &data)
+	if err != nil {
+		return "", &os.PathError{Op: "FindFirstFile", Path: path, Err: err}
+	}
+	syscall.FindClose(h)
+	return syscall.UTF16ToString(data.FileName[:]), nil
+}
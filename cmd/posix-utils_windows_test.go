@@ -23,6 +23,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test if various paths work as expected when converted to UNC form
@@ -134,3 +135,91 @@ func Test32kUNCPath(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// Test that file names reserved by Windows are rejected.
+func TestPosixReservedNames(t *testing.T) {
+	fs, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(path)
+
+	if err = fs.MakeVol("voldir"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"con", "dir/NUL.txt", "com1/object", "object.", "object "} {
+		if err = fs.AppendFile("voldir", name, []byte("hello")); err != errFileNameInvalid {
+			t.Errorf("%q: expected %s, got %v", name, errFileNameInvalid, err)
+		}
+		if _, err = fs.StatFile("voldir", name); err != errFileNotFound {
+			t.Errorf("%q: expected %s, got %v", name, errFileNotFound, err)
+		}
+	}
+	if isValidVolname("nul") {
+		t.Error("expected volume name nul to be invalid")
+	}
+}
+
+// Test that NTFS is detected as case insensitive and names differing
+// only in case are not confused.
+func TestPosixCaseInsensitive(t *testing.T) {
+	fs, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(path)
+
+	if !fs.(*posix).caseInsensitive {
+		t.Fatal("expected NTFS to be case insensitive")
+	}
+	if err = fs.MakeVol("voldir"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.AppendFile("voldir", "photo.jpg", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fs.StatFile("voldir", "PHOTO.jpg"); err != errFileNotFound {
+		t.Errorf("expected %s, got %v", errFileNotFound, err)
+	}
+	if err = fs.AppendFile("voldir", "PHOTO.jpg", []byte("hello")); err != errFileNameCollision {
+		t.Errorf("expected %s, got %v", errFileNameCollision, err)
+	}
+}
+
+// Test that a file is replaced while a reader still has it open.
+func TestRenameFileOpenReader(t *testing.T) {
+	fs, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(path)
+
+	if err = fs.MakeVol("voldir"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.AppendFile("voldir", "object", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if err = fs.AppendFile("voldir", "tmp-object", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := os.Open(preparePath(pathJoin(path, "voldir", "object")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		reader.Close()
+	}()
+	if err = fs.RenameFile("voldir", "tmp-object", "voldir", "object"); err != nil {
+		t.Fatalf("expected the rename to wait for the reader, got %v", err)
+	}
+	buf, err := fs.ReadAll("voldir", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "new" {
+		t.Errorf("expected new content, got %q", buf)
+	}
+}
@@ -46,6 +46,8 @@ type posix struct {
 	minFreeSpace  int64
 	minFreeInodes int64
 	pool          sync.Pool
+	// Whether names differing only in case are the same file on disk.
+	caseInsensitive bool
 }
 
 // checkPathLength - returns error if given path name length more than 255
@@ -71,6 +73,117 @@ func checkPathLength(pathName string) error {
 	return nil
 }
 
+// Device names reserved by Windows in every directory, with or without
+// an extension.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// isValidWindowsPathName - returns false if a component of the slash
+// separated pathName cannot be a file name on Windows: reserved device
+// names, names ending in a dot or a space, which Windows strips, and
+// reserved characters.
+func isValidWindowsPathName(pathName string) bool {
+	for _, name := range strings.Split(pathName, slashSeparator) {
+		if name == "" || name == "." || name == ".." {
+			continue
+		}
+		if strings.ContainsAny(name, `:*?"<>|`) {
+			return false
+		}
+		for _, r := range name {
+			if r < 32 {
+				return false
+			}
+		}
+		if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+			return false
+		}
+		base := strings.TrimRight(strings.SplitN(name, ".", 2)[0], " ")
+		if windowsReservedNames[strings.ToUpper(base)] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPathName - returns errFileNameInvalid if the slash separated
+// pathName cannot be stored by this operating system.
+func checkPathName(pathName string) error {
+	if runtime.GOOS == "windows" && !isValidWindowsPathName(pathName) {
+		return errFileNameInvalid
+	}
+	return nil
+}
+
+// isCaseInsensitive - returns true if the file system of diskPath does
+// not distinguish names differing only in case, such as NTFS and HFS+.
+func isCaseInsensitive(diskPath string) bool {
+	name := "case-probe-" + mustGetUUID()
+	f, err := os.Create(preparePath(filepath.Join(diskPath, name)))
+	if err != nil {
+		// Read only disk, assume the default of the platform.
+		return runtime.GOOS == "windows"
+	}
+	f.Close()
+	defer os.Remove(preparePath(filepath.Join(diskPath, name)))
+	_, err = os.Stat(preparePath(filepath.Join(diskPath, strings.ToUpper(name))))
+	return err == nil
+}
+
+// checkPathCase - returns errFileNameCollision if a component of path
+// exists in volumeDir with a different case. Only done on case
+// insensitive file systems, where every existing component of path is
+// looked up.
+func (s *posix) checkPathCase(volumeDir, path string) error {
+	if !s.caseInsensitive {
+		return nil
+	}
+	dirPath := volumeDir
+	for _, name := range strings.Split(path, slashSeparator) {
+		if name == "" {
+			continue
+		}
+		dirPath = slashpath.Join(dirPath, name)
+		actual, err := fileNameCase(preparePath(dirPath))
+		if err != nil {
+			// Nothing further to collide with, other errors are
+			// returned by the operation itself.
+			return nil
+		}
+		if actual != name {
+			return errFileNameCollision
+		}
+	}
+	return nil
+}
+
+// checkPathExists - returns errFileNotFound if path cannot exist on
+// this operating system or only exists with a different case.
+func (s *posix) checkPathExists(volumeDir, path string) error {
+	if checkPathName(path) != nil {
+		return errFileNotFound
+	}
+	if err := s.checkPathCase(volumeDir, path); err != nil {
+		return errFileNotFound
+	}
+	return nil
+}
+
+// checkPathCreate - returns errFileNameInvalid if path cannot be
+// created on this operating system, and errFileNameCollision if it
+// exists with a different case.
+func (s *posix) checkPathCreate(volumeDir, path string) error {
+	if err := checkPathName(path); err != nil {
+		return err
+	}
+	return s.checkPathCase(volumeDir, path)
+}
+
 // isDirEmpty - returns whether given directory is empty or not.
 func isDirEmpty(dirname string) bool {
 	f, err := os.Open(dirname)
@@ -137,6 +250,7 @@ func newPosix(path string) (StorageAPI, error) {
 	if err = fs.checkDiskFree(); err != nil {
 		return nil, err
 	}
+	fs.caseInsensitive = isCaseInsensitive(diskPath)
 	return fs, nil
 }
 
@@ -435,6 +549,9 @@ func (s *posix) ListDir(volume, dirPath string) (entries []string, err error) {
 		}
 		return nil, err
 	}
+	if err = s.checkPathExists(volumeDir, dirPath); err != nil {
+		return nil, err
+	}
 	return readDir(pathJoin(volumeDir, dirPath))
 }
 
@@ -477,6 +594,9 @@ func (s *posix) ReadAll(volume, path string) (buf []byte, err error) {
 	if err = checkPathLength(preparePath(filePath)); err != nil {
 		return nil, err
 	}
+	if err = s.checkPathExists(volumeDir, path); err != nil {
+		return nil, err
+	}
 
 	// Open the file for reading.
 	buf, err = ioutil.ReadFile(preparePath(filePath))
@@ -543,6 +663,9 @@ func (s *posix) ReadFile(volume string, path string, offset int64, buf []byte) (
 	if err = checkPathLength(preparePath(filePath)); err != nil {
 		return 0, err
 	}
+	if err = s.checkPathExists(volumeDir, path); err != nil {
+		return 0, err
+	}
 
 	// Open the file for reading.
 	file, err := os.Open(preparePath(filePath))
@@ -615,6 +738,9 @@ func (s *posix) createFile(volume, path string) (f *os.File, err error) {
 	if err = checkPathLength(preparePath(filePath)); err != nil {
 		return nil, err
 	}
+	if err = s.checkPathCreate(volumeDir, path); err != nil {
+		return nil, err
+	}
 
 	// Verify if the file already exists and is not of regular type.
 	var st os.FileInfo
@@ -767,6 +893,9 @@ func (s *posix) StatFile(volume, path string) (file FileInfo, err error) {
 	if err = checkPathLength(preparePath(filePath)); err != nil {
 		return FileInfo{}, err
 	}
+	if err = s.checkPathExists(volumeDir, path); err != nil {
+		return FileInfo{}, err
+	}
 	st, err := os.Stat(preparePath(filePath))
 	if err != nil {
 		// File is really not found.
@@ -815,7 +944,7 @@ func deleteFile(basePath, deletePath string) error {
 		return nil
 	}
 	// Attempt to remove path.
-	if err := os.Remove(preparePath(deletePath)); err != nil {
+	if err := removeFile(preparePath(deletePath)); err != nil {
 		if os.IsNotExist(err) {
 			return errFileNotFound
 		} else if os.IsPermission(err) {
@@ -865,6 +994,9 @@ func (s *posix) DeleteFile(volume, path string) (err error) {
 	if err = checkPathLength(preparePath(filePath)); err != nil {
 		return err
 	}
+	if err = s.checkPathExists(volumeDir, path); err != nil {
+		return err
+	}
 
 	// Delete file and delete parent directory as well if its empty.
 	return deleteFile(volumeDir, filePath)
@@ -923,6 +1055,12 @@ func (s *posix) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) (err e
 	if err = checkPathLength(preparePath(dstFilePath)); err != nil {
 		return err
 	}
+	if err = s.checkPathExists(srcVolumeDir, srcPath); err != nil {
+		return err
+	}
+	if err = s.checkPathCreate(dstVolumeDir, dstPath); err != nil {
+		return err
+	}
 	if srcIsDir {
 		// If source is a directory we expect the destination to be non-existent always.
 		_, err = os.Stat(preparePath(dstFilePath))
@@ -948,7 +1086,7 @@ func (s *posix) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) (err e
 		return err
 	}
 	// Finally attempt a rename.
-	err = os.Rename(preparePath(srcFilePath), preparePath(dstFilePath))
+	err = renameFile(preparePath(srcFilePath), preparePath(dstFilePath))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return errFileNotFound
@@ -963,3 +1101,92 @@ func (s *posix) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) (err e
 
 	return nil
 }
+
+// cloneFile - creates dstPath with the content of srcPath without
+// copying its data. A reflink is attempted first, then a hard link,
+// linked is true if dstPath is a hard link to srcPath. Files must not
+// be modified in place after being cloned, as a hard linked copy
+// would change along with them.
+func (s *posix) cloneFile(srcVolume, srcPath, dstVolume, dstPath string) (linked bool, err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if s.ioErrCount > maxAllowedIOError {
+		return false, errFaultyDisk
+	}
+
+	if err = s.checkDiskFound(); err != nil {
+		return false, err
+	}
+
+	srcVolumeDir, err := s.getVolDir(srcVolume)
+	if err != nil {
+		return false, err
+	}
+	dstVolumeDir, err := s.getVolDir(dstVolume)
+	if err != nil {
+		return false, err
+	}
+	srcFilePath := slashpath.Join(srcVolumeDir, srcPath)
+	if err = checkPathLength(preparePath(srcFilePath)); err != nil {
+		return false, err
+	}
+	dstFilePath := slashpath.Join(dstVolumeDir, dstPath)
+	if err = checkPathLength(preparePath(dstFilePath)); err != nil {
+		return false, err
+	}
+	if err = s.checkPathExists(srcVolumeDir, srcPath); err != nil {
+		return false, err
+	}
+	if err = s.checkPathCreate(dstVolumeDir, dstPath); err != nil {
+		return false, err
+	}
+
+	src, err := os.Open(preparePath(srcFilePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, errFileNotFound
+		}
+		return false, err
+	}
+	defer src.Close()
+
+	st, err := src.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !st.Mode().IsRegular() {
+		return false, errIsNotRegular
+	}
+
+	// Creates all the parent directories, with mode 0777 mkdir honors system umask.
+	if err = mkdirAll(preparePath(slashpath.Dir(dstFilePath)), 0777); err != nil {
+		if isSysErrNotDir(err) || isSysErrPathNotFound(err) {
+			return false, errFileAccessDenied
+		}
+		return false, err
+	}
+
+	dst, err := os.OpenFile(preparePath(dstFilePath), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, errFileAccessDenied
+		}
+		return false, err
+	}
+	err = Reflink(dst, src)
+	dst.Close()
+	if err == nil {
+		return false, nil
+	}
+	removeFile(preparePath(dstFilePath))
+
+	// Filesystem does not share blocks between files, fall back to a hard link.
+	if err = os.Link(preparePath(srcFilePath), preparePath(dstFilePath)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
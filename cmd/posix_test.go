@@ -1556,3 +1556,85 @@ func TestStatFile(t *testing.T) {
 		}
 	}
 }
+
+// Tests file names which cannot be stored on Windows.
+func TestIsValidWindowsPathName(t *testing.T) {
+	testCases := []struct {
+		pathName string
+		valid    bool
+	}{
+		{"object", true},
+		{"dir/object.txt", true},
+		{"dir/", true},
+		{"console/connection", true},
+		{"con", false},
+		{"CON", false},
+		{"dir/nul.txt", false},
+		{"com1/object", false},
+		{"lpt9.tar.gz", false},
+		{"aux .txt", false},
+		{"object.", false},
+		{"object ", false},
+		{"dir./object", false},
+		{"a:b", false},
+		{"a*b", false},
+		{"a?b", false},
+		{"a\x01b", false},
+	}
+	for i, testCase := range testCases {
+		if valid := isValidWindowsPathName(testCase.pathName); valid != testCase.valid {
+			t.Errorf("Test %d: Expected %q valid %v, got %v", i+1, testCase.pathName, testCase.valid, valid)
+		}
+	}
+}
+
+// Tests that names differing only in case from existing files are
+// neither found nor created on case insensitive file systems.
+func TestPosixCaseCollision(t *testing.T) {
+	posixStorage, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatalf("Unable to create posix test setup, %s", err)
+	}
+	defer removeAll(path)
+
+	// Case sensitive file systems look up names in their parent
+	// directory like case insensitive ones without a native lookup.
+	posixStorage.(*posix).caseInsensitive = true
+
+	if err = posixStorage.MakeVol("success-vol"); err != nil {
+		t.Fatalf("Unable to create volume, %s", err)
+	}
+	if err = posixStorage.AppendFile("success-vol", "dir/photo.jpg", []byte("hello")); err != nil {
+		t.Fatalf("Unable to create file, %s", err)
+	}
+
+	testCases := []struct {
+		path      string
+		statErr   error
+		appendErr error
+	}{
+		{"dir/photo.jpg", nil, nil},
+		{"dir/PHOTO.JPG", errFileNotFound, errFileNameCollision},
+		{"DIR/photo.jpg", errFileNotFound, errFileNameCollision},
+		{"DIR/other.jpg", errFileNotFound, errFileNameCollision},
+		{"dir/other.jpg", errFileNotFound, nil},
+	}
+	for i, testCase := range testCases {
+		if _, err = posixStorage.StatFile("success-vol", testCase.path); err != testCase.statErr {
+			t.Errorf("Test %d: Expected StatFile error %v, got %v", i+1, testCase.statErr, err)
+		}
+		if err = posixStorage.AppendFile("success-vol", testCase.path, []byte("hello")); err != testCase.appendErr {
+			t.Errorf("Test %d: Expected AppendFile error %v, got %v", i+1, testCase.appendErr, err)
+		}
+	}
+
+	if _, err = posixStorage.ListDir("success-vol", "DIR/"); err != errFileNotFound {
+		t.Errorf("Expected ListDir error %v, got %v", errFileNotFound, err)
+	}
+	if err = posixStorage.AppendFile("success-vol", "tmp-file", []byte("hello")); err != nil {
+		t.Fatalf("Unable to create file, %s", err)
+	}
+	if err = posixStorage.RenameFile("success-vol", "tmp-file", "success-vol", "dir/Photo.jpg"); err != errFileNameCollision {
+		t.Errorf("Expected RenameFile error %v, got %v", errFileNameCollision, err)
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -381,6 +383,56 @@ func testPostPolicyBucketHandler(obj ObjectLayer, instanceType string, t TestErr
 
 }
 
+// Tests the responses to POST form uploads asking for a redirect or a status.
+func TestWritePostPolicyResponse(t *testing.T) {
+	etag := `"5d41402abc4b2a76b9719d911017c592"`
+	parsePolicy := func(conditions string) PostPolicyForm {
+		postPolicyForm, err := parsePostPolicyForm(`{"expiration": "2030-01-01T00:00:00.000Z", "conditions": [` + conditions + `]}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return postPolicyForm
+	}
+	noPolicy := parsePolicy("")
+	redirectPolicy := parsePolicy(`["starts-with", "$success_action_redirect", "https://example.com/"]`)
+	testCases := []struct {
+		formValues     map[string]string
+		postPolicyForm PostPolicyForm
+		expectedStatus int
+		location       string
+		body           string
+	}{
+		{map[string]string{}, noPolicy, http.StatusNoContent, "", ""},
+		{map[string]string{"Success_action_status": "200"}, noPolicy, http.StatusOK, "", ""},
+		{map[string]string{"Success_action_status": "201"}, noPolicy, http.StatusCreated, "",
+			"<PostResponse><Location>/bucket/photos/cat.jpg</Location><Bucket>bucket</Bucket><Key>photos/cat.jpg</Key><ETag>&#34;5d41402abc4b2a76b9719d911017c592&#34;</ETag></PostResponse>"},
+		{map[string]string{"Success_action_status": "404"}, noPolicy, http.StatusNoContent, "", ""},
+		{map[string]string{"Success_action_redirect": "https://example.com/done?from=upload", "Success_action_status": "201"}, redirectPolicy,
+			http.StatusSeeOther, "https://example.com/done?bucket=bucket&etag=" + url.QueryEscape(etag) + "&from=upload&key=photos%2Fcat.jpg", ""},
+		{map[string]string{"Redirect": "https://example.com/done"}, parsePolicy(`{"redirect": "https://example.com/done"}`),
+			http.StatusSeeOther, "https://example.com/done?bucket=bucket&etag=" + url.QueryEscape(etag) + "&key=photos%2Fcat.jpg", ""},
+		// Relative redirects are ignored.
+		{map[string]string{"Success_action_redirect": "/done"}, parsePolicy(`["eq", "$success_action_redirect", "/done"]`), http.StatusNoContent, "", ""},
+		// Redirects the policy does not cover are ignored.
+		{map[string]string{"Success_action_redirect": "https://evil.example.org/"}, noPolicy, http.StatusNoContent, "", ""},
+		{map[string]string{"Success_action_redirect": "https://evil.example.org/", "Success_action_status": "201"}, noPolicy, http.StatusCreated, "", ""},
+		{map[string]string{"Redirect": "https://evil.example.org/"}, redirectPolicy, http.StatusNoContent, "", ""},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		writePostPolicyResponse(rec, testCase.formValues, testCase.postPolicyForm, "bucket", "photos/cat.jpg", "5d41402abc4b2a76b9719d911017c592")
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, rec.Code)
+		}
+		if testCase.location != "" && rec.Header().Get("Location") != testCase.location {
+			t.Errorf("Test %d: Expected location %s, got %s", i+1, testCase.location, rec.Header().Get("Location"))
+		}
+		if body := rec.Body.String(); !strings.HasSuffix(body, testCase.body) {
+			t.Errorf("Test %d: Expected body %s, got %s", i+1, testCase.body, body)
+		}
+	}
+}
+
 // postPresignSignatureV4 - presigned signature for PostPolicy requests.
 func postPresignSignatureV4(policyBase64 string, t time.Time, secretAccessKey, location string) string {
 	// Get signining key.
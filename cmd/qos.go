@@ -0,0 +1,255 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Interactive requests served in a row while bulk requests wait,
+// before a bulk request is served so that it is never starved.
+const qosInteractiveBurst = 4
+
+// qosClass - scheduling class of a request under contention.
+type qosClass int
+
+const (
+	// Served first.
+	qosInteractive qosClass = iota
+	// Backups and other bulk transfers, served when no interactive
+	// request waits.
+	qosBulk
+	qosClasses
+)
+
+func (class qosClass) String() string {
+	if class == qosBulk {
+		return "bulk"
+	}
+	return "interactive"
+}
+
+// QoSStats - requests served and waiting per scheduling class.
+type QoSStats struct {
+	// Configured limit, zero if disabled.
+	MaxRequests int `json:"maxRequests"`
+	// Requests served at present.
+	Running int `json:"running"`
+	// Requests waiting for a slot per class.
+	Waiting map[string]int `json:"waiting"`
+	// Requests served per class since the server started.
+	Served map[string]uint64 `json:"served"`
+}
+
+// qosConfig - access keys and buckets whose requests are bulk.
+type qosConfig struct {
+	maxRequests    int
+	bulkAccessKeys map[string]bool
+	bulkBuckets    map[string]bool
+}
+
+// parseQoSList - parses a comma separated list, empty entries are
+// ignored.
+func parseQoSList(value string) map[string]bool {
+	set := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			set[entry] = true
+		}
+	}
+	return set
+}
+
+// parseQoSConfig - parses the values of MINIO_QOS_MAX_REQUESTS,
+// MINIO_QOS_BULK_ACCESS_KEYS and MINIO_QOS_BULK_BUCKETS.
+func parseQoSConfig(maxRequests, bulkAccessKeys, bulkBuckets string) (config qosConfig, err error) {
+	switch strings.ToLower(maxRequests) {
+	case "", "off":
+	default:
+		config.maxRequests, err = strconv.Atoi(maxRequests)
+		if err != nil || config.maxRequests <= 0 {
+			return qosConfig{}, fmt.Errorf("Unknown value `%s` for MINIO_QOS_MAX_REQUESTS, expected a positive number or `off`", maxRequests)
+		}
+	}
+	config.bulkAccessKeys = parseQoSList(bulkAccessKeys)
+	config.bulkBuckets = parseQoSList(bulkBuckets)
+	if config.maxRequests == 0 && (len(config.bulkAccessKeys) > 0 || len(config.bulkBuckets) > 0) {
+		return qosConfig{}, fmt.Errorf("MINIO_QOS_MAX_REQUESTS is required by MINIO_QOS_BULK_ACCESS_KEYS and MINIO_QOS_BULK_BUCKETS")
+	}
+	return config, nil
+}
+
+// loadQoSConfig - loads the scheduling classes from the environment.
+func loadQoSConfig() (qosConfig, error) {
+	return parseQoSConfig(os.Getenv("MINIO_QOS_MAX_REQUESTS"), os.Getenv("MINIO_QOS_BULK_ACCESS_KEYS"),
+		os.Getenv("MINIO_QOS_BULK_BUCKETS"))
+}
+
+// getRequestQoSClass - returns bulk if the access key or the bucket of
+// r is tagged as bulk.
+func (config qosConfig) getRequestQoSClass(r *http.Request) qosClass {
+	if accessKey := getRequestAccessKey(r); accessKey != "" && config.bulkAccessKeys[accessKey] {
+		return qosBulk
+	}
+	bucket := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	if bucket != "" && config.bulkBuckets[bucket] {
+		return qosBulk
+	}
+	return qosInteractive
+}
+
+// qosScheduler - serves at most maxRequests requests at a time, the
+// others wait in a queue per class. Freed slots go to interactive
+// requests first, but every qosInteractiveBurst interactive requests
+// one waiting bulk request is served.
+type qosScheduler struct {
+	mu          sync.Mutex
+	maxRequests int
+	running     int
+	waiting     [qosClasses][]chan struct{}
+	burst       int
+	served      [qosClasses]uint64
+}
+
+// Global request scheduler, nil unless enabled with MINIO_QOS_MAX_REQUESTS.
+var globalQoSScheduler *qosScheduler
+
+// Access keys and buckets tagged with a class, set with
+// MINIO_QOS_BULK_ACCESS_KEYS and MINIO_QOS_BULK_BUCKETS.
+var globalQoSConfig qosConfig
+
+func newQoSScheduler(maxRequests int) *qosScheduler {
+	return &qosScheduler{maxRequests: maxRequests}
+}
+
+// acquire - waits for a slot, returns false without one if done is
+// closed first.
+func (s *qosScheduler) acquire(class qosClass, done <-chan bool) bool {
+	s.mu.Lock()
+	if s.running < s.maxRequests {
+		s.running++
+		s.served[class]++
+		s.mu.Unlock()
+		return true
+	}
+	ready := make(chan struct{})
+	s.waiting[class] = append(s.waiting[class], ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-done:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, waiter := range s.waiting[class] {
+		if waiter == ready {
+			s.waiting[class] = append(s.waiting[class][:i], s.waiting[class][i+1:]...)
+			return false
+		}
+	}
+	// Handed a slot meanwhile, pass it on.
+	s.next()
+	return false
+}
+
+// release - frees the slot of a served request.
+func (s *qosScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next()
+}
+
+// next - hands the slot of a completed request to the next waiting
+// request, caller must hold the lock.
+func (s *qosScheduler) next() {
+	interactive, bulk := len(s.waiting[qosInteractive]) > 0, len(s.waiting[qosBulk]) > 0
+	var class qosClass
+	switch {
+	case interactive && bulk && s.burst < qosInteractiveBurst:
+		class = qosInteractive
+		s.burst++
+	case interactive && !bulk:
+		class = qosInteractive
+		s.burst = 0
+	case bulk:
+		class = qosBulk
+		s.burst = 0
+	default:
+		s.running--
+		return
+	}
+	ready := s.waiting[class][0]
+	s.waiting[class] = s.waiting[class][1:]
+	s.served[class]++
+	close(ready)
+}
+
+// stats - returns the requests served and waiting per class.
+func (s *qosScheduler) stats() QoSStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := QoSStats{
+		MaxRequests: s.maxRequests,
+		Running:     s.running,
+		Waiting:     make(map[string]int, qosClasses),
+		Served:      make(map[string]uint64, qosClasses),
+	}
+	for class := qosInteractive; class < qosClasses; class++ {
+		stats.Waiting[class.String()] = len(s.waiting[class])
+		stats.Served[class.String()] = s.served[class]
+	}
+	return stats
+}
+
+type qosHandler struct {
+	handler http.Handler
+}
+
+// setQoSHandler - serves S3 requests in the order of their scheduling
+// class while more than MINIO_QOS_MAX_REQUESTS are received. The slot
+// is held until the response is complete, so the backend IO of bulk
+// requests also yields to interactive ones.
+func setQoSHandler(h http.Handler) http.Handler {
+	return qosHandler{h}
+}
+
+func (h qosHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := globalQoSScheduler
+	if s == nil || getAPIPriority(getRequestAPIName(r)) == priorityCritical {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	var done <-chan bool
+	if cn, ok := w.(http.CloseNotifier); ok {
+		done = cn.CloseNotify()
+	}
+	if !s.acquire(globalQoSConfig.getRequestQoSClass(r), done) {
+		// Client went away while waiting.
+		return
+	}
+	defer s.release()
+	h.handler.ServeHTTP(w, r)
+}
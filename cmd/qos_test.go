@@ -0,0 +1,128 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Tests parsing the QoS configuration and classifying requests.
+func TestParseQoSConfig(t *testing.T) {
+	testCases := []struct {
+		maxRequests    string
+		bulkAccessKeys string
+		bulkBuckets    string
+		success        bool
+	}{
+		{"", "", "", true},
+		{"off", "", "", true},
+		{"8", "backup, ", "archive,logs", true},
+		{"0", "", "", false},
+		{"many", "", "", false},
+		{"", "backup", "", false},
+	}
+	for i, testCase := range testCases {
+		_, err := parseQoSConfig(testCase.maxRequests, testCase.bulkAccessKeys, testCase.bulkBuckets)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+	}
+
+	config, err := parseQoSConfig("8", "backup", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classTestCases := []struct {
+		path      string
+		accessKey string
+		class     qosClass
+	}{
+		{"/bucket/object", "", qosInteractive},
+		{"/bucket/object", "user", qosInteractive},
+		{"/bucket/object", "backup", qosBulk},
+		{"/archive/object", "user", qosBulk},
+		{"/archive", "", qosBulk},
+		{"/", "", qosInteractive},
+	}
+	for i, testCase := range classTestCases {
+		req, err := http.NewRequest("GET", "http://localhost:9000"+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.accessKey != "" {
+			req.Header.Set("Authorization", signV2Algorithm+" "+testCase.accessKey+":signature")
+		}
+		if class := config.getRequestQoSClass(req); class != testCase.class {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.class, class)
+		}
+	}
+}
+
+// Tests that waiting interactive requests are served before bulk ones
+// without starving them.
+func TestQoSScheduler(t *testing.T) {
+	s := newQoSScheduler(1)
+	if !s.acquire(qosInteractive, nil) {
+		t.Fatal("Expected a free slot")
+	}
+
+	// Queue bulk requests first, then interactive ones.
+	order := make(chan qosClass, 10)
+	queue := func(class qosClass, n int) {
+		for i := 0; i < n; i++ {
+			go func() {
+				if s.acquire(class, nil) {
+					order <- class
+					s.release()
+				}
+			}()
+		}
+		for {
+			if stats := s.stats(); stats.Waiting[class.String()] == n {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	queue(qosBulk, 2)
+	queue(qosInteractive, 6)
+
+	// A client leaving while waiting gives up its place.
+	done := make(chan bool, 1)
+	done <- true
+	if s.acquire(qosBulk, done) {
+		t.Fatal("Expected no slot for a closed request")
+	}
+
+	s.release()
+	expected := []qosClass{
+		qosInteractive, qosInteractive, qosInteractive, qosInteractive,
+		qosBulk, qosInteractive, qosInteractive, qosBulk,
+	}
+	for i, class := range expected {
+		if got := <-order; got != class {
+			t.Errorf("Request %d: Expected %s, got %s", i+1, class, got)
+		}
+	}
+
+	stats := s.stats()
+	if stats.Running != 0 || stats.Served["interactive"] != 7 || stats.Served["bulk"] != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
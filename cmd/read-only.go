@@ -0,0 +1,98 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Admin API requests which write to the backend, the config or the
+// credentials, by method and path below the admin API prefix.
+var mutatingAdminRequests = []struct {
+	method, path string
+}{
+	{"POST", "/heal"},
+	{"POST", "/batch"},
+	{"POST", "/events/replay"},
+	{"PUT", "/import"},
+	{"PUT", "/config"},
+	{"PUT", "/policy"},
+	{"POST", "/credential/rotate"},
+	{"PUT", "/credential/expiry"},
+	{"POST", "/kms/rewrap"},
+}
+
+// isMutatingAdminRequest - returns true for the admin API requests
+// which write, a consistency check only when it repairs.
+func isMutatingAdminRequest(r *http.Request) bool {
+	urlPath := strings.TrimPrefix(r.URL.Path, adminAPIPathPrefix+"/"+adminAPIVersion)
+	if r.Method == "POST" && urlPath == "/fsck" {
+		repair, _ := strconv.ParseBool(r.URL.Query().Get("repair"))
+		return repair
+	}
+	for _, request := range mutatingAdminRequests {
+		if r.Method == request.method && (urlPath == request.path || strings.HasPrefix(urlPath, request.path+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMutatingRequest - returns true for every S3 request which modifies
+// buckets, objects or their configuration, deletes included, for
+// browser uploads and for the admin API requests which write. Selecting
+// object content only reads it. The calls between servers are not S3
+// requests.
+func isMutatingRequest(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/upload/") {
+		return r.Method == "PUT"
+	}
+	if strings.HasPrefix(r.URL.Path, adminAPIPathPrefix+"/"+adminAPIVersion+"/") {
+		return isMutatingAdminRequest(r)
+	}
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/") {
+		return false
+	}
+	switch r.Method {
+	case "PUT", "DELETE":
+		return true
+	case "POST":
+		_, isSelect := r.URL.Query()["select"]
+		return !isSelect
+	}
+	return false
+}
+
+type readOnlyHandler struct {
+	handler http.Handler
+}
+
+// setReadOnlyHandler - rejects all the writes, admin API included, if
+// the server was started with --read-only.
+func setReadOnlyHandler(h http.Handler) http.Handler {
+	return readOnlyHandler{h}
+}
+
+func (h readOnlyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if globalIsReadOnly && isMutatingRequest(r) {
+		writeErrorResponse(w, r, ErrServerReadOnly, r.URL.Path)
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,255 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that all the writes are rejected if read-only, and only then.
+func TestReadOnlyHandler(t *testing.T) {
+	defer func(readOnly bool) { globalIsReadOnly = readOnly }(globalIsReadOnly)
+
+	handler := setReadOnlyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testCases := []struct {
+		method, url string
+		mutating    bool
+	}{
+		{"GET", "/", false},
+		{"GET", "/bucket?list-type=2", false},
+		{"HEAD", "/bucket", false},
+		{"GET", "/bucket/object", false},
+		{"HEAD", "/bucket/object", false},
+		{"GET", "/bucket/object?uploadId=id", false},
+		{"POST", "/bucket/object?select&select-type=2", false},
+		{"PUT", "/bucket", true},
+		{"PUT", "/bucket?policy", true},
+		{"DELETE", "/bucket", true},
+		{"POST", "/bucket?delete", true},
+		{"POST", "/bucket", true},
+		{"PUT", "/bucket/object", true},
+		{"POST", "/bucket/object?uploads", true},
+		{"POST", "/bucket/object?uploadId=id", true},
+		{"DELETE", "/bucket/object", true},
+		{"PUT", "/minio/upload/bucket/object", true},
+		{"GET", "/minio/download/bucket/object", false},
+		{"POST", "/minio/webrpc", false},
+		{"POST", "/minio/admin/v1/fsck", false},
+		{"POST", "/minio/admin/v1/fsck?repair=false", false},
+		{"POST", "/minio/admin/v1/fsck?repair=true", true},
+		{"POST", "/minio/admin/v1/verify/bucket", false},
+		{"GET", "/minio/admin/v1/config", false},
+		{"GET", "/minio/admin/v1/batch", false},
+		{"GET", "/minio/admin/v1/export/bucket", false},
+		{"POST", "/minio/admin/v1/heal/bucket/object", true},
+		{"POST", "/minio/admin/v1/batch", true},
+		{"POST", "/minio/admin/v1/events/replay", true},
+		{"PUT", "/minio/admin/v1/import/bucket", true},
+		{"PUT", "/minio/admin/v1/config", true},
+		{"PUT", "/minio/admin/v1/policy/bucket", true},
+		{"POST", "/minio/admin/v1/credential/rotate", true},
+		{"PUT", "/minio/admin/v1/credential/expiry", true},
+		{"POST", "/minio/admin/v1/kms/rewrap/bucket", true},
+	}
+	for _, readOnly := range []bool{false, true} {
+		globalIsReadOnly = readOnly
+		for i, testCase := range testCases {
+			req, err := http.NewRequest(testCase.method, "http://127.0.0.1:9000"+testCase.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if !(readOnly && testCase.mutating) {
+				if rec.Code != http.StatusOK {
+					t.Errorf("Test %d: %s %s, read-only %v: expected status 200, got %d",
+						i+1, testCase.method, testCase.url, readOnly, rec.Code)
+				}
+				continue
+			}
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("Test %d: %s %s: expected status 403, got %d", i+1, testCase.method, testCase.url, rec.Code)
+			}
+			errorResponse := APIErrorResponse{}
+			if err = xml.Unmarshal(rec.Body.Bytes(), &errorResponse); err != nil {
+				t.Fatalf("Test %d: Unable to unmarshal response body %s", i+1, rec.Body.String())
+			}
+			if errorResponse.Code != "XMinioServerReadOnly" {
+				t.Errorf("Test %d: Expected XMinioServerReadOnly, got %s", i+1, errorResponse.Code)
+			}
+		}
+	}
+}
+
+// Tests that the writes of the browser are rejected if read-only.
+func TestWebHandlerReadOnly(t *testing.T) {
+	ExecObjectLayerTest(t, testWebHandlerReadOnly)
+}
+
+func testWebHandlerReadOnly(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	defer func(readOnly bool) { globalIsReadOnly = readOnly }(globalIsReadOnly)
+
+	apiRouter := initTestWebRPCEndPoint(obj)
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	globalIsReadOnly = true
+
+	testCases := []struct {
+		method string
+		args   interface{}
+	}{
+		{"Web.MakeBucket", MakeBucketArgs{BucketName: getRandomBucketName()}},
+		{"Web.RemoveObject", RemoveObjectArgs{BucketName: bucketName, ObjectName: "object"}},
+		{"Web.SetBucketPolicy", SetBucketPolicyArgs{BucketName: bucketName, Prefix: "", Policy: "readonly"}},
+		{"Web.SetAuth", SetAuthArgs{AccessKey: "new-access-key", SecretKey: "new-secret-key"}},
+	}
+	for i, testCase := range testCases {
+		req, err := newTestWebRPCRequest(testCase.method, authorization, testCase.args)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request: <ERROR> %v", i+1, err)
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		err = getTestWebRPCResponse(rec, &WebGenericRep{})
+		if err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("Test %d: %s: Expected %s to be rejected, got %v", i+1, instanceType, testCase.method, err)
+		}
+	}
+
+	if cred := serverConfig.GetCredential(); cred.AccessKeyID != credentials.AccessKeyID {
+		t.Errorf("%s: Expected the credentials to be kept, got %s", instanceType, cred.AccessKeyID)
+	}
+
+	// Reads are still served.
+	req, err := newTestWebRPCRequest("Web.ListBuckets", authorization, WebGenericArgs{})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+	}
+	rec := httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, req)
+	reply := &ListBucketsRep{}
+	if err = getTestWebRPCResponse(rec, &reply); err != nil || len(reply.Buckets) != 1 {
+		t.Errorf("%s: Unexpected buckets %v, %v", instanceType, reply.Buckets, err)
+	}
+}
+
+// Tests that the background writers are paused if read-only.
+func TestBackgroundWritersReadOnly(t *testing.T) {
+	ExecObjectLayerTest(t, testBackgroundWritersReadOnly)
+}
+
+func testBackgroundWritersReadOnly(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	defer func(readOnly bool) { globalIsReadOnly = readOnly }(globalIsReadOnly)
+
+	bucket, destBucket := getRandomBucketName(), getRandomBucketName()
+	for _, b := range []string{bucket, destBucket} {
+		if err := obj.MakeBucket(b); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	if _, err := obj.PutObject(bucket, "logs/a.log", 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	uploadID, err := obj.NewMultipartUpload(bucket, "upload", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var lcfg lifecycleConfig
+	rules := `<LifecycleConfiguration>
+<Rule><Prefix>logs/</Prefix><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule>
+</LifecycleConfiguration>`
+	if err = xml.Unmarshal([]byte(rules), &lcfg); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if err = persistLifecycleConfig(bucket, &lcfg, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	icfg := &inventoryConfig{Format: inventoryFormatCSV}
+	icfg.Destination.Bucket = destBucket
+	icfg.Schedule.Frequency = inventoryFrequencyDaily
+	if err = persistInventoryConfig(bucket, icfg, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	globalIsReadOnly = true
+	later := time.Now().UTC().Add(30 * 24 * time.Hour)
+	if err = applyLifecycle(bucket, later, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "logs/a.log"); err != nil {
+		t.Errorf("%s: Expected the object not to expire, got %v", instanceType, err)
+	}
+	if aborted, err := reapStaleUploads(obj, time.Hour, later); err != nil || aborted != 0 {
+		t.Errorf("%s: Expected no upload aborted, got %d, %v", instanceType, aborted, err)
+	}
+	if _, err = obj.ListObjectParts(bucket, "upload", uploadID, 0, maxPartsList); err != nil {
+		t.Errorf("%s: Expected the upload to be kept, got %v", instanceType, err)
+	}
+	if err = runDueInventory(bucket, later, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	lo, err := obj.ListObjects(destBucket, "", "", "", 1000)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(lo.Objects) != 0 {
+		t.Errorf("%s: Expected no inventory report, got %d objects", instanceType, len(lo.Objects))
+	}
+
+	// They resume once writable.
+	globalIsReadOnly = false
+	if err = applyLifecycle(bucket, later, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "logs/a.log"); !isErrObjectNotFound(err) {
+		t.Errorf("%s: Expected the object to expire, got %v", instanceType, err)
+	}
+	if aborted, err := reapStaleUploads(obj, time.Hour, later); err != nil || aborted != 1 {
+		t.Errorf("%s: Expected the upload to be aborted, got %d, %v", instanceType, aborted, err)
+	}
+	if err = runDueInventory(bucket, later, obj); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if lo, err = obj.ListObjects(destBucket, "", "", "", 1000); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(lo.Objects) == 0 {
+		t.Errorf("%s: Expected an inventory report", instanceType)
+	}
+}
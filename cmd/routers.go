@@ -35,6 +35,9 @@ func newObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	if len(storageDisks) == 1 {
 		// Initialize FS object layer.
 		objAPI, err = newFSObjects(storageDisks[0])
+	} else if globalIsJBOD {
+		// Initialize JBOD object layer.
+		objAPI, err = newJBODObjects(storageDisks)
 	} else {
 		// Initialize XL object layer.
 		objAPI, err = newXLObjects(storageDisks)
@@ -42,7 +45,12 @@ func newObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	if err != nil {
 		return nil, err
 	}
+	return configureObjectLayer(objAPI)
+}
 
+// configureObjectLayer - loads the bucket configurations of objAPI and
+// wraps it with the optional object layers.
+func configureObjectLayer(objAPI ObjectLayer) (ObjectLayer, error) {
 	// The following actions are performed here, so that any
 	// requests coming in early in the bootup sequence don't fail
 	// unexpectedly - e.g. if initEventNotifier was initialized
@@ -51,7 +59,7 @@ func newObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	// drops or crashes.
 
 	// Migrate bucket policy from configDir to .minio.sys/buckets/
-	err = migrateBucketPolicyConfig(objAPI)
+	err := migrateBucketPolicyConfig(objAPI)
 	if err != nil {
 		errorIf(err, "Unable to migrate bucket policy from config directory")
 		return nil, err
@@ -67,10 +75,55 @@ func newObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	err = initBucketPolicies(objAPI)
 	fatalIf(err, "Unable to load all bucket policies.")
 
+	// Initialize and load bucket transforms.
+	err = initBucketTransforms(objAPI)
+	fatalIf(err, "Unable to load all bucket transforms.")
+
 	// Initialize a new event notifier.
 	err = initEventNotifier(objAPI)
 	fatalIf(err, "Unable to initialize event notification.")
 
+	// Slow down the backend if simulated for testing.
+	if globalDiskSimulation.isEnabled() {
+		objAPI = newSimulatedObjects(objAPI, globalDiskSimulation)
+	}
+
+	// Measure the calls to the backend if enabled.
+	if globalBackendMetrics != nil {
+		objAPI = newMetricsObjects(objAPI, globalBackendMetrics)
+	}
+
+	// Store the data of new objects once if enabled.
+	if globalDedup != nil {
+		if err = globalDedup.load(objAPI); err != nil {
+			errorIf(err, "Unable to load the deduplicated chunks.")
+			return nil, err
+		}
+		objAPI = newDedupObjects(objAPI, globalDedup)
+	}
+
+	// Read the objects missing locally from another backend if enabled.
+	if globalObjectFallback != nil {
+		objAPI = newFallbackObjects(objAPI, globalObjectFallback)
+	}
+
+	// Mirror the writes to another backend if enabled, encrypted
+	// objects are mirrored as they are stored.
+	if globalObjectMirror != nil {
+		objAPI = newMirrorObjects(objAPI, globalObjectMirror)
+	}
+
+	// Encrypt new objects and decrypt encrypted ones if a KMS is
+	// configured.
+	if globalKMS != nil {
+		objAPI = newEncryptedObjects(objAPI, globalKMS, globalPreviousKMS)
+	}
+
+	// Serve repeated listings from the cache if enabled.
+	if globalListCache != nil {
+		objAPI = newListCacheObjects(objAPI, globalListCache)
+	}
+
 	// Success.
 	return objAPI, nil
 }
@@ -102,20 +155,34 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) (http.Handler, error)
 		return nil, err
 	}
 
+	// Register admin router, before the web router since both
+	// share the reserved bucket path prefix.
+	registerAdminRouter(mux)
+
 	// Register RPC router for web related calls.
 	if err = registerBrowserPeerRPCRouter(mux); err != nil {
 		return nil, err
 	}
 
-	if err = registerWebRouter(mux); err != nil {
-		return nil, err
+	// Register web router only if the browser is enabled.
+	if globalIsBrowserEnabled {
+		if err = registerWebRouter(mux); err != nil {
+			return nil, err
+		}
 	}
 
 	// Add API router.
 	registerAPIRouter(mux)
 
+	// Register rest of the handlers.
+	return registerHandlers(mux, serverHandlerFns()...), nil
+}
+
+// serverHandlerFns - returns the generic handlers applied to all
+// incoming requests.
+func serverHandlerFns() []HandlerFunc {
 	// List of some generic handlers which are applied for all incoming requests.
-	var handlerFns = []HandlerFunc{
+	return []HandlerFunc{
 		// Limits all requests size to a maximum fixed limit
 		setRequestSizeLimitHandler,
 		// Adds 'crossdomain.xml' policy handler to serve legacy flash clients.
@@ -137,9 +204,20 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) (http.Handler, error)
 		// routes them accordingly. Client receives a HTTP error for
 		// invalid/unsupported signatures.
 		setAuthHandler,
+		// Rejects all the writes if the server is read-only.
+		setReadOnlyHandler,
+		// Rejects uploads while disk usage is above the watermarks.
+		setStorageFullHandler,
+		// Rejects writes with SlowDown while memory usage is above the limit.
+		setMemoryPressureHandler,
+		// Serves interactive requests before bulk ones above the request limit.
+		setQoSHandler,
+		// Sheds listings, then all S3 requests with SlowDown while the server is saturated.
+		setAdmissionHandler,
+		// Records request statistics for the web console.
+		setHTTPStatsHandler,
+		// Delays requests and caps their bandwidth if enabled for testing.
+		setNetworkSimulationHandler,
 		// Add new handlers here.
 	}
-
-	// Register rest of the handlers.
-	return registerHandlers(mux, handlerFns...), nil
 }
@@ -196,3 +196,18 @@ func S3PeersUpdateBucketPolicy(bucket string, pCh policyChange) {
 		)
 	}
 }
+
+// S3PeersUpdateBucketTransform - Sends update bucket transform request
+// to all peers, nil tcfg removes the transform. Currently we log an
+// error and continue.
+func S3PeersUpdateBucketTransform(bucket string, tcfg *transformConfig) {
+	setBTPArgs := &SetBucketTransformPeerArgs{Bucket: bucket, TCfg: tcfg}
+	errs := globalS3Peers.SendUpdate(nil, setBTPArgs)
+	for idx, err := range errs {
+		errorIf(
+			err,
+			"Error sending update bucket transform to %s - %v",
+			globalS3Peers[idx].addr, err,
+		)
+	}
+}
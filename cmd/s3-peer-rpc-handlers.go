@@ -140,3 +140,32 @@ func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args *SetBucketPolicyPeerArgs,
 
 	return s3.bms.UpdateBucketPolicy(args)
 }
+
+// SetBucketTransformPeerArgs - Arguments collection for
+// SetBucketTransformPeer RPC call
+type SetBucketTransformPeerArgs struct {
+	// For Auth
+	GenericArgs
+
+	Bucket string
+
+	// Transform config, nil if the transform was removed.
+	TCfg *transformConfig
+}
+
+// BucketUpdate - implements bucket transform updates,
+// the underlying operation is a network call updates all
+// the peers participating for new set/unset transforms.
+func (s *SetBucketTransformPeerArgs) BucketUpdate(client BucketMetaState) error {
+	return client.UpdateBucketTransform(s)
+}
+
+// tell receiving server to update a bucket transform
+func (s3 *s3PeerAPIHandlers) SetBucketTransformPeer(args *SetBucketTransformPeerArgs, reply *GenericReply) error {
+	// check auth
+	if !isRPCTokenValid(args.Token) {
+		return errInvalidToken
+	}
+
+	return s3.bms.UpdateBucketTransform(args)
+}
@@ -33,9 +33,25 @@ import (
 
 var serverFlags = []cli.Flag{
 	cli.StringFlag{
-		Name:  "address",
-		Value: ":9000",
-		Usage: `Bind to a specific IP:PORT. Defaults to ":9000".`,
+		Name:   "address",
+		Value:  ":9000",
+		Usage:  `Bind to a specific IP:PORT. Defaults to ":9000".`,
+		EnvVar: "MINIO_ADDRESS",
+	},
+	cli.StringFlag{
+		Name:   "ftp-address",
+		Usage:  `Serve buckets over FTP on a specific IP:PORT, FTPS is enabled and required before login when TLS certificates are configured. Disabled by default.`,
+		EnvVar: "MINIO_FTP_ADDRESS",
+	},
+	cli.BoolFlag{
+		Name:   "jbod",
+		Usage:  `Keep each object whole on one of the disks instead of erasure coding it across all of them. The disks must be local.`,
+		EnvVar: "MINIO_JBOD",
+	},
+	cli.BoolFlag{
+		Name:   "read-only",
+		Usage:  `Reject all the writes with XMinioServerReadOnly, admin API included, reads and listings are served. Lifecycle expiration, the multipart reaper and inventory reports are paused.`,
+		EnvVar: "MINIO_READ_ONLY",
 	},
 }
 
@@ -57,6 +73,111 @@ ENVIRONMENT VARIABLES:
   ACCESS:
      MINIO_ACCESS_KEY: Username or access key of 5 to 20 characters in length.
      MINIO_SECRET_KEY: Password or secret key of 8 to 40 characters in length.
+     MINIO_ACCESS_KEY_FILE, MINIO_SECRET_KEY_FILE: Files to read the keys from, such as Docker or Kubernetes secrets.
+
+  REGION:
+     MINIO_REGION: Region of the server, overrides "region" in config.json.
+
+  BROWSER:
+     MINIO_BROWSER: To disable web browser access, set this value to "off".
+
+  TLS:
+     MINIO_TLS_MIN_VERSION: Minimum TLS version accepted, one of "1.0", "1.1" or "1.2".
+     MINIO_TLS_CIPHER_SUITES: Comma separated list of allowed cipher suites, in order of preference.
+     MINIO_TLS_CURVES: Comma separated list of elliptic curves, in order of preference.
+     Renegotiation is always rejected.
+
+  CONSISTENCY:
+     MINIO_FSCK: Check all objects in the background at startup, set to "check" to report
+       inconsistencies or "repair" to also heal or quarantine them and purge stale temporary files.
+     MINIO_MULTIPART_EXPIRY: Abort multipart uploads without activity for longer than this
+       duration, e.g. "168h". Checked hourly, disabled by default.
+
+  DISK USAGE:
+     MINIO_DISK_HIGH_WATERMARK: Reject uploads with XMinioStorageFull once this percentage of
+       the storage capacity is used, e.g. "90". Disabled by default.
+     MINIO_DISK_LOW_WATERMARK: Accept uploads again once usage drops below this percentage,
+       defaults to the high watermark.
+     MINIO_DISK_FULL_READONLY: Set to "on" to reject all writes except deletes while above
+       the high watermark, not only uploads.
+
+  MEMORY:
+     MINIO_MEMORY_LIMIT: Reject writes with SlowDown while the memory used by the server is above
+       this size, e.g. "4GiB", or percentage of the system memory, e.g. "80%". Writes resume below
+       90% of the limit. Disabled by default.
+
+  ADMISSION:
+     MINIO_ADMISSION_MAX_REQUESTS: Reject S3 requests with SlowDown while this many are in flight.
+       Listings are rejected from 75% of it on. Disabled by default.
+     MINIO_ADMISSION_MAX_LATENCY: Reject listings with SlowDown while the average time to the first
+       response byte is above this duration, e.g. "500ms". Disabled by default.
+
+  QOS:
+     MINIO_QOS_MAX_REQUESTS: Serve at most this many S3 requests at a time, others wait and are
+       served interactive first. Disabled by default.
+     MINIO_QOS_BULK_ACCESS_KEYS: Comma separated access keys whose requests are bulk, e.g. backups.
+     MINIO_QOS_BULK_BUCKETS: Comma separated buckets whose requests are bulk.
+
+  LISTING CACHE:
+     MINIO_LIST_CACHE_TTL: Serve repeated listings from a cache for up to this duration, e.g. "10s".
+       Writes through this server drop the listings they change, writes through other servers of
+       a distributed setup are seen once the listings expire. Disabled by default.
+
+  DEDUPLICATION:
+     MINIO_DEDUP: Set to "on" to store the data of new objects in chunks kept once for all the objects
+       they are part of. Objects written while enabled are only readable with it. Disabled by default.
+
+  BACKEND METRICS:
+     MINIO_BACKEND_METRICS: Set to "on" to count the calls, errors, bytes and latency of every
+       operation of the backend, reported by the admin stats. Disabled by default.
+
+  MIRROR:
+     MINIO_MIRROR_ENDPOINT: Mirror the buckets and objects written to an S3 compatible endpoint,
+       e.g. "https://s3.amazonaws.com". Reads are served locally. Disabled by default.
+     MINIO_MIRROR_ACCESS_KEY, MINIO_MIRROR_SECRET_KEY, MINIO_MIRROR_REGION: Credentials and region
+       of the endpoint, the region defaults to "us-east-1".
+     MINIO_MIRROR_MODE: "sync" to fail writes not mirrored, the default, or "async" to mirror them
+       in the background in order.
+
+  FALLBACK:
+     MINIO_FALLBACK_ENDPOINT: Read the objects missing locally from an S3 compatible endpoint, e.g.
+       to migrate its buckets gradually. Listings and writes stay local. Disabled by default.
+     MINIO_FALLBACK_ACCESS_KEY, MINIO_FALLBACK_SECRET_KEY, MINIO_FALLBACK_REGION: Credentials and
+       region of the endpoint, the region defaults to "us-east-1".
+     MINIO_FALLBACK_POPULATE: Set to "on" to copy the objects read from the endpoint locally.
+
+  FS BACKEND:
+     MINIO_FS_PACK_THRESHOLD: Append objects up to this size, at most "1MiB", to a container file per
+       bucket instead of creating a file for each, e.g. "16KiB". Disabled by default.
+
+  BITROT:
+     MINIO_BITROT_ALGO: Checksum protecting new objects of an erasure coded backend against bitrot,
+       one of "blake2b", "highwayhash256" or "sha256". Defaults to "blake2b", which servers of older
+       releases in a distributed setup can read.
+
+  EVENT JOURNAL:
+     MINIO_EVENT_JOURNAL_RETENTION: Record bucket events for this duration, e.g. "24h", so that they can be
+       listed and replayed to a notification target with the admin API. Disabled by default.
+
+  ENCRYPTION:
+     MINIO_KMS_MASTER_KEY: Encrypt new objects with data keys sealed by this master key, set as
+       "<key-id>:<hex encoded 256 bit key>". Disabled by default.
+     MINIO_KMS_VAULT_ENDPOINT, MINIO_KMS_VAULT_TOKEN, MINIO_KMS_VAULT_KEY_NAME: Seal the data keys
+       with a key of the transit secrets engine of a Vault server instead, created with derived set.
+     MINIO_KMS_PREVIOUS_MASTER_KEY: Keep reading the objects whose data keys are sealed by this master key,
+       replaced by the KMS, until the admin API re-wraps their data keys.
+     Encrypted objects are only readable while the KMS they were encrypted with stays configured.
+
+  TIMEOUTS:
+     MINIO_HTTP_READ_HEADER_TIMEOUT: Close client connections not sending the request headers
+       within this duration, e.g. "30s".
+     MINIO_HTTP_IDLE_TIMEOUT: Close keep-alive client connections idle for longer than this duration.
+     MINIO_HTTP_PROGRESS_TIMEOUT: Abort requests not transferring a byte for longer than this duration.
+     MINIO_HTTP_TRANSFER_TIMEOUT: Abort requests not completed within this duration.
+     MINIO_REMOTE_READ_HEADER_TIMEOUT, MINIO_REMOTE_IDLE_TIMEOUT, MINIO_REMOTE_PROGRESS_TIMEOUT,
+     MINIO_REMOTE_TRANSFER_TIMEOUT: Same for connections to transform endpoints. The read header
+       timeout also bounds connecting, "3s" by default, and is the only one used for remote peers.
+     Timeouts are otherwise disabled by default, set them to "off" to disable them explicitly.
 
 EXAMPLES:
   1. Start minio server on "/home/shared" directory.
@@ -65,17 +186,26 @@ EXAMPLES:
   2. Start minio server bound to a specific IP:PORT.
       $ minio {{.Name}} --address 192.168.1.101:9000 /home/shared
 
-  3. Start erasure coded minio server on a 12 disks server.
+  3. Start minio server with an additional FTP frontend on port 2121.
+      $ minio {{.Name}} --ftp-address :2121 /home/shared
+
+  4. Start erasure coded minio server on a 12 disks server.
       $ minio {{.Name}} /mnt/export1/ /mnt/export2/ /mnt/export3/ /mnt/export4/ \
           /mnt/export5/ /mnt/export6/ /mnt/export7/ /mnt/export8/ /mnt/export9/ \
           /mnt/export10/ /mnt/export11/ /mnt/export12/
 
-  4. Start erasure coded distributed minio server on a 4 node setup with 1 drive each. Run following commands on all the 4 nodes.
+  5. Start erasure coded distributed minio server on a 4 node setup with 1 drive each. Run following commands on all the 4 nodes.
       $ export MINIO_ACCESS_KEY=minio
       $ export MINIO_SECRET_KEY=miniostorage
       $ minio {{.Name}} http://192.168.1.11/mnt/export/ http://192.168.1.12/mnt/export/ \
           http://192.168.1.13/mnt/export/ http://192.168.1.14/mnt/export/
 
+  6. Start minio server keeping each object whole on one of 3 disks, without erasure coding.
+      $ minio {{.Name}} --jbod /mnt/export1/ /mnt/export2/ /mnt/export3/
+
+  7. Start minio server serving "/home/shared" read-only, e.g. during maintenance.
+      $ minio {{.Name}} --read-only /home/shared
+
 `,
 }
 
@@ -200,12 +330,106 @@ func initServerConfig(c *cli.Context) {
 	loadRootCAs()
 
 	// When credentials inherited from the env, server cmd has to save them in the disk
-	if os.Getenv("MINIO_ACCESS_KEY") != "" && os.Getenv("MINIO_SECRET_KEY") != "" {
+	if globalIsEnvCreds {
 		// Env credentials are already loaded in serverConfig, just save in the disk
 		err = serverConfig.Save()
 		fatalIf(err, "Unable to save credentials in the disk.")
 	}
 
+	// Enable or disable the web browser.
+	globalIsBrowserEnabled, err = parseBrowserEnv(os.Getenv("MINIO_BROWSER"))
+	fatalIf(err, "Invalid value for MINIO_BROWSER.")
+
+	// Check the backend consistency at startup.
+	globalFsckMode, err = parseFsckEnv(os.Getenv("MINIO_FSCK"))
+	fatalIf(err, "Invalid value for MINIO_FSCK.")
+
+	// Abort stale multipart uploads.
+	globalMultipartExpiry, err = parseMultipartExpiryEnv(os.Getenv("MINIO_MULTIPART_EXPIRY"))
+	fatalIf(err, "Invalid value for MINIO_MULTIPART_EXPIRY.")
+
+	// Load the disk usage watermarks.
+	globalDiskWatermark, err = loadDiskWatermark()
+	fatalIf(err, "Invalid disk usage watermarks.")
+
+	// Load the TLS policy of the listeners.
+	globalTLSPolicy, err = loadTLSPolicy()
+	fatalIf(err, "Invalid TLS policy.")
+
+	// Load the memory limit above which writes are rejected.
+	globalMemoryPressure.limit, err = loadMemoryLimit()
+	fatalIf(err, "Invalid value for MINIO_MEMORY_LIMIT.")
+
+	// Load the limits above which requests are shed.
+	globalAdmissionControl.maxRequests, globalAdmissionControl.maxLatency, err = loadAdmissionLimits()
+	fatalIf(err, "Invalid admission limits.")
+
+	// Load the scheduling classes of access keys and buckets.
+	globalQoSConfig, err = loadQoSConfig()
+	fatalIf(err, "Invalid QoS configuration.")
+	if globalQoSConfig.maxRequests > 0 {
+		globalQoSScheduler = newQoSScheduler(globalQoSConfig.maxRequests)
+	}
+
+	// Load the time listings are cached for.
+	listCacheTTL, err := loadListCacheTTL()
+	fatalIf(err, "Invalid value for MINIO_LIST_CACHE_TTL.")
+	if listCacheTTL > 0 {
+		globalListCache = newListCache(listCacheTTL)
+	}
+
+	// Load the size up to which objects are packed by the FS backend.
+	globalFSPackThreshold, err = loadFSPackThreshold()
+	fatalIf(err, "Invalid value for MINIO_FS_PACK_THRESHOLD.")
+
+	// Load the bit-rot algo used when creating objects.
+	globalBitRotAlgo, err = loadBitRotAlgo()
+	fatalIf(err, "Invalid value for MINIO_BITROT_ALGO.")
+
+	// Load the period bucket events are recorded for.
+	eventJournalRetention, err := loadEventJournalRetention()
+	fatalIf(err, "Invalid value for MINIO_EVENT_JOURNAL_RETENTION.")
+	if eventJournalRetention > 0 {
+		globalEventJournal = newEventJournal(eventJournalRetention)
+	}
+
+	// Load the KMS sealing the data keys of encrypted objects.
+	globalKMS, err = loadKMS()
+	fatalIf(err, "Invalid KMS configuration.")
+	globalPreviousKMS, err = loadPreviousKMS(globalKMS)
+	fatalIf(err, "Invalid value for MINIO_KMS_PREVIOUS_MASTER_KEY.")
+
+	// Load whether the data of new objects is deduplicated.
+	globalDedup, err = loadDedupStore()
+	fatalIf(err, "Invalid value for MINIO_DEDUP.")
+
+	// Load whether the calls to the backend are measured.
+	globalBackendMetrics, err = loadBackendMetrics()
+	fatalIf(err, "Invalid value for MINIO_BACKEND_METRICS.")
+
+	// Load the backend the writes are mirrored to.
+	globalObjectMirror, err = loadObjectMirror()
+	fatalIf(err, "Invalid mirror configuration.")
+
+	// Load the backend missing objects are read from.
+	globalObjectFallback, err = loadObjectFallback()
+	fatalIf(err, "Invalid fallback configuration.")
+
+	// Load the network conditions simulated for testing.
+	globalNetworkSimulation, err = loadNetworkSimulation()
+	fatalIf(err, "Invalid simulated network conditions.")
+
+	// Load the backend conditions simulated for testing.
+	globalDiskSimulation, err = loadDiskSimulation()
+	fatalIf(err, "Invalid simulated disk conditions.")
+
+	// Load the timeouts of client and remote connections.
+	globalHTTPTimeouts, err = loadConnTimeouts("MINIO_HTTP")
+	fatalIf(err, "Invalid client connection timeouts.")
+	globalRemoteTimeouts, err = loadConnTimeouts("MINIO_REMOTE")
+	fatalIf(err, "Invalid remote connection timeouts.")
+	transformHTTPClient = newRemoteHTTPClient(globalRemoteTimeouts)
+
 	// Set maxOpenFiles, This is necessary since default operating
 	// system limits of 1024, 2048 are not enough for Minio server.
 	setMaxOpenFiles()
@@ -218,6 +442,18 @@ func initServerConfig(c *cli.Context) {
 	// Do not fail if this is not allowed, lower limits are fine as well.
 }
 
+// parseBrowserEnv - parses the value of MINIO_BROWSER, the browser is
+// enabled unless the value is "off".
+func parseBrowserEnv(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("Unknown value `%s`, expected `on` or `off`", value)
+}
+
 // Validate if input disks are sufficient for initializing XL.
 func checkSufficientDisks(eps []*url.URL) error {
 	// Verify total number of disks.
@@ -314,6 +550,14 @@ func checkServerSyntax(c *cli.Context) {
 	fatalIf(err, "Unable to parse storage endpoints %s", disks)
 	checkEndpointsSyntax(endpoints, disks)
 
+	if c.Bool("jbod") {
+		// Any number of local disks for JBOD setup.
+		if isDistributedSetup(endpoints) {
+			fatalIf(errJBODDistributed, "Storage endpoint error.")
+		}
+		return
+	}
+
 	if len(endpoints) > 1 {
 		// For XL setup.
 		err = checkSufficientDisks(endpoints)
@@ -413,6 +657,12 @@ func serverMain(c *cli.Context) {
 	// Check if endpoints are part of distributed setup.
 	globalIsDistXL = isDistributedSetup(endpoints)
 
+	// Check if disks are kept as JBOD instead of XL.
+	globalIsJBOD = c.Bool("jbod") && len(endpoints) > 1
+
+	// Check if all the writes are rejected.
+	globalIsReadOnly = c.Bool("read-only")
+
 	// Configure server.
 	srvConfig := serverCmdConfig{
 		serverAddr:   serverAddr,
@@ -432,6 +682,9 @@ func serverMain(c *cli.Context) {
 	// Initialize name space lock.
 	initNSLock(globalIsDistXL)
 
+	// Throttle failed authentication attempts.
+	globalAuthThrottle = newAuthThrottle()
+
 	// Initialize a new HTTP server.
 	apiServer := NewServerMux(serverAddr, handler)
 
@@ -458,8 +711,22 @@ func serverMain(c *cli.Context) {
 		fatalIf(lerr, "Failed to start minio server.")
 	}(tls)
 
-	// Wait for formatting of disks.
-	formattedDisks, err := waitForFormatDisks(firstDisk, endpoints, storageDisks)
+	// Start the optional FTP frontend.
+	if ftpAddr := c.String("ftp-address"); ftpAddr != "" {
+		ftpSrv, ferr := newFTPServer(ftpAddr, tls)
+		fatalIf(ferr, "Unable to initialize FTP server.")
+		go func() {
+			fatalIf(ftpSrv.ListenAndServe(), "Failed to start FTP server.")
+		}()
+	}
+
+	// Wait for formatting of disks, JBOD disks are formatted separately.
+	var formattedDisks []StorageAPI
+	if globalIsJBOD {
+		formattedDisks, err = waitForFormatJBODDisks(endpoints, storageDisks)
+	} else {
+		formattedDisks, err = waitForFormatDisks(firstDisk, endpoints, storageDisks)
+	}
 	fatalIf(err, "formatting storage disks failed")
 
 	// Once formatted, initialize object layer.
@@ -470,6 +737,33 @@ func serverMain(c *cli.Context) {
 	globalObjectAPI = newObject
 	globalObjLayerMutex.Unlock()
 
+	// Sample bucket usage for the web console.
+	startBucketUsageSampler(newObject)
+
+	// Write bucket inventory reports when due.
+	startInventoryScheduler(newObject)
+
+	// Expire objects and uploads under bucket lifecycle rules.
+	startLifecycle(newObject)
+
+	// Record bucket events if requested.
+	startEventJournal(newObject)
+
+	// Check the consistency of the backend if requested.
+	startFsck(newObject)
+
+	// Abort stale multipart uploads if requested.
+	startMultipartReaper(newObject)
+
+	// Reject uploads while disks are above the usage watermarks.
+	startDiskWatermarkMonitor(newObject)
+
+	// Reject writes while memory usage is above the limit.
+	startMemoryPressureMonitor()
+
+	// Shed listings while the average latency is above its limit.
+	startAdmissionMonitor()
+
 	// Prints the formatted startup message once object layer is initialized.
 	printStartupMessage(endPoints)
 
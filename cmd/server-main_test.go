@@ -347,3 +347,26 @@ func TestInitServerConfig(t *testing.T) {
 		initServerConfig(ctx)
 	}
 }
+
+// Tests parsing of MINIO_BROWSER.
+func TestParseBrowserEnv(t *testing.T) {
+	testCases := []struct {
+		value      string
+		enabled    bool
+		shouldPass bool
+	}{
+		{"", true, true},
+		{"on", true, true},
+		{"OFF", false, true},
+		{"disabled", false, false},
+	}
+	for i, testCase := range testCases {
+		enabled, err := parseBrowserEnv(testCase.value)
+		if (err == nil) != testCase.shouldPass {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if enabled != testCase.enabled {
+			t.Errorf("Test %d: Expected %t, got %t", i+1, testCase.enabled, enabled)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -143,6 +144,11 @@ type ListenerMux struct {
 	// Cond is used to signal Close when there are no references to the listener.
 	cond *sync.Cond
 	refs int
+	// Transfers stalled for longer are aborted, zero if disabled.
+	progressTimeout time.Duration
+	// Progress tracking of the accepted connections, by connection.
+	progressMu    sync.Mutex
+	progressConns map[net.Conn]*progressConn
 }
 
 // ListenerMuxAcceptRes contains then final net.Conn data (wrapper by tls or not) to be sent to the http handler
@@ -152,12 +158,14 @@ type ListenerMuxAcceptRes struct {
 }
 
 // newListenerMux listens and wraps accepted connections with tls after protocol peeking
-func newListenerMux(listener net.Listener, config *tls.Config) *ListenerMux {
+func newListenerMux(listener net.Listener, config *tls.Config, progressTimeout time.Duration) *ListenerMux {
 	l := ListenerMux{
-		Listener:    listener,
-		config:      config,
-		cond:        sync.NewCond(&sync.Mutex{}),
-		acceptResCh: make(chan ListenerMuxAcceptRes),
+		Listener:        listener,
+		config:          config,
+		cond:            sync.NewCond(&sync.Mutex{}),
+		acceptResCh:     make(chan ListenerMuxAcceptRes),
+		progressTimeout: progressTimeout,
+		progressConns:   make(map[net.Conn]*progressConn),
 	}
 	// Start listening, wrap connections with tls when needed
 	go func() {
@@ -171,18 +179,41 @@ func newListenerMux(listener net.Listener, config *tls.Config) *ListenerMux {
 			// Wrap the connection with ConnMux to be able to peek the data in the incoming connection
 			// and decide if we need to wrap the connection itself with a TLS or not
 			go func(conn net.Conn) {
-				connMux := NewConnMux(conn)
-				if connMux.PeekProtocol() == "tls" {
-					l.acceptResCh <- ListenerMuxAcceptRes{conn: tls.Server(connMux, l.config)}
-				} else {
-					l.acceptResCh <- ListenerMuxAcceptRes{conn: connMux}
+				var pconn *progressConn
+				if l.progressTimeout > 0 {
+					pconn = newProgressConn(conn, l.progressTimeout)
+					conn = pconn
 				}
+				var res net.Conn = NewConnMux(conn)
+				if res.(*ConnMux).PeekProtocol() == "tls" {
+					res = tls.Server(res, l.config)
+				}
+				if pconn != nil {
+					l.progressMu.Lock()
+					l.progressConns[res] = pconn
+					l.progressMu.Unlock()
+				}
+				l.acceptResCh <- ListenerMuxAcceptRes{conn: res}
 			}(conn)
 		}
 	}()
 	return &l
 }
 
+// trackProgress - enables the progress timeout of a connection while
+// a request is active, to be used as http.Server.ConnState.
+func (l *ListenerMux) trackProgress(conn net.Conn, state http.ConnState) {
+	l.progressMu.Lock()
+	pconn, ok := l.progressConns[conn]
+	if state == http.StateHijacked || state == http.StateClosed {
+		delete(l.progressConns, conn)
+	}
+	l.progressMu.Unlock()
+	if ok {
+		pconn.setActive(state == http.StateActive)
+	}
+}
+
 // IsClosed - Returns if the underlying listener is closed fully.
 func (l *ListenerMux) IsClosed() bool {
 	l.cond.L.Lock()
@@ -241,6 +272,8 @@ type ServerMux struct {
 	listeners       []*ListenerMux
 	WaitGroup       *sync.WaitGroup
 	GracefulTimeout time.Duration
+	// Transfers stalled for longer are aborted, zero if disabled.
+	ProgressTimeout time.Duration
 	mu              sync.Mutex // guards closed, conns, and listener
 	closed          bool
 	conns           map[net.Conn]http.ConnState // except terminal states
@@ -251,16 +284,22 @@ func NewServerMux(addr string, handler http.Handler) *ServerMux {
 	m := &ServerMux{
 		Server: &http.Server{
 			Addr: addr,
-			// Do not add any timeouts Golang net.Conn
-			// closes connections right after 10mins even
-			// if they are not idle.
-			Handler:        handler,
-			MaxHeaderBytes: 1 << 20,
+			// Timeouts are disabled unless configured with
+			// MINIO_HTTP_*_TIMEOUT, Golang net.Conn closes
+			// connections right after 10mins even if they
+			// are not idle.
+			Handler:           handler,
+			MaxHeaderBytes:    1 << 20,
+			ReadHeaderTimeout: globalHTTPTimeouts.readHeader,
+			IdleTimeout:       globalHTTPTimeouts.idle,
+			ReadTimeout:       globalHTTPTimeouts.total,
+			WriteTimeout:      globalHTTPTimeouts.total,
 		},
 		WaitGroup: &sync.WaitGroup{},
 		// Wait for 5 seconds for new incoming connnections, otherwise
 		// forcibly close them during graceful stop or restart.
 		GracefulTimeout: 5 * time.Second,
+		ProgressTimeout: globalHTTPTimeouts.progress,
 	}
 
 	// Track connection state
@@ -271,7 +310,7 @@ func NewServerMux(addr string, handler http.Handler) *ServerMux {
 }
 
 // Initialize listeners on all ports.
-func initListeners(serverAddr string, tls *tls.Config) ([]*ListenerMux, error) {
+func initListeners(serverAddr string, tls *tls.Config, progressTimeout time.Duration) ([]*ListenerMux, error) {
 	host, port, err := net.SplitHostPort(serverAddr)
 	if err != nil {
 		return nil, err
@@ -283,7 +322,7 @@ func initListeners(serverAddr string, tls *tls.Config) ([]*ListenerMux, error) {
 		if err != nil {
 			return nil, err
 		}
-		listeners = append(listeners, newListenerMux(listener, tls))
+		listeners = append(listeners, newListenerMux(listener, tls, progressTimeout))
 		return listeners, nil
 	}
 	var addrs []string
@@ -304,7 +343,7 @@ func initListeners(serverAddr string, tls *tls.Config) ([]*ListenerMux, error) {
 		if err != nil {
 			return nil, err
 		}
-		listeners = append(listeners, newListenerMux(listener, tls))
+		listeners = append(listeners, newListenerMux(listener, tls, progressTimeout))
 	}
 	return listeners, nil
 }
@@ -322,6 +361,7 @@ func (m *ServerMux) ListenAndServe(certFile, keyFile string) (err error) {
 		if config.NextProtos == nil {
 			config.NextProtos = []string{"http/1.1", "h2"}
 		}
+		globalTLSPolicy.apply(config)
 		config.Certificates = make([]tls.Certificate, 1)
 		config.Certificates[0], err = tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
@@ -331,7 +371,7 @@ func (m *ServerMux) ListenAndServe(certFile, keyFile string) (err error) {
 
 	go m.handleServiceSignals()
 
-	listeners, err := initListeners(m.Server.Addr, config)
+	listeners, err := initListeners(m.Server.Addr, config, m.ProgressTimeout)
 	if err != nil {
 		return err
 	}
@@ -365,7 +405,16 @@ func (m *ServerMux) ListenAndServe(certFile, keyFile string) (err error) {
 		wg.Add(1)
 		go func(listener *ListenerMux) {
 			defer wg.Done()
-			serr := http.Serve(listener, httpHandler)
+			srv := &http.Server{
+				Handler:           httpHandler,
+				MaxHeaderBytes:    m.Server.MaxHeaderBytes,
+				ReadHeaderTimeout: m.Server.ReadHeaderTimeout,
+				IdleTimeout:       m.Server.IdleTimeout,
+				ReadTimeout:       m.Server.ReadTimeout,
+				WriteTimeout:      m.Server.WriteTimeout,
+				ConnState:         listener.trackProgress,
+			}
+			serr := srv.Serve(listener)
 			// Do not print the error if the listener is closed.
 			if !listener.IsClosed() {
 				errorIf(serr, "Unable to serve incoming requests.")
@@ -437,6 +486,7 @@ func (m *ServerMux) connState() {
 				m.conns = make(map[net.Conn]http.ConnState)
 			}
 			m.conns[c] = cs
+			atomic.AddInt64(&globalHTTPStats.openConns, 1)
 		case http.StateActive:
 			// Only update status to StateActive if it's in the conns dictionary
 			if _, ok := m.conns[c]; ok {
@@ -465,5 +515,6 @@ func (m *ServerMux) forgetConn(c net.Conn) {
 	if _, ok := m.conns[c]; ok {
 		delete(m.conns, c)
 		m.WaitGroup.Done()
+		atomic.AddInt64(&globalHTTPStats.openConns, -1)
 	}
 }
@@ -60,7 +60,7 @@ func runTest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	ln = newListenerMux(ln, &tls.Config{})
+	ln = newListenerMux(ln, &tls.Config{}, 0)
 
 	addr := ln.Addr().String()
 	waitForListener := make(chan error)
@@ -135,7 +135,7 @@ func TestInitListeners(t *testing.T) {
 		},
 	}
 	for i, testCase := range testCases {
-		listeners, err := initListeners(testCase.serverAddr, &tls.Config{})
+		listeners, err := initListeners(testCase.serverAddr, &tls.Config{}, 0)
 		if testCase.shouldPass {
 			if err != nil {
 				t.Fatalf("Test %d: Unable to initialize listeners %s", i+1, err)
@@ -152,7 +152,7 @@ func TestInitListeners(t *testing.T) {
 	}
 	// Windows doesn't have 'localhost' hostname.
 	if runtime.GOOS != "windows" {
-		listeners, err := initListeners("localhost:"+getFreePort(), &tls.Config{})
+		listeners, err := initListeners("localhost:"+getFreePort(), &tls.Config{}, 0)
 		if err != nil {
 			t.Fatalf("Test 3: Unable to initialize listeners %s", err)
 		}
@@ -285,6 +285,29 @@ func TestServerCloseBlocking(t *testing.T) {
 	m.mu.Unlock()
 }
 
+// Tests that open connections are counted in the server statistics.
+func TestServerMuxConnCount(t *testing.T) {
+	savedStats := globalHTTPStats
+	globalHTTPStats = &httpStats{}
+	defer func() { globalHTTPStats = savedStats }()
+
+	m := NewServerMux("", http.NotFoundHandler())
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+
+	m.Server.ConnState(c1, http.StateNew)
+	m.Server.ConnState(c1, http.StateActive)
+	if openConns := globalHTTPStats.openConns; openConns != 1 {
+		t.Errorf("Expected 1 open connection, got %d", openConns)
+	}
+	m.Server.ConnState(c1, http.StateClosed)
+	// Closing twice must not count twice.
+	m.Server.ConnState(c1, http.StateClosed)
+	if openConns := globalHTTPStats.openConns; openConns != 0 {
+		t.Errorf("Expected no open connections, got %d", openConns)
+	}
+}
+
 func TestListenAndServePlain(t *testing.T) {
 	wait := make(chan struct{})
 	addr := net.JoinHostPort("127.0.0.1", getFreePort())
@@ -52,9 +52,12 @@ func printStartupMessage(endPoints []string) {
 	printCLIAccessMsg(endPoints[0])
 	printObjectAPIMsg()
 
+	// The capacity behind a gateway is not known.
 	objAPI := newObjectLayerFn()
 	if objAPI != nil {
-		printStorageInfo(objAPI.StorageInfo())
+		if storageInfo := objAPI.StorageInfo(); storageInfo.Backend.Type != Gateway {
+			printStorageInfo(storageInfo)
+		}
 	}
 
 	if isSSL() {
@@ -80,8 +83,10 @@ func printServerCommonMsg(endPoints []string) {
 	console.Println(colorBlue("Region: ") + colorBold(fmt.Sprintf(getFormatStr(len(region), 3), region)))
 	printEventNotifiers()
 
-	console.Println(colorBlue("\nBrowser Access:"))
-	console.Println(fmt.Sprintf(getFormatStr(len(endPointStr), 3), endPointStr))
+	if globalIsBrowserEnabled {
+		console.Println(colorBlue("\nBrowser Access:"))
+		console.Println(fmt.Sprintf(getFormatStr(len(endPointStr), 3), endPointStr))
+	}
 }
 
 // Prints bucket notification configurations.
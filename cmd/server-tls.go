@@ -0,0 +1,122 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLS versions accepted by MINIO_TLS_MIN_VERSION.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+}
+
+// Cipher suites accepted by MINIO_TLS_CIPHER_SUITES.
+var tlsCipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_RC4_128_SHA":                tls.TLS_RSA_WITH_RC4_128_SHA,
+	"TLS_RSA_WITH_3DES_EDE_CBC_SHA":           tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_RC4_128_SHA":        tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_RC4_128_SHA":          tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA":     tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+}
+
+// Elliptic curves accepted by MINIO_TLS_CURVES.
+var tlsCurves = map[string]tls.CurveID{
+	"P256": tls.CurveP256,
+	"P384": tls.CurveP384,
+	"P521": tls.CurveP521,
+}
+
+// tlsPolicy - restrictions applied to the TLS listeners of the server,
+// zero values leave the Go defaults in place. Renegotiation needs no
+// setting, the Go TLS server always rejects it.
+type tlsPolicy struct {
+	minVersion       uint16
+	cipherSuites     []uint16
+	curvePreferences []tls.CurveID
+}
+
+// apply - sets the policy on config.
+func (p tlsPolicy) apply(config *tls.Config) {
+	config.MinVersion = p.minVersion
+	if len(p.cipherSuites) > 0 {
+		config.CipherSuites = p.cipherSuites
+		// Honor the order of the allow-list.
+		config.PreferServerCipherSuites = true
+	}
+	config.CurvePreferences = p.curvePreferences
+}
+
+// splitTLSList - splits a comma separated list, ignoring spaces and
+// empty entries.
+func splitTLSList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseTLSPolicy - parses the values of MINIO_TLS_MIN_VERSION,
+// MINIO_TLS_CIPHER_SUITES and MINIO_TLS_CURVES.
+func parseTLSPolicy(minVersion, cipherSuites, curves string) (p tlsPolicy, err error) {
+	if minVersion != "" {
+		var ok bool
+		if p.minVersion, ok = tlsVersions[minVersion]; !ok {
+			return p, fmt.Errorf("Unknown TLS version `%s`, expected one of `1.0`, `1.1` or `1.2`", minVersion)
+		}
+	}
+	for _, name := range splitTLSList(cipherSuites) {
+		suite, ok := tlsCipherSuites[strings.ToUpper(name)]
+		if !ok {
+			return p, fmt.Errorf("Unknown TLS cipher suite `%s`", name)
+		}
+		p.cipherSuites = append(p.cipherSuites, suite)
+	}
+	for _, name := range splitTLSList(curves) {
+		curve, ok := tlsCurves[strings.ToUpper(name)]
+		if !ok {
+			return p, fmt.Errorf("Unknown TLS curve `%s`, expected one of `P256`, `P384` or `P521`", name)
+		}
+		p.curvePreferences = append(p.curvePreferences, curve)
+	}
+	return p, nil
+}
+
+// loadTLSPolicy - loads the TLS policy from the environment.
+func loadTLSPolicy() (tlsPolicy, error) {
+	return parseTLSPolicy(os.Getenv("MINIO_TLS_MIN_VERSION"), os.Getenv("MINIO_TLS_CIPHER_SUITES"), os.Getenv("MINIO_TLS_CURVES"))
+}
@@ -0,0 +1,84 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"reflect"
+	"testing"
+)
+
+// Tests parsing of the MINIO_TLS_* variables.
+func TestParseTLSPolicy(t *testing.T) {
+	testCases := []struct {
+		minVersion   string
+		cipherSuites string
+		curves       string
+		policy       tlsPolicy
+		shouldPass   bool
+	}{
+		// Test case - 1.
+		// Nothing set, Go defaults.
+		{"", "", "", tlsPolicy{}, true},
+		// Test case - 2.
+		{"1.2", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls_ecdhe_rsa_with_aes_128_gcm_sha256", "P384,P256",
+			tlsPolicy{
+				minVersion:       tls.VersionTLS12,
+				cipherSuites:     []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+				curvePreferences: []tls.CurveID{tls.CurveP384, tls.CurveP256},
+			}, true},
+		// Test case - 3.
+		// Unknown version.
+		{"1.3", "", "", tlsPolicy{}, false},
+		// Test case - 4.
+		// Unknown cipher suite.
+		{"", "TLS_RSA_WITH_NULL_SHA", "", tlsPolicy{}, false},
+		// Test case - 5.
+		// Unknown curve.
+		{"", "", "P224", tlsPolicy{}, false},
+	}
+	for i, testCase := range testCases {
+		policy, err := parseTLSPolicy(testCase.minVersion, testCase.cipherSuites, testCase.curves)
+		if (err == nil) != testCase.shouldPass {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if testCase.shouldPass && !reflect.DeepEqual(policy, testCase.policy) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.policy, policy)
+		}
+	}
+}
+
+// Tests setting the policy on a TLS config.
+func TestTLSPolicyApply(t *testing.T) {
+	policy := tlsPolicy{
+		minVersion:   tls.VersionTLS12,
+		cipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}
+	config := &tls.Config{}
+	policy.apply(config)
+	if config.MinVersion != tls.VersionTLS12 || !config.PreferServerCipherSuites ||
+		!reflect.DeepEqual(config.CipherSuites, policy.cipherSuites) {
+		t.Errorf("Unexpected TLS config %v", config)
+	}
+
+	// Without an allow-list Go defaults are kept.
+	config = &tls.Config{}
+	tlsPolicy{}.apply(config)
+	if config.MinVersion != 0 || config.CipherSuites != nil || config.PreferServerCipherSuites {
+		t.Errorf("Unexpected TLS config %v", config)
+	}
+}
@@ -1280,6 +1280,59 @@ func (s *TestSuiteCommon) TestHeadOnBucket(c *C) {
 	c.Assert(response.StatusCode, Equals, http.StatusOK)
 }
 
+// TestStandardHeadersPersist - Object upload with Cache-Control, Expires and Content-Disposition,
+// then HEAD and GET requests validate that the headers set during upload are replayed.
+func (s *TestSuiteCommon) TestStandardHeadersPersist(c *C) {
+	// generate a random bucket name.
+	bucketName := getRandomBucketName()
+	// HTTP request to create the bucket.
+	request, err := newTestSignedRequest("PUT", getMakeBucketURL(s.endPoint, bucketName),
+		0, nil, s.accessKey, s.secretKey, s.signer)
+	c.Assert(err, IsNil)
+
+	client := http.Client{Transport: s.transport}
+	// execute the HTTP request to create bucket.
+	response, err := client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	headers := map[string]string{
+		"Cache-Control":       "public, max-age=86400",
+		"Expires":             "Thu, 01 Dec 2044 16:00:00 GMT",
+		"Content-Disposition": "attachment; filename=\"report.txt\"",
+	}
+	buffer := bytes.NewReader([]byte("hello world"))
+	objectName := "test-object.txt"
+	// constructing HTTP request for object upload.
+	request, err = newTestSignedRequest("PUT", getPutObjectURL(s.endPoint, bucketName, objectName),
+		int64(buffer.Len()), buffer, s.accessKey, s.secretKey, s.signer)
+	c.Assert(err, IsNil)
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	if s.signer == signerV2 {
+		err = signRequestV2(request, s.accessKey, s.secretKey)
+		c.Assert(err, IsNil)
+	}
+	// execute the HTTP request for object upload.
+	response, err = client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	for _, method := range []string{"HEAD", "GET"} {
+		request, err = newTestSignedRequest(method, getGetObjectURL(s.endPoint, bucketName, objectName),
+			0, nil, s.accessKey, s.secretKey, s.signer)
+		c.Assert(err, IsNil)
+		response, err = client.Do(request)
+		c.Assert(err, IsNil)
+		c.Assert(response.StatusCode, Equals, http.StatusOK)
+		// Verify that the headers set during the upload persist.
+		for k, v := range headers {
+			c.Assert(response.Header.Get(k), Equals, v)
+		}
+	}
+}
+
 // TestContentTypePersists - Object upload with different Content-type is first done.
 // And then a HEAD and GET request on these objects are done to validate if the same Content-Type set during upload persists.
 func (s *TestSuiteCommon) TestContentTypePersists(c *C) {
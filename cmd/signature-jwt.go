@@ -76,6 +76,8 @@ func (jwt *JWT) GenerateToken(accessKey string) (string, error) {
 
 var errInvalidAccessKeyID = errors.New("The access key ID you provided does not exist in our records")
 var errAuthentication = errors.New("Authentication failed, check your access credentials")
+var errAccessKeyExpired = errors.New("The access key ID you provided has expired")
+var errAuthThrottled = errors.New("Too many failed authentication attempts, please try again later")
 
 // Authenticate - authenticates incoming access key and secret key.
 func (jwt *JWT) Authenticate(accessKey, secretKey string) error {
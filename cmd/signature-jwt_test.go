@@ -75,15 +75,15 @@ func TestNewJWT(t *testing.T) {
 		// Test to read already created config file.
 		{path4, true, nil, nil},
 		// Access key is too small.
-		{path4, false, &credential{"user", "pass"}, errInvalidAccessKeyLength},
+		{path4, false, &credential{AccessKeyID: "user", SecretAccessKey: "pass"}, errInvalidAccessKeyLength},
 		// Access key is too long.
-		{path4, false, &credential{"user12345678901234567", "pass"}, errInvalidAccessKeyLength},
+		{path4, false, &credential{AccessKeyID: "user12345678901234567", SecretAccessKey: "pass"}, errInvalidAccessKeyLength},
 		// Secret key is too small.
-		{path4, false, &credential{"myuser", "pass"}, errInvalidSecretKeyLength},
+		{path4, false, &credential{AccessKeyID: "myuser", SecretAccessKey: "pass"}, errInvalidSecretKeyLength},
 		// Secret key is too long.
-		{path4, false, &credential{"myuser", "pass1234567890123456789012345678901234567"}, errInvalidSecretKeyLength},
+		{path4, false, &credential{AccessKeyID: "myuser", SecretAccessKey: "pass1234567890123456789012345678901234567"}, errInvalidSecretKeyLength},
 		// Valid access/secret keys.
-		{path4, false, &credential{"myuser", "mypassword"}, nil},
+		{path4, false, &credential{AccessKeyID: "myuser", SecretAccessKey: "mypassword"}, nil},
 	}
 
 	// Run tests.
@@ -57,10 +57,10 @@ var resourceList = []string{
 }
 
 func doesPolicySignatureV2Match(formValues map[string]string) APIErrorCode {
-	cred := serverConfig.GetCredential()
 	accessKey := formValues["Awsaccesskeyid"]
-	if accessKey != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
+	cred, s3Error := serverConfig.GetCredentialByAccessKey(accessKey)
+	if s3Error != ErrNone {
+		return s3Error
 	}
 	signature := formValues["Signature"]
 	policy := formValues["Policy"]
@@ -74,9 +74,6 @@ func doesPolicySignatureV2Match(formValues map[string]string) APIErrorCode {
 //     - http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html#RESTAuthenticationQueryStringAuth
 // returns ErrNone if matches. S3 errors otherwise.
 func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
-
 	// url.RawPath will be valid if path has any encoded characters, if not it will
 	// be empty - in which case we need to consider url.Path (bug in net/http?)
 	encodedResource := r.URL.RawPath
@@ -93,7 +90,14 @@ func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
 	var expires string
 	var accessKey string
 	for _, query := range queries {
-		keyval := strings.Split(query, "=")
+		keyval := strings.SplitN(query, "=", 2)
+		// Reserved parameters without a value are malformed.
+		switch keyval[0] {
+		case "AWSAccessKeyId", "Signature", "Expires":
+			if len(keyval) != 2 {
+				return ErrInvalidQueryParams
+			}
+		}
 		switch keyval[0] {
 		case "AWSAccessKeyId":
 			accessKey = keyval[1]
@@ -111,8 +115,9 @@ func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
 	}
 
 	// Validate if access key id same.
-	if accessKey != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
+	cred, s3Error := serverConfig.GetCredentialByAccessKey(accessKey)
+	if s3Error != ErrNone {
+		return s3Error
 	}
 
 	// Make sure the request has not expired.
@@ -125,7 +130,7 @@ func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
 		return ErrExpiredPresignRequest
 	}
 
-	expectedSignature := preSignatureV2(r.Method, encodedResource, strings.Join(filteredQueries, "&"), r.Header, expires)
+	expectedSignature := preSignatureV2(cred, r.Method, encodedResource, strings.Join(filteredQueries, "&"), r.Header, expires)
 	if gotSignature != getURLEncodedName(expectedSignature) {
 		return ErrSignatureDoesNotMatch
 	}
@@ -153,41 +158,37 @@ func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
 //     - http://docs.aws.amazon.com/AmazonS3/latest/dev/auth-request-sig-v2.html
 // returns true if matches, false otherwise. if error is not nil then it is always false
 
-func validateV2AuthHeader(v2Auth string) APIErrorCode {
+func validateV2AuthHeader(v2Auth string) (credential, APIErrorCode) {
 	if v2Auth == "" {
-		return ErrAuthHeaderEmpty
+		return credential{}, ErrAuthHeaderEmpty
 	}
 	// Verify if the header algorithm is supported or not.
 	if !strings.HasPrefix(v2Auth, signV2Algorithm) {
-		return ErrSignatureVersionNotSupported
+		return credential{}, ErrSignatureVersionNotSupported
 	}
 
 	// below is V2 Signed Auth header format, splitting on `space` (after the `AWS` string).
 	// Authorization = "AWS" + " " + AWSAccessKeyId + ":" + Signature
 	authFields := strings.Split(v2Auth, " ")
 	if len(authFields) != 2 {
-		return ErrMissingFields
+		return credential{}, ErrMissingFields
 	}
 
 	// Then will be splitting on ":", this will seprate `AWSAccessKeyId` and `Signature` string.
 	keySignFields := strings.Split(strings.TrimSpace(authFields[1]), ":")
 	if len(keySignFields) != 2 {
-		return ErrMissingFields
+		return credential{}, ErrMissingFields
 	}
 
 	// Access credentials.
-	cred := serverConfig.GetCredential()
-	if keySignFields[0] != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
-	}
-
-	return ErrNone
+	return serverConfig.GetCredentialByAccessKey(keySignFields[0])
 }
 
 func doesSignV2Match(r *http.Request) APIErrorCode {
 	v2Auth := r.Header.Get("Authorization")
 
-	if apiError := validateV2AuthHeader(v2Auth); apiError != ErrNone {
+	cred, apiError := validateV2AuthHeader(v2Auth)
+	if apiError != ErrNone {
 		return apiError
 	}
 
@@ -205,7 +206,7 @@ func doesSignV2Match(r *http.Request) APIErrorCode {
 	// Encode query strings
 	encodedQuery := r.URL.Query().Encode()
 
-	expectedAuth := signatureV2(r.Method, encodedResource, encodedQuery, r.Header)
+	expectedAuth := signatureV2(cred, r.Method, encodedResource, encodedQuery, r.Header)
 	if v2Auth != expectedAuth {
 		return ErrSignatureDoesNotMatch
 	}
@@ -220,15 +221,13 @@ func calculateSignatureV2(stringToSign string, secret string) string {
 }
 
 // Return signature-v2 for the presigned request.
-func preSignatureV2(method string, encodedResource string, encodedQuery string, headers http.Header, expires string) string {
-	cred := serverConfig.GetCredential()
+func preSignatureV2(cred credential, method string, encodedResource string, encodedQuery string, headers http.Header, expires string) string {
 	stringToSign := presignV2STS(method, encodedResource, encodedQuery, headers, expires)
 	return calculateSignatureV2(stringToSign, cred.SecretAccessKey)
 }
 
 // Return signature-v2 authrization header.
-func signatureV2(method string, encodedResource string, encodedQuery string, headers http.Header) string {
-	cred := serverConfig.GetCredential()
+func signatureV2(cred credential, method string, encodedResource string, encodedQuery string, headers http.Header) string {
 	stringToSign := signV2STS(method, encodedResource, encodedQuery, headers)
 	signature := calculateSignatureV2(stringToSign, cred.SecretAccessKey)
 	return fmt.Sprintf("%s %s:%s", signV2Algorithm, cred.AccessKeyID, signature)
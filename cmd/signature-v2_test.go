@@ -33,6 +33,7 @@ func TestDoesPresignedV2SignatureMatch(t *testing.T) {
 
 	testCases := []struct {
 		queryParams map[string]string
+		rawQuery    string
 		headers     map[string]string
 		expected    APIErrorCode
 	}{
@@ -76,6 +77,16 @@ func TestDoesPresignedV2SignatureMatch(t *testing.T) {
 			},
 			expected: ErrSignatureDoesNotMatch,
 		},
+		// (5) Should error on parameters without a value.
+		{
+			rawQuery: "AWSAccessKeyId&Signature&Expires",
+			expected: ErrInvalidQueryParams,
+		},
+		// (6) Should error on a signature without a value.
+		{
+			rawQuery: "AWSAccessKeyId=" + serverConfig.GetCredential().AccessKeyID + "&Expires=60&Signature",
+			expected: ErrInvalidQueryParams,
+		},
 	}
 
 	// Run each test case individually.
@@ -87,7 +98,11 @@ func TestDoesPresignedV2SignatureMatch(t *testing.T) {
 		}
 
 		// Create a request to use.
-		req, e := http.NewRequest(http.MethodGet, "http://host/a/b?"+query.Encode(), nil)
+		rawQuery := query.Encode()
+		if testCase.rawQuery != "" {
+			rawQuery = testCase.rawQuery
+		}
+		req, e := http.NewRequest(http.MethodGet, "http://host/a/b?"+rawQuery, nil)
 		if e != nil {
 			t.Errorf("(%d) failed to create http.Request, got %v", i, e)
 		}
@@ -171,7 +186,7 @@ func TestValidateV2AuthHeader(t *testing.T) {
 	for i, testCase := range testCases {
 		t.Run(fmt.Sprintf("Case %d AuthStr \"%s\".", i+1, testCase.authString), func(t *testing.T) {
 
-			actualErrCode := validateV2AuthHeader(testCase.authString)
+			_, actualErrCode := validateV2AuthHeader(testCase.authString)
 
 			if testCase.expectedError != actualErrCode {
 				t.Errorf("Expected the error code to be %v, got %v.", testCase.expectedError, actualErrCode)
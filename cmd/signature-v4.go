@@ -158,9 +158,6 @@ func doesPolicySignatureMatch(formValues map[string]string) APIErrorCode {
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
 // returns ErrNone if the signature matches.
 func doesPolicySignatureV4Match(formValues map[string]string) APIErrorCode {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
-
 	// Server region.
 	region := serverConfig.GetRegion()
 
@@ -171,8 +168,9 @@ func doesPolicySignatureV4Match(formValues map[string]string) APIErrorCode {
 	}
 
 	// Verify if the access key id matches.
-	if credHeader.accessKey != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
+	cred, s3Error := serverConfig.GetCredentialByAccessKey(credHeader.accessKey)
+	if s3Error != ErrNone {
+		return s3Error
 	}
 
 	// Verify if the region is valid.
@@ -204,9 +202,6 @@ func doesPolicySignatureV4Match(formValues map[string]string) APIErrorCode {
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
 // returns ErrNone if the signature matches.
 func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region string) APIErrorCode {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
-
 	// Copy request
 	req := *r
 
@@ -217,8 +212,9 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 	}
 
 	// Verify if the access key id matches.
-	if pSignValues.Credential.accessKey != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
+	cred, s3Error := serverConfig.GetCredentialByAccessKey(pSignValues.Credential.accessKey)
+	if s3Error != ErrNone {
+		return s3Error
 	}
 
 	// Hashed payload mismatch, return content sha256 mismatch.
@@ -327,9 +323,6 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
 // returns ErrNone if signature matches.
 func doesSignatureMatch(hashedPayload string, r *http.Request, region string) APIErrorCode {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
-
 	// Copy request.
 	req := *r
 
@@ -367,8 +360,9 @@ func doesSignatureMatch(hashedPayload string, r *http.Request, region string) AP
 	}
 
 	// Verify if the access key id matches.
-	if signV4Values.Credential.accessKey != cred.AccessKeyID {
-		return ErrInvalidAccessKeyID
+	cred, s3Error := serverConfig.GetCredentialByAccessKey(signV4Values.Credential.accessKey)
+	if s3Error != ErrNone {
+		return s3Error
 	}
 
 	// Verify if region is valid.
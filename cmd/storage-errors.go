@@ -48,6 +48,14 @@ var errFileNotFound = errors.New("file not found")
 // errFileNameTooLong - given file name is too long than supported length.
 var errFileNameTooLong = errors.New("file name too long")
 
+// errFileNameInvalid - given file name cannot be stored by the
+// operating system.
+var errFileNameInvalid = errors.New("file name not supported by the operating system")
+
+// errFileNameCollision - given file name differs only in case from an
+// existing file on a case insensitive file system.
+var errFileNameCollision = errors.New("file name differs only in case from an existing file")
+
 // errVolumeExists - cannot create same volume again.
 var errVolumeExists = errors.New("volume already exists")
 
@@ -69,6 +69,10 @@ func toStorageErr(err error) error {
 		return errFileNotFound
 	case errFileNameTooLong.Error():
 		return errFileNameTooLong
+	case errFileNameInvalid.Error():
+		return errFileNameInvalid
+	case errFileNameCollision.Error():
+		return errFileNameCollision
 	case errFileAccessDenied.Error():
 		return errFileAccessDenied
 	case errIsNotRegular.Error():
@@ -0,0 +1,64 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/sha1"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"strings"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Reversed polynomial of the CRC-64/NVME checksum.
+const crc64NVMEPolynomial = 0x9a6c9329ac4bc9b5
+
+// hash/crc32 uses the SSE4.2 and ARMv8 CRC32 instructions for the
+// Castagnoli table where available.
+var (
+	crc32cTable    = crc32.MakeTable(crc32.Castagnoli)
+	crc64NVMETable = crc64.MakeTable(crc64NVMEPolynomial)
+)
+
+// trailerChecksums - checksums which can be sent as trailing headers
+// of streaming uploads, by header name.
+var trailerChecksums = map[string]func() hash.Hash{
+	"x-amz-checksum-crc32":     func() hash.Hash { return crc32.NewIEEE() },
+	"x-amz-checksum-crc32c":    func() hash.Hash { return crc32.New(crc32cTable) },
+	"x-amz-checksum-crc64nvme": func() hash.Hash { return crc64.New(crc64NVMETable) },
+	"x-amz-checksum-sha1":      sha1.New,
+	"x-amz-checksum-sha256":    sha256.New,
+}
+
+// parseTrailerHeader - returns the checksum header declared by the
+// x-amz-trailer header, only allowed with the streaming payloads
+// carrying trailing headers, which require it.
+func parseTrailerHeader(payload, trailerHeader string) (string, APIErrorCode) {
+	trailer := strings.ToLower(strings.TrimSpace(trailerHeader))
+	if payload != streamingContentSHA256Trailer && payload != streamingUnsignedTrailer {
+		if trailer != "" {
+			return "", ErrInvalidTrailer
+		}
+		return "", ErrNone
+	}
+	if _, ok := trailerChecksums[trailer]; !ok {
+		return "", ErrInvalidTrailer
+	}
+	return trailer, ErrNone
+}
@@ -21,11 +21,13 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"hash"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -34,16 +36,26 @@ import (
 
 // Streaming AWS Signature Version '4' constants.
 const (
-	emptySHA256            = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
-	streamingContentSHA256 = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
-	signV4ChunkedAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	emptySHA256                   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	streamingContentSHA256        = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingContentSHA256Trailer = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingUnsignedTrailer      = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	signV4ChunkedAlgorithm        = "AWS4-HMAC-SHA256-PAYLOAD"
+	signV4TrailerAlgorithm        = "AWS4-HMAC-SHA256-TRAILER"
 )
 
-// getChunkSignature - get chunk signature.
-func getChunkSignature(seedSignature string, date time.Time, hashedChunk string) string {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
+// isStreamingPayload - returns true if the x-amz-content-sha256 value
+// announces a chunked payload.
+func isStreamingPayload(payload string) bool {
+	switch payload {
+	case streamingContentSHA256, streamingContentSHA256Trailer, streamingUnsignedTrailer:
+		return true
+	}
+	return false
+}
 
+// getChunkSignature - get chunk signature.
+func getChunkSignature(cred credential, seedSignature string, date time.Time, hashedChunk string) string {
 	// Server region.
 	region := serverConfig.GetRegion()
 
@@ -64,14 +76,30 @@ func getChunkSignature(seedSignature string, date time.Time, hashedChunk string)
 	return newSignature
 }
 
+// getTrailerSignature - get the signature of the trailing headers.
+func getTrailerSignature(cred credential, seedSignature string, date time.Time, hashedTrailer string) string {
+	// Server region.
+	region := serverConfig.GetRegion()
+
+	// Calculate string to sign.
+	stringToSign := signV4TrailerAlgorithm + "\n" +
+		date.Format(iso8601Format) + "\n" +
+		getScope(date, region) + "\n" +
+		seedSignature + "\n" +
+		hashedTrailer
+
+	// Get hmac signing key.
+	signingKey := getSigningKey(cred.SecretAccessKey, date, region)
+
+	// Calculate signature.
+	return getSignature(signingKey, stringToSign)
+}
+
 // calculateSeedSignature - Calculate seed signature in accordance with
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
 // returns signature, error otherwise if the signature mismatches or any other
 // error while parsing and validating.
-func calculateSeedSignature(r *http.Request) (signature string, date time.Time, errCode APIErrorCode) {
-	// Access credentials.
-	cred := serverConfig.GetCredential()
-
+func calculateSeedSignature(r *http.Request) (cred credential, signature string, date time.Time, errCode APIErrorCode) {
 	// Server region.
 	region := serverConfig.GetRegion()
 
@@ -84,25 +112,27 @@ func calculateSeedSignature(r *http.Request) (signature string, date time.Time,
 	// Parse signature version '4' header.
 	signV4Values, errCode := parseSignV4(v4Auth)
 	if errCode != ErrNone {
-		return "", time.Time{}, errCode
+		return cred, "", time.Time{}, errCode
 	}
 
 	// Payload streaming.
-	payload := streamingContentSHA256
+	payload := req.Header.Get("X-Amz-Content-Sha256")
 
 	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD'
-	if payload != req.Header.Get("X-Amz-Content-Sha256") {
-		return "", time.Time{}, ErrContentSHA256Mismatch
+	// or one of its variants with trailing headers.
+	if !isStreamingPayload(payload) {
+		return cred, "", time.Time{}, ErrContentSHA256Mismatch
 	}
 
 	// Extract all the signed headers along with its values.
 	extractedSignedHeaders, errCode := extractSignedHeaders(signV4Values.SignedHeaders, req.Header)
 	if errCode != ErrNone {
-		return "", time.Time{}, errCode
+		return cred, "", time.Time{}, errCode
 	}
 	// Verify if the access key id matches.
-	if signV4Values.Credential.accessKey != cred.AccessKeyID {
-		return "", time.Time{}, ErrInvalidAccessKeyID
+	cred, errCode = serverConfig.GetCredentialByAccessKey(signV4Values.Credential.accessKey)
+	if errCode != ErrNone {
+		return cred, "", time.Time{}, errCode
 	}
 
 	// Verify if region is valid.
@@ -110,14 +140,14 @@ func calculateSeedSignature(r *http.Request) (signature string, date time.Time,
 	// Should validate region, only if region is set. Some operations
 	// do not need region validated for example GetBucketLocation.
 	if !isValidRegion(sRegion, region) {
-		return "", time.Time{}, ErrInvalidRegion
+		return cred, "", time.Time{}, ErrInvalidRegion
 	}
 
 	// Extract date, if not present throw error.
 	var dateStr string
 	if dateStr = req.Header.Get(http.CanonicalHeaderKey("x-amz-date")); dateStr == "" {
 		if dateStr = r.Header.Get("Date"); dateStr == "" {
-			return "", time.Time{}, ErrMissingDateHeader
+			return cred, "", time.Time{}, ErrMissingDateHeader
 		}
 	}
 	// Parse date header.
@@ -125,7 +155,7 @@ func calculateSeedSignature(r *http.Request) (signature string, date time.Time,
 	date, err = time.Parse(iso8601Format, dateStr)
 	if err != nil {
 		errorIf(err, "Unable to parse date", dateStr)
-		return "", time.Time{}, ErrMalformedDate
+		return cred, "", time.Time{}, ErrMalformedDate
 	}
 
 	// Query string.
@@ -145,11 +175,11 @@ func calculateSeedSignature(r *http.Request) (signature string, date time.Time,
 
 	// Verify if signature match.
 	if newSignature != signV4Values.Signature {
-		return "", time.Time{}, ErrSignatureDoesNotMatch
+		return cred, "", time.Time{}, ErrSignatureDoesNotMatch
 	}
 
 	// Return caculated signature.
-	return newSignature, date, ErrNone
+	return cred, newSignature, date, ErrNone
 }
 
 const maxLineLength = 4 * humanize.KiByte // assumed <= bufio.defaultBufSize 4KiB
@@ -160,6 +190,12 @@ var errLineTooLong = errors.New("header line too long")
 // Malformed encoding is generated when chunk header is wrongly formed.
 var errMalformedEncoding = errors.New("malformed chunked encoding")
 
+// Malformed trailer is generated when the trailing headers are wrongly formed.
+var errMalformedTrailer = errors.New("malformed trailing headers")
+
+// Checksum mismatch is generated when the trailing checksum does not match the payload.
+var errChecksumMismatch = errors.New("trailing checksum mismatch")
+
 // newSignV4ChunkedReader returns a new s3ChunkedReader that translates the data read from r
 // out of HTTP "chunked" format before returning it.
 // The s3ChunkedReader returns io.EOF when the final 0-length chunk is read.
@@ -167,23 +203,42 @@ var errMalformedEncoding = errors.New("malformed chunked encoding")
 // NewChunkedReader is not needed by normal applications. The http package
 // automatically decodes chunking when reading response bodies.
 func newSignV4ChunkedReader(req *http.Request) (io.Reader, APIErrorCode) {
-	seedSignature, seedDate, errCode := calculateSeedSignature(req)
+	var cred credential
+	var seedSignature string
+	var seedDate time.Time
+	errCode := throttleAuth(req, func() (errCode APIErrorCode) {
+		cred, seedSignature, seedDate, errCode = calculateSeedSignature(req)
+		return errCode
+	})
+	if errCode != ErrNone {
+		return nil, errCode
+	}
+	payload := req.Header.Get("X-Amz-Content-Sha256")
+	trailer, errCode := parseTrailerHeader(payload, req.Header.Get("X-Amz-Trailer"))
 	if errCode != ErrNone {
 		return nil, errCode
 	}
-	return &s3ChunkedReader{
+	cr := &s3ChunkedReader{
 		reader:            bufio.NewReader(req.Body),
+		cred:              cred,
 		seedSignature:     seedSignature,
 		seedDate:          seedDate,
 		chunkSHA256Writer: sha256.New(),
 		state:             readChunkHeader,
-	}, ErrNone
+		unsigned:          payload == streamingUnsignedTrailer,
+		trailer:           trailer,
+	}
+	if trailer != "" {
+		cr.checksumWriter = trailerChecksums[trailer]()
+	}
+	return cr, ErrNone
 }
 
 // Represents the overall state that is required for decoding a
 // AWS Signature V4 chunked reader.
 type s3ChunkedReader struct {
 	reader            *bufio.Reader
+	cred              credential
 	seedSignature     string
 	seedDate          time.Time
 	state             chunkState
@@ -192,6 +247,12 @@ type s3ChunkedReader struct {
 	chunkSHA256Writer hash.Hash // Calculates sha256 of chunk data.
 	n                 uint64    // Unread bytes in chunk
 	err               error
+
+	unsigned         bool      // Chunks are not signed, only the request is.
+	trailer          string    // Checksum header sent after the last chunk.
+	trailerValue     string    // Value of the trailing checksum.
+	trailerSignature string    // Signature of the trailing headers.
+	checksumWriter   hash.Hash // Calculates the trailing checksum of the payload.
 }
 
 // Read chunk reads the chunk token signature portion.
@@ -221,6 +282,7 @@ const (
 	readChunkTrailer
 	readChunk
 	verifyChunk
+	readChunkEnd
 )
 
 func (cs chunkState) String() string {
@@ -234,6 +296,8 @@ func (cs chunkState) String() string {
 		stateString = "readChunk"
 	case verifyChunk:
 		stateString = "verifyChunk"
+	case readChunkEnd:
+		stateString = "readChunkEnd"
 	}
 	return stateString
 }
@@ -256,9 +320,17 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 			}
 			cr.state = readChunk
 		case readChunkTrailer:
-			cr.err = readCRLF(cr.reader)
-			if cr.err != nil {
-				return 0, errMalformedEncoding
+			if cr.lastChunk && cr.trailer != "" {
+				// The last chunk is followed by the trailing headers.
+				cr.err = cr.readTrailer()
+				if cr.err != nil {
+					return 0, cr.err
+				}
+			} else {
+				cr.err = readCRLF(cr.reader)
+				if cr.err != nil {
+					return 0, errMalformedEncoding
+				}
 			}
 			cr.state = verifyChunk
 		case readChunk:
@@ -284,6 +356,9 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 
 			// Calculate sha256.
 			cr.chunkSHA256Writer.Write(rbuf[:n0])
+			if cr.checksumWriter != nil {
+				cr.checksumWriter.Write(rbuf[:n0])
+			}
 			// Update the bytes read into request buffer so far.
 			n += n0
 			buf = buf[n0:]
@@ -296,25 +371,89 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 				continue
 			}
 		case verifyChunk:
-			// Calculate the hashed chunk.
-			hashedChunk := hex.EncodeToString(cr.chunkSHA256Writer.Sum(nil))
-			// Calculate the chunk signature.
-			newSignature := getChunkSignature(cr.seedSignature, cr.seedDate, hashedChunk)
-			if cr.chunkSignature != newSignature {
-				// Chunk signature doesn't match we return signature does not match.
-				cr.err = errSignatureMismatch
-				return 0, cr.err
+			if !cr.unsigned {
+				// Calculate the hashed chunk.
+				hashedChunk := hex.EncodeToString(cr.chunkSHA256Writer.Sum(nil))
+				// Calculate the chunk signature.
+				newSignature := getChunkSignature(cr.cred, cr.seedSignature, cr.seedDate, hashedChunk)
+				if cr.chunkSignature != newSignature {
+					// Chunk signature doesn't match we return signature does not match.
+					cr.err = errSignatureMismatch
+					return 0, cr.err
+				}
+				// Newly calculated signature becomes the seed for the next chunk
+				// this follows the chaining.
+				cr.seedSignature = newSignature
 			}
-			// Newly calculated signature becomes the seed for the next chunk
-			// this follows the chaining.
-			cr.seedSignature = newSignature
 			cr.chunkSHA256Writer.Reset()
 			cr.state = readChunkHeader
 			if cr.lastChunk {
+				// Verify the trailing checksum before the end of
+				// the payload is returned.
+				if cr.trailer != "" {
+					cr.err = cr.verifyTrailer()
+					if cr.err != nil {
+						return 0, cr.err
+					}
+				}
+				cr.state = readChunkEnd
 				return n, nil
 			}
+		case readChunkEnd:
+			return 0, io.EOF
+		}
+	}
+}
+
+// readTrailer - reads the trailing headers sent after the last chunk,
+// "name:value" lines ended by an empty line. Only the declared
+// checksum and the trailer signature are accepted.
+func (cr *s3ChunkedReader) readTrailer() error {
+	for {
+		line, err := cr.reader.ReadSlice('\n')
+		if len(line) >= maxLineLength {
+			return errMalformedTrailer
 		}
+		line = trimTrailingWhitespace(line)
+		if len(line) == 0 && (err == nil || err == io.EOF) {
+			break
+		}
+		if err != nil {
+			return errMalformedTrailer
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon == -1 {
+			return errMalformedTrailer
+		}
+		value := strings.TrimSpace(string(line[colon+1:]))
+		switch strings.ToLower(string(line[:colon])) {
+		case cr.trailer:
+			cr.trailerValue = value
+		case "x-amz-trailer-signature":
+			cr.trailerSignature = value
+		default:
+			return errMalformedTrailer
+		}
+	}
+	if cr.trailerValue == "" || (!cr.unsigned && cr.trailerSignature == "") {
+		return errMalformedTrailer
 	}
+	return nil
+}
+
+// verifyTrailer - verifies the signature of the trailing headers,
+// chained to the signature of the last chunk, and the checksum.
+func (cr *s3ChunkedReader) verifyTrailer() error {
+	if !cr.unsigned {
+		hashedTrailer := getSHA256Hash([]byte(cr.trailer + ":" + cr.trailerValue + "\n"))
+		if getTrailerSignature(cr.cred, cr.seedSignature, cr.seedDate, hashedTrailer) != cr.trailerSignature {
+			return errSignatureMismatch
+		}
+	}
+	if base64.StdEncoding.EncodeToString(cr.checksumWriter.Sum(nil)) != cr.trailerValue {
+		return errChecksumMismatch
+	}
+	return nil
 }
 
 // readCRLF - check if reader only has '\r\n' CRLF character.
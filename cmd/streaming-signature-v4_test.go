@@ -19,10 +19,18 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/minio/sha256-simd"
 )
 
 // Test read chunk line.
@@ -194,3 +202,134 @@ func TestParseHexUint(t *testing.T) {
 		}
 	}
 }
+
+// Returns a streaming upload of data in chunks of chunkSize followed
+// by the given trailing checksum, signed unless the payload is unsigned.
+func newTestStreamingTrailerRequest(payload, trailer, checksum string, data []byte, chunkSize int, cred credential) (*http.Request, error) {
+	req, err := http.NewRequest("PUT", "http://127.0.0.1:9000/bucket/object", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-amz-content-sha256", payload)
+	req.Header.Set("content-encoding", "aws-chunked")
+	req.Header.Set("x-amz-decoded-content-length", strconv.Itoa(len(data)))
+	if trailer != "" {
+		req.Header.Set("x-amz-trailer", trailer)
+	}
+	currTime := time.Now().UTC()
+	signature, err := signStreamingRequest(req, cred.AccessKeyID, cred.SecretAccessKey, currTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream bytes.Buffer
+	for {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		chunk := data[:n]
+		data = data[n:]
+		if payload == streamingUnsignedTrailer {
+			fmt.Fprintf(&stream, "%x\r\n", n)
+		} else {
+			signature = getChunkSignature(cred, signature, currTime, getSHA256Hash(chunk))
+			fmt.Fprintf(&stream, "%x;chunk-signature=%s\r\n", n, signature)
+		}
+		if n == 0 {
+			break
+		}
+		stream.Write(chunk)
+		stream.WriteString("\r\n")
+	}
+	if trailer != "" {
+		fmt.Fprintf(&stream, "%s:%s\r\n", trailer, checksum)
+		if payload != streamingUnsignedTrailer {
+			hashedTrailer := getSHA256Hash([]byte(trailer + ":" + checksum + "\n"))
+			fmt.Fprintf(&stream, "x-amz-trailer-signature:%s\r\n", getTrailerSignature(cred, signature, currTime, hashedTrailer))
+		}
+	}
+	stream.WriteString("\r\n")
+	req.Body = ioutil.NopCloser(&stream)
+	return req, nil
+}
+
+// Tests streaming uploads with trailing checksums.
+func TestS3ChunkedReaderTrailer(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+	cred := serverConfig.GetCredential()
+
+	data := bytes.Repeat([]byte("trailing checksum\n"), 1000)
+	crc := crc32.NewIEEE()
+	crc.Write(data)
+	crc32Sum := base64.StdEncoding.EncodeToString(crc.Sum(nil))
+	sha256Bytes := sha256.Sum256(data)
+	sha256Sum := base64.StdEncoding.EncodeToString(sha256Bytes[:])
+
+	testCases := []struct {
+		payload, trailer, checksum string
+		expectedErrCode            APIErrorCode
+		expectedErr                error
+	}{
+		// Test - 1 - signed chunks with a crc32 trailer.
+		{streamingContentSHA256Trailer, "x-amz-checksum-crc32", crc32Sum, ErrNone, nil},
+		// Test - 2 - unsigned chunks with a sha256 trailer.
+		{streamingUnsignedTrailer, "x-amz-checksum-sha256", sha256Sum, ErrNone, nil},
+		// Test - 3 - signed chunks without a trailer.
+		{streamingContentSHA256, "", "", ErrNone, nil},
+		// Test - 4 - checksum mismatch.
+		{streamingContentSHA256Trailer, "x-amz-checksum-crc32", sha256Sum, ErrNone, errChecksumMismatch},
+		// Test - 5 - checksum mismatch of an unsigned payload.
+		{streamingUnsignedTrailer, "x-amz-checksum-crc32", sha256Sum, ErrNone, errChecksumMismatch},
+		// Test - 6 - unsupported checksum.
+		{streamingContentSHA256Trailer, "x-amz-checksum-md5", crc32Sum, ErrInvalidTrailer, nil},
+		// Test - 7 - trailer without a trailer payload.
+		{streamingContentSHA256, "x-amz-checksum-crc32", crc32Sum, ErrInvalidTrailer, nil},
+		// Test - 8 - trailer payload without a trailer.
+		{streamingUnsignedTrailer, "", "", ErrInvalidTrailer, nil},
+	}
+	for i, testCase := range testCases {
+		req, err := newTestStreamingTrailerRequest(testCase.payload, testCase.trailer, testCase.checksum, data, 4096, cred)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create request: %v", i+1, err)
+		}
+		reader, errCode := newSignV4ChunkedReader(req)
+		if errCode != testCase.expectedErrCode {
+			t.Fatalf("Test %d: Expected error code %d, got %d", i+1, testCase.expectedErrCode, errCode)
+		}
+		if errCode != ErrNone {
+			continue
+		}
+		payload, err := ioutil.ReadAll(reader)
+		if err != testCase.expectedErr {
+			t.Fatalf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if err == nil && !bytes.Equal(payload, data) {
+			t.Errorf("Test %d: Unexpected payload of %d bytes", i+1, len(payload))
+		}
+	}
+
+	// A trailer with an invalid signature is rejected.
+	req, err := newTestStreamingTrailerRequest(streamingContentSHA256Trailer, "x-amz-checksum-crc32", crc32Sum, data, 4096, cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatureStart := bytes.LastIndex(stream, []byte("x-amz-trailer-signature:")) + len("x-amz-trailer-signature:")
+	stream[signatureStart] ^= 1
+	req.Body = ioutil.NopCloser(bytes.NewReader(stream))
+	reader, errCode := newSignV4ChunkedReader(req)
+	if errCode != ErrNone {
+		t.Fatalf("Unexpected error code %d", errCode)
+	}
+	if _, err = ioutil.ReadAll(reader); err != errSignatureMismatch {
+		t.Errorf("Expected %v for a tampered trailer signature, got %v", errSignatureMismatch, err)
+	}
+}
@@ -1969,6 +1969,12 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 		case "CopyObject":
 			// Register Copy Object  handler.
 			bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(api.CopyObjectHandler)
+		case "ComposeObject":
+			// Register ComposeObject handler.
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.ComposeObjectHandler).Queries("compose", "")
+		case "CopyObjectPart":
+			// Register CopyObjectPart handler.
+			bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(api.CopyObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
 		case "PutBucketPolicy":
 			// Register PutBucket Policy handler.
 			bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
@@ -1990,6 +1996,15 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 		case "NewMultipart":
 			// Register New Multipart upload handler.
 			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
+		case "GetObjectTorrent":
+			// Register GetObjectTorrent handler.
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTorrentHandler).Queries("torrent", "")
+		case "GetObjectArchive":
+			// Register GetObjectArchive handler.
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectArchiveHandler).Queries("archive", "{archive:.*}")
+		case "SelectObjectContent":
+			// Register SelectObjectContent handler.
+			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.SelectObjectContentHandler).Queries("select", "", "select-type", "2")
 		case "PutObjectPart":
 			// Register PutObjectPart handler.
 			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
@@ -2005,6 +2020,41 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 		case "AbortMultipart":
 			// Register AbortMultipart Handler.
 			bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+		case "BucketTransform":
+			// Register bucket transform handlers along with GetObject.
+			bucket.Methods("GET").HandlerFunc(api.GetBucketTransformHandler).Queries("transform", "")
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketTransformHandler).Queries("transform", "")
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketTransformHandler).Queries("transform", "")
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+		case "BucketInventory":
+			// Register bucket inventory handlers.
+			bucket.Methods("GET").HandlerFunc(api.GetBucketInventoryHandler).Queries("inventory", "")
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketInventoryHandler).Queries("inventory", "")
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketInventoryHandler).Queries("inventory", "")
+		case "ObjectTagging":
+			// Register object tagging handlers along with the object
+			// handlers reporting and removing tags.
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTaggingHandler).Queries("tagging", "")
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectTaggingHandler).Queries("tagging", "")
+			bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectTaggingHandler).Queries("tagging", "")
+			bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
+			bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectHandler)
+		case "BucketLifecycle":
+			// Register bucket lifecycle handlers.
+			bucket.Methods("GET").HandlerFunc(api.GetBucketLifecycleHandler).Queries("lifecycle", "")
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketLifecycleHandler).Queries("lifecycle", "")
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketLifecycleHandler).Queries("lifecycle", "")
+		case "BucketVersioning":
+			// Register bucket versioning handlers along with the object
+			// handlers reading and writing versions.
+			bucket.Methods("GET").HandlerFunc(api.GetBucketVersioningHandler).Queries("versioning", "")
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketVersioningHandler).Queries("versioning", "")
+			bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
+			bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectHandler)
 		case "GetBucketNotification":
 			// Register GetBucketNotification Handler.
 			bucket.Methods("GET").HandlerFunc(api.GetBucketNotificationHandler).Queries("notification", "")
@@ -47,3 +47,109 @@ var errServerVersionMismatch = errors.New("Server versions do not match")
 
 // errServerTimeMismatch - server times are too far apart.
 var errServerTimeMismatch = errors.New("Server times are too far apart")
+
+// errInvalidArchive - object is not a valid zip or tar archive.
+var errInvalidArchive = errors.New("Object is not a valid zip or tar archive")
+
+// errInvalidCompressedObject - object content is not valid gzip data.
+var errInvalidCompressedObject = errors.New("Object content is not valid gzip compressed data")
+
+// errNoSuchTransform - bucket has no transform configuration.
+var errNoSuchTransform = errors.New("The bucket has no transform configuration")
+
+// errTransformFailed - transform endpoint failed to transform an object.
+var errTransformFailed = errors.New("Transform endpoint failed")
+
+// errConfigNotFound - bucket configuration file does not exist.
+var errConfigNotFound = errors.New("Bucket configuration not found")
+
+// errNoSuchInventory - bucket has no inventory configuration.
+var errNoSuchInventory = errors.New("The bucket has no inventory configuration")
+
+// errInvalidAnonymousPolicy - anonymous policy is not one of none,
+// download, upload or public.
+var errInvalidAnonymousPolicy = errors.New("Anonymous policy must be one of none, download, upload or public")
+
+// errProfilerBusy - a profile is already being captured.
+var errProfilerBusy = errors.New("A profile is already being captured")
+
+// errFsckInProgress - a consistency check is already running.
+var errFsckInProgress = errors.New("A consistency check is already in progress")
+
+// errInvalidBatchJob - batch job description is not valid.
+var errInvalidBatchJob = errors.New("Invalid batch job description")
+
+// errNoSuchBatchJob - batch job does not exist.
+var errNoSuchBatchJob = errors.New("The batch job does not exist")
+
+// errEventJournalDisabled - bucket events are not recorded.
+var errEventJournalDisabled = errors.New("The event journal is disabled")
+
+// errInvalidEventQuery - time range or selection of recorded events is
+// not valid.
+var errInvalidEventQuery = errors.New("Invalid selection of recorded events")
+
+// errInvalidEventTarget - replay target is not a configured external
+// target.
+var errInvalidEventTarget = errors.New("The event target is not configured")
+
+// errInvalidTag - object tags exceed the allowed number or lengths,
+// or repeat a key.
+var errInvalidTag = errors.New("The object tags are not valid")
+
+// errNoSuchVersion - the requested version of an object does not
+// exist.
+var errNoSuchVersion = errors.New("The specified version does not exist")
+
+// errNoSuchLifecycle - bucket has no lifecycle configuration.
+var errNoSuchLifecycle = errors.New("The bucket has no lifecycle configuration")
+
+// errInsecureSSECustomerRequest - customer provided keys were sent
+// over a connection without TLS.
+var errInsecureSSECustomerRequest = errors.New("Customer provided keys must be sent over a secure connection")
+
+// errInvalidEncryptionAlgorithm - server side encryption with an
+// algorithm other than AES256 was requested.
+var errInvalidEncryptionAlgorithm = errors.New("The encryption algorithm is not supported")
+
+// errInvalidSSECustomerKey - customer provided key is not a base64
+// encoded 256 bit key.
+var errInvalidSSECustomerKey = errors.New("The customer provided key is not valid")
+
+// errMissingSSECustomerKey - the encryption algorithm, the key or its
+// MD5 was not sent.
+var errMissingSSECustomerKey = errors.New("The customer provided key is missing")
+
+// errSSECustomerKeyMD5Mismatch - the MD5 of the customer provided key
+// does not match the one sent.
+var errSSECustomerKeyMD5Mismatch = errors.New("The MD5 of the customer provided key does not match")
+
+// errEncryptedObject - the object is encrypted and no key to read it
+// was sent.
+var errEncryptedObject = errors.New("The object is encrypted")
+
+// errSSEKeyMismatch - the customer provided key is not the one the
+// object was encrypted with.
+var errSSEKeyMismatch = errors.New("The customer provided key does not match the object")
+
+// errInvalidEncryptionParameters - encryption keys were sent to read
+// an object that is not encrypted.
+var errInvalidEncryptionParameters = errors.New("The object is not encrypted")
+
+// errKMSInvalidSealedKey - the sealed data key of an object could not
+// be unsealed by the KMS.
+var errKMSInvalidSealedKey = errors.New("The sealed key of the object is invalid")
+
+// errKMSNotConfigured - the object is encrypted at rest and no KMS is
+// configured to unseal its data key.
+var errKMSNotConfigured = errors.New("The object is encrypted at rest and no KMS is configured")
+
+// errObjectTampered - the encrypted data of an object failed
+// authentication.
+var errObjectTampered = errors.New("The encrypted data of the object has been modified")
+
+// errJBODDistributed - the disks of a JBOD backend must be local.
+var errJBODDistributed = errors.New("JBOD disks must be local to the server")
+
+// errServerReadOnly - the server was started read-only.
+var errServerReadOnly = errors.New("The server is read-only")
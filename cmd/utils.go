@@ -116,6 +116,8 @@ const (
 	maxObjectSize = 5 * humanize.GiByte
 	// minimum Part size for multipart upload is 5MiB
 	minPartSize = 5 * humanize.MiByte
+	// maximum Part size for multipart upload is 5GiB
+	maxPartSize = 5 * humanize.GiByte
 	// maximum Part ID for multipart upload is 10000 (Acceptable values range from 1 to 10000 inclusive)
 	maxPartID = 10000
 )
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -39,23 +40,30 @@ import (
 	"github.com/minio/miniobrowser"
 )
 
+// webTokenKey - returns the key a browser token is signed with, the
+// secret key of the current credentials as long as they are valid.
+// Tokens of the credentials replaced by a rotation are refused, or the
+// replaced keys could fetch the new ones with GetAuth.
+func webTokenKey(token *jwtgo.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	}
+	claims, ok := token.Claims.(jwtgo.MapClaims)
+	if !ok {
+		return nil, errAuthentication
+	}
+	accessKey, _ := claims["sub"].(string)
+	cred := serverConfig.GetCredential()
+	if accessKey != cred.AccessKeyID || cred.isExpired(time.Now().UTC()) {
+		return nil, errAuthentication
+	}
+	return []byte(cred.SecretAccessKey), nil
+}
+
 // isJWTReqAuthenticated validates if any incoming request to be a
 // valid JWT authenticated request.
 func isJWTReqAuthenticated(req *http.Request) bool {
-	jwt, err := newJWT(defaultJWTExpiry, serverConfig.GetCredential())
-	if err != nil {
-		errorIf(err, "unable to initialize a new JWT")
-		return false
-	}
-
-	var reqCallback jwtgo.Keyfunc
-	reqCallback = func(token *jwtgo.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwt.SecretAccessKey), nil
-	}
-	token, err := jwtreq.ParseFromRequest(req, jwtreq.AuthorizationHeaderExtractor, reqCallback)
+	token, err := jwtreq.ParseFromRequest(req, jwtreq.AuthorizationHeaderExtractor, webTokenKey)
 	if err != nil {
 		errorIf(err, "token parsing failed")
 		return false
@@ -148,6 +156,9 @@ func (web *webAPIHandlers) MakeBucket(r *http.Request, args *MakeBucketArgs, rep
 	if !isJWTReqAuthenticated(r) {
 		return toJSONError(errAuthentication)
 	}
+	if globalIsReadOnly {
+		return toJSONError(errServerReadOnly)
+	}
 	if err := objectAPI.MakeBucket(args.BucketName); err != nil {
 		return toJSONError(err, args.BucketName)
 	}
@@ -228,29 +239,27 @@ func (web *webAPIHandlers) ListObjects(r *http.Request, args *ListObjectsArgs, r
 	if !isJWTReqAuthenticated(r) {
 		return toJSONError(errAuthentication)
 	}
-	marker := ""
+	lister := newObjectLister(objectAPI, args.BucketName, args.Prefix, "", "/")
 	for {
-		lo, err := objectAPI.ListObjects(args.BucketName, args.Prefix, marker, "/", 1000)
+		obj, err := lister.Next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			return &json2.Error{Message: err.Error()}
 		}
-		marker = lo.NextMarker
-		for _, obj := range lo.Objects {
+		if obj.IsDir {
 			reply.Objects = append(reply.Objects, WebObjectInfo{
-				Key:          obj.Name,
-				LastModified: obj.ModTime,
-				Size:         obj.Size,
-				ContentType:  obj.ContentType,
+				Key: obj.Name,
 			})
+			continue
 		}
-		for _, prefix := range lo.Prefixes {
-			reply.Objects = append(reply.Objects, WebObjectInfo{
-				Key: prefix,
-			})
-		}
-		if !lo.IsTruncated {
-			break
-		}
+		reply.Objects = append(reply.Objects, WebObjectInfo{
+			Key:          obj.Name,
+			LastModified: obj.ModTime,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+		})
 	}
 	reply.UIVersion = miniobrowser.UIVersion
 	return nil
@@ -272,7 +281,10 @@ func (web *webAPIHandlers) RemoveObject(r *http.Request, args *RemoveObjectArgs,
 	if !isJWTReqAuthenticated(r) {
 		return toJSONError(errAuthentication)
 	}
-	if err := objectAPI.DeleteObject(args.BucketName, args.ObjectName); err != nil {
+	if globalIsReadOnly {
+		return toJSONError(errServerReadOnly)
+	}
+	if err := deleteObjectVersioned(objectAPI, args.BucketName, args.ObjectName); err != nil {
 		if isErrObjectNotFound(err) {
 			// Ignore object not found error.
 			reply.UIVersion = miniobrowser.UIVersion
@@ -309,14 +321,31 @@ type LoginRep struct {
 	UIVersion string `json:"uiVersion"`
 }
 
-// Login - user login handler.
+// Login - user login handler. Only the current credentials login, the
+// credentials replaced by a rotation are for the S3 API only, and not
+// once they have expired. Failed logins are throttled like the S3 API.
 func (web *webAPIHandlers) Login(r *http.Request, args *LoginArgs, reply *LoginRep) error {
-	jwt, err := newJWT(defaultJWTExpiry, serverConfig.GetCredential())
+	cred := serverConfig.GetCredential()
+	jwt, err := newJWT(defaultJWTExpiry, cred)
 	if err != nil {
 		return toJSONError(err)
 	}
 
-	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
+	ipKey, accessKeyKey := authThrottleKeysOf(r.RemoteAddr, strings.TrimSpace(args.Username))
+	s3Error := throttleAuthKeys(ipKey, accessKeyKey, func() APIErrorCode {
+		if err = jwt.Authenticate(args.Username, args.Password); err != nil {
+			return ErrSignatureDoesNotMatch
+		}
+		if cred.isExpired(time.Now().UTC()) {
+			err = errAccessKeyExpired
+			return ErrAccessKeyExpired
+		}
+		return ErrNone
+	})
+	if s3Error == ErrAuthThrottled {
+		return toJSONError(errAuthThrottled)
+	}
+	if err != nil {
 		return toJSONError(err)
 	}
 
@@ -365,6 +394,9 @@ func (web *webAPIHandlers) SetAuth(r *http.Request, args *SetAuthArgs, reply *Se
 	if !isJWTReqAuthenticated(r) {
 		return toJSONError(errAuthentication)
 	}
+	if globalIsReadOnly {
+		return toJSONError(errServerReadOnly)
+	}
 
 	// Initialize jwt with the new access keys, fail if not possible.
 	jwt, err := newJWT(defaultJWTExpiry, credential{
@@ -396,12 +428,14 @@ func (web *webAPIHandlers) SetAuth(r *http.Request, args *SetAuthArgs, reply *Se
 		return err
 	}
 
-	cred := credential{args.AccessKey, args.SecretKey}
-	// Notify all other Minio peers to update credentials
-	errsMap := updateCredsOnPeers(cred)
+	cred := credential{AccessKeyID: args.AccessKey, SecretAccessKey: args.SecretKey}
+	// Notify all other Minio peers to update credentials, previous
+	// credentials are no longer accepted.
+	errsMap := updateCredsOnPeers(cred, nil)
 
 	// Update local credentials
 	serverConfig.SetCredential(cred)
+	serverConfig.SetPreviousCredential(nil)
 	if err = serverConfig.Save(); err != nil {
 		errsMap[globalMinioAddr] = err
 	}
@@ -478,6 +512,11 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 	// Extract incoming metadata if any.
 	metadata := extractMetadataFromHeader(r.Header)
 
+	if _, err := prepareObjectVersion(objectAPI, bucket, object, metadata); err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+
 	sha256sum := ""
 	if _, err := objectAPI.PutObject(bucket, object, -1, r.Body, metadata, sha256sum); err != nil {
 		writeWebErrorResponse(w, err)
@@ -515,18 +554,7 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 	object := vars["object"]
 	tokenStr := r.URL.Query().Get("token")
 
-	jwt, err := newJWT(defaultJWTExpiry, serverConfig.GetCredential()) // Expiry set to 24Hrs.
-	if err != nil {
-		errorIf(err, "error in getting new JWT")
-		return
-	}
-
-	token, e := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwt.SecretAccessKey), nil
-	})
+	token, e := jwtgo.Parse(tokenStr, webTokenKey)
 	if e != nil || !token.Valid {
 		writeWebErrorResponse(w, errAuthentication)
 		return
@@ -539,6 +567,11 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 		writeWebErrorResponse(w, err)
 		return
 	}
+	// Encrypted objects can not be read without their key.
+	if isEncryptedObject(objInfo) {
+		writeWebErrorResponse(w, errEncryptedObject)
+		return
+	}
 	offset := int64(0)
 	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
 	if err != nil {
@@ -583,6 +616,42 @@ func readBucketAccessPolicy(objAPI ObjectLayer, bucketName string) (policy.Bucke
 
 }
 
+// setBucketAccessPolicy - sets the canned policy of prefix in bucket,
+// the bucket policy is removed once no statements remain.
+func setBucketAccessPolicy(objAPI ObjectLayer, bucketName, prefix string, bucketP policy.BucketPolicy) error {
+	policyInfo, err := readBucketAccessPolicy(objAPI, bucketName)
+	if err != nil {
+		return err
+	}
+	policyInfo.Statements = policy.SetPolicy(policyInfo.Statements, bucketP, bucketName, prefix)
+	if len(policyInfo.Statements) == 0 {
+		return persistAndNotifyBucketPolicyChange(bucketName, policyChange{true, nil}, objAPI)
+	}
+	data, err := json.Marshal(policyInfo)
+	if err != nil {
+		return err
+	}
+
+	// Parse bucket policy.
+	var policy = &bucketPolicy{}
+	err = parseBucketPolicy(bytes.NewReader(data), policy)
+	if err != nil {
+		errorIf(err, "Unable to parse bucket policy.")
+		return err
+	}
+
+	// Parse check bucket policy.
+	if s3Error := checkBucketPolicyResources(bucketName, policy); s3Error != ErrNone {
+		apiErr := getAPIError(s3Error)
+		if apiErr.Code == "XMinioPolicyNesting" {
+			return PolicyNesting{}
+		}
+		return errors.New(apiErr.Description)
+	}
+
+	return persistAndNotifyBucketPolicyChange(bucketName, policyChange{false, policy}, objAPI)
+}
+
 // GetBucketPolicy - get bucket policy.
 func (web *webAPIHandlers) GetBucketPolicy(r *http.Request, args *GetBucketPolicyArgs, reply *GetBucketPolicyRep) error {
 	objectAPI := web.ObjectAPI()
@@ -665,6 +734,9 @@ func (web *webAPIHandlers) SetBucketPolicy(r *http.Request, args *SetBucketPolic
 	if !isJWTReqAuthenticated(r) {
 		return toJSONError(errAuthentication)
 	}
+	if globalIsReadOnly {
+		return toJSONError(errServerReadOnly)
+	}
 
 	bucketP := policy.BucketPolicy(args.Policy)
 	if !bucketP.IsValidBucketPolicy() {
@@ -673,47 +745,7 @@ func (web *webAPIHandlers) SetBucketPolicy(r *http.Request, args *SetBucketPolic
 		}
 	}
 
-	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
-	if err != nil {
-		return toJSONError(err, args.BucketName)
-	}
-	policyInfo.Statements = policy.SetPolicy(policyInfo.Statements, bucketP, args.BucketName, args.Prefix)
-	if len(policyInfo.Statements) == 0 {
-		err = persistAndNotifyBucketPolicyChange(args.BucketName, policyChange{true, nil}, objectAPI)
-		if err != nil {
-			return toJSONError(err, args.BucketName)
-		}
-		reply.UIVersion = miniobrowser.UIVersion
-		return nil
-	}
-	data, err := json.Marshal(policyInfo)
-	if err != nil {
-		return toJSONError(err)
-	}
-
-	// Parse bucket policy.
-	var policy = &bucketPolicy{}
-	err = parseBucketPolicy(bytes.NewReader(data), policy)
-	if err != nil {
-		errorIf(err, "Unable to parse bucket policy.")
-		return toJSONError(err, args.BucketName)
-	}
-
-	// Parse check bucket policy.
-	if s3Error := checkBucketPolicyResources(args.BucketName, policy); s3Error != ErrNone {
-		apiErr := getAPIError(s3Error)
-		var err error
-		if apiErr.Code == "XMinioPolicyNesting" {
-			err = PolicyNesting{}
-		} else {
-			err = errors.New(apiErr.Description)
-		}
-		return toJSONError(err, args.BucketName)
-	}
-
-	// TODO: update policy statements according to bucket name,
-	// prefix and policy arguments.
-	if err := persistAndNotifyBucketPolicyChange(args.BucketName, policyChange{false, policy}, objectAPI); err != nil {
+	if err := setBucketAccessPolicy(objectAPI, args.BucketName, args.Prefix, bucketP); err != nil {
 		return toJSONError(err, args.BucketName)
 	}
 	reply.UIVersion = miniobrowser.UIVersion
@@ -796,6 +828,69 @@ func presignedGet(host, bucket, object string, expiry int64) string {
 	return host + path + "?" + query + "&" + "X-Amz-Signature=" + signature
 }
 
+// ConsoleMetricsRep - request statistics for the web console.
+type ConsoleMetricsRep struct {
+	Uptime        time.Duration     `json:"uptime"`
+	Requests      uint64            `json:"requests"`
+	Errors        uint64            `json:"errors"`
+	BytesReceived uint64            `json:"bytesReceived"`
+	BytesSent     uint64            `json:"bytesSent"`
+	Throughput    Throughput        `json:"throughput"`
+	RecentErrors  []HTTPError       `json:"recentErrors"`
+	AuthThrottle  AuthThrottleStats `json:"authThrottle"`
+	UIVersion     string            `json:"uiVersion"`
+}
+
+// ConsoleMetrics - live request statistics of this server.
+func (web *webAPIHandlers) ConsoleMetrics(r *http.Request, args *WebGenericArgs, reply *ConsoleMetricsRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return toJSONError(errAuthentication)
+	}
+	counters, throughput, recentErrors := globalHTTPStats.snapshot()
+	reply.Uptime = time.Since(globalBootTime)
+	reply.Requests = counters.requests
+	reply.Errors = counters.errors
+	reply.BytesReceived = counters.bytesReceived
+	reply.BytesSent = counters.bytesSent
+	reply.Throughput = throughput
+	reply.RecentErrors = recentErrors
+	if globalAuthThrottle != nil {
+		reply.AuthThrottle = globalAuthThrottle.stats()
+	}
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// BucketUsageArgs - bucket usage args, all buckets if BucketName is empty.
+type BucketUsageArgs struct {
+	BucketName string `json:"bucketName"`
+}
+
+// BucketUsageRep - usage history of buckets, oldest sample first.
+type BucketUsageRep struct {
+	Buckets   map[string][]BucketUsageSample `json:"buckets"`
+	UIVersion string                         `json:"uiVersion"`
+}
+
+// BucketUsage - usage of buckets over time.
+func (web *webAPIHandlers) BucketUsage(r *http.Request, args *BucketUsageArgs, reply *BucketUsageRep) error {
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return toJSONError(errServerNotInitialized)
+	}
+	if !isJWTReqAuthenticated(r) {
+		return toJSONError(errAuthentication)
+	}
+	if args.BucketName != "" {
+		if _, err := objectAPI.GetBucketInfo(args.BucketName); err != nil {
+			return toJSONError(err, args.BucketName)
+		}
+	}
+	reply.Buckets = globalBucketUsage.get(args.BucketName)
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
 // toJSONError converts regular errors into more user friendly
 // and consumable error message for the browser UI.
 func toJSONError(err error, params ...string) (jerr *json2.Error) {
@@ -848,6 +943,15 @@ func toWebAPIError(err error) APIError {
 			Description:    err.Error(),
 		}
 	}
+	if err == errServerReadOnly {
+		return getAPIError(ErrServerReadOnly)
+	}
+	if err == errAccessKeyExpired {
+		return getAPIError(ErrAccessKeyExpired)
+	}
+	if err == errAuthThrottled {
+		return getAPIError(ErrAuthThrottled)
+	}
 
 	// Convert error type to api error code.
 	var apiErrCode APIErrorCode
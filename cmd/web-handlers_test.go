@@ -22,10 +22,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/minio/minio-go/pkg/policy"
@@ -165,6 +167,118 @@ func testLoginWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler)
 	}
 }
 
+// Wrapper for calling the Login Web Handler with expiring credentials.
+func TestWebHandlerLoginExpiry(t *testing.T) {
+	ExecObjectLayerTest(t, testLoginExpiryWebHandler)
+}
+
+// testLoginExpiryWebHandler - Tests that expired credentials can not
+// login, and that credentials replaced by a rotation can neither login
+// nor use the tokens they were issued, GetAuth would hand them the new
+// keys otherwise.
+func testLoginExpiryWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	apiRouter := initTestWebRPCEndPoint(obj)
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	past, future := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	previous := credential{AccessKeyID: "previous-access", SecretAccessKey: "previous-secret", Expiration: &future}
+	jwt, err := newJWT(defaultJWTExpiry, previous)
+	if err != nil {
+		t.Fatalf("%s: Unable to initialize JWT: %v", instanceType, err)
+	}
+	previousToken, err := jwt.GenerateToken(previous.AccessKeyID)
+	if err != nil {
+		t.Fatalf("%s: Unable to generate token: %v", instanceType, err)
+	}
+	serverConfig.SetPreviousCredential(&previous)
+
+	if _, err = getWebRPCToken(apiRouter, previous.AccessKeyID, previous.SecretAccessKey); err == nil {
+		t.Errorf("%s: Expected the previous credentials to be rejected", instanceType)
+	}
+	getAuth := func(authorization string) (*GetAuthReply, error) {
+		req, err := newTestWebRPCRequest("Web.GetAuth", authorization, WebGenericArgs{})
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		reply := &GetAuthReply{}
+		return reply, getTestWebRPCResponse(rec, reply)
+	}
+	if reply, err := getAuth(previousToken); err == nil || reply.SecretKey != "" {
+		t.Errorf("%s: Expected the token of the previous credentials to be rejected, got %v", instanceType, reply)
+	}
+
+	cred := serverConfig.GetCredential()
+	authorization, err := getWebRPCToken(apiRouter, cred.AccessKeyID, cred.SecretAccessKey)
+	if err != nil {
+		t.Fatalf("%s: Expected the current credentials to login, got %v", instanceType, err)
+	}
+	if _, err = getAuth(authorization); err != nil {
+		t.Errorf("%s: Expected the token of the current credentials to be valid, got %v", instanceType, err)
+	}
+
+	cred.Expiration = &past
+	serverConfig.SetCredential(cred)
+	if _, err = getWebRPCToken(apiRouter, cred.AccessKeyID, cred.SecretAccessKey); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("%s: Expected the expired credentials to be rejected, got %v", instanceType, err)
+	}
+	if _, err = getAuth(authorization); err == nil {
+		t.Errorf("%s: Expected the token of the expired credentials to be rejected", instanceType)
+	}
+}
+
+// Wrapper for calling Login Web Handler throttling tests.
+func TestWebHandlerLoginThrottle(t *testing.T) {
+	ExecObjectLayerTest(t, testLoginThrottleWebHandler)
+}
+
+// testLoginThrottleWebHandler - Tests that failed logins are recorded
+// and that blocked source IPs are rejected, right password or not.
+func testLoginThrottleWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	apiRouter := initTestWebRPCEndPoint(obj)
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	globalAuthThrottle = newAuthThrottle()
+	defer func() { globalAuthThrottle = nil }()
+
+	cred := serverConfig.GetCredential()
+	login := func(secretKey string) error {
+		req, err := newTestWebRPCRequest("Web.Login", "", LoginArgs{Username: cred.AccessKeyID, Password: secretKey})
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+		}
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		return getTestWebRPCResponse(rec, &LoginRep{})
+	}
+
+	if err = login("wrong-secret-key"); err == nil {
+		t.Fatalf("%s: Expected a wrong secret key to be rejected", instanceType)
+	}
+	if stats := globalAuthThrottle.stats(); stats.Failures != 1 {
+		t.Errorf("%s: Expected the failed login to be recorded, got %+v", instanceType, stats)
+	}
+	if err = login(cred.SecretAccessKey); err != nil {
+		t.Fatalf("%s: Expected the login to succeed, got %v", instanceType, err)
+	}
+
+	ipKey, _ := authThrottleKeysOf("192.0.2.1:1234", cred.AccessKeyID)
+	globalAuthThrottle.sources[ipKey] = &authFailures{last: time.Now().UTC(), blockedUntil: time.Now().UTC().Add(time.Minute)}
+	if err = login(cred.SecretAccessKey); err == nil || !strings.Contains(err.Error(), "Too many failed") {
+		t.Errorf("%s: Expected the blocked source to be rejected, got %v", instanceType, err)
+	}
+}
+
 // Wrapper for calling StorageInfo Web Handler
 func TestWebHandlerStorageInfo(t *testing.T) {
 	ExecObjectLayerTest(t, testStorageInfoWebHandler)
@@ -212,6 +326,106 @@ func testStorageInfoWebHandler(obj ObjectLayer, instanceType string, t TestErrHa
 	}
 }
 
+// Wrapper for calling ConsoleMetrics and BucketUsage Web Handlers
+func TestWebHandlerConsole(t *testing.T) {
+	ExecObjectLayerTest(t, testConsoleWebHandlers)
+}
+
+// testConsoleWebHandlers - Test ConsoleMetrics and BucketUsage web handlers
+func testConsoleWebHandlers(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root directory after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	data := []byte("hello")
+	if _, err = obj.PutObject(bucketName, "a/b", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	if err = sampleBucketUsage(obj); err != nil {
+		t.Fatalf("%s : Unable to sample bucket usage %s", instanceType, err)
+	}
+
+	// Record a failed request.
+	globalHTTPStats.update(&http.Request{Method: "GET", URL: &url.URL{Path: "/" + bucketName + "/missing"}}, http.StatusNotFound, 0, 100)
+
+	rec := httptest.NewRecorder()
+	metricsReply := &ConsoleMetricsRep{}
+	req, err := newTestWebRPCRequest("Web.ConsoleMetrics", authorization, WebGenericArgs{})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+	}
+	if err = getTestWebRPCResponse(rec, &metricsReply); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	if metricsReply.Requests == 0 || metricsReply.Errors == 0 || metricsReply.BytesSent < 100 {
+		t.Fatalf("%s: Unexpected metrics %+v", instanceType, metricsReply)
+	}
+	lastError := metricsReply.RecentErrors[len(metricsReply.RecentErrors)-1]
+	if lastError.StatusCode != http.StatusNotFound || lastError.Path != "/"+bucketName+"/missing" {
+		t.Fatalf("%s: Unexpected recent error %+v", instanceType, lastError)
+	}
+
+	testCases := []struct {
+		bucketName      string
+		expectedObjects int64
+		expectedSize    int64
+		shouldPass      bool
+	}{
+		{bucketName, 1, int64(len(data)), true},
+		{"", 1, int64(len(data)), true},
+		{"non-existent-bucket", 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		rec = httptest.NewRecorder()
+		usageReply := &BucketUsageRep{}
+		req, err = newTestWebRPCRequest("Web.BucketUsage", authorization, BucketUsageArgs{BucketName: testCase.bucketName})
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+		}
+		err = getTestWebRPCResponse(rec, &usageReply)
+		if testCase.shouldPass != (err == nil) {
+			t.Fatalf("Test %d: %s: Unexpected error %v", i+1, instanceType, err)
+		}
+		if !testCase.shouldPass {
+			continue
+		}
+		samples := usageReply.Buckets[bucketName]
+		if len(samples) == 0 {
+			t.Fatalf("Test %d: %s: Expected usage samples for %s", i+1, instanceType, bucketName)
+		}
+		last := samples[len(samples)-1]
+		if last.Objects != testCase.expectedObjects || last.Size != testCase.expectedSize {
+			t.Errorf("Test %d: %s: Unexpected usage %+v", i+1, instanceType, last)
+		}
+	}
+}
+
 // Wrapper for calling ServerInfo Web Handler
 func TestWebHandlerServerInfo(t *testing.T) {
 	ExecObjectLayerTest(t, testServerInfoWebHandler)
@@ -1201,7 +1415,7 @@ func TestWebCheckAuthorization(t *testing.T) {
 		"ListBuckets", "ListObjects", "RemoveObject",
 		"GenerateAuth", "SetAuth", "GetAuth",
 		"GetBucketPolicy", "SetBucketPolicy", "ListAllBucketPolicies",
-		"PresignedGet",
+		"PresignedGet", "ConsoleMetrics", "BucketUsage",
 	}
 	for _, rpcCall := range webRPCs {
 		args := &GenericArgs{}
@@ -58,7 +58,7 @@ func listDirHealFactory(isLeaf isLeafFunc, disks ...StorageAPI) listDirFunc {
 			// find elements in entries which are not in mergedentries
 			for _, entry := range entries {
 				idx := sort.SearchStrings(mergedEntries, entry)
-				if mergedEntries[idx] == entry {
+				if idx < len(mergedEntries) && mergedEntries[idx] == entry {
 					continue
 				}
 				newEntries = append(newEntries, entry)
@@ -53,10 +53,13 @@ type checkSumInfo struct {
 	Hash      string `json:"hash"`
 }
 
-// Constant indicates current bit-rot algo used when creating objects.
-const (
-	bitRotAlgo = "blake2b"
-)
+// Default bit-rot algo used when creating objects.
+const defaultBitRotAlgo = "blake2b"
+
+// Bit-rot algo used when creating objects, set with MINIO_BITROT_ALGO.
+// The algo is saved with the checksums of each part, objects created
+// with another algo remain readable.
+var globalBitRotAlgo = defaultBitRotAlgo
 
 // erasureInfo - carries erasure coding related information, block
 // distribution and checksums.
@@ -89,7 +92,7 @@ func (e erasureInfo) GetCheckSumInfo(partName string) (ckSum checkSumInfo) {
 			return sum
 		}
 	}
-	return checkSumInfo{Algorithm: bitRotAlgo}
+	return checkSumInfo{Algorithm: globalBitRotAlgo}
 }
 
 // statInfo - carries stat information of the object.
@@ -229,16 +232,16 @@ func (xl xlObjects) readXLMetaParts(bucket, object string) (xlMetaParts []object
 	return nil, err
 }
 
-// readXLMetaStat - return xlMetaV1.Stat and xlMetaV1.Meta from  one of the disks picked at random.
-func (xl xlObjects) readXLMetaStat(bucket, object string) (xlStat statInfo, xlMeta map[string]string, err error) {
+// readXLMetaStat - return xlMetaV1.Stat, xlMetaV1.Meta and xlMetaV1.Parts from one of the disks picked at random.
+func (xl xlObjects) readXLMetaStat(bucket, object string) (xlStat statInfo, xlMeta map[string]string, xlParts []objectPartInfo, err error) {
 	for _, disk := range xl.getLoadBalancedDisks() {
 		if disk == nil {
 			continue
 		}
-		// parses only xlMetaV1.Meta and xlMeta.Stat
-		xlStat, xlMeta, err = readXLMetaStat(disk, bucket, object)
+		// parses only xlMetaV1.Meta, xlMetaV1.Stat and xlMetaV1.Parts
+		xlStat, xlMeta, xlParts, err = readXLMetaStat(disk, bucket, object)
 		if err == nil {
-			return xlStat, xlMeta, nil
+			return xlStat, xlMeta, xlParts, nil
 		}
 		// For any reason disk or bucket is not available continue
 		// and read from other disks.
@@ -248,7 +251,7 @@ func (xl xlObjects) readXLMetaStat(bucket, object string) (xlStat statInfo, xlMe
 		break
 	}
 	// Return error here.
-	return statInfo{}, nil, err
+	return statInfo{}, nil, nil, err
 }
 
 // deleteXLMetadata - deletes `xl.json` on a single disk.
@@ -380,7 +380,7 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	}
 
 	// Erasure code data and write across all disks.
-	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaTmpBucket, tmpPartPath, teeReader, xlMeta.Erasure.BlockSize, xl.dataBlocks, xl.parityBlocks, bitRotAlgo, xl.writeQuorum)
+	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaTmpBucket, tmpPartPath, teeReader, xlMeta.Erasure.BlockSize, xl.dataBlocks, xl.parityBlocks, globalBitRotAlgo, xl.writeQuorum)
 	if err != nil {
 		return "", toObjectErr(err, bucket, object)
 	}
@@ -459,7 +459,7 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 		partsMetadata[index].Erasure.AddCheckSumInfo(checkSumInfo{
 			Name:      partSuffix,
 			Hash:      checkSums[index],
-			Algorithm: bitRotAlgo,
+			Algorithm: globalBitRotAlgo,
 		})
 	}
 
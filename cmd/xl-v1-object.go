@@ -192,7 +192,7 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 			// Set checksum algo only once, while it is possible to have
 			// different algos per block because of our `xl.json`.
 			// It is not a requirement, set this only once for all the disks.
-			if ckSumAlgo != "" {
+			if ckSumAlgo == "" {
 				ckSumAlgo = ckSumInfo.Algorithm
 			}
 		}
@@ -236,7 +236,7 @@ func (xl xlObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
 // getObjectInfo - wrapper for reading object metadata and constructs ObjectInfo.
 func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, err error) {
 	// returns xl meta map and stat info.
-	xlStat, xlMetaMap, err := xl.readXLMetaStat(bucket, object)
+	xlStat, xlMetaMap, xlParts, err := xl.readXLMetaStat(bucket, object)
 	if err != nil {
 		// Return error.
 		return ObjectInfo{}, err
@@ -251,6 +251,7 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 		MD5Sum:          xlMetaMap["md5Sum"],
 		ContentType:     xlMetaMap["content-type"],
 		ContentEncoding: xlMetaMap["content-encoding"],
+		Parts:           xlParts,
 	}
 
 	// md5Sum has already been extracted into objInfo.MD5Sum.  We
@@ -435,7 +436,7 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	}
 
 	// Erasure code data and write across all disks.
-	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaTmpBucket, tempErasureObj, teeReader, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, bitRotAlgo, xl.writeQuorum)
+	sizeWritten, checkSums, err := erasureCreateFile(onlineDisks, minioMetaTmpBucket, tempErasureObj, teeReader, xlMeta.Erasure.BlockSize, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, globalBitRotAlgo, xl.writeQuorum)
 	if err != nil {
 		return ObjectInfo{}, toObjectErr(err, minioMetaTmpBucket, tempErasureObj)
 	}
@@ -526,7 +527,7 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 		partsMetadata[index].Erasure.AddCheckSumInfo(checkSumInfo{
 			Name:      "part.1",
 			Hash:      checkSums[index],
-			Algorithm: bitRotAlgo,
+			Algorithm: globalBitRotAlgo,
 		})
 	}
 
@@ -598,6 +599,18 @@ func (xl xlObjects) deleteObject(bucket, object string) error {
 	return nil
 }
 
+// CopyObject - copies an object, the data is read and erasure coded
+// again as copies cannot share the blocks of their source.
+func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string, metadata map[string]string) (ObjectInfo, error) {
+	if err := checkGetObjArgs(srcBucket, srcObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := checkPutObjectArgs(dstBucket, dstObject, xl); err != nil {
+		return ObjectInfo{}, err
+	}
+	return copyObject(xl, srcBucket, srcObject, dstBucket, dstObject, metadata)
+}
+
 // DeleteObject - deletes an object, this call doesn't necessary reply
 // any error as it is not necessary for the handler to reply back a
 // response to the client request.
@@ -255,12 +255,12 @@ func readXLMetaParts(disk StorageAPI, bucket string, object string) ([]objectPar
 	return xlMetaParts, nil
 }
 
-// read xl.json from the given disk and parse xlV1Meta.Stat and xlV1Meta.Meta using gjson.
-func readXLMetaStat(disk StorageAPI, bucket string, object string) (statInfo, map[string]string, error) {
+// read xl.json from the given disk and parse xlV1Meta.Stat, xlV1Meta.Meta and xlV1Meta.Parts using gjson.
+func readXLMetaStat(disk StorageAPI, bucket string, object string) (statInfo, map[string]string, []objectPartInfo, error) {
 	// Reads entire `xl.json`.
 	xlMetaBuf, err := disk.ReadAll(bucket, path.Join(object, xlMetaJSONFile))
 	if err != nil {
-		return statInfo{}, nil, traceError(err)
+		return statInfo{}, nil, nil, traceError(err)
 	}
 	// obtain xlMetaV1{}.Meta using `github.com/tidwall/gjson`.
 	xlMetaMap := parseXLMetaMap(xlMetaBuf)
@@ -268,10 +268,10 @@ func readXLMetaStat(disk StorageAPI, bucket string, object string) (statInfo, ma
 	// obtain xlMetaV1{}.Stat using `github.com/tidwall/gjson`.
 	xlStat, err := parseXLStat(xlMetaBuf)
 	if err != nil {
-		return statInfo{}, nil, traceError(err)
+		return statInfo{}, nil, nil, traceError(err)
 	}
 	// Return structured `xl.json`.
-	return xlStat, xlMetaMap, nil
+	return xlStat, xlMetaMap, parseXLParts(xlMetaBuf), nil
 }
 
 // readXLMeta reads `xl.json` and returns back XL metadata structure.
@@ -0,0 +1,125 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Problems reported by VerifyObject which show the data of an object is
+// lost, rather than only out of date on some disks.
+const (
+	bitrotProblem          = "checksum mismatch"
+	incompleteWriteProblem = "incomplete write"
+)
+
+// VerifyObject - re-reads the parts of an object from every disk and
+// compares them with the bitrot checksums in `xl.json`, returns the
+// discrepancies found. Unlike HealObject nothing is repaired.
+func (xl xlObjects) VerifyObject(bucket, object string) ([]string, error) {
+	if err := checkGetObjArgs(bucket, object); err != nil {
+		return nil, err
+	}
+
+	// Lock the object before reading.
+	objectLock := nsMutex.NewNSLock(bucket, object)
+	objectLock.RLock()
+	defer objectLock.RUnlock()
+
+	// Read metadata associated with the object from all disks.
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+	missing := 0
+	for _, err := range errs {
+		if errorCause(err) == errFileNotFound {
+			missing++
+		}
+	}
+	if missing > len(xl.storageDisks)-xl.readQuorum {
+		// Even with the offline disks back there would be no read
+		// quorum, the write of the object never completed.
+		return []string{fmt.Sprintf("%s found on %d of %d disks, %s",
+			xlMetaJSONFile, len(errs)-missing, len(errs), incompleteWriteProblem)}, nil
+	}
+	// Do we have read quorum?
+	if !isDiskQuorum(errs, xl.readQuorum) {
+		return nil, traceError(InsufficientReadQuorum{}, errs...)
+	}
+	if reducedErr := reduceReadQuorumErrs(errs, objectOpIgnoredErrs, xl.readQuorum); reducedErr != nil {
+		return nil, toObjectErr(reducedErr, bucket, object)
+	}
+
+	// Pick latest valid metadata.
+	_, modTime := listOnlineDisks(xl.storageDisks, metaArr, errs)
+	xlMeta, err := pickValidXLMeta(metaArr, modTime)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	for index, disk := range xl.storageDisks {
+		if disk == nil || errorCause(errs[index]) == errDiskNotFound {
+			problems = append(problems, fmt.Sprintf("disk %d: offline", index+1))
+			continue
+		}
+		if errs[index] != nil {
+			problems = append(problems, fmt.Sprintf("disk %s: unable to read %s: %s", disk, xlMetaJSONFile, errs[index]))
+			continue
+		}
+		if metaArr[index].Stat.ModTime != modTime {
+			problems = append(problems, fmt.Sprintf("disk %s: outdated %s", disk, xlMetaJSONFile))
+			continue
+		}
+		for _, part := range xlMeta.Parts {
+			sumInfo := metaArr[index].Erasure.GetCheckSumInfo(part.Name)
+			if !isValidBlock(disk, bucket, pathJoin(object, part.Name), sumInfo.Hash, sumInfo.Algorithm) {
+				problems = append(problems, fmt.Sprintf("disk %s: %s %s", disk, part.Name, bitrotProblem))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// QuarantineObject - moves an object out of the namespace into the
+// quarantine prefix of the meta bucket on all disks.
+func (xl xlObjects) QuarantineObject(bucket, object string) error {
+	if err := checkDelObjArgs(bucket, object); err != nil {
+		return err
+	}
+
+	objectLock := nsMutex.NewNSLock(bucket, object)
+	objectLock.Lock()
+	defer objectLock.Unlock()
+
+	quarantinePath := pathJoin(quarantineMetaPrefix, bucket, object, mustGetUUID())
+	// Objects with too few copies of xl.json are quarantined as
+	// well, a single disk suffices.
+	if err := renameObject(xl.storageDisks, bucket, object, minioMetaBucket, quarantinePath, 1); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	if xl.objCacheEnabled {
+		xl.objCache.Delete(pathJoin(bucket, object))
+	}
+	return nil
+}
+
+// PurgeTempFiles - removes temporary files older than olderThan on
+// the local disks.
+func (xl xlObjects) PurgeTempFiles(olderThan time.Duration) (int, error) {
+	return purgeTempFiles(xl.storageDisks, olderThan)
+}
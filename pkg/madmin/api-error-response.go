@@ -0,0 +1,92 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package madmin
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// ErrorResponse - Is the typed error returned by all API operations.
+type ErrorResponse struct {
+	XMLName    xml.Name `xml:"Error" json:"-"`
+	Code       string
+	Message    string
+	BucketName string
+	Key        string
+	RequestID  string `xml:"RequestId"`
+	HostID     string `xml:"HostId"`
+
+	// Region where the bucket is located. This header is returned
+	// only in HEAD bucket and ListObjects response.
+	Region string
+
+	// HTTP status code of the response.
+	StatusCode int `xml:"-" json:"-"`
+}
+
+// Error - Returns HTTP error string
+func (e ErrorResponse) Error() string {
+	return e.Message
+}
+
+// ToErrorResponse - Returns parsed ErrorResponse struct from body and
+// http headers.
+//
+// For example:
+//
+//	import admin "github.com/minio/minio/pkg/madmin"
+//	...
+//	...
+//	ss, err := adm.ServiceStatus(...)
+//	if err != nil {
+//	   resp := admin.ToErrorResponse(err)
+//	}
+//	...
+func ToErrorResponse(err error) ErrorResponse {
+	switch err := err.(type) {
+	case ErrorResponse:
+		return err
+	default:
+		return ErrorResponse{}
+	}
+}
+
+// httpRespToErrorResponse returns a new encoded ErrorResponse structure.
+func httpRespToErrorResponse(resp *http.Response) error {
+	if resp == nil || resp.Body == nil {
+		return errEmptyResponse
+	}
+	errResp := ErrorResponse{StatusCode: resp.StatusCode}
+	if err := xml.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		// Body is not a valid error document, fall back to the status line.
+		errResp.Code = resp.Status
+		errResp.Message = http.StatusText(resp.StatusCode)
+	}
+	errResp.StatusCode = resp.StatusCode
+	return errResp
+}
+
+// ErrInvalidArgument - Invalid argument response.
+func ErrInvalidArgument(message string) error {
+	return ErrorResponse{
+		Code:      "InvalidArgument",
+		Message:   message,
+		RequestID: "minio",
+	}
+}
@@ -0,0 +1,226 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package madmin implements a typed Go client for the Minio admin API.
+package madmin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/s3signer"
+)
+
+// Admin API path prefix and version served by Minio server.
+const (
+	adminAPIPathPrefix = "/minio/admin"
+	adminAPIVersion    = "v1"
+)
+
+// Default region used to sign admin API requests.
+const defaultRegion = "us-east-1"
+
+// Global constants.
+const (
+	libraryName    = "madmin-go"
+	libraryVersion = "0.0.1"
+)
+
+// User Agent should always following the below style.
+// Please open an issue to discuss any new changes here.
+//
+//	Minio (OS; ARCH) LIB/VER APP/VER
+const (
+	libraryUserAgentPrefix = "Minio (" + runtime.GOOS + "; " + runtime.GOARCH + ") "
+	libraryUserAgent       = libraryUserAgentPrefix + libraryName + "/" + libraryVersion
+)
+
+// AdminClient implements Minio admin API client.
+type AdminClient struct {
+	// Parsed endpoint url provided by the user.
+	endpointURL url.URL
+
+	// Credentials used to sign all admin API requests.
+	accessKeyID     string
+	secretAccessKey string
+
+	// Region used to sign requests, must match the server region.
+	region string
+
+	// Needs allocation.
+	httpClient *http.Client
+}
+
+// New - instantiate minio admin client. endpoint is of the form
+// `host:port` and secure decides between https and http.
+func New(endpoint string, accessKeyID, secretAccessKey string, secure bool) (*AdminClient, error) {
+	endpointURL, err := getEndpointURL(endpoint, secure)
+	if err != nil {
+		return nil, err
+	}
+	return &AdminClient{
+		endpointURL:     *endpointURL,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		region:          defaultRegion,
+		httpClient:      &http.Client{Transport: http.DefaultTransport},
+	}, nil
+}
+
+// SetRegion - sets the region used to sign requests, needed only when
+// the server is configured with a region other than "us-east-1".
+func (adm *AdminClient) SetRegion(region string) {
+	adm.region = region
+}
+
+// SetCustomTransport - set new custom transport.
+func (adm *AdminClient) SetCustomTransport(customHTTPTransport http.RoundTripper) {
+	adm.httpClient.Transport = customHTTPTransport
+}
+
+// getEndpointURL - construct a new endpoint URL.
+func getEndpointURL(endpoint string, secure bool) (*url.URL, error) {
+	if endpoint == "" || strings.Contains(endpoint, "/") {
+		return nil, ErrInvalidArgument("Endpoint should be of the form host:port without a scheme or path.")
+	}
+	scheme := "https"
+	if !secure {
+		scheme = "http"
+	}
+	return url.Parse(scheme + "://" + endpoint)
+}
+
+// requestData - is container for all the values to make a request.
+type requestData struct {
+	method      string
+	relPath     string // URL path relative to admin API base, e.g "/info".
+	queryValues url.Values
+	content     []byte
+	// Streamed instead of content with an unsigned payload.
+	contentBody   io.Reader
+	contentLength int64
+}
+
+// makeTargetURL - make a new target url.
+func (adm AdminClient) makeTargetURL(reqData requestData) *url.URL {
+	targetURL := adm.endpointURL
+	targetURL.Path = adminAPIPathPrefix + "/" + adminAPIVersion + reqData.relPath
+	if len(reqData.queryValues) > 0 {
+		targetURL.RawQuery = reqData.queryValues.Encode()
+	}
+	return &targetURL
+}
+
+// newRequest - instantiate a new signed HTTP request for a given method.
+func (adm AdminClient) newRequest(reqData requestData) (*http.Request, error) {
+	if reqData.method == "" {
+		return nil, ErrInvalidArgument("Method cannot be empty.")
+	}
+
+	req, err := http.NewRequest(reqData.method, adm.makeTargetURL(reqData).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", libraryUserAgent)
+	if reqData.contentBody != nil {
+		req.Header.Set("X-Amz-Content-Sha256", s3signer.UnsignedPayload)
+		req.Body = ioutil.NopCloser(reqData.contentBody)
+		req.ContentLength = reqData.contentLength
+	} else {
+		sum := sha256.Sum256(reqData.content)
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+		if len(reqData.content) > 0 {
+			req.Body = ioutil.NopCloser(bytes.NewReader(reqData.content))
+			req.ContentLength = int64(len(reqData.content))
+		}
+	}
+
+	return s3signer.SignV4(req, adm.accessKeyID, adm.secretAccessKey, adm.region, time.Now().UTC()), nil
+}
+
+// executeMethod - instantiates a signed request and executes it,
+// non 2xx responses are converted into an ErrorResponse.
+func (adm AdminClient) executeMethod(reqData requestData) (*http.Response, error) {
+	req, err := adm.newRequest(reqData)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := adm.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer closeResponse(resp)
+		return nil, httpRespToErrorResponse(resp)
+	}
+	return resp, nil
+}
+
+// executeJSONMethod - executes the request and decodes the JSON response into v.
+func (adm AdminClient) executeJSONMethod(reqData requestData, v interface{}) error {
+	resp, err := adm.executeMethod(reqData)
+	if err != nil {
+		return err
+	}
+	defer closeResponse(resp)
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// executeNoContentMethod - executes a request whose response body is ignored.
+func (adm AdminClient) executeNoContentMethod(reqData requestData) error {
+	resp, err := adm.executeMethod(reqData)
+	if err != nil {
+		return err
+	}
+	closeResponse(resp)
+	return nil
+}
+
+// closeResponse close non nil response with any response Body.
+// convenient wrapper to drain any remaining data on response body.
+//
+// Subsequently this allows golang http RoundTripper
+// to re-use the same connection for future requests.
+func closeResponse(resp *http.Response) {
+	// Callers should close resp.Body when done reading from it.
+	// If resp.Body is not closed, the Client's underlying RoundTripper
+	// (typically Transport) may not be able to re-use a persistent TCP
+	// connection to the server for a subsequent "keep-alive" request.
+	if resp != nil && resp.Body != nil {
+		// Drain any remaining Body and then close the connection.
+		// Without this closing connection would disallow re-using
+		// the same connection for future uses.
+		//  - http://stackoverflow.com/a/17961593/4465767
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// errEmptyResponse is returned when the server sends an error
+// status without any error document.
+var errEmptyResponse = errors.New("Response is empty. Please report this issue at https://github.com/minio/minio/issues")
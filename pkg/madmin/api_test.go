@@ -0,0 +1,51 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import "testing"
+
+// Tests validation of endpoints passed to New.
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		endpoint    string
+		secure      bool
+		expectedURL string
+		shouldPass  bool
+	}{
+		{"localhost:9000", false, "http://localhost:9000/minio/admin/v1/info", true},
+		{"play.minio.io", true, "https://play.minio.io/minio/admin/v1/info", true},
+		{"", false, "", false},
+		{"http://localhost:9000", false, "", false},
+		{"localhost:9000/path", false, "", false},
+	}
+	for i, testCase := range testCases {
+		adm, err := New(testCase.endpoint, "accesskey", "secretkey", testCase.secure)
+		if err != nil && testCase.shouldPass {
+			t.Errorf("Test %d: Expected to pass, but failed with: %s", i+1, err)
+		}
+		if err == nil && !testCase.shouldPass {
+			t.Errorf("Test %d: Expected to fail, but passed", i+1)
+		}
+		if err != nil {
+			continue
+		}
+		targetURL := adm.makeTargetURL(requestData{relPath: "/info"})
+		if targetURL.String() != testCase.expectedURL {
+			t.Errorf("Test %d: Expected URL %s, got %s", i+1, testCase.expectedURL, targetURL)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Batch job operations.
+const (
+	// Copies the objects to the target bucket and prefix.
+	BatchJobCopy = "copy"
+	// Deletes the objects.
+	BatchJobDelete = "delete"
+)
+
+// BatchJobFilter - selects the objects a batch job applies to, empty
+// fields match every object.
+type BatchJobFilter struct {
+	Suffix         string    `json:"suffix,omitempty"`
+	MinSize        int64     `json:"minSize,omitempty"`
+	MaxSize        int64     `json:"maxSize,omitempty"`
+	ModifiedBefore time.Time `json:"modifiedBefore,omitempty"`
+	ModifiedAfter  time.Time `json:"modifiedAfter,omitempty"`
+}
+
+// BatchJobRequest - description of a batch job, the operation is
+// applied to every object under prefix matched by the filter.
+type BatchJobRequest struct {
+	Operation string         `json:"operation"`
+	Bucket    string         `json:"bucket"`
+	Prefix    string         `json:"prefix,omitempty"`
+	Filter    BatchJobFilter `json:"filter"`
+	// Destination of copies, the prefix replaces the source prefix.
+	TargetBucket string `json:"targetBucket,omitempty"`
+	TargetPrefix string `json:"targetPrefix,omitempty"`
+	// Retries of a failed object operation, 3 if not set.
+	Retries *int `json:"retries,omitempty"`
+}
+
+// BatchJobFailure - an object the operation failed for.
+type BatchJobFailure struct {
+	Object string `json:"object"`
+	Error  string `json:"error"`
+}
+
+// BatchJobStatus - progress of a batch job, its completion report
+// once finished. State is one of running, completed, failed or
+// cancelled.
+type BatchJobStatus struct {
+	ID        string            `json:"id"`
+	Request   BatchJobRequest   `json:"request"`
+	State     string            `json:"state"`
+	Started   time.Time         `json:"started"`
+	Finished  time.Time         `json:"finished,omitempty"`
+	Scanned   int64             `json:"scanned"`
+	Matched   int64             `json:"matched"`
+	Succeeded int64             `json:"succeeded"`
+	Failed    int64             `json:"failed"`
+	Bytes     int64             `json:"bytes"`
+	Failures  []BatchJobFailure `json:"failures,omitempty"`
+	// Reason of a failed job.
+	Error string `json:"error,omitempty"`
+}
+
+// StartBatchJob - Starts a job applying an operation to the objects
+// under a prefix on the server, returns once the job is started.
+func (adm *AdminClient) StartBatchJob(job BatchJobRequest) (BatchJobStatus, error) {
+	var status BatchJobStatus
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return status, err
+	}
+	err = adm.executeJSONMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/batch",
+		content: jobBytes,
+	}, &status)
+	return status, err
+}
+
+// ListBatchJobs - Lists the running jobs and the last finished ones.
+func (adm *AdminClient) ListBatchJobs() ([]BatchJobStatus, error) {
+	var statuses []BatchJobStatus
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/batch",
+	}, &statuses)
+	return statuses, err
+}
+
+// GetBatchJob - Returns the progress of a job, or its completion
+// report once finished.
+func (adm *AdminClient) GetBatchJob(id string) (BatchJobStatus, error) {
+	var status BatchJobStatus
+	if id == "" {
+		return status, ErrInvalidArgument("Batch job ID cannot be empty.")
+	}
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/batch/" + id,
+	}, &status)
+	return status, err
+}
+
+// CancelBatchJob - Stops a running job after the object in progress.
+func (adm *AdminClient) CancelBatchJob(id string) error {
+	if id == "" {
+		return ErrInvalidArgument("Batch job ID cannot be empty.")
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodDelete,
+		relPath: "/batch/" + id,
+	})
+}
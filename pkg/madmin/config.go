@@ -0,0 +1,54 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// GetConfig - Returns the JSON encoded configuration currently
+// loaded by the server, its secret keys and notification target
+// passwords replaced by "REDACTED".
+func (adm *AdminClient) GetConfig() ([]byte, error) {
+	resp, err := adm.executeMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/config",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponse(resp)
+	return ioutil.ReadAll(resp.Body)
+}
+
+// SetConfig - Replaces the server configuration with the JSON
+// document read from config. The server validates and persists
+// the new configuration, it takes effect after ServiceRestart.
+// Secret keys and passwords left as "REDACTED" are kept.
+func (adm *AdminClient) SetConfig(config io.Reader) error {
+	configBytes, err := ioutil.ReadAll(config)
+	if err != nil {
+		return err
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPut,
+		relPath: "/config",
+		content: configBytes,
+	})
+}
@@ -0,0 +1,65 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Credential - access and secret keys of the server.
+type Credential struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	// Time after which the keys are rejected, nil if they never expire.
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// RotateCredential - Replaces the server credentials with newly
+// generated ones which expire at expiry, never if zero. The current
+// credentials stay valid for the S3 API during the grace period, the
+// clients must switch to the returned credentials before it ends. The
+// admin API and the browser only accept the returned credentials.
+func (adm *AdminClient) RotateCredential(grace time.Duration, expiry time.Time) (Credential, error) {
+	queryValues := make(url.Values)
+	queryValues.Set("grace", grace.String())
+	if !expiry.IsZero() {
+		queryValues.Set("expiry", expiry.UTC().Format(time.RFC3339))
+	}
+	var cred Credential
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodPost,
+		relPath:     "/credential/rotate",
+		queryValues: queryValues,
+	}, &cred)
+	return cred, err
+}
+
+// SetCredentialExpiry - Sets the time after which the current server
+// credentials are rejected, a zero expiry removes it.
+func (adm *AdminClient) SetCredentialExpiry(expiry time.Time) error {
+	queryValues := make(url.Values)
+	if !expiry.IsZero() {
+		queryValues.Set("expiry", expiry.UTC().Format(time.RFC3339))
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:      http.MethodPut,
+		relPath:     "/credential/expiry",
+		queryValues: queryValues,
+	})
+}
@@ -0,0 +1,105 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// JournalEvent - a bucket event recorded by the server. Record is the
+// S3 event message sent to notification targets.
+type JournalEvent struct {
+	ID        string          `json:"id"`
+	Time      time.Time       `json:"time"`
+	Bucket    string          `json:"bucket"`
+	Object    string          `json:"object"`
+	EventType string          `json:"eventType"`
+	Record    json.RawMessage `json:"record"`
+}
+
+// EventList - recorded events in chronological order, truncated if
+// more events match than were asked for.
+type EventList struct {
+	Events    []JournalEvent `json:"events"`
+	Truncated bool           `json:"truncated"`
+}
+
+// EventReplayRequest - selects recorded events to send again to a
+// notification target, by ID if any are given and within the time
+// range and bucket otherwise.
+type EventReplayRequest struct {
+	Target string    `json:"target"`
+	IDs    []string  `json:"ids,omitempty"`
+	From   time.Time `json:"from,omitempty"`
+	To     time.Time `json:"to,omitempty"`
+	Bucket string    `json:"bucket,omitempty"`
+}
+
+// EventReplayResult - number of events sent to the target.
+type EventReplayResult struct {
+	Replayed int `json:"replayed"`
+}
+
+// ListEvents - Lists up to max bucket events recorded between from and
+// to, in all buckets if bucket is empty. Zero times and max are not
+// sent, the server then lists from the oldest recorded event up to now,
+// 1000 events at most.
+func (adm *AdminClient) ListEvents(from, to time.Time, bucket string, max int) (EventList, error) {
+	var list EventList
+	queryValues := make(url.Values)
+	if !from.IsZero() {
+		queryValues.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		queryValues.Set("to", to.Format(time.RFC3339))
+	}
+	if bucket != "" {
+		queryValues.Set("bucket", bucket)
+	}
+	if max > 0 {
+		queryValues.Set("max", strconv.Itoa(max))
+	}
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodGet,
+		relPath:     "/events",
+		queryValues: queryValues,
+	}, &list)
+	return list, err
+}
+
+// ReplayEvents - Sends recorded bucket events again to the notification
+// target with the ARN of req.Target.
+func (adm *AdminClient) ReplayEvents(req EventReplayRequest) (EventReplayResult, error) {
+	var result EventReplayResult
+	if req.Target == "" {
+		return result, ErrInvalidArgument("Event target cannot be empty.")
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return result, err
+	}
+	err = adm.executeJSONMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/events/replay",
+		content: reqBytes,
+	}, &result)
+	return result, err
+}
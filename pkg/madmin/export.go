@@ -0,0 +1,68 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// BucketImportInfo - summary of an imported archive.
+type BucketImportInfo struct {
+	Objects int   `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// ExportBucket - Returns a tar archive of the objects under prefix
+// with their metadata, streamed from the server. The caller must
+// close the returned reader.
+func (adm *AdminClient) ExportBucket(bucket, prefix string) (io.ReadCloser, error) {
+	if bucket == "" {
+		return nil, ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	queryValues := make(url.Values)
+	if prefix != "" {
+		queryValues.Set("prefix", prefix)
+	}
+	resp, err := adm.executeMethod(requestData{
+		method:      http.MethodGet,
+		relPath:     "/export/" + bucket,
+		queryValues: queryValues,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// ImportBucket - Creates an object for every file of a tar archive of
+// size bytes, restoring the metadata of archives written by
+// ExportBucket. The bucket is created if it does not exist.
+func (adm *AdminClient) ImportBucket(bucket string, archive io.Reader, size int64) (BucketImportInfo, error) {
+	var info BucketImportInfo
+	if bucket == "" {
+		return info, ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	err := adm.executeJSONMethod(requestData{
+		method:        http.MethodPut,
+		relPath:       "/import/" + bucket,
+		contentBody:   archive,
+		contentLength: size,
+	}, &info)
+	return info, err
+}
@@ -0,0 +1,57 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// FsckObject - an inconsistent object and the repair applied.
+type FsckObject struct {
+	Bucket   string   `json:"bucket"`
+	Object   string   `json:"object"`
+	Problems []string `json:"problems"`
+	// One of healed, quarantined or unrepaired, empty unless a
+	// repair was requested.
+	Action string `json:"action,omitempty"`
+}
+
+// FsckInfo - summary of a consistency check of the backend.
+type FsckInfo struct {
+	Objects         int          `json:"objects"`
+	TempFilesPurged int          `json:"tempFilesPurged"`
+	Healed          int          `json:"healed"`
+	Quarantined     int          `json:"quarantined"`
+	Inconsistent    []FsckObject `json:"inconsistent"`
+}
+
+// Fsck - Checks all objects against their metadata and checksums.
+// With repair stale temporary files are removed, inconsistent objects
+// are healed and objects with damaged data are moved to quarantine.
+func (adm *AdminClient) Fsck(repair bool) (FsckInfo, error) {
+	var info FsckInfo
+	queryValues := make(url.Values)
+	queryValues.Set("repair", strconv.FormatBool(repair))
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodPost,
+		relPath:     "/fsck",
+		queryValues: queryValues,
+	}, &info)
+	return info, err
+}
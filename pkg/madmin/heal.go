@@ -0,0 +1,43 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import "net/http"
+
+// HealBucket - Heals missing bucket entries on all the disks, valid
+// only for XL backends.
+func (adm *AdminClient) HealBucket(bucket string) error {
+	if bucket == "" {
+		return ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/heal/" + bucket,
+	})
+}
+
+// HealObject - Heals missing or outdated parts of an object on all
+// the disks, valid only for XL backends.
+func (adm *AdminClient) HealObject(bucket, object string) error {
+	if bucket == "" || object == "" {
+		return ErrInvalidArgument("Bucket and object names cannot be empty.")
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/heal/" + bucket + "/" + object,
+	})
+}
@@ -0,0 +1,73 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"time"
+)
+
+// BackendType - represents different backend types.
+type BackendType int
+
+// Enum for different backend types.
+const (
+	Unknown BackendType = iota
+	// Filesystem backend.
+	FS
+	// Multi disk XL (single, distributed) backend.
+	XL
+)
+
+// StorageInfo - represents total capacity of underlying storage.
+type StorageInfo struct {
+	// Total disk space.
+	Total int64
+	// Free available disk space.
+	Free int64
+	// Backend type.
+	Backend struct {
+		// Represents various backend types, currently on FS and XL.
+		Type BackendType
+
+		// Following fields are only meaningful if BackendType is XL.
+		OnlineDisks  int // Online disks during server startup.
+		OfflineDisks int // Offline disks during server startup.
+		ReadQuorum   int // Minimum disks required for successful read operations.
+		WriteQuorum  int // Minimum disks required for successful write operations.
+	}
+}
+
+// ServerInfo - represents the response of server info API.
+type ServerInfo struct {
+	ServerVersion ServerVersion `json:"serverVersion"`
+	Uptime        time.Duration `json:"uptime"`
+	Region        string        `json:"region"`
+	IsDistXL      bool          `json:"isDistXL"`
+	StorageInfo   StorageInfo   `json:"storageInfo"`
+}
+
+// ServerInfo - Returns version, uptime, region and storage
+// information of the server.
+func (adm *AdminClient) ServerInfo() (ServerInfo, error) {
+	var info ServerInfo
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/info",
+	}, &info)
+	return info, err
+}
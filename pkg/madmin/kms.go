@@ -0,0 +1,94 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package madmin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// KMSRewrapInfo - progress of re-wrapping the data keys of the objects
+// of a bucket under the current master key. A run is resumed after
+// Marker and VersionMarker.
+type KMSRewrapInfo struct {
+	Bucket string `json:"bucket"`
+	// Last object and prior version re-wrapped or skipped.
+	Marker        string `json:"marker,omitempty"`
+	VersionMarker string `json:"versionMarker,omitempty"`
+	// Number of objects and prior versions whose data keys were
+	// re-wrapped, and of those which needed no re-wrapping.
+	Rewrapped int64 `json:"rewrapped"`
+	Skipped   int64 `json:"skipped"`
+	// Set on the last progress, along with Error if the run failed.
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// RewrapKeys - Re-wraps the data keys of the encrypted objects of a
+// bucket and of the prior versions of its objects under the current
+// master key, from marker and versionMarker on. Data keys already
+// sealed by the current master key are re-wrapped only if force is
+// set. progress, if not nil, is called with the progress streamed by
+// the server, the last one is returned. A failed run is resumed with
+// the markers of the returned progress.
+func (adm *AdminClient) RewrapKeys(bucket, marker, versionMarker string, force bool, progress func(KMSRewrapInfo)) (KMSRewrapInfo, error) {
+	info := KMSRewrapInfo{Bucket: bucket, Marker: marker, VersionMarker: versionMarker}
+	if bucket == "" {
+		return info, ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	queryValues := make(url.Values)
+	if marker != "" {
+		queryValues.Set("marker", marker)
+	}
+	if versionMarker != "" {
+		queryValues.Set("version-marker", versionMarker)
+	}
+	if force {
+		queryValues.Set("force", "true")
+	}
+	resp, err := adm.executeMethod(requestData{
+		method:      http.MethodPost,
+		relPath:     "/kms/rewrap/" + bucket,
+		queryValues: queryValues,
+	})
+	if err != nil {
+		return info, err
+	}
+	defer closeResponse(resp)
+
+	dec := json.NewDecoder(resp.Body)
+	for !info.Done {
+		var next KMSRewrapInfo
+		if err = dec.Decode(&next); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return info, err
+		}
+		info = next
+		if progress != nil {
+			progress(info)
+		}
+	}
+	if info.Error != "" {
+		return info, errors.New(info.Error)
+	}
+	return info, nil
+}
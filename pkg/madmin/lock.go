@@ -0,0 +1,81 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// OpsLockState - represents lock specific details of an operation.
+type OpsLockState struct {
+	OperationID string        `json:"opsID"`          // String containing operation ID.
+	LockSource  string        `json:"lockSource"`     // Operation type (GetObject, PutObject...)
+	LockType    string        `json:"lockType"`       // Lock type (RLock, WLock)
+	Status      string        `json:"status"`         // Status can be Running/Ready/Blocked.
+	Since       time.Time     `json:"statusSince"`    // Time when the lock was initially held.
+	Duration    time.Duration `json:"statusDuration"` // Duration since the lock was held.
+}
+
+// VolumeLockInfo - represents the lock state of a bucket, object pair.
+type VolumeLockInfo struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	// All locks blocked + running for given <volume,path> pair.
+	LocksOnObject int64 `json:"locksOnObject"`
+	// Count of operations which has successfully acquired the lock
+	// but hasn't unlocked yet( operation in progress).
+	LocksAcquiredOnObject int64 `json:"locksAcquiredOnObject"`
+	// Count of operations which are blocked waiting for the lock
+	// to be released.
+	TotalBlockedLocks int64 `json:"locksBlockedOnObject"`
+	// State information containing state of the locks for all operations
+	// on given <volume,path> pair.
+	LockDetailsOnObject []OpsLockState `json:"lockDetailsOnObject"`
+}
+
+// SystemLockState - represents the lock state of the entire server.
+type SystemLockState struct {
+	TotalLocks int64 `json:"totalLocks"`
+	// Count of operations which are blocked waiting for the lock to
+	// be released.
+	TotalBlockedLocks int64 `json:"totalBlockedLocks"`
+	// Count of operations which has successfully acquired the lock but
+	// hasn't unlocked yet( operation in progress).
+	TotalAcquiredLocks int64            `json:"totalAcquiredLocks"`
+	LocksInfoPerObject []VolumeLockInfo `json:"locksInfoPerObject"`
+}
+
+// ListLocks - Lists namespace locks held or waited upon, an empty
+// bucket or prefix matches every lock.
+func (adm *AdminClient) ListLocks(bucket, prefix string) (SystemLockState, error) {
+	queryValues := make(url.Values)
+	if bucket != "" {
+		queryValues.Set("bucket", bucket)
+	}
+	if prefix != "" {
+		queryValues.Set("prefix", prefix)
+	}
+	var lockState SystemLockState
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodGet,
+		relPath:     "/locks",
+		queryValues: queryValues,
+	}, &lockState)
+	return lockState, err
+}
@@ -0,0 +1,75 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AnonymousPolicyType - anonymous access allowed on a prefix.
+type AnonymousPolicyType string
+
+// Anonymous policy types.
+const (
+	// No anonymous access.
+	AnonymousPolicyNone AnonymousPolicyType = "none"
+	// Anonymous downloads and listing.
+	AnonymousPolicyDownload AnonymousPolicyType = "download"
+	// Anonymous uploads.
+	AnonymousPolicyUpload AnonymousPolicyType = "upload"
+	// Anonymous downloads, listing and uploads.
+	AnonymousPolicyPublic AnonymousPolicyType = "public"
+)
+
+// AnonymousPolicy - anonymous policy of a prefix in a bucket.
+type AnonymousPolicy struct {
+	Prefix string              `json:"prefix"`
+	Policy AnonymousPolicyType `json:"policy"`
+}
+
+// ListAnonymousPolicies - Lists the anonymous policies of all the
+// prefixes of bucket, prefixes without anonymous access are omitted.
+func (adm *AdminClient) ListAnonymousPolicies(bucket string) ([]AnonymousPolicy, error) {
+	if bucket == "" {
+		return nil, ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	var policies []AnonymousPolicy
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/policy/" + bucket,
+	}, &policies)
+	return policies, err
+}
+
+// SetAnonymousPolicy - Sets the anonymous policy of prefix in bucket,
+// an empty prefix applies to the whole bucket. The server translates
+// the policy into bucket policy statements.
+func (adm *AdminClient) SetAnonymousPolicy(bucket, prefix string, policy AnonymousPolicyType) error {
+	if bucket == "" {
+		return ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	policyBytes, err := json.Marshal(AnonymousPolicy{Prefix: prefix, Policy: policy})
+	if err != nil {
+		return err
+	}
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPut,
+		relPath: "/policy/" + bucket,
+		content: policyBytes,
+	})
+}
@@ -0,0 +1,99 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ProfilerType - type of profile captured by CaptureProfile.
+type ProfilerType string
+
+// Profiles supported by CaptureProfile.
+const (
+	ProfilerCPU   ProfilerType = "cpu"
+	ProfilerHeap  ProfilerType = "heap"
+	ProfilerBlock ProfilerType = "block"
+)
+
+// RuntimeInfo - Go runtime statistics of the server process.
+type RuntimeInfo struct {
+	GoVersion    string        `json:"goVersion"`
+	NumCPU       int           `json:"numCPU"`
+	NumGoroutine int           `json:"numGoroutine"`
+	Alloc        uint64        `json:"alloc"`
+	TotalAlloc   uint64        `json:"totalAlloc"`
+	Sys          uint64        `json:"sys"`
+	HeapAlloc    uint64        `json:"heapAlloc"`
+	HeapInuse    uint64        `json:"heapInuse"`
+	HeapObjects  uint64        `json:"heapObjects"`
+	NumGC        uint32        `json:"numGC"`
+	PauseTotal   time.Duration `json:"pauseTotal"`
+	LastGC       time.Time     `json:"lastGC"`
+}
+
+// RuntimeInfo - Returns goroutine, memory and garbage collector
+// statistics of the server.
+func (adm *AdminClient) RuntimeInfo() (RuntimeInfo, error) {
+	var info RuntimeInfo
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/debug/runtime",
+	}, &info)
+	return info, err
+}
+
+// DumpGoroutines - Returns the stacks of all goroutines of the server
+// as text.
+func (adm *AdminClient) DumpGoroutines() ([]byte, error) {
+	queryValues := make(url.Values)
+	queryValues.Set("debug", "2")
+	resp, err := adm.executeMethod(requestData{
+		method:      http.MethodGet,
+		relPath:     "/debug/pprof/goroutine",
+		queryValues: queryValues,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponse(resp)
+	return ioutil.ReadAll(resp.Body)
+}
+
+// CaptureProfile - Samples a CPU or block profile on the server for
+// duration, or takes a heap profile, and returns it in pprof format.
+// The caller must close the returned reader.
+func (adm *AdminClient) CaptureProfile(profiler ProfilerType, duration time.Duration) (io.ReadCloser, error) {
+	queryValues := make(url.Values)
+	queryValues.Set("profile", string(profiler))
+	if duration > 0 {
+		queryValues.Set("duration", duration.String())
+	}
+	resp, err := adm.executeMethod(requestData{
+		method:      http.MethodPost,
+		relPath:     "/debug/profile",
+		queryValues: queryValues,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"time"
+)
+
+// ServerVersion - server version information.
+type ServerVersion struct {
+	Version  string `json:"version"`
+	CommitID string `json:"commitID"`
+}
+
+// ServiceStatusMetadata - represents the response of service status API.
+type ServiceStatusMetadata struct {
+	ServerVersion ServerVersion `json:"serverVersion"`
+	Uptime        time.Duration `json:"uptime"`
+}
+
+// ServiceStatus - Returns the version and uptime of the server.
+func (adm *AdminClient) ServiceStatus() (ServiceStatusMetadata, error) {
+	var status ServiceStatusMetadata
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/service",
+	}, &status)
+	return status, err
+}
+
+// ServiceRestart - Restarts the server process. The call returns
+// before the server goes down, callers may poll ServiceStatus to
+// find out when the server is back online.
+func (adm *AdminClient) ServiceRestart() error {
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/service/restart",
+	})
+}
+
+// ServiceStop - Stops the server process gracefully.
+func (adm *AdminClient) ServiceStop() error {
+	return adm.executeNoContentMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/service/stop",
+	})
+}
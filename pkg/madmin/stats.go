@@ -0,0 +1,132 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"time"
+)
+
+// Throughput - request and byte rates per second.
+type Throughput struct {
+	RequestsPerSec float64 `json:"requestsPerSec"`
+	RxBytesPerSec  float64 `json:"rxBytesPerSec"`
+	TxBytesPerSec  float64 `json:"txBytesPerSec"`
+}
+
+// APIStats - request counters of a single API.
+type APIStats struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+}
+
+// AuthThrottleStats - failed authentication counters.
+type AuthThrottleStats struct {
+	Failures       uint64 `json:"failures"`
+	Rejected       uint64 `json:"rejected"`
+	BlockedSources int    `json:"blockedSources"`
+}
+
+// MemoryPressureStats - memory usage against the configured limit,
+// writes are shed with SlowDown while active.
+type MemoryPressureStats struct {
+	Limit  uint64 `json:"limit"`
+	Used   uint64 `json:"used"`
+	Active bool   `json:"active"`
+	Shed   uint64 `json:"shed"`
+}
+
+// AdmissionStats - load against the configured admission limits,
+// listings are shed first while saturated.
+type AdmissionStats struct {
+	MaxRequests  int64         `json:"maxRequests"`
+	MaxLatency   time.Duration `json:"maxLatency"`
+	InFlight     int64         `json:"inFlight"`
+	Latency      time.Duration `json:"latency"`
+	Saturated    bool          `json:"saturated"`
+	ShedListings uint64        `json:"shedListings"`
+	ShedRequests uint64        `json:"shedRequests"`
+}
+
+// QoSStats - requests served and waiting per scheduling class,
+// "interactive" or "bulk".
+type QoSStats struct {
+	MaxRequests int               `json:"maxRequests"`
+	Running     int               `json:"running"`
+	Waiting     map[string]int    `json:"waiting"`
+	Served      map[string]uint64 `json:"served"`
+}
+
+// ListCacheStats - listings cached, served from the cache and
+// dropped by writes.
+type ListCacheStats struct {
+	TTL           time.Duration `json:"ttl"`
+	Entries       int           `json:"entries"`
+	Hits          uint64        `json:"hits"`
+	Misses        uint64        `json:"misses"`
+	Invalidations uint64        `json:"invalidations"`
+}
+
+// LatencyBucket - calls slower than the bound of the previous bucket
+// and at most as slow as UpperBound, zero for the slowest bucket.
+type LatencyBucket struct {
+	UpperBound time.Duration `json:"upperBound"`
+	Count      uint64        `json:"count"`
+}
+
+// BackendOpStats - calls to a single operation of the backend, with
+// the object data read and written and the time spent.
+type BackendOpStats struct {
+	Calls        uint64          `json:"calls"`
+	Errors       uint64          `json:"errors"`
+	BytesRead    uint64          `json:"bytesRead"`
+	BytesWritten uint64          `json:"bytesWritten"`
+	TotalLatency time.Duration   `json:"totalLatency"`
+	Latency      []LatencyBucket `json:"latency"`
+}
+
+// ServerStats - live statistics of the server, counters are
+// cumulative since the server started.
+type ServerStats struct {
+	Uptime           time.Duration             `json:"uptime"`
+	Requests         uint64                    `json:"requests"`
+	Errors           uint64                    `json:"errors"`
+	ServerErrors     uint64                    `json:"serverErrors"`
+	BytesReceived    uint64                    `json:"bytesReceived"`
+	BytesSent        uint64                    `json:"bytesSent"`
+	Throughput       Throughput                `json:"throughput"`
+	OpenConnections  int64                     `json:"openConnections"`
+	InFlightRequests int64                     `json:"inFlightRequests"`
+	APIs             map[string]APIStats       `json:"apis"`
+	AuthThrottle     AuthThrottleStats         `json:"authThrottle"`
+	MemoryPressure   MemoryPressureStats       `json:"memoryPressure"`
+	Admission        AdmissionStats            `json:"admission"`
+	QoS              QoSStats                  `json:"qos"`
+	ListCache        ListCacheStats            `json:"listCache"`
+	Backend          map[string]BackendOpStats `json:"backend"`
+}
+
+// ServerStats - Returns request counters per API, error counts, bytes
+// transferred, open connections and in-flight requests.
+func (adm *AdminClient) ServerStats() (ServerStats, error) {
+	var stats ServerStats
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodGet,
+		relPath: "/stats",
+	}, &stats)
+	return stats, err
+}
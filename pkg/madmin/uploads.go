@@ -0,0 +1,58 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// IncompleteUpload - a multipart upload in progress.
+type IncompleteUpload struct {
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	UploadID  string    `json:"uploadId"`
+	Initiated time.Time `json:"initiated"`
+	// Time of the latest part upload, Initiated if there are none.
+	LastModified time.Time `json:"lastModified"`
+	Parts        int       `json:"parts"`
+	Size         int64     `json:"size"`
+}
+
+// IncompleteUploadsInfo - multipart uploads in progress and the
+// storage used by their parts.
+type IncompleteUploadsInfo struct {
+	Uploads   []IncompleteUpload `json:"uploads"`
+	TotalSize int64              `json:"totalSize"`
+}
+
+// ListIncompleteUploads - Lists the multipart uploads in progress
+// under prefix with the size of their parts, in all buckets if bucket
+// is empty.
+func (adm *AdminClient) ListIncompleteUploads(bucket, prefix string) (IncompleteUploadsInfo, error) {
+	var info IncompleteUploadsInfo
+	queryValues := make(url.Values)
+	queryValues.Set("bucket", bucket)
+	queryValues.Set("prefix", prefix)
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodGet,
+		relPath:     "/uploads",
+		queryValues: queryValues,
+	}, &info)
+	return info, err
+}
@@ -0,0 +1,78 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package madmin
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ObjectVerifyInfo - result of verifying an object against its
+// stored checksums.
+type ObjectVerifyInfo struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+	// Discrepancies found, empty if the object is intact.
+	Problems []string `json:"problems"`
+}
+
+// VerifyObjectsInfo - result of verifying objects under a prefix,
+// only objects with discrepancies are listed.
+type VerifyObjectsInfo struct {
+	Verified    int                `json:"verified"`
+	Objects     []ObjectVerifyInfo `json:"objects"`
+	IsTruncated bool               `json:"isTruncated"`
+	NextMarker  string             `json:"nextMarker,omitempty"`
+}
+
+// VerifyObject - Re-reads an object from the backend and compares it
+// with its stored ETag and bitrot checksums.
+func (adm *AdminClient) VerifyObject(bucket, object string) (ObjectVerifyInfo, error) {
+	var info ObjectVerifyInfo
+	if bucket == "" || object == "" {
+		return info, ErrInvalidArgument("Bucket and object names cannot be empty.")
+	}
+	err := adm.executeJSONMethod(requestData{
+		method:  http.MethodPost,
+		relPath: "/verify/" + bucket + "/" + object,
+	}, &info)
+	return info, err
+}
+
+// VerifyObjects - Verifies up to maxKeys objects under prefix after
+// marker, continue with NextMarker while IsTruncated is set.
+func (adm *AdminClient) VerifyObjects(bucket, prefix, marker string, maxKeys int) (VerifyObjectsInfo, error) {
+	var info VerifyObjectsInfo
+	if bucket == "" {
+		return info, ErrInvalidArgument("Bucket name cannot be empty.")
+	}
+	queryValues := make(url.Values)
+	queryValues.Set("prefix", prefix)
+	queryValues.Set("marker", marker)
+	if maxKeys > 0 {
+		queryValues.Set("max-keys", strconv.Itoa(maxKeys))
+	}
+	err := adm.executeJSONMethod(requestData{
+		method:      http.MethodPost,
+		relPath:     "/verify/" + bucket,
+		queryValues: queryValues,
+	}, &info)
+	return info, err
+}
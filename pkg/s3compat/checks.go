@@ -0,0 +1,700 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3compat
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Objects written by the checks, in the order they are listed.
+const (
+	objectName     = "object"
+	copyObjectName = "object-copy"
+	dirObjectA     = "dir/a"
+	dirObjectB     = "dir/b"
+	multipartName  = "multipart"
+)
+
+// Smallest size of a multipart upload part other than the last one.
+const minPartSize = 5 * 1024 * 1024
+
+// Listing documents returned by S3, namespaces are ignored.
+type listAllMyBucketsResult struct {
+	Buckets []struct {
+		Name string
+	} `xml:"Buckets>Bucket"`
+}
+
+type listBucketResult struct {
+	IsTruncated           bool
+	NextMarker            string
+	NextContinuationToken string
+	Contents              []struct {
+		Key  string
+		Size int64
+	}
+	CommonPrefixes []struct {
+		Prefix string
+	}
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type listPartsResult struct {
+	Parts []struct {
+		PartNumber int
+		Size       int64
+	} `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type etagResult struct {
+	ETag string
+}
+
+type deleteObjectsRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool
+	Objects []struct {
+		Key string
+	} `xml:"Object"`
+}
+
+type deleteObjectsResult struct {
+	Deleted []struct {
+		Key string
+	}
+	Errors []struct {
+		Key  string
+		Code string
+	} `xml:"Error"`
+}
+
+// check - one S3 behavior verified against the endpoint.
+type check struct {
+	name string
+	run  func(s *suite) error
+	// Later checks are skipped if a required check fails.
+	required bool
+	// Run even if a required check failed, once the bucket exists.
+	cleanup bool
+}
+
+// suite - state shared by the checks of a run, they are run in order
+// against a bucket created by the first one.
+type suite struct {
+	client  *client
+	bucket  string
+	created bool
+	data    []byte
+	etag    string
+}
+
+// Checks run against every endpoint, in order.
+var checks = []check{
+	{name: "MakeBucket", run: (*suite).makeBucket, required: true},
+	{name: "MakeBucketExisting", run: (*suite).makeBucketExisting},
+	{name: "HeadBucket", run: (*suite).headBucket},
+	{name: "HeadBucketMissing", run: (*suite).headBucketMissing},
+	{name: "ListBuckets", run: (*suite).listBuckets},
+	{name: "PutObject", run: (*suite).putObject, required: true},
+	{name: "GetObject", run: (*suite).getObject},
+	{name: "HeadObject", run: (*suite).headObject},
+	{name: "GetObjectRange", run: (*suite).getObjectRange},
+	{name: "GetObjectInvalidRange", run: (*suite).getObjectInvalidRange},
+	{name: "GetObjectConditional", run: (*suite).getObjectConditional},
+	{name: "GetObjectMissing", run: (*suite).getObjectMissing},
+	{name: "GetObjectMissingBucket", run: (*suite).getObjectMissingBucket},
+	{name: "CopyObject", run: (*suite).copyObject},
+	{name: "ListObjectsDelimiter", run: (*suite).listObjectsDelimiter},
+	{name: "ListObjectsPaging", run: (*suite).listObjectsPaging},
+	{name: "ListObjectsV2Paging", run: (*suite).listObjectsV2Paging},
+	{name: "MultipartUpload", run: (*suite).multipartUpload},
+	{name: "MultipartPartTooSmall", run: (*suite).multipartPartTooSmall},
+	{name: "AbortMultipartUpload", run: (*suite).abortMultipartUpload},
+	{name: "DeleteObjects", run: (*suite).deleteObjects},
+	{name: "DeleteObject", run: (*suite).deleteObject},
+	{name: "SignatureMismatch", run: (*suite).signatureMismatch},
+	{name: "DeleteBucketNotEmpty", run: (*suite).deleteBucketNotEmpty},
+	{name: "DeleteBucket", run: (*suite).deleteBucket, cleanup: true},
+}
+
+// quoteETag - returns the quoted ETag of data as sent by S3.
+func quoteETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (s *suite) makeBucket() error {
+	resp, err := s.client.do(request{method: "PUT", bucket: s.bucket})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	s.created = true
+	return nil
+}
+
+func (s *suite) makeBucketExisting() error {
+	resp, err := s.client.do(request{method: "PUT", bucket: s.bucket})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusConflict, "BucketAlreadyOwnedByYou", "BucketAlreadyExists")
+}
+
+func (s *suite) headBucket() error {
+	resp, err := s.client.do(request{method: "HEAD", bucket: s.bucket})
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusOK)
+}
+
+func (s *suite) headBucketMissing() error {
+	resp, err := s.client.do(request{method: "HEAD", bucket: s.bucket + "-missing"})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusNotFound, "NoSuchBucket")
+}
+
+func (s *suite) listBuckets() error {
+	resp, err := s.client.do(request{method: "GET"})
+	if err != nil {
+		return err
+	}
+	var result listAllMyBucketsResult
+	if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+		return err
+	}
+	for _, bucket := range result.Buckets {
+		if bucket.Name == s.bucket {
+			return nil
+		}
+	}
+	return fmt.Errorf("bucket %s is not listed", s.bucket)
+}
+
+func (s *suite) putObject() error {
+	header := make(http.Header)
+	header.Set("Content-Type", "text/plain")
+	header.Set("X-Amz-Meta-Color", "blue")
+	resp, err := s.client.do(request{method: "PUT", bucket: s.bucket, object: objectName, header: header, body: s.data})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	s.etag = resp.header.Get("ETag")
+	if s.etag != quoteETag(s.data) {
+		return fmt.Errorf("expected ETag %s, got %s", quoteETag(s.data), s.etag)
+	}
+	return nil
+}
+
+func (s *suite) getObject() error {
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: objectName})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if !bytes.Equal(resp.body, s.data) {
+		return fmt.Errorf("expected %d bytes written, got %d different bytes", len(s.data), len(resp.body))
+	}
+	if etag := resp.header.Get("ETag"); etag != s.etag {
+		return fmt.Errorf("expected ETag %s, got %s", s.etag, etag)
+	}
+	if contentType := resp.header.Get("Content-Type"); contentType != "text/plain" {
+		return fmt.Errorf("expected Content-Type text/plain, got %s", contentType)
+	}
+	return nil
+}
+
+func (s *suite) headObject() error {
+	resp, err := s.client.do(request{method: "HEAD", bucket: s.bucket, object: objectName})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if length := resp.header.Get("Content-Length"); length != strconv.Itoa(len(s.data)) {
+		return fmt.Errorf("expected Content-Length %d, got %s", len(s.data), length)
+	}
+	if color := resp.header.Get("X-Amz-Meta-Color"); color != "blue" {
+		return fmt.Errorf("expected metadata X-Amz-Meta-Color blue, got %q", color)
+	}
+	return nil
+}
+
+func (s *suite) getObjectRange() error {
+	header := make(http.Header)
+	header.Set("Range", "bytes=2-5")
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: objectName, header: header})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusPartialContent); err != nil {
+		return err
+	}
+	if !bytes.Equal(resp.body, s.data[2:6]) {
+		return fmt.Errorf("expected bytes %q, got %q", s.data[2:6], resp.body)
+	}
+	if contentRange := resp.header.Get("Content-Range"); contentRange != fmt.Sprintf("bytes 2-5/%d", len(s.data)) {
+		return fmt.Errorf("expected Content-Range bytes 2-5/%d, got %s", len(s.data), contentRange)
+	}
+	return nil
+}
+
+func (s *suite) getObjectInvalidRange() error {
+	header := make(http.Header)
+	header.Set("Range", fmt.Sprintf("bytes=%d-", len(s.data)+10))
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: objectName, header: header})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+}
+
+func (s *suite) getObjectConditional() error {
+	header := make(http.Header)
+	header.Set("If-None-Match", s.etag)
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: objectName, header: header})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusNotModified); err != nil {
+		return fmt.Errorf("If-None-Match: %v", err)
+	}
+	header = make(http.Header)
+	header.Set("If-Match", `"00000000000000000000000000000000"`)
+	resp, err = s.client.do(request{method: "GET", bucket: s.bucket, object: objectName, header: header})
+	if err != nil {
+		return err
+	}
+	if err = expectError(resp, http.StatusPreconditionFailed, "PreconditionFailed"); err != nil {
+		return fmt.Errorf("If-Match: %v", err)
+	}
+	return nil
+}
+
+func (s *suite) getObjectMissing() error {
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: objectName + "-missing"})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusNotFound, "NoSuchKey")
+}
+
+func (s *suite) getObjectMissingBucket() error {
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket + "-missing", object: objectName})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusNotFound, "NoSuchBucket")
+}
+
+func (s *suite) copyObject() error {
+	header := make(http.Header)
+	header.Set("X-Amz-Copy-Source", "/"+s.bucket+"/"+objectName)
+	resp, err := s.client.do(request{method: "PUT", bucket: s.bucket, object: copyObjectName, header: header})
+	if err != nil {
+		return err
+	}
+	var result etagResult
+	if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+		return err
+	}
+	if result.ETag != s.etag {
+		return fmt.Errorf("expected ETag %s, got %s", s.etag, result.ETag)
+	}
+	resp, err = s.client.do(request{method: "GET", bucket: s.bucket, object: copyObjectName})
+	if err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if !bytes.Equal(resp.body, s.data) {
+		return fmt.Errorf("copy differs from the source object")
+	}
+	return nil
+}
+
+// putDirObjects - writes the objects listed under the dir/ prefix.
+func (s *suite) putDirObjects() error {
+	for _, object := range []string{dirObjectA, dirObjectB} {
+		resp, err := s.client.do(request{method: "PUT", bucket: s.bucket, object: object, body: []byte(object)})
+		if err != nil {
+			return err
+		}
+		if err = expectStatus(resp, http.StatusOK); err != nil {
+			return fmt.Errorf("writing %s: %v", object, err)
+		}
+	}
+	return nil
+}
+
+func (s *suite) listObjectsDelimiter() error {
+	if err := s.putDirObjects(); err != nil {
+		return err
+	}
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, query: url.Values{"delimiter": {"/"}}})
+	if err != nil {
+		return err
+	}
+	var result listBucketResult
+	if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+		return err
+	}
+	var keys, prefixes []string
+	for _, object := range result.Contents {
+		keys = append(keys, object.Key)
+	}
+	for _, prefix := range result.CommonPrefixes {
+		prefixes = append(prefixes, prefix.Prefix)
+	}
+	if expected := []string{objectName, copyObjectName}; !reflect.DeepEqual(keys, expected) {
+		return fmt.Errorf("expected objects %v, got %v", expected, keys)
+	}
+	if expected := []string{"dir/"}; !reflect.DeepEqual(prefixes, expected) {
+		return fmt.Errorf("expected prefixes %v, got %v", expected, prefixes)
+	}
+	return nil
+}
+
+// listAllObjects - lists the bucket one object per page, with markers
+// if v2 is false or continuation tokens otherwise.
+func (s *suite) listAllObjects(v2 bool) ([]string, error) {
+	var keys []string
+	var next string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			return nil, fmt.Errorf("listing did not end after %d pages", pages)
+		}
+		query := url.Values{"max-keys": {"1"}}
+		if v2 {
+			query.Set("list-type", "2")
+			if next != "" {
+				query.Set("continuation-token", next)
+			}
+		} else if next != "" {
+			query.Set("marker", next)
+		}
+		resp, err := s.client.do(request{method: "GET", bucket: s.bucket, query: query})
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+			return nil, err
+		}
+		if len(result.Contents) > 1 {
+			return nil, fmt.Errorf("expected at most one object per page, got %d", len(result.Contents))
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		switch {
+		case v2:
+			next = result.NextContinuationToken
+		case result.NextMarker != "":
+			next = result.NextMarker
+		case len(result.Contents) > 0:
+			next = result.Contents[0].Key
+		default:
+			return nil, fmt.Errorf("truncated listing without a marker")
+		}
+	}
+}
+
+func (s *suite) listObjectsPaging() error {
+	keys, err := s.listAllObjects(false)
+	if err != nil {
+		return err
+	}
+	if expected := []string{dirObjectA, dirObjectB, objectName, copyObjectName}; !reflect.DeepEqual(keys, expected) {
+		return fmt.Errorf("expected objects %v, got %v", expected, keys)
+	}
+	return nil
+}
+
+func (s *suite) listObjectsV2Paging() error {
+	keys, err := s.listAllObjects(true)
+	if err != nil {
+		return err
+	}
+	if expected := []string{dirObjectA, dirObjectB, objectName, copyObjectName}; !reflect.DeepEqual(keys, expected) {
+		return fmt.Errorf("expected objects %v, got %v", expected, keys)
+	}
+	return nil
+}
+
+// newMultipartUpload - initiates a multipart upload of object.
+func (s *suite) newMultipartUpload(object string) (string, error) {
+	resp, err := s.client.do(request{method: "POST", bucket: s.bucket, object: object, query: url.Values{"uploads": {""}}})
+	if err != nil {
+		return "", err
+	}
+	var result initiateMultipartUploadResult
+	if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+		return "", err
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("no upload id returned")
+	}
+	return result.UploadID, nil
+}
+
+// putObjectParts - uploads parts in order and returns them for completion.
+func (s *suite) putObjectParts(object, uploadID string, parts ...[]byte) ([]completePart, error) {
+	var completed []completePart
+	for i, part := range parts {
+		query := url.Values{"partNumber": {strconv.Itoa(i + 1)}, "uploadId": {uploadID}}
+		resp, err := s.client.do(request{method: "PUT", bucket: s.bucket, object: object, query: query, body: part})
+		if err != nil {
+			return nil, err
+		}
+		if err = expectStatus(resp, http.StatusOK); err != nil {
+			return nil, fmt.Errorf("part %d: %v", i+1, err)
+		}
+		completed = append(completed, completePart{i + 1, resp.header.Get("ETag")})
+	}
+	return completed, nil
+}
+
+// completeMultipartUpload - sends the completion of uploadID.
+func (s *suite) completeMultipartUpload(object, uploadID string, parts []completePart) (response, error) {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return response{}, err
+	}
+	return s.client.do(request{method: "POST", bucket: s.bucket, object: object, query: url.Values{"uploadId": {uploadID}}, body: body})
+}
+
+// abortMultipartUploadID - aborts uploadID.
+func (s *suite) abortMultipartUploadID(object, uploadID string) error {
+	resp, err := s.client.do(request{method: "DELETE", bucket: s.bucket, object: object, query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusNoContent)
+}
+
+func (s *suite) multipartUpload() error {
+	uploadID, err := s.newMultipartUpload(multipartName)
+	if err != nil {
+		return err
+	}
+	first, last := bytes.Repeat([]byte("a"), minPartSize), []byte("last part")
+	parts, err := s.putObjectParts(multipartName, uploadID, first, last)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: multipartName, query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		return err
+	}
+	var listed listPartsResult
+	if err = decodeResponse(resp, http.StatusOK, &listed); err != nil {
+		return fmt.Errorf("listing parts: %v", err)
+	}
+	if len(listed.Parts) != 2 || listed.Parts[0].Size != minPartSize || listed.Parts[1].Size != int64(len(last)) {
+		return fmt.Errorf("expected parts of %d and %d bytes, got %+v", minPartSize, len(last), listed.Parts)
+	}
+	if resp, err = s.completeMultipartUpload(multipartName, uploadID, parts); err != nil {
+		return err
+	}
+	var completed etagResult
+	if err = decodeResponse(resp, http.StatusOK, &completed); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(strings.Trim(completed.ETag, `"`), "-2") {
+		return fmt.Errorf("expected a multipart ETag ending in -2, got %s", completed.ETag)
+	}
+	if resp, err = s.client.do(request{method: "HEAD", bucket: s.bucket, object: multipartName}); err != nil {
+		return err
+	}
+	if err = expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if length := resp.header.Get("Content-Length"); length != strconv.Itoa(len(first)+len(last)) {
+		return fmt.Errorf("expected Content-Length %d, got %s", len(first)+len(last), length)
+	}
+	return nil
+}
+
+func (s *suite) multipartPartTooSmall() error {
+	uploadID, err := s.newMultipartUpload(multipartName + "-small")
+	if err != nil {
+		return err
+	}
+	defer s.abortMultipartUploadID(multipartName+"-small", uploadID)
+
+	parts, err := s.putObjectParts(multipartName+"-small", uploadID, []byte("first part"), []byte("last part"))
+	if err != nil {
+		return err
+	}
+	resp, err := s.completeMultipartUpload(multipartName+"-small", uploadID, parts)
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusBadRequest, "EntityTooSmall")
+}
+
+func (s *suite) abortMultipartUpload() error {
+	uploadID, err := s.newMultipartUpload(multipartName + "-aborted")
+	if err != nil {
+		return err
+	}
+	if err = s.abortMultipartUploadID(multipartName+"-aborted", uploadID); err != nil {
+		return err
+	}
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket, object: multipartName + "-aborted", query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusNotFound, "NoSuchUpload")
+}
+
+// deleteObjectList - sends a multiple objects delete of objects.
+func (s *suite) deleteObjectList(objects []string) (deleteObjectsResult, error) {
+	var del deleteObjectsRequest
+	for _, object := range objects {
+		del.Objects = append(del.Objects, struct{ Key string }{object})
+	}
+	body, err := xml.Marshal(del)
+	if err != nil {
+		return deleteObjectsResult{}, err
+	}
+	sum := md5.Sum(body)
+	header := make(http.Header)
+	header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	resp, err := s.client.do(request{method: "POST", bucket: s.bucket, query: url.Values{"delete": {""}}, header: header, body: body})
+	if err != nil {
+		return deleteObjectsResult{}, err
+	}
+	var result deleteObjectsResult
+	err = decodeResponse(resp, http.StatusOK, &result)
+	return result, err
+}
+
+func (s *suite) deleteObjects() error {
+	result, err := s.deleteObjectList([]string{dirObjectA, dirObjectB})
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("expected no errors, got %+v", result.Errors)
+	}
+	if len(result.Deleted) != 2 {
+		return fmt.Errorf("expected 2 deleted objects, got %+v", result.Deleted)
+	}
+	resp, err := s.client.do(request{method: "HEAD", bucket: s.bucket, object: dirObjectA})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusNotFound, "NoSuchKey")
+}
+
+func (s *suite) deleteObject() error {
+	for i := 0; i < 2; i++ {
+		// Deleting a missing object succeeds as well.
+		resp, err := s.client.do(request{method: "DELETE", bucket: s.bucket, object: copyObjectName})
+		if err != nil {
+			return err
+		}
+		if err = expectStatus(resp, http.StatusNoContent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *suite) signatureMismatch() error {
+	badClient := *s.client
+	badClient.secretAccessKey += "-invalid"
+	resp, err := badClient.do(request{method: "GET", bucket: s.bucket, object: objectName})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusForbidden, "SignatureDoesNotMatch")
+}
+
+func (s *suite) deleteBucketNotEmpty() error {
+	resp, err := s.client.do(request{method: "DELETE", bucket: s.bucket})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, http.StatusConflict, "BucketNotEmpty")
+}
+
+// deleteBucket - deletes the objects left by the checks and the bucket.
+func (s *suite) deleteBucket() error {
+	resp, err := s.client.do(request{method: "GET", bucket: s.bucket})
+	if err != nil {
+		return err
+	}
+	var result listBucketResult
+	if err = decodeResponse(resp, http.StatusOK, &result); err != nil {
+		return err
+	}
+	if len(result.Contents) > 0 {
+		var objects []string
+		for _, object := range result.Contents {
+			objects = append(objects, object.Key)
+		}
+		if _, err = s.deleteObjectList(objects); err != nil {
+			return err
+		}
+	}
+	if resp, err = s.client.do(request{method: "DELETE", bucket: s.bucket}); err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3compat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio/pkg/s3signer"
+)
+
+// errorResponse - error document returned by S3 for failed requests.
+type errorResponse struct {
+	Code    string
+	Message string
+}
+
+// client - minimal S3 client signing every request with signature v4,
+// responses are returned as is so that checks can inspect them.
+type client struct {
+	endpointURL     url.URL
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+	httpClient      *http.Client
+}
+
+// request - values of a request to send.
+type request struct {
+	method string
+	bucket string
+	object string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// response - status, headers and the complete body of a response.
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// newRequest - returns the signed HTTP request of req.
+func (c *client) newRequest(req request) (*http.Request, error) {
+	targetURL := c.endpointURL
+	targetURL.Path = "/"
+	if req.bucket != "" {
+		targetURL.Path += req.bucket
+		if req.object != "" {
+			targetURL.Path += "/" + req.object
+		}
+	}
+	if len(req.query) > 0 {
+		targetURL.RawQuery = req.query.Encode()
+	}
+	httpReq, err := http.NewRequest(req.method, targetURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	sum := sha256.Sum256(req.body)
+	httpReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	if len(req.body) > 0 {
+		httpReq.Body = ioutil.NopCloser(bytes.NewReader(req.body))
+		httpReq.ContentLength = int64(len(req.body))
+	}
+	return s3signer.SignV4(httpReq, c.accessKeyID, c.secretAccessKey, c.region, time.Now().UTC()), nil
+}
+
+// do - sends req and reads its complete response.
+func (c *client) do(req request) (response, error) {
+	httpReq, err := c.newRequest(req)
+	if err != nil {
+		return response{}, err
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, err
+	}
+	return response{resp.StatusCode, resp.Header, body}, nil
+}
+
+// expectStatus - returns an error unless resp has statusCode.
+func expectStatus(resp response, statusCode int) error {
+	if resp.statusCode == statusCode {
+		return nil
+	}
+	var errResp errorResponse
+	if xml.Unmarshal(resp.body, &errResp) == nil && errResp.Code != "" {
+		return fmt.Errorf("expected status %d, got %d %s: %s", statusCode, resp.statusCode, errResp.Code, errResp.Message)
+	}
+	return fmt.Errorf("expected status %d, got %d", statusCode, resp.statusCode)
+}
+
+// expectError - returns an error unless resp is an error document with
+// statusCode and one of codes.
+func expectError(resp response, statusCode int, codes ...string) error {
+	if resp.statusCode != statusCode {
+		return fmt.Errorf("expected status %d with %v, got %d", statusCode, codes, resp.statusCode)
+	}
+	if len(resp.body) == 0 {
+		// Responses to HEAD requests carry no error document.
+		return nil
+	}
+	var errResp errorResponse
+	if err := xml.Unmarshal(resp.body, &errResp); err != nil {
+		return fmt.Errorf("expected an error document with %v: %v", codes, err)
+	}
+	for _, code := range codes {
+		if errResp.Code == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected error %v, got %s", codes, errResp.Code)
+}
+
+// decodeResponse - decodes the XML body of a successful resp into v.
+func decodeResponse(resp response, statusCode int, v interface{}) error {
+	if err := expectStatus(resp, statusCode); err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(resp.body, v); err != nil {
+		return fmt.Errorf("invalid response document: %v", err)
+	}
+	return nil
+}
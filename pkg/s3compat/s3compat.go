@@ -0,0 +1,228 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package s3compat verifies that an endpoint behaves like Amazon S3.
+// It runs a fixed set of checks against a bucket it creates and
+// deletes, and reports which behaviors pass.
+//
+// From a Go test:
+//
+//	func TestCompatibility(t *testing.T) {
+//		s3compat.RunTest(t, s3compat.Config{
+//			Endpoint:  server.URL,
+//			AccessKey: accessKey,
+//			SecretKey: secretKey,
+//		})
+//	}
+//
+// From the command line:
+//
+//	$ minio compat http://localhost:9000
+package s3compat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Default region used to sign requests.
+const defaultRegion = "us-east-1"
+
+// Config - endpoint to verify and the credentials to use.
+type Config struct {
+	// URL of the endpoint, such as http://localhost:9000.
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	// Region of the endpoint, "us-east-1" if empty.
+	Region string
+	// Transport used to send requests, http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// Result - outcome of a single check.
+type Result struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report - outcome of all checks against an endpoint.
+type Report struct {
+	Endpoint string   `json:"endpoint"`
+	Bucket   string   `json:"bucket"`
+	Results  []Result `json:"results"`
+}
+
+// Counts - returns the number of passed, failed and skipped checks.
+func (r Report) Counts() (passed, failed, skipped int) {
+	for _, result := range r.Results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Passed:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Compatible - returns true if every check passed.
+func (r Report) Compatible() bool {
+	_, failed, skipped := r.Counts()
+	return failed == 0 && skipped == 0
+}
+
+// WriteTo - writes the report as text, one line per check followed by
+// a summary.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	write := func(format string, args ...interface{}) error {
+		n, err := fmt.Fprintf(w, format, args...)
+		written += int64(n)
+		return err
+	}
+	for _, result := range r.Results {
+		var err error
+		switch {
+		case result.Skipped:
+			err = write("SKIP  %s\n", result.Name)
+		case result.Passed:
+			err = write("PASS  %s (%s)\n", result.Name, result.Duration)
+		default:
+			err = write("FAIL  %s (%s): %s\n", result.Name, result.Duration, result.Error)
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	passed, failed, skipped := r.Counts()
+	err := write("%d passed, %d failed, %d skipped against %s\n", passed, failed, skipped, r.Endpoint)
+	return written, err
+}
+
+// newClient - returns a client for config.
+func newClient(config Config) (*client, error) {
+	endpointURL, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" || endpointURL.Host == "" {
+		return nil, errors.New("Endpoint should be a URL such as http://localhost:9000")
+	}
+	if endpointURL.Path != "" && endpointURL.Path != "/" {
+		return nil, errors.New("Endpoint should not have a path")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("Both access and secret keys must be set")
+	}
+	region := config.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &client{
+		endpointURL:     *endpointURL,
+		accessKeyID:     config.AccessKey,
+		secretAccessKey: config.SecretKey,
+		region:          region,
+		httpClient:      &http.Client{Transport: transport},
+	}, nil
+}
+
+// newBucketName - returns a random bucket name unlikely to exist.
+func newBucketName() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "s3compat-" + hex.EncodeToString(b[:]), nil
+}
+
+// Run - runs all checks against the endpoint of config, in a bucket
+// deleted at the end. An error is returned only if config is invalid.
+func Run(config Config) (Report, error) {
+	c, err := newClient(config)
+	if err != nil {
+		return Report{}, err
+	}
+	bucket, err := newBucketName()
+	if err != nil {
+		return Report{}, err
+	}
+	s := &suite{
+		client: c,
+		bucket: bucket,
+		data:   []byte("The quick brown fox jumps over the lazy dog."),
+	}
+	report := Report{Endpoint: config.Endpoint, Bucket: bucket}
+	var skip bool
+	for _, check := range checks {
+		if skip && !(check.cleanup && s.created) {
+			report.Results = append(report.Results, Result{Name: check.name, Skipped: true})
+			continue
+		}
+		start := time.Now()
+		err := check.run(s)
+		result := Result{Name: check.name, Passed: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			result.Error = err.Error()
+			if check.required {
+				skip = true
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// TestErrHandler - the subset of testing.TB used by RunTest.
+type TestErrHandler interface {
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// RunTest - runs all checks against the endpoint of config and fails t
+// for every check that does not pass.
+func RunTest(t TestErrHandler, config Config) Report {
+	report, err := Run(config)
+	if err != nil {
+		t.Fatalf("Invalid compatibility suite config: %v", err)
+	}
+	for _, result := range report.Results {
+		switch {
+		case result.Skipped:
+			t.Errorf("%s: skipped after a required check failed", result.Name)
+		case !result.Passed:
+			t.Errorf("%s: %s", result.Name, result.Error)
+		}
+	}
+	return report
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3compat
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests validation of the endpoint and credentials.
+func TestNewClient(t *testing.T) {
+	testCases := []struct {
+		config  Config
+		success bool
+	}{
+		{Config{Endpoint: "http://localhost:9000", AccessKey: "minio", SecretKey: "miniostorage"}, true},
+		{Config{Endpoint: "https://localhost:9000/", AccessKey: "minio", SecretKey: "miniostorage"}, true},
+		{Config{Endpoint: "localhost:9000", AccessKey: "minio", SecretKey: "miniostorage"}, false},
+		{Config{Endpoint: "http://localhost:9000/bucket", AccessKey: "minio", SecretKey: "miniostorage"}, false},
+		{Config{Endpoint: "http://localhost:9000", AccessKey: "minio"}, false},
+	}
+	for i, testCase := range testCases {
+		_, err := newClient(testCase.config)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %v, got %v", i+1, testCase.success, err)
+		}
+	}
+}
+
+// Tests that checks after a failed required check are skipped, except
+// the bucket cleanup.
+func TestRunSkipsAfterRequiredCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Buckets are created, objects are rejected.
+		if r.Method == "PUT" && strings.Count(r.URL.Path, "/") == 1 {
+			return
+		}
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer server.Close()
+
+	report, err := Run(Config{Endpoint: server.URL, AccessKey: "minio", SecretKey: "miniostorage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Compatible() {
+		t.Fatal("Expected an incompatible report")
+	}
+	for _, result := range report.Results {
+		var skipped bool
+		switch result.Name {
+		case "MakeBucket", "MakeBucketExisting", "HeadBucket", "HeadBucketMissing", "ListBuckets", "PutObject", "DeleteBucket":
+		default:
+			skipped = true
+		}
+		if result.Skipped != skipped {
+			t.Errorf("%s: Expected skipped %v, got %v", result.Name, skipped, result.Skipped)
+		}
+	}
+	if !report.Results[0].Passed {
+		t.Errorf("Expected MakeBucket to pass, got %s", report.Results[0].Error)
+	}
+
+	var buf bytes.Buffer
+	if _, err = report.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "FAIL  PutObject") || !strings.Contains(buf.String(), "SKIP  GetObject") {
+		t.Errorf("Unexpected report:\n%s", buf.String())
+	}
+}
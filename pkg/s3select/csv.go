@@ -0,0 +1,276 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3select
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Valid values of CSV FileHeaderInfo.
+const (
+	fileHeaderUse    = "USE"
+	fileHeaderIgnore = "IGNORE"
+	fileHeaderNone   = "NONE"
+)
+
+// Valid values of CSV QuoteFields.
+const (
+	quoteFieldsAlways   = "ALWAYS"
+	quoteFieldsAsNeeded = "ASNEEDED"
+)
+
+// csvRecord - a single CSV row, fields are addressed by header name
+// or by position as _1, _2...
+type csvRecord struct {
+	reader *csvReader
+	values []string
+}
+
+func (r *csvRecord) get(path []pathElem) interface{} {
+	if len(path) != 1 || path[0].index >= 0 {
+		return nil
+	}
+	name := path[0].key
+	if idx, ok := r.reader.header[name]; ok && idx < len(r.values) {
+		return r.values[idx]
+	}
+	if idx, ok := r.reader.headerFold[strings.ToLower(name)]; ok && idx < len(r.values) {
+		return r.values[idx]
+	}
+	if strings.HasPrefix(name, "_") {
+		if pos, err := strconv.Atoi(name[1:]); err == nil && pos >= 1 && pos <= len(r.values) {
+			return r.values[pos-1]
+		}
+	}
+	return nil
+}
+
+func (r *csvRecord) fields() ([]string, []interface{}) {
+	names := make([]string, len(r.values))
+	values := make([]interface{}, len(r.values))
+	for i, field := range r.values {
+		if i < len(r.reader.headerNames) {
+			names[i] = r.reader.headerNames[i]
+		} else {
+			names[i] = "_" + strconv.Itoa(i+1)
+		}
+		values[i] = field
+	}
+	return names, values
+}
+
+// csvReader - reads CSV records from the object.
+type csvReader struct {
+	reader         *csv.Reader
+	fileHeaderInfo string
+	headerRead     bool
+	headerNames    []string
+	header         map[string]int
+	headerFold     map[string]int
+
+	// Set when a custom single byte record delimiter is swapped with
+	// newlines on input, the swap is reverted on every field.
+	swappedDelimiter byte
+}
+
+// newCSVReader - validates the CSV input serialization and returns a
+// reader of records from input.
+func newCSVReader(input io.Reader, args *CSVInput) (*csvReader, error) {
+	fileHeaderInfo := strings.ToUpper(args.FileHeaderInfo)
+	switch fileHeaderInfo {
+	case "":
+		fileHeaderInfo = fileHeaderNone
+	case fileHeaderUse, fileHeaderIgnore, fileHeaderNone:
+	default:
+		return nil, errInvalidFileHeaderInfo
+	}
+
+	if args.QuoteCharacter != "" && args.QuoteCharacter != `"` {
+		return nil, errInvalidRequestParameter("only '\"' is supported as QuoteCharacter")
+	}
+	if args.QuoteEscapeCharacter != "" && args.QuoteEscapeCharacter != `"` {
+		return nil, errInvalidRequestParameter("only '\"' is supported as QuoteEscapeCharacter")
+	}
+
+	r := &csvReader{
+		fileHeaderInfo: fileHeaderInfo,
+		header:         make(map[string]int),
+		headerFold:     make(map[string]int),
+	}
+	switch args.RecordDelimiter {
+	case "", "\n", "\r\n":
+	default:
+		if len(args.RecordDelimiter) != 1 {
+			return nil, errInvalidRequestParameter("RecordDelimiter must be a single character or \\r\\n")
+		}
+		r.swappedDelimiter = args.RecordDelimiter[0]
+		input = &swapReader{input, r.swappedDelimiter, '\n'}
+	}
+
+	r.reader = csv.NewReader(input)
+	r.reader.FieldsPerRecord = -1
+	if args.FieldDelimiter != "" {
+		comma, size := utf8.DecodeRuneInString(args.FieldDelimiter)
+		if size != len(args.FieldDelimiter) || comma == '"' || comma == '\n' || comma == '\r' {
+			return nil, errInvalidRequestParameter("FieldDelimiter must be a single character")
+		}
+		r.reader.Comma = comma
+	}
+	if args.Comments != "" {
+		comment, size := utf8.DecodeRuneInString(args.Comments)
+		if size != len(args.Comments) || comment == r.reader.Comma {
+			return nil, errInvalidRequestParameter("Comments must be a single character")
+		}
+		r.reader.Comment = comment
+	}
+	return r, nil
+}
+
+// read - returns the next record, io.EOF at the end of input.
+func (r *csvReader) read() (record, error) {
+	if !r.headerRead {
+		r.headerRead = true
+		if r.fileHeaderInfo != fileHeaderNone {
+			header, err := r.readFields()
+			if err != nil {
+				return nil, err
+			}
+			if r.fileHeaderInfo == fileHeaderUse {
+				r.headerNames = header
+				for i, name := range header {
+					r.header[name] = i
+					r.headerFold[strings.ToLower(name)] = i
+				}
+			}
+		}
+	}
+	fields, err := r.readFields()
+	if err != nil {
+		return nil, err
+	}
+	return &csvRecord{reader: r, values: fields}, nil
+}
+
+// readFields - reads the next row of fields.
+func (r *csvReader) readFields() ([]string, error) {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return nil, &Error{"CSVParsingError", parseErr.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.swappedDelimiter != 0 {
+		for i, field := range fields {
+			fields[i] = string(swapBytes([]byte(field), r.swappedDelimiter, '\n'))
+		}
+	}
+	return fields, nil
+}
+
+// swapReader - swaps two bytes in the underlying stream.
+type swapReader struct {
+	reader io.Reader
+	a, b   byte
+}
+
+func (s *swapReader) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	swapBytes(p[:n], s.a, s.b)
+	return n, err
+}
+
+// swapBytes - swaps all occurrences of a and b in p in place.
+func swapBytes(p []byte, a, b byte) []byte {
+	for i, c := range p {
+		switch c {
+		case a:
+			p[i] = b
+		case b:
+			p[i] = a
+		}
+	}
+	return p
+}
+
+// csvWriter - formats output records as CSV.
+type csvWriter struct {
+	fieldDelimiter  string
+	recordDelimiter string
+	quoteAlways     bool
+}
+
+// newCSVWriter - validates the CSV output serialization.
+func newCSVWriter(args *CSVOutput) (*csvWriter, error) {
+	w := &csvWriter{
+		fieldDelimiter:  ",",
+		recordDelimiter: "\n",
+	}
+	switch strings.ToUpper(args.QuoteFields) {
+	case "", quoteFieldsAsNeeded:
+	case quoteFieldsAlways:
+		w.quoteAlways = true
+	default:
+		return nil, errInvalidQuoteFields
+	}
+	if args.QuoteCharacter != "" && args.QuoteCharacter != `"` {
+		return nil, errInvalidRequestParameter("only '\"' is supported as QuoteCharacter")
+	}
+	if args.QuoteEscapeCharacter != "" && args.QuoteEscapeCharacter != `"` {
+		return nil, errInvalidRequestParameter("only '\"' is supported as QuoteEscapeCharacter")
+	}
+	if args.FieldDelimiter != "" {
+		w.fieldDelimiter = args.FieldDelimiter
+	}
+	if args.RecordDelimiter != "" {
+		w.recordDelimiter = args.RecordDelimiter
+	}
+	return w, nil
+}
+
+// writeRecord - appends a single record to buf.
+func (w *csvWriter) writeRecord(buf *bytes.Buffer, names []string, values []interface{}) {
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(w.fieldDelimiter)
+		}
+		field := fmtValue(v)
+		if !w.quoteAlways && !w.needsQuotes(field) {
+			buf.WriteString(field)
+			continue
+		}
+		buf.WriteByte('"')
+		buf.WriteString(strings.Replace(field, `"`, `""`, -1))
+		buf.WriteByte('"')
+	}
+	buf.WriteString(w.recordDelimiter)
+}
+
+// needsQuotes - returns true if field must be quoted to be read back.
+func (w *csvWriter) needsQuotes(field string) bool {
+	return strings.Contains(field, w.fieldDelimiter) ||
+		strings.Contains(field, w.recordDelimiter) ||
+		strings.ContainsAny(field, "\"\r\n")
+}
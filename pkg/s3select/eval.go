@@ -0,0 +1,443 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3select
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// expr - an expression evaluated against a single record. Values are
+// one of nil (NULL), string, float64 or bool.
+type expr interface {
+	eval(rec record) interface{}
+}
+
+// literal - a constant value.
+type literal struct {
+	val interface{}
+}
+
+func (l *literal) eval(rec record) interface{} {
+	return l.val
+}
+
+// columnRef - a reference to a field of the record.
+type columnRef struct {
+	path []pathElem
+}
+
+func (c *columnRef) eval(rec record) interface{} {
+	return rec.get(c.path)
+}
+
+// logicalExpr - AND, OR with SQL three valued logic.
+type logicalExpr struct {
+	op          string
+	left, right expr
+}
+
+func (l *logicalExpr) eval(rec record) interface{} {
+	left, lok := l.left.eval(rec).(bool)
+	right, rok := l.right.eval(rec).(bool)
+	if l.op == "AND" {
+		if (lok && !left) || (rok && !right) {
+			return false
+		}
+		if lok && rok {
+			return true
+		}
+		return nil
+	}
+	if (lok && left) || (rok && right) {
+		return true
+	}
+	if lok && rok {
+		return false
+	}
+	return nil
+}
+
+// notExpr - logical negation.
+type notExpr struct {
+	e expr
+}
+
+func (n *notExpr) eval(rec record) interface{} {
+	if b, ok := n.e.eval(rec).(bool); ok {
+		return !b
+	}
+	return nil
+}
+
+// compareExpr - binary comparison.
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (c *compareExpr) eval(rec record) interface{} {
+	cmp, ok := compareValues(c.left.eval(rec), c.right.eval(rec))
+	if !ok {
+		return nil
+	}
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return nil
+}
+
+// isNullExpr - IS [NOT] NULL.
+type isNullExpr struct {
+	e      expr
+	negate bool
+}
+
+func (i *isNullExpr) eval(rec record) interface{} {
+	return (i.e.eval(rec) == nil) != i.negate
+}
+
+// likeExpr - [NOT] LIKE with % and _ wildcards.
+type likeExpr struct {
+	e, pattern expr
+	negate     bool
+}
+
+func (l *likeExpr) eval(rec record) interface{} {
+	v, pattern := l.e.eval(rec), l.pattern.eval(rec)
+	if v == nil || pattern == nil {
+		return nil
+	}
+	return likeMatch(fmtValue(v), fmtValue(pattern)) != l.negate
+}
+
+// likeMatch - matches s against a LIKE pattern.
+func likeMatch(s, pattern string) bool {
+	if pattern == "" {
+		return s == ""
+	}
+	r, size := utf8.DecodeRuneInString(pattern)
+	switch r {
+	case '%':
+		for i := 0; i <= len(s); {
+			if likeMatch(s[i:], pattern[size:]) {
+				return true
+			}
+			if i == len(s) {
+				break
+			}
+			_, n := utf8.DecodeRuneInString(s[i:])
+			i += n
+		}
+		return false
+	case '_':
+		if s == "" {
+			return false
+		}
+		_, n := utf8.DecodeRuneInString(s)
+		return likeMatch(s[n:], pattern[size:])
+	}
+	if !strings.HasPrefix(s, string(r)) {
+		return false
+	}
+	return likeMatch(s[size:], pattern[size:])
+}
+
+// betweenExpr - [NOT] BETWEEN low AND high.
+type betweenExpr struct {
+	e, low, high expr
+	negate       bool
+}
+
+func (b *betweenExpr) eval(rec record) interface{} {
+	v := b.e.eval(rec)
+	lcmp, lok := compareValues(v, b.low.eval(rec))
+	hcmp, hok := compareValues(v, b.high.eval(rec))
+	if !lok || !hok {
+		return nil
+	}
+	return (lcmp >= 0 && hcmp <= 0) != b.negate
+}
+
+// inExpr - [NOT] IN (list).
+type inExpr struct {
+	e      expr
+	list   []expr
+	negate bool
+}
+
+func (i *inExpr) eval(rec record) interface{} {
+	v := i.e.eval(rec)
+	if v == nil {
+		return nil
+	}
+	for _, e := range i.list {
+		if cmp, ok := compareValues(v, e.eval(rec)); ok && cmp == 0 {
+			return !i.negate
+		}
+	}
+	return i.negate
+}
+
+// arithExpr - binary arithmetic, non numeric operands yield NULL.
+type arithExpr struct {
+	op          string
+	left, right expr
+}
+
+func (a *arithExpr) eval(rec record) interface{} {
+	left, lok := toNumber(a.left.eval(rec))
+	right, rok := toNumber(a.right.eval(rec))
+	if !lok || !rok {
+		return nil
+	}
+	switch a.op {
+	case "+":
+		return left + right
+	case "-":
+		return left - right
+	case "*":
+		return left * right
+	case "/":
+		if right == 0 {
+			return nil
+		}
+		return left / right
+	case "%":
+		if right == 0 {
+			return nil
+		}
+		return math.Mod(left, right)
+	}
+	return nil
+}
+
+// castExpr - CAST(expr AS type).
+type castExpr struct {
+	e   expr
+	typ string
+}
+
+func (c *castExpr) eval(rec record) interface{} {
+	v := c.e.eval(rec)
+	if v == nil {
+		return nil
+	}
+	switch c.typ {
+	case "INT", "INTEGER":
+		if f, ok := toNumber(v); ok {
+			return math.Trunc(f)
+		}
+		return nil
+	case "FLOAT", "DECIMAL", "NUMERIC":
+		if f, ok := toNumber(v); ok {
+			return f
+		}
+		return nil
+	case "BOOL", "BOOLEAN":
+		if b, ok := v.(bool); ok {
+			return b
+		}
+		if b, err := strconv.ParseBool(fmtValue(v)); err == nil {
+			return b
+		}
+		return nil
+	}
+	return fmtValue(v)
+}
+
+// aggregateExpr - an aggregate function, accumulated over all the
+// matching records by update, eval returns the current result.
+type aggregateExpr struct {
+	name  string
+	arg   expr // nil for COUNT(*).
+	count int64
+	sum   float64
+	value interface{} // Current MIN or MAX.
+}
+
+// Supported aggregate functions.
+var aggregateFuncs = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// update - accumulates rec into the aggregate, NULL values are skipped.
+func (a *aggregateExpr) update(rec record) {
+	if a.arg == nil {
+		a.count++
+		return
+	}
+	v := a.arg.eval(rec)
+	if v == nil {
+		return
+	}
+	switch a.name {
+	case "COUNT":
+		a.count++
+	case "SUM", "AVG":
+		if f, ok := toNumber(v); ok {
+			a.count++
+			a.sum += f
+		}
+	case "MIN", "MAX":
+		if a.value == nil {
+			a.value = v
+			return
+		}
+		if cmp, ok := compareValues(v, a.value); ok && ((a.name == "MIN" && cmp < 0) || (a.name == "MAX" && cmp > 0)) {
+			a.value = v
+		}
+	}
+}
+
+func (a *aggregateExpr) eval(rec record) interface{} {
+	switch a.name {
+	case "COUNT":
+		return float64(a.count)
+	case "SUM":
+		if a.count == 0 {
+			return nil
+		}
+		return a.sum
+	case "AVG":
+		if a.count == 0 {
+			return nil
+		}
+		return a.sum / float64(a.count)
+	}
+	return a.value
+}
+
+// scalarFunc - a function applied to the values of a single record.
+type scalarFunc struct {
+	nargs int
+	fn    func(args []interface{}) interface{}
+}
+
+// Scalar functions supported in select expressions.
+var scalarFuncs = map[string]scalarFunc{
+	"LOWER": {1, func(args []interface{}) interface{} {
+		if args[0] == nil {
+			return nil
+		}
+		return strings.ToLower(fmtValue(args[0]))
+	}},
+	"UPPER": {1, func(args []interface{}) interface{} {
+		if args[0] == nil {
+			return nil
+		}
+		return strings.ToUpper(fmtValue(args[0]))
+	}},
+	"TRIM": {1, func(args []interface{}) interface{} {
+		if args[0] == nil {
+			return nil
+		}
+		return strings.TrimSpace(fmtValue(args[0]))
+	}},
+	"CHAR_LENGTH": {1, func(args []interface{}) interface{} {
+		if args[0] == nil {
+			return nil
+		}
+		return float64(utf8.RuneCountInString(fmtValue(args[0])))
+	}},
+}
+
+// funcExpr - a scalar function call.
+type funcExpr struct {
+	name string
+	fn   func(args []interface{}) interface{}
+	args []expr
+}
+
+func (f *funcExpr) eval(rec record) interface{} {
+	args := make([]interface{}, len(f.args))
+	for i, arg := range f.args {
+		args[i] = arg.eval(rec)
+	}
+	return f.fn(args)
+}
+
+// toNumber - converts v to a number if possible.
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// compareValues - compares two values, numerically when both are
+// numbers, returns false if either is NULL or they are not comparable.
+func compareValues(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if ab, ok := a.(bool); ok {
+		bb, ok := b.(bool)
+		if !ok {
+			if parsed, err := strconv.ParseBool(fmtValue(b)); err == nil {
+				bb, ok = parsed, true
+			}
+		}
+		if !ok {
+			return 0, false
+		}
+		if ab == bb {
+			return 0, true
+		}
+		if !ab {
+			return -1, true
+		}
+		return 1, true
+	}
+	if _, ok := b.(bool); ok {
+		cmp, ok := compareValues(b, a)
+		return -cmp, ok
+	}
+	if af, ok := toNumber(a); ok {
+		if bf, ok := toNumber(b); ok {
+			switch {
+			case af < bf:
+				return -1, true
+			case af > bf:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return strings.Compare(fmtValue(a), fmtValue(b)), true
+}
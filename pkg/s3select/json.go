@@ -0,0 +1,213 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3select
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Valid values of JSON Type.
+const (
+	jsonTypeDocument = "DOCUMENT"
+	jsonTypeLines    = "LINES"
+)
+
+// jsonRecord - a single JSON value, fields are addressed by path.
+type jsonRecord struct {
+	value interface{}
+	// Encoded form of value, preserves the order of object keys.
+	raw json.RawMessage
+}
+
+func (r *jsonRecord) get(path []pathElem) interface{} {
+	v := r.value
+	for _, elem := range path {
+		switch current := v.(type) {
+		case map[string]interface{}:
+			if elem.index >= 0 {
+				return nil
+			}
+			next, ok := current[elem.key]
+			if !ok {
+				// Unquoted identifiers are case insensitive.
+				for key, val := range current {
+					if strings.EqualFold(key, elem.key) {
+						next, ok = val, true
+						break
+					}
+				}
+			}
+			if !ok {
+				return nil
+			}
+			v = next
+		case []interface{}:
+			if elem.index < 0 || elem.index >= len(current) {
+				return nil
+			}
+			v = current[elem.index]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func (r *jsonRecord) fields() ([]string, []interface{}) {
+	obj, ok := r.value.(map[string]interface{})
+	if !ok {
+		return []string{"_1"}, []interface{}{r.value}
+	}
+
+	// Recover the order of keys from the encoded form if available.
+	var names []string
+	if r.raw != nil {
+		dec := json.NewDecoder(bytes.NewReader(r.raw))
+		if t, err := dec.Token(); err == nil && t == json.Delim('{') {
+			for dec.More() {
+				t, err := dec.Token()
+				if err != nil {
+					break
+				}
+				names = append(names, t.(string))
+				var skip json.RawMessage
+				if err = dec.Decode(&skip); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if len(names) != len(obj) {
+		names = names[:0]
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		values[i] = obj[name]
+	}
+	return names, values
+}
+
+// jsonReader - reads JSON records from the object. In LINES mode every
+// value is a record, in DOCUMENT mode a top level array is read as a
+// sequence of records. Records may be selected below a path with
+// FROM S3Object[*].path, arrays found at the path are iterated.
+type jsonReader struct {
+	decoder  *json.Decoder
+	docType  string
+	fromPath []pathElem
+	pending  []interface{} // Records left over from the last value read.
+}
+
+// newJSONReader - validates the JSON input serialization and returns a
+// reader of records from input.
+func newJSONReader(input io.Reader, args *JSONInput, fromPath []pathElem) (*jsonReader, error) {
+	docType := strings.ToUpper(args.Type)
+	switch docType {
+	case jsonTypeDocument, jsonTypeLines:
+	default:
+		return nil, errInvalidJSONType
+	}
+	return &jsonReader{
+		decoder:  json.NewDecoder(input),
+		docType:  docType,
+		fromPath: fromPath,
+	}, nil
+}
+
+// read - returns the next record, io.EOF at the end of input.
+func (r *jsonReader) read() (record, error) {
+	for len(r.pending) == 0 {
+		var raw json.RawMessage
+		if err := r.decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			if _, ok := err.(*json.SyntaxError); ok || err == io.ErrUnexpectedEOF {
+				return nil, &Error{"JSONParsingError", "Error parsing JSON input: " + err.Error()}
+			}
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, &Error{"JSONParsingError", "Error parsing JSON input: " + err.Error()}
+		}
+
+		// Records read without a path keep their encoded form.
+		if len(r.fromPath) == 0 {
+			if arr, ok := value.([]interface{}); ok && r.docType == jsonTypeDocument {
+				r.pending = append(r.pending, arr...)
+				continue
+			}
+			return &jsonRecord{value: value, raw: raw}, nil
+		}
+
+		value = (&jsonRecord{value: value}).get(r.fromPath)
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			r.pending = append(r.pending, v...)
+		default:
+			r.pending = append(r.pending, v)
+		}
+	}
+	value := r.pending[0]
+	r.pending = r.pending[1:]
+	return &jsonRecord{value: value}, nil
+}
+
+// jsonWriter - formats output records as JSON objects.
+type jsonWriter struct {
+	recordDelimiter string
+}
+
+// newJSONWriter - validates the JSON output serialization.
+func newJSONWriter(args *JSONOutput) *jsonWriter {
+	w := &jsonWriter{recordDelimiter: "\n"}
+	if args.RecordDelimiter != "" {
+		w.recordDelimiter = args.RecordDelimiter
+	}
+	return w
+}
+
+// writeRecord - appends a single record to buf.
+func (w *jsonWriter) writeRecord(buf *bytes.Buffer, names []string, values []interface{}) {
+	buf.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(names[i])
+		buf.Write(name)
+		buf.WriteByte(':')
+		data, err := json.Marshal(v)
+		if err != nil {
+			data = []byte("null")
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	buf.WriteString(w.recordDelimiter)
+}
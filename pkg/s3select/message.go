@@ -0,0 +1,116 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3select
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+)
+
+// Select responses are a sequence of event stream messages:
+//
+//	<total length:4> <headers length:4> <prelude crc:4> <headers> <payload> <message crc:4>
+//
+// where every header is encoded as
+//
+//	<name length:1> <name> <value type:1> <value length:2> <value>
+//
+// All integers are big endian and both checksums are CRC32 (IEEE).
+
+// Header value type for string values, the only type used here.
+const headerValueTypeString = 7
+
+// messageHeader - a single event stream message header.
+type messageHeader struct {
+	name, value string
+}
+
+// writeMessage - encodes a single event stream message to w.
+func writeMessage(w io.Writer, headers []messageHeader, payload []byte) error {
+	var headerBuf bytes.Buffer
+	for _, h := range headers {
+		headerBuf.WriteByte(byte(len(h.name)))
+		headerBuf.WriteString(h.name)
+		headerBuf.WriteByte(headerValueTypeString)
+		binary.Write(&headerBuf, binary.BigEndian, uint16(len(h.value)))
+		headerBuf.WriteString(h.value)
+	}
+
+	var msg bytes.Buffer
+	totalLength := 4 + 4 + 4 + headerBuf.Len() + len(payload) + 4
+	binary.Write(&msg, binary.BigEndian, uint32(totalLength))
+	binary.Write(&msg, binary.BigEndian, uint32(headerBuf.Len()))
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(headerBuf.Bytes())
+	msg.Write(payload)
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+
+	_, err := w.Write(msg.Bytes())
+	return err
+}
+
+// writeRecordsMessage - sends a batch of output records.
+func writeRecordsMessage(w io.Writer, payload []byte) error {
+	return writeMessage(w, []messageHeader{
+		{":event-type", "Records"},
+		{":content-type", "application/octet-stream"},
+		{":message-type", "event"},
+	}, payload)
+}
+
+// stats - bytes scanned, processed and returned by a select request.
+type stats struct {
+	XMLName        xml.Name
+	BytesScanned   int64
+	BytesProcessed int64
+	BytesReturned  int64
+}
+
+// writeStatsMessage - sends the final statistics, eventType is either
+// "Stats" or "Progress" which share the same payload layout.
+func writeStatsMessage(w io.Writer, eventType string, st stats) error {
+	st.XMLName.Local = eventType
+	payload, err := xml.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return writeMessage(w, []messageHeader{
+		{":event-type", eventType},
+		{":content-type", "text/xml"},
+		{":message-type", "event"},
+	}, payload)
+}
+
+// writeEndMessage - marks the successful end of the response.
+func writeEndMessage(w io.Writer) error {
+	return writeMessage(w, []messageHeader{
+		{":event-type", "End"},
+		{":message-type", "event"},
+	}, nil)
+}
+
+// writeErrorMessage - reports an error after the response has started.
+func writeErrorMessage(w io.Writer, code, message string) error {
+	return writeMessage(w, []messageHeader{
+		{":error-code", code},
+		{":error-message", message},
+		{":message-type", "error"},
+	}, nil)
+}
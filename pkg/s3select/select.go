@@ -0,0 +1,419 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package s3select implements S3 Select, SQL expressions evaluated
+// over the contents of a single object with only the matching records
+// streamed back to the client.
+package s3select
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Records are batched into messages of at most this size.
+const maxRecordsMessageSize = 64 * 1024
+
+// Request - represents the SelectObjectContentRequest document.
+type Request struct {
+	XMLName             xml.Name `xml:"SelectObjectContentRequest"`
+	Expression          string
+	ExpressionType      string
+	InputSerialization  InputSerialization
+	OutputSerialization OutputSerialization
+	RequestProgress     struct {
+		Enabled bool
+	}
+}
+
+// InputSerialization - describes the format of the object.
+type InputSerialization struct {
+	CompressionType string
+	CSV             *CSVInput
+	JSON            *JSONInput
+	Parquet         *ParquetInput
+}
+
+// CSVInput - describes a CSV formatted object.
+type CSVInput struct {
+	FileHeaderInfo       string
+	RecordDelimiter      string
+	FieldDelimiter       string
+	QuoteCharacter       string
+	QuoteEscapeCharacter string
+	Comments             string
+}
+
+// JSONInput - describes a JSON formatted object, Type is either
+// DOCUMENT or LINES.
+type JSONInput struct {
+	Type string
+}
+
+// ParquetInput - describes a Parquet formatted object. Parquet input
+// is recognized but not supported, no Parquet decoder is available.
+type ParquetInput struct{}
+
+// OutputSerialization - describes the format of the returned records.
+type OutputSerialization struct {
+	CSV  *CSVOutput
+	JSON *JSONOutput
+}
+
+// CSVOutput - describes CSV formatted output records.
+type CSVOutput struct {
+	QuoteFields          string
+	RecordDelimiter      string
+	FieldDelimiter       string
+	QuoteCharacter       string
+	QuoteEscapeCharacter string
+}
+
+// JSONOutput - describes JSON formatted output records.
+type JSONOutput struct {
+	RecordDelimiter string
+}
+
+// Valid values of CompressionType.
+const (
+	compressionNone  = "NONE"
+	compressionGzip  = "GZIP"
+	compressionBzip2 = "BZIP2"
+)
+
+// Error - a select error along with the S3 error code reported to clients.
+type Error struct {
+	code    string
+	message string
+}
+
+// Code - returns the S3 error code.
+func (e *Error) Code() string {
+	return e.code
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Select errors.
+var (
+	errMissingExpression     = &Error{"MissingRequiredParameter", "The Expression parameter is required."}
+	errInvalidExpressionType = &Error{"InvalidExpressionType", "The ExpressionType is invalid. Only SQL expressions are supported."}
+	errInvalidCompression    = &Error{"InvalidCompressionFormat", "The file is not in a supported compression format. Only NONE, GZIP and BZIP2 are supported."}
+	errMissingInputFormat    = &Error{"MissingRequiredParameter", "The InputSerialization must specify exactly one of CSV or JSON."}
+	errMissingOutputFormat   = &Error{"MissingRequiredParameter", "The OutputSerialization must specify exactly one of CSV or JSON."}
+	errInvalidJSONType       = &Error{"InvalidJsonType", "The JsonType is invalid. Only DOCUMENT and LINES are supported."}
+	errParquetNotSupported   = &Error{"NotImplemented", "Parquet input is not supported. Only CSV and JSON are supported."}
+	errInvalidFileHeaderInfo = &Error{"InvalidFileHeaderInfo", "The FileHeaderInfo is invalid. Only NONE, USE, and IGNORE are supported."}
+	errInvalidQuoteFields    = &Error{"InvalidQuoteFields", "The QuoteFields is invalid. Only ALWAYS and ASNEEDED are supported."}
+)
+
+// errSyntax - an invalid or unsupported select expression.
+func errSyntax(format string, args ...interface{}) error {
+	return &Error{"UnsupportedSyntax", "Invalid SQL expression: " + fmt.Sprintf(format, args...)}
+}
+
+// errInvalidRequestParameter - an unsupported serialization parameter.
+func errInvalidRequestParameter(message string) error {
+	return &Error{"InvalidRequestParameter", "The value of a parameter in the request is invalid: " + message}
+}
+
+// ParseRequest - decodes a SelectObjectContentRequest document.
+func ParseRequest(reader io.Reader) (*Request, error) {
+	req := &Request{}
+	if err := xml.NewDecoder(reader).Decode(req); err != nil {
+		return nil, &Error{"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema."}
+	}
+	return req, nil
+}
+
+// record - a single input record.
+type record interface {
+	// get - returns the value at path, nil if there is no such field.
+	get(path []pathElem) interface{}
+	// fields - returns the names and values of all the fields of the
+	// record, for SELECT *.
+	fields() (names []string, values []interface{})
+}
+
+// recordReader - reads records from the object.
+type recordReader interface {
+	// read - returns the next record, io.EOF at the end of input.
+	read() (record, error)
+}
+
+// newRecordReader - returns a reader of records in the input format,
+// fromPath selects the records below S3Object[*] for JSON input.
+func newRecordReader(input io.Reader, args InputSerialization, fromPath []pathElem) (recordReader, error) {
+	if args.JSON != nil {
+		return newJSONReader(input, args.JSON, fromPath)
+	}
+	if len(fromPath) > 0 {
+		return nil, errSyntax("paths in the FROM clause are only supported for JSON input")
+	}
+	return newCSVReader(input, args.CSV)
+}
+
+// newRecordWriter - returns a writer of records in the output format.
+func newRecordWriter(args OutputSerialization) (recordWriter, error) {
+	if args.JSON != nil {
+		return newJSONWriter(args.JSON), nil
+	}
+	return newCSVWriter(args.CSV)
+}
+
+// recordWriter - formats output records.
+type recordWriter interface {
+	writeRecord(buf *bytes.Buffer, names []string, values []interface{})
+}
+
+// Select - a validated select request ready to be evaluated.
+type Select struct {
+	req    *Request
+	query  *query
+	names  []string
+	writer recordWriter
+}
+
+// NewSelect - validates req and parses its expression.
+func NewSelect(req *Request) (*Select, error) {
+	if strings.TrimSpace(req.Expression) == "" {
+		return nil, errMissingExpression
+	}
+	if !strings.EqualFold(req.ExpressionType, "SQL") {
+		return nil, errInvalidExpressionType
+	}
+	switch strings.ToUpper(req.InputSerialization.CompressionType) {
+	case "", compressionNone, compressionGzip, compressionBzip2:
+	default:
+		return nil, errInvalidCompression
+	}
+	if req.InputSerialization.Parquet != nil {
+		return nil, errParquetNotSupported
+	}
+	if (req.InputSerialization.CSV == nil) == (req.InputSerialization.JSON == nil) {
+		return nil, errMissingInputFormat
+	}
+	if (req.OutputSerialization.CSV == nil) == (req.OutputSerialization.JSON == nil) {
+		return nil, errMissingOutputFormat
+	}
+	writer, err := newRecordWriter(req.OutputSerialization)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := parseQuery(req.Expression)
+	if err != nil {
+		return nil, err
+	}
+	// Validate input serialization without any data.
+	if _, err = newRecordReader(strings.NewReader(""), req.InputSerialization, q.fromPath); err != nil {
+		return nil, err
+	}
+
+	s := &Select{req: req, query: q, writer: writer}
+	for i, proj := range q.projections {
+		name := proj.alias
+		if name == "" {
+			if col, ok := proj.expr.(*columnRef); ok && col.path[len(col.path)-1].index < 0 {
+				name = col.path[len(col.path)-1].key
+			} else {
+				name = fmt.Sprintf("_%d", i+1)
+			}
+		}
+		s.names = append(s.names, name)
+	}
+	return s, nil
+}
+
+// countingReader - counts the bytes read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// flusher - implemented by writers which buffer, such as http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+// Evaluate - reads the object from input and writes the matching
+// records to w as a stream of event messages. Errors found in the
+// input are reported to the client as error messages, the returned
+// error is non nil only if writing to w failed.
+func (s *Select) Evaluate(input io.Reader, w io.Writer) error {
+	scanned := &countingReader{reader: input}
+	processed := scanned
+	var returned int64
+
+	send := func(fn func() error) error {
+		if err := fn(); err != nil {
+			return err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	flushRecords := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		returned += int64(buf.Len())
+		err := send(func() error { return writeRecordsMessage(w, buf.Bytes()) })
+		buf.Reset()
+		return err
+	}
+
+	decompressed, err := decompress(scanned, s.req.InputSerialization.CompressionType)
+	if err != nil {
+		return send(func() error { return writeSelectError(w, err) })
+	}
+	if decompressed != io.Reader(scanned) {
+		processed = &countingReader{reader: decompressed}
+	}
+
+	reader, err := newRecordReader(processed, s.req.InputSerialization, s.query.fromPath)
+	if err != nil {
+		return send(func() error { return writeSelectError(w, err) })
+	}
+
+	// Aggregates consume every matching record and produce a single
+	// row at the end, LIMIT applies to that row.
+	aggregate := len(s.query.aggregates) > 0
+
+	var matched int64
+	for aggregate || s.query.limit < 0 || matched < s.query.limit {
+		rec, readErr := reader.read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if err = flushRecords(); err != nil {
+				return err
+			}
+			return send(func() error { return writeSelectError(w, readErr) })
+		}
+		if s.query.where != nil {
+			if ok, _ := s.query.where.eval(rec).(bool); !ok {
+				continue
+			}
+		}
+		matched++
+
+		if aggregate {
+			for _, agg := range s.query.aggregates {
+				agg.update(rec)
+			}
+			continue
+		}
+		if s.query.selectAll {
+			names, values := rec.fields()
+			s.writer.writeRecord(&buf, names, values)
+		} else {
+			values := make([]interface{}, len(s.query.projections))
+			for i, proj := range s.query.projections {
+				values[i] = proj.expr.eval(rec)
+			}
+			s.writer.writeRecord(&buf, s.names, values)
+		}
+		if buf.Len() >= maxRecordsMessageSize {
+			if err = flushRecords(); err != nil {
+				return err
+			}
+		}
+	}
+	if aggregate && s.query.limit != 0 {
+		values := make([]interface{}, len(s.query.projections))
+		for i, proj := range s.query.projections {
+			values[i] = proj.expr.eval(nil)
+		}
+		s.writer.writeRecord(&buf, s.names, values)
+	}
+	if err = flushRecords(); err != nil {
+		return err
+	}
+
+	st := stats{
+		BytesScanned:   scanned.n,
+		BytesProcessed: processed.n,
+		BytesReturned:  returned,
+	}
+	if s.req.RequestProgress.Enabled {
+		if err = send(func() error { return writeStatsMessage(w, "Progress", st) }); err != nil {
+			return err
+		}
+	}
+	if err = send(func() error { return writeStatsMessage(w, "Stats", st) }); err != nil {
+		return err
+	}
+	return send(func() error { return writeEndMessage(w) })
+}
+
+// decompress - returns a reader of the uncompressed object.
+func decompress(input io.Reader, compressionType string) (io.Reader, error) {
+	switch strings.ToUpper(compressionType) {
+	case compressionGzip:
+		gr, err := gzip.NewReader(input)
+		if err == gzip.ErrHeader || err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, &Error{"InvalidCompressionFormat", "The object is not in GZIP format."}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &decompressReader{gr, compressionGzip}, nil
+	case compressionBzip2:
+		return &decompressReader{bzip2.NewReader(input), compressionBzip2}, nil
+	}
+	return input, nil
+}
+
+// decompressReader - reports corrupt compressed input as an
+// InvalidCompressionFormat error.
+type decompressReader struct {
+	reader io.Reader
+	format string
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	n, err := d.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = &Error{"InvalidCompressionFormat", "The object is not valid " + d.format + " data: " + err.Error()}
+	}
+	return n, err
+}
+
+// writeSelectError - reports err as an error message.
+func writeSelectError(w io.Writer, err error) error {
+	if selectErr, ok := err.(*Error); ok {
+		return writeErrorMessage(w, selectErr.Code(), selectErr.Error())
+	}
+	return writeErrorMessage(w, "InternalError", err.Error())
+}
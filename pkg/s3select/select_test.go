@@ -0,0 +1,347 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package s3select
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testMessage - a decoded event stream message.
+type testMessage struct {
+	headers map[string]string
+	payload []byte
+}
+
+// readMessages - decodes and verifies an event stream.
+func readMessages(t *testing.T, data []byte) []testMessage {
+	var msgs []testMessage
+	for len(data) > 0 {
+		if len(data) < 16 {
+			t.Fatalf("Short message of %d bytes", len(data))
+		}
+		totalLength := binary.BigEndian.Uint32(data[0:4])
+		headersLength := binary.BigEndian.Uint32(data[4:8])
+		if crc32.ChecksumIEEE(data[0:8]) != binary.BigEndian.Uint32(data[8:12]) {
+			t.Fatal("Prelude checksum mismatch")
+		}
+		msg := data[:totalLength]
+		if crc32.ChecksumIEEE(msg[:totalLength-4]) != binary.BigEndian.Uint32(msg[totalLength-4:]) {
+			t.Fatal("Message checksum mismatch")
+		}
+		headers := make(map[string]string)
+		h := msg[12 : 12+headersLength]
+		for len(h) > 0 {
+			nameLen := int(h[0])
+			name := string(h[1 : 1+nameLen])
+			h = h[1+nameLen:]
+			if h[0] != headerValueTypeString {
+				t.Fatalf("Unexpected header value type %d", h[0])
+			}
+			valueLen := int(binary.BigEndian.Uint16(h[1:3]))
+			headers[name] = string(h[3 : 3+valueLen])
+			h = h[3+valueLen:]
+		}
+		msgs = append(msgs, testMessage{headers, msg[12+headersLength : totalLength-4]})
+		data = data[totalLength:]
+	}
+	return msgs
+}
+
+const testCSV = "name,age,city\nAlice,34,Paris\nBob,27,\"New York, NY\"\nCarol,45,London\n"
+
+// Tests evaluation of select requests over CSV input.
+func TestSelectCSV(t *testing.T) {
+	testCases := []struct {
+		expression string
+		input      CSVInput
+		output     CSVOutput
+		expected   string
+	}{
+		{"SELECT * FROM S3Object", CSVInput{FileHeaderInfo: "IGNORE"}, CSVOutput{},
+			"Alice,34,Paris\nBob,27,\"New York, NY\"\nCarol,45,London\n"},
+		{"SELECT s.name, s.age FROM S3Object s WHERE CAST(s.age AS INT) > 30", CSVInput{FileHeaderInfo: "USE"}, CSVOutput{},
+			"Alice,34\nCarol,45\n"},
+		{"SELECT _1 FROM S3Object LIMIT 2", CSVInput{}, CSVOutput{},
+			"name\nAlice\n"},
+		{"SELECT city FROM S3Object WHERE name = 'Bob'", CSVInput{FileHeaderInfo: "USE"}, CSVOutput{FieldDelimiter: ";", QuoteFields: "ALWAYS", RecordDelimiter: "\r\n"},
+			"\"New York, NY\"\r\n"},
+		{"SELECT name FROM S3Object WHERE age > 100", CSVInput{FileHeaderInfo: "USE"}, CSVOutput{}, ""},
+	}
+	for i, testCase := range testCases {
+		input, output := testCase.input, testCase.output
+		req := &Request{Expression: testCase.expression, ExpressionType: "SQL"}
+		req.InputSerialization.CSV = &input
+		req.OutputSerialization.CSV = &output
+		s, err := NewSelect(req)
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error: %s", i+1, err)
+		}
+		var buf bytes.Buffer
+		if err = s.Evaluate(strings.NewReader(testCSV), &buf); err != nil {
+			t.Fatalf("Test %d: Unexpected error: %s", i+1, err)
+		}
+
+		var records bytes.Buffer
+		msgs := readMessages(t, buf.Bytes())
+		for _, msg := range msgs {
+			if msg.headers[":event-type"] == "Records" {
+				records.Write(msg.payload)
+			}
+		}
+		if records.String() != testCase.expected {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.expected, records.String())
+		}
+		if n := len(msgs); n < 2 || msgs[n-2].headers[":event-type"] != "Stats" || msgs[n-1].headers[":event-type"] != "End" {
+			t.Errorf("Test %d: Expected response to end with Stats and End", i+1)
+		}
+	}
+}
+
+// Tests custom input delimiters.
+func TestSelectCSVDelimiters(t *testing.T) {
+	req := &Request{Expression: "SELECT b FROM S3Object WHERE a = '2'", ExpressionType: "SQL"}
+	req.InputSerialization.CSV = &CSVInput{FileHeaderInfo: "USE", FieldDelimiter: "|", RecordDelimiter: ";", Comments: "#"}
+	req.OutputSerialization.CSV = &CSVOutput{}
+	s, err := NewSelect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = s.Evaluate(strings.NewReader("a|b;#comment;1|x;2|multi\nline;"), &buf); err != nil {
+		t.Fatal(err)
+	}
+	msgs := readMessages(t, buf.Bytes())
+	if string(msgs[0].payload) != "\"multi\nline\"\n" {
+		t.Errorf("Unexpected records %q", msgs[0].payload)
+	}
+}
+
+// Tests validation of select requests.
+func TestNewSelectErrors(t *testing.T) {
+	testCases := []struct {
+		request      string
+		expectedCode string
+	}{
+		{"<SelectObjectContentRequest>", "MalformedXML"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>XPATH</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"InvalidExpressionType"},
+		{"<SelectObjectContentRequest><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"MissingRequiredParameter"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CompressionType>LZ4</CompressionType><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"InvalidCompressionFormat"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV><FileHeaderInfo>FIRST</FileHeaderInfo></CSV></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"InvalidFileHeaderInfo"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV><QuoteFields>NEVER</QuoteFields></CSV></OutputSerialization></SelectObjectContentRequest>",
+			"InvalidQuoteFields"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object WHERE</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"UnsupportedSyntax"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/><JSON><Type>LINES</Type></JSON></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"MissingRequiredParameter"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><JSON><Type>TREE</Type></JSON></InputSerialization><OutputSerialization><JSON/></OutputSerialization></SelectObjectContentRequest>",
+			"InvalidJsonType"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><Parquet/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"NotImplemented"},
+		{"<SelectObjectContentRequest><Expression>SELECT * FROM S3Object[*].items</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"UnsupportedSyntax"},
+		{"<SelectObjectContentRequest><Expression>SELECT name, COUNT(*) FROM S3Object</Expression><ExpressionType>SQL</ExpressionType>" +
+			"<InputSerialization><CSV/></InputSerialization><OutputSerialization><CSV/></OutputSerialization></SelectObjectContentRequest>",
+			"UnsupportedSyntax"},
+	}
+	for i, testCase := range testCases {
+		req, err := ParseRequest(strings.NewReader(testCase.request))
+		if err == nil {
+			_, err = NewSelect(req)
+		}
+		selectErr, ok := err.(*Error)
+		if !ok {
+			t.Fatalf("Test %d: Expected *Error, got %v", i+1, err)
+		}
+		if selectErr.Code() != testCase.expectedCode {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expectedCode, selectErr.Code())
+		}
+	}
+}
+
+// Tests that malformed input is reported as an error message.
+func TestSelectCSVParsingError(t *testing.T) {
+	req := &Request{Expression: "SELECT * FROM S3Object", ExpressionType: "SQL"}
+	req.InputSerialization.CSV = &CSVInput{}
+	req.OutputSerialization.CSV = &CSVOutput{}
+	s, err := NewSelect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = s.Evaluate(strings.NewReader("a,b\n\"unterminated"), &buf); err != nil {
+		t.Fatal(err)
+	}
+	msgs := readMessages(t, buf.Bytes())
+	last := msgs[len(msgs)-1]
+	if last.headers[":message-type"] != "error" || last.headers[":error-code"] != "CSVParsingError" {
+		t.Errorf("Expected CSVParsingError, got %v", last.headers)
+	}
+}
+
+// evaluateRecords - evaluates req over input, returns the records
+// returned along with all the messages.
+func evaluateRecords(t *testing.T, req *Request, input []byte) (string, []testMessage) {
+	s, err := NewSelect(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var buf bytes.Buffer
+	if err = s.Evaluate(bytes.NewReader(input), &buf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var records bytes.Buffer
+	msgs := readMessages(t, buf.Bytes())
+	for _, msg := range msgs {
+		if msg.headers[":event-type"] == "Records" {
+			records.Write(msg.payload)
+		}
+	}
+	return records.String(), msgs
+}
+
+const testJSONLines = `{"name":"Alice","age":34,"address":{"city":"Paris"},"tags":["a","b"]}
+{"name":"Bob","age":27,"address":{"city":"New York"},"tags":[]}
+{"name":"Carol","age":45,"address":{"city":"London"},"tags":["c"]}
+`
+
+// Tests evaluation of select requests over JSON input.
+func TestSelectJSON(t *testing.T) {
+	testCases := []struct {
+		expression string
+		jsonType   string
+		input      string
+		csvOutput  bool
+		expected   string
+	}{
+		{"SELECT * FROM S3Object WHERE age < 30", "LINES", testJSONLines, false,
+			`{"name":"Bob","age":27,"address":{"city":"New York"},"tags":[]}` + "\n"},
+		{"SELECT s.name, s.address.city FROM S3Object s WHERE s.age > 30", "LINES", testJSONLines, false,
+			`{"name":"Alice","city":"Paris"}` + "\n" + `{"name":"Carol","city":"London"}` + "\n"},
+		{"SELECT s.tags[0] AS first FROM S3Object s WHERE s.tags[0] IS NOT NULL", "LINES", testJSONLines, true,
+			"a\nc\n"},
+		{"SELECT COUNT(*), SUM(age), MIN(name), MAX(age), AVG(age) FROM S3Object", "LINES", testJSONLines, true,
+			"3,106,Alice,45,35.333333333333336\n"},
+		{"SELECT COUNT(*) FROM S3Object WHERE age > 100", "LINES", testJSONLines, true,
+			"0\n"},
+		{"SELECT name FROM S3Object", "DOCUMENT", `[{"name":"Alice"},{"name":"Bob"}]`, true,
+			"Alice\nBob\n"},
+		{"SELECT * FROM S3Object", "DOCUMENT", `{"b":1,"a":2}`, false,
+			`{"b":1,"a":2}` + "\n"},
+		{"SELECT id FROM S3Object[*].items[*] WHERE qty >= 2", "DOCUMENT", `{"items":[{"id":"x","qty":1},{"id":"y","qty":2},{"id":"z","qty":3}]}`, true,
+			"y\nz\n"},
+		{"SELECT MAX(s.qty) FROM S3Object[*].items s", "LINES", "{\"items\":[{\"qty\":1}]}\n{\"items\":[{\"qty\":7},{\"qty\":3}]}\n", true,
+			"7\n"},
+	}
+	for i, testCase := range testCases {
+		req := &Request{Expression: testCase.expression, ExpressionType: "SQL"}
+		req.InputSerialization.JSON = &JSONInput{Type: testCase.jsonType}
+		if testCase.csvOutput {
+			req.OutputSerialization.CSV = &CSVOutput{}
+		} else {
+			req.OutputSerialization.JSON = &JSONOutput{}
+		}
+		records, _ := evaluateRecords(t, req, []byte(testCase.input))
+		if records != testCase.expected {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.expected, records)
+		}
+	}
+}
+
+// Tests that malformed JSON is reported as an error message.
+func TestSelectJSONParsingError(t *testing.T) {
+	req := &Request{Expression: "SELECT * FROM S3Object", ExpressionType: "SQL"}
+	req.InputSerialization.JSON = &JSONInput{Type: "LINES"}
+	req.OutputSerialization.JSON = &JSONOutput{}
+	records, msgs := evaluateRecords(t, req, []byte("{\"a\":1}\n{\"a\":"))
+	if records != `{"a":1}`+"\n" {
+		t.Errorf("Unexpected records %q", records)
+	}
+	last := msgs[len(msgs)-1]
+	if last.headers[":message-type"] != "error" || last.headers[":error-code"] != "JSONParsingError" {
+		t.Errorf("Expected JSONParsingError, got %v", last.headers)
+	}
+}
+
+// bzip2 compressed form of `{"a":1}\n{"a":2}\n`, compress/bzip2 only
+// implements decompression.
+var testBzip2Data = []byte{
+	66, 90, 104, 57, 49, 65, 89, 38, 83, 89, 34, 157, 226, 233, 0, 0,
+	6, 89, 128, 0, 16, 16, 0, 48, 16, 32, 0, 0, 10, 32, 0, 49,
+	12, 8, 18, 128, 122, 137, 194, 38, 134, 139, 226, 238, 72, 167, 10, 18,
+	4, 83, 188, 93, 32,
+}
+
+// Tests evaluation of select requests over compressed objects.
+func TestSelectCompressed(t *testing.T) {
+	plain := []byte("{\"a\":1}\n{\"a\":2}\n")
+	var gzipData bytes.Buffer
+	gw := gzip.NewWriter(&gzipData)
+	gw.Write(plain)
+	gw.Close()
+
+	testCases := []struct {
+		compressionType string
+		input           []byte
+		expectedCode    string
+	}{
+		{"GZIP", gzipData.Bytes(), ""},
+		{"bzip2", testBzip2Data, ""},
+		{"GZIP", plain, "InvalidCompressionFormat"},
+		{"BZIP2", plain, "InvalidCompressionFormat"},
+	}
+	for i, testCase := range testCases {
+		req := &Request{Expression: "SELECT SUM(a) FROM S3Object", ExpressionType: "SQL"}
+		req.InputSerialization.CompressionType = testCase.compressionType
+		req.InputSerialization.JSON = &JSONInput{Type: "LINES"}
+		req.OutputSerialization.CSV = &CSVOutput{}
+		records, msgs := evaluateRecords(t, req, testCase.input)
+		last := msgs[len(msgs)-1]
+		if testCase.expectedCode != "" {
+			if last.headers[":error-code"] != testCase.expectedCode {
+				t.Errorf("Test %d: Expected %s, got %v", i+1, testCase.expectedCode, last.headers)
+			}
+			continue
+		}
+		if records != "3\n" {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, "3\n", records)
+		}
+		st := string(msgs[len(msgs)-2].payload)
+		expectedStats := "<BytesProcessed>" + strconv.Itoa(len(plain)) + "</BytesProcessed>"
+		if !strings.Contains(st, expectedStats) {
+			t.Errorf("Test %d: Expected %s in %s", i+1, expectedStats, st)
+		}
+	}
+}
@@ -0,0 +1,697 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3select
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind - kind of a lexical token in a select expression.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+// token - a single lexical token.
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// is - returns true if the token is the given symbol or the given
+// keyword, keywords are matched case insensitively.
+func (t token) is(val string) bool {
+	switch t.kind {
+	case tokSymbol:
+		return t.val == val
+	case tokIdent:
+		return strings.EqualFold(t.val, val)
+	}
+	return false
+}
+
+// tokenize - splits a select expression into tokens.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			// String literal or quoted identifier, the quote
+			// character is escaped by doubling it.
+			var val []rune
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						val = append(val, r)
+						j++
+						continue
+					}
+					break
+				}
+				val = append(val, runes[j])
+			}
+			if j >= len(runes) {
+				return nil, errSyntax("unterminated quoted string at position %d", i)
+			}
+			kind := tokString
+			if r == '"' {
+				kind = tokQuotedIdent
+			}
+			tokens = append(tokens, token{kind, string(val)})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[i:j])})
+			i = j
+		default:
+			// Two character operators first.
+			if i+1 < len(runes) {
+				switch op := string(runes[i : i+2]); op {
+				case "<=", ">=", "<>", "!=":
+					tokens = append(tokens, token{tokSymbol, op})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("=<>*,().+-/%[]", r) {
+				return nil, errSyntax("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, token{tokSymbol, string(r)})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// projection - a single item of the select list.
+type projection struct {
+	expr  expr
+	alias string
+}
+
+// query - a parsed select expression.
+type query struct {
+	selectAll   bool
+	projections []projection
+	aggregates  []*aggregateExpr
+	fromPath    []pathElem // Path below S3Object[*] records are read from.
+	where       expr
+	limit       int64 // -1 when no limit is set.
+}
+
+// parser - recursive descent parser for select expressions.
+type parser struct {
+	tokens []token
+	pos    int
+	alias  string // Table alias given in the FROM clause.
+
+	aggregates  []*aggregateExpr
+	inAggregate bool // Set while parsing the argument of an aggregate.
+	columnRefs  int  // Column references outside of aggregates.
+}
+
+// parseQuery - parses a select expression of the form
+//
+//	SELECT <projections> FROM S3Object[*][.path] [[AS] alias] [WHERE <condition>] [LIMIT <n>]
+func parseQuery(expression string) (*query, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parseSelect()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept - consumes the next token if it matches val.
+func (p *parser) accept(val string) bool {
+	if p.peek().is(val) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect - consumes the next token, failing unless it matches val.
+func (p *parser) expect(val string) error {
+	if !p.accept(val) {
+		return errSyntax("expected %s, found %q", val, p.peek().val)
+	}
+	return nil
+}
+
+// isReserved - keywords which may not be used as bare aliases.
+func isReserved(t token) bool {
+	for _, kw := range []string{"FROM", "WHERE", "LIMIT", "AS", "AND", "OR", "NOT"} {
+		if t.is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseSelect() (*query, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &query{limit: -1}
+
+	// The FROM clause is parsed first so that column references in the
+	// select list can strip the table alias, remember where we were.
+	start := p.pos
+	depth := 0
+	for !(depth == 0 && p.peek().is("FROM")) {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return nil, errSyntax("missing FROM clause")
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		}
+	}
+	p.next()
+	if err := p.parseFrom(q); err != nil {
+		return nil, err
+	}
+	end := p.pos
+
+	p.pos = start
+	if err := p.parseProjections(q); err != nil {
+		return nil, err
+	}
+	if !p.accept("FROM") {
+		return nil, errSyntax("unexpected %q in select list", p.peek().val)
+	}
+	p.pos = end
+	q.aggregates = p.aggregates
+	if len(q.aggregates) > 0 && p.columnRefs > 0 {
+		return nil, errSyntax("columns must be used within aggregate functions when aggregates are selected")
+	}
+
+	if p.accept("WHERE") {
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if len(p.aggregates) != len(q.aggregates) {
+			return nil, errSyntax("aggregate functions are not allowed in WHERE")
+		}
+		q.where = where
+	}
+	if p.accept("LIMIT") {
+		t := p.next()
+		limit, err := strconv.ParseInt(t.val, 10, 64)
+		if t.kind != tokNumber || err != nil || limit < 0 {
+			return nil, errSyntax("invalid LIMIT %q", t.val)
+		}
+		q.limit = limit
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errSyntax("unexpected %q", t.val)
+	}
+	return q, nil
+}
+
+// parseFrom - parses "S3Object[*][.path] [[AS] alias]".
+func (p *parser) parseFrom(q *query) error {
+	if t := p.next(); !t.is("S3Object") {
+		return errSyntax("unsupported data source %q, only S3Object is supported", t.val)
+	}
+	if p.accept("[") {
+		if err := p.expect("*"); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	}
+	path, err := p.parsePath()
+	if err != nil {
+		return err
+	}
+	q.fromPath = path
+	if len(path) > 0 && p.accept("[") {
+		// Trailing [*] on the path, arrays are always iterated.
+		if err = p.expect("*"); err != nil {
+			return err
+		}
+		if err = p.expect("]"); err != nil {
+			return err
+		}
+	}
+	hasAS := p.accept("AS")
+	if t := p.peek(); (t.kind == tokIdent && !isReserved(t)) || t.kind == tokQuotedIdent {
+		p.alias = p.next().val
+	} else if hasAS {
+		return errSyntax("expected alias after AS")
+	}
+	return nil
+}
+
+// parseProjections - parses the select list.
+func (p *parser) parseProjections(q *query) error {
+	if p.accept("*") {
+		q.selectAll = true
+		return nil
+	}
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		proj := projection{expr: e}
+		if p.accept("AS") {
+			t := p.next()
+			if t.kind != tokIdent && t.kind != tokQuotedIdent {
+				return errSyntax("expected alias after AS")
+			}
+			proj.alias = t.val
+		} else if t := p.peek(); (t.kind == tokIdent && !isReserved(t)) || t.kind == tokQuotedIdent {
+			proj.alias = p.next().val
+		}
+		q.projections = append(q.projections, proj)
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{e}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.is("="), t.is("!="), t.is("<>"), t.is("<"), t.is("<="), t.is(">"), t.is(">="):
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpr{op: t.val, left: left, right: right}, nil
+	case t.is("IS"):
+		p.next()
+		negate := p.accept("NOT")
+		if err = p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{e: left, negate: negate}, nil
+	}
+
+	negate := p.accept("NOT")
+	switch {
+	case p.accept("LIKE"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &likeExpr{e: left, pattern: pattern, negate: negate}, nil
+	case p.accept("BETWEEN"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{e: left, low: low, high: high, negate: negate}, nil
+	case p.accept("IN"):
+		if err = p.expect("("); err != nil {
+			return nil, err
+		}
+		list, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		return &inExpr{e: left, list: list, negate: negate}, nil
+	case negate:
+		return nil, errSyntax("expected LIKE, BETWEEN or IN after NOT")
+	}
+	return left, nil
+}
+
+// parseExprList - parses a comma separated list of expressions up to
+// and including the closing parenthesis.
+func (p *parser) parseExprList() ([]expr, error) {
+	var list []expr
+	if p.accept(")") {
+		return list, nil
+	}
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if p.accept(")") {
+			return list, nil
+		}
+		if err = p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.is("+") && !t.is("-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithExpr{op: t.val, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.is("*") && !t.is("/") && !t.is("%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithExpr{op: t.val, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("-") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &arithExpr{op: "-", left: &literal{float64(0)}, right: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, errSyntax("invalid number %q", t.val)
+		}
+		return &literal{f}, nil
+	case tokString:
+		return &literal{t.val}, nil
+	case tokQuotedIdent:
+		return p.parseColumn(t)
+	case tokSymbol:
+		if t.val == "(" {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	case tokIdent:
+		switch {
+		case t.is("TRUE"):
+			return &literal{true}, nil
+		case t.is("FALSE"):
+			return &literal{false}, nil
+		case t.is("NULL"):
+			return &literal{nil}, nil
+		case t.is("CAST"):
+			return p.parseCast()
+		case p.peek().is("("):
+			p.next()
+			return p.parseFunction(t.val)
+		case isReserved(t):
+			return nil, errSyntax("unexpected keyword %s", t.val)
+		}
+		return p.parseColumn(t)
+	}
+	return nil, errSyntax("unexpected %q", t.val)
+}
+
+// parseCast - parses "CAST(<expr> AS <type>)" after the CAST keyword.
+func (p *parser) parseCast() (expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect("AS"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	typ := strings.ToUpper(t.val)
+	switch typ {
+	case "INT", "INTEGER", "FLOAT", "DECIMAL", "NUMERIC", "STRING", "VARCHAR", "BOOL", "BOOLEAN":
+	default:
+		return nil, errSyntax("unsupported CAST type %q", t.val)
+	}
+	if err = p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &castExpr{e: e, typ: typ}, nil
+}
+
+// parseFunction - parses the arguments of a function call after the
+// opening parenthesis.
+func (p *parser) parseFunction(name string) (expr, error) {
+	name = strings.ToUpper(name)
+	if _, ok := aggregateFuncs[name]; ok {
+		return p.parseAggregate(name)
+	}
+	fn, ok := scalarFuncs[name]
+	if !ok {
+		return nil, errSyntax("unsupported function %s", name)
+	}
+	args, err := p.parseExprList()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != fn.nargs {
+		return nil, errSyntax("%s expects %d argument(s)", name, fn.nargs)
+	}
+	return &funcExpr{name: name, fn: fn.fn, args: args}, nil
+}
+
+// parseAggregate - parses the argument of an aggregate function, only
+// COUNT accepts "*".
+func (p *parser) parseAggregate(name string) (expr, error) {
+	if p.inAggregate {
+		return nil, errSyntax("aggregate functions cannot be nested")
+	}
+	agg := &aggregateExpr{name: name}
+	if name == "COUNT" && p.accept("*") {
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	} else {
+		p.inAggregate = true
+		args, err := p.parseExprList()
+		p.inAggregate = false
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != 1 {
+			return nil, errSyntax("%s expects 1 argument", name)
+		}
+		agg.arg = args[0]
+	}
+	p.aggregates = append(p.aggregates, agg)
+	return agg, nil
+}
+
+// parseColumn - parses a column reference starting with t, such as
+// "name", "s.name", "s._1", "s.\"First Name\"" or "s.items[0].id".
+func (p *parser) parseColumn(t token) (expr, error) {
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	path = append([]pathElem{{key: t.val, index: -1}}, path...)
+	// Strip the table alias.
+	if len(path) > 1 && path[0].index < 0 &&
+		(strings.EqualFold(path[0].key, p.alias) || strings.EqualFold(path[0].key, "S3Object")) {
+		path = path[1:]
+	}
+	if !p.inAggregate {
+		p.columnRefs++
+	}
+	return &columnRef{path: path}, nil
+}
+
+// parsePath - parses a possibly empty sequence of ".key" and "[index]"
+// path elements.
+func (p *parser) parsePath() ([]pathElem, error) {
+	var path []pathElem
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokIdent && t.kind != tokQuotedIdent {
+				return nil, errSyntax("expected name after '.'")
+			}
+			path = append(path, pathElem{key: t.val, index: -1})
+		case p.peek().is("[") && !p.tokens[p.pos+1].is("*"):
+			p.next()
+			t := p.next()
+			index, err := strconv.Atoi(t.val)
+			if t.kind != tokNumber || err != nil || index < 0 {
+				return nil, errSyntax("invalid array index %q", t.val)
+			}
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{index: index})
+		default:
+			return path, nil
+		}
+	}
+}
+
+// pathElem - an element of a path expression, either an object key
+// or an array index when index is not negative.
+type pathElem struct {
+	key   string
+	index int
+}
+
+// fmtValue - formats a value for output.
+func fmtValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
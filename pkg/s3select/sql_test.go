@@ -0,0 +1,106 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package s3select
+
+import "testing"
+
+// Tests parsing and evaluation of select expressions against a record.
+func TestQueryEval(t *testing.T) {
+	reader := &csvReader{
+		header:     map[string]int{"name": 0, "age": 1, "city": 2},
+		headerFold: map[string]int{"name": 0, "age": 1, "city": 2},
+	}
+	rec := &csvRecord{reader: reader, values: []string{"Alice", "34", "Paris"}}
+
+	testCases := []struct {
+		expression string
+		match      bool
+	}{
+		{"SELECT * FROM S3Object", true},
+		{"SELECT * FROM S3Object WHERE age > 30", true},
+		{"SELECT * FROM S3Object WHERE age > 100", false},
+		{"SELECT * FROM S3Object s WHERE s.age >= 34 AND s.city = 'Paris'", true},
+		{"SELECT * FROM S3Object s WHERE s._1 = 'Alice'", true},
+		{"SELECT * FROM S3Object AS s WHERE s.\"name\" <> 'Alice' OR s.age < 10", false},
+		{"SELECT * FROM S3Object WHERE CAST(age AS INT) = 34", true},
+		{"SELECT * FROM S3Object WHERE name LIKE 'A%e'", true},
+		{"SELECT * FROM S3Object WHERE name NOT LIKE '_lice'", false},
+		{"SELECT * FROM S3Object WHERE age BETWEEN 30 AND 40", true},
+		{"SELECT * FROM S3Object WHERE city IN ('London', 'Paris')", true},
+		{"SELECT * FROM S3Object WHERE missing IS NULL", true},
+		{"SELECT * FROM S3Object WHERE NOT (age + 1 = 35)", false},
+		{"SELECT * FROM S3Object WHERE LOWER(name) = 'alice'", true},
+		{"SELECT * FROM S3Object WHERE missing = 'x' OR age = 34", true},
+		{"select * from s3object where Name = 'Alice'", true},
+	}
+	for i, testCase := range testCases {
+		q, err := parseQuery(testCase.expression)
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error: %s", i+1, err)
+		}
+		match := true
+		if q.where != nil {
+			match, _ = q.where.eval(rec).(bool)
+		}
+		if match != testCase.match {
+			t.Errorf("Test %d: %s: expected %v, got %v", i+1, testCase.expression, testCase.match, match)
+		}
+	}
+}
+
+// Tests that invalid expressions are rejected.
+func TestParseQueryErrors(t *testing.T) {
+	testCases := []string{
+		"",
+		"SELECT",
+		"SELECT * FROM",
+		"SELECT * FROM table",
+		"SELECT * FROM S3Object WHERE",
+		"SELECT * FROM S3Object WHERE name = 'unterminated",
+		"SELECT * FROM S3Object LIMIT -1",
+		"SELECT * FROM S3Object WHERE UNKNOWN(name) = 1",
+		"SELECT * FROM S3Object WHERE name NOT = 1",
+		"DELETE FROM S3Object",
+	}
+	for i, expression := range testCases {
+		if _, err := parseQuery(expression); err == nil {
+			t.Errorf("Test %d: Expected %q to fail", i+1, expression)
+		}
+	}
+}
+
+// Tests LIKE pattern matching.
+func TestLikeMatch(t *testing.T) {
+	testCases := []struct {
+		s, pattern string
+		match      bool
+	}{
+		{"", "", true},
+		{"", "%", true},
+		{"abc", "abc", true},
+		{"abc", "a_c", true},
+		{"abc", "a%", true},
+		{"abc", "%c", true},
+		{"abc", "%b%", true},
+		{"abc", "a_", false},
+		{"héllo", "h_llo", true},
+	}
+	for i, testCase := range testCases {
+		if match := likeMatch(testCase.s, testCase.pattern); match != testCase.match {
+			t.Errorf("Test %d: likeMatch(%q, %q) expected %v", i+1, testCase.s, testCase.pattern, testCase.match)
+		}
+	}
+}